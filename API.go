@@ -1,5 +1,7 @@
 package goex
 
+import "context"
+
 // api interface
 
 type API interface {
@@ -21,3 +23,30 @@ type API interface {
 
 	GetExchangeName() string
 }
+
+/**
+ * 一次获取所有交易对的行情, 有批量行情接口的交易所实现. map的键为统一交易对(见CanonicalPair), 不同交易所的结果可以直接按键合并
+ */
+type TickersAPI interface {
+	GetAllTickers() (map[CurrencyPair]*TickerDecimal, error)
+}
+
+// API的context版本, ctx用于设置超时和取消请求, 见WithContext
+type APIContext interface {
+	LimitBuyWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error)
+	LimitSellWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error)
+	MarketBuyWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error)
+	MarketSellWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error)
+	CancelOrderWithContext(ctx context.Context, orderId string, currency CurrencyPair) (bool, error)
+	GetOneOrderWithContext(ctx context.Context, orderId string, currency CurrencyPair) (*Order, error)
+	GetUnfinishOrdersWithContext(ctx context.Context, currency CurrencyPair) ([]Order, error)
+	GetOrderHistorysWithContext(ctx context.Context, currency CurrencyPair, currentPage, pageSize int) ([]Order, error)
+	GetAccountWithContext(ctx context.Context) (*Account, error)
+
+	GetTickerWithContext(ctx context.Context, currency CurrencyPair) (*Ticker, error)
+	GetDepthWithContext(ctx context.Context, size int, currency CurrencyPair) (*Depth, error)
+	GetKlineRecordsWithContext(ctx context.Context, currency CurrencyPair, period, size, since int) ([]Kline, error)
+	GetTradesWithContext(ctx context.Context, currencyPair CurrencyPair, since int64) ([]Trade, error)
+
+	GetExchangeName() string
+}
@@ -1,13 +1,14 @@
 package goex
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"reflect"
 	"time"
 )
 
 /**
+  Deprecated: 通过反射调用, 线性等待并在重试失败后panic, 请使用RetryPolicy.Do或Retry
   @retry  重试次数
   @method 调用的函数，比如: api.GetTicker ,注意：不是api.GetTicker(...)
   @params 参数,顺序一定要按照实际调用函数入参顺序一样
@@ -31,7 +32,6 @@ func RE(retry int, method interface{}, params ...interface{}) interface{} {
 	var retryC int = 0
 _CALL:
 	if retryC > 0 {
-		log.Println("sleep....", time.Duration(retryC*200*int(time.Millisecond)))
 		time.Sleep(time.Duration(retryC * 200 * int(time.Millisecond)))
 	}
 
@@ -40,10 +40,10 @@ _CALL:
 	for _, vl := range retValues {
 		if vl.Type().String() == "error" {
 			if !vl.IsNil() {
-				log.Println(vl)
+				DefaultLogger().Warn("invoke method error", "method", invokeM.String(), "err", vl.Interface())
 				retryC++
 				if retryC <= retry {
-					log.Printf("Invoke Method[%s] Error , Begin Retry Call [%d] ...", invokeM.String(), retryC)
+					DefaultLogger().Debug("retry invoke method", "method", invokeM.String(), "retry", retryC)
 					goto _CALL
 				} else {
 					panic("Invoke Method Fail ???" + invokeM.String())
@@ -62,25 +62,33 @@ _CALL:
  */
 func CancelAllUnfinishedOrders(api API, currencyPair CurrencyPair) int {
 	if api == nil {
-		log.Println("api instance is nil ??? , please new a api instance")
+		DefaultLogger().Error("api instance is nil, please new a api instance")
 		return -1
 	}
 
-	orders := RE(10, api.GetUnfinishOrders, currencyPair)
-	if orders != nil {
-		c := 0
-		for _, ord := range orders.([]Order) {
-			_, err := api.CancelOrder(fmt.Sprintf("%d", ord.OrderID), currencyPair)
-			if err != nil {
-				log.Println(err)
-			}
-			c++
-			time.Sleep(100 * time.Millisecond) //控制频率
-		}
+	var orders []Order
+	err := Retry(context.Background(), func(ctx context.Context) error {
+		var err error
+		orders, err = api.GetUnfinishOrders(currencyPair)
+		return err
+	})
+	if err != nil {
+		DefaultLogger().Warn("get unfinished orders failed", "exchange", api.GetExchangeName(), "pair", currencyPair, "err", err)
+		return 0
+	}
 
-		return c
+	c := 0
+	for _, ord := range orders {
+		_, err := api.CancelOrder(fmt.Sprintf("%d", ord.OrderID), currencyPair)
+		if err != nil {
+			DefaultLogger().Warn("cancel order failed", "exchange", api.GetExchangeName(), "order_id", ord.OrderID, "err", err)
+		}
+		c++
+		if GetExchangeRateLimiter(api.GetExchangeName()) == nil {
+			time.Sleep(100 * time.Millisecond) //控制频率, 已注册限频器的交易所由限频器控制
+		}
 	}
-	return 0
+	return c
 }
 
 /**
@@ -88,17 +96,27 @@ func CancelAllUnfinishedOrders(api API, currencyPair CurrencyPair) int {
  */
 func CancelAllUnfinishedFutureOrders(api FutureRestAPI, contractType string, currencyPair CurrencyPair) {
 	if api == nil {
-		log.Println("api instance is nil ??? , please new a api instance")
+		DefaultLogger().Error("api instance is nil, please new a api instance")
 		return
 	}
 
-	orders := RE(10, api.GetUnfinishFutureOrders, currencyPair, contractType)
-	if orders != nil {
-		for _, ord := range orders.([]Order) {
-			_, err := api.FutureCancelOrder(currencyPair, contractType, fmt.Sprintf("%d", ord.OrderID))
-			if err != nil {
-				log.Println(err)
-			}
+	var orders []FutureOrder
+	err := Retry(context.Background(), func(ctx context.Context) error {
+		var err error
+		orders, err = api.GetUnfinishFutureOrders(currencyPair, contractType)
+		return err
+	})
+	if err != nil {
+		DefaultLogger().Warn("get unfinished future orders failed", "exchange", api.GetExchangeName(), "pair", currencyPair, "contract", contractType, "err", err)
+		return
+	}
+
+	for _, ord := range orders {
+		_, err := api.FutureCancelOrder(currencyPair, contractType, fmt.Sprintf("%d", ord.OrderID))
+		if err != nil {
+			DefaultLogger().Warn("cancel future order failed", "exchange", api.GetExchangeName(), "order_id", ord.OrderID, "err", err)
+		}
+		if GetExchangeRateLimiter(api.GetExchangeName()) == nil {
 			time.Sleep(100 * time.Millisecond) //控制频率
 		}
 	}
@@ -1,5 +1,14 @@
 package goex
 
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
 type ApiError struct {
 	ErrCode,
 	ErrMsg,
@@ -23,4 +32,265 @@ var (
 	EX_ERR_INVALID_CURRENCY_PAIR = ApiError{ErrCode: "EX_ERR_0007", ErrMsg: "invalid currency pair"}
 	EX_ERR_NOT_FIND_ORDER        = ApiError{ErrCode: "EX_ERR_0008", ErrMsg: "not find order"}
 	EX_ERR_SYMBOL_ERR            = ApiError{ErrCode: "EX_ERR_0009", ErrMsg: "symbol error"}
+	EX_ERR_NOT_SUPPORTED         = ApiError{ErrCode: "EX_ERR_0010", ErrMsg: "not supported"}
+	EX_ERR_NOT_FIND_RECORD       = ApiError{ErrCode: "EX_ERR_0011", ErrMsg: "not find record"}
+)
+
+// 错误分类, 实现了error接口, 可以用errors.Is(err, ERR_RATE_LIMITED)判断错误类别
+type ErrorCategory int
+
+const (
+	ERR_UNKNOWN              ErrorCategory = iota
+	ERR_RATE_LIMITED                       // 触发限频
+	ERR_INSUFFICIENT_BALANCE               // 余额不足
+	ERR_ORDER_NOT_FOUND                    // 订单不存在
+	ERR_INVALID_SYMBOL                     // 交易对/币种错误
+	ERR_AUTH                               // API Key或签名错误
+	ERR_NONCE                              // nonce或时间戳错误
+	ERR_MAINTENANCE                        // 交易所维护或服务不可用
+	ERR_POST_ONLY_REJECTED                 // 只做maker的订单会立即成交而被拒绝
+	ERR_NETWORK                            // 网络错误
+	ERR_INVALID_PARAM                      // 参数错误
+)
+
+var errorCategoryNames = [...]string{"unknown error", "rate limited", "insufficient balance", "order not found",
+	"invalid symbol", "auth failure", "nonce error", "exchange maintenance", "post only rejected", "network error",
+	"invalid parameter"}
+
+func (c ErrorCategory) String() string {
+	if c < 0 || int(c) >= len(errorCategoryNames) {
+		return errorCategoryNames[ERR_UNKNOWN]
+	}
+	return errorCategoryNames[c]
+}
+
+func (c ErrorCategory) Error() string {
+	return c.String()
+}
+
+/**
+ * 稍后重试可能成功的错误类别
+ */
+func (c ErrorCategory) Temporary() bool {
+	switch c {
+	case ERR_RATE_LIMITED, ERR_NONCE, ERR_MAINTENANCE, ERR_NETWORK:
+		return true
+	}
+	return false
+}
+
+var apiErrorCategories = map[string]ErrorCategory{
+	EX_ERR_API_LIMIT.ErrCode:             ERR_RATE_LIMITED,
+	EX_ERR_SIGN.ErrCode:                  ERR_AUTH,
+	EX_ERR_NOT_FIND_SECRETKEY.ErrCode:    ERR_AUTH,
+	EX_ERR_NOT_FIND_APIKEY.ErrCode:       ERR_AUTH,
+	EX_ERR_INSUFFICIENT_BALANCE.ErrCode:  ERR_INSUFFICIENT_BALANCE,
+	EX_ERR_INVALID_CURRENCY_PAIR.ErrCode: ERR_INVALID_SYMBOL,
+	EX_ERR_NOT_FIND_ORDER.ErrCode:        ERR_ORDER_NOT_FOUND,
+	EX_ERR_SYMBOL_ERR.ErrCode:            ERR_INVALID_SYMBOL,
+}
+
+func (e ApiError) Category() ErrorCategory {
+	return apiErrorCategories[e.ErrCode]
+}
+
+func (e ApiError) Is(target error) bool {
+	c, ok := target.(ErrorCategory)
+	return ok && c != ERR_UNKNOWN && e.Category() == c
+}
+
+/**
+ * 交易所返回的错误, 保留原始错误码、HTTP状态码和响应内容
+ */
+type ExchangeError struct {
+	Exchange   string
+	Category   ErrorCategory
+	Code       string // 交易所原始错误码
+	Message    string // 交易所原始错误信息
+	HttpStatus int    // 非HTTP错误时为0
+	Body       string
+	Err        error // 底层错误, 如网络错误
+}
+
+func NewExchangeError(exchange string, category ErrorCategory, code, message string) *ExchangeError {
+	return &ExchangeError{Exchange: exchange, Category: category, Code: code, Message: message}
+}
+
+func (e *ExchangeError) Error() string {
+	switch {
+	case e.HttpStatus != 0:
+		return fmt.Sprintf("HttpStatusCode:%d ,Desc:%s", e.HttpStatus, e.Body)
+	case e.Err != nil && e.Message == "":
+		return e.Err.Error()
+	case e.Code == "" || e.Code == e.Message:
+		return e.Message
+	case e.Message == "":
+		return e.Code
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *ExchangeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ExchangeError) Is(target error) bool {
+	switch t := target.(type) {
+	case ErrorCategory:
+		return t != ERR_UNKNOWN && e.Category == t
+	case ApiError:
+		return t.Category() != ERR_UNKNOWN && e.Category == t.Category()
+	}
+	return false
+}
+
+func (e *ExchangeError) Temporary() bool {
+	return e.Category.Temporary()
+}
+
+/**
+ * 获取错误类别
+ */
+func ErrorCategoryOf(err error) ErrorCategory {
+	if err == nil {
+		return ERR_UNKNOWN
+	}
+	var exErr *ExchangeError
+	if errors.As(err, &exErr) {
+		return exErr.Category
+	}
+	var apiErr ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.Category()
+	}
+	var category ErrorCategory
+	if errors.As(err, &category) {
+		return category
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ERR_NETWORK
+	}
+	return ERR_UNKNOWN
+}
+
+/**
+ * 根据错误信息判断类别, 用于没有错误码或错误码未收录的情况
+ */
+func ClassifyMessage(message string) ErrorCategory {
+	msg := strings.ToLower(message)
+	switch {
+	case msg == "":
+		return ERR_UNKNOWN
+	case strings.Contains(msg, "insufficient") || strings.Contains(msg, "not enough"):
+		return ERR_INSUFFICIENT_BALANCE
+	case strings.Contains(msg, "post only") || strings.Contains(msg, "post_only") || strings.Contains(msg, "postonly") ||
+		strings.Contains(msg, "immediately match"):
+		return ERR_POST_ONLY_REJECTED
+	case strings.Contains(msg, "too many") || strings.Contains(msg, "rate limit") || strings.Contains(msg, "too frequent"):
+		return ERR_RATE_LIMITED
+	case strings.Contains(msg, "nonce") || strings.Contains(msg, "timestamp"):
+		return ERR_NONCE
+	case strings.Contains(msg, "maintenance") || strings.Contains(msg, "maintain"):
+		return ERR_MAINTENANCE
+	case strings.Contains(msg, "order") && (strings.Contains(msg, "not exist") || strings.Contains(msg, "not found") ||
+		strings.Contains(msg, "unknown order")):
+		return ERR_ORDER_NOT_FOUND
+	case strings.Contains(msg, "signature") || strings.Contains(msg, "api key") || strings.Contains(msg, "api-key") ||
+		strings.Contains(msg, "apikey"):
+		return ERR_AUTH
+	}
+	return ERR_UNKNOWN
+}
+
+func httpStatusCategory(statusCode int) ErrorCategory {
+	switch {
+	case statusCode == http.StatusTooManyRequests || statusCode == 418:
+		return ERR_RATE_LIMITED
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ERR_AUTH
+	case statusCode == http.StatusServiceUnavailable:
+		return ERR_MAINTENANCE
+	case statusCode == http.StatusBadGateway || statusCode == http.StatusGatewayTimeout:
+		return ERR_NETWORK
+	}
+	return ERR_UNKNOWN
+}
+
+/**
+ * 交易所原始错误码到错误类别的映射.
+ * 已接入错误分类的接口: binance现货和合约、bitfinex、bitmex、fcoin、gateiospot、huobi现货和合约、okcoin V3、poloniex、biki、zbg和plo.
+ * fameex和其他旧接口未接入, 返回错误的类别为ERR_UNKNOWN, 可以用ClassifyMessage按错误信息判断.
+ * bitfinex只有现货、杠杆和借贷接口, 没有期货接口
+ */
+type ErrorCodes map[string]ErrorCategory
+
+func (codes ErrorCodes) Category(code, message string) ErrorCategory {
+	if c, ok := codes[code]; ok {
+		return c
+	}
+	return ClassifyMessage(message)
+}
+
+func (codes ErrorCodes) NewError(exchange, code, message string) *ExchangeError {
+	return NewExchangeError(exchange, codes.Category(code, message), code, message)
+}
+
+/**
+ * 解析非2xx响应的内容, 无法识别时返回nil
+ */
+type ErrorParser func(statusCode int, body []byte) *ExchangeError
+
+type errorParserEntry struct {
+	exchange string
+	parse    ErrorParser
+}
+
+var (
+	errorParsersLock sync.RWMutex
+	errorParsers     = map[string]errorParserEntry{} // host -> parser
 )
+
+/**
+ * 注册交易所的错误解析, HTTP工具函数对发往hosts的失败请求返回*ExchangeError
+ */
+func RegisterErrorParser(exchange string, parse ErrorParser, hosts ...string) {
+	errorParsersLock.Lock()
+	defer errorParsersLock.Unlock()
+	for _, host := range hosts {
+		errorParsers[host] = errorParserEntry{exchange: exchange, parse: parse}
+	}
+}
+
+/**
+ * 非2xx响应的错误
+ */
+func NewHttpError(host string, statusCode int, body []byte) *ExchangeError {
+	errorParsersLock.RLock()
+	entry, ok := errorParsers[host]
+	errorParsersLock.RUnlock()
+
+	e := &ExchangeError{HttpStatus: statusCode, Body: string(body)}
+	if ok {
+		e.Exchange = entry.exchange
+		if parsed := entry.parse(statusCode, body); parsed != nil {
+			e.Category = parsed.Category
+			e.Code = parsed.Code
+			e.Message = parsed.Message
+		}
+	}
+	if e.Category == ERR_UNKNOWN {
+		e.Category = httpStatusCategory(statusCode)
+	}
+	return e
+}
+
+/**
+ * 网络错误
+ */
+func NewNetworkError(host string, err error) *ExchangeError {
+	errorParsersLock.RLock()
+	entry := errorParsers[host]
+	errorParsersLock.RUnlock()
+	return &ExchangeError{Exchange: entry.exchange, Category: ERR_NETWORK, Err: err}
+}
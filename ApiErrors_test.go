@@ -0,0 +1,46 @@
+package goex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchangeError_Is(t *testing.T) {
+	err := fmt.Errorf("place order: %w", NewExchangeError(BINANCE, ERR_INSUFFICIENT_BALANCE, "-2010", "Account has insufficient balance"))
+	assert.True(t, errors.Is(err, ERR_INSUFFICIENT_BALANCE))
+	assert.True(t, errors.Is(err, EX_ERR_INSUFFICIENT_BALANCE))
+	assert.False(t, errors.Is(err, ERR_RATE_LIMITED))
+	assert.Equal(t, ERR_INSUFFICIENT_BALANCE, ErrorCategoryOf(err))
+
+	var exErr *ExchangeError
+	assert.True(t, errors.As(err, &exErr))
+	assert.Equal(t, "-2010", exErr.Code)
+	assert.Equal(t, "-2010: Account has insufficient balance", exErr.Error())
+
+	assert.True(t, errors.Is(EX_ERR_API_LIMIT, ERR_RATE_LIMITED))
+	assert.True(t, ErrorCategoryOf(EX_ERR_API_LIMIT).Temporary())
+	assert.Equal(t, ERR_UNKNOWN, ErrorCategoryOf(errors.New("fail")))
+}
+
+func TestNewHttpError(t *testing.T) {
+	codes := ErrorCodes{"1001": ERR_ORDER_NOT_FOUND}
+	RegisterErrorParser("test", func(statusCode int, body []byte) *ExchangeError {
+		return codes.NewError("test", string(body[:4]), string(body[5:]))
+	}, "api.test.com")
+
+	err := NewHttpError("api.test.com", 400, []byte("1001 order does not exist"))
+	assert.Equal(t, "HttpStatusCode:400 ,Desc:1001 order does not exist", err.Error())
+	assert.Equal(t, "test", err.Exchange)
+	assert.True(t, errors.Is(err, ERR_ORDER_NOT_FOUND))
+
+	err = NewHttpError("api.test.com", 400, []byte("1002 Timestamp outside of recvWindow"))
+	assert.True(t, errors.Is(err, ERR_NONCE))
+
+	err = NewHttpError("unknown.com", http.StatusTooManyRequests, nil)
+	assert.True(t, errors.Is(err, ERR_RATE_LIMITED))
+	assert.Equal(t, 429, err.HttpStatus)
+}
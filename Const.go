@@ -63,9 +63,11 @@ const (
 )
 
 var (
-	THIS_WEEK_CONTRACT = "this_week" //周合约
-	NEXT_WEEK_CONTRACT = "next_week" //次周合约
-	QUARTER_CONTRACT   = "quarter"   //季度合约
+	THIS_WEEK_CONTRACT  = "this_week"  //周合约
+	NEXT_WEEK_CONTRACT  = "next_week"  //次周合约
+	QUARTER_CONTRACT    = "quarter"    //季度合约
+	BI_QUARTER_CONTRACT = "bi_quarter" //次季度合约
+	SWAP_CONTRACT       = "swap"       //永续合约
 )
 
 //exchanges const
@@ -92,4 +94,11 @@ const (
 	COIN58      = "58coin.com"
 	FCOIN       = "fcoin.com"
 	HITBTC      = "hitbtc.com"
+	BITMEX      = "bitmex.com"
+	BIKI        = "biki.com"
+	PLO         = "plo.one"
+	FAMEEX      = "fameex.com"
+
+	// 币安合约与现货的服务器不同, 服务器时钟等按交易所区分的设置单独注册
+	BINANCE_FUTURE = "binance.com/future"
 )
@@ -0,0 +1,400 @@
+package goex
+
+import "context"
+
+/**
+ * 在goroutine中调用fn, ctx先结束时不等待fn返回, done为false
+ */
+func runWithContext(ctx context.Context, fn func() error) (done bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	ch := make(chan error, 1)
+	go func() {
+		ch <- fn()
+	}()
+	select {
+	case err := <-ch:
+		return true, err
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+/**
+ * 下单、撤单等改变订单状态的调用: ctx已结束时不发送请求, 发送后等待结果, 不会在请求仍在执行时返回ctx.Err()
+ */
+func runMutationWithContext(ctx context.Context, fn func() error) (done bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return true, fn()
+}
+
+/**
+ * 把API转换为APIContext, 已实现APIContext的直接返回. binance、huobi、poloniex把ctx传给HTTP请求,
+ * ctx结束时请求被中止.
+ * 其余交易所使用适配器兜底: 查询在ctx结束时立即返回ctx.Err(), 请求仍在后台执行;
+ * 下单和撤单在ctx已结束时不发送, 发送后等待交易所返回, 避免把可能已成功的下单当成取消而重复下单
+ */
+func WithContext(api API) APIContext {
+	if ctxApi, ok := api.(APIContext); ok {
+		return ctxApi
+	}
+	return apiContextAdapter{api}
+}
+
+type apiContextAdapter struct {
+	api API
+}
+
+func (a apiContextAdapter) GetExchangeName() string {
+	return a.api.GetExchangeName()
+}
+
+func (a apiContextAdapter) LimitBuyWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	var ret *Order
+	done, err := runMutationWithContext(ctx, func() (err error) {
+		ret, err = a.api.LimitBuy(amount, price, currency)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) LimitSellWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	var ret *Order
+	done, err := runMutationWithContext(ctx, func() (err error) {
+		ret, err = a.api.LimitSell(amount, price, currency)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) MarketBuyWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	var ret *Order
+	done, err := runMutationWithContext(ctx, func() (err error) {
+		ret, err = a.api.MarketBuy(amount, price, currency)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) MarketSellWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	var ret *Order
+	done, err := runMutationWithContext(ctx, func() (err error) {
+		ret, err = a.api.MarketSell(amount, price, currency)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) CancelOrderWithContext(ctx context.Context, orderId string, currency CurrencyPair) (bool, error) {
+	var ret bool
+	done, err := runMutationWithContext(ctx, func() (err error) {
+		ret, err = a.api.CancelOrder(orderId, currency)
+		return
+	})
+	if !done {
+		return false, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) GetOneOrderWithContext(ctx context.Context, orderId string, currency CurrencyPair) (*Order, error) {
+	var ret *Order
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetOneOrder(orderId, currency)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) GetUnfinishOrdersWithContext(ctx context.Context, currency CurrencyPair) ([]Order, error) {
+	var ret []Order
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetUnfinishOrders(currency)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) GetOrderHistorysWithContext(ctx context.Context, currency CurrencyPair, currentPage, pageSize int) ([]Order, error) {
+	var ret []Order
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetOrderHistorys(currency, currentPage, pageSize)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) GetAccountWithContext(ctx context.Context) (*Account, error) {
+	var ret *Account
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetAccount()
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) GetTickerWithContext(ctx context.Context, currency CurrencyPair) (*Ticker, error) {
+	var ret *Ticker
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetTicker(currency)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) GetDepthWithContext(ctx context.Context, size int, currency CurrencyPair) (*Depth, error) {
+	var ret *Depth
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetDepth(size, currency)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) GetKlineRecordsWithContext(ctx context.Context, currency CurrencyPair, period, size, since int) ([]Kline, error) {
+	var ret []Kline
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetKlineRecords(currency, period, size, since)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a apiContextAdapter) GetTradesWithContext(ctx context.Context, currencyPair CurrencyPair, since int64) ([]Trade, error) {
+	var ret []Trade
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetTrades(currencyPair, since)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+/**
+ * 把FutureRestAPI转换为FutureRestAPIContext, 已实现FutureRestAPIContext的直接返回.
+ * 目前期货交易所都使用适配器兜底, 下单和撤单的处理同WithContext
+ */
+func WithFutureContext(api FutureRestAPI) FutureRestAPIContext {
+	if ctxApi, ok := api.(FutureRestAPIContext); ok {
+		return ctxApi
+	}
+	return futureContextAdapter{api}
+}
+
+type futureContextAdapter struct {
+	api FutureRestAPI
+}
+
+func (a futureContextAdapter) GetExchangeName() string {
+	return a.api.GetExchangeName()
+}
+
+func (a futureContextAdapter) GetDeliveryTime() (int, int, int, int) {
+	return a.api.GetDeliveryTime()
+}
+
+func (a futureContextAdapter) GetFutureEstimatedPriceWithContext(ctx context.Context, currencyPair CurrencyPair) (float64, error) {
+	var ret float64
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetFutureEstimatedPrice(currencyPair)
+		return
+	})
+	if !done {
+		return 0, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) GetFutureTickerWithContext(ctx context.Context, currencyPair CurrencyPair, contractType string) (*Ticker, error) {
+	var ret *Ticker
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetFutureTicker(currencyPair, contractType)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) GetFutureDepthWithContext(ctx context.Context, currencyPair CurrencyPair, contractType string, size int) (*Depth, error) {
+	var ret *Depth
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetFutureDepth(currencyPair, contractType, size)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) GetFutureIndexWithContext(ctx context.Context, currencyPair CurrencyPair) (float64, error) {
+	var ret float64
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetFutureIndex(currencyPair)
+		return
+	})
+	if !done {
+		return 0, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) GetFutureUserinfoWithContext(ctx context.Context) (*FutureAccount, error) {
+	var ret *FutureAccount
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetFutureUserinfo()
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) PlaceFutureOrderWithContext(ctx context.Context, currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice, leverRate int) (string, error) {
+	var ret string
+	done, err := runMutationWithContext(ctx, func() (err error) {
+		ret, err = a.api.PlaceFutureOrder(currencyPair, contractType, price, amount, openType, matchPrice, leverRate)
+		return
+	})
+	if !done {
+		return "", err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) FutureCancelOrderWithContext(ctx context.Context, currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
+	var ret bool
+	done, err := runMutationWithContext(ctx, func() (err error) {
+		ret, err = a.api.FutureCancelOrder(currencyPair, contractType, orderId)
+		return
+	})
+	if !done {
+		return false, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) GetFuturePositionWithContext(ctx context.Context, currencyPair CurrencyPair, contractType string) ([]FuturePosition, error) {
+	var ret []FuturePosition
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetFuturePosition(currencyPair, contractType)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) GetFutureOrdersWithContext(ctx context.Context, orderIds []string, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
+	var ret []FutureOrder
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetFutureOrders(orderIds, currencyPair, contractType)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) GetUnfinishFutureOrdersWithContext(ctx context.Context, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
+	var ret []FutureOrder
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetUnfinishFutureOrders(currencyPair, contractType)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) GetFeeWithContext(ctx context.Context) (float64, error) {
+	var ret float64
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetFee()
+		return
+	})
+	if !done {
+		return 0, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) GetExchangeRateWithContext(ctx context.Context) (float64, error) {
+	var ret float64
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetExchangeRate()
+		return
+	})
+	if !done {
+		return 0, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) GetContractValueWithContext(ctx context.Context, currencyPair CurrencyPair) (float64, error) {
+	var ret float64
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetContractValue(currencyPair)
+		return
+	})
+	if !done {
+		return 0, err
+	}
+	return ret, err
+}
+
+func (a futureContextAdapter) GetKlineRecordsWithContext(ctx context.Context, contract_type string, currency CurrencyPair, period string, size, since int) ([]FutureKline, error) {
+	var ret []FutureKline
+	done, err := runWithContext(ctx, func() (err error) {
+		ret, err = a.api.GetKlineRecords(contract_type, currency, period, size, since)
+		return
+	})
+	if !done {
+		return nil, err
+	}
+	return ret, err
+}
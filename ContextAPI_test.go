@@ -0,0 +1,85 @@
+package goex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type slowTickerAPI struct {
+	API
+	delay time.Duration
+}
+
+func (a slowTickerAPI) GetTicker(currency CurrencyPair) (*Ticker, error) {
+	time.Sleep(a.delay)
+	return &Ticker{Pair: currency, Last: 1}, nil
+}
+
+func TestWithContext(t *testing.T) {
+	api := WithContext(slowTickerAPI{delay: 0})
+	ticker, err := api.GetTickerWithContext(context.Background(), BTC_USDT)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, ticker.Last)
+
+	api = WithContext(slowTickerAPI{delay: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	ticker, err = api.GetTickerWithContext(ctx, BTC_USDT)
+	assert.Nil(t, ticker)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+}
+
+type slowOrderAPI struct {
+	API
+	delay  time.Duration
+	placed *int
+}
+
+func (a slowOrderAPI) LimitBuy(amount, price string, currency CurrencyPair) (*Order, error) {
+	time.Sleep(a.delay)
+	*a.placed++
+	return &Order{OrderID2: "1", Currency: currency}, nil
+}
+
+func TestWithContext_Order(t *testing.T) {
+	var placed int
+	api := WithContext(slowOrderAPI{delay: 50 * time.Millisecond, placed: &placed})
+
+	// 请求发出后ctx超时, 仍然返回下单结果
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	order, err := api.LimitBuyWithContext(ctx, "1", "100", BTC_USDT)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", order.OrderID2)
+	assert.Equal(t, 1, placed)
+
+	// ctx已结束时不发送
+	order, err = api.LimitBuyWithContext(ctx, "1", "100", BTC_USDT)
+	assert.Nil(t, order)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, placed)
+}
+
+func TestHttpGetWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := HttpGetWithContext(ctx, http.DefaultClient, server.URL)
+	assert.NotNil(t, err)
+	assert.True(t, ctx.Err() != nil)
+}
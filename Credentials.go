@@ -0,0 +1,236 @@
+package goex
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+/**
+ * API凭证. String和GoString只输出API Key的前4位, 避免密钥出现在日志中
+ */
+type Credentials struct {
+	ApiKey     string `json:"api_key"`
+	SecretKey  string `json:"secret_key"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return redacted
+	}
+	return key[:4] + redacted
+}
+
+func (c Credentials) String() string {
+	return fmt.Sprintf("Credentials{ApiKey: %s}", maskKey(c.ApiKey))
+}
+
+func (c Credentials) GoString() string {
+	return c.String()
+}
+
+var ErrNoCredentials = errors.New("no credentials")
+
+/**
+ * 凭证来源, 每次签名时调用, 实现需要自己缓存, 返回新的凭证即完成轮换
+ */
+type CredentialProvider interface {
+	Credentials() (Credentials, error)
+}
+
+/**
+ * 固定的凭证, 可以用Rotate替换
+ */
+type StaticCredentials struct {
+	lock        sync.RWMutex
+	credentials Credentials
+}
+
+func NewStaticCredentials(apiKey, secretKey, passphrase string) *StaticCredentials {
+	return &StaticCredentials{credentials: Credentials{ApiKey: apiKey, SecretKey: secretKey, Passphrase: passphrase}}
+}
+
+func (s *StaticCredentials) Credentials() (Credentials, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.credentials.ApiKey == "" || s.credentials.SecretKey == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	return s.credentials, nil
+}
+
+func (s *StaticCredentials) Rotate(credentials Credentials) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.credentials = credentials
+}
+
+/**
+ * 从环境变量读取凭证: {Prefix}_API_KEY, {Prefix}_SECRET_KEY, {Prefix}_PASSPHRASE, 如BINANCE_API_KEY
+ */
+type EnvCredentials struct {
+	Prefix string
+}
+
+func NewEnvCredentials(prefix string) *EnvCredentials {
+	return &EnvCredentials{Prefix: prefix}
+}
+
+func (e *EnvCredentials) Credentials() (Credentials, error) {
+	prefix := strings.ToUpper(e.Prefix)
+	c := Credentials{
+		ApiKey:     os.Getenv(prefix + "_API_KEY"),
+		SecretKey:  os.Getenv(prefix + "_SECRET_KEY"),
+		Passphrase: os.Getenv(prefix + "_PASSPHRASE"),
+	}
+	if c.ApiKey == "" || c.SecretKey == "" {
+		return Credentials{}, fmt.Errorf("%w: %s_API_KEY or %s_SECRET_KEY not set", ErrNoCredentials, prefix, prefix)
+	}
+	return c, nil
+}
+
+/**
+ * AES-256-GCM加密的凭证文件, 内容为nonce+密文, 明文为Credentials的JSON.
+ * 文件修改时间变化时重新读取, 替换文件即可轮换凭证. 文件用EncryptCredentialsFile生成
+ */
+type FileCredentials struct {
+	Path string
+	key  []byte
+
+	lock        sync.Mutex
+	modTime     time.Time
+	credentials Credentials
+}
+
+/**
+ * key为32字节的AES-256密钥
+ */
+func NewFileCredentials(path string, key []byte) (*FileCredentials, error) {
+	if len(key) != 32 {
+		return nil, errors.New("credentials file key must be 32 bytes")
+	}
+	return &FileCredentials{Path: path, key: key}, nil
+}
+
+func (f *FileCredentials) Credentials() (Credentials, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if info.ModTime().Equal(f.modTime) && f.credentials.ApiKey != "" {
+		return f.credentials, nil
+	}
+
+	data, err := ioutil.ReadFile(f.Path)
+	if err != nil {
+		return Credentials{}, err
+	}
+	credentials, err := decryptCredentials(f.key, data)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("decrypt %s: %w", f.Path, err)
+	}
+	f.credentials = credentials
+	f.modTime = info.ModTime()
+	return credentials, nil
+}
+
+func decryptCredentials(key, data []byte) (Credentials, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return Credentials{}, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return Credentials{}, errors.New("credentials file too short")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	var credentials Credentials
+	err = json.Unmarshal(plain, &credentials)
+	return credentials, err
+}
+
+/**
+ * 生成FileCredentials读取的加密凭证文件, 文件权限为0600
+ */
+func EncryptCredentialsFile(path string, key []byte, credentials Credentials) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, gcm.Seal(nonce, nonce, plain, nil), 0600)
+}
+
+/**
+ * 从本地凭证代理获取凭证, Url返回Credentials的JSON. 结果缓存TTL, 刷新失败时继续使用缓存的凭证
+ */
+type AgentCredentials struct {
+	Url    string
+	Client *http.Client
+	TTL    time.Duration
+
+	lock        sync.Mutex
+	fetched     time.Time
+	credentials Credentials
+}
+
+func NewAgentCredentials(url string) *AgentCredentials {
+	return &AgentCredentials{Url: url, Client: http.DefaultClient, TTL: time.Minute}
+}
+
+func (a *AgentCredentials) Credentials() (Credentials, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.credentials.ApiKey != "" && time.Since(a.fetched) < a.TTL {
+		return a.credentials, nil
+	}
+
+	var credentials Credentials
+	err := HttpGet4(a.Client, a.Url, nil, &credentials)
+	if err == nil && (credentials.ApiKey == "" || credentials.SecretKey == "") {
+		err = ErrNoCredentials
+	}
+	if err != nil {
+		if a.credentials.ApiKey != "" {
+			DefaultLogger().Warn("refresh credentials from agent failed, using cached credentials", "url", a.Url, "err", err)
+			return a.credentials, nil
+		}
+		return Credentials{}, err
+	}
+
+	a.credentials = credentials
+	a.fetched = time.Now()
+	return credentials, nil
+}
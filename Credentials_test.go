@@ -0,0 +1,137 @@
+package goex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentials_String(t *testing.T) {
+	c := Credentials{ApiKey: "abcdefgh", SecretKey: "topsecret", Passphrase: "pass"}
+	assert.Equal(t, "Credentials{ApiKey: abcd***}", c.String())
+	assert.NotContains(t, fmt.Sprintf("%v %+v %#v", c, c, c), "topsecret")
+	assert.NotContains(t, fmt.Sprintf("%v", &c), "pass")
+}
+
+func TestStaticCredentials_Rotate(t *testing.T) {
+	s := NewStaticCredentials("key1", "secret1", "")
+	c, err := s.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "key1", c.ApiKey)
+
+	s.Rotate(Credentials{ApiKey: "key2", SecretKey: "secret2"})
+	c, err = s.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "key2", c.ApiKey)
+	assert.Equal(t, "secret2", c.SecretKey)
+
+	_, err = NewStaticCredentials("", "", "").Credentials()
+	assert.Equal(t, ErrNoCredentials, err)
+}
+
+func TestEnvCredentials(t *testing.T) {
+	os.Setenv("GOEX_TEST_API_KEY", "envkey")
+	os.Setenv("GOEX_TEST_SECRET_KEY", "envsecret")
+	os.Setenv("GOEX_TEST_PASSPHRASE", "envpass")
+	defer func() {
+		os.Unsetenv("GOEX_TEST_API_KEY")
+		os.Unsetenv("GOEX_TEST_SECRET_KEY")
+		os.Unsetenv("GOEX_TEST_PASSPHRASE")
+	}()
+
+	c, err := NewEnvCredentials("goex_test").Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{ApiKey: "envkey", SecretKey: "envsecret", Passphrase: "envpass"}, c)
+
+	_, err = NewEnvCredentials("GOEX_MISSING").Credentials()
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+}
+
+func TestFileCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goex")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "credentials")
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	assert.Nil(t, EncryptCredentialsFile(path, key, Credentials{ApiKey: "filekey", SecretKey: "filesecret"}))
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	data, _ := ioutil.ReadFile(path)
+	assert.NotContains(t, string(data), "filesecret")
+
+	f, err := NewFileCredentials(path, key)
+	assert.Nil(t, err)
+	c, err := f.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "filekey", c.ApiKey)
+	assert.Equal(t, "filesecret", c.SecretKey)
+
+	// 替换文件即轮换凭证
+	assert.Nil(t, EncryptCredentialsFile(path, key, Credentials{ApiKey: "newkey", SecretKey: "newsecret"}))
+	later := time.Now().Add(time.Second)
+	assert.Nil(t, os.Chtimes(path, later, later))
+	c, err = f.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "newkey", c.ApiKey)
+
+	wrongKey := make([]byte, 32)
+	f, _ = NewFileCredentials(path, wrongKey)
+	_, err = f.Credentials()
+	assert.NotNil(t, err)
+
+	_, err = NewFileCredentials(path, key[:16])
+	assert.NotNil(t, err)
+}
+
+func TestAgentCredentials(t *testing.T) {
+	fail := false
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(Credentials{ApiKey: fmt.Sprintf("agentkey%d", calls), SecretKey: "agentsecret"})
+	}))
+	defer server.Close()
+
+	a := NewAgentCredentials(server.URL)
+	c, err := a.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "agentkey1", c.ApiKey)
+
+	// TTL内使用缓存
+	c, _ = a.Credentials()
+	assert.Equal(t, "agentkey1", c.ApiKey)
+	assert.Equal(t, 1, calls)
+
+	// 过期后刷新
+	a.TTL = 0
+	c, _ = a.Credentials()
+	assert.Equal(t, "agentkey2", c.ApiKey)
+
+	// 刷新失败时继续使用缓存
+	fail = true
+	c, err = a.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "agentkey2", c.ApiKey)
+
+	_, err = NewAgentCredentials(server.URL).Credentials()
+	assert.NotNil(t, err)
+}
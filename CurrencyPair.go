@@ -10,6 +10,7 @@ type ExSymbols map[string]Symbols
 
 var exSymbols ExSymbols
 
+// Deprecated: 使用SymbolMapper
 func GetExSymbols(exName string) Symbols {
 	ret, ok := exSymbols[exName]
 	if !ok {
@@ -18,6 +19,7 @@ func GetExSymbols(exName string) Symbols {
 	return ret
 }
 
+// Deprecated: 使用SymbolMapper, 交易对代码从交易所的交易品种列表获取
 func RegisterExSymbol(exName string, pair CurrencyPair) {
 	if exSymbols == nil {
 		exSymbols = make(ExSymbols)
@@ -160,6 +162,7 @@ func (c CurrencyPair) String() string {
 	return c.ToSymbol("_")
 }
 
+// Deprecated: 使用SymbolMapper.NativeCurrency
 func (c Currency) AdaptBchToBcc() Currency {
 	if c.Symbol == "BCH" || c.Symbol == "bch" {
 		return BCC
@@ -167,6 +170,7 @@ func (c Currency) AdaptBchToBcc() Currency {
 	return c
 }
 
+// Deprecated: 使用SymbolMapper.CanonicalCurrency
 func (c Currency) AdaptBccToBch() Currency {
 	if c.Symbol == "BCC" || c.Symbol == "bcc" {
 		return BCH
@@ -230,6 +234,7 @@ func (pair CurrencyPair) ToSymbol2(joinChar string) string {
 	return strings.Join([]string{pair.CurrencyB.Symbol, pair.CurrencyA.Symbol}, joinChar)
 }
 
+// Deprecated: 使用SymbolMapper, 找不到USD计价的交易品种时ToNative使用USDT计价
 func (pair CurrencyPair) AdaptUsdtToUsd() CurrencyPair {
 	CurrencyB := pair.CurrencyB
 	if pair.CurrencyB == USDT {
@@ -246,7 +251,7 @@ func (pair CurrencyPair) AdaptUsdToUsdt() CurrencyPair {
 	return CurrencyPair{pair.CurrencyA, CurrencyB}
 }
 
-//It is currently applicable to binance and zb
+// Deprecated: 使用SymbolMapper, 交易对代码从交易所的交易品种列表获取
 func (pair CurrencyPair) AdaptBchToBcc() CurrencyPair {
 	CurrencyA := pair.CurrencyA
 	if pair.CurrencyA == BCH {
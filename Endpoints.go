@@ -0,0 +1,306 @@
+package goex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+type endpointHost struct {
+	host      string
+	failures  int // 连续失败次数
+	downUntil time.Time
+	latency   time.Duration // 成功请求耗时的指数移动平均
+}
+
+/**
+ * 地址的健康状况
+ */
+type EndpointHealth struct {
+	Host      string
+	Healthy   bool
+	Failures  int
+	DownUntil time.Time
+	Latency   time.Duration
+}
+
+/**
+ * 交易所的一组等价API域名, 如api.binance.com、api1.binance.com. 请求发往其中任意一个域名时, 按健康状况选择实际的域名:
+ * 健康的域名按登记顺序优先, 连续失败FailureThreshold次的域名暂停Cooldown后再尝试.
+ * GET请求遇到网络错误、超时或5xx时依次换下一个域名重试; 其他请求只发往请求原来的域名一次, 避免重复下单.
+ * HedgeDelay大于0时, 不带签名的GET请求超过HedgeDelay没有响应会同时发往下一个域名, 取先成功的响应.
+ * HostSigned为true时(如火币的签名包含域名), 带签名的请求换了域名会验签失败, 只发往请求原来的域名.
+ * 限频、错误解析和钩子的交易所仍按请求原来的域名计算
+ */
+type EndpointGroup struct {
+	Exchange         string
+	FailureThreshold int
+	Cooldown         time.Duration
+	HedgeDelay       time.Duration // 0为不对冲
+	MaxAttempts      int           // GET请求最多尝试的域名数, 0为所有域名
+	HostSigned       bool          // 签名包含域名, 带签名的请求不换域名
+
+	lock  sync.Mutex
+	hosts []*endpointHost
+}
+
+func NewEndpointGroup(exchange string, hosts ...string) *EndpointGroup {
+	g := &EndpointGroup{
+		Exchange:         exchange,
+		FailureThreshold: 3,
+		Cooldown:         30 * time.Second,
+	}
+	for _, host := range hosts {
+		g.hosts = append(g.hosts, &endpointHost{host: host})
+	}
+	return g
+}
+
+func (g *EndpointGroup) Hosts() []string {
+	hosts := make([]string, len(g.hosts))
+	for i, h := range g.hosts {
+		hosts[i] = h.host
+	}
+	return hosts
+}
+
+func (g *EndpointGroup) Health() []EndpointHealth {
+	now := time.Now()
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	ret := make([]EndpointHealth, len(g.hosts))
+	for i, h := range g.hosts {
+		ret[i] = EndpointHealth{Host: h.host, Healthy: !now.Before(h.downUntil), Failures: h.failures, DownUntil: h.downUntil, Latency: h.latency}
+	}
+	return ret
+}
+
+/**
+ * 按优先顺序排列的域名: 健康的域名按登记顺序在前, 暂停的域名按恢复时间在后
+ */
+func (g *EndpointGroup) ordered() []string {
+	now := time.Now()
+	g.lock.Lock()
+	hosts := make([]*endpointHost, len(g.hosts))
+	copy(hosts, g.hosts)
+	downUntil := make(map[string]time.Time, len(hosts))
+	for _, h := range hosts {
+		downUntil[h.host] = h.downUntil
+	}
+	g.lock.Unlock()
+
+	sort.SliceStable(hosts, func(i, j int) bool {
+		di, dj := downUntil[hosts[i].host], downUntil[hosts[j].host]
+		healthyI, healthyJ := !now.Before(di), !now.Before(dj)
+		if healthyI != healthyJ {
+			return healthyI
+		}
+		return !healthyI && di.Before(dj)
+	})
+	ret := make([]string, len(hosts))
+	for i, h := range hosts {
+		ret[i] = h.host
+	}
+	return ret
+}
+
+/**
+ * 是否应该换一个域名重试: 网络错误、超时、交易所维护和5xx
+ */
+func shouldFailover(err error) bool {
+	var exErr *ExchangeError
+	if errors.As(err, &exErr) && exErr.HttpStatus >= 500 {
+		return true
+	}
+	switch ErrorCategoryOf(err) {
+	case ERR_NETWORK, ERR_MAINTENANCE:
+		return true
+	}
+	return false
+}
+
+/**
+ * 记录请求结果. 被取消的请求(如对冲中较慢的一方)不计入
+ */
+func (g *EndpointGroup) report(host string, latency time.Duration, err error) {
+	if err != nil && errors.Is(err, context.Canceled) {
+		return
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for _, h := range g.hosts {
+		if h.host != host {
+			continue
+		}
+		if err != nil && shouldFailover(err) {
+			h.failures++
+			if h.failures >= g.FailureThreshold {
+				h.downUntil = time.Now().Add(g.Cooldown)
+			}
+			return
+		}
+		h.failures = 0
+		h.downUntil = time.Time{}
+		if err == nil {
+			if h.latency == 0 {
+				h.latency = latency
+			} else {
+				h.latency = (h.latency*4 + latency) / 5
+			}
+		}
+		return
+	}
+}
+
+type endpointResult struct {
+	body   []byte
+	header http.Header
+	err    error
+}
+
+type endpointSender func(ctx context.Context, host string) ([]byte, http.Header, error)
+
+func (g *EndpointGroup) send(ctx context.Context, host string, fn endpointSender) endpointResult {
+	start := time.Now()
+	body, header, err := fn(ctx, host)
+	g.report(host, time.Since(start), err)
+	return endpointResult{body, header, err}
+}
+
+func (g *EndpointGroup) do(req *http.Request, fn endpointSender) ([]byte, http.Header, error) {
+	ctx := req.Context()
+	hosts := g.ordered()
+	if len(hosts) == 0 {
+		return nil, nil, errors.New("no endpoint for " + g.Exchange)
+	}
+	if req.Method != http.MethodGet || (g.HostSigned && isSignedRequest(req)) {
+		r := g.send(ctx, req.URL.Host, fn)
+		return r.body, r.header, r.err
+	}
+	if g.MaxAttempts > 0 && g.MaxAttempts < len(hosts) {
+		hosts = hosts[:g.MaxAttempts]
+	}
+	if g.HedgeDelay > 0 && len(hosts) > 1 && !isSignedRequest(req) {
+		return g.hedge(ctx, hosts, fn)
+	}
+
+	var r endpointResult
+	for _, host := range hosts {
+		r = g.send(ctx, host, fn)
+		if r.err == nil || !shouldFailover(r.err) || ctx.Err() != nil {
+			break
+		}
+	}
+	return r.body, r.header, r.err
+}
+
+/**
+ * 先发往第一个域名, 每过HedgeDelay没有成功响应或者请求失败时再发往下一个域名, 返回第一个成功的响应
+ */
+func (g *EndpointGroup) hedge(ctx context.Context, hosts []string, fn endpointSender) ([]byte, http.Header, error) {
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan endpointResult, len(hosts))
+	next, pending := 0, 0
+	launch := func() {
+		host := hosts[next]
+		next++
+		pending++
+		go func() {
+			results <- g.send(hedgeCtx, host, fn)
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(g.HedgeDelay)
+	defer timer.Stop()
+
+	var last endpointResult
+	for pending > 0 {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil || !shouldFailover(r.err) {
+				return r.body, r.header, r.err
+			}
+			last = r
+			if next < len(hosts) {
+				launch()
+			}
+		case <-timer.C:
+			if next < len(hosts) {
+				launch()
+				timer.Reset(g.HedgeDelay)
+			}
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+	return last.body, last.header, last.err
+}
+
+var (
+	endpointGroupsLock     sync.RWMutex
+	endpointGroups         = map[string]*EndpointGroup{} // host -> group
+	exchangeEndpointGroups = map[string]*EndpointGroup{} // exchange -> group
+)
+
+/**
+ * 注册交易所的域名组, 替换该交易所已注册的域名组. 组内的域名同时登记为该交易所的域名
+ */
+func RegisterEndpointGroup(group *EndpointGroup) {
+	endpointGroupsLock.Lock()
+	if old, ok := exchangeEndpointGroups[group.Exchange]; ok {
+		for _, host := range old.Hosts() {
+			delete(endpointGroups, host)
+		}
+	}
+	exchangeEndpointGroups[group.Exchange] = group
+	for _, host := range group.Hosts() {
+		endpointGroups[host] = group
+	}
+	endpointGroupsLock.Unlock()
+
+	RegisterExchangeHosts(group.Exchange, group.Hosts()...)
+}
+
+func UnregisterEndpointGroup(exchange string) {
+	endpointGroupsLock.Lock()
+	defer endpointGroupsLock.Unlock()
+	if old, ok := exchangeEndpointGroups[exchange]; ok {
+		for _, host := range old.Hosts() {
+			delete(endpointGroups, host)
+		}
+		delete(exchangeEndpointGroups, exchange)
+	}
+}
+
+func GetEndpointGroup(host string) *EndpointGroup {
+	endpointGroupsLock.RLock()
+	defer endpointGroupsLock.RUnlock()
+	return endpointGroups[host]
+}
+
+func GetExchangeEndpointGroup(exchange string) *EndpointGroup {
+	endpointGroupsLock.RLock()
+	defer endpointGroupsLock.RUnlock()
+	return exchangeEndpointGroups[exchange]
+}
+
+/**
+ * 交易所公布的备用域名, 默认只做故障切换, 不做对冲
+ */
+func init() {
+	// https://binance-docs.github.io/apidocs/spot/cn/#api
+	RegisterEndpointGroup(NewEndpointGroup(BINANCE, "api.binance.com", "api1.binance.com", "api2.binance.com", "api3.binance.com"))
+	RegisterEndpointGroup(NewEndpointGroup(OKEX, "www.okex.com", "aws.okex.com"))
+	// 火币的签名包含域名, 只有公开行情请求换域名
+	huobi := NewEndpointGroup(HUOBI_PRO, "api.huobi.br.com", "api.huobi.pro", "api-aws.huobi.pro")
+	huobi.HostSigned = true
+	RegisterEndpointGroup(huobi)
+}
@@ -0,0 +1,197 @@
+package goex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type endpointServer struct {
+	*httptest.Server
+	hits int32
+}
+
+func newEndpointServer(handler func(w http.ResponseWriter, r *http.Request)) *endpointServer {
+	s := &endpointServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.hits, 1)
+		handler(w, r)
+	}))
+	return s
+}
+
+func (s *endpointServer) host() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func (s *endpointServer) hitCount() int {
+	return int(atomic.LoadInt32(&s.hits))
+}
+
+func TestEndpointGroup_Failover(t *testing.T) {
+	bad := newEndpointServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer bad.Close()
+	good := newEndpointServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"host":"good"}`))
+	})
+	defer good.Close()
+
+	group := NewEndpointGroup("endpoint_test", bad.host(), good.host())
+	group.FailureThreshold = 2
+	RegisterEndpointGroup(group)
+	defer UnregisterEndpointGroup("endpoint_test")
+
+	body, err := NewHttpRequest(http.DefaultClient, "GET", bad.URL+"/api/ticker", "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"host":"good"}`, string(body))
+	assert.Equal(t, 1, group.Health()[0].Failures)
+	assert.True(t, group.Health()[0].Healthy)
+
+	NewHttpRequest(http.DefaultClient, "GET", bad.URL+"/api/ticker", "", nil)
+	assert.False(t, group.Health()[0].Healthy)
+	assert.Equal(t, 2, bad.hitCount())
+
+	// 暂停期间直接使用健康的域名
+	body, err = NewHttpRequest(http.DefaultClient, "GET", bad.URL+"/api/ticker", "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"host":"good"}`, string(body))
+	assert.Equal(t, 2, bad.hitCount())
+	assert.Equal(t, 3, good.hitCount())
+	assert.Equal(t, group, GetEndpointGroup(good.host()))
+}
+
+func TestEndpointGroup_NoFailover(t *testing.T) {
+	first := newEndpointServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	defer first.Close()
+	second := newEndpointServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	})
+	defer second.Close()
+
+	RegisterEndpointGroup(NewEndpointGroup("endpoint_test", first.host(), second.host()))
+	defer UnregisterEndpointGroup("endpoint_test")
+
+	// 非GET请求只发送一次
+	_, err := NewHttpRequest(http.DefaultClient, "POST", first.URL+"/api/order", "a=1", nil)
+	assert.NotNil(t, err)
+	// 4xx不切换域名
+	_, err = NewHttpRequest(http.DefaultClient, "GET", first.URL+"/api/order", "", nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, first.hitCount())
+	assert.Equal(t, 0, second.hitCount())
+}
+
+func TestEndpointGroup_Hedge(t *testing.T) {
+	slow := newEndpointServer(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte("slow"))
+	})
+	defer slow.Close()
+	fast := newEndpointServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fast"))
+	})
+	defer fast.Close()
+
+	group := NewEndpointGroup("endpoint_test", slow.host(), fast.host())
+	group.HedgeDelay = 20 * time.Millisecond
+	RegisterEndpointGroup(group)
+	defer UnregisterEndpointGroup("endpoint_test")
+
+	start := time.Now()
+	body, err := NewHttpRequest(http.DefaultClient, "GET", slow.URL+"/api/depth", "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "fast", string(body))
+	assert.True(t, time.Since(start) < 200*time.Millisecond)
+
+	// 被取消的慢请求不算失败
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 0, group.Health()[0].Failures)
+
+	// 带签名的请求不对冲
+	body, err = NewHttpRequest(http.DefaultClient, "GET", slow.URL+"/api/account?timestamp=1&signature=abc", "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "slow", string(body))
+	assert.Equal(t, 1, fast.hitCount())
+}
+
+func TestEndpointGroup_HostSigned(t *testing.T) {
+	first := newEndpointServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer first.Close()
+	second := newEndpointServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	})
+	defer second.Close()
+
+	group := NewEndpointGroup("endpoint_test", first.host(), second.host())
+	group.FailureThreshold = 1
+	group.HostSigned = true
+	RegisterEndpointGroup(group)
+	defer UnregisterEndpointGroup("endpoint_test")
+
+	// 公开行情照常切换域名, 之后第一个域名处于暂停状态
+	_, err := NewHttpRequest(http.DefaultClient, "GET", first.URL+"/market/tickers", "", nil)
+	assert.Nil(t, err)
+	assert.False(t, group.Health()[0].Healthy)
+
+	// 签名包含域名, 带签名的请求即使域名暂停也不换域名
+	_, err = NewHttpRequest(http.DefaultClient, "GET", first.URL+"/v1/account/accounts?AccessKeyId=k&Signature=s", "", nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, first.hitCount())
+	assert.Equal(t, 1, second.hitCount())
+}
+
+func TestEndpointGroup_NonGetRequestedHost(t *testing.T) {
+	first := newEndpointServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("first"))
+	})
+	defer first.Close()
+	second := newEndpointServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("second"))
+	})
+	defer second.Close()
+
+	group := NewEndpointGroup("endpoint_test", first.host(), second.host())
+	group.FailureThreshold = 1
+	RegisterEndpointGroup(group)
+	defer UnregisterEndpointGroup("endpoint_test")
+
+	// 第二个域名排到前面后, 下单仍然发往请求的域名
+	NewHttpRequest(http.DefaultClient, "GET", first.URL+"/api/ticker", "", nil)
+	assert.False(t, group.Health()[0].Healthy)
+	body, err := NewHttpRequest(http.DefaultClient, "POST", second.URL+"/api/order", "a=1", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "second", string(body))
+	body, err = NewHttpRequest(http.DefaultClient, "POST", first.URL+"/api/order", "a=1", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "first", string(body))
+}
+
+func TestEndpointGroup_HuobiHosts(t *testing.T) {
+	group := GetEndpointGroup("api.huobi.br.com")
+	if assert.NotNil(t, group) {
+		assert.Equal(t, group, GetEndpointGroup("api.huobi.pro"))
+		assert.True(t, group.HostSigned)
+	}
+}
@@ -0,0 +1,139 @@
+package goex
+
+import "sort"
+
+/**
+ * 现货成交明细接口. pair为统一交易对, from/to为毫秒时间戳, 0表示不限制, limit为每次请求的条数, 必须大于0.
+ * GetFills返回[from, to]内的一页成交, GetAllFills翻页返回[from, to]内的全部成交, 按成交时间升序
+ */
+type FillsAPI interface {
+	GetFills(pair CurrencyPair, from, to int64, limit int) ([]FillDecimal, error)
+	GetAllFills(pair CurrencyPair, from, to int64, limit int) ([]FillDecimal, error)
+}
+
+// 按时间范围分页获取成交记录, 返回 [from, to] 内最多 limit 条
+type FillsPageFunc func(from, to int64, limit int) ([]FillDecimal, error)
+
+// 按页码分页获取成交记录, page从1开始
+type FillsPagedFunc func(page, limit int) ([]FillDecimal, error)
+
+// 按游标分页获取成交记录, 返回下一页的游标, 游标为空表示没有更多数据
+type FillsCursorFunc func(cursor string, limit int) ([]FillDecimal, string, error)
+
+/**
+ * 按成交ID去重, 并按成交时间升序排列
+ */
+func MergeFills(lists ...[]FillDecimal) []FillDecimal {
+	seen := make(map[string]bool)
+	var ret []FillDecimal
+	for _, l := range lists {
+		for _, f := range l {
+			key := f.OrderId + ":" + f.FillId
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			ret = append(ret, f)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].TransactionTime < ret[j].TransactionTime
+	})
+	return ret
+}
+
+func fillKey(r interface{}) string {
+	f := r.(FillDecimal)
+	return f.OrderId + ":" + f.FillId
+}
+
+func fillTime(r interface{}) int64 {
+	return r.(FillDecimal).TransactionTime
+}
+
+func getAllFills(p *Paginator) ([]FillDecimal, error) {
+	records, err := p.All()
+	fills := make([]FillDecimal, len(records))
+	for i, r := range records {
+		fills[i] = r.(FillDecimal)
+	}
+	return MergeFills(fills), err
+}
+
+func toRecords(fills []FillDecimal) []interface{} {
+	records := make([]interface{}, len(fills))
+	for i, f := range fills {
+		records[i] = f
+	}
+	return records
+}
+
+/**
+ * 从from开始按时间向后翻页, 直到返回的记录少于limit
+ * 相邻两页边界上同一毫秒的记录会重复返回, 按成交ID去重
+ */
+func GetAllFillsByTime(from, to int64, limit int, fetch FillsPageFunc) ([]FillDecimal, error) {
+	return getAllFills(NewPaginator(PAGING_BY_TIME_ASC, limit, from, to, func(q PageQuery) ([]interface{}, string, error) {
+		fills, err := fetch(q.From, q.To, q.Limit)
+		return toRecords(fills), "", err
+	}, fillKey, fillTime))
+}
+
+/**
+ * 按页码翻页, 直到返回的记录少于limit. 交易所按时间倒序返回时, 遇到早于from的记录停止
+ */
+func GetAllFillsByPage(from, to int64, limit int, fetch FillsPagedFunc) ([]FillDecimal, error) {
+	return getAllFills(NewPaginator(PAGING_BY_PAGE, limit, from, to, func(q PageQuery) ([]interface{}, string, error) {
+		fills, err := fetch(q.Page, q.Limit)
+		return toRecords(fills), "", err
+	}, fillKey, fillTime))
+}
+
+/**
+ * 按游标翻页, 直到游标为空或返回空页
+ */
+func GetAllFillsByCursor(limit int, fetch FillsCursorFunc) ([]FillDecimal, error) {
+	return getAllFills(cursorFillsPaginator(0, 0, limit, fetch))
+}
+
+/**
+ * 按游标翻页, 只保留[from, to]内的成交, 遇到早于from的成交停止. 用于不支持按时间查询的交易所
+ */
+func GetAllFillsByCursorInRange(from, to int64, limit int, fetch FillsCursorFunc) ([]FillDecimal, error) {
+	return getAllFills(cursorFillsPaginator(from, to, limit, fetch))
+}
+
+/**
+ * 按游标从最新的成交向前翻页, 返回[from, to]内最新的最多limit条成交
+ */
+func GetFillsByCursorInRange(from, to int64, limit int, fetch FillsCursorFunc) ([]FillDecimal, error) {
+	if limit <= 0 {
+		return nil, ErrPaginatorMisconfigured
+	}
+	p := cursorFillsPaginator(from, to, limit, fetch)
+	var fills []FillDecimal
+	for len(fills) < limit && !p.Done() {
+		records, err := p.Next()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			fills = append(fills, r.(FillDecimal))
+		}
+	}
+	if len(fills) > limit {
+		fills = fills[:limit]
+	}
+	return MergeFills(fills), nil
+}
+
+func cursorFillsPaginator(from, to int64, limit int, fetch FillsCursorFunc) *Paginator {
+	var tm func(interface{}) int64
+	if from > 0 || to > 0 {
+		tm = fillTime
+	}
+	return NewPaginator(PAGING_BY_CURSOR, limit, from, to, func(q PageQuery) ([]interface{}, string, error) {
+		fills, next, err := fetch(q.Cursor, q.Limit)
+		return toRecords(fills), next, err
+	}, fillKey, tm)
+}
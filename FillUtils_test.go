@@ -0,0 +1,107 @@
+package goex
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAllFillsByTime(t *testing.T) {
+	var all []FillDecimal
+	for i := 0; i < 25; i++ {
+		all = append(all, FillDecimal{FillId: fmt.Sprint(i), TransactionTime: int64(1000 + i/2)})
+	}
+
+	var calls int
+	fills, err := GetAllFillsByTime(1000, 0, 10, func(from, to int64, limit int) ([]FillDecimal, error) {
+		calls++
+		var page []FillDecimal
+		for _, f := range all {
+			if f.TransactionTime >= from && len(page) < limit {
+				page = append(page, f)
+			}
+		}
+		return page, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 25, len(fills))
+	assert.Equal(t, "24", fills[24].FillId)
+	assert.True(t, calls > 2)
+}
+
+func TestGetAllFillsByCursor(t *testing.T) {
+	pages := map[string][]FillDecimal{
+		"":  {{FillId: "3", TransactionTime: 3}, {FillId: "2", TransactionTime: 2}},
+		"2": {{FillId: "2", TransactionTime: 2}, {FillId: "1", TransactionTime: 1}},
+		"1": nil,
+	}
+	fills, err := GetAllFillsByCursor(2, func(cursor string, limit int) ([]FillDecimal, string, error) {
+		page := pages[cursor]
+		if len(page) == 0 {
+			return nil, "", nil
+		}
+		return page, page[len(page)-1].FillId, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(fills))
+	assert.Equal(t, "1", fills[0].FillId)
+}
+
+func TestGetAllFillsByPage(t *testing.T) {
+	// 按时间倒序的页码翻页, 遇到早于from的成交停止
+	var calls int
+	fills, err := GetAllFillsByPage(3, 0, 2, func(page, limit int) ([]FillDecimal, error) {
+		calls++
+		all := []FillDecimal{{FillId: "5", TransactionTime: 5}, {FillId: "4", TransactionTime: 4}, {FillId: "3", TransactionTime: 3}, {FillId: "2", TransactionTime: 2}, {FillId: "1", TransactionTime: 1}}
+		start := (page - 1) * limit
+		if start >= len(all) {
+			return nil, nil
+		}
+		end := start + limit
+		if end > len(all) {
+			end = len(all)
+		}
+		return all[start:end], nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(fills))
+	assert.Equal(t, "3", fills[0].FillId)
+	assert.Equal(t, 2, calls)
+
+	_, err = GetAllFillsByPage(0, 0, 0, func(page, limit int) ([]FillDecimal, error) {
+		t.Fatal("fetch with invalid limit")
+		return nil, nil
+	})
+	assert.Equal(t, ErrPaginatorMisconfigured, err)
+}
+
+func TestGetFillsByCursorInRange(t *testing.T) {
+	pages := map[string][]FillDecimal{
+		"":  {{FillId: "6", TransactionTime: 6}, {FillId: "5", TransactionTime: 5}},
+		"5": {{FillId: "4", TransactionTime: 4}, {FillId: "3", TransactionTime: 3}},
+		"3": {{FillId: "2", TransactionTime: 2}, {FillId: "1", TransactionTime: 1}},
+	}
+	fetch := func(cursor string, limit int) ([]FillDecimal, string, error) {
+		page := pages[cursor]
+		if len(page) == 0 {
+			return nil, "", nil
+		}
+		return page, page[len(page)-1].FillId, nil
+	}
+
+	// to之后的成交被跳过, 取最新的limit条
+	fills, err := GetFillsByCursorInRange(2, 5, 2, fetch)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(fills))
+	assert.Equal(t, "4", fills[0].FillId)
+	assert.Equal(t, "5", fills[1].FillId)
+
+	fills, err = GetAllFillsByCursorInRange(2, 5, 2, fetch)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(fills))
+	assert.Equal(t, "2", fills[0].FillId)
+
+	_, err = GetFillsByCursorInRange(0, 0, 0, fetch)
+	assert.Equal(t, ErrPaginatorMisconfigured, err)
+}
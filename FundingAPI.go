@@ -0,0 +1,139 @@
+package goex
+
+import "github.com/shopspring/decimal"
+
+// 钱包类型
+type WalletType string
+
+const (
+	WALLET_SPOT    WalletType = "spot"    // 币币账户
+	WALLET_MARGIN  WalletType = "margin"  // 杠杆账户
+	WALLET_FUTURE  WalletType = "future"  // 交割合约账户
+	WALLET_SWAP    WalletType = "swap"    // 永续合约账户
+	WALLET_FUNDING WalletType = "funding" // 资金账户
+)
+
+// 充提状态
+type FundingStatus int
+
+const (
+	FUNDING_PENDING    FundingStatus = iota // 已提交, 等待审核或确认
+	FUNDING_PROCESSING                      // 处理中, 已上链等待确认
+	FUNDING_SUCCESS                         // 已完成
+	FUNDING_FAILED                          // 失败或被拒绝
+	FUNDING_CANCELED                        // 已撤销
+)
+
+var fundingStatusSymbol = [...]string{"PENDING", "PROCESSING", "SUCCESS", "FAILED", "CANCELED"}
+
+func (s FundingStatus) String() string {
+	if s < 0 || int(s) >= len(fundingStatusSymbol) {
+		return "UNKNOWN"
+	}
+	return fundingStatusSymbol[s]
+}
+
+func (s FundingStatus) IsFinal() bool {
+	return s == FUNDING_SUCCESS || s == FUNDING_FAILED || s == FUNDING_CANCELED
+}
+
+type FundingType int
+
+const (
+	FUNDING_DEPOSIT FundingType = 1 + iota
+	FUNDING_WITHDRAW
+)
+
+type DepositAddress struct {
+	Currency Currency
+	Address  string
+	Tag      string // memo/tag/payment id, 不需要时为空
+	Chain    string // 同一币种有多条链时区分, 如USDT的OMNI/ERC20
+}
+
+// 充值或提现记录
+type FundingRecord struct {
+	Id        string
+	Type      FundingType
+	Currency  Currency
+	Amount    decimal.Decimal
+	Fee       decimal.Decimal
+	Address   string
+	Tag       string
+	TxId      string
+	Status    FundingStatus
+	RawStatus string // 交易所原始状态
+	Timestamp int64  // 毫秒
+}
+
+type WithdrawFeeDecimal struct {
+	Currency  Currency
+	Chain     string
+	MinFee    decimal.Decimal
+	MaxFee    decimal.Decimal
+	MinAmount decimal.Decimal
+}
+
+type WithdrawParam struct {
+	Currency Currency
+	Amount   decimal.Decimal
+	Address  string          // 必须是交易所白名单中的地址
+	Tag      string          // memo/tag/payment id, 不需要时为空
+	Chain    string          // 链名称, 币种有多条链且需要查询手续费时必须指定
+	Fee      decimal.Decimal // 部分交易所需要指定手续费, 为零时使用交易所的最低手续费
+	TradePwd string          // 部分交易所需要资金密码
+}
+
+/**
+ * 充提币和账户间划转接口
+ */
+type FundingAPI interface {
+	GetExchangeName() string
+
+	/**
+	 * 获取充值地址, 同一币种有多条链时返回多个地址
+	 */
+	GetDepositAddress(currency Currency) ([]DepositAddress, error)
+
+	/**
+	 * 获取最近的充值记录
+	 */
+	GetDeposits(currency Currency) ([]FundingRecord, error)
+
+	/**
+	 * 提币, 返回提币ID
+	 */
+	CreateWithdrawal(param WithdrawParam) (string, error)
+
+	/**
+	 * 获取最近的提币记录
+	 */
+	GetWithdrawals(currency Currency) ([]FundingRecord, error)
+
+	/**
+	 * 查询提币状态
+	 */
+	GetWithdrawal(currency Currency, withdrawId string) (*FundingRecord, error)
+
+	/**
+	 * 获取提币手续费, currency为UNKNOWN时返回全部币种
+	 */
+	GetWithdrawalFees(currency Currency) ([]WithdrawFeeDecimal, error)
+
+	/**
+	 * 在同一交易所的不同钱包之间划转, 返回划转ID
+	 */
+	TransferBetweenWallets(currency Currency, amount decimal.Decimal, from, to WalletType) (string, error)
+}
+
+/**
+ * 在提币记录中查找指定ID的记录
+ */
+func FindFundingRecord(records []FundingRecord, id string) (*FundingRecord, error) {
+	for i := range records {
+		if records[i].Id == id {
+			return &records[i], nil
+		}
+	}
+	return nil, EX_ERR_NOT_FIND_RECORD
+}
@@ -1,5 +1,7 @@
 package goex
 
+import "context"
+
 type FutureRestAPI interface {
 	/**
 	 *获取交易所名字
@@ -101,3 +103,25 @@ type FutureRestAPI interface {
 	 */
 	GetKlineRecords(contract_type string, currency CurrencyPair, period string, size, since int) ([]FutureKline, error)
 }
+
+/**
+ * FutureRestAPI的context版本, ctx用于设置超时和取消请求, 见WithFutureContext
+ */
+type FutureRestAPIContext interface {
+	GetExchangeName() string
+	GetFutureEstimatedPriceWithContext(ctx context.Context, currencyPair CurrencyPair) (float64, error)
+	GetFutureTickerWithContext(ctx context.Context, currencyPair CurrencyPair, contractType string) (*Ticker, error)
+	GetFutureDepthWithContext(ctx context.Context, currencyPair CurrencyPair, contractType string, size int) (*Depth, error)
+	GetFutureIndexWithContext(ctx context.Context, currencyPair CurrencyPair) (float64, error)
+	GetFutureUserinfoWithContext(ctx context.Context) (*FutureAccount, error)
+	PlaceFutureOrderWithContext(ctx context.Context, currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice, leverRate int) (string, error)
+	FutureCancelOrderWithContext(ctx context.Context, currencyPair CurrencyPair, contractType, orderId string) (bool, error)
+	GetFuturePositionWithContext(ctx context.Context, currencyPair CurrencyPair, contractType string) ([]FuturePosition, error)
+	GetFutureOrdersWithContext(ctx context.Context, orderIds []string, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error)
+	GetUnfinishFutureOrdersWithContext(ctx context.Context, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error)
+	GetFeeWithContext(ctx context.Context) (float64, error)
+	GetExchangeRateWithContext(ctx context.Context) (float64, error)
+	GetContractValueWithContext(ctx context.Context, currencyPair CurrencyPair) (float64, error)
+	GetDeliveryTime() (int, int, int, int)
+	GetKlineRecordsWithContext(ctx context.Context, contract_type string, currency CurrencyPair, period string, size, since int) ([]FutureKline, error)
+}
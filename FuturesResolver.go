@@ -0,0 +1,318 @@
+package goex
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrContractNotListed = errors.New("contract not listed")
+
+const contractWeek = 7 * 24 * time.Hour
+
+/**
+ * 按到期时间把交割合约映射为合约类型, 键为THIS_WEEK_CONTRACT等. 到期时间在now+ahead之后一周内的为周合约,
+ * 一到两周的为次周合约, 两周以后最近的为季度合约, 其次为次季度合约; 到期时间早于now+ahead的合约视为已交割.
+ * 交割前两周内的季度合约会变为周合约或次周合约, 与OKEx、火币的规则一致; 只有季度合约的交易所(如BitMEX)同样处理
+ */
+func ClassifyContracts(instruments []Instrument, now time.Time, ahead time.Duration) map[string]Instrument {
+	effective := now.Add(ahead)
+	var live []Instrument
+	for _, inst := range instruments {
+		if inst.Type != INSTRUMENT_FUTURE || inst.Expiry.IsZero() || inst.Status == INSTRUMENT_STATUS_DELISTED {
+			continue
+		}
+		if inst.Expiry.After(effective) {
+			live = append(live, inst)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool {
+		return live[i].Expiry.Before(live[j].Expiry)
+	})
+
+	ret := make(map[string]Instrument, 4)
+	quarters := []string{QUARTER_CONTRACT, BI_QUARTER_CONTRACT}
+	for _, inst := range live {
+		remain := inst.Expiry.Sub(effective)
+		var contractType string
+		switch {
+		case remain <= contractWeek:
+			contractType = THIS_WEEK_CONTRACT
+		case remain <= 2*contractWeek:
+			contractType = NEXT_WEEK_CONTRACT
+		case len(quarters) > 0:
+			contractType = quarters[0]
+			quarters = quarters[1:]
+		default:
+			continue
+		}
+		if _, ok := ret[contractType]; !ok {
+			ret[contractType] = inst
+		}
+	}
+	return ret
+}
+
+/**
+ * 交割合约换月事件, From为即将交割的合约, To为接替它的合约
+ */
+type ContractRoll struct {
+	Exchange     string
+	Pair         CurrencyPair
+	ContractType string
+	From         Instrument
+	To           Instrument
+}
+
+type futuresKey struct {
+	base         string
+	quote        string
+	contractType string
+}
+
+/**
+ * 把交易对和合约类型(this_week/next_week/quarter/bi_quarter)解析为具体的交割合约, 如BTC-USD-190628.
+ * 交易品种来自InstrumentCatalog, OKEx V3的GetUnifiedInstruments以及火币和BitMEX的GetInstruments都提供到期时间.
+ * Watch的合约在交割前RollAhead切换到接替的合约, 并通知SubscribeRoll注册的回调,
+ * 行情订阅和策略可以在回调中迁移到新合约
+ */
+type FuturesResolver struct {
+	Exchange  string
+	Catalog   *InstrumentCatalog
+	RollAhead time.Duration
+
+	lock        sync.Mutex
+	watched     map[futuresKey]Instrument
+	pending     bool // 有合约等待上市, 下次检查前刷新目录
+	rollHandles []func(ContractRoll)
+	errorHandle func(error)
+	running     bool
+	stopChan    chan struct{}
+}
+
+func NewFuturesResolver(exchange string, catalog *InstrumentCatalog) *FuturesResolver {
+	return &FuturesResolver{
+		Exchange:  exchange,
+		Catalog:   catalog,
+		RollAhead: 10 * time.Minute,
+		watched:   make(map[futuresKey]Instrument),
+	}
+}
+
+func newFuturesKey(pair CurrencyPair, contractType string) futuresKey {
+	return futuresKey{
+		base:         CanonicalCurrency(pair.CurrencyA).Symbol,
+		quote:        CanonicalCurrency(pair.CurrencyB).Symbol,
+		contractType: strings.ToLower(contractType),
+	}
+}
+
+func (r *FuturesResolver) contracts(key futuresKey, now time.Time) (map[string]Instrument, error) {
+	instruments, err := r.Catalog.All()
+	if err != nil {
+		return nil, err
+	}
+	var matched []Instrument
+	for _, inst := range instruments {
+		if CanonicalCurrency(inst.Pair.CurrencyA).Symbol == key.base && CanonicalCurrency(inst.Pair.CurrencyB).Symbol == key.quote {
+			matched = append(matched, inst)
+		}
+	}
+	return ClassifyContracts(matched, now, r.RollAhead), nil
+}
+
+func (r *FuturesResolver) resolve(key futuresKey, now time.Time) (Instrument, error) {
+	contracts, err := r.contracts(key, now)
+	if err != nil {
+		return Instrument{}, err
+	}
+	inst, ok := contracts[key.contractType]
+	if !ok {
+		return Instrument{}, fmt.Errorf("%s %s_%s %s: %w", r.Exchange, key.base, key.quote, key.contractType, ErrContractNotListed)
+	}
+	return inst, nil
+}
+
+/**
+ * 当前时间的合约
+ */
+func (r *FuturesResolver) Resolve(pair CurrencyPair, contractType string) (Instrument, error) {
+	return r.ResolveAt(pair, contractType, time.Now())
+}
+
+func (r *FuturesResolver) ResolveAt(pair CurrencyPair, contractType string, now time.Time) (Instrument, error) {
+	return r.resolve(newFuturesKey(pair, contractType), now)
+}
+
+/**
+ * 合约代码当前对应的合约类型, 已交割或不是交割合约时返回ErrContractNotListed
+ */
+func (r *FuturesResolver) ContractTypeOf(symbol string) (string, error) {
+	inst, err := r.Catalog.Get(symbol)
+	if err != nil {
+		return "", err
+	}
+	contracts, err := r.contracts(newFuturesKey(inst.Pair, ""), time.Now())
+	if err != nil {
+		return "", err
+	}
+	for contractType, c := range contracts {
+		if strings.EqualFold(c.Symbol, inst.Symbol) {
+			return contractType, nil
+		}
+	}
+	return "", fmt.Errorf("%s %s: %w", r.Exchange, symbol, ErrContractNotListed)
+}
+
+/**
+ * 开始跟踪合约类型, 返回当前的合约. 换月时通知SubscribeRoll注册的回调
+ */
+func (r *FuturesResolver) Watch(pair CurrencyPair, contractType string) (Instrument, error) {
+	return r.WatchAt(pair, contractType, time.Now())
+}
+
+func (r *FuturesResolver) WatchAt(pair CurrencyPair, contractType string, now time.Time) (Instrument, error) {
+	key := newFuturesKey(pair, contractType)
+	inst, err := r.resolve(key, now)
+	if err != nil {
+		return inst, err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if current, ok := r.watched[key]; ok {
+		return current, nil
+	}
+	r.watched[key] = inst
+	return inst, nil
+}
+
+func (r *FuturesResolver) Unwatch(pair CurrencyPair, contractType string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.watched, newFuturesKey(pair, contractType))
+}
+
+func (r *FuturesResolver) SubscribeRoll(handle func(ContractRoll)) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.rollHandles = append(r.rollHandles, handle)
+}
+
+func (r *FuturesResolver) SetErrorHandler(handle func(error)) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.errorHandle = handle
+}
+
+/**
+ * 按now重新解析跟踪的合约, 返回并通知发生的换月. 接替的合约还没有上市时保持原合约, 下次检查时再试
+ */
+func (r *FuturesResolver) Check(now time.Time) ([]ContractRoll, error) {
+	r.lock.Lock()
+	keys := make([]futuresKey, 0, len(r.watched))
+	for key := range r.watched {
+		keys = append(keys, key)
+	}
+	r.lock.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	var rolls []ContractRoll
+	pending := false
+	for _, key := range keys {
+		inst, err := r.resolve(key, now)
+		if errors.Is(err, ErrContractNotListed) {
+			pending = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		r.lock.Lock()
+		current, ok := r.watched[key]
+		if ok && !strings.EqualFold(current.Symbol, inst.Symbol) {
+			r.watched[key] = inst
+			rolls = append(rolls, ContractRoll{
+				Exchange:     r.Exchange,
+				Pair:         NewCurrencyPair(NewCurrency(key.base, ""), NewCurrency(key.quote, "")),
+				ContractType: key.contractType,
+				From:         current,
+				To:           inst,
+			})
+		}
+		r.lock.Unlock()
+	}
+
+	r.lock.Lock()
+	r.pending = pending
+	handles := r.rollHandles
+	r.lock.Unlock()
+	for _, roll := range rolls {
+		for _, handle := range handles {
+			handle(roll)
+		}
+	}
+	return rolls, nil
+}
+
+/**
+ * 启动后台检查, 已启动时不做任何事. 有合约等待上市时每次检查前刷新目录
+ */
+func (r *FuturesResolver) Start(interval time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.running {
+		return
+	}
+	r.running = true
+	r.stopChan = make(chan struct{})
+	go r.loop(interval, r.stopChan)
+}
+
+func (r *FuturesResolver) Stop() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.running {
+		return
+	}
+	r.running = false
+	close(r.stopChan)
+}
+
+func (r *FuturesResolver) loop(interval time.Duration, stopChan chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopChan:
+			return
+		case <-ticker.C:
+		}
+
+		r.lock.Lock()
+		pending := r.pending
+		r.lock.Unlock()
+		var err error
+		if pending {
+			err = r.Catalog.Refresh()
+		}
+		if err == nil {
+			_, err = r.Check(time.Now())
+		}
+		if err != nil {
+			r.lock.Lock()
+			errorHandle := r.errorHandle
+			r.lock.Unlock()
+			if errorHandle != nil {
+				errorHandle(err)
+			} else {
+				DefaultLogger().Warn("check futures contracts failed", "exchange", r.Exchange, "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package goex
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 2019-06-14(周五) 16:00北京时间交割的周合约
+var testDelivery = time.Date(2019, 6, 14, 8, 0, 0, 0, time.UTC)
+
+func testFuture(symbol string, pair CurrencyPair, expiry time.Time) Instrument {
+	return Instrument{Symbol: symbol, Pair: pair, Type: INSTRUMENT_FUTURE, Expiry: expiry, Status: INSTRUMENT_STATUS_TRADING}
+}
+
+func testFutures() []Instrument {
+	return []Instrument{
+		testFuture("BTC-USD-190614", BTC_USD, testDelivery),
+		testFuture("BTC-USD-190621", BTC_USD, testDelivery.Add(contractWeek)),
+		testFuture("BTC-USD-190628", BTC_USD, testDelivery.Add(2*contractWeek)),
+		testFuture("BTC-USD-190927", BTC_USD, time.Date(2019, 9, 27, 8, 0, 0, 0, time.UTC)),
+		testFuture("ETH-USD-190614", ETH_USD, testDelivery),
+		{Symbol: "BTC-USD-SWAP", Pair: BTC_USD, Type: INSTRUMENT_SWAP},
+	}
+}
+
+func TestClassifyContracts(t *testing.T) {
+	contracts := ClassifyContracts(testFutures()[:4], testDelivery.Add(-3*24*time.Hour), 10*time.Minute)
+	assert.Equal(t, "BTC-USD-190614", contracts[THIS_WEEK_CONTRACT].Symbol)
+	assert.Equal(t, "BTC-USD-190621", contracts[NEXT_WEEK_CONTRACT].Symbol)
+	assert.Equal(t, "BTC-USD-190628", contracts[QUARTER_CONTRACT].Symbol)
+	assert.Equal(t, "BTC-USD-190927", contracts[BI_QUARTER_CONTRACT].Symbol)
+
+	// 交割前5分钟, 周合约已切换到原次周合约, 新的次周合约还没有上市
+	contracts = ClassifyContracts(testFutures()[:4], testDelivery.Add(-5*time.Minute), 10*time.Minute)
+	assert.Equal(t, "BTC-USD-190621", contracts[THIS_WEEK_CONTRACT].Symbol)
+	assert.Equal(t, "BTC-USD-190628", contracts[NEXT_WEEK_CONTRACT].Symbol)
+	assert.Equal(t, "BTC-USD-190927", contracts[QUARTER_CONTRACT].Symbol)
+	_, ok := contracts[BI_QUARTER_CONTRACT]
+	assert.False(t, ok)
+}
+
+func TestFuturesResolver_Roll(t *testing.T) {
+	api := &fakeInstrumentAPI{instruments: testFutures()}
+	r := NewFuturesResolver("test", NewInstrumentCatalog("test", api))
+	var rolls []ContractRoll
+	r.SubscribeRoll(func(roll ContractRoll) {
+		rolls = append(rolls, roll)
+	})
+
+	inst, err := r.ResolveAt(CurrencyPair{XBT, USD}, THIS_WEEK_CONTRACT, testDelivery.Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, "BTC-USD-190614", inst.Symbol)
+	_, err = r.ResolveAt(ETH_USD, QUARTER_CONTRACT, testDelivery.Add(-time.Hour))
+	assert.True(t, errors.Is(err, ErrContractNotListed))
+
+	inst, err = r.WatchAt(BTC_USD, THIS_WEEK_CONTRACT, testDelivery.Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, "BTC-USD-190614", inst.Symbol)
+	inst, err = r.WatchAt(BTC_USD, NEXT_WEEK_CONTRACT, testDelivery.Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, "BTC-USD-190621", inst.Symbol)
+
+	ret, err := r.Check(testDelivery.Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ret))
+
+	ret, err = r.Check(testDelivery.Add(-5 * time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ret))
+	assert.Equal(t, 2, len(rolls))
+	assert.Equal(t, NEXT_WEEK_CONTRACT, rolls[0].ContractType)
+	assert.Equal(t, "BTC-USD-190621", rolls[0].From.Symbol)
+	assert.Equal(t, "BTC-USD-190628", rolls[0].To.Symbol)
+	assert.Equal(t, THIS_WEEK_CONTRACT, rolls[1].ContractType)
+	assert.Equal(t, "BTC-USD-190614", rolls[1].From.Symbol)
+	assert.Equal(t, "BTC-USD-190621", rolls[1].To.Symbol)
+	assert.Equal(t, BTC_USD, rolls[1].Pair)
+
+	// 已切换的合约不再重复通知
+	ret, err = r.Check(testDelivery.Add(-time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ret))
+	r.lock.Lock()
+	assert.False(t, r.pending)
+	r.lock.Unlock()
+
+	r.Unwatch(BTC_USD, THIS_WEEK_CONTRACT)
+	r.Unwatch(BTC_USD, NEXT_WEEK_CONTRACT)
+	_, err = r.WatchAt(BTC_USD, BI_QUARTER_CONTRACT, testDelivery.Add(-time.Hour))
+	assert.Nil(t, err)
+	_, err = r.Check(testDelivery.Add(-5 * time.Minute))
+	assert.Nil(t, err)
+	r.lock.Lock()
+	assert.True(t, r.pending)
+	r.lock.Unlock()
+}
@@ -0,0 +1,268 @@
+package goex
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+/**
+ * HTTP请求信息, Endpoint为去掉订单号、交易对等参数后的路径, 适合作为监控指标的标签
+ */
+type HttpRequestInfo struct {
+	Exchange     string
+	Method       string
+	Host         string
+	Path         string
+	Endpoint     string
+	RequestBytes int
+	Start        time.Time
+}
+
+/**
+ * HTTP请求结果, Latency为发送请求到读完响应的时间, Wait为限频等待的时间
+ */
+type HttpResponseInfo struct {
+	StatusCode    int
+	Latency       time.Duration
+	Wait          time.Duration
+	ResponseBytes int
+	Err           error
+}
+
+/**
+ * 错误类别, 如rate_limited, 请求成功时为空
+ */
+func (info *HttpResponseInfo) Category() string {
+	if info.Err == nil {
+		return ""
+	}
+	return strings.Replace(ErrorCategoryOf(info.Err).String(), " ", "_", -1)
+}
+
+/**
+ * HTTP请求钩子, BeforeRequest返回的ctx用于发送请求并传给AfterRequest
+ */
+type HttpHook interface {
+	BeforeRequest(ctx context.Context, req *HttpRequestInfo) context.Context
+	AfterRequest(ctx context.Context, req *HttpRequestInfo, resp *HttpResponseInfo)
+}
+
+/**
+ * websocket钩子
+ */
+type WsHook interface {
+	OnWsConnect(exchange, wsUrl string, err error)
+	OnWsMessage(exchange, wsUrl string, size int)
+	OnWsReconnect(exchange, wsUrl string, err error)
+}
+
+var (
+	hooksLock sync.RWMutex
+	httpHooks []HttpHook
+	wsHooks   []WsHook
+)
+
+/**
+ * 注册钩子, hook可以实现HttpHook和WsHook中的一个或两个
+ */
+func RegisterHook(hook interface{}) {
+	hooksLock.Lock()
+	defer hooksLock.Unlock()
+	if h, ok := hook.(HttpHook); ok {
+		httpHooks = append(httpHooks[:len(httpHooks):len(httpHooks)], h)
+	}
+	if h, ok := hook.(WsHook); ok {
+		wsHooks = append(wsHooks[:len(wsHooks):len(wsHooks)], h)
+	}
+}
+
+func UnregisterHook(hook interface{}) {
+	hooksLock.Lock()
+	defer hooksLock.Unlock()
+	if h, ok := hook.(HttpHook); ok {
+		hooks := make([]HttpHook, 0, len(httpHooks))
+		for _, e := range httpHooks {
+			if e != h {
+				hooks = append(hooks, e)
+			}
+		}
+		httpHooks = hooks
+	}
+	if h, ok := hook.(WsHook); ok {
+		hooks := make([]WsHook, 0, len(wsHooks))
+		for _, e := range wsHooks {
+			if e != h {
+				hooks = append(hooks, e)
+			}
+		}
+		wsHooks = hooks
+	}
+}
+
+func getHttpHooks() []HttpHook {
+	hooksLock.RLock()
+	defer hooksLock.RUnlock()
+	return httpHooks
+}
+
+func getWsHooks() []WsHook {
+	hooksLock.RLock()
+	defer hooksLock.RUnlock()
+	return wsHooks
+}
+
+func notifyWsConnect(exchange, wsUrl string, err error) {
+	for _, h := range getWsHooks() {
+		h.OnWsConnect(exchange, wsUrl, err)
+	}
+}
+
+func notifyWsMessage(exchange, wsUrl string, size int) {
+	for _, h := range getWsHooks() {
+		h.OnWsMessage(exchange, wsUrl, size)
+	}
+}
+
+func notifyWsReconnect(exchange, wsUrl string, err error) {
+	for _, h := range getWsHooks() {
+		h.OnWsReconnect(exchange, wsUrl, err)
+	}
+}
+
+var (
+	exchangeHostsLock sync.RWMutex
+	exchangeHosts     = map[string]string{} // host -> exchange
+)
+
+/**
+ * 登记交易所的域名, 用于确定请求和websocket连接所属的交易所
+ */
+func RegisterExchangeHosts(exchange string, hosts ...string) {
+	exchangeHostsLock.Lock()
+	defer exchangeHostsLock.Unlock()
+	for _, host := range hosts {
+		exchangeHosts[host] = exchange
+	}
+}
+
+/**
+ * 域名所属的交易所, 依次查找RegisterExchangeHosts、错误解析和限频器登记的域名, 都没有时返回域名
+ */
+func ExchangeOfHost(host string) string {
+	if i := strings.Index(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+
+	exchangeHostsLock.RLock()
+	exchange, ok := exchangeHosts[host]
+	exchangeHostsLock.RUnlock()
+	if ok {
+		return exchange
+	}
+
+	errorParsersLock.RLock()
+	entry, ok := errorParsers[host]
+	errorParsersLock.RUnlock()
+	if ok {
+		return entry.exchange
+	}
+
+	if limiter := GetRateLimiter(host); limiter != nil {
+		return limiter.Exchange
+	}
+	return host
+}
+
+func exchangeOfUrl(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+	return ExchangeOfHost(u.Host)
+}
+
+/**
+ * 把路径中的订单号、交易对等参数替换为{id}, 如/api/futures/v3/orders/BTC-USD-190628/123456789 -> /api/futures/v3/orders/{id}/{id}.
+ * 只替换像参数的路径段: 纯数字、UUID、16位以上的十六进制串、8位以上且包含数字的字母数字串, 以及不含小写字母的交易对如BTC-USDT.
+ * openOrders、myTrades这样的驼峰路径段保留
+ */
+func EndpointLabel(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isIdSegment(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isIdSegment(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	var digits, lower, upper, hex, other int
+	for _, c := range segment {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+			hex++
+		case c >= 'a' && c <= 'z':
+			lower++
+			if c <= 'f' {
+				hex++
+			}
+		case c >= 'A' && c <= 'Z':
+			upper++
+			if c <= 'F' {
+				hex++
+			}
+		case c == '-' || c == '_':
+		default:
+			other++
+		}
+	}
+	n := len(segment)
+	switch {
+	case other > 0:
+		return false
+	case digits == n:
+		return true
+	case isUUID(segment):
+		return true
+	case hex == n && n >= 16:
+		return true
+	case digits > 0 && n >= 8:
+		return true
+	}
+	// 交易对
+	return upper > 0 && lower == 0
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func init() {
+	RegisterExchangeHosts(BINANCE, "stream.binance.com", "fstream.binance.com")
+	RegisterExchangeHosts(OKEX, "real.okex.com")
+	RegisterExchangeHosts(HUOBI_PRO, "api.huobi.br.com")
+	RegisterExchangeHosts(BITMEX, "www.bitmex.com", "testnet.bitmex.com")
+}
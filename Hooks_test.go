@@ -0,0 +1,108 @@
+package goex
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointLabel(t *testing.T) {
+	assert.Equal(t, "/api/v3/ticker/24hr", EndpointLabel("/api/v3/ticker/24hr"))
+	assert.Equal(t, "/api/futures/v3/orders/{id}/{id}", EndpointLabel("/api/futures/v3/orders/BTC-USD-190628/123456789"))
+	assert.Equal(t, "/api/spot/v3/instruments/{id}/ticker", EndpointLabel("/api/spot/v3/instruments/BTC-USDT/ticker"))
+	assert.Equal(t, "/api/v3/{id}/trades", EndpointLabel("/api/v3/BTCUSDT/trades"))
+
+	// 驼峰路径段不是参数
+	assert.Equal(t, "/fapi/v1/openOrders", EndpointLabel("/fapi/v1/openOrders"))
+	assert.Equal(t, "/api/v3/myTrades", EndpointLabel("/api/v3/myTrades"))
+	assert.Equal(t, "/api/v1/getMarketSummaries", EndpointLabel("/api/v1/getMarketSummaries"))
+
+	assert.Equal(t, "/v1/order/orders/{id}/submitcancel", EndpointLabel("/v1/order/orders/59378/submitcancel"))
+	assert.Equal(t, "/api/v2/orders/{id}", EndpointLabel("/api/v2/orders/2f1c3b8e-6a4d-4c1e-9b7a-0d5e8f3a1c2b"))
+	assert.Equal(t, "/api/v2/orders/{id}", EndpointLabel("/api/v2/orders/5f3e8a9b0c1d2e3f"))
+	assert.Equal(t, "/api/v2/orders/{id}", EndpointLabel("/api/v2/orders/a1b2c3d4e5"))
+}
+
+type fakeSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *fakeSpan) RecordError(err error)                      { s.err = err }
+func (s *fakeSpan) End()                                       { s.ended = true }
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	span := &fakeSpan{name: name, attrs: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestHttpHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`not found`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	RegisterExchangeHosts("test", "127.0.0.1")
+
+	collector := NewPrometheusCollector("goex")
+	tracer := &fakeTracer{}
+	tracing := NewTracingHook(tracer)
+	RegisterHook(collector)
+	RegisterHook(tracing)
+	defer UnregisterHook(collector)
+	defer UnregisterHook(tracing)
+
+	_, err := HttpPostForm3(http.DefaultClient, server.URL+"/api/v3/order/12345678", "a=1", nil)
+	assert.Nil(t, err)
+	_, err = HttpGet6(http.DefaultClient, server.URL+"/api/v3/missing", nil)
+	assert.NotNil(t, err)
+
+	if assert.Len(t, tracer.spans, 2) {
+		span := tracer.spans[0]
+		assert.Equal(t, "HTTP POST /api/v3/order/{id}", span.name)
+		assert.Equal(t, "test", span.attrs["goex.exchange"])
+		assert.Equal(t, 200, span.attrs["http.status_code"])
+		assert.True(t, span.ended)
+		assert.Nil(t, span.err)
+
+		span = tracer.spans[1]
+		assert.Equal(t, 404, span.attrs["http.status_code"])
+		assert.Equal(t, "unknown_error", span.attrs["goex.error_category"])
+		assert.NotNil(t, span.err)
+	}
+
+	collector.OnWsMessage("test", "wss://example.com", 10)
+	collector.OnWsReconnect("test", "wss://example.com", errors.New("eof"))
+
+	var buf bytes.Buffer
+	n, err := collector.WriteTo(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	metrics := buf.String()
+	assert.Contains(t, metrics, `goex_http_requests_total{exchange="test",method="POST",endpoint="/api/v3/order/{id}",status="200",category="none"} 1`)
+	assert.Contains(t, metrics, `goex_http_requests_total{exchange="test",method="GET",endpoint="/api/v3/missing",status="404",category="unknown_error"} 1`)
+	assert.Contains(t, metrics, `goex_http_request_duration_seconds_count{exchange="test",method="POST",endpoint="/api/v3/order/{id}"} 1`)
+	assert.Contains(t, metrics, `goex_http_request_duration_seconds_bucket{exchange="test",method="POST",endpoint="/api/v3/order/{id}",le="+Inf"} 1`)
+	assert.Contains(t, metrics, `goex_http_request_bytes_total{exchange="test"} 3`)
+	assert.Contains(t, metrics, `goex_ws_message_bytes_total{exchange="test"} 10`)
+	assert.Contains(t, metrics, `goex_ws_reconnects_total{exchange="test",result="error"} 1`)
+}
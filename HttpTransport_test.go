@@ -0,0 +1,135 @@
+package goex
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransport_InvalidConfig(t *testing.T) {
+	_, err := NewTransport(TransportConfig{Proxy: "ftp://127.0.0.1:21"})
+	assert.NotNil(t, err)
+	_, err = NewTransport(TransportConfig{LocalAddr: "not-an-ip"})
+	assert.NotNil(t, err)
+	_, err = NewTransport(TransportConfig{LocalAddrs: map[string]string{BINANCE: "1.2.3"}})
+	assert.NotNil(t, err)
+
+	transport, err := NewTransport(TransportConfig{Proxy: "socks5://127.0.0.1:1080"})
+	assert.Nil(t, err)
+	assert.NotNil(t, transport.transport.Proxy)
+	assert.Equal(t, 32, transport.transport.MaxIdleConnsPerHost)
+}
+
+func TestTransport_MaxResponseBytes(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			w.Write([]byte(body))
+			w.(http.Flusher).Flush()
+			return
+		}
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client, err := NewHttpClient(TransportConfig{MaxResponseBytes: 50}, time.Second)
+	assert.Nil(t, err)
+
+	_, err = client.Get(server.URL)
+	assert.True(t, errors.Is(err, ErrResponseTooLarge))
+
+	resp, err := client.Get(server.URL + "/chunked")
+	assert.Nil(t, err)
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, ErrResponseTooLarge, err)
+	assert.Equal(t, 50, len(data))
+
+	client, _ = NewHttpClient(TransportConfig{MaxResponseBytes: 100}, time.Second)
+	resp, err = client.Get(server.URL + "/chunked")
+	assert.Nil(t, err)
+	data, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, body, string(data))
+}
+
+func TestTransport_HTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+	client, err := NewHttpClient(TransportConfig{TLSConfig: tlsConfig}, time.Second)
+	assert.Nil(t, err)
+	resp, err := client.Get(server.URL)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 2, resp.ProtoMajor)
+
+	client, _ = NewHttpClient(TransportConfig{TLSConfig: tlsConfig, DisableHTTP2: true}, time.Second)
+	resp, err = client.Get(server.URL)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 1, resp.ProtoMajor)
+}
+
+func TestTransport_LocalAddr(t *testing.T) {
+	remoteAddrs := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddrs <- r.RemoteAddr
+	}))
+	defer server.Close()
+
+	host, _, _ := net.SplitHostPort(server.Listener.Addr().String())
+	client, err := NewHttpClient(TransportConfig{LocalAddr: "0.0.0.0", LocalAddrs: map[string]string{host: "127.0.0.1"}}, time.Second)
+	assert.Nil(t, err)
+	resp, err := client.Get(server.URL)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	remoteHost, _, _ := net.SplitHostPort(<-remoteAddrs)
+	assert.Equal(t, "127.0.0.1", remoteHost)
+}
+
+func TestDnsCache(t *testing.T) {
+	calls := 0
+	fail := false
+	cache := newDnsCache(time.Hour)
+	cache.resolve = func(ctx context.Context, host string) ([]string, error) {
+		calls++
+		if fail {
+			return nil, errors.New("no such host")
+		}
+		return []string{"127.0.0.1"}, nil
+	}
+
+	addrs, err := cache.lookup(context.Background(), "api.binance.com")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"127.0.0.1"}, addrs)
+	cache.lookup(context.Background(), "api.binance.com")
+	assert.Equal(t, 1, calls)
+
+	// 过期后重新解析, 解析失败时使用过期的结果
+	cache.entries["api.binance.com"] = dnsEntry{addrs: addrs, expires: time.Now().Add(-time.Second)}
+	fail = true
+	addrs, err = cache.lookup(context.Background(), "api.binance.com")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"127.0.0.1"}, addrs)
+	assert.Equal(t, 2, calls)
+
+	_, err = cache.lookup(context.Background(), "www.bitmex.com")
+	assert.NotNil(t, err)
+}
@@ -2,18 +2,37 @@ package goex
 
 //http request 工具函数
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 func NewHttpRequest(client *http.Client, reqType string, reqUrl string, postData string, requstHeaders map[string]string) ([]byte, error) {
-	req, _ := http.NewRequest(reqType, reqUrl, strings.NewReader(postData))
+	return NewHttpRequestWithContext(context.Background(), client, reqType, reqUrl, postData, requstHeaders)
+}
+
+func NewHttpRequestWithContext(ctx context.Context, client *http.Client, reqType string, reqUrl string, postData string, requstHeaders map[string]string) ([]byte, error) {
+	bodyData, _, err := NewHttpRequestExWithContext(ctx, client, reqType, reqUrl, postData, requstHeaders)
+	return bodyData, err
+}
+
+func NewHttpRequestEx(client *http.Client, reqType string, reqUrl string, postData string, requstHeaders map[string]string) ([]byte, http.Header, error) {
+	return NewHttpRequestExWithContext(context.Background(), client, reqType, reqUrl, postData, requstHeaders)
+}
+
+/**
+ * 发送请求, ctx可用于设置超时和取消请求, 返回响应内容和响应头.
+ * 发往已注册EndpointGroup的请求按地址的健康状况选择实际的地址, 见EndpointGroup
+ */
+func NewHttpRequestExWithContext(ctx context.Context, client *http.Client, reqType string, reqUrl string, postData string, requstHeaders map[string]string) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, reqType, reqUrl, strings.NewReader(postData))
+	if err != nil {
+		return nil, nil, err
+	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 5.1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/31.0.1650.63 Safari/537.36")
 
 	if requstHeaders != nil {
@@ -22,56 +41,98 @@ func NewHttpRequest(client *http.Client, reqType string, reqUrl string, postData
 		}
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	defer resp.Body.Close()
-
-	bodyData, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		return nil, errors.New(fmt.Sprintf("HttpStatusCode:%d ,Desc:%s", resp.StatusCode, string(bodyData)))
+	group := GetEndpointGroup(req.URL.Host)
+	if group == nil {
+		return sendHttpRequest(client, req, req.URL.Host, len(postData))
 	}
-
-	return bodyData, nil
+	return group.do(req, func(ctx context.Context, host string) ([]byte, http.Header, error) {
+		r := req.Clone(ctx)
+		r.URL.Host = host
+		r.Host = ""
+		r.Body = ioutil.NopCloser(strings.NewReader(postData))
+		return sendHttpRequest(client, r, req.URL.Host, len(postData))
+	})
 }
 
-func NewHttpRequestEx(client *http.Client, reqType string, reqUrl string, postData string, requstHeaders map[string]string) ([]byte, http.Header, error) {
-	req, _ := http.NewRequest(reqType, reqUrl, strings.NewReader(postData))
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 5.1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/31.0.1650.63 Safari/537.36")
+/**
+ * 发送一次请求并调用钩子, 限频和错误解析按limitHost(请求原来的域名)查找
+ */
+func sendHttpRequest(client *http.Client, req *http.Request, limitHost string, requestBytes int) ([]byte, http.Header, error) {
+	hooks := getHttpHooks()
+	if len(hooks) == 0 {
+		bodyData, header, err := doHttpRequest(client, req, limitHost, &HttpResponseInfo{})
+		return bodyData, header, err
+	}
+
+	ctx := req.Context()
+	reqInfo := &HttpRequestInfo{
+		Exchange:     ExchangeOfHost(limitHost),
+		Method:       req.Method,
+		Host:         req.URL.Host,
+		Path:         req.URL.Path,
+		Endpoint:     EndpointLabel(req.URL.Path),
+		RequestBytes: requestBytes,
+		Start:        time.Now(),
+	}
+	for _, h := range hooks {
+		ctx = h.BeforeRequest(ctx, reqInfo)
+	}
+	req = req.WithContext(ctx)
+
+	respInfo := &HttpResponseInfo{}
+	bodyData, header, err := doHttpRequest(client, req, limitHost, respInfo)
+	respInfo.Err = err
+	for _, h := range hooks {
+		h.AfterRequest(ctx, reqInfo, respInfo)
+	}
+	return bodyData, header, err
+}
 
-	if requstHeaders != nil {
-		for k, v := range requstHeaders {
-			req.Header.Add(k, v)
+func doHttpRequest(client *http.Client, req *http.Request, limitHost string, info *HttpResponseInfo) ([]byte, http.Header, error) {
+	limiter := GetRateLimiter(limitHost)
+	if limiter != nil {
+		waitStart := time.Now()
+		err := limiter.WaitContext(req.Context(), req)
+		info.Wait = time.Since(waitStart)
+		if err != nil {
+			return nil, nil, err
 		}
 	}
 
+	start := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, nil, err
+		info.Latency = time.Since(start)
+		return nil, nil, NewNetworkError(limitHost, err)
+	}
+	info.StatusCode = resp.StatusCode
+
+	if limiter != nil {
+		limiter.Observe(req, resp)
 	}
 
 	defer resp.Body.Close()
 
 	bodyData, err := ioutil.ReadAll(resp.Body)
+	info.Latency = time.Since(start)
+	info.ResponseBytes = len(bodyData)
 	if err != nil {
-		return nil, resp.Header, err
+		return nil, resp.Header, NewNetworkError(limitHost, err)
 	}
 
-	if resp.StatusCode != 200 {
-		return nil, resp.Header, errors.New(fmt.Sprintf("HttpStatusCode:%d ,Desc:%s", resp.StatusCode, string(bodyData)))
+	if resp.StatusCode/100 != 2 {
+		return nil, resp.Header, NewHttpError(limitHost, resp.StatusCode, bodyData)
 	}
 
 	return bodyData, resp.Header, nil
 }
 
 func HttpGet(client *http.Client, reqUrl string) (map[string]interface{}, error) {
-	respData, err := NewHttpRequest(client, "GET", reqUrl, "", nil)
+	return HttpGetWithContext(context.Background(), client, reqUrl)
+}
+
+func HttpGetWithContext(ctx context.Context, client *http.Client, reqUrl string) (map[string]interface{}, error) {
+	respData, err := NewHttpRequestWithContext(ctx, client, "GET", reqUrl, "", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -79,20 +140,24 @@ func HttpGet(client *http.Client, reqUrl string) (map[string]interface{}, error)
 	var bodyDataMap map[string]interface{}
 	err = json.Unmarshal(respData, &bodyDataMap)
 	if err != nil {
-		log.Println(string(respData))
+		DefaultLogger().Warn("decode response failed", "url", reqUrl, "err", err, "body", string(respData))
 		return nil, err
 	}
 	return bodyDataMap, nil
 }
 
 func HttpGet2(client *http.Client, reqUrl string, headers map[string]string) (map[string]interface{}, error) {
+	return HttpGet2WithContext(context.Background(), client, reqUrl, headers)
+}
+
+func HttpGet2WithContext(ctx context.Context, client *http.Client, reqUrl string, headers map[string]string) (map[string]interface{}, error) {
 	if headers == nil {
 		headers = map[string]string{}
 	}
 	if _, ok := headers["Content-Type"]; !ok {
 		headers["Content-Type"] = "application/x-www-form-urlencoded"
 	}
-	respData, err := NewHttpRequest(client, "GET", reqUrl, "", headers)
+	respData, err := NewHttpRequestWithContext(ctx, client, "GET", reqUrl, "", headers)
 	if err != nil {
 		return nil, err
 	}
@@ -100,20 +165,24 @@ func HttpGet2(client *http.Client, reqUrl string, headers map[string]string) (ma
 	var bodyDataMap map[string]interface{}
 	err = json.Unmarshal(respData, &bodyDataMap)
 	if err != nil {
-		log.Println("respData", string(respData))
+		DefaultLogger().Warn("decode response failed", "url", reqUrl, "err", err, "body", string(respData))
 		return nil, err
 	}
 	return bodyDataMap, nil
 }
 
 func HttpGet3(client *http.Client, reqUrl string, headers map[string]string) ([]interface{}, error) {
+	return HttpGet3WithContext(context.Background(), client, reqUrl, headers)
+}
+
+func HttpGet3WithContext(ctx context.Context, client *http.Client, reqUrl string, headers map[string]string) ([]interface{}, error) {
 	if headers == nil {
 		headers = map[string]string{}
 	}
 	if _, ok := headers["Content-Type"]; !ok {
 		headers["Content-Type"] = "application/x-www-form-urlencoded"
 	}
-	respData, err := NewHttpRequest(client, "GET", reqUrl, "", headers)
+	respData, err := NewHttpRequestWithContext(ctx, client, "GET", reqUrl, "", headers)
 	if err != nil {
 		return nil, err
 	}
@@ -121,27 +190,31 @@ func HttpGet3(client *http.Client, reqUrl string, headers map[string]string) ([]
 	var bodyDataMap []interface{}
 	err = json.Unmarshal(respData, &bodyDataMap)
 	if err != nil {
-		log.Println("respData", string(respData))
+		DefaultLogger().Warn("decode response failed", "url", reqUrl, "err", err, "body", string(respData))
 		return nil, err
 	}
 	return bodyDataMap, nil
 }
 
 func HttpGet4(client *http.Client, reqUrl string, headers map[string]string, result interface{}) error {
+	return HttpGet4WithContext(context.Background(), client, reqUrl, headers, result)
+}
+
+func HttpGet4WithContext(ctx context.Context, client *http.Client, reqUrl string, headers map[string]string, result interface{}) error {
 	if headers == nil {
 		headers = map[string]string{}
 	}
 	if _, ok := headers["Content-Type"]; !ok {
 		headers["Content-Type"] = "application/x-www-form-urlencoded"
 	}
-	respData, err := NewHttpRequest(client, "GET", reqUrl, "", headers)
+	respData, err := NewHttpRequestWithContext(ctx, client, "GET", reqUrl, "", headers)
 	if err != nil {
-		println(reqUrl + err.Error())
+		DefaultLogger().Warn("http request failed", "url", reqUrl, "err", err)
 		return err
 	}
 	err = json.Unmarshal(respData, result)
 	if err != nil {
-		log.Printf("HttpGet4 - json.Unmarshal failed : %v, resp %s", err, string(respData))
+		DefaultLogger().Warn("decode response failed", "url", reqUrl, "err", err, "body", string(respData))
 		return err
 	}
 
@@ -149,20 +222,24 @@ func HttpGet4(client *http.Client, reqUrl string, headers map[string]string, res
 }
 
 func HttpGet5(client *http.Client, reqUrl string, headers map[string]string, result interface{}) (error, http.Header) {
+	return HttpGet5WithContext(context.Background(), client, reqUrl, headers, result)
+}
+
+func HttpGet5WithContext(ctx context.Context, client *http.Client, reqUrl string, headers map[string]string, result interface{}) (error, http.Header) {
 	if headers == nil {
 		headers = map[string]string{}
 	}
 	if _, ok := headers["Content-Type"]; !ok {
 		headers["Content-Type"] = "application/x-www-form-urlencoded"
 	}
-	respData, respHeader, err := NewHttpRequestEx(client, "GET", reqUrl, "", headers)
+	respData, respHeader, err := NewHttpRequestExWithContext(ctx, client, "GET", reqUrl, "", headers)
 	if err != nil {
-		print(err.Error())
+		DefaultLogger().Warn("http request failed", "url", reqUrl, "err", err)
 		return err, respHeader
 	}
 	err = json.Unmarshal(respData, result)
 	if err != nil {
-		log.Printf("HttpGet4 - json.Unmarshal failed : %v, resp %s", err, string(respData))
+		DefaultLogger().Warn("decode response failed", "url", reqUrl, "err", err, "body", string(respData))
 		return err, respHeader
 	}
 
@@ -170,67 +247,103 @@ func HttpGet5(client *http.Client, reqUrl string, headers map[string]string, res
 }
 
 func HttpGet6(client *http.Client, reqUrl string, headers map[string]string) ([]byte, error) {
+	return HttpGet6WithContext(context.Background(), client, reqUrl, headers)
+}
+
+func HttpGet6WithContext(ctx context.Context, client *http.Client, reqUrl string, headers map[string]string) ([]byte, error) {
 	if headers == nil {
 		headers = map[string]string{}
 	}
 	if _, ok := headers["Content-Type"]; !ok {
 		headers["Content-Type"] = "application/x-www-form-urlencoded"
 	}
-	respData, err := NewHttpRequest(client, "GET", reqUrl, "", headers)
+	respData, err := NewHttpRequestWithContext(ctx, client, "GET", reqUrl, "", headers)
 	if err != nil {
-		println(reqUrl + err.Error())
+		DefaultLogger().Warn("http request failed", "url", reqUrl, "err", err)
 		return nil, err
 	}
 	return respData, nil
 }
 
 func HttpPostForm(client *http.Client, reqUrl string, postData url.Values) ([]byte, error) {
+	return HttpPostFormWithContext(context.Background(), client, reqUrl, postData)
+}
+
+func HttpPostFormWithContext(ctx context.Context, client *http.Client, reqUrl string, postData url.Values) ([]byte, error) {
 	headers := map[string]string{
 		"Content-Type": "application/x-www-form-urlencoded"}
-	return NewHttpRequest(client, "POST", reqUrl, postData.Encode(), headers)
+	return NewHttpRequestWithContext(ctx, client, "POST", reqUrl, postData.Encode(), headers)
 }
 
 func HttpPostForm2(client *http.Client, reqUrl string, postData url.Values, headers map[string]string) ([]byte, error) {
+	return HttpPostForm2WithContext(context.Background(), client, reqUrl, postData, headers)
+}
+
+func HttpPostForm2WithContext(ctx context.Context, client *http.Client, reqUrl string, postData url.Values, headers map[string]string) ([]byte, error) {
 	if headers == nil {
 		headers = map[string]string{}
 	}
 	headers["Content-Type"] = "application/x-www-form-urlencoded"
-	return NewHttpRequest(client, "POST", reqUrl, postData.Encode(), headers)
+	return NewHttpRequestWithContext(ctx, client, "POST", reqUrl, postData.Encode(), headers)
 }
 
 func HttpPostForm3(client *http.Client, reqUrl string, postData string, headers map[string]string) ([]byte, error) {
-	return NewHttpRequest(client, "POST", reqUrl, postData, headers)
+	return HttpPostForm3WithContext(context.Background(), client, reqUrl, postData, headers)
+}
+
+func HttpPostForm3WithContext(ctx context.Context, client *http.Client, reqUrl string, postData string, headers map[string]string) ([]byte, error) {
+	return NewHttpRequestWithContext(ctx, client, "POST", reqUrl, postData, headers)
 }
 
 func HttpPostForm4(client *http.Client, reqUrl string, postData interface{}, headers map[string]string) ([]byte, error) {
+	return HttpPostForm4WithContext(context.Background(), client, reqUrl, postData, headers)
+}
+
+func HttpPostForm4WithContext(ctx context.Context, client *http.Client, reqUrl string, postData interface{}, headers map[string]string) ([]byte, error) {
 	if headers == nil {
 		headers = map[string]string{}
 	}
 	headers["Content-Type"] = "application/json"
 	data, _ := json.Marshal(postData)
-	return NewHttpRequest(client, "POST", reqUrl, string(data), headers)
+	return NewHttpRequestWithContext(ctx, client, "POST", reqUrl, string(data), headers)
 }
 
 func HttpPostJson(client *http.Client, reqUrl string, body string, headers map[string]string) ([]byte, error) {
+	return HttpPostJsonWithContext(context.Background(), client, reqUrl, body, headers)
+}
+
+func HttpPostJsonWithContext(ctx context.Context, client *http.Client, reqUrl string, body string, headers map[string]string) ([]byte, error) {
 	if headers == nil {
 		headers = map[string]string{}
 	}
 	headers["Content-Type"] = "application/json"
-	return NewHttpRequest(client, "POST", reqUrl, body, headers)
+	return NewHttpRequestWithContext(ctx, client, "POST", reqUrl, body, headers)
 }
 
 func HttpDeleteForm(client *http.Client, reqUrl string, postData url.Values, headers map[string]string) ([]byte, error) {
+	return HttpDeleteFormWithContext(context.Background(), client, reqUrl, postData, headers)
+}
+
+func HttpDeleteFormWithContext(ctx context.Context, client *http.Client, reqUrl string, postData url.Values, headers map[string]string) ([]byte, error) {
 	if headers == nil {
 		headers = map[string]string{}
 	}
 	headers["Content-Type"] = "application/x-www-form-urlencoded"
-	return NewHttpRequest(client, "DELETE", reqUrl, postData.Encode(), headers)
+	return NewHttpRequestWithContext(ctx, client, "DELETE", reqUrl, postData.Encode(), headers)
 }
 
 func HttpDeleteForm3(client *http.Client, reqUrl string, postData string, headers map[string]string) ([]byte, error) {
-	return NewHttpRequest(client, "DELETE", reqUrl, postData, headers)
+	return HttpDeleteForm3WithContext(context.Background(), client, reqUrl, postData, headers)
+}
+
+func HttpDeleteForm3WithContext(ctx context.Context, client *http.Client, reqUrl string, postData string, headers map[string]string) ([]byte, error) {
+	return NewHttpRequestWithContext(ctx, client, "DELETE", reqUrl, postData, headers)
 }
 
 func HttpPutForm3(client *http.Client, reqUrl string, postData string, headers map[string]string) ([]byte, error) {
-	return NewHttpRequest(client, "PUT", reqUrl, postData, headers)
+	return HttpPutForm3WithContext(context.Background(), client, reqUrl, postData, headers)
+}
+
+func HttpPutForm3WithContext(ctx context.Context, client *http.Client, reqUrl string, postData string, headers map[string]string) ([]byte, error) {
+	return NewHttpRequestWithContext(ctx, client, "PUT", reqUrl, postData, headers)
 }
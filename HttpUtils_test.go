@@ -0,0 +1,27 @@
+package goex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpPostJson_StatusCode(t *testing.T) {
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer server.Close()
+
+	// 202 Accepted is a success, gate.io v4 answers withdrawals with it
+	body, err := HttpPostJson(http.DefaultClient, server.URL, `{}`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":"1"}`, string(body))
+
+	status = http.StatusBadRequest
+	_, err = HttpPostJson(http.DefaultClient, server.URL, `{}`, nil)
+	assert.NotNil(t, err)
+}
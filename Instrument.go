@@ -0,0 +1,316 @@
+package goex
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// 交易品种类型
+type InstrumentType string
+
+const (
+	INSTRUMENT_SPOT   InstrumentType = "spot"   // 现货
+	INSTRUMENT_FUTURE InstrumentType = "future" // 交割合约
+	INSTRUMENT_SWAP   InstrumentType = "swap"   // 永续合约
+	INSTRUMENT_OPTION InstrumentType = "option" // 期权
+)
+
+// 交易品种状态
+type InstrumentStatus string
+
+const (
+	INSTRUMENT_STATUS_UNKNOWN   InstrumentStatus = ""          // 交易所没有返回状态
+	INSTRUMENT_STATUS_TRADING   InstrumentStatus = "trading"   // 正常交易
+	INSTRUMENT_STATUS_SUSPENDED InstrumentStatus = "suspended" // 暂停交易
+	INSTRUMENT_STATUS_DELISTED  InstrumentStatus = "delisted"  // 已下线或已交割
+)
+
+/**
+ * 统一的交易品种信息, 交易所没有提供的字段为零值
+ */
+type Instrument struct {
+	Exchange        string
+	Symbol          string // 交易所的交易对或合约代码, 如BTCUSDT、BTC-USD-190628
+	Id              string // 交易所内部的交易品种编号, 如ZBG的marketId, 没有时为空
+	Pair            CurrencyPair
+	Type            InstrumentType
+	TickSize        decimal.Decimal // 价格最小变动
+	LotSize         decimal.Decimal // 数量最小变动
+	MinQty          decimal.Decimal // 最小下单数量
+	MinNotional     decimal.Decimal // 最小下单金额, 以计价币计
+	PricePrecision  int32           // 价格小数位数
+	AmountPrecision int32           // 数量小数位数
+	ContractValue   decimal.Decimal // 每张合约的面值, 现货为零
+	Multiplier      decimal.Decimal // 合约乘数, 现货为零
+	Expiry          time.Time       // 交割时间, 现货和永续合约为零值
+	SettleCurrency  Currency        // 结算币种, 现货为UNKNOWN
+	Status          InstrumentStatus
+}
+
+/**
+ * 最小变动对应的小数位数, 如0.001 -> 3, 10 -> 0
+ */
+func PrecisionOf(step decimal.Decimal) int32 {
+	if step.Sign() <= 0 {
+		return 0
+	}
+	exp := -step.Exponent()
+	// 去掉末尾的0, 如0.0100 -> 0.01
+	for exp > 0 && step.Shift(exp-1).Equal(step.Shift(exp-1).Truncate(0)) {
+		exp--
+	}
+	return exp
+}
+
+/**
+ * 小数位数对应的最小变动, 如3 -> 0.001
+ */
+func StepOf(precision int32) decimal.Decimal {
+	return decimal.New(1, -precision)
+}
+
+/**
+ * 用最小变动补全小数位数, 或者用小数位数补全最小变动
+ */
+func (inst *Instrument) FillPrecision() {
+	if inst.TickSize.Sign() > 0 {
+		inst.PricePrecision = PrecisionOf(inst.TickSize)
+	} else {
+		inst.TickSize = StepOf(inst.PricePrecision)
+	}
+	if inst.LotSize.Sign() > 0 {
+		inst.AmountPrecision = PrecisionOf(inst.LotSize)
+	} else {
+		inst.LotSize = StepOf(inst.AmountPrecision)
+	}
+}
+
+func (inst *Instrument) IsTrading() bool {
+	return inst.Status == INSTRUMENT_STATUS_TRADING || inst.Status == INSTRUMENT_STATUS_UNKNOWN
+}
+
+/**
+ * 获取交易所所有交易品种的接口.
+ * 有交易品种接口的连接器都已实现, okcoin的V1接口(OKCoin_CN、OKCoin_COM、OKEx、OKExSpot)使用OKExV3的GetInstruments.
+ * aacoin、aex、allcoin、btcbox、btcc、coin58和coincheck没有交易品种接口, 未实现
+ */
+type InstrumentAPI interface {
+	GetInstruments() ([]Instrument, error)
+}
+
+/**
+ * 函数适配为InstrumentAPI, 用于GetInstruments返回交易所原始格式的连接器, 如OKEx V3的GetUnifiedInstruments
+ */
+type InstrumentAPIFunc func() ([]Instrument, error)
+
+func (f InstrumentAPIFunc) GetInstruments() ([]Instrument, error) {
+	return f()
+}
+
+var ErrInstrumentNotFound = errors.New("instrument not found")
+
+/**
+ * 交易品种目录, 缓存交易所的交易品种并定期刷新. 第一次查询时加载, Start后按RefreshInterval在后台刷新
+ */
+type InstrumentCatalog struct {
+	Exchange        string
+	API             InstrumentAPI
+	RefreshInterval time.Duration
+	RetryInterval   time.Duration // 刷新失败后的重试间隔
+
+	lock        sync.RWMutex
+	instruments []Instrument
+	bySymbol    map[string]int
+	updated     time.Time
+	running     bool
+	stopChan    chan struct{}
+}
+
+func NewInstrumentCatalog(exchange string, api InstrumentAPI) *InstrumentCatalog {
+	return &InstrumentCatalog{
+		Exchange:        exchange,
+		API:             api,
+		RefreshInterval: time.Hour,
+		RetryInterval:   time.Minute,
+	}
+}
+
+/**
+ * 重新获取交易品种, 失败时保留原来的数据
+ */
+func (c *InstrumentCatalog) Refresh() error {
+	instruments, err := c.API.GetInstruments()
+	if err != nil {
+		return err
+	}
+
+	bySymbol := make(map[string]int, len(instruments))
+	for i := range instruments {
+		if instruments[i].Exchange == "" {
+			instruments[i].Exchange = c.Exchange
+		}
+		bySymbol[strings.ToUpper(instruments[i].Symbol)] = i
+	}
+
+	c.lock.Lock()
+	c.instruments = instruments
+	c.bySymbol = bySymbol
+	c.updated = time.Now()
+	c.lock.Unlock()
+	return nil
+}
+
+func (c *InstrumentCatalog) ensureLoaded() error {
+	c.lock.RLock()
+	loaded := c.bySymbol != nil
+	c.lock.RUnlock()
+	if loaded {
+		return nil
+	}
+	return c.Refresh()
+}
+
+/**
+ * 最近一次刷新成功的时间
+ */
+func (c *InstrumentCatalog) Updated() time.Time {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.updated
+}
+
+/**
+ * 所有交易品种, 按Symbol排序
+ */
+func (c *InstrumentCatalog) All() ([]Instrument, error) {
+	if err := c.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	c.lock.RLock()
+	ret := make([]Instrument, len(c.instruments))
+	copy(ret, c.instruments)
+	c.lock.RUnlock()
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Symbol < ret[j].Symbol
+	})
+	return ret, nil
+}
+
+/**
+ * 按交易所的交易对或合约代码查找, 不区分大小写
+ */
+func (c *InstrumentCatalog) Get(symbol string) (Instrument, error) {
+	if err := c.ensureLoaded(); err != nil {
+		return Instrument{}, err
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	i, ok := c.bySymbol[strings.ToUpper(symbol)]
+	if !ok {
+		return Instrument{}, ErrInstrumentNotFound
+	}
+	return c.instruments[i], nil
+}
+
+/**
+ * 按交易对和类型查找, 交割合约可能有多个
+ */
+func (c *InstrumentCatalog) Find(pair CurrencyPair, typ InstrumentType) ([]Instrument, error) {
+	if err := c.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	var ret []Instrument
+	for _, inst := range c.instruments {
+		if inst.Type == typ && strings.EqualFold(inst.Pair.CurrencyA.Symbol, pair.CurrencyA.Symbol) &&
+			strings.EqualFold(inst.Pair.CurrencyB.Symbol, pair.CurrencyB.Symbol) {
+			ret = append(ret, inst)
+		}
+	}
+	if len(ret) == 0 {
+		return nil, ErrInstrumentNotFound
+	}
+	return ret, nil
+}
+
+/**
+ * 启动后台刷新, 已启动时不做任何事
+ */
+func (c *InstrumentCatalog) Start() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.running {
+		return
+	}
+	c.running = true
+	c.stopChan = make(chan struct{})
+	go c.loop(c.stopChan)
+}
+
+func (c *InstrumentCatalog) Stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.running {
+		return
+	}
+	c.running = false
+	close(c.stopChan)
+}
+
+func (c *InstrumentCatalog) loop(stopChan chan struct{}) {
+	for {
+		wait := c.RefreshInterval
+		if err := c.Refresh(); err != nil {
+			DefaultLogger().Warn("refresh instruments failed", "exchange", c.Exchange, "err", err)
+			wait = c.RetryInterval
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-stopChan:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+var (
+	instrumentCatalogs     = map[string]*InstrumentCatalog{}
+	instrumentCatalogsLock sync.RWMutex
+)
+
+/**
+ * 注册交易所的交易品种目录, 替换已注册的目录
+ */
+func RegisterInstrumentCatalog(catalog *InstrumentCatalog) {
+	instrumentCatalogsLock.Lock()
+	old := instrumentCatalogs[catalog.Exchange]
+	instrumentCatalogs[catalog.Exchange] = catalog
+	instrumentCatalogsLock.Unlock()
+	if old != nil && old != catalog {
+		old.Stop()
+	}
+}
+
+func UnregisterInstrumentCatalog(exchange string) {
+	instrumentCatalogsLock.Lock()
+	catalog := instrumentCatalogs[exchange]
+	delete(instrumentCatalogs, exchange)
+	instrumentCatalogsLock.Unlock()
+	if catalog != nil {
+		catalog.Stop()
+	}
+}
+
+func GetInstrumentCatalog(exchange string) *InstrumentCatalog {
+	instrumentCatalogsLock.RLock()
+	defer instrumentCatalogsLock.RUnlock()
+	return instrumentCatalogs[exchange]
+}
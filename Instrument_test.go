@@ -0,0 +1,144 @@
+package goex
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeInstrumentAPI struct {
+	lock        sync.Mutex
+	instruments []Instrument
+	err         error
+	calls       int
+}
+
+func (api *fakeInstrumentAPI) GetInstruments() ([]Instrument, error) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	api.calls++
+	if api.err != nil {
+		return nil, api.err
+	}
+	ret := make([]Instrument, len(api.instruments))
+	copy(ret, api.instruments)
+	return ret, nil
+}
+
+func (api *fakeInstrumentAPI) set(instruments []Instrument, err error) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	api.instruments = instruments
+	api.err = err
+}
+
+func testInstruments() []Instrument {
+	return []Instrument{
+		{Symbol: "ETHUSDT", Pair: ETH_USDT, Type: INSTRUMENT_SPOT},
+		{Symbol: "BTCUSDT", Pair: BTC_USDT, Type: INSTRUMENT_SPOT},
+		{Symbol: "BTC-USD-190927", Pair: BTC_USD, Type: INSTRUMENT_FUTURE},
+		{Symbol: "BTC-USD-191227", Pair: BTC_USD, Type: INSTRUMENT_FUTURE},
+	}
+}
+
+func TestPrecisionOf(t *testing.T) {
+	assert.Equal(t, int32(3), PrecisionOf(decimal.RequireFromString("0.001")))
+	assert.Equal(t, int32(2), PrecisionOf(decimal.RequireFromString("0.0100")))
+	assert.Equal(t, int32(1), PrecisionOf(decimal.RequireFromString("0.5")))
+	assert.Equal(t, int32(0), PrecisionOf(decimal.RequireFromString("1.000")))
+	assert.Equal(t, int32(0), PrecisionOf(decimal.RequireFromString("10")))
+	assert.Equal(t, int32(0), PrecisionOf(decimal.Zero))
+
+	assert.True(t, StepOf(3).Equal(decimal.RequireFromString("0.001")))
+	assert.True(t, StepOf(0).Equal(decimal.New(1, 0)))
+}
+
+func TestInstrument_FillPrecision(t *testing.T) {
+	inst := Instrument{TickSize: decimal.RequireFromString("0.0100"), AmountPrecision: 4}
+	inst.FillPrecision()
+	assert.Equal(t, int32(2), inst.PricePrecision)
+	assert.True(t, inst.LotSize.Equal(decimal.RequireFromString("0.0001")))
+	assert.Equal(t, int32(4), inst.AmountPrecision)
+
+	assert.True(t, inst.IsTrading())
+	inst.Status = INSTRUMENT_STATUS_SUSPENDED
+	assert.False(t, inst.IsTrading())
+}
+
+func TestInstrumentCatalog_Lookup(t *testing.T) {
+	api := &fakeInstrumentAPI{instruments: testInstruments()}
+	catalog := NewInstrumentCatalog("test", api)
+
+	inst, err := catalog.Get("btcusdt")
+	assert.Nil(t, err)
+	assert.Equal(t, "BTCUSDT", inst.Symbol)
+	assert.Equal(t, "test", inst.Exchange)
+	assert.Equal(t, 1, api.calls)
+
+	_, err = catalog.Get("LTCUSDT")
+	assert.Equal(t, ErrInstrumentNotFound, err)
+	assert.Equal(t, 1, api.calls)
+
+	all, err := catalog.All()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(all))
+	assert.Equal(t, "BTC-USD-190927", all[0].Symbol)
+	assert.Equal(t, "ETHUSDT", all[3].Symbol)
+
+	futures, err := catalog.Find(BTC_USD, INSTRUMENT_FUTURE)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(futures))
+
+	_, err = catalog.Find(BTC_USDT, INSTRUMENT_SWAP)
+	assert.Equal(t, ErrInstrumentNotFound, err)
+}
+
+func TestInstrumentCatalog_RefreshFailure(t *testing.T) {
+	api := &fakeInstrumentAPI{err: errors.New("down")}
+	catalog := NewInstrumentCatalog("test", api)
+
+	_, err := catalog.Get("BTCUSDT")
+	assert.NotNil(t, err)
+	assert.True(t, catalog.Updated().IsZero())
+
+	api.set(testInstruments(), nil)
+	assert.Nil(t, catalog.Refresh())
+	updated := catalog.Updated()
+	assert.False(t, updated.IsZero())
+
+	api.set(nil, errors.New("down"))
+	assert.NotNil(t, catalog.Refresh())
+	inst, err := catalog.Get("ETHUSDT")
+	assert.Nil(t, err)
+	assert.Equal(t, ETH_USDT, inst.Pair)
+	assert.Equal(t, updated, catalog.Updated())
+}
+
+func TestInstrumentCatalog_Registry(t *testing.T) {
+	first := NewInstrumentCatalog("test", &fakeInstrumentAPI{instruments: testInstruments()})
+	first.Start()
+	RegisterInstrumentCatalog(first)
+	assert.Equal(t, first, GetInstrumentCatalog("test"))
+
+	second := NewInstrumentCatalog("test", &fakeInstrumentAPI{})
+	RegisterInstrumentCatalog(second)
+	assert.Equal(t, second, GetInstrumentCatalog("test"))
+	first.lock.RLock()
+	assert.False(t, first.running)
+	first.lock.RUnlock()
+
+	UnregisterInstrumentCatalog("test")
+	assert.Nil(t, GetInstrumentCatalog("test"))
+}
+
+func TestInstrumentAPIFunc(t *testing.T) {
+	catalog := NewInstrumentCatalog("test", InstrumentAPIFunc(func() ([]Instrument, error) {
+		return testInstruments(), nil
+	}))
+	inst, err := catalog.Get("ETHUSDT")
+	assert.Nil(t, err)
+	assert.Equal(t, ETH_USDT, inst.Pair)
+}
@@ -0,0 +1,129 @@
+package goex
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type KlineDecimal struct {
+	Pair      CurrencyPair
+	Timestamp int64 // 单位:秒, 与Kline相同
+	Open      decimal.Decimal
+	Close     decimal.Decimal
+	High      decimal.Decimal
+	Low       decimal.Decimal
+	Vol       decimal.Decimal
+}
+
+func (k *KlineDecimal) ToKline() Kline {
+	open, _ := k.Open.Float64()
+	close, _ := k.Close.Float64()
+	high, _ := k.High.Float64()
+	low, _ := k.Low.Float64()
+	vol, _ := k.Vol.Float64()
+	return Kline{Pair: k.Pair, Timestamp: k.Timestamp, Open: open, Close: close, High: high, Low: low, Vol: vol}
+}
+
+func KlineDecimalsToKlines(klines []KlineDecimal) []Kline {
+	ret := make([]Kline, len(klines))
+	for i := range klines {
+		ret[i] = klines[i].ToKline()
+	}
+	return ret
+}
+
+var klinePeriodDurations = map[int]time.Duration{
+	KLINE_PERIOD_1MIN:  time.Minute,
+	KLINE_PERIOD_5MIN:  5 * time.Minute,
+	KLINE_PERIOD_15MIN: 15 * time.Minute,
+	KLINE_PERIOD_30MIN: 30 * time.Minute,
+	KLINE_PERIOD_60MIN: time.Hour,
+	KLINE_PERIOD_4H:    4 * time.Hour,
+	KLINE_PERIOD_1DAY:  24 * time.Hour,
+	KLINE_PERIOD_1WEEK: 7 * 24 * time.Hour,
+	// 月和年按30天和365天估算, 只用于计算翻页的时间范围
+	KLINE_PERIOD_1MONTH: 30 * 24 * time.Hour,
+	KLINE_PERIOD_1YEAR:  365 * 24 * time.Hour,
+}
+
+/**
+ * K线周期的时长, 未知的周期返回0
+ */
+func KlinePeriodDuration(period int) time.Duration {
+	return klinePeriodDurations[period]
+}
+
+/**
+ * 交易所不支持的K线周期返回EX_ERR_NOT_SUPPORTED
+ */
+func KlinePeriodCode(codes map[int]string, period int) (string, error) {
+	code, ok := codes[period]
+	if !ok {
+		return "", EX_ERR_NOT_SUPPORTED
+	}
+	return code, nil
+}
+
+func klineDecimalKey(r interface{}) string {
+	return strconv.FormatInt(r.(KlineDecimal).Timestamp, 10)
+}
+
+func klineDecimalTime(r interface{}) int64 {
+	return r.(KlineDecimal).Timestamp * 1000
+}
+
+/**
+ * 翻页获取K线, since为毫秒时间戳. since大于0时从since开始按时间正序翻页, q.From为本页的开始时间;
+ * 否则从最新的K线开始倒序翻页, q.To为本页的结束时间, 0表示最新.
+ * fetch每页最多返回q.Limit根, 不要求排序, 交易所不支持的时间参数可以忽略.
+ * 返回按时间正序排列的最多size根K线
+ */
+func GetAllKlineDecimals(since int64, size, limit int, fetch func(q PageQuery) ([]KlineDecimal, error)) ([]KlineDecimal, error) {
+	if size <= 0 || limit <= 0 {
+		return nil, ErrPaginatorMisconfigured
+	}
+	if limit > size {
+		limit = size
+	}
+	mode := PAGING_BY_TIME_DESC
+	if since > 0 {
+		mode = PAGING_BY_TIME_ASC
+	}
+
+	p := NewPaginator(mode, limit, since, 0, func(q PageQuery) ([]interface{}, string, error) {
+		klines, err := fetch(q)
+		records := make([]interface{}, len(klines))
+		for i, k := range klines {
+			records[i] = k
+		}
+		return records, "", err
+	}, klineDecimalKey, klineDecimalTime)
+	// 不支持时间参数的交易所每页都返回相同的K线, 限制页数避免一直翻页
+	p.MaxPages = (size+limit-1)/limit + 1
+
+	var ret []KlineDecimal
+	for !p.Done() && len(ret) < size {
+		records, err := p.Next()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			ret = append(ret, r.(KlineDecimal))
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Timestamp < ret[j].Timestamp
+	})
+	if len(ret) > size {
+		if mode == PAGING_BY_TIME_ASC {
+			ret = ret[:size]
+		} else {
+			ret = ret[len(ret)-size:]
+		}
+	}
+	return ret, nil
+}
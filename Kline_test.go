@@ -0,0 +1,102 @@
+package goex
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// 模拟交易所的K线数据, 每分钟一根, 时间为秒
+func testKlineSource(start, count int64) []KlineDecimal {
+	klines := make([]KlineDecimal, count)
+	for i := range klines {
+		ts := start + int64(i)*60
+		klines[i] = KlineDecimal{Timestamp: ts, Close: decimal.New(ts, 0)}
+	}
+	return klines
+}
+
+// 支持from/to参数的接口, 正序翻页返回From之后的Limit根, 倒序翻页返回To之前的Limit根
+func rangeKlineFetch(source []KlineDecimal, calls *int) func(q PageQuery) ([]KlineDecimal, error) {
+	return func(q PageQuery) ([]KlineDecimal, error) {
+		*calls++
+		var ret []KlineDecimal
+		if q.From > 0 {
+			for _, k := range source {
+				if k.Timestamp*1000 >= q.From && len(ret) < q.Limit {
+					ret = append(ret, k)
+				}
+			}
+			return ret, nil
+		}
+		for i := len(source) - 1; i >= 0 && len(ret) < q.Limit; i-- {
+			if q.To == 0 || source[i].Timestamp*1000 <= q.To {
+				ret = append(ret, source[i])
+			}
+		}
+		return ret, nil
+	}
+}
+
+func TestGetAllKlineDecimals_Since(t *testing.T) {
+	source := testKlineSource(1000000, 250)
+	calls := 0
+	klines, err := GetAllKlineDecimals(1000000*1000+60*1000*10, 120, 50, rangeKlineFetch(source, &calls))
+	assert.Nil(t, err)
+	assert.Equal(t, 120, len(klines))
+	assert.Equal(t, int64(1000000+600), klines[0].Timestamp)
+	for i := 1; i < len(klines); i++ {
+		assert.Equal(t, klines[i-1].Timestamp+60, klines[i].Timestamp)
+	}
+	assert.True(t, calls >= 3)
+}
+
+func TestGetAllKlineDecimals_Latest(t *testing.T) {
+	source := testKlineSource(1000000, 250)
+	calls := 0
+	klines, err := GetAllKlineDecimals(0, 120, 50, rangeKlineFetch(source, &calls))
+	assert.Nil(t, err)
+	assert.Equal(t, 120, len(klines))
+	assert.Equal(t, source[249].Timestamp, klines[119].Timestamp)
+	assert.Equal(t, source[130].Timestamp, klines[0].Timestamp)
+
+	// 数据不足size根时返回全部
+	klines, err = GetAllKlineDecimals(0, 500, 100, rangeKlineFetch(source, &calls))
+	assert.Nil(t, err)
+	assert.Equal(t, 250, len(klines))
+}
+
+func TestGetAllKlineDecimals_IgnoredParams(t *testing.T) {
+	// 不支持时间参数的接口每次都返回最近的Limit根
+	source := testKlineSource(1000000, 250)
+	calls := 0
+	fetch := func(q PageQuery) ([]KlineDecimal, error) {
+		calls++
+		return source[len(source)-q.Limit:], nil
+	}
+	klines, err := GetAllKlineDecimals(0, 150, 100, fetch)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, len(klines))
+	assert.Equal(t, 3, calls)
+
+	klines, err = GetAllKlineDecimals(source[200].Timestamp*1000, 150, 100, fetch)
+	assert.Nil(t, err)
+	assert.Equal(t, 50, len(klines))
+	assert.Equal(t, source[200].Timestamp, klines[0].Timestamp)
+}
+
+func TestKlinePeriodCode(t *testing.T) {
+	codes := map[int]string{KLINE_PERIOD_1MIN: "1min"}
+	code, err := KlinePeriodCode(codes, KLINE_PERIOD_1MIN)
+	assert.Nil(t, err)
+	assert.Equal(t, "1min", code)
+	_, err = KlinePeriodCode(codes, KLINE_PERIOD_1YEAR)
+	assert.Equal(t, EX_ERR_NOT_SUPPORTED, err)
+
+	k := KlineDecimal{Pair: BTC_USDT, Timestamp: 60, Open: ToDecimal("1.5"), Vol: ToDecimal(2.25)}
+	kline := k.ToKline()
+	assert.Equal(t, 1.5, kline.Open)
+	assert.Equal(t, 2.25, kline.Vol)
+	assert.True(t, ToDecimal([]int{}).IsZero())
+}
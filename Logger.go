@@ -0,0 +1,279 @@
+package goex
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+type LogLevel int
+
+const (
+	LOG_DEBUG LogLevel = iota
+	LOG_INFO
+	LOG_WARN
+	LOG_ERROR
+)
+
+var logLevelNames = [...]string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+func (l LogLevel) String() string {
+	if l < 0 || int(l) >= len(logLevelNames) {
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+	return logLevelNames[l]
+}
+
+/**
+ * 结构化日志接口, keyvals为交替的键和值, 如logger.Warn("get ticker failed", "symbol", "BTCUSDT", "err", err)
+ */
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+	With(keyvals ...interface{}) Logger
+}
+
+/**
+ * 用一个函数实现Logger, With的字段放在每条日志的keyvals前面
+ */
+type LogFunc func(level LogLevel, msg string, keyvals []interface{})
+
+func NewLogger(fn LogFunc) Logger {
+	return funcLogger{fn: fn}
+}
+
+type funcLogger struct {
+	fn     LogFunc
+	fields []interface{}
+}
+
+func (l funcLogger) log(level LogLevel, msg string, keyvals []interface{}) {
+	if len(l.fields) > 0 {
+		keyvals = append(l.fields[:len(l.fields):len(l.fields)], keyvals...)
+	}
+	l.fn(level, msg, keyvals)
+}
+
+func (l funcLogger) Debug(msg string, keyvals ...interface{}) { l.log(LOG_DEBUG, msg, keyvals) }
+func (l funcLogger) Info(msg string, keyvals ...interface{})  { l.log(LOG_INFO, msg, keyvals) }
+func (l funcLogger) Warn(msg string, keyvals ...interface{})  { l.log(LOG_WARN, msg, keyvals) }
+func (l funcLogger) Error(msg string, keyvals ...interface{}) { l.log(LOG_ERROR, msg, keyvals) }
+
+func (l funcLogger) With(keyvals ...interface{}) Logger {
+	fields := append(l.fields[:len(l.fields):len(l.fields)], keyvals...)
+	return funcLogger{fn: l.fn, fields: fields}
+}
+
+/**
+ * 什么都不输出的Logger, 默认的日志
+ */
+var NopLogger Logger = NewLogger(func(level LogLevel, msg string, keyvals []interface{}) {})
+
+/**
+ * 输出到标准库log.Logger的Logger, 格式为"WARN get ticker failed symbol=BTCUSDT err=timeout", 低于minLevel的日志丢弃.
+ * logger为nil时使用log包的默认Logger
+ */
+func NewStdLogger(logger *log.Logger, minLevel LogLevel) Logger {
+	return NewLogger(func(level LogLevel, msg string, keyvals []interface{}) {
+		if level < minLevel {
+			return
+		}
+		var sb strings.Builder
+		sb.WriteString(level.String())
+		sb.WriteString(" ")
+		sb.WriteString(msg)
+		for i := 0; i < len(keyvals); i += 2 {
+			sb.WriteString(" ")
+			sb.WriteString(fmt.Sprint(keyvals[i]))
+			sb.WriteString("=")
+			if i+1 < len(keyvals) {
+				value := fmt.Sprint(keyvals[i+1])
+				if strings.ContainsAny(value, " \t\n\"") {
+					value = fmt.Sprintf("%q", value)
+				}
+				sb.WriteString(value)
+			}
+		}
+		if logger == nil {
+			log.Println(sb.String())
+		} else {
+			logger.Println(sb.String())
+		}
+	})
+}
+
+const redacted = "***"
+
+var secretKeyNames = []string{
+	"api[-_]?key", "api[-_]?secret(?:[-_]?key)?", "access[-_]?key(?:[-_]?id)?", "secret(?:[-_]?key)?",
+	"signature", "sign", "passphrase", "password", "authorization",
+	"x-mbx-apikey", "ok-access-(?:key|sign|passphrase)", "api-signature", "fc-access-(?:key|signature)",
+}
+
+var (
+	secretKeyRegexp   = regexp.MustCompile(`(?i)^(?:` + strings.Join(secretKeyNames, "|") + `)$`)
+	secretValueRegexp = regexp.MustCompile(`(?i)(^|[^a-z0-9])((?:` + strings.Join(secretKeyNames, "|") + `)["']?\s*[:=]\s*["']?)([^"'&\s,;}\]]+)`)
+)
+
+/**
+ * 键名是否为密钥、签名等敏感信息
+ */
+func IsSecretKey(key string) bool {
+	return secretKeyRegexp.MatchString(key)
+}
+
+func redactString(s string) string {
+	return secretValueRegexp.ReplaceAllString(s, "${1}${2}"+redacted)
+}
+
+/**
+ * 去掉日志值中的API Key、签名和passphrase: 字符串中的key=value、"key":"value"形式,
+ * 以及http.Header、url.Values、map[string]string、*http.Request和*url.URL中的敏感字段
+ */
+func Redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return redactString(v)
+	case []byte:
+		return redactString(string(v))
+	case error:
+		return redactString(v.Error())
+	case *url.URL:
+		if v == nil {
+			return v
+		}
+		return redactString(v.String())
+	case *http.Request:
+		if v == nil {
+			return v
+		}
+		return v.Method + " " + redactString(v.URL.String())
+	case http.Header:
+		ret := make(http.Header, len(v))
+		for k, values := range v {
+			if IsSecretKey(k) {
+				ret[k] = []string{redacted}
+			} else {
+				ret[k] = values
+			}
+		}
+		return ret
+	case url.Values:
+		ret := make(url.Values, len(v))
+		for k, values := range v {
+			if IsSecretKey(k) {
+				ret[k] = []string{redacted}
+			} else {
+				ret[k] = values
+			}
+		}
+		return ret
+	case map[string]string:
+		ret := make(map[string]string, len(v))
+		for k, s := range v {
+			if IsSecretKey(k) {
+				ret[k] = redacted
+			} else {
+				ret[k] = redactString(s)
+			}
+		}
+		return ret
+	}
+	return value
+}
+
+func redactKeyvals(keyvals []interface{}) []interface{} {
+	ret := make([]interface{}, len(keyvals))
+	for i := 0; i < len(keyvals); i++ {
+		if i%2 == 0 {
+			ret[i] = keyvals[i]
+			continue
+		}
+		if key, ok := keyvals[i-1].(string); ok && IsSecretKey(key) {
+			ret[i] = redacted
+		} else {
+			ret[i] = Redact(keyvals[i])
+		}
+	}
+	return ret
+}
+
+type redactingLogger struct {
+	logger Logger
+}
+
+func (l redactingLogger) Debug(msg string, keyvals ...interface{}) {
+	l.logger.Debug(redactString(msg), redactKeyvals(keyvals)...)
+}
+
+func (l redactingLogger) Info(msg string, keyvals ...interface{}) {
+	l.logger.Info(redactString(msg), redactKeyvals(keyvals)...)
+}
+
+func (l redactingLogger) Warn(msg string, keyvals ...interface{}) {
+	l.logger.Warn(redactString(msg), redactKeyvals(keyvals)...)
+}
+
+func (l redactingLogger) Error(msg string, keyvals ...interface{}) {
+	l.logger.Error(redactString(msg), redactKeyvals(keyvals)...)
+}
+
+func (l redactingLogger) With(keyvals ...interface{}) Logger {
+	return redactingLogger{l.logger.With(redactKeyvals(keyvals)...)}
+}
+
+/**
+ * 包装Logger, 输出前用Redact去掉敏感信息. logger为nil时返回NopLogger
+ */
+func NewRedactingLogger(logger Logger) Logger {
+	switch logger.(type) {
+	case nil:
+		return NopLogger
+	case redactingLogger:
+		return logger
+	}
+	return redactingLogger{logger}
+}
+
+type loggerHolder struct {
+	logger Logger
+}
+
+var defaultLogger atomic.Value
+
+/**
+ * 设置没有单独设置Logger的交易所实例和工具函数使用的日志, 默认为NopLogger
+ */
+func SetDefaultLogger(logger Logger) {
+	defaultLogger.Store(loggerHolder{NewRedactingLogger(logger)})
+}
+
+func DefaultLogger() Logger {
+	if holder, ok := defaultLogger.Load().(loggerHolder); ok {
+		return holder.logger
+	}
+	return NopLogger
+}
+
+/**
+ * logger为nil时返回DefaultLogger
+ */
+func LoggerOr(logger Logger) Logger {
+	if logger == nil {
+		return DefaultLogger()
+	}
+	return logger
+}
+
+/**
+ * 交易所实例的日志, logger为nil时使用DefaultLogger, 每条日志带exchange字段
+ */
+func ExchangeLogger(logger Logger, exchange string) Logger {
+	return LoggerOr(logger).With("exchange", exchange)
+}
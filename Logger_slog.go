@@ -0,0 +1,45 @@
+//go:build go1.21
+// +build go1.21
+
+package goex
+
+import (
+	"log/slog"
+)
+
+/**
+ * log/slog的适配
+ */
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+/**
+ * logger为nil时使用slog.Default()
+ */
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogLogger{logger: logger}
+}
+
+func (l *SlogLogger) Debug(msg string, keyvals ...interface{}) {
+	l.logger.Debug(msg, keyvals...)
+}
+
+func (l *SlogLogger) Info(msg string, keyvals ...interface{}) {
+	l.logger.Info(msg, keyvals...)
+}
+
+func (l *SlogLogger) Warn(msg string, keyvals ...interface{}) {
+	l.logger.Warn(msg, keyvals...)
+}
+
+func (l *SlogLogger) Error(msg string, keyvals ...interface{}) {
+	l.logger.Error(msg, keyvals...)
+}
+
+func (l *SlogLogger) With(keyvals ...interface{}) Logger {
+	return &SlogLogger{logger: l.logger.With(keyvals...)}
+}
@@ -0,0 +1,28 @@
+//go:build go1.21
+// +build go1.21
+
+package goex
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := NewRedactingLogger(NewSlogLogger(slog.New(handler))).With("exchange", "huobi")
+
+	logger.Error("sign failed", "api_key", "k1", "n", 1)
+	assert.Equal(t, "level=ERROR msg=\"sign failed\" exchange=huobi api_key=*** n=1\n", buf.String())
+}
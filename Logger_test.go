@@ -0,0 +1,47 @@
+package goex
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "https://api.binance.com/api/v3/order?symbol=BTCUSDT&timestamp=1&signature=***",
+		Redact("https://api.binance.com/api/v3/order?symbol=BTCUSDT&timestamp=1&signature=abcdef"))
+	assert.Equal(t, `{"apiKey":"***","passphrase": "***","symbol":"BTC"}`,
+		Redact(`{"apiKey":"k1","passphrase": "p1","symbol":"BTC"}`))
+	assert.Equal(t, "AccessKeyId=***&SignatureMethod=HmacSHA256&Signature=***",
+		Redact("AccessKeyId=k1&SignatureMethod=HmacSHA256&Signature=s1"))
+	assert.Equal(t, "request failed: OK-ACCESS-SIGN: ***", Redact(errors.New("request failed: OK-ACCESS-SIGN: s1")))
+
+	header := http.Header{"X-Mbx-Apikey": {"k1"}, "Content-Type": {"application/json"}}
+	assert.Equal(t, http.Header{"X-Mbx-Apikey": {"***"}, "Content-Type": {"application/json"}}, Redact(header))
+	assert.Equal(t, []string{"k1"}, header["X-Mbx-Apikey"])
+
+	values := url.Values{"api_key": {"k1"}, "symbol": {"BTCUSDT"}}
+	assert.Equal(t, url.Values{"api_key": {"***"}, "symbol": {"BTCUSDT"}}, Redact(values))
+}
+
+func TestRedactingLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewRedactingLogger(NewStdLogger(log.New(&buf, "", 0), LOG_INFO)).With("exchange", "binance")
+
+	logger.Debug("ignored")
+	logger.Warn("place order failed", "secret_key", "s1", "url", "/api/v3/order?signature=abc", "err", "bad request")
+	assert.Equal(t, "WARN place order failed exchange=binance secret_key=*** url=/api/v3/order?signature=*** err=\"bad request\"\n", buf.String())
+}
+
+func TestDefaultLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetDefaultLogger(NewStdLogger(log.New(&buf, "", 0), LOG_DEBUG))
+	defer SetDefaultLogger(nil)
+
+	ExchangeLogger(nil, "okex").Info("ok", "passphrase", "p1")
+	assert.Equal(t, "INFO ok exchange=okex passphrase=***\n", buf.String())
+}
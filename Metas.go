@@ -109,10 +109,27 @@ type TickerDecimal struct {
 	High         decimal.Decimal    `json:"high"`
 	Low          decimal.Decimal    `json:"low"`
 	Vol          decimal.Decimal    `json:"vol"`
+	QuoteVol     decimal.Decimal    `json:"quote_vol"` // 24小时成交额, 以计价币计
+	Change       decimal.Decimal    `json:"change"`    // 24小时涨跌幅, 0.01表示1%
 	Date         uint64       		`json:"date"` // 单位:秒(second)
 	ContractId   int64        		`json:"omitempty"`
 }
 
+/**
+ * 用开盘价和最新价补全涨跌幅, 交易所只返回涨跌幅时反推开盘价
+ */
+func (t *TickerDecimal) FillChange() {
+	if t.Open.Sign() > 0 {
+		if t.Change.IsZero() {
+			t.Change = t.Last.Sub(t.Open).Div(t.Open)
+		}
+		return
+	}
+	if one := decimal.New(1, 0).Add(t.Change); one.Sign() > 0 && t.Last.Sign() > 0 {
+		t.Open = t.Last.Div(one)
+	}
+}
+
 type DepthRecord struct {
 	Price,
 	Amount float64
@@ -299,3 +316,16 @@ type FutureFillDecimal struct {
 	TransactionTime int64
 	IsMaker bool
 }
+
+type FillDecimal struct {
+	FillId string
+	OrderId string
+	Currency CurrencyPair
+	Side TradeSide
+	Price decimal.Decimal
+	Qty decimal.Decimal
+	Fee decimal.Decimal
+	FeeCurrency string
+	IsMaker bool
+	TransactionTime int64
+}
@@ -0,0 +1,24 @@
+package goex
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTickerDecimal_FillChange(t *testing.T) {
+	ticker := TickerDecimal{Last: decimal.RequireFromString("110"), Open: decimal.RequireFromString("100")}
+	ticker.FillChange()
+	assert.Equal(t, "0.1", ticker.Change.String())
+
+	// 只有涨跌幅时反推开盘价
+	ticker = TickerDecimal{Last: decimal.RequireFromString("90"), Change: decimal.RequireFromString("-0.1")}
+	ticker.FillChange()
+	assert.Equal(t, "100", ticker.Open.String())
+
+	// 交易所返回的涨跌幅不会被覆盖
+	ticker = TickerDecimal{Last: decimal.RequireFromString("110"), Open: decimal.RequireFromString("100"), Change: decimal.RequireFromString("0.2")}
+	ticker.FillChange()
+	assert.Equal(t, "0.2", ticker.Change.String())
+}
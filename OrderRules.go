@@ -0,0 +1,393 @@
+package goex
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// 违反的下单规则
+type OrderRule string
+
+const (
+	RULE_PRICE        OrderRule = "price"        // 价格必须大于0
+	RULE_AMOUNT       OrderRule = "amount"       // 数量按最小变动取整后必须大于0
+	RULE_MIN_QTY      OrderRule = "min_qty"      // 最小下单数量
+	RULE_MIN_NOTIONAL OrderRule = "min_notional" // 最小下单金额
+	RULE_NOT_TRADING  OrderRule = "not_trading"  // 交易品种暂停交易或已下线
+)
+
+/**
+ * 本地检查不通过的订单, 没有发送到交易所. 错误类别为ERR_INVALID_PARAM
+ */
+type OrderRuleError struct {
+	Exchange string
+	Symbol   string
+	Rule     OrderRule
+	Value    decimal.Decimal // 取整后的值
+	Limit    decimal.Decimal // 规则要求的值
+}
+
+func (e *OrderRuleError) Error() string {
+	switch e.Rule {
+	case RULE_MIN_QTY, RULE_MIN_NOTIONAL:
+		return fmt.Sprintf("%s %s: %s %s below %s", e.Exchange, e.Symbol, e.Rule, e.Value, e.Limit)
+	case RULE_NOT_TRADING:
+		return fmt.Sprintf("%s %s: not trading", e.Exchange, e.Symbol)
+	}
+	return fmt.Sprintf("%s %s: invalid %s %s", e.Exchange, e.Symbol, e.Rule, e.Value)
+}
+
+func (e *OrderRuleError) Unwrap() error {
+	return ERR_INVALID_PARAM
+}
+
+var ErrNoContractValue = errors.New("instrument has no contract value")
+
+/**
+ * 按step取整, roundUp为false时向下取整
+ */
+func RoundToStep(value, step decimal.Decimal, roundUp bool) decimal.Decimal {
+	if step.Sign() <= 0 {
+		return value
+	}
+	n := value.Div(step)
+	if roundUp {
+		n = n.Ceil()
+	} else {
+		n = n.Floor()
+	}
+	return n.Mul(step)
+}
+
+/**
+ * 价格按TickSize取整: 买单向下, 卖单向上, 取整后的价格不会比原价格更差
+ */
+func (inst *Instrument) RoundPrice(side TradeSide, price decimal.Decimal) decimal.Decimal {
+	roundUp := side == SELL || side == SELL_MARKET
+	return RoundToStep(price, inst.TickSize, roundUp)
+}
+
+/**
+ * 数量按LotSize向下取整, 不会超过原数量
+ */
+func (inst *Instrument) RoundAmount(amount decimal.Decimal) decimal.Decimal {
+	return RoundToStep(amount, inst.LotSize, false)
+}
+
+func (inst *Instrument) ruleError(rule OrderRule, value, limit decimal.Decimal) *OrderRuleError {
+	return &OrderRuleError{Exchange: inst.Exchange, Symbol: inst.Symbol, Rule: rule, Value: value, Limit: limit}
+}
+
+/**
+ * 取整并检查限价单, 返回取整后的价格和数量, 不满足规则时返回*OrderRuleError
+ */
+func (inst *Instrument) PrepareLimitOrder(side TradeSide, price, amount decimal.Decimal) (decimal.Decimal, decimal.Decimal, error) {
+	if !inst.IsTrading() {
+		return price, amount, inst.ruleError(RULE_NOT_TRADING, decimal.Zero, decimal.Zero)
+	}
+	price = inst.RoundPrice(side, price)
+	if price.Sign() <= 0 {
+		return price, amount, inst.ruleError(RULE_PRICE, price, inst.TickSize)
+	}
+	amount, err := inst.checkAmount(amount)
+	if err != nil {
+		return price, amount, err
+	}
+	if inst.MinNotional.Sign() > 0 {
+		if notional := price.Mul(amount); notional.LessThan(inst.MinNotional) {
+			return price, amount, inst.ruleError(RULE_MIN_NOTIONAL, notional, inst.MinNotional)
+		}
+	}
+	return price, amount, nil
+}
+
+/**
+ * 取整并检查市价单的数量. 部分交易所市价买单的数量是计价币金额, 这种情况不要调用
+ */
+func (inst *Instrument) PrepareMarketOrder(amount decimal.Decimal) (decimal.Decimal, error) {
+	if !inst.IsTrading() {
+		return amount, inst.ruleError(RULE_NOT_TRADING, decimal.Zero, decimal.Zero)
+	}
+	return inst.checkAmount(amount)
+}
+
+func (inst *Instrument) checkAmount(amount decimal.Decimal) (decimal.Decimal, error) {
+	amount = inst.RoundAmount(amount)
+	if amount.Sign() <= 0 {
+		return amount, inst.ruleError(RULE_AMOUNT, amount, inst.LotSize)
+	}
+	if inst.MinQty.Sign() > 0 && amount.LessThan(inst.MinQty) {
+		return amount, inst.ruleError(RULE_MIN_QTY, amount, inst.MinQty)
+	}
+	return amount, nil
+}
+
+/**
+ * 币本位(反向)合约: 结算币种为基础币种, 面值以计价币计, 如OKEx BTC-USD-190628每张100美元
+ */
+func (inst *Instrument) IsInverse() bool {
+	return inst.Type != INSTRUMENT_SPOT && strings.EqualFold(inst.SettleCurrency.Symbol, inst.Pair.CurrencyA.Symbol)
+}
+
+func (inst *Instrument) contractSize() decimal.Decimal {
+	if inst.Multiplier.Sign() > 0 {
+		return inst.ContractValue.Mul(inst.Multiplier)
+	}
+	return inst.ContractValue
+}
+
+/**
+ * 币的数量换算为合约张数, 按LotSize向下取整. 币本位合约按price换算, U本位合约与价格无关
+ */
+func (inst *Instrument) CoinToContracts(coin, price decimal.Decimal) (decimal.Decimal, error) {
+	size := inst.contractSize()
+	if size.Sign() <= 0 {
+		return decimal.Zero, ErrNoContractValue
+	}
+	var contracts decimal.Decimal
+	if inst.IsInverse() {
+		if price.Sign() <= 0 {
+			return decimal.Zero, inst.ruleError(RULE_PRICE, price, inst.TickSize)
+		}
+		contracts = coin.Mul(price).Div(size)
+	} else {
+		contracts = coin.Div(size)
+	}
+	return inst.RoundAmount(contracts), nil
+}
+
+/**
+ * 合约张数换算为币的数量
+ */
+func (inst *Instrument) ContractsToCoin(contracts, price decimal.Decimal) (decimal.Decimal, error) {
+	size := inst.contractSize()
+	if size.Sign() <= 0 {
+		return decimal.Zero, ErrNoContractValue
+	}
+	if inst.IsInverse() {
+		if price.Sign() <= 0 {
+			return decimal.Zero, inst.ruleError(RULE_PRICE, price, inst.TickSize)
+		}
+		return contracts.Mul(size).Div(price), nil
+	}
+	return contracts.Mul(size), nil
+}
+
+/**
+ * 按币的数量下合约单: 价格按TickSize取整, 币的数量按取整后的价格换算为合约张数并检查规则, 返回价格和张数.
+ * OKExV3、HuobiFuture和BitMEX的下单方法没有统一的接口, 下单前调用这个方法换算
+ */
+func (inst *Instrument) PrepareContractOrder(side TradeSide, price, coin decimal.Decimal) (decimal.Decimal, decimal.Decimal, error) {
+	if !inst.IsTrading() {
+		return price, coin, inst.ruleError(RULE_NOT_TRADING, decimal.Zero, decimal.Zero)
+	}
+	price = inst.RoundPrice(side, price)
+	if price.Sign() <= 0 {
+		return price, coin, inst.ruleError(RULE_PRICE, price, inst.TickSize)
+	}
+	contracts, err := inst.PrepareContractAmount(coin, price)
+	return price, contracts, err
+}
+
+/**
+ * 币的数量换算为合约张数并检查规则, 用于市价或对手价单. 币本位合约按price换算, U本位合约price可以为零
+ */
+func (inst *Instrument) PrepareContractAmount(coin, price decimal.Decimal) (decimal.Decimal, error) {
+	if !inst.IsTrading() {
+		return coin, inst.ruleError(RULE_NOT_TRADING, decimal.Zero, decimal.Zero)
+	}
+	contracts, err := inst.CoinToContracts(coin, price)
+	if err != nil {
+		return contracts, err
+	}
+	return inst.checkAmount(contracts)
+}
+
+/**
+ * 下单前按交易品种规则取整和检查的API, 其他方法直接调用原API.
+ * 交易品种从Catalog中按交易对查找现货品种, 查找失败时不下单
+ */
+type OrderRulesAPI struct {
+	API
+	Catalog *InstrumentCatalog
+}
+
+func NewOrderRulesAPI(api API, catalog *InstrumentCatalog) *OrderRulesAPI {
+	return &OrderRulesAPI{API: api, Catalog: catalog}
+}
+
+func (a *OrderRulesAPI) instrument(currency CurrencyPair) (*Instrument, error) {
+	instruments, err := a.Catalog.Find(currency, INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", a.Catalog.Exchange, currency, err)
+	}
+	return &instruments[0], nil
+}
+
+func (a *OrderRulesAPI) prepareLimit(side TradeSide, amount, price string, currency CurrencyPair) (string, string, error) {
+	inst, err := a.instrument(currency)
+	if err != nil {
+		return "", "", err
+	}
+	p, err := decimal.NewFromString(price)
+	if err != nil {
+		return "", "", inst.ruleError(RULE_PRICE, decimal.Zero, inst.TickSize)
+	}
+	q, err := decimal.NewFromString(amount)
+	if err != nil {
+		return "", "", inst.ruleError(RULE_AMOUNT, decimal.Zero, inst.LotSize)
+	}
+	p, q, err = inst.PrepareLimitOrder(side, p, q)
+	if err != nil {
+		return "", "", err
+	}
+	return q.String(), p.String(), nil
+}
+
+func (a *OrderRulesAPI) LimitBuy(amount, price string, currency CurrencyPair) (*Order, error) {
+	amount, price, err := a.prepareLimit(BUY, amount, price, currency)
+	if err != nil {
+		return nil, err
+	}
+	return a.API.LimitBuy(amount, price, currency)
+}
+
+func (a *OrderRulesAPI) LimitSell(amount, price string, currency CurrencyPair) (*Order, error) {
+	amount, price, err := a.prepareLimit(SELL, amount, price, currency)
+	if err != nil {
+		return nil, err
+	}
+	return a.API.LimitSell(amount, price, currency)
+}
+
+/**
+ * 市价卖单只检查数量. 市价买单的数量在不同交易所含义不同, 不做处理
+ */
+func (a *OrderRulesAPI) MarketSell(amount, price string, currency CurrencyPair) (*Order, error) {
+	inst, err := a.instrument(currency)
+	if err != nil {
+		return nil, err
+	}
+	q, err := decimal.NewFromString(amount)
+	if err != nil {
+		return nil, inst.ruleError(RULE_AMOUNT, decimal.Zero, inst.LotSize)
+	}
+	if q, err = inst.PrepareMarketOrder(q); err != nil {
+		return nil, err
+	}
+	return a.API.MarketSell(q.String(), price, currency)
+}
+
+// 交割合约类型对应按交割时间排序后的位置
+var futureContractIndex = map[string]int{
+	THIS_WEEK_CONTRACT:  0,
+	NEXT_WEEK_CONTRACT:  1,
+	QUARTER_CONTRACT:    2,
+	BI_QUARTER_CONTRACT: 3,
+}
+
+/**
+ * 合约下单前按交易品种规则取整、检查并换算张数的API, 其他方法直接调用原API.
+ * PlaceFutureOrder的amount为币的数量, 换算为合约张数后下单.
+ * 交易品种按contractType查找: 先作为合约代码查找, 再按交易对查找永续合约(SWAP_CONTRACT)或交割合约(按交割时间排序)
+ */
+type FutureOrderRulesAPI struct {
+	FutureRestAPI
+	Catalog *InstrumentCatalog
+}
+
+func NewFutureOrderRulesAPI(api FutureRestAPI, catalog *InstrumentCatalog) *FutureOrderRulesAPI {
+	return &FutureOrderRulesAPI{FutureRestAPI: api, Catalog: catalog}
+}
+
+func (a *FutureOrderRulesAPI) instrument(currencyPair CurrencyPair, contractType string) (*Instrument, error) {
+	inst, err := a.Catalog.Get(contractType)
+	if err == nil {
+		return &inst, nil
+	}
+	if !errors.Is(err, ErrInstrumentNotFound) {
+		return nil, err
+	}
+
+	typ := INSTRUMENT_FUTURE
+	if contractType == SWAP_CONTRACT {
+		typ = INSTRUMENT_SWAP
+	}
+	instruments, err := a.Catalog.Find(currencyPair, typ)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s: %w", a.Catalog.Exchange, currencyPair, contractType, err)
+	}
+	if typ == INSTRUMENT_SWAP {
+		return &instruments[0], nil
+	}
+
+	// 跳过已交割的合约
+	var futures []Instrument
+	for _, inst := range instruments {
+		if inst.Status != INSTRUMENT_STATUS_DELISTED {
+			futures = append(futures, inst)
+		}
+	}
+	sort.Slice(futures, func(i, j int) bool {
+		return futures[i].Expiry.Before(futures[j].Expiry)
+	})
+	idx, ok := futureContractIndex[contractType]
+	if !ok || idx >= len(futures) {
+		return nil, fmt.Errorf("%s %s %s: %w", a.Catalog.Exchange, currencyPair, contractType, ErrInstrumentNotFound)
+	}
+	return &futures[idx], nil
+}
+
+/**
+ * 开多和平空为买, 开空和平多为卖
+ */
+func futureOrderSide(openType int) TradeSide {
+	if openType == OPEN_BUY || openType == CLOSE_SELL {
+		return BUY
+	}
+	return SELL
+}
+
+/**
+ * amount为币的数量. 对手价下单(matchPrice为1)时不检查价格, 币本位合约按最新价换算张数
+ */
+func (a *FutureOrderRulesAPI) PlaceFutureOrder(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice, leverRate int) (string, error) {
+	inst, err := a.instrument(currencyPair, contractType)
+	if err != nil {
+		return "", err
+	}
+	coin, err := decimal.NewFromString(amount)
+	if err != nil {
+		return "", inst.ruleError(RULE_AMOUNT, decimal.Zero, inst.LotSize)
+	}
+
+	var contracts decimal.Decimal
+	if matchPrice == 1 {
+		var ref decimal.Decimal
+		if inst.IsInverse() {
+			ticker, err := a.FutureRestAPI.GetFutureTicker(currencyPair, contractType)
+			if err != nil {
+				return "", err
+			}
+			ref = decimal.NewFromFloat(ticker.Last)
+		}
+		if contracts, err = inst.PrepareContractAmount(coin, ref); err != nil {
+			return "", err
+		}
+	} else {
+		p, err := decimal.NewFromString(price)
+		if err != nil {
+			return "", inst.ruleError(RULE_PRICE, decimal.Zero, inst.TickSize)
+		}
+		p, contracts, err = inst.PrepareContractOrder(futureOrderSide(openType), p, coin)
+		if err != nil {
+			return "", err
+		}
+		price = p.String()
+	}
+	return a.FutureRestAPI.PlaceFutureOrder(currencyPair, contractType, price, contracts.String(), openType, matchPrice, leverRate)
+}
@@ -0,0 +1,193 @@
+package goex
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustDecimal(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func testSpotInstrument() Instrument {
+	return Instrument{
+		Exchange:    "test",
+		Symbol:      "BTCUSDT",
+		Pair:        BTC_USDT,
+		Type:        INSTRUMENT_SPOT,
+		TickSize:    mustDecimal("0.01"),
+		LotSize:     mustDecimal("0.001"),
+		MinQty:      mustDecimal("0.002"),
+		MinNotional: mustDecimal("10"),
+	}
+}
+
+func TestInstrument_RoundPrice(t *testing.T) {
+	inst := testSpotInstrument()
+	assert.Equal(t, "100.12", inst.RoundPrice(BUY, mustDecimal("100.129")).String())
+	assert.Equal(t, "100.13", inst.RoundPrice(SELL, mustDecimal("100.121")).String())
+	assert.Equal(t, "100.12", inst.RoundPrice(SELL, mustDecimal("100.12")).String())
+	assert.Equal(t, "1.234", inst.RoundAmount(mustDecimal("1.2349")).String())
+
+	inst.TickSize = mustDecimal("0.5")
+	assert.Equal(t, "8000.5", inst.RoundPrice(BUY, mustDecimal("8000.9")).String())
+	assert.Equal(t, "8001", inst.RoundPrice(SELL, mustDecimal("8000.6")).String())
+}
+
+func TestInstrument_PrepareLimitOrder(t *testing.T) {
+	inst := testSpotInstrument()
+
+	price, amount, err := inst.PrepareLimitOrder(BUY, mustDecimal("10000.005"), mustDecimal("0.0019"))
+	var ruleErr *OrderRuleError
+	assert.True(t, errors.As(err, &ruleErr))
+	assert.Equal(t, RULE_MIN_QTY, ruleErr.Rule)
+	assert.True(t, errors.Is(err, ERR_INVALID_PARAM))
+	assert.Equal(t, ERR_INVALID_PARAM, ErrorCategoryOf(err))
+
+	_, _, err = inst.PrepareLimitOrder(BUY, mustDecimal("1000"), mustDecimal("0.0099"))
+	assert.True(t, errors.As(err, &ruleErr))
+	assert.Equal(t, RULE_MIN_NOTIONAL, ruleErr.Rule)
+	assert.Equal(t, "9", ruleErr.Value.String())
+
+	_, _, err = inst.PrepareLimitOrder(BUY, mustDecimal("0.001"), mustDecimal("1"))
+	assert.True(t, errors.As(err, &ruleErr))
+	assert.Equal(t, RULE_PRICE, ruleErr.Rule)
+
+	price, amount, err = inst.PrepareLimitOrder(SELL, mustDecimal("10000.001"), mustDecimal("0.0105"))
+	assert.Nil(t, err)
+	assert.Equal(t, "10000.01", price.String())
+	assert.Equal(t, "0.01", amount.String())
+
+	inst.Status = INSTRUMENT_STATUS_SUSPENDED
+	_, _, err = inst.PrepareLimitOrder(SELL, mustDecimal("10000"), mustDecimal("1"))
+	assert.True(t, errors.As(err, &ruleErr))
+	assert.Equal(t, RULE_NOT_TRADING, ruleErr.Rule)
+}
+
+func TestInstrument_Contracts(t *testing.T) {
+	// 币本位, 每张100美元
+	inverse := Instrument{Pair: BTC_USD, Type: INSTRUMENT_FUTURE, ContractValue: mustDecimal("100"), LotSize: mustDecimal("1"), SettleCurrency: BTC}
+	assert.True(t, inverse.IsInverse())
+	contracts, err := inverse.CoinToContracts(mustDecimal("1.5"), mustDecimal("8000"))
+	assert.Nil(t, err)
+	assert.Equal(t, "120", contracts.String())
+	coin, err := inverse.ContractsToCoin(mustDecimal("120"), mustDecimal("8000"))
+	assert.Nil(t, err)
+	assert.Equal(t, "1.5", coin.String())
+	_, err = inverse.CoinToContracts(mustDecimal("1"), decimal.Zero)
+	assert.True(t, errors.Is(err, ERR_INVALID_PARAM))
+
+	// U本位, 每张0.01 BTC
+	linear := Instrument{Pair: BTC_USDT, Type: INSTRUMENT_SWAP, ContractValue: mustDecimal("0.01"), Multiplier: mustDecimal("1"), LotSize: mustDecimal("1"), SettleCurrency: USDT}
+	assert.False(t, linear.IsInverse())
+	contracts, err = linear.CoinToContracts(mustDecimal("0.257"), mustDecimal("8000"))
+	assert.Nil(t, err)
+	assert.Equal(t, "25", contracts.String())
+	coin, err = linear.ContractsToCoin(mustDecimal("25"), decimal.Zero)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.25", coin.String())
+
+	_, err = (&Instrument{Type: INSTRUMENT_SWAP}).CoinToContracts(mustDecimal("1"), mustDecimal("1"))
+	assert.Equal(t, ErrNoContractValue, err)
+}
+
+type orderRecordAPI struct {
+	API
+	amount, price string
+}
+
+func (a *orderRecordAPI) LimitBuy(amount, price string, currency CurrencyPair) (*Order, error) {
+	a.amount, a.price = amount, price
+	return &Order{Currency: currency}, nil
+}
+
+func (a *orderRecordAPI) MarketSell(amount, price string, currency CurrencyPair) (*Order, error) {
+	a.amount, a.price = amount, price
+	return &Order{Currency: currency}, nil
+}
+
+func TestOrderRulesAPI(t *testing.T) {
+	catalog := NewInstrumentCatalog("test", &fakeInstrumentAPI{instruments: []Instrument{testSpotInstrument()}})
+	inner := &orderRecordAPI{}
+	api := NewOrderRulesAPI(inner, catalog)
+
+	_, err := api.LimitBuy("0.12345", "10000.129", BTC_USDT)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.123", inner.amount)
+	assert.Equal(t, "10000.12", inner.price)
+
+	inner.amount = ""
+	_, err = api.LimitBuy("0.0001", "10000", BTC_USDT)
+	assert.True(t, errors.Is(err, ERR_INVALID_PARAM))
+	assert.Equal(t, "", inner.amount)
+
+	_, err = api.MarketSell("0.0025", "0", BTC_USDT)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.002", inner.amount)
+
+	_, err = api.LimitBuy("1", "100", ETH_USDT)
+	assert.True(t, errors.Is(err, ErrInstrumentNotFound))
+}
+
+type futureOrderRecordAPI struct {
+	FutureRestAPI
+	contractType, amount, price string
+}
+
+func (a *futureOrderRecordAPI) GetFutureTicker(currencyPair CurrencyPair, contractType string) (*Ticker, error) {
+	return &Ticker{Pair: currencyPair, Last: 8000}, nil
+}
+
+func (a *futureOrderRecordAPI) PlaceFutureOrder(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice, leverRate int) (string, error) {
+	a.contractType, a.amount, a.price = contractType, amount, price
+	return "1", nil
+}
+
+func TestFutureOrderRulesAPI(t *testing.T) {
+	future := func(symbol string, expiry time.Time, status InstrumentStatus) Instrument {
+		return Instrument{Exchange: "test", Symbol: symbol, Pair: BTC_USD, Type: INSTRUMENT_FUTURE, TickSize: mustDecimal("0.01"),
+			LotSize: mustDecimal("1"), MinQty: mustDecimal("1"), ContractValue: mustDecimal("100"), Expiry: expiry, SettleCurrency: BTC, Status: status}
+	}
+	now := time.Now()
+	instruments := []Instrument{
+		future("BTC-USD-Q", now.Add(60*24*time.Hour), INSTRUMENT_STATUS_TRADING),
+		future("BTC-USD-NW", now.Add(10*24*time.Hour), INSTRUMENT_STATUS_TRADING),
+		future("BTC-USD-OLD", now.Add(-24*time.Hour), INSTRUMENT_STATUS_DELISTED),
+		future("BTC-USD-TW", now.Add(3*24*time.Hour), INSTRUMENT_STATUS_TRADING),
+		{Exchange: "test", Symbol: "BTC-USDT-SWAP", Pair: BTC_USDT, Type: INSTRUMENT_SWAP, TickSize: mustDecimal("0.1"),
+			LotSize: mustDecimal("1"), MinQty: mustDecimal("1"), ContractValue: mustDecimal("0.01"), SettleCurrency: USDT},
+	}
+	catalog := NewInstrumentCatalog("test", &fakeInstrumentAPI{instruments: instruments})
+	inner := &futureOrderRecordAPI{}
+	api := NewFutureOrderRulesAPI(inner, catalog)
+
+	// 开空为卖单, 价格向上取整, 1.5 BTC * 8000.01 / 100 = 120张
+	_, err := api.PlaceFutureOrder(BTC_USD, QUARTER_CONTRACT, "8000.001", "1.5", OPEN_SELL, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, QUARTER_CONTRACT, inner.contractType)
+	assert.Equal(t, "8000.01", inner.price)
+	assert.Equal(t, "120", inner.amount)
+
+	// 对手价按最新价换算
+	_, err = api.PlaceFutureOrder(BTC_USD, "btc-usd-nw", "0", "0.5", OPEN_BUY, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, "0", inner.price)
+	assert.Equal(t, "40", inner.amount)
+
+	_, err = api.PlaceFutureOrder(BTC_USDT, SWAP_CONTRACT, "8000.05", "0.257", CLOSE_SELL, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, "8000", inner.price)
+	assert.Equal(t, "25", inner.amount)
+
+	inner.amount = ""
+	_, err = api.PlaceFutureOrder(BTC_USD, THIS_WEEK_CONTRACT, "8000", "0.01", OPEN_BUY, 0, 10)
+	assert.True(t, errors.Is(err, ERR_INVALID_PARAM))
+	assert.Equal(t, "", inner.amount)
+
+	_, err = api.PlaceFutureOrder(BTC_USD, BI_QUARTER_CONTRACT, "8000", "1", OPEN_BUY, 0, 10)
+	assert.True(t, errors.Is(err, ErrInstrumentNotFound))
+}
@@ -0,0 +1,239 @@
+package goex
+
+import "errors"
+
+// 分页方式
+const (
+	PAGING_BY_PAGE      = 1 + iota // 页码翻页, Page从1开始
+	PAGING_BY_CURSOR               // 游标翻页, 由Fetch返回下一页游标
+	PAGING_BY_TIME_ASC             // 按时间正序翻页, 每页从上一页最晚的时间开始
+	PAGING_BY_TIME_DESC            // 按时间倒序翻页, 每页到上一页最早的时间为止
+)
+
+type PageQuery struct {
+	Page   int
+	Cursor string
+	From   int64 // 毫秒, 0表示不限制
+	To     int64 // 毫秒, 0表示不限制
+	Limit  int
+}
+
+// 返回一页记录, 以及游标翻页时下一页的游标
+type PageFetchFunc func(q PageQuery) ([]interface{}, string, error)
+
+/**
+ * 通用的历史记录翻页器, 适用于订单历史、成交明细和账单流水
+ * 页与页之间边界上重复返回的记录按Key去重; 提供Time时会丢弃[From, To]以外的记录,
+ * 并在页码/游标翻页遇到早于From的记录时停止(这类接口都按时间倒序返回)
+ */
+type Paginator struct {
+	Mode     int
+	Limit    int
+	From     int64
+	To       int64
+	MaxPages int // 0表示不限制
+
+	Fetch PageFetchFunc
+	Key   func(record interface{}) string
+	Time  func(record interface{}) int64
+
+	page   int
+	cursor string
+	from   int64
+	to     int64
+	seen   map[string]bool
+	done   bool
+}
+
+var ErrPaginatorMisconfigured = errors.New("paginator misconfigured")
+
+func NewPaginator(mode, limit int, from, to int64, fetch PageFetchFunc, key func(interface{}) string, tm func(interface{}) int64) *Paginator {
+	return &Paginator{
+		Mode:  mode,
+		Limit: limit,
+		From:  from,
+		To:    to,
+		Fetch: fetch,
+		Key:   key,
+		Time:  tm,
+	}
+}
+
+func (p *Paginator) Done() bool {
+	return p.done
+}
+
+/**
+ * 获取下一页中未出现过的记录, 翻页结束时返回nil
+ */
+func (p *Paginator) Next() ([]interface{}, error) {
+	if p.done {
+		return nil, nil
+	}
+	if p.Fetch == nil || p.Limit <= 0 {
+		return nil, ErrPaginatorMisconfigured
+	}
+	if (p.Mode == PAGING_BY_TIME_ASC || p.Mode == PAGING_BY_TIME_DESC) && p.Time == nil {
+		return nil, ErrPaginatorMisconfigured
+	}
+	if p.seen == nil {
+		p.seen = make(map[string]bool)
+		p.from = p.From
+		p.to = p.To
+	}
+
+	for !p.done {
+		p.page++
+		if p.MaxPages > 0 && p.page >= p.MaxPages {
+			p.done = true
+		}
+
+		q := PageQuery{Page: p.page, Cursor: p.cursor, From: p.from, To: p.to, Limit: p.Limit}
+		records, next, err := p.Fetch(q)
+		if err != nil {
+			p.page--
+			p.done = false
+			return nil, err
+		}
+
+		if len(records) < p.Limit {
+			p.done = true
+		}
+
+		var ret []interface{}
+		var minTime, maxTime int64
+		for i, r := range records {
+			if p.Time != nil {
+				t := p.Time(r)
+				if i == 0 || t < minTime {
+					minTime = t
+				}
+				if i == 0 || t > maxTime {
+					maxTime = t
+				}
+				if p.From > 0 && t < p.From || p.To > 0 && t > p.To {
+					continue
+				}
+			}
+			if p.Key != nil {
+				k := p.Key(r)
+				if p.seen[k] {
+					continue
+				}
+				p.seen[k] = true
+			}
+			ret = append(ret, r)
+		}
+
+		switch p.Mode {
+		case PAGING_BY_PAGE:
+			if p.Time != nil && p.From > 0 && len(records) > 0 && minTime < p.From {
+				p.done = true
+			}
+		case PAGING_BY_CURSOR:
+			if next == "" || next == p.cursor {
+				p.done = true
+			}
+			p.cursor = next
+			if p.Time != nil && p.From > 0 && len(records) > 0 && minTime < p.From {
+				p.done = true
+			}
+		case PAGING_BY_TIME_ASC:
+			if maxTime <= p.from {
+				// 整页都在同一毫秒, 只能跳过该毫秒
+				maxTime = p.from + 1
+			}
+			p.from = maxTime
+			if p.To > 0 && p.from > p.To {
+				p.done = true
+			}
+		case PAGING_BY_TIME_DESC:
+			if p.to > 0 && minTime >= p.to {
+				minTime = p.to - 1
+			}
+			p.to = minTime
+			if p.From > 0 && p.to < p.From {
+				p.done = true
+			}
+		default:
+			return nil, ErrPaginatorMisconfigured
+		}
+
+		if len(records) == 0 {
+			p.done = true
+		}
+		if len(ret) > 0 || p.done {
+			return ret, nil
+		}
+	}
+	return nil, nil
+}
+
+/**
+ * 翻页直到结束, 返回全部记录
+ */
+func (p *Paginator) All() ([]interface{}, error) {
+	var ret []interface{}
+	for !p.done {
+		records, err := p.Next()
+		if err != nil {
+			return ret, err
+		}
+		ret = append(ret, records...)
+	}
+	return ret, nil
+}
+
+func orderDecimalKey(r interface{}) string {
+	o := r.(OrderDecimal)
+	return o.OrderID2
+}
+
+func orderDecimalTime(r interface{}) int64 {
+	o := r.(OrderDecimal)
+	return o.Timestamp
+}
+
+/**
+ * 翻页获取OrderDecimal, fetch返回一页订单及游标翻页时下一页的游标
+ */
+func GetAllOrderDecimals(mode, limit int, from, to int64, fetch func(q PageQuery) ([]OrderDecimal, string, error)) ([]OrderDecimal, error) {
+	p := NewPaginator(mode, limit, from, to, func(q PageQuery) ([]interface{}, string, error) {
+		orders, next, err := fetch(q)
+		records := make([]interface{}, len(orders))
+		for i, o := range orders {
+			records[i] = o
+		}
+		return records, next, err
+	}, orderDecimalKey, orderDecimalTime)
+
+	records, err := p.All()
+	ret := make([]OrderDecimal, len(records))
+	for i, r := range records {
+		ret[i] = r.(OrderDecimal)
+	}
+	return ret, err
+}
+
+/**
+ * 按页码翻页获取API的全部历史订单
+ */
+func GetAllOrderHistorys(api API, currency CurrencyPair, pageSize int) ([]Order, error) {
+	p := NewPaginator(PAGING_BY_PAGE, pageSize, 0, 0, func(q PageQuery) ([]interface{}, string, error) {
+		orders, err := api.GetOrderHistorys(currency, q.Page, q.Limit)
+		records := make([]interface{}, len(orders))
+		for i, o := range orders {
+			records[i] = o
+		}
+		return records, "", err
+	}, func(r interface{}) string {
+		return r.(Order).OrderID2
+	}, nil)
+
+	records, err := p.All()
+	ret := make([]Order, len(records))
+	for i, r := range records {
+		ret[i] = r.(Order)
+	}
+	return ret, err
+}
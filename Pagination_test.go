@@ -0,0 +1,83 @@
+package goex
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 100条订单, 时间1..100, 接口按时间倒序返回
+func newOrderDecimals() []OrderDecimal {
+	var orders []OrderDecimal
+	for i := 100; i >= 1; i-- {
+		orders = append(orders, OrderDecimal{OrderID2: fmt.Sprint(i), Timestamp: int64(i)})
+	}
+	return orders
+}
+
+func TestPaginator_Page(t *testing.T) {
+	orders := newOrderDecimals()
+	var calls int
+	ret, err := GetAllOrderDecimals(PAGING_BY_PAGE, 10, 35, 0, func(q PageQuery) ([]OrderDecimal, string, error) {
+		calls++
+		start := (q.Page - 1) * q.Limit
+		// 翻页期间插入新订单, 导致边界上的记录重复
+		if q.Page > 1 {
+			start--
+		}
+		end := start + q.Limit
+		if end > len(orders) {
+			end = len(orders)
+		}
+		return orders[start:end], "", nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 66, len(ret))
+	assert.Equal(t, "35", ret[len(ret)-1].OrderID2)
+	assert.Equal(t, 7, calls)
+}
+
+func TestPaginator_TimeDesc(t *testing.T) {
+	orders := newOrderDecimals()
+	ret, err := GetAllOrderDecimals(PAGING_BY_TIME_DESC, 10, 0, 50, func(q PageQuery) ([]OrderDecimal, string, error) {
+		var page []OrderDecimal
+		for _, o := range orders {
+			// 含边界, 上一页最早的记录会重复返回
+			if o.Timestamp <= q.To+1 && len(page) < q.Limit {
+				page = append(page, o)
+			}
+		}
+		return page, "", nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 50, len(ret))
+	assert.Equal(t, "50", ret[0].OrderID2)
+	assert.Equal(t, "1", ret[49].OrderID2)
+}
+
+func TestPaginator_Cursor(t *testing.T) {
+	orders := newOrderDecimals()
+	p := NewPaginator(PAGING_BY_CURSOR, 30, 0, 0, func(q PageQuery) ([]interface{}, string, error) {
+		start := 0
+		if q.Cursor != "" {
+			fmt.Sscan(q.Cursor, &start)
+		}
+		var page []interface{}
+		for i := start; i < len(orders) && len(page) < q.Limit; i++ {
+			page = append(page, orders[i])
+		}
+		return page, fmt.Sprint(start + len(page)), nil
+	}, orderDecimalKey, nil)
+
+	var pages int
+	var total int
+	for !p.Done() {
+		records, err := p.Next()
+		assert.Nil(t, err)
+		total += len(records)
+		pages++
+	}
+	assert.Equal(t, 100, total)
+	assert.Equal(t, 4, pages)
+}
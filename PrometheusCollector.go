@@ -0,0 +1,228 @@
+package goex
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type histogram struct {
+	counts []uint64 // 每个桶的计数(非累计), 最后一个为+Inf
+	sum    float64
+	count  uint64
+}
+
+/**
+ * Prometheus指标收集器, 同时实现HttpHook、WsHook和http.Handler, 不依赖Prometheus客户端库.
+ * 用法:
+ *   collector := NewPrometheusCollector("goex")
+ *   RegisterHook(collector)
+ *   http.Handle("/metrics", collector)
+ */
+type PrometheusCollector struct {
+	Namespace string
+	Buckets   []float64 // 请求耗时的直方图分桶, 单位秒
+
+	lock           sync.Mutex
+	requests       map[string]uint64 // exchange, method, endpoint, status, category
+	latency        map[string]*histogram
+	wait           map[string]float64 // exchange, method, endpoint
+	requestBytes   map[string]uint64  // exchange
+	responseBytes  map[string]uint64  // exchange
+	wsConnects     map[string]uint64  // exchange, result
+	wsMessages     map[string]uint64  // exchange
+	wsMessageBytes map[string]uint64  // exchange
+	wsReconnects   map[string]uint64  // exchange, result
+}
+
+func NewPrometheusCollector(namespace string) *PrometheusCollector {
+	return &PrometheusCollector{
+		Namespace:      namespace,
+		Buckets:        DefaultLatencyBuckets,
+		requests:       map[string]uint64{},
+		latency:        map[string]*histogram{},
+		wait:           map[string]float64{},
+		requestBytes:   map[string]uint64{},
+		responseBytes:  map[string]uint64{},
+		wsConnects:     map[string]uint64{},
+		wsMessages:     map[string]uint64{},
+		wsMessageBytes: map[string]uint64{},
+		wsReconnects:   map[string]uint64{},
+	}
+}
+
+func escapeLabel(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	return strings.Replace(value, "\n", `\n`, -1)
+}
+
+// labels("exchange", "binance", "method", "GET") -> exchange="binance",method="GET"
+func labels(kv ...string) string {
+	parts := make([]string, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, kv[i], escapeLabel(kv[i+1])))
+	}
+	return strings.Join(parts, ",")
+}
+
+func resultLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+func (c *PrometheusCollector) BeforeRequest(ctx context.Context, req *HttpRequestInfo) context.Context {
+	return ctx
+}
+
+func (c *PrometheusCollector) AfterRequest(ctx context.Context, req *HttpRequestInfo, resp *HttpResponseInfo) {
+	category := resp.Category()
+	if category == "" {
+		category = "none"
+	}
+	requestKey := labels("exchange", req.Exchange, "method", req.Method, "endpoint", req.Endpoint,
+		"status", strconv.Itoa(resp.StatusCode), "category", category)
+	endpointKey := labels("exchange", req.Exchange, "method", req.Method, "endpoint", req.Endpoint)
+	exchangeKey := labels("exchange", req.Exchange)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.requests[requestKey]++
+	c.wait[endpointKey] += resp.Wait.Seconds()
+	c.requestBytes[exchangeKey] += uint64(req.RequestBytes)
+	c.responseBytes[exchangeKey] += uint64(resp.ResponseBytes)
+
+	h := c.latency[endpointKey]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(c.Buckets)+1)}
+		c.latency[endpointKey] = h
+	}
+	seconds := resp.Latency.Seconds()
+	i := sort.SearchFloat64s(c.Buckets, seconds)
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+}
+
+func (c *PrometheusCollector) OnWsConnect(exchange, wsUrl string, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.wsConnects[labels("exchange", exchange, "result", resultLabel(err))]++
+}
+
+func (c *PrometheusCollector) OnWsMessage(exchange, wsUrl string, size int) {
+	key := labels("exchange", exchange)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.wsMessages[key]++
+	c.wsMessageBytes[key] += uint64(size)
+}
+
+func (c *PrometheusCollector) OnWsReconnect(exchange, wsUrl string, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.wsReconnects[labels("exchange", exchange, "result", resultLabel(err))]++
+}
+
+func (c *PrometheusCollector) name(name string) string {
+	if c.Namespace == "" {
+		return name
+	}
+	return c.Namespace + "_" + name
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]uint64:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]float64:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*histogram:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *PrometheusCollector) writeCounter(w *bufio.Writer, name, help string, values map[string]uint64) {
+	name = c.name(name)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, k := range sortedKeys(values) {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, k, values[k])
+	}
+}
+
+/**
+ * 按Prometheus文本格式输出所有指标
+ */
+func (c *PrometheusCollector) WriteTo(writer io.Writer) (int64, error) {
+	cw := &countingWriter{w: writer}
+	w := bufio.NewWriter(cw)
+
+	c.lock.Lock()
+	c.writeCounter(w, "http_requests_total", "HTTP requests by exchange, endpoint, status and error category.", c.requests)
+
+	name := c.name("http_request_duration_seconds")
+	fmt.Fprintf(w, "# HELP %s HTTP request latency excluding rate limiter wait.\n# TYPE %s histogram\n", name, name)
+	for _, k := range sortedKeys(c.latency) {
+		h := c.latency[k]
+		var cumulative uint64
+		for i, bound := range c.Buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, k, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, k, h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, k, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, k, h.count)
+	}
+
+	name = c.name("http_rate_limit_wait_seconds_total")
+	fmt.Fprintf(w, "# HELP %s Time spent waiting for the client side rate limiter.\n# TYPE %s counter\n", name, name)
+	for _, k := range sortedKeys(c.wait) {
+		fmt.Fprintf(w, "%s{%s} %s\n", name, k, strconv.FormatFloat(c.wait[k], 'g', -1, 64))
+	}
+
+	c.writeCounter(w, "http_request_bytes_total", "HTTP request body bytes sent.", c.requestBytes)
+	c.writeCounter(w, "http_response_bytes_total", "HTTP response body bytes received.", c.responseBytes)
+	c.writeCounter(w, "ws_connects_total", "Websocket connection attempts.", c.wsConnects)
+	c.writeCounter(w, "ws_messages_total", "Websocket data messages received.", c.wsMessages)
+	c.writeCounter(w, "ws_message_bytes_total", "Websocket data message bytes received.", c.wsMessageBytes)
+	c.writeCounter(w, "ws_reconnects_total", "Websocket reconnection attempts.", c.wsReconnects)
+	c.lock.Unlock()
+
+	err := w.Flush()
+	return cw.n, err
+}
+
+func (c *PrometheusCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,442 @@
+package goex
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 接口限频类别
+type RateLimitClass int
+
+const (
+	RATE_PUBLIC  RateLimitClass = iota // 行情等公共接口
+	RATE_PRIVATE                       // 账户、订单查询等私有接口
+	RATE_ORDER                         // 下单、撤单
+)
+
+// 请求优先级, 接近限频时低优先级请求让出预留的额度, 保证撤单优先
+type RatePriority int
+
+const (
+	PRIORITY_LOW    RatePriority = iota // 查询
+	PRIORITY_NORMAL                     // 下单
+	PRIORITY_HIGH                       // 撤单
+)
+
+/**
+ * 令牌桶, 容量Capacity, 每Interval补满
+ */
+type TokenBucket struct {
+	Capacity float64
+	Interval time.Duration
+	Reserve  float64 // 为高优先级请求预留的令牌数
+
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(capacity int, interval time.Duration) *TokenBucket {
+	return &TokenBucket{
+		Capacity: float64(capacity),
+		Interval: interval,
+		Reserve:  float64(capacity) / 10,
+		tokens:   float64(capacity),
+	}
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens += b.Capacity * float64(now.Sub(b.last)) / float64(b.Interval)
+		if b.tokens > b.Capacity {
+			b.tokens = b.Capacity
+		}
+	}
+	b.last = now
+}
+
+func (b *TokenBucket) reserveFor(priority RatePriority) float64 {
+	switch priority {
+	case PRIORITY_HIGH:
+		return 0
+	case PRIORITY_NORMAL:
+		return b.Reserve / 2
+	}
+	return b.Reserve
+}
+
+// 获取weight个令牌还需等待的时间
+func (b *TokenBucket) wait(weight float64, priority RatePriority) time.Duration {
+	if weight > b.Capacity {
+		weight = b.Capacity
+	}
+	need := weight + b.reserveFor(priority)
+	if need > b.Capacity {
+		need = b.Capacity
+	}
+	if b.tokens >= need {
+		return 0
+	}
+	return time.Duration((need - b.tokens) / b.Capacity * float64(b.Interval))
+}
+
+func (b *TokenBucket) take(weight float64) {
+	b.tokens -= weight
+}
+
+// 根据交易所返回的剩余额度校正
+func (b *TokenBucket) setRemaining(remaining float64) {
+	if remaining < b.tokens {
+		b.tokens = remaining
+	}
+}
+
+/**
+ * 接口限频规则, Method为空匹配所有方法, Path为URL路径前缀
+ */
+type RateLimitRule struct {
+	Method   string
+	Path     string
+	Class    RateLimitClass
+	Weight   int
+	Priority RatePriority
+}
+
+/**
+ * 交易所的客户端限频器
+ * 每个请求按规则(或默认分类)归类并计算权重, 从对应类别的令牌桶和全局权重桶(如Binance的1200权重/分钟)中扣除.
+ * 根据x-ratelimit-remaining、Retry-After和Binance的X-MBX-USED-WEIGHT等响应头自适应调整.
+ */
+type RateLimiter struct {
+	Exchange string
+	Hosts    []string
+	Rules    []RateLimitRule
+	MaxWait  time.Duration // 最长等待时间, 超过返回EX_ERR_API_LIMIT, 0表示一直等待
+
+	UsedWeightHeader string // 已使用权重的响应头, 如Binance的X-MBX-USED-WEIGHT-1M
+	OrderCountHeader string // 已下单次数的响应头, 如Binance的X-MBX-ORDER-COUNT-10S
+
+	lock        sync.Mutex
+	buckets     map[RateLimitClass]*TokenBucket
+	weight      *TokenBucket
+	pausedUntil time.Time
+}
+
+func NewRateLimiter(exchange string, hosts ...string) *RateLimiter {
+	return &RateLimiter{
+		Exchange: exchange,
+		Hosts:    hosts,
+		buckets:  make(map[RateLimitClass]*TokenBucket),
+	}
+}
+
+/**
+ * 设置某类接口的限频, 如每2秒20次: SetLimit(RATE_PUBLIC, 20, 2*time.Second)
+ */
+func (limiter *RateLimiter) SetLimit(class RateLimitClass, count int, interval time.Duration) *RateLimiter {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	limiter.buckets[class] = NewTokenBucket(count, interval)
+	return limiter
+}
+
+/**
+ * 设置所有接口共享的权重限制
+ */
+func (limiter *RateLimiter) SetWeightLimit(weight int, interval time.Duration) *RateLimiter {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	limiter.weight = NewTokenBucket(weight, interval)
+	return limiter
+}
+
+func (limiter *RateLimiter) AddRule(rule RateLimitRule) *RateLimiter {
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	limiter.Rules = append(limiter.Rules, rule)
+	return limiter
+}
+
+// 私有接口常见的签名参数/请求头
+var signatureKeys = []string{"signature", "sign", "api-signature", "ok-access-sign", "x-mbx-apikey", "key", "apikey", "api_key", "accesskeyid"}
+
+func isSignedRequest(req *http.Request) bool {
+	for _, k := range signatureKeys {
+		if req.Header.Get(k) != "" {
+			return true
+		}
+	}
+	query := strings.ToLower(req.URL.RawQuery)
+	for _, k := range signatureKeys {
+		if strings.HasPrefix(query, k+"=") || strings.Contains(query, "&"+k+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * 请求分类: 优先匹配规则; 否则撤单(DELETE或路径包含cancel)为高优先级的RATE_ORDER, 其他非GET请求为RATE_ORDER,
+ * 带签名的GET为RATE_PRIVATE, 其余为RATE_PUBLIC
+ */
+func (limiter *RateLimiter) Classify(req *http.Request) (RateLimitClass, int, RatePriority) {
+	path := req.URL.Path
+	for _, rule := range limiter.Rules {
+		if rule.Method != "" && rule.Method != req.Method {
+			continue
+		}
+		if !strings.HasPrefix(path, rule.Path) {
+			continue
+		}
+		weight := rule.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		return rule.Class, weight, rule.Priority
+	}
+
+	switch {
+	case req.Method == "DELETE" || strings.Contains(strings.ToLower(path), "cancel"):
+		return RATE_ORDER, 1, PRIORITY_HIGH
+	case req.Method != "GET":
+		return RATE_ORDER, 1, PRIORITY_NORMAL
+	case isSignedRequest(req):
+		return RATE_PRIVATE, 1, PRIORITY_LOW
+	}
+	return RATE_PUBLIC, 1, PRIORITY_LOW
+}
+
+/**
+ * 请求前调用, 等待到有足够的额度
+ */
+func (limiter *RateLimiter) Wait(req *http.Request) error {
+	return limiter.WaitContext(context.Background(), req)
+}
+
+/**
+ * 同Wait, ctx结束时返回ctx.Err()
+ */
+func (limiter *RateLimiter) WaitContext(ctx context.Context, req *http.Request) error {
+	class, weight, priority := limiter.Classify(req)
+	return limiter.AcquireContext(ctx, class, weight, priority)
+}
+
+func (limiter *RateLimiter) Acquire(class RateLimitClass, weight int, priority RatePriority) error {
+	return limiter.AcquireContext(context.Background(), class, weight, priority)
+}
+
+func (limiter *RateLimiter) AcquireContext(ctx context.Context, class RateLimitClass, weight int, priority RatePriority) error {
+	start := time.Now()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		limiter.lock.Lock()
+		now := time.Now()
+		var wait time.Duration
+		if now.Before(limiter.pausedUntil) {
+			wait = limiter.pausedUntil.Sub(now)
+		}
+
+		bucket := limiter.buckets[class]
+		if bucket != nil {
+			bucket.refill(now)
+			if w := bucket.wait(1, priority); w > wait {
+				wait = w
+			}
+		}
+		if limiter.weight != nil {
+			limiter.weight.refill(now)
+			if w := limiter.weight.wait(float64(weight), priority); w > wait {
+				wait = w
+			}
+		}
+
+		if wait == 0 {
+			if bucket != nil {
+				bucket.take(1)
+			}
+			if limiter.weight != nil {
+				limiter.weight.take(float64(weight))
+			}
+			limiter.lock.Unlock()
+			return nil
+		}
+		limiter.lock.Unlock()
+
+		if limiter.MaxWait > 0 && now.Add(wait).Sub(start) > limiter.MaxWait {
+			return EX_ERR_API_LIMIT
+		}
+		if wait < time.Millisecond {
+			wait = time.Millisecond
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func parseHeaderFloat(header http.Header, key string) (float64, bool) {
+	s := header.Get(key)
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+/**
+ * 请求后调用, 根据响应头调整额度
+ */
+func (limiter *RateLimiter) Observe(req *http.Request, resp *http.Response) {
+	class, _, _ := limiter.Classify(req)
+
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	now := time.Now()
+	header := resp.Header
+
+	if used, ok := parseHeaderFloat(header, limiter.UsedWeightHeader); ok && limiter.weight != nil {
+		limiter.weight.refill(now)
+		limiter.weight.setRemaining(limiter.weight.Capacity - used)
+	}
+	if count, ok := parseHeaderFloat(header, limiter.OrderCountHeader); ok {
+		if bucket := limiter.buckets[RATE_ORDER]; bucket != nil {
+			bucket.refill(now)
+			bucket.setRemaining(bucket.Capacity - count)
+		}
+	}
+
+	if remaining, ok := parseHeaderFloat(header, "X-Ratelimit-Remaining"); ok {
+		bucket := limiter.buckets[class]
+		if bucket == nil {
+			bucket = limiter.weight
+		}
+		if bucket != nil {
+			bucket.refill(now)
+			bucket.setRemaining(remaining)
+		}
+		if remaining <= 0 {
+			if reset, ok := parseHeaderFloat(header, "X-Ratelimit-Reset"); ok {
+				limiter.pause(time.Unix(int64(reset), 0))
+			}
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == 418 {
+		if seconds, ok := parseHeaderFloat(header, "Retry-After"); ok {
+			limiter.pause(now.Add(time.Duration(seconds * float64(time.Second))))
+		} else {
+			limiter.pause(now.Add(time.Second))
+		}
+	}
+}
+
+func (limiter *RateLimiter) pause(until time.Time) {
+	if until.After(limiter.pausedUntil) {
+		limiter.pausedUntil = until
+	}
+}
+
+var (
+	rateLimitersLock sync.RWMutex
+	rateLimiters     = map[string]*RateLimiter{} // host -> limiter
+	exchangeLimiters = map[string]*RateLimiter{} // exchange -> limiter
+)
+
+/**
+ * 注册限频器, 之后发往limiter.Hosts的请求都经过限频
+ */
+func RegisterRateLimiter(limiter *RateLimiter) {
+	rateLimitersLock.Lock()
+	defer rateLimitersLock.Unlock()
+	if old, ok := exchangeLimiters[limiter.Exchange]; ok {
+		for _, host := range old.Hosts {
+			delete(rateLimiters, host)
+		}
+	}
+	exchangeLimiters[limiter.Exchange] = limiter
+	for _, host := range limiter.Hosts {
+		rateLimiters[host] = limiter
+	}
+}
+
+/**
+ * 取消交易所的限频
+ */
+func UnregisterRateLimiter(exchange string) {
+	rateLimitersLock.Lock()
+	defer rateLimitersLock.Unlock()
+	if old, ok := exchangeLimiters[exchange]; ok {
+		for _, host := range old.Hosts {
+			delete(rateLimiters, host)
+		}
+		delete(exchangeLimiters, exchange)
+	}
+}
+
+func GetRateLimiter(host string) *RateLimiter {
+	rateLimitersLock.RLock()
+	defer rateLimitersLock.RUnlock()
+	return rateLimiters[host]
+}
+
+func GetExchangeRateLimiter(exchange string) *RateLimiter {
+	rateLimitersLock.RLock()
+	defer rateLimitersLock.RUnlock()
+	return exchangeLimiters[exchange]
+}
+
+/**
+ * 按交易所文档的限频规则注册默认限频器
+ */
+func init() {
+	// https://binance-docs.github.io/apidocs/spot/cn/#limits
+	binance := NewRateLimiter(BINANCE, "api.binance.com").
+		SetWeightLimit(1200, time.Minute).
+		SetLimit(RATE_ORDER, 10, time.Second).
+		AddRule(RateLimitRule{Method: "DELETE", Path: "/api/v3/order", Class: RATE_PRIVATE, Weight: 1, Priority: PRIORITY_HIGH}).
+		AddRule(RateLimitRule{Method: "GET", Path: "/api/v1/depth", Class: RATE_PUBLIC, Weight: 5}).
+		AddRule(RateLimitRule{Method: "GET", Path: "/api/v3/depth", Class: RATE_PUBLIC, Weight: 5}).
+		AddRule(RateLimitRule{Method: "GET", Path: "/api/v1/ticker/24hr", Class: RATE_PUBLIC, Weight: 40}).
+		AddRule(RateLimitRule{Method: "GET", Path: "/api/v3/ticker/24hr", Class: RATE_PUBLIC, Weight: 40}).
+		AddRule(RateLimitRule{Method: "GET", Path: "/api/v3/openOrders", Class: RATE_PRIVATE, Weight: 40}).
+		AddRule(RateLimitRule{Method: "GET", Path: "/api/v3/allOrders", Class: RATE_PRIVATE, Weight: 5}).
+		AddRule(RateLimitRule{Method: "GET", Path: "/api/v3/account", Class: RATE_PRIVATE, Weight: 5}).
+		AddRule(RateLimitRule{Method: "GET", Path: "/api/v3/myTrades", Class: RATE_PRIVATE, Weight: 5}).
+		AddRule(RateLimitRule{Method: "GET", Path: "/api/v1/exchangeInfo", Class: RATE_PUBLIC, Weight: 10}).
+		AddRule(RateLimitRule{Method: "GET", Path: "/api/v3/exchangeInfo", Class: RATE_PUBLIC, Weight: 10})
+	binance.UsedWeightHeader = "X-Mbx-Used-Weight-1m"
+	binance.OrderCountHeader = "X-Mbx-Order-Count-10s"
+	RegisterRateLimiter(binance)
+
+	// https://www.bitmex.com/app/restAPI#Limits
+	RegisterRateLimiter(NewRateLimiter(BITMEX, "www.bitmex.com", "testnet.bitmex.com").
+		SetLimit(RATE_PUBLIC, 30, time.Minute).
+		SetLimit(RATE_PRIVATE, 60, time.Minute).
+		SetLimit(RATE_ORDER, 60, time.Minute))
+
+	// https://www.okex.com/docs/zh/#README, 多数接口为每2秒20次
+	RegisterRateLimiter(NewRateLimiter(OKEX, "www.okex.com").
+		SetLimit(RATE_PUBLIC, 20, 2*time.Second).
+		SetLimit(RATE_PRIVATE, 20, 2*time.Second).
+		SetLimit(RATE_ORDER, 40, 2*time.Second))
+
+	// https://huobiapi.github.io/docs/spot/v1/cn/#5ea2e0cde2, 私有接口每个API Key 10秒100次
+	RegisterRateLimiter(NewRateLimiter(HUOBI_PRO, "api.huobi.br.com", "api.huobi.pro", "api-aws.huobi.pro").
+		SetLimit(RATE_PUBLIC, 100, 10*time.Second).
+		SetLimit(RATE_PRIVATE, 100, 10*time.Second).
+		SetLimit(RATE_ORDER, 100, 10*time.Second))
+}
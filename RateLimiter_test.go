@@ -0,0 +1,95 @@
+package goex
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRequest(method, url string, headers map[string]string) *http.Request {
+	req, _ := http.NewRequest(method, url, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func TestRateLimiter_Classify(t *testing.T) {
+	limiter := GetExchangeRateLimiter(BINANCE)
+	assert.NotNil(t, limiter)
+
+	class, weight, priority := limiter.Classify(newRequest("GET", "https://api.binance.com/api/v1/depth?symbol=BTCUSDT", nil))
+	assert.Equal(t, RATE_PUBLIC, class)
+	assert.Equal(t, 5, weight)
+	assert.Equal(t, PRIORITY_LOW, priority)
+
+	class, _, priority = limiter.Classify(newRequest("DELETE", "https://api.binance.com/api/v3/order?signature=x", nil))
+	assert.Equal(t, RATE_PRIVATE, class)
+	assert.Equal(t, PRIORITY_HIGH, priority)
+
+	limiter = NewRateLimiter("test")
+	class, _, _ = limiter.Classify(newRequest("GET", "https://www.okex.com/api/futures/v3/accounts", map[string]string{"OK-ACCESS-SIGN": "x"}))
+	assert.Equal(t, RATE_PRIVATE, class)
+	class, _, priority = limiter.Classify(newRequest("POST", "https://www.okex.com/api/futures/v3/cancel_order/1/2", nil))
+	assert.Equal(t, RATE_ORDER, class)
+	assert.Equal(t, PRIORITY_HIGH, priority)
+	class, _, priority = limiter.Classify(newRequest("POST", "https://www.okex.com/api/futures/v3/order", nil))
+	assert.Equal(t, RATE_ORDER, class)
+	assert.Equal(t, PRIORITY_NORMAL, priority)
+}
+
+func TestRateLimiter_Priority(t *testing.T) {
+	limiter := NewRateLimiter("test").SetLimit(RATE_ORDER, 10, time.Hour)
+	limiter.MaxWait = 10 * time.Millisecond
+
+	for i := 0; i < 9; i++ {
+		assert.Nil(t, limiter.Acquire(RATE_ORDER, 1, PRIORITY_LOW))
+	}
+	// the last token is reserved for cancels
+	assert.Equal(t, EX_ERR_API_LIMIT, limiter.Acquire(RATE_ORDER, 1, PRIORITY_LOW))
+	assert.Nil(t, limiter.Acquire(RATE_ORDER, 1, PRIORITY_HIGH))
+	assert.Equal(t, EX_ERR_API_LIMIT, limiter.Acquire(RATE_ORDER, 1, PRIORITY_HIGH))
+
+	// classes without a bucket are not limited
+	assert.Nil(t, limiter.Acquire(RATE_PUBLIC, 1, PRIORITY_LOW))
+}
+
+func TestRateLimiter_Observe(t *testing.T) {
+	limiter := NewRateLimiter("test").SetWeightLimit(1200, time.Hour)
+	limiter.UsedWeightHeader = "X-Mbx-Used-Weight-1m"
+	limiter.MaxWait = 10 * time.Millisecond
+
+	req := newRequest("GET", "https://api.binance.com/api/v3/ticker/price", nil)
+	resp := &http.Response{StatusCode: 200, Header: http.Header{}}
+	resp.Header.Set("X-MBX-USED-WEIGHT-1M", "1195")
+	limiter.Observe(req, resp)
+
+	assert.Nil(t, limiter.Acquire(RATE_PUBLIC, 5, PRIORITY_HIGH))
+	assert.Equal(t, EX_ERR_API_LIMIT, limiter.Acquire(RATE_PUBLIC, 1, PRIORITY_HIGH))
+
+	limiter = NewRateLimiter("test")
+	limiter.MaxWait = 10 * time.Millisecond
+	resp = &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "60")
+	limiter.Observe(req, resp)
+	assert.Equal(t, EX_ERR_API_LIMIT, limiter.Acquire(RATE_ORDER, 1, PRIORITY_HIGH))
+
+	limiter = NewRateLimiter("test").SetLimit(RATE_PUBLIC, 30, 100*time.Millisecond)
+	resp = &http.Response{StatusCode: 200, Header: http.Header{}}
+	resp.Header.Set("x-ratelimit-remaining", "0")
+	limiter.Observe(req, resp)
+	start := time.Now()
+	assert.Nil(t, limiter.Acquire(RATE_PUBLIC, 1, PRIORITY_LOW))
+	assert.True(t, time.Since(start) >= 5*time.Millisecond)
+}
+
+func TestRateLimiter_HuobiHosts(t *testing.T) {
+	for _, host := range []string{"api.huobi.br.com", "api.huobi.pro", "api-aws.huobi.pro"} {
+		limiter := GetRateLimiter(host)
+		if assert.NotNil(t, limiter, host) {
+			assert.Equal(t, HUOBI_PRO, limiter.Exchange)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package goex
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+/**
+ * 重试策略, 指数退避加随机抖动, 只重试可恢复的错误(限频、网络、维护、时间戳), 拒单等错误不重试
+ */
+type RetryPolicy struct {
+	MaxAttempts    int           // 最多调用次数, 包括第一次
+	InitialBackoff time.Duration // 第一次重试前的等待时间
+	MaxBackoff     time.Duration
+	Multiplier     float64 // 每次重试等待时间的倍数
+	Jitter         float64 // 随机抖动比例, 0.2表示等待时间在±20%内浮动
+
+	Retryable func(err error) bool                             // 为nil时使用IsRetryable
+	OnRetry   func(attempt int, err error, wait time.Duration) // 每次重试前调用, 可用于记录日志
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+/**
+ * 默认的重试判断: 限频、网络错误、交易所维护和时间戳错误可以重试
+ */
+func IsRetryable(err error) bool {
+	return ErrorCategoryOf(err).Temporary()
+}
+
+/**
+ * 第attempt次重试前的等待时间, attempt从1开始
+ */
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	wait := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		wait = wait * (1 - p.Jitter + 2*p.Jitter*rand.Float64())
+	}
+	return time.Duration(wait)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+/**
+ * 调用fn直到成功、遇到不可重试的错误、达到最多调用次数或ctx结束, 返回最后一次调用的错误.
+ * ctx在等待重试时结束则返回ctx.Err()
+ */
+func (p RetryPolicy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := fn(ctx)
+		if err == nil || attempt >= maxAttempts || !p.retryable(err) {
+			return err
+		}
+
+		wait := p.Backoff(attempt)
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+/**
+ * 下单重试: 只有指定了clientOid, 重复提交会被交易所去重时才重试, 否则只调用一次, 避免重复下单
+ */
+func (p RetryPolicy) DoOrder(ctx context.Context, clientOid string, fn func(ctx context.Context) error) error {
+	if clientOid == "" {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(ctx)
+	}
+	return p.Do(ctx, fn)
+}
+
+/**
+ * 使用DefaultRetryPolicy重试
+ */
+func Retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	return DefaultRetryPolicy.Do(ctx, fn)
+}
+
+/**
+ * 使用DefaultRetryPolicy重试下单
+ */
+func RetryOrder(ctx context.Context, clientOid string, fn func(ctx context.Context) error) error {
+	return DefaultRetryPolicy.DoOrder(ctx, clientOid, fn)
+}
@@ -0,0 +1,85 @@
+package goex
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     4 * time.Millisecond,
+	Multiplier:     2,
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	calls := 0
+	err := testRetryPolicy.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return NewExchangeError("test", ERR_NETWORK, "", "timeout")
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+
+	// rejects are not retried
+	calls = 0
+	err = testRetryPolicy.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return NewExchangeError("test", ERR_INSUFFICIENT_BALANCE, "", "insufficient balance")
+	})
+	assert.True(t, errors.Is(err, ERR_INSUFFICIENT_BALANCE))
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	err = testRetryPolicy.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return EX_ERR_API_LIMIT
+	})
+	assert.Equal(t, EX_ERR_API_LIMIT, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestRetryPolicy_Cancel(t *testing.T) {
+	policy := testRetryPolicy
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := policy.Do(ctx, func(ctx context.Context) error {
+		return EX_ERR_API_LIMIT
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestRetryPolicy_DoOrder(t *testing.T) {
+	calls := 0
+	place := func(ctx context.Context) error {
+		calls++
+		return NewExchangeError("test", ERR_NETWORK, "", "timeout")
+	}
+
+	testRetryPolicy.DoOrder(context.Background(), "", place)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	testRetryPolicy.DoOrder(context.Background(), "client-1", place)
+	assert.Equal(t, 4, calls)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2, Jitter: 0.2}
+	for i := 0; i < 10; i++ {
+		wait := policy.Backoff(2)
+		assert.True(t, wait >= 160*time.Millisecond && wait <= 240*time.Millisecond)
+	}
+	policy.Jitter = 0
+	assert.Equal(t, time.Second, policy.Backoff(10))
+}
@@ -0,0 +1,277 @@
+package goex
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+ * 查询交易所服务器时间
+ */
+type ServerTimeFetcher func(ctx context.Context, client *http.Client) (time.Time, error)
+
+/**
+ * 交易所服务器时钟, 定期查询服务器时间并估计本地时钟偏差, 签名时用Now()代替time.Now(), 避免本地时钟漂移导致时间戳被拒.
+ * 每次同步请求Samples次, 取往返时间(RTT)最短的一次, 偏差 = 服务器时间 - (发送时间 + RTT/2)
+ */
+type ServerClock struct {
+	Exchange      string
+	Fetch         ServerTimeFetcher
+	Interval      time.Duration // 同步间隔
+	RetryInterval time.Duration // 同步失败后的重试间隔
+	Samples       int
+	Timeout       time.Duration // 每次查询的超时时间
+
+	lock     sync.RWMutex
+	client   *http.Client
+	offset   time.Duration
+	rtt      time.Duration
+	lastSync time.Time
+	running  int32 // 原子读写, Now()不加锁判断是否已启动
+	stopChan chan struct{}
+}
+
+func NewServerClock(exchange string, fetch ServerTimeFetcher) *ServerClock {
+	return &ServerClock{
+		Exchange:      exchange,
+		Fetch:         fetch,
+		Interval:      5 * time.Minute,
+		RetryInterval: 10 * time.Second,
+		Samples:       3,
+		Timeout:       5 * time.Second,
+	}
+}
+
+/**
+ * 设置查询服务器时间使用的http.Client, 默认为http.DefaultClient
+ */
+func (c *ServerClock) SetHttpClient(client *http.Client) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.client = client
+}
+
+func (c *ServerClock) httpClient() *http.Client {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if c.client == nil {
+		return http.DefaultClient
+	}
+	return c.client
+}
+
+/**
+ * 查询服务器时间并更新偏差, 所有查询都失败时返回最后一个错误, 偏差保持不变
+ */
+func (c *ServerClock) Sync(ctx context.Context) error {
+	samples := c.Samples
+	if samples <= 0 {
+		samples = 1
+	}
+	client := c.httpClient()
+
+	var (
+		bestOffset, bestRTT time.Duration
+		found               bool
+		lastErr             error
+	)
+	for i := 0; i < samples; i++ {
+		reqCtx := ctx
+		var cancel context.CancelFunc
+		if c.Timeout > 0 {
+			reqCtx, cancel = context.WithTimeout(ctx, c.Timeout)
+		}
+		sent := time.Now()
+		serverTime, err := c.Fetch(reqCtx, client)
+		received := time.Now()
+		if cancel != nil {
+			cancel()
+		}
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		rtt := received.Sub(sent)
+		if !found || rtt < bestRTT {
+			bestRTT = rtt
+			bestOffset = serverTime.Sub(sent.Add(rtt / 2))
+			found = true
+		}
+	}
+	if !found {
+		return lastErr
+	}
+
+	c.lock.Lock()
+	c.offset = bestOffset
+	c.rtt = bestRTT
+	c.lastSync = time.Now()
+	c.lock.Unlock()
+	return nil
+}
+
+/**
+ * 服务器时间减本地时间
+ */
+func (c *ServerClock) Offset() time.Duration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.offset
+}
+
+/**
+ * 最近一次同步的往返时间
+ */
+func (c *ServerClock) RTT() time.Duration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.rtt
+}
+
+/**
+ * 最近一次同步成功的本地时间, 未同步过为零值
+ */
+func (c *ServerClock) LastSync() time.Time {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lastSync
+}
+
+/**
+ * 估计的服务器当前时间, 第一次调用时启动后台同步, 同步完成前返回本地时间
+ */
+func (c *ServerClock) Now() time.Time {
+	if atomic.LoadInt32(&c.running) == 0 {
+		c.Start()
+	}
+	return time.Now().Add(c.Offset())
+}
+
+/**
+ * 启动后台同步, 已启动时不做任何事
+ */
+func (c *ServerClock) Start() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.running != 0 {
+		return
+	}
+	atomic.StoreInt32(&c.running, 1)
+	c.stopChan = make(chan struct{})
+	go c.loop(c.stopChan)
+}
+
+func (c *ServerClock) Stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.running == 0 {
+		return
+	}
+	atomic.StoreInt32(&c.running, 0)
+	close(c.stopChan)
+}
+
+func (c *ServerClock) loop(stopChan chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopChan
+		cancel()
+	}()
+
+	for {
+		wait := c.Interval
+		if err := c.Sync(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			DefaultLogger().Warn("sync server time failed", "exchange", c.Exchange, "err", err)
+			wait = c.RetryInterval
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-stopChan:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+/**
+ * 用响应的Date头估计服务器时间, 用于没有时间接口的交易所. Date头只精确到秒, 加半秒抵消截断的平均误差
+ */
+func DateHeaderTimeFetcher(reqUrl string) ServerTimeFetcher {
+	return func(ctx context.Context, client *http.Client) (time.Time, error) {
+		_, header, err := NewHttpRequestExWithContext(ctx, client, "GET", reqUrl, "", nil)
+		if err != nil {
+			return time.Time{}, err
+		}
+		serverTime, err := http.ParseTime(header.Get("Date"))
+		if err != nil {
+			return time.Time{}, err
+		}
+		return serverTime.Add(500 * time.Millisecond), nil
+	}
+}
+
+var (
+	serverClocks     = map[string]*ServerClock{}
+	serverClocksLock sync.RWMutex
+)
+
+/**
+ * 注册交易所的服务器时钟, 替换已注册的时钟
+ */
+func RegisterServerClock(clock *ServerClock) {
+	serverClocksLock.Lock()
+	old := serverClocks[clock.Exchange]
+	serverClocks[clock.Exchange] = clock
+	serverClocksLock.Unlock()
+	if old != nil && old != clock {
+		old.Stop()
+	}
+}
+
+func UnregisterServerClock(exchange string) {
+	serverClocksLock.Lock()
+	clock := serverClocks[exchange]
+	delete(serverClocks, exchange)
+	serverClocksLock.Unlock()
+	if clock != nil {
+		clock.Stop()
+	}
+}
+
+func GetServerClock(exchange string) *ServerClock {
+	serverClocksLock.RLock()
+	defer serverClocksLock.RUnlock()
+	return serverClocks[exchange]
+}
+
+/**
+ * 设置交易所服务器时钟使用的http.Client, 交易所没有注册时钟时不做任何事
+ */
+func SetServerClockHttpClient(exchange string, client *http.Client) {
+	if clock := GetServerClock(exchange); clock != nil && client != nil {
+		clock.SetHttpClient(client)
+	}
+}
+
+/**
+ * 交易所服务器的当前时间, 没有注册时钟的交易所返回本地时间
+ */
+func ServerTime(exchange string) time.Time {
+	if clock := GetServerClock(exchange); clock != nil {
+		return clock.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,86 @@
+package goex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerClock_Sync(t *testing.T) {
+	calls := 0
+	clock := NewServerClock("test", func(ctx context.Context, client *http.Client) (time.Time, error) {
+		calls++
+		if calls == 1 {
+			// 第一次往返时间较长, 应该被丢弃
+			time.Sleep(20 * time.Millisecond)
+			return time.Now().Add(time.Hour), nil
+		}
+		return time.Now().Add(3 * time.Second), nil
+	})
+
+	assert.Nil(t, clock.Sync(context.Background()))
+	assert.Equal(t, 3, calls)
+	assert.InDelta(t, float64(3*time.Second), float64(clock.Offset()), float64(5*time.Millisecond))
+	assert.True(t, clock.RTT() < 20*time.Millisecond)
+	assert.False(t, clock.LastSync().IsZero())
+}
+
+func TestServerClock_SyncError(t *testing.T) {
+	fetchErr := errors.New("timeout")
+	clock := NewServerClock("test", func(ctx context.Context, client *http.Client) (time.Time, error) {
+		return time.Time{}, fetchErr
+	})
+	clock.offset = time.Second
+
+	assert.Equal(t, fetchErr, clock.Sync(context.Background()))
+	assert.Equal(t, time.Second, clock.Offset())
+	assert.True(t, clock.LastSync().IsZero())
+}
+
+func TestServerTime(t *testing.T) {
+	before := time.Now()
+	assert.False(t, ServerTime("unknown").Before(before))
+
+	synced := make(chan struct{}, 1)
+	clock := NewServerClock("test", func(ctx context.Context, client *http.Client) (time.Time, error) {
+		defer func() {
+			select {
+			case synced <- struct{}{}:
+			default:
+			}
+		}()
+		return time.Now().Add(-time.Minute), nil
+	})
+	clock.Samples = 1
+	RegisterServerClock(clock)
+	defer UnregisterServerClock("test")
+
+	ServerTime("test")
+	select {
+	case <-synced:
+	case <-time.After(time.Second):
+		t.Fatal("clock not started")
+	}
+	for i := 0; i < 100 && clock.LastSync().IsZero(); i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, ServerTime("test").Before(time.Now().Add(-50*time.Second)))
+}
+
+func TestDateHeaderTimeFetcher(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", date.Format(http.TimeFormat))
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	serverTime, err := DateHeaderTimeFetcher(server.URL)(context.Background(), server.Client())
+	assert.Nil(t, err)
+	assert.True(t, date.Add(500*time.Millisecond).Equal(serverTime))
+}
@@ -0,0 +1,129 @@
+package goex
+
+import (
+	"fmt"
+	"sync"
+)
+
+type SignAlgorithm int
+
+const (
+	HMAC_SHA256_HEX SignAlgorithm = iota
+	HMAC_SHA256_BASE64
+	HMAC_SHA512_HEX
+	HMAC_SHA512_BASE64
+	HMAC_SHA384_HEX
+	HMAC_SHA1_HEX
+	HMAC_MD5_HEX
+)
+
+/**
+ * 签名结果, 带上签名所用凭证的API Key和passphrase, 保证请求头和签名来自同一份凭证
+ */
+type Signature struct {
+	ApiKey     string
+	Passphrase string
+	Message    string
+	Sign       string
+}
+
+/**
+ * 签名器, 密钥只在签名器内部使用, 不暴露给交易所实现.
+ * build根据API Key生成待签名的内容, 内容不包含API Key时可以用SignMessage
+ */
+type Signer interface {
+	Sign(alg SignAlgorithm, build func(apiKey string) string) (*Signature, error)
+}
+
+/**
+ * 待签名内容与API Key无关时使用
+ */
+func SignMessage(message string) func(apiKey string) string {
+	return func(string) string {
+		return message
+	}
+}
+
+type credentialSigner struct {
+	provider CredentialProvider
+}
+
+/**
+ * 每次签名时从provider获取凭证的签名器
+ */
+func NewSigner(provider CredentialProvider) Signer {
+	return credentialSigner{provider: provider}
+}
+
+/**
+ * 固定凭证的签名器, 用于兼容传入API Key和密钥的构造函数
+ */
+func NewStaticSigner(apiKey, secretKey, passphrase string) Signer {
+	return NewSigner(NewStaticCredentials(apiKey, secretKey, passphrase))
+}
+
+func (s credentialSigner) Sign(alg SignAlgorithm, build func(apiKey string) string) (*Signature, error) {
+	credentials, err := s.provider.Credentials()
+	if err != nil {
+		return nil, err
+	}
+	message := build(credentials.ApiKey)
+	sign, err := signWith(alg, credentials.SecretKey, message)
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{ApiKey: credentials.ApiKey, Passphrase: credentials.Passphrase, Message: message, Sign: sign}, nil
+}
+
+/**
+ * 可在运行时替换的签名器, 交易所实现持有它而不是直接持有Signer,
+ * SetSigner与正在进行的请求签名并发执行时没有数据竞争
+ */
+type SignerRef struct {
+	lock   sync.RWMutex
+	signer Signer
+}
+
+func NewSignerRef(signer Signer) *SignerRef {
+	return &SignerRef{signer: signer}
+}
+
+func (r *SignerRef) Set(signer Signer) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.signer = signer
+}
+
+func (r *SignerRef) Get() Signer {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.signer
+}
+
+func (r *SignerRef) Sign(alg SignAlgorithm, build func(apiKey string) string) (*Signature, error) {
+	signer := r.Get()
+	if signer == nil {
+		return nil, fmt.Errorf("no signer configured")
+	}
+	return signer.Sign(alg, build)
+}
+
+func signWith(alg SignAlgorithm, secret, message string) (string, error) {
+	switch alg {
+	case HMAC_SHA256_HEX:
+		return GetParamHmacSHA256Sign(secret, message)
+	case HMAC_SHA256_BASE64:
+		return GetParamHmacSHA256Base64Sign(secret, message)
+	case HMAC_SHA512_HEX:
+		return GetParamHmacSHA512Sign(secret, message)
+	case HMAC_SHA512_BASE64:
+		return GetParamHmacSHA512Base64SignEx(secret, message)
+	case HMAC_SHA384_HEX:
+		return GetParamHmacSha384Sign(secret, message)
+	case HMAC_SHA1_HEX:
+		return GetParamHmacSHA1Sign(secret, message)
+	case HMAC_MD5_HEX:
+		return GetParamHmacMD5Sign(secret, message)
+	}
+	return "", fmt.Errorf("unsupported sign algorithm %d", alg)
+}
@@ -0,0 +1,80 @@
+package goex
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner_Sign(t *testing.T) {
+	signer := NewStaticSigner("key", "secret", "pass")
+
+	signature, err := signer.Sign(HMAC_SHA256_HEX, SignMessage("a=1&b=2"))
+	assert.Nil(t, err)
+	expected, _ := GetParamHmacSHA256Sign("secret", "a=1&b=2")
+	assert.Equal(t, expected, signature.Sign)
+	assert.Equal(t, "key", signature.ApiKey)
+	assert.Equal(t, "pass", signature.Passphrase)
+	assert.Equal(t, "a=1&b=2", signature.Message)
+
+	signature, err = signer.Sign(HMAC_SHA256_BASE64, func(apiKey string) string {
+		return "accessKey=" + apiKey
+	})
+	assert.Nil(t, err)
+	expected, _ = GetParamHmacSHA256Base64Sign("secret", "accessKey=key")
+	assert.Equal(t, expected, signature.Sign)
+	assert.Equal(t, "accessKey=key", signature.Message)
+
+	_, err = signer.Sign(SignAlgorithm(100), SignMessage(""))
+	assert.NotNil(t, err)
+}
+
+func TestSigner_Rotate(t *testing.T) {
+	credentials := NewStaticCredentials("key1", "secret1", "")
+	signer := NewSigner(credentials)
+
+	s1, _ := signer.Sign(HMAC_SHA256_HEX, SignMessage("message"))
+	credentials.Rotate(Credentials{ApiKey: "key2", SecretKey: "secret2"})
+	s2, _ := signer.Sign(HMAC_SHA256_HEX, SignMessage("message"))
+
+	assert.Equal(t, "key1", s1.ApiKey)
+	assert.Equal(t, "key2", s2.ApiKey)
+	assert.NotEqual(t, s1.Sign, s2.Sign)
+}
+
+type errorCredentials struct{}
+
+func (errorCredentials) Credentials() (Credentials, error) {
+	return Credentials{}, errors.New("agent unavailable")
+}
+
+func TestSigner_ProviderError(t *testing.T) {
+	signature, err := NewSigner(errorCredentials{}).Sign(HMAC_SHA256_HEX, SignMessage("message"))
+	assert.Nil(t, signature)
+	assert.EqualError(t, err, "agent unavailable")
+}
+
+func TestSignerRef_Set(t *testing.T) {
+	ref := NewSignerRef(NewStaticSigner("key1", "secret1", ""))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ref.Sign(HMAC_SHA256_HEX, SignMessage("message"))
+			assert.Nil(t, err)
+		}()
+	}
+	ref.Set(NewStaticSigner("key2", "secret2", ""))
+	wg.Wait()
+
+	signature, err := ref.Sign(HMAC_SHA256_HEX, SignMessage("message"))
+	assert.Nil(t, err)
+	assert.Equal(t, "key2", signature.ApiKey)
+
+	_, err = NewSignerRef(nil).Sign(HMAC_SHA256_HEX, SignMessage("message"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,359 @@
+package goex
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 所有交易所共用的币种别名, 交易所使用的名称 -> 统一名称
+var defaultAssetAliases = map[string]string{
+	"XBT": "BTC",
+	"XDG": "DOGE",
+	"BCC": "BCH",
+}
+
+/**
+ * 按所有交易所共用的别名转换币种名称, 如XBT -> BTC
+ */
+func CanonicalCurrency(c Currency) Currency {
+	symbol := strings.ToUpper(c.Symbol)
+	if canonical, ok := defaultAssetAliases[symbol]; ok {
+		return NewCurrency(canonical, c.Desc)
+	}
+	return NewCurrency(symbol, c.Desc)
+}
+
+/**
+ * 按所有交易所共用的别名转换交易对, 用作跨交易所的map键
+ */
+func CanonicalPair(pair CurrencyPair) CurrencyPair {
+	return CurrencyPair{CanonicalCurrency(pair.CurrencyA), CanonicalCurrency(pair.CurrencyB)}
+}
+
+var ErrAmbiguousSymbol = errors.New("more than one instrument matches")
+
+type symbolMapKey struct {
+	typ   InstrumentType
+	base  string
+	quote string
+}
+
+/**
+ * 交易所的交易对或合约代码与统一交易对之间的双向转换.
+ * 统一交易对使用统一的币种名称(如BTC而不是XBT), 交易品种从InstrumentCatalog加载, 目录刷新后自动重建;
+ * 没有交易品种接口的交易所可以用Add逐个添加
+ */
+type SymbolMapper struct {
+	Exchange            string
+	Catalog             *InstrumentCatalog
+	MissRefreshInterval time.Duration // FromNative找不到时刷新目录的最小间隔, 用于发现新上线的交易品种, 0表示不刷新
+
+	lock        sync.RWMutex
+	aliases     map[string]string
+	natives     map[string]string     // 统一名称 -> 交易所名称, 只包含AddAssetAlias添加的别名
+	byNative    map[string]Instrument // 大写的交易所代码
+	byPlain     map[string][]string   // 去掉分隔符的大写代码 -> 交易所代码
+	byPair      map[symbolMapKey][]string
+	manual      []Instrument
+	loaded      time.Time
+	missRefresh time.Time
+}
+
+func NewSymbolMapper(exchange string, catalog *InstrumentCatalog) *SymbolMapper {
+	m := &SymbolMapper{
+		Exchange:            exchange,
+		Catalog:             catalog,
+		MissRefreshInterval: time.Minute,
+		aliases:             make(map[string]string, len(defaultAssetAliases)),
+		natives:             make(map[string]string),
+	}
+	for native, canonical := range defaultAssetAliases {
+		m.aliases[native] = canonical
+	}
+	m.rebuild(nil)
+	return m
+}
+
+/**
+ * 添加交易所特有的币种别名, 如Kraken的XXBT -> BTC
+ */
+func (m *SymbolMapper) AddAssetAlias(native, canonical string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.aliases[strings.ToUpper(native)] = strings.ToUpper(canonical)
+	m.natives[strings.ToUpper(canonical)] = strings.ToUpper(native)
+}
+
+/**
+ * 统一的币种名称转换为交易所的名称, 如ZB的BCH -> BCC. 没有用AddAssetAlias添加别名时返回大写的原名称
+ */
+func (m *SymbolMapper) NativeCurrency(c Currency) Currency {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	symbol := strings.ToUpper(c.Symbol)
+	if native, ok := m.natives[symbol]; ok {
+		return NewCurrency(native, c.Desc)
+	}
+	return NewCurrency(symbol, c.Desc)
+}
+
+func (m *SymbolMapper) canonicalCurrency(c Currency) Currency {
+	symbol := strings.ToUpper(c.Symbol)
+	if canonical, ok := m.aliases[symbol]; ok {
+		return NewCurrency(canonical, c.Desc)
+	}
+	return NewCurrency(symbol, c.Desc)
+}
+
+/**
+ * 交易所的币种名称转换为统一名称
+ */
+func (m *SymbolMapper) CanonicalCurrency(c Currency) Currency {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.canonicalCurrency(c)
+}
+
+func (m *SymbolMapper) CanonicalPair(pair CurrencyPair) CurrencyPair {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return CurrencyPair{m.canonicalCurrency(pair.CurrencyA), m.canonicalCurrency(pair.CurrencyB)}
+}
+
+/**
+ * 手工添加交易品种, 目录重建后仍然保留. 同一代码再次添加时替换
+ */
+func (m *SymbolMapper) Add(native string, pair CurrencyPair, typ InstrumentType) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for i := range m.manual {
+		if strings.EqualFold(m.manual[i].Symbol, native) {
+			m.manual = append(m.manual[:i], m.manual[i+1:]...)
+			break
+		}
+	}
+	m.manual = append(m.manual, Instrument{Exchange: m.Exchange, Symbol: native, Pair: pair, Type: typ})
+	m.index(m.manual[len(m.manual)-1])
+}
+
+/**
+ * 用交易品种列表重建映射, 替换之前加载的交易品种
+ */
+func (m *SymbolMapper) Load(instruments []Instrument) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.rebuild(instruments)
+}
+
+func (m *SymbolMapper) rebuild(instruments []Instrument) {
+	m.byNative = make(map[string]Instrument, len(instruments)+len(m.manual))
+	m.byPlain = make(map[string][]string, len(instruments)+len(m.manual))
+	m.byPair = make(map[symbolMapKey][]string, len(instruments)+len(m.manual))
+	for _, inst := range instruments {
+		m.index(inst)
+	}
+	for _, inst := range m.manual {
+		m.index(inst)
+	}
+}
+
+func plainSymbol(symbol string) string {
+	return strings.NewReplacer("-", "", "_", "", "/", "").Replace(strings.ToUpper(symbol))
+}
+
+func (m *SymbolMapper) index(inst Instrument) {
+	key := strings.ToUpper(inst.Symbol)
+	if old, ok := m.byNative[key]; ok {
+		m.unindex(old)
+	}
+	inst.Pair = CurrencyPair{m.canonicalCurrency(inst.Pair.CurrencyA), m.canonicalCurrency(inst.Pair.CurrencyB)}
+	m.byNative[key] = inst
+	plain := plainSymbol(inst.Symbol)
+	m.byPlain[plain] = append(m.byPlain[plain], inst.Symbol)
+	pk := symbolMapKey{inst.Type, inst.Pair.CurrencyA.Symbol, inst.Pair.CurrencyB.Symbol}
+	m.byPair[pk] = append(m.byPair[pk], inst.Symbol)
+}
+
+func (m *SymbolMapper) unindex(inst Instrument) {
+	remove := func(symbols []string) []string {
+		ret := symbols[:0:0]
+		for _, s := range symbols {
+			if !strings.EqualFold(s, inst.Symbol) {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+	plain := plainSymbol(inst.Symbol)
+	m.byPlain[plain] = remove(m.byPlain[plain])
+	pk := symbolMapKey{inst.Type, inst.Pair.CurrencyA.Symbol, inst.Pair.CurrencyB.Symbol}
+	m.byPair[pk] = remove(m.byPair[pk])
+}
+
+// 目录刷新过则重建映射
+func (m *SymbolMapper) sync() error {
+	if m.Catalog == nil {
+		return nil
+	}
+	m.lock.RLock()
+	loaded := m.loaded
+	m.lock.RUnlock()
+	if updated := m.Catalog.Updated(); !updated.IsZero() && !updated.After(loaded) {
+		return nil
+	}
+
+	// 第一次调用时All会加载目录
+	instruments, err := m.Catalog.All()
+	if err != nil {
+		return err
+	}
+	updated := m.Catalog.Updated()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if updated.After(m.loaded) {
+		m.rebuild(instruments)
+		m.loaded = updated
+	}
+	return nil
+}
+
+/**
+ * 统一交易对对应的所有交易所代码, 按代码排序. 没有完全匹配时, USD和USDT计价互相替代
+ */
+func (m *SymbolMapper) Natives(pair CurrencyPair, typ InstrumentType) ([]string, error) {
+	if err := m.sync(); err != nil {
+		return nil, err
+	}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	base := m.canonicalCurrency(pair.CurrencyA).Symbol
+	quote := m.canonicalCurrency(pair.CurrencyB).Symbol
+	symbols := m.byPair[symbolMapKey{typ, base, quote}]
+	if len(symbols) == 0 {
+		switch quote {
+		case USD.Symbol:
+			symbols = m.byPair[symbolMapKey{typ, base, USDT.Symbol}]
+		case USDT.Symbol:
+			symbols = m.byPair[symbolMapKey{typ, base, USD.Symbol}]
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("%s %s %s: %w", m.Exchange, pair, typ, ErrInstrumentNotFound)
+	}
+	ret := make([]string, len(symbols))
+	copy(ret, symbols)
+	sort.Strings(ret)
+	return ret, nil
+}
+
+/**
+ * 统一交易对转换为交易所代码, 匹配多个交易品种(如多个交割合约)时返回ErrAmbiguousSymbol
+ */
+func (m *SymbolMapper) ToNative(pair CurrencyPair, typ InstrumentType) (string, error) {
+	symbols, err := m.Natives(pair, typ)
+	if err != nil {
+		return "", err
+	}
+	if len(symbols) > 1 {
+		return "", fmt.Errorf("%s %s %s: %w: %s", m.Exchange, pair, typ, ErrAmbiguousSymbol, strings.Join(symbols, ","))
+	}
+	return symbols[0], nil
+}
+
+/**
+ * 交易所代码转换为交易品种, Pair为统一交易对. 不区分大小写, 找不到时忽略-_/分隔符再查找一次.
+ * 仍然找不到且目录超过MissRefreshInterval没有刷新时, 刷新目录后再查找
+ */
+func (m *SymbolMapper) FromNative(symbol string) (Instrument, error) {
+	if err := m.sync(); err != nil {
+		return Instrument{}, err
+	}
+	if inst, ok := m.fromNative(symbol); ok {
+		return inst, nil
+	}
+	if m.refreshOnMiss() {
+		if err := m.sync(); err != nil {
+			return Instrument{}, err
+		}
+		if inst, ok := m.fromNative(symbol); ok {
+			return inst, nil
+		}
+	}
+	return Instrument{}, fmt.Errorf("%s %s: %w", m.Exchange, symbol, ErrInstrumentNotFound)
+}
+
+func (m *SymbolMapper) fromNative(symbol string) (Instrument, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if inst, ok := m.byNative[strings.ToUpper(symbol)]; ok {
+		return inst, true
+	}
+	if symbols := m.byPlain[plainSymbol(symbol)]; len(symbols) == 1 {
+		return m.byNative[strings.ToUpper(symbols[0])], true
+	}
+	return Instrument{}, false
+}
+
+// 刷新失败也记录时间, 避免每次查找都请求交易所
+func (m *SymbolMapper) refreshOnMiss() bool {
+	if m.Catalog == nil || m.MissRefreshInterval <= 0 {
+		return false
+	}
+	now := time.Now()
+	m.lock.Lock()
+	if now.Sub(m.Catalog.Updated()) < m.MissRefreshInterval || now.Sub(m.missRefresh) < m.MissRefreshInterval {
+		m.lock.Unlock()
+		return false
+	}
+	m.missRefresh = now
+	m.lock.Unlock()
+	return m.Catalog.Refresh() == nil
+}
+
+var (
+	symbolMappers     = map[string]*SymbolMapper{}
+	symbolMappersLock sync.RWMutex
+)
+
+func RegisterSymbolMapper(mapper *SymbolMapper) {
+	symbolMappersLock.Lock()
+	defer symbolMappersLock.Unlock()
+	symbolMappers[mapper.Exchange] = mapper
+}
+
+func UnregisterSymbolMapper(exchange string) {
+	symbolMappersLock.Lock()
+	defer symbolMappersLock.Unlock()
+	delete(symbolMappers, exchange)
+}
+
+/**
+ * 获取交易所的映射, 没有注册时用已注册的交易品种目录创建并注册, 都没有时返回nil
+ */
+func GetSymbolMapper(exchange string) *SymbolMapper {
+	symbolMappersLock.RLock()
+	mapper := symbolMappers[exchange]
+	symbolMappersLock.RUnlock()
+	if mapper != nil {
+		return mapper
+	}
+
+	catalog := GetInstrumentCatalog(exchange)
+	if catalog == nil {
+		return nil
+	}
+	symbolMappersLock.Lock()
+	defer symbolMappersLock.Unlock()
+	if mapper = symbolMappers[exchange]; mapper == nil {
+		mapper = NewSymbolMapper(exchange, catalog)
+		symbolMappers[exchange] = mapper
+	}
+	return mapper
+}
@@ -0,0 +1,111 @@
+package goex
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymbolMapper_Convert(t *testing.T) {
+	m := NewSymbolMapper("test", nil)
+	m.AddAssetAlias("XXBT", "BTC")
+	m.Load([]Instrument{
+		{Symbol: "XBTUSD", Pair: CurrencyPair{XBT, USD}, Type: INSTRUMENT_SWAP},
+		{Symbol: "XBTM19", Pair: CurrencyPair{XBT, USD}, Type: INSTRUMENT_FUTURE},
+		{Symbol: "XBTU19", Pair: CurrencyPair{XBT, USD}, Type: INSTRUMENT_FUTURE},
+		{Symbol: "bcc_usdt", Pair: BCC_USDT, Type: INSTRUMENT_SPOT},
+		{Symbol: "XXBTZUSD", Pair: CurrencyPair{NewCurrency("XXBT", ""), USD}, Type: INSTRUMENT_SPOT},
+	})
+
+	symbol, err := m.ToNative(BTC_USD, INSTRUMENT_SWAP)
+	assert.Nil(t, err)
+	assert.Equal(t, "XBTUSD", symbol)
+	symbol, err = m.ToNative(CurrencyPair{XBT, USD}, INSTRUMENT_SWAP)
+	assert.Nil(t, err)
+	assert.Equal(t, "XBTUSD", symbol)
+
+	// USDT计价找不到时使用USD计价的合约
+	symbol, err = m.ToNative(BTC_USDT, INSTRUMENT_SWAP)
+	assert.Nil(t, err)
+	assert.Equal(t, "XBTUSD", symbol)
+
+	symbol, err = m.ToNative(BCH_USDT, INSTRUMENT_SPOT)
+	assert.Nil(t, err)
+	assert.Equal(t, "bcc_usdt", symbol)
+	symbol, err = m.ToNative(BTC_USD, INSTRUMENT_SPOT)
+	assert.Nil(t, err)
+	assert.Equal(t, "XXBTZUSD", symbol)
+
+	_, err = m.ToNative(BTC_USD, INSTRUMENT_FUTURE)
+	assert.True(t, errors.Is(err, ErrAmbiguousSymbol))
+	symbols, err := m.Natives(BTC_USD, INSTRUMENT_FUTURE)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"XBTM19", "XBTU19"}, symbols)
+
+	_, err = m.ToNative(ETH_USDT, INSTRUMENT_SPOT)
+	assert.True(t, errors.Is(err, ErrInstrumentNotFound))
+
+	inst, err := m.FromNative("xbtusd")
+	assert.Nil(t, err)
+	assert.Equal(t, BTC_USD, inst.Pair)
+	assert.Equal(t, INSTRUMENT_SWAP, inst.Type)
+	inst, err = m.FromNative("BCCUSDT")
+	assert.Nil(t, err)
+	assert.Equal(t, BCH_USDT, inst.Pair)
+	assert.Equal(t, "bcc_usdt", inst.Symbol)
+
+	assert.Equal(t, BTC, m.CanonicalCurrency(NewCurrency("xxbt", "")))
+	assert.Equal(t, "XXBT", m.NativeCurrency(BTC).Symbol)
+	// 所有交易所共用的别名不反向转换
+	assert.Equal(t, BCH, m.NativeCurrency(BCH))
+	assert.Equal(t, BTC_USDT, CanonicalPair(CurrencyPair{XBT, USDT}))
+	assert.Equal(t, NewCurrencyPair(NewCurrency("EOS", ""), BTC), CanonicalPair(NewCurrencyPair2("eos_btc")))
+}
+
+func TestSymbolMapper_Catalog(t *testing.T) {
+	api := &fakeInstrumentAPI{instruments: testInstruments()}
+	catalog := NewInstrumentCatalog("test", api)
+	m := NewSymbolMapper("test", catalog)
+	m.Add("ltc_usdt", LTC_USDT, INSTRUMENT_SPOT)
+
+	symbol, err := m.ToNative(BTC_USDT, INSTRUMENT_SPOT)
+	assert.Nil(t, err)
+	assert.Equal(t, "BTCUSDT", symbol)
+	_, err = m.FromNative("ETHUSDT")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, api.calls)
+
+	api.set([]Instrument{{Symbol: "ETH_USDT", Pair: ETH_USDT, Type: INSTRUMENT_SPOT}}, nil)
+	assert.Nil(t, catalog.Refresh())
+	_, err = m.FromNative("BTCUSDT")
+	assert.True(t, errors.Is(err, ErrInstrumentNotFound))
+	symbol, err = m.ToNative(ETH_USDT, INSTRUMENT_SPOT)
+	assert.Nil(t, err)
+	assert.Equal(t, "ETH_USDT", symbol)
+	symbol, err = m.ToNative(LTC_USDT, INSTRUMENT_SPOT)
+	assert.Nil(t, err)
+	assert.Equal(t, "ltc_usdt", symbol)
+
+	// 新上线的交易品种在找不到时刷新目录
+	m.MissRefreshInterval = time.Millisecond
+	time.Sleep(2 * time.Millisecond)
+	api.set([]Instrument{{Symbol: "ETH_USDT", Pair: ETH_USDT, Type: INSTRUMENT_SPOT}, {Symbol: "EOS_USDT", Pair: EOS_USDT, Type: INSTRUMENT_SPOT}}, nil)
+	calls := api.calls
+	inst, err := m.FromNative("EOS_USDT")
+	assert.Nil(t, err)
+	assert.Equal(t, EOS_USDT, inst.Pair)
+	assert.Equal(t, calls+1, api.calls)
+	_, err = m.FromNative("XRP_USDT")
+	assert.True(t, errors.Is(err, ErrInstrumentNotFound))
+	assert.Equal(t, calls+1, api.calls)
+
+	RegisterInstrumentCatalog(catalog)
+	defer UnregisterInstrumentCatalog("test")
+	registered := GetSymbolMapper("test")
+	assert.NotNil(t, registered)
+	assert.Equal(t, registered, GetSymbolMapper("test"))
+	UnregisterSymbolMapper("test")
+	assert.Nil(t, GetSymbolMapper("other"))
+}
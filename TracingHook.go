@@ -0,0 +1,59 @@
+package goex
+
+import (
+	"context"
+)
+
+/**
+ * 追踪的span, 接口与OpenTelemetry的trace.Span相近, 可以用很少的代码适配
+ */
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+/**
+ * 为每个HTTP请求创建span的钩子, span名称为"HTTP 方法 Endpoint", 属性沿用OpenTelemetry的HTTP语义约定
+ */
+type TracingHook struct {
+	Tracer Tracer
+}
+
+func NewTracingHook(tracer Tracer) *TracingHook {
+	return &TracingHook{Tracer: tracer}
+}
+
+type spanKey struct {
+	hook *TracingHook
+}
+
+func (h *TracingHook) BeforeRequest(ctx context.Context, req *HttpRequestInfo) context.Context {
+	ctx, span := h.Tracer.StartSpan(ctx, "HTTP "+req.Method+" "+req.Endpoint)
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.route", req.Endpoint)
+	span.SetAttribute("net.peer.name", req.Host)
+	span.SetAttribute("goex.exchange", req.Exchange)
+	return context.WithValue(ctx, spanKey{h}, span)
+}
+
+func (h *TracingHook) AfterRequest(ctx context.Context, req *HttpRequestInfo, resp *HttpResponseInfo) {
+	span, ok := ctx.Value(spanKey{h}).(Span)
+	if !ok {
+		return
+	}
+	if resp.StatusCode != 0 {
+		span.SetAttribute("http.status_code", resp.StatusCode)
+	}
+	span.SetAttribute("http.response_content_length", resp.ResponseBytes)
+	span.SetAttribute("goex.rate_limit_wait_ms", resp.Wait.Milliseconds())
+	if resp.Err != nil {
+		span.SetAttribute("goex.error_category", resp.Category())
+		span.RecordError(resp.Err)
+	}
+	span.End()
+}
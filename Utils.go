@@ -3,6 +3,8 @@ package goex
 import (
 	"strconv"
 	"reflect"
+
+	"github.com/shopspring/decimal"
 )
 
 
@@ -55,3 +57,21 @@ func ToInt(i interface{}) int {
 	}
 	return 0
 }
+
+/**
+ * 用于解析数组中混合了字符串和数字的行情数据, 无法解析时返回0
+ */
+func ToDecimal(i interface{}) decimal.Decimal {
+	switch v := i.(type) {
+	case string:
+		d, _ := decimal.NewFromString(v)
+		return d
+	case float64:
+		return decimal.NewFromFloat(v)
+	case int64:
+		return decimal.New(v, 0)
+	case int:
+		return decimal.New(int64(v), 0)
+	}
+	return decimal.Zero
+}
@@ -13,7 +13,8 @@ const (
 	API_BASE_URL = "https://acx.io/"
 	API_V1       = API_BASE_URL + "/api/v2/"
 
-	TICKER_URI = "/tickers/%s.json"
+	TICKER_URI  = "/tickers/%s.json"
+	MARKETS_URI = "markets.json"
 	//DEPTH_URI              = "depth.php?c=%s&mk_type=%s"
 	//ACCOUNT_URI            = "getMyBalance.php"
 	//TRADE_URI              = "trades.php?c=%s&mk_type=%s"
@@ -37,6 +38,35 @@ func (acx *Acx) GetExchangeName() string {
 	return EXCHANGE_NAME
 }
 
+/**
+ * 交易品种来自markets, name为"BTC/AUD"的格式, 接口不提供精度和最小下单量
+ */
+func (acx *Acx) GetInstruments() ([]Instrument, error) {
+	var resp []struct {
+		Id   string
+		Name string
+	}
+	err := HttpGet4(acx.httpClient, API_V1+MARKETS_URI, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Instrument, 0, len(resp))
+	for _, r := range resp {
+		parts := strings.Split(r.Name, "/")
+		if len(parts) != 2 {
+			continue
+		}
+		ret = append(ret, Instrument{
+			Exchange: EXCHANGE_NAME,
+			Symbol:   r.Id,
+			Pair:     NewCurrencyPair(NewCurrency(parts[0], ""), NewCurrency(parts[1], "")),
+			Type:     INSTRUMENT_SPOT,
+		})
+	}
+	return ret, nil
+}
+
 func (acx *Acx) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	tickerUri := API_V1 + fmt.Sprintf(TICKER_URI, strings.ToLower(currency.ToSymbol("")))
 	bodyDataMap, err := HttpGet(acx.httpClient, tickerUri)
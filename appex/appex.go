@@ -145,6 +145,18 @@ func (this *Appex) GetSymbols() ([]Symbol, error) {
 	return data.Data, nil
 }
 
+func (this *Appex) GetInstruments() ([]Instrument, error) {
+	symbols, err := this.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (this *Appex) transSymbol(symbol string) string {
 	return strings.ToLower(strings.Replace(symbol, "_", "", -1))
 }
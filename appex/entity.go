@@ -15,6 +15,19 @@ type Symbol struct {
 	Symbol string 			`json:"symbol"`
 }
 
+func (this *Symbol) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Symbol: this.Symbol,
+		Pair: goex.NewCurrencyPair2(this.BaseCurrency + "_" + this.QuoteCurrency),
+		Type: goex.INSTRUMENT_SPOT,
+		PricePrecision: int32(this.PricePrecision),
+		AmountPrecision: int32(this.AmountPrecision),
+		MinQty: this.MinAmount,
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 
 type OrderInfo struct {
 	Id decimal.Decimal
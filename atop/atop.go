@@ -107,6 +107,18 @@ func (ok *Atop) GetSymbols() ([]Symbol, error) {
 	return ret, nil
 }
 
+func (ok *Atop) GetInstruments() ([]Instrument, error) {
+	symbols, err := ok.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (this *Atop) transSymbol(symbol string) string {
 	return strings.ToLower(symbol)
 }
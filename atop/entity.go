@@ -12,6 +12,19 @@ type Symbol struct {
 	PricePoint int `json:"pricePoint"`
 }
 
+func (this *Symbol) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Symbol: this.Symbol,
+		Pair: goex.NewCurrencyPair2(this.Symbol),
+		Type: goex.INSTRUMENT_SPOT,
+		PricePrecision: int32(this.PricePoint),
+		AmountPrecision: int32(this.CoinPoint),
+		MinQty: this.MinAmount,
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 type OrderInfo struct {
 	Number         decimal.Decimal
 	Price          decimal.Decimal
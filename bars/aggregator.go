@@ -0,0 +1,418 @@
+package bars
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// DEFAULT_MAX_HISTORY Closed bars kept per symbol by default
+const DEFAULT_MAX_HISTORY = 1000
+
+// KlineAPI REST klines used to seed history, implemented by connectors with GetKlineRecordsDecimal
+type KlineAPI interface {
+	GetKlineRecordsDecimal(currency goex.CurrencyPair, period, size, since int) ([]goex.KlineDecimal, error)
+}
+
+var ErrSeedNotSupported = errors.New("only time bars can be seeded from klines")
+
+type series struct {
+	open        map[int64]*Bar // Time bars: open bars by start
+	current     *Bar           // Volume and tick bars: the bar being built
+	history     []Bar
+	watermark   int64 // Latest trade time or Advance time seen, ms
+	closedUntil int64 // Time bars: windows starting before this are closed, later trades for them are dropped
+	lateCount   int64
+}
+
+// Aggregator Builds bars of one spec from trade streams of any number of symbols.
+// Time bars stay open for GraceWindow after their end so that late trades still land in them;
+// a time bar closes once a trade or Advance call at or after End + GraceWindow is seen.
+// Periods without trades produce no bar.
+type Aggregator struct {
+	Spec Spec
+	// GraceWindow How long a time bar accepts late trades after its end, set before feeding trades
+	GraceWindow time.Duration
+	// MaxHistory Closed bars kept per symbol, set before feeding trades
+	MaxHistory int
+
+	lock   sync.Mutex
+	series map[string]*series
+
+	updateHandles []func(Bar)
+	closeHandles  []func(Bar)
+}
+
+// NewAggregator Aggregator constructor
+func NewAggregator(spec Spec) (*Aggregator, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &Aggregator{
+		Spec:       spec,
+		MaxHistory: DEFAULT_MAX_HISTORY,
+		series:     make(map[string]*series),
+	}, nil
+}
+
+// SubscribeUpdate Register a handler for in-progress bar updates, called once per bar touched by a batch of trades
+func (a *Aggregator) SubscribeUpdate(handle func(Bar)) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.updateHandles = append(a.updateHandles, handle)
+}
+
+// SubscribeClose Register a handler for closed bars, called in bar order per symbol
+func (a *Aggregator) SubscribeClose(handle func(Bar)) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.closeHandles = append(a.closeHandles, handle)
+}
+
+func (a *Aggregator) getSeries(symbol string) *series {
+	s, ok := a.series[symbol]
+	if !ok {
+		s = &series{open: make(map[int64]*Bar)}
+		a.series[symbol] = s
+	}
+	return s
+}
+
+// OnTrades Feed stream trades, the signature matches GetTradeWithWs handlers.
+// Trades without a time are stamped with the local time
+func (a *Aggregator) OnTrades(symbol string, trades []goex.TradeDecimal) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	a.lock.Lock()
+	s := a.getSeries(symbol)
+	var updated []*Bar
+	var closed []Bar
+	for i := range trades {
+		trade := &trades[i]
+		ts := trade.Date
+		if ts <= 0 {
+			ts = now
+		}
+		var b *Bar
+		switch a.Spec.Type {
+		case TIME_BAR:
+			b = a.addTimeTrade(s, symbol, trade, ts)
+		default:
+			b, closed = a.addCountTrade(s, symbol, trade, ts, closed)
+		}
+		if b != nil && !containsBar(updated, b) {
+			updated = append(updated, b)
+		}
+	}
+	if a.Spec.Type == TIME_BAR {
+		closed = a.closeTimeBars(s, closed)
+	}
+	updates := make([]Bar, 0, len(updated))
+	for _, b := range updated {
+		if !b.Closed {
+			updates = append(updates, *b)
+		}
+	}
+	updateHandles, closeHandles := a.updateHandles, a.closeHandles
+	a.lock.Unlock()
+
+	// Updates first, so a bar's close always follows its last update
+	for _, bar := range updates {
+		for _, handle := range updateHandles {
+			handle(bar)
+		}
+	}
+	for _, bar := range closed {
+		for _, handle := range closeHandles {
+			handle(bar)
+		}
+	}
+}
+
+func containsBar(bars []*Bar, b *Bar) bool {
+	for _, x := range bars {
+		if x == b {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Aggregator) addTimeTrade(s *series, symbol string, trade *goex.TradeDecimal, ts int64) *Bar {
+	if ts < s.closedUntil {
+		s.lateCount++
+		return nil
+	}
+	period := a.Spec.periodMs()
+	start := ts - ts%period
+	b, ok := s.open[start]
+	if ok {
+		b.add(trade, ts)
+	} else {
+		b = newBar(symbol, trade, ts)
+		b.add(trade, ts)
+		b.Start, b.End = start, start+period
+		s.open[start] = b
+	}
+	if ts > s.watermark {
+		s.watermark = ts
+	}
+	return b
+}
+
+func (a *Aggregator) addCountTrade(s *series, symbol string, trade *goex.TradeDecimal, ts int64, closed []Bar) (*Bar, []Bar) {
+	b := s.current
+	if b == nil {
+		b = newBar(symbol, trade, ts)
+		s.current = b
+	}
+	b.add(trade, ts)
+	b.Start, b.End = b.openTime, b.closeTime
+	if ts > s.watermark {
+		s.watermark = ts
+	}
+
+	full := false
+	if a.Spec.Type == VOLUME_BAR {
+		full = b.Volume.GreaterThanOrEqual(a.Spec.Volume)
+	} else {
+		full = b.Trades >= a.Spec.Ticks
+	}
+	if full {
+		b.Closed = true
+		s.current = nil
+		a.appendHistory(s, *b)
+		closed = append(closed, *b)
+	}
+	return b, closed
+}
+
+// closeTimeBars Close the time bars whose grace window has passed
+func (a *Aggregator) closeTimeBars(s *series, closed []Bar) []Bar {
+	period := a.Spec.periodMs()
+	limit := s.watermark - int64(a.GraceWindow/time.Millisecond)
+	if limit <= 0 {
+		return closed
+	}
+	if until := limit - limit%period; until > s.closedUntil {
+		s.closedUntil = until
+	}
+
+	var starts []int64
+	for start := range s.open {
+		if start < s.closedUntil {
+			starts = append(starts, start)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	for _, start := range starts {
+		b := s.open[start]
+		delete(s.open, start)
+		b.Closed = true
+		a.appendHistory(s, *b)
+		closed = append(closed, *b)
+	}
+	return closed
+}
+
+func (a *Aggregator) appendHistory(s *series, b Bar) {
+	s.history = append(s.history, b)
+	if a.MaxHistory > 0 && len(s.history) > a.MaxHistory {
+		s.history = append(s.history[:0:0], s.history[len(s.history)-a.MaxHistory:]...)
+	}
+}
+
+// Advance Close time bars of all symbols as if a trade at now had been received,
+// so that bars close on quiet markets. now should follow the exchange clock
+func (a *Aggregator) Advance(now time.Time) {
+	if a.Spec.Type != TIME_BAR {
+		return
+	}
+	ts := now.UnixNano() / int64(time.Millisecond)
+
+	a.lock.Lock()
+	symbols := make([]string, 0, len(a.series))
+	for symbol := range a.series {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	var closed []Bar
+	for _, symbol := range symbols {
+		s := a.series[symbol]
+		if ts > s.watermark {
+			s.watermark = ts
+		}
+		closed = a.closeTimeBars(s, closed)
+	}
+	closeHandles := a.closeHandles
+	a.lock.Unlock()
+
+	for _, bar := range closed {
+		for _, handle := range closeHandles {
+			handle(bar)
+		}
+	}
+}
+
+// Seed Fill the history of a symbol from REST klines whose period divides the spec period.
+// Klines are bucketed into bars; all buckets but the latest become closed history without firing handlers,
+// the latest stays open and keeps collecting trades, so trades it already includes may be counted twice.
+// Buckets at or after the first bar built from trades are ignored
+func (a *Aggregator) Seed(symbol string, klines []goex.KlineDecimal) error {
+	if a.Spec.Type != TIME_BAR {
+		return ErrSeedNotSupported
+	}
+	if len(klines) == 0 {
+		return nil
+	}
+	sorted := make([]goex.KlineDecimal, len(klines))
+	copy(sorted, klines)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp < sorted[j].Timestamp
+	})
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	s := a.getSeries(symbol)
+	firstLive := int64(-1)
+	if len(s.history) > 0 {
+		firstLive = s.history[0].Start
+	}
+	for start := range s.open {
+		if firstLive < 0 || start < firstLive {
+			firstLive = start
+		}
+	}
+
+	period := a.Spec.periodMs()
+	var buckets []*Bar
+	for i := range sorted {
+		k := &sorted[i]
+		ts := k.Timestamp * 1000
+		start := ts - ts%period
+		if firstLive >= 0 && start >= firstLive {
+			break
+		}
+		var b *Bar
+		if n := len(buckets); n > 0 && buckets[n-1].Start == start {
+			b = buckets[n-1]
+			if k.High.GreaterThan(b.High) {
+				b.High = k.High
+			}
+			if k.Low.LessThan(b.Low) {
+				b.Low = k.Low
+			}
+			b.Close = k.Close
+			b.Volume = b.Volume.Add(k.Vol)
+			b.closeTime = ts
+		} else {
+			b = &Bar{
+				Symbol:    symbol,
+				Start:     start,
+				End:       start + period,
+				Open:      k.Open,
+				High:      k.High,
+				Low:       k.Low,
+				Close:     k.Close,
+				Volume:    k.Vol,
+				Amount:    decimal.Zero,
+				Seeded:    true,
+				openTime:  ts,
+				closeTime: ts,
+			}
+			buckets = append(buckets, b)
+		}
+	}
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	if firstLive < 0 {
+		// No live bars yet, the latest bucket stays open
+		last := buckets[len(buckets)-1]
+		buckets = buckets[:len(buckets)-1]
+		s.open[last.Start] = last
+		if last.Start > s.closedUntil {
+			s.closedUntil = last.Start
+		}
+	}
+	seeded := make([]Bar, 0, len(buckets)+len(s.history))
+	for _, b := range buckets {
+		b.Closed = true
+		seeded = append(seeded, *b)
+	}
+	if n := len(buckets); n > 0 && buckets[n-1].End > s.closedUntil {
+		s.closedUntil = buckets[n-1].End
+	}
+	live := s.history
+	s.history = nil
+	for _, b := range append(seeded, live...) {
+		a.appendHistory(s, b)
+	}
+	return nil
+}
+
+func (a *Aggregator) historyCopy(symbol string) []Bar {
+	s, ok := a.series[symbol]
+	if !ok {
+		return nil
+	}
+	ret := make([]Bar, len(s.history))
+	copy(ret, s.history)
+	return ret
+}
+
+// SeedFromAPI Fetch the latest size klines of the given period and seed the history of symbol
+func (a *Aggregator) SeedFromAPI(api KlineAPI, pair goex.CurrencyPair, symbol string, period, size int) error {
+	d := goex.KlinePeriodDuration(period)
+	if d <= 0 || a.Spec.Type != TIME_BAR || a.Spec.Period%d != 0 {
+		return fmt.Errorf("kline period %d does not divide bar period %s: %w", period, a.Spec.Period, ErrSeedNotSupported)
+	}
+	klines, err := api.GetKlineRecordsDecimal(pair, period, size, 0)
+	if err != nil {
+		return err
+	}
+	return a.Seed(symbol, klines)
+}
+
+// History Closed bars of a symbol in time order
+func (a *Aggregator) History(symbol string) []Bar {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.historyCopy(symbol)
+}
+
+// Current Open bars of a symbol in time order
+func (a *Aggregator) Current(symbol string) []Bar {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	s, ok := a.series[symbol]
+	if !ok {
+		return nil
+	}
+	var ret []Bar
+	if s.current != nil {
+		ret = append(ret, *s.current)
+	}
+	for _, b := range s.open {
+		ret = append(ret, *b)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Start < ret[j].Start })
+	return ret
+}
+
+// LateCount Number of trades dropped because their time bar had already closed
+func (a *Aggregator) LateCount(symbol string) int64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if s, ok := a.series[symbol]; ok {
+		return s.lateCount
+	}
+	return 0
+}
@@ -0,0 +1,149 @@
+package bars
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+	"github.com/stretchr/testify/assert"
+)
+
+func trade(ts int64, price, amount string) goex.TradeDecimal {
+	return goex.TradeDecimal{Date: ts, Price: decimal.RequireFromString(price), Amount: decimal.RequireFromString(amount)}
+}
+
+type barRecorder struct {
+	updates []Bar
+	closes  []Bar
+}
+
+func newRecorder(a *Aggregator) *barRecorder {
+	r := &barRecorder{}
+	a.SubscribeUpdate(func(b Bar) { r.updates = append(r.updates, b) })
+	a.SubscribeClose(func(b Bar) { r.closes = append(r.closes, b) })
+	return r
+}
+
+func TestAggregator_TimeBars(t *testing.T) {
+	a, err := NewAggregator(TimeSpec(10 * time.Second))
+	assert.Nil(t, err)
+	a.GraceWindow = 2 * time.Second
+	r := newRecorder(a)
+
+	a.OnTrades("BTC_USDT", []goex.TradeDecimal{
+		trade(1000, "100", "1"),
+		trade(5000, "105", "2"),
+		trade(3000, "98", "1"),
+	})
+	assert.Equal(t, 1, len(r.updates))
+	assert.Equal(t, 0, len(r.closes))
+	bar := r.updates[0]
+	assert.Equal(t, int64(0), bar.Start)
+	assert.Equal(t, int64(10000), bar.End)
+	assert.Equal(t, "100", bar.Open.String())
+	assert.Equal(t, "105", bar.Close.String())
+	assert.Equal(t, "105", bar.High.String())
+	assert.Equal(t, "98", bar.Low.String())
+	assert.Equal(t, "4", bar.Volume.String())
+	assert.Equal(t, "408", bar.Amount.String())
+	assert.Equal(t, 3, bar.Trades)
+
+	// Within the grace window: a new bar opens and a late trade still lands in the first one
+	a.OnTrades("BTC_USDT", []goex.TradeDecimal{trade(11000, "110", "1"), trade(9500, "101", "1")})
+	assert.Equal(t, 0, len(r.closes))
+	assert.Equal(t, "101", a.Current("BTC_USDT")[0].Close.String())
+
+	// The grace window has passed
+	a.OnTrades("BTC_USDT", []goex.TradeDecimal{trade(12000, "111", "1")})
+	assert.Equal(t, 1, len(r.closes))
+	assert.True(t, r.closes[0].Closed)
+	assert.Equal(t, 4, r.closes[0].Trades)
+	assert.Equal(t, "101", r.closes[0].Close.String())
+
+	a.OnTrades("BTC_USDT", []goex.TradeDecimal{trade(9900, "90", "1")})
+	assert.Equal(t, int64(1), a.LateCount("BTC_USDT"))
+	assert.Equal(t, 1, len(a.History("BTC_USDT")))
+
+	a.Advance(time.Unix(22, 0))
+	assert.Equal(t, 2, len(r.closes))
+	assert.Equal(t, int64(10000), r.closes[1].Start)
+	assert.Equal(t, "111", r.closes[1].Close.String())
+	assert.Equal(t, 0, len(a.Current("BTC_USDT")))
+}
+
+func TestAggregator_VolumeAndTickBars(t *testing.T) {
+	a, err := NewAggregator(VolumeSpec(decimal.New(3, 0)))
+	assert.Nil(t, err)
+	r := newRecorder(a)
+	a.OnTrades("ETH_USDT", []goex.TradeDecimal{
+		trade(1000, "10", "1"),
+		trade(2000, "11", "2.5"),
+		trade(3000, "12", "1"),
+	})
+	assert.Equal(t, 1, len(r.closes))
+	assert.Equal(t, "3.5", r.closes[0].Volume.String())
+	assert.Equal(t, int64(1000), r.closes[0].Start)
+	assert.Equal(t, int64(2000), r.closes[0].End)
+	assert.Equal(t, 1, len(r.updates))
+	assert.Equal(t, "12", r.updates[0].Open.String())
+
+	a, _ = NewAggregator(TickSpec(2))
+	r = newRecorder(a)
+	a.OnTrades("ETH_USDT", []goex.TradeDecimal{trade(1000, "10", "1"), trade(2000, "11", "1"), trade(3000, "12", "1")})
+	a.OnTrades("ETH_USDT", []goex.TradeDecimal{trade(4000, "13", "1")})
+	assert.Equal(t, 2, len(r.closes))
+	assert.Equal(t, "12", r.closes[1].Open.String())
+	assert.Equal(t, "13", r.closes[1].Close.String())
+
+	_, err = NewAggregator(TickSpec(0))
+	assert.Equal(t, ErrInvalidSpec, err)
+}
+
+type fakeKlineAPI struct {
+	klines []goex.KlineDecimal
+}
+
+func (api *fakeKlineAPI) GetKlineRecordsDecimal(currency goex.CurrencyPair, period, size, since int) ([]goex.KlineDecimal, error) {
+	return api.klines, nil
+}
+
+func TestAggregator_Seed(t *testing.T) {
+	spec, err := KlineSpec(goex.KLINE_PERIOD_5MIN)
+	assert.Nil(t, err)
+	a, _ := NewAggregator(spec)
+	r := newRecorder(a)
+
+	var klines []goex.KlineDecimal
+	for i := int64(0); i < 12; i++ {
+		p := decimal.New(100+i, 0)
+		klines = append(klines, goex.KlineDecimal{Timestamp: i * 60, Open: p, High: p, Low: p, Close: p, Vol: decimal.New(1, 0)})
+	}
+	api := &fakeKlineAPI{klines: klines}
+	assert.NotNil(t, a.SeedFromAPI(api, goex.BTC_USDT, "BTC_USDT", goex.KLINE_PERIOD_4H, 12))
+	assert.Nil(t, a.SeedFromAPI(api, goex.BTC_USDT, "BTC_USDT", goex.KLINE_PERIOD_1MIN, 12))
+
+	history := a.History("BTC_USDT")
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, 0, len(r.closes))
+	assert.True(t, history[0].Seeded)
+	assert.Equal(t, "100", history[0].Open.String())
+	assert.Equal(t, "104", history[0].Close.String())
+	assert.Equal(t, "5", history[0].Volume.String())
+	assert.Equal(t, int64(300), history[1].ToKlineDecimal(goex.BTC_USDT).Timestamp)
+
+	// The latest bucket keeps collecting trades, earlier ones drop them
+	a.OnTrades("BTC_USDT", []goex.TradeDecimal{trade(11*60*1000+500, "120", "1"), trade(5*60*1000, "1", "1")})
+	current := a.Current("BTC_USDT")
+	assert.Equal(t, 1, len(current))
+	assert.Equal(t, "120", current[0].High.String())
+	assert.Equal(t, "3", current[0].Volume.String())
+	assert.Equal(t, int64(1), a.LateCount("BTC_USDT"))
+
+	a.Advance(time.Unix(15*60, 0))
+	assert.Equal(t, 1, len(r.closes))
+	assert.Equal(t, 3, len(a.History("BTC_USDT")))
+
+	_, err = KlineSpec(goex.KLINE_PERIOD_1MONTH)
+	assert.NotNil(t, err)
+}
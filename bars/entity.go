@@ -0,0 +1,157 @@
+package bars
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// BarType How trades are grouped into bars
+type BarType int
+
+const (
+	TIME_BAR   BarType = iota // Fixed time period, aligned to the epoch
+	VOLUME_BAR                // Closes once the traded base volume reaches a threshold
+	TICK_BAR                  // Closes after a fixed number of trades
+)
+
+var barTypeSymbol = [...]string{"TIME", "VOLUME", "TICK"}
+
+func (t BarType) String() string {
+	if t < 0 || int(t) >= len(barTypeSymbol) {
+		return "UNKNOWN"
+	}
+	return barTypeSymbol[t]
+}
+
+// Spec Bar specification, use TimeSpec, VolumeSpec or TickSpec to build one
+type Spec struct {
+	Type   BarType
+	Period time.Duration   // TIME_BAR, at least one millisecond
+	Volume decimal.Decimal // VOLUME_BAR
+	Ticks  int             // TICK_BAR
+}
+
+// TimeSpec Time bars of any period, e.g. 10 * time.Second
+func TimeSpec(period time.Duration) Spec {
+	return Spec{Type: TIME_BAR, Period: period}
+}
+
+// VolumeSpec Volume bars, the trade crossing the threshold is included entirely in the closing bar
+func VolumeSpec(volume decimal.Decimal) Spec {
+	return Spec{Type: VOLUME_BAR, Volume: volume}
+}
+
+// TickSpec Tick bars of n trades
+func TickSpec(n int) Spec {
+	return Spec{Type: TICK_BAR, Ticks: n}
+}
+
+// KlineSpec Time bars of a goex.KLINE_PERIOD_*, monthly and yearly periods are not supported
+func KlineSpec(period int) (Spec, error) {
+	if period == goex.KLINE_PERIOD_1MONTH || period == goex.KLINE_PERIOD_1YEAR {
+		return Spec{}, fmt.Errorf("kline period %d is not a fixed duration", period)
+	}
+	d := goex.KlinePeriodDuration(period)
+	if d <= 0 {
+		return Spec{}, fmt.Errorf("unknown kline period %d", period)
+	}
+	return TimeSpec(d), nil
+}
+
+var ErrInvalidSpec = errors.New("invalid bar spec")
+
+// Validate Check the parameters of the spec
+func (s Spec) Validate() error {
+	switch s.Type {
+	case TIME_BAR:
+		if s.Period < time.Millisecond {
+			return ErrInvalidSpec
+		}
+	case VOLUME_BAR:
+		if s.Volume.Sign() <= 0 {
+			return ErrInvalidSpec
+		}
+	case TICK_BAR:
+		if s.Ticks <= 0 {
+			return ErrInvalidSpec
+		}
+	default:
+		return ErrInvalidSpec
+	}
+	return nil
+}
+
+func (s Spec) periodMs() int64 {
+	return int64(s.Period / time.Millisecond)
+}
+
+// Bar OHLCV bar, times are in milliseconds
+type Bar struct {
+	Symbol string
+	Start  int64 // Time bars: period start; other bars: time of the first trade
+	End    int64 // Time bars: period end, exclusive; other bars: time of the last trade
+	Open   decimal.Decimal
+	High   decimal.Decimal
+	Low    decimal.Decimal
+	Close  decimal.Decimal
+	Volume decimal.Decimal // Base currency volume
+	Amount decimal.Decimal // Quote currency turnover, sum of price * amount
+	Trades int
+	Closed bool
+	Seeded bool // Built from REST klines rather than trades
+
+	openTime  int64
+	closeTime int64
+}
+
+func newBar(symbol string, trade *goex.TradeDecimal, ts int64) *Bar {
+	return &Bar{
+		Symbol:    symbol,
+		Start:     ts,
+		End:       ts,
+		Open:      trade.Price,
+		High:      trade.Price,
+		Low:       trade.Price,
+		Close:     trade.Price,
+		openTime:  ts,
+		closeTime: ts,
+	}
+}
+
+// add Add a trade, open and close follow trade time so that out-of-order trades land correctly
+func (b *Bar) add(trade *goex.TradeDecimal, ts int64) {
+	if ts < b.openTime {
+		b.Open = trade.Price
+		b.openTime = ts
+	}
+	if ts >= b.closeTime {
+		b.Close = trade.Price
+		b.closeTime = ts
+	}
+	if trade.Price.GreaterThan(b.High) {
+		b.High = trade.Price
+	}
+	if trade.Price.LessThan(b.Low) {
+		b.Low = trade.Price
+	}
+	b.Volume = b.Volume.Add(trade.Amount)
+	b.Amount = b.Amount.Add(trade.Amount.Mul(trade.Price))
+	b.Trades++
+}
+
+// ToKlineDecimal Convert to a kline, the timestamp is the bar start in seconds
+func (b *Bar) ToKlineDecimal(pair goex.CurrencyPair) goex.KlineDecimal {
+	return goex.KlineDecimal{
+		Pair:      pair,
+		Timestamp: b.Start / 1000,
+		Open:      b.Open,
+		Close:     b.Close,
+		High:      b.High,
+		Low:       b.Low,
+		Vol:       b.Volume,
+	}
+}
@@ -154,6 +154,19 @@ func (api *BiBull) GetSymbols() ([]Symbol, error) {
 	return data.Data, nil
 }
 
+// GetInstruments Get symbols as instruments
+func (api *BiBull) GetInstruments() ([]goex.Instrument, error) {
+	symbols, err := api.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]goex.Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (api *BiBull) transSymbol(symbol string) string {
 	return strings.ToLower(strings.Replace(symbol, "_", "", -1))
 }
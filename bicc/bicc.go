@@ -146,6 +146,18 @@ func (ok *Bicc) GetSymbols() ([]Symbol, error) {
 	return data.Data, nil
 }
 
+func (ok *Bicc) GetInstruments() ([]Instrument, error) {
+	symbols, err := ok.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (this *Bicc) transSymbol(symbol string) string {
 	return strings.ToLower(strings.Replace(symbol, "_", "", -1))
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"log"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/nubo/jwt"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 const (
@@ -20,6 +22,8 @@ const (
 	DEPTH_URI    = API_BASE_URL + "/markets/%s/depth"
 	ACCOUNT_URI  = API_BASE_URL + "/viewer/accounts"
 	ORDERS_URI   = API_BASE_URL + "/viewer/orders"
+	KLINE_URI    = "https://big.one/api/v3/asset_pairs/%s/candles"
+	PAIRS_URI    = "https://big.one/api/v3/asset_pairs"
 	//TRADE_URI    = "orders"
 )
 
@@ -38,6 +42,49 @@ func (bo *Bigone) GetExchangeName() string {
 	return goex.BIGONE
 }
 
+// 交易品种来自v3的asset_pairs, min_quote_value为计价币的最小下单金额
+func (bo *Bigone) GetInstruments() ([]goex.Instrument, error) {
+	var resp struct {
+		Code    int
+		Message string
+		Data    []struct {
+			Name          string
+			QuoteScale    int32           `json:"quote_scale"`
+			BaseScale     int32           `json:"base_scale"`
+			MinQuoteValue decimal.Decimal `json:"min_quote_value"`
+			BaseAsset     struct {
+				Symbol string
+			} `json:"base_asset"`
+			QuoteAsset struct {
+				Symbol string
+			} `json:"quote_asset"`
+		}
+	}
+	err := goex.HttpGet4(bo.httpClient, PAIRS_URI, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code != 0 {
+		return nil, fmt.Errorf("error code: %d, %s", resp.Code, resp.Message)
+	}
+
+	ret := make([]goex.Instrument, len(resp.Data))
+	for i, r := range resp.Data {
+		inst := goex.Instrument{
+			Exchange:        goex.BIGONE,
+			Symbol:          r.Name,
+			Pair:            goex.NewCurrencyPair(goex.NewCurrency(r.BaseAsset.Symbol, ""), goex.NewCurrency(r.QuoteAsset.Symbol, "")),
+			Type:            goex.INSTRUMENT_SPOT,
+			PricePrecision:  r.QuoteScale,
+			AmountPrecision: r.BaseScale,
+			MinNotional:     r.MinQuoteValue,
+		}
+		inst.FillPrecision()
+		ret[i] = inst
+	}
+	return ret, nil
+}
+
 type TickerResp struct {
 	Errors []struct {
 		Code      int `json:"code"`
@@ -444,8 +491,80 @@ func (bo *Bigone) GetDepth(size int, currencyPair goex.CurrencyPair) (*goex.Dept
 	return depth, nil
 }
 
+var klinePeriods = map[int]string{
+	goex.KLINE_PERIOD_1MIN:   "min1",
+	goex.KLINE_PERIOD_5MIN:   "min5",
+	goex.KLINE_PERIOD_15MIN:  "min15",
+	goex.KLINE_PERIOD_30MIN:  "min30",
+	goex.KLINE_PERIOD_60MIN:  "hour1",
+	goex.KLINE_PERIOD_4H:     "hour4",
+	goex.KLINE_PERIOD_1DAY:   "day1",
+	goex.KLINE_PERIOD_1WEEK:  "week1",
+	goex.KLINE_PERIOD_1MONTH: "month1",
+}
+
 func (bo *Bigone) GetKlineRecords(currency goex.CurrencyPair, period, size, since int) ([]goex.Kline, error) {
-	panic("not implements")
+	klines, err := bo.GetKlineRecordsDecimal(currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return goex.KlineDecimalsToKlines(klines), nil
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线. v2没有K线接口, 使用v3接口
+ */
+func (bo *Bigone) GetKlineRecordsDecimal(currency goex.CurrencyPair, period, size, since int) ([]goex.KlineDecimal, error) {
+	p, err := goex.KlinePeriodCode(klinePeriods, period)
+	if err != nil {
+		return nil, err
+	}
+	window := goex.KlinePeriodDuration(period)
+	apiURL := fmt.Sprintf(KLINE_URI, currency.ToSymbol("-"))
+
+	return goex.GetAllKlineDecimals(int64(since), size, 500, func(q goex.PageQuery) ([]goex.KlineDecimal, error) {
+		params := url.Values{}
+		params.Set("period", p)
+		params.Set("limit", fmt.Sprint(q.Limit))
+		// time为结束时间, 正序翻页时取[From, From+Limit个周期)
+		if q.From > 0 {
+			end := time.Unix(0, q.From*int64(time.Millisecond)).Add(time.Duration(q.Limit) * window)
+			params.Set("time", end.UTC().Format(time.RFC3339))
+		} else if q.To > 0 {
+			params.Set("time", time.Unix(0, q.To*int64(time.Millisecond)).UTC().Format(time.RFC3339))
+		}
+
+		var resp struct {
+			Code    int
+			Message string
+			Data    []struct {
+				Time   string
+				Open   decimal.Decimal
+				Close  decimal.Decimal
+				High   decimal.Decimal
+				Low    decimal.Decimal
+				Volume decimal.Decimal
+			}
+		}
+		err := goex.HttpGet4(bo.httpClient, apiURL+"?"+params.Encode(), nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Code != 0 {
+			return nil, fmt.Errorf("error code: %d, %s", resp.Code, resp.Message)
+		}
+
+		klines := make([]goex.KlineDecimal, 0, len(resp.Data))
+		for _, r := range resp.Data {
+			t, err := time.Parse(time.RFC3339, r.Time)
+			if err != nil {
+				continue
+			}
+			klines = append(klines, goex.KlineDecimal{Pair: currency, Timestamp: t.Unix(),
+				Open: r.Open, Close: r.Close, High: r.High, Low: r.Low, Vol: r.Volume})
+		}
+		return klines, nil
+	})
 }
 
 //非个人，整个交易所的交易记录
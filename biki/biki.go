@@ -36,6 +36,7 @@ const (
 	getTicker      = "/open/api/get_ticker?symbol=%s"
 	getMarketDepth = "/open/api/market_dept?symbol=%s&type=step0"
 	getTrades      = "/open/api/get_trades?symbol=%s"
+	getRecords     = "/open/api/get_records?symbol=%s&period=%s"
 	account        = "/open/api/user/account"
 	createOrder    = "/open/api/create_order"
 	massReplace    = "/open/api/mass_replaceV2"
@@ -43,15 +44,22 @@ const (
 	newOrder       = "/open/api/new_order"
 	orderInfo      = "/open/api/order_info"
 	allOrder       = "/open/api/all_order"
+	allTrade       = "/open/api/all_trade"
 )
 
+func init() {
+	// Biki没有查询服务器时间的接口, 用行情接口响应的Date头估计
+	goex.RegisterServerClock(goex.NewServerClock(goex.BIKI, goex.DateHeaderTimeFetcher(apiBaseURL+commonSymbols)))
+}
+
 // Biki Biki api
 type Biki struct {
 	APIKey    string
 	SecretKey string
 	client    *http.Client
+	logger    goex.Logger
 
-	symbolNameMap map[string]string
+	symbols *goex.SymbolMapper
 
 	ws               *goex.WsConn
 	createWsLock     sync.Mutex
@@ -72,38 +80,27 @@ func NewBiki(APIKey string, SecretKey string) *Biki {
 		},
 	}
 
-	biki.symbolNameMap = make(map[string]string)
+	goex.SetServerClockHttpClient(goex.BIKI, biki.client)
+
+	biki.symbols = goex.NewSymbolMapper(goex.BIKI, goex.NewInstrumentCatalog(goex.BIKI, biki))
 	return biki
 }
 
-func (biki *Biki) getPairByName(name string) string {
-	name = strings.ToUpper(name)
-	c, ok := biki.symbolNameMap[name]
-	if ok {
-		return c
+// SetLogger Set the instance logger, goex.DefaultLogger is used when nil
+func (biki *Biki) SetLogger(logger goex.Logger) {
+	if logger != nil {
+		logger = goex.NewRedactingLogger(logger)
 	}
+	biki.logger = logger
+}
 
-	var err error
-	var l []Symbol
-	for i := 0; i < 5; i++ {
-		l, err = biki.GetSymbols()
-		if err == nil {
-			break
-		}
-		time.Sleep(time.Second)
-	}
-	if err != nil {
-		panic(err)
-	}
+func (biki *Biki) log() goex.Logger {
+	return goex.ExchangeLogger(biki.logger, goex.BIKI)
+}
 
-	for _, o := range l {
-		biki.symbolNameMap[strings.ToUpper(o.Symbol)] = fmt.Sprintf("%s_%s", o.BaseCoin, o.CountCoin)
-	}
-	c, ok = biki.symbolNameMap[name]
-	if !ok {
-		return ""
-	}
-	return c
+// SymbolMapper Get the mapper between BTC_USDT style pairs and biki symbols
+func (biki *Biki) SymbolMapper() *goex.SymbolMapper {
+	return biki.symbols
 }
 
 // GetSymbols Get symbols
@@ -134,7 +131,7 @@ func (biki *Biki) GetSymbols() ([]Symbol, error) {
 	}
 
 	if data.Code.IntPart() != 0 {
-		return nil, fmt.Errorf("error code: %s", data.Code.String())
+		return nil, bikiError(data.Code.String(), data.Msg)
 	}
 
 	for i := range data.Data {
@@ -145,13 +142,30 @@ func (biki *Biki) GetSymbols() ([]Symbol, error) {
 	return data.Data, nil
 }
 
-func (biki *Biki) transSymbol(symbol string) string {
-	return strings.ToLower(strings.Replace(symbol, "_", "", -1))
+// GetInstruments Get symbols as instruments
+func (biki *Biki) GetInstruments() ([]goex.Instrument, error) {
+	symbols, err := biki.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]goex.Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
+// transSymbol BTC_USDT -> btcusdt
+func (biki *Biki) transSymbol(symbol string) (string, error) {
+	return biki.symbols.ToNative(goex.NewCurrencyPair2(strings.ToUpper(symbol)), goex.INSTRUMENT_SPOT)
 }
 
 // GetTicker Get ticker
 func (biki *Biki) GetTicker(symbol string) (*goex.TickerDecimal, error) {
-	symbol = biki.transSymbol(symbol)
+	symbol, err := biki.transSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
 	url := apiBaseURL + getTicker
 	resp, err := biki.client.Get(fmt.Sprintf(url, symbol))
 	if err != nil {
@@ -185,7 +199,7 @@ func (biki *Biki) GetTicker(symbol string) (*goex.TickerDecimal, error) {
 	}
 
 	if data.Code.IntPart() != 0 {
-		return nil, fmt.Errorf("error code: %s", data.Code.String())
+		return nil, bikiError(data.Code.String(), data.Msg)
 	}
 
 	r := data.Data
@@ -205,7 +219,10 @@ func (biki *Biki) GetTicker(symbol string) (*goex.TickerDecimal, error) {
 // GetDepth Get depth
 func (biki *Biki) GetDepth(symbol string) (*goex.DepthDecimal, error) {
 	inputSymbol := symbol
-	symbol = biki.transSymbol(symbol)
+	symbol, err := biki.transSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
 	url := fmt.Sprintf(apiBaseURL+getMarketDepth, symbol)
 	resp, err := biki.client.Get(url)
 	if err != nil {
@@ -237,7 +254,7 @@ func (biki *Biki) GetDepth(symbol string) (*goex.DepthDecimal, error) {
 	}
 
 	if data.Code.IntPart() != 0 {
-		return nil, fmt.Errorf("error code: %s", data.Code.String())
+		return nil, bikiError(data.Code.String(), data.Msg)
 	}
 
 	r := data.Data.Tick
@@ -260,7 +277,10 @@ func (biki *Biki) GetDepth(symbol string) (*goex.DepthDecimal, error) {
 
 // GetTrades Get trades
 func (biki *Biki) GetTrades(symbol string) ([]goex.TradeDecimal, error) {
-	symbol = biki.transSymbol(symbol)
+	symbol, err := biki.transSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
 	url := fmt.Sprintf(apiBaseURL+getTrades, symbol)
 	resp, err := biki.client.Get(url)
 	if err != nil {
@@ -292,7 +312,7 @@ func (biki *Biki) GetTrades(symbol string) ([]goex.TradeDecimal, error) {
 	}
 
 	if data.Code.IntPart() != 0 {
-		return nil, fmt.Errorf("error code: %s", data.Code.String())
+		return nil, bikiError(data.Code.String(), data.Msg)
 	}
 
 	var trades = make([]goex.TradeDecimal, len(data.Data))
@@ -308,6 +328,74 @@ func (biki *Biki) GetTrades(symbol string) ([]goex.TradeDecimal, error) {
 	return trades, nil
 }
 
+var klinePeriods = map[int]string{
+	goex.KLINE_PERIOD_1MIN:   "1",
+	goex.KLINE_PERIOD_5MIN:   "5",
+	goex.KLINE_PERIOD_15MIN:  "15",
+	goex.KLINE_PERIOD_30MIN:  "30",
+	goex.KLINE_PERIOD_60MIN:  "60",
+	goex.KLINE_PERIOD_1DAY:   "1440",
+	goex.KLINE_PERIOD_1WEEK:  "10080",
+	goex.KLINE_PERIOD_1MONTH: "43200",
+}
+
+// GetKlineRecordsDecimal Get klines, since is a millisecond timestamp, the latest size klines are returned when since <= 0.
+// The api only returns the latest klines, klines earlier than that are not available
+func (biki *Biki) GetKlineRecordsDecimal(pair goex.CurrencyPair, period, size, since int) ([]goex.KlineDecimal, error) {
+	code, err := goex.KlinePeriodCode(klinePeriods, period)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := biki.symbols.ToNative(pair, goex.INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, err
+	}
+
+	return goex.GetAllKlineDecimals(int64(since), size, 300, func(q goex.PageQuery) ([]goex.KlineDecimal, error) {
+		resp, err := biki.client.Get(fmt.Sprintf(apiBaseURL+getRecords, symbol, code))
+		if err != nil {
+			return nil, err
+		}
+
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var data struct {
+			Msg  string
+			Code decimal.Decimal
+			Data [][]decimal.Decimal
+		}
+
+		err = json.Unmarshal(body, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		if data.Code.IntPart() != 0 {
+			return nil, bikiError(data.Code.String(), data.Msg)
+		}
+
+		// [time, open, high, low, close, volume], time in seconds
+		klines := make([]goex.KlineDecimal, 0, len(data.Data))
+		for _, r := range data.Data {
+			if len(r) < 6 {
+				continue
+			}
+			ts := r[0].IntPart()
+			if q.From > 0 && ts*1000 < q.From || q.To > 0 && ts*1000 > q.To {
+				continue
+			}
+			klines = append(klines, goex.KlineDecimal{Pair: pair, Timestamp: ts, Open: r[1], High: r[2], Low: r[3], Close: r[4], Vol: r[5]})
+		}
+		return klines, nil
+	})
+}
+
 func (biki *Biki) signData(data string) string {
 	message := data + biki.SecretKey
 	sign, _ := goex.GetParamMD5Sign(biki.SecretKey, message)
@@ -316,7 +404,7 @@ func (biki *Biki) signData(data string) string {
 }
 
 func (biki *Biki) sign(param map[string]string) map[string]string {
-	timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	timestamp := strconv.FormatInt(goex.ServerTime(goex.BIKI).UnixNano()/int64(time.Millisecond), 10)
 	param["api_key"] = biki.APIKey
 	param["time"] = timestamp
 
@@ -375,7 +463,7 @@ func (biki *Biki) GetAccount() ([]goex.SubAccountDecimal, error) {
 	}
 
 	if resp.Code.IntPart() != 0 {
-		return nil, fmt.Errorf("error code: %s", resp.Code.String())
+		return nil, bikiError(resp.Code.String(), resp.Msg)
 	}
 
 	var ret []goex.SubAccountDecimal
@@ -397,7 +485,10 @@ func (biki *Biki) GetAccount() ([]goex.SubAccountDecimal, error) {
 
 // PlaceOrder Place order
 func (biki *Biki) PlaceOrder(volume decimal.Decimal, side string, _type int, symbol string, price decimal.Decimal) (string, error) {
-	symbol = biki.transSymbol(symbol)
+	symbol, err := biki.transSymbol(symbol)
+	if err != nil {
+		return "", err
+	}
 	params := map[string]string{
 		"side":   side,
 		"volume": volume.String(),
@@ -430,7 +521,7 @@ func (biki *Biki) PlaceOrder(volume decimal.Decimal, side string, _type int, sym
 	}
 
 	if resp.Code.IntPart() != 0 {
-		return "", fmt.Errorf("error code: %s", resp.Code.String())
+		return "", bikiError(resp.Code.String(), resp.Msg)
 	}
 
 	return resp.Data.OrderID.String(), nil
@@ -438,7 +529,10 @@ func (biki *Biki) PlaceOrder(volume decimal.Decimal, side string, _type int, sym
 
 // CancelOrder Cancel order
 func (biki *Biki) CancelOrder(symbol string, orderID string) error {
-	symbol = biki.transSymbol(symbol)
+	symbol, err := biki.transSymbol(symbol)
+	if err != nil {
+		return err
+	}
 	params := map[string]string{
 		"symbol":   symbol,
 		"order_id": orderID,
@@ -464,7 +558,7 @@ func (biki *Biki) CancelOrder(symbol string, orderID string) error {
 	}
 
 	if resp.Code.IntPart() != 0 {
-		return fmt.Errorf("error code: %s", resp.Code.String())
+		return bikiError(resp.Code.String(), resp.Msg)
 	}
 
 	return nil
@@ -476,7 +570,10 @@ func (biki *Biki) MassReplace(symbol string, cancelOrderIDs []string, reqList []
 	placeErrors = make([]error, len(reqList))
 	cancelErrors = make([]error, len(cancelOrderIDs))
 
-	symbol = biki.transSymbol(symbol)
+	symbol, err = biki.transSymbol(symbol)
+	if err != nil {
+		return
+	}
 
 	params := map[string]string{
 		"symbol": symbol,
@@ -489,14 +586,13 @@ func (biki *Biki) MassReplace(symbol string, cancelOrderIDs []string, reqList []
 
 	if len(reqList) > 0 {
 		massPlace, _ := json.Marshal(reqList)
-		println(string(massPlace))
+		biki.log().Debug("mass replace", "mass_place", string(massPlace))
 		params["mass_place"] = string(massPlace)
 	}
 
 	params = biki.sign(params)
 
 	data := biki.buildQueryString(params)
-	println(data)
 
 	url := apiBaseURL + massReplace
 	var body []byte
@@ -506,7 +602,7 @@ func (biki *Biki) MassReplace(symbol string, cancelOrderIDs []string, reqList []
 		return
 	}
 
-	println(string(body))
+	biki.log().Debug("mass replace response", "body", string(body))
 
 	var resp struct {
 		Msg  string
@@ -533,7 +629,7 @@ func (biki *Biki) MassReplace(symbol string, cancelOrderIDs []string, reqList []
 	}
 
 	if resp.Code.IntPart() != 0 {
-		err = fmt.Errorf("error code: %s", resp.Code.String())
+		err = bikiError(resp.Code.String(), resp.Msg)
 		return
 	}
 
@@ -548,7 +644,7 @@ func (biki *Biki) MassReplace(symbol string, cancelOrderIDs []string, reqList []
 			if o.Code.IsZero() {
 				continue
 			}
-			err1 := fmt.Errorf("error code: %s", o.Code.String())
+			err1 := bikiError(o.Code.String(), o.Msg)
 			for _, orderID := range o.OrderIDs {
 				index, ok := m[orderID.String()]
 				if !ok {
@@ -575,7 +671,7 @@ func (biki *Biki) MassReplace(symbol string, cancelOrderIDs []string, reqList []
 				orderIDs[i] = o.OrderIDs[i].String()
 			}
 		} else {
-			err1 := fmt.Errorf("error code: %s", o.Code.String())
+			err1 := bikiError(o.Code.String(), o.Msg)
 			for i := range placeErrors {
 				placeErrors[i] = err1
 			}
@@ -587,8 +683,12 @@ func (biki *Biki) MassReplace(symbol string, cancelOrderIDs []string, reqList []
 
 // QueryPendingOrders Query pending orders
 func (biki *Biki) QueryPendingOrders(symbol string, page, pageSize int) ([]goex.OrderDecimal, error) {
+	nativeSymbol, err := biki.transSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
 	param := map[string]string{
-		"symbol": biki.transSymbol(symbol),
+		"symbol": nativeSymbol,
 	}
 	if page > 0 {
 		param["page"] = strconv.Itoa(page)
@@ -609,13 +709,13 @@ func (biki *Biki) QueryPendingOrders(symbol string, page, pageSize int) ([]goex.
 		}
 	}
 
-	err := goex.HttpGet4(biki.client, url, nil, &resp)
+	err = goex.HttpGet4(biki.client, url, nil, &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.Code.IntPart() != 0 {
-		return nil, fmt.Errorf("error code: %s", resp.Code.String())
+		return nil, bikiError(resp.Code.String(), resp.Msg)
 	}
 
 	var ret = make([]goex.OrderDecimal, len(resp.Data.ResultList))
@@ -628,8 +728,12 @@ func (biki *Biki) QueryPendingOrders(symbol string, page, pageSize int) ([]goex.
 
 // QueryAllOrders Query all orders
 func (biki *Biki) QueryAllOrders(symbol string, page, pageSize int) ([]goex.OrderDecimal, error) {
+	nativeSymbol, err := biki.transSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
 	param := map[string]string{
-		"symbol": biki.transSymbol(symbol),
+		"symbol": nativeSymbol,
 	}
 	if page > 0 {
 		param["page"] = strconv.Itoa(page)
@@ -650,13 +754,13 @@ func (biki *Biki) QueryAllOrders(symbol string, page, pageSize int) ([]goex.Orde
 		}
 	}
 
-	err := goex.HttpGet4(biki.client, url, nil, &resp)
+	err = goex.HttpGet4(biki.client, url, nil, &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.Code.IntPart() != 0 {
-		return nil, fmt.Errorf("error code: %s", resp.Code.String())
+		return nil, bikiError(resp.Code.String(), resp.Msg)
 	}
 
 	var ret = make([]goex.OrderDecimal, len(resp.Data.OrderList))
@@ -667,11 +771,22 @@ func (biki *Biki) QueryAllOrders(symbol string, page, pageSize int) ([]goex.Orde
 	return ret, nil
 }
 
+// QueryAllOrdersByTime Query all orders created in [from, to] page by page, timestamps in milliseconds, 0 means unlimited
+func (biki *Biki) QueryAllOrdersByTime(symbol string, from, to int64, pageSize int) ([]goex.OrderDecimal, error) {
+	return goex.GetAllOrderDecimals(goex.PAGING_BY_PAGE, pageSize, from, to, func(q goex.PageQuery) ([]goex.OrderDecimal, string, error) {
+		orders, err := biki.QueryAllOrders(symbol, q.Page, q.Limit)
+		return orders, "", err
+	})
+}
+
 // QueryOrder Query an order
 func (biki *Biki) QueryOrder(symbol string, orderID string) (*goex.OrderDecimal, error) {
-	symbol = strings.ToUpper(symbol)
+	nativeSymbol, err := biki.transSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
 	param := biki.sign(map[string]string{
-		"symbol":   biki.transSymbol(symbol),
+		"symbol":   nativeSymbol,
 		"order_id": orderID,
 	})
 
@@ -685,13 +800,13 @@ func (biki *Biki) QueryOrder(symbol string, orderID string) (*goex.OrderDecimal,
 		}
 	}
 
-	err := goex.HttpGet4(biki.client, url, nil, &resp)
+	err = goex.HttpGet4(biki.client, url, nil, &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.Code.IntPart() != 0 {
-		return nil, fmt.Errorf("error code: %s", resp.Code.String())
+		return nil, bikiError(resp.Code.String(), resp.Msg)
 	}
 
 	if resp.Data.OrderInfo == nil {
@@ -700,3 +815,70 @@ func (biki *Biki) QueryOrder(symbol string, orderID string) (*goex.OrderDecimal,
 
 	return resp.Data.OrderInfo.ToOrderDecimal(symbol), nil
 }
+
+// GetFills Query the latest own trades between from and to, timestamps in milliseconds, 0 means unlimited
+func (biki *Biki) GetFills(pair goex.CurrencyPair, from, to int64, limit int) ([]goex.FillDecimal, error) {
+	if limit <= 0 {
+		return nil, goex.ErrPaginatorMisconfigured
+	}
+	fills, err := biki.getFillsPage(pair.ToSymbol("_"), from, to, 1, limit)
+	return goex.MergeFills(fills), err
+}
+
+func (biki *Biki) getFillsPage(symbol string, from, to int64, page, pageSize int) ([]goex.FillDecimal, error) {
+	nativeSymbol, err := biki.transSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+	param := map[string]string{
+		"symbol": nativeSymbol,
+	}
+	if from > 0 {
+		param["startDate"] = time.Unix(from/1000, 0).Format("2006-01-02 15:04:05")
+	}
+	if to > 0 {
+		param["endDate"] = time.Unix(to/1000, 0).Format("2006-01-02 15:04:05")
+	}
+	if page > 0 {
+		param["page"] = strconv.Itoa(page)
+	}
+	if pageSize > 0 {
+		param["pageSize"] = strconv.Itoa(pageSize)
+	}
+	param = biki.sign(param)
+
+	url := apiBaseURL + allTrade + "?" + biki.buildQueryString(param)
+
+	var resp struct {
+		Msg  string
+		Code decimal.Decimal
+		Data struct {
+			Count      int
+			ResultList []TradeInfo
+		}
+	}
+
+	err = goex.HttpGet4(biki.client, url, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Code.IntPart() != 0 {
+		return nil, bikiError(resp.Code.String(), resp.Msg)
+	}
+
+	var ret = make([]goex.FillDecimal, len(resp.Data.ResultList))
+	for i := range resp.Data.ResultList {
+		ret[i] = *resp.Data.ResultList[i].ToFillDecimal(symbol)
+	}
+
+	return ret, nil
+}
+
+// GetAllFills Query all own trades between from and to page by page, limit is the page size
+func (biki *Biki) GetAllFills(pair goex.CurrencyPair, from, to int64, limit int) ([]goex.FillDecimal, error) {
+	symbol := pair.ToSymbol("_")
+	return goex.GetAllFillsByPage(from, to, limit, func(page, limit int) ([]goex.FillDecimal, error) {
+		return biki.getFillsPage(symbol, from, to, page, limit)
+	})
+}
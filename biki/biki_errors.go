@@ -0,0 +1,37 @@
+package biki
+
+import (
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// biki返回{"code":"100005","msg":"..."}, code为0表示成功, 未收录的错误码按msg分类
+var bikiErrorCodes = goex.ErrorCodes{
+	"100002": goex.ERR_MAINTENANCE,
+	"100004": goex.ERR_INVALID_PARAM,
+	"100005": goex.ERR_AUTH,
+	"100007": goex.ERR_AUTH,
+	"110002": goex.ERR_INVALID_SYMBOL,
+	"110032": goex.ERR_AUTH,
+}
+
+func bikiError(code, msg string) error {
+	return bikiErrorCodes.NewError(goex.BIKI, code, msg)
+}
+
+func parseBikiError(statusCode int, body []byte) *goex.ExchangeError {
+	var resp struct {
+		Code decimal.Decimal
+		Msg  string
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Code.IsZero() {
+		return nil
+	}
+	return bikiErrorCodes.NewError(goex.BIKI, resp.Code.String(), resp.Msg)
+}
+
+func init() {
+	goex.RegisterErrorParser(goex.BIKI, parseBikiError, "openapi.biki.com")
+}
@@ -2,6 +2,7 @@ package biki
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -13,6 +14,8 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+var _ goex.FillsAPI = (*Biki)(nil)
+
 var biki *Biki
 
 func chk(err error) {
@@ -51,8 +54,10 @@ func TestBiki_GetSymbols(t *testing.T) {
 	output(ret)
 }
 
-func TestBiki_getPairByName(t *testing.T) {
-	fmt.Println(biki.getPairByName("bikiusdt"))
+func TestBiki_SymbolMapper(t *testing.T) {
+	inst, err := biki.SymbolMapper().FromNative("bikiusdt")
+	chk(err)
+	output(inst)
 }
 
 func TestBiki_GetTicker(t *testing.T) {
@@ -193,6 +198,20 @@ func TestBiki_GetALLOrders(t *testing.T) {
 	output(orders)
 }
 
+func TestBiki_QueryAllOrdersByTime(t *testing.T) {
+	code := "sht_usdt"
+	orders, err := biki.QueryAllOrdersByTime(code, 0, 0, 100)
+	assert.Nil(t, err)
+	output(orders)
+}
+
+func TestBiki_GetFills(t *testing.T) {
+	code := "sht_usdt"
+	fills, err := biki.GetFills(goex.NewCurrencyPair2(code), 0, 0, 100)
+	assert.Nil(t, err)
+	output(fills)
+}
+
 func TestZBG_QueryAllDoneOrders(t *testing.T) {
 	code := "sht_usdt"
 
@@ -229,3 +248,10 @@ func TestZBG_QueryAllDoneOrders(t *testing.T) {
 	assert.Nil(t, err)
 	ioutil.WriteFile(code+"-orders.json", bytes, 0666)
 }
+
+func TestParseBikiError(t *testing.T) {
+	err := parseBikiError(400, []byte(`{"code":"100005","msg":"sign error"}`))
+	assert.True(t, errors.Is(err, goex.ERR_AUTH))
+	assert.Equal(t, "100005", err.Code)
+	assert.Nil(t, parseBikiError(200, []byte(`{"code":"0","msg":"suc"}`)))
+}
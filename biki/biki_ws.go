@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"regexp"
 	"strings"
 	"time"
@@ -44,13 +43,14 @@ func (biki *Biki) createWsConn() {
 
 			biki.ws = goex.NewWsConn("wss://ws.biki.com/kline-api/ws")
 			biki.ws.SetErrorHandler(biki.errorHandle)
+			biki.ws.SetLogger(biki.logger)
 			biki.ws.ReConnect()
 			biki.ws.ReceiveMessageEx(func(isBin bool, msg []byte) {
 				var err error
 				//println(string(msg))
 				msg, err = gzipDecode(msg)
 				if err != nil {
-					fmt.Println(err)
+					biki.log().Warn("decode websocket message failed", "err", err)
 					return
 				}
 				// println(string(msg))
@@ -62,7 +62,7 @@ func (biki *Biki) createWsConn() {
 				}
 				err = json.Unmarshal(msg, &data)
 				if err != nil {
-					log.Print(err)
+					biki.log().Warn("decode websocket message failed", "err", err)
 					return
 				}
 
@@ -93,8 +93,11 @@ func (biki *Biki) createWsConn() {
 
 // GetDepthWithWs Subscribe depth
 func (biki *Biki) GetDepthWithWs(oSymbol string, handle func(*goex.DepthDecimal)) error {
+	symbol, err := biki.transSymbol(oSymbol)
+	if err != nil {
+		return err
+	}
 	biki.createWsConn()
-	symbol := biki.transSymbol(oSymbol)
 
 	channel := fmt.Sprintf("market_%s_depth_step0", symbol)
 
@@ -112,8 +115,11 @@ func (biki *Biki) GetDepthWithWs(oSymbol string, handle func(*goex.DepthDecimal)
 
 // GetTradeWithWs Subscribe trades
 func (biki *Biki) GetTradeWithWs(oSymbol string, handle func(string, []goex.TradeDecimal)) error {
+	symbol, err := biki.transSymbol(oSymbol)
+	if err != nil {
+		return err
+	}
 	biki.createWsConn()
-	symbol := biki.transSymbol(oSymbol)
 
 	channel := fmt.Sprintf("market_%s_trade_ticker", symbol)
 
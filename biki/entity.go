@@ -1,6 +1,8 @@
 package biki
 
 import (
+	"strings"
+
 	"github.com/shopspring/decimal"
 	goex "github.com/stephenlyu/GoEx"
 )
@@ -22,6 +24,20 @@ type Symbol struct {
 	PricePrecision  int    `json:"price_precision"`
 }
 
+// ToInstrument convert to instrument
+func (symbol *Symbol) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Exchange:        goex.BIKI,
+		Symbol:          symbol.Symbol,
+		Pair:            goex.NewCurrencyPair2(symbol.BaseCoin + "_" + symbol.CountCoin),
+		Type:            goex.INSTRUMENT_SPOT,
+		PricePrecision:  int32(symbol.PricePrecision),
+		AmountPrecision: int32(symbol.AmountPrecision),
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 // OrderInfo order info
 type OrderInfo struct {
 	Side         string
@@ -85,3 +101,37 @@ func (oi *OrderInfo) ToOrderDecimal(symbol string) *goex.OrderDecimal {
 		Side:         side,
 	}
 }
+
+// TradeInfo trade info
+type TradeInfo struct {
+	ID      decimal.Decimal
+	Side    string
+	Price   decimal.Decimal
+	Volume  decimal.Decimal
+	Fee     decimal.Decimal
+	FeeCoin string          `json:"feeCoin"`
+	BidID   decimal.Decimal `json:"bid_id"`
+	AskID   decimal.Decimal `json:"ask_id"`
+	Ctime   decimal.Decimal `json:"ctime"`
+}
+
+// ToFillDecimal translate to FillDecimal
+func (ti *TradeInfo) ToFillDecimal(symbol string) *goex.FillDecimal {
+	fill := &goex.FillDecimal{
+		FillId:          ti.ID.String(),
+		Currency:        goex.NewCurrencyPair2(symbol),
+		Price:           ti.Price,
+		Qty:             ti.Volume,
+		Fee:             ti.Fee,
+		FeeCurrency:     strings.ToUpper(ti.FeeCoin),
+		TransactionTime: ti.Ctime.IntPart(),
+	}
+	if ti.Side == OrderBuy {
+		fill.Side = goex.BUY
+		fill.OrderId = ti.BidID.String()
+	} else {
+		fill.Side = goex.SELL
+		fill.OrderId = ti.AskID.String()
+	}
+	return fill
+}
@@ -1,11 +1,12 @@
 package binance
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/shopspring/decimal"
 	. "github.com/stephenlyu/GoEx"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -18,17 +19,19 @@ const (
 	API_V1       = API_BASE_URL + "api/v1/"
 	API_V3       = API_BASE_URL + "api/v3/"
 
+	EXCHANGE_INFO_URI      = "exchangeInfo"
 	TICKER_URI             = "ticker/24hr?symbol=%s"
 	TICKERS_URI            = "ticker/allBookTickers"
+	ALL_TICKERS_URI        = "ticker/24hr"
 	DEPTH_URI              = "depth?symbol=%s&limit=%d"
 	ACCOUNT_URI            = "account?"
 	ORDER_URI              = "order?"
 	UNFINISHED_ORDERS_INFO = "openOrders?"
+	MY_TRADES_URI          = "myTrades?"
 )
 
 type Binance struct {
-	accessKey,
-	secretKey          string
+	signer             *SignerRef
 	httpClient         *http.Client
 
 	wsData             *WsConn
@@ -39,36 +42,163 @@ type Binance struct {
 	wsAccountHandleMap map[string]func(*SubAccountDecimal)
 	wsOrderHandleMap   map[string]func([]OrderDecimal)
 	errorHandle        func(error)
+	logger             Logger
+	symbols            *SymbolMapper
 }
 
-func (bn *Binance) buildParamsSigned(postForm *url.Values) error {
+/**
+ * 签名请求参数, 返回带API Key的请求头
+ */
+func (bn *Binance) buildParamsSigned(postForm *url.Values) (map[string]string, error) {
 	postForm.Set("recvWindow", "6000000")
-	tonce := strconv.FormatInt(time.Now().UnixNano(), 10)[0:13]
+	tonce := strconv.FormatInt(ServerTime(BINANCE).UnixNano(), 10)[0:13]
 	postForm.Set("timestamp", tonce)
-	payload := postForm.Encode()
-	sign, _ := GetParamHmacSHA256Sign(bn.secretKey, payload)
-	postForm.Set("signature", sign)
-	return nil
+	signature, err := bn.signer.Sign(HMAC_SHA256_HEX, SignMessage(postForm.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	postForm.Set("signature", signature.Sign)
+	return map[string]string{"X-MBX-APIKEY": signature.ApiKey}, nil
 }
 
 func New(client *http.Client, api_key, secret_key string) *Binance {
-	return &Binance{
-		accessKey: api_key,
-		secretKey: secret_key,
+	return NewWithSigner(client, NewStaticSigner(api_key, secret_key, ""))
+}
+
+/**
+ * 使用自定义签名器创建实例, 密钥可以来自环境变量、加密文件或凭证代理
+ */
+func NewWithSigner(client *http.Client, signer Signer) *Binance {
+	SetServerClockHttpClient(BINANCE, client)
+	bn := &Binance{
+		signer:     NewSignerRef(signer),
 		httpClient: client}
+	bn.symbols = NewSymbolMapper(BINANCE, NewInstrumentCatalog(BINANCE, bn))
+	return bn
+}
+
+/**
+ * 替换签名器, 用于运行时轮换密钥
+ */
+func (bn *Binance) SetSigner(signer Signer) {
+	bn.signer.Set(signer)
+}
+
+// https://binance-docs.github.io/apidocs/spot/cn/#8ad7ac4f63
+func fetchServerTime(ctx context.Context, client *http.Client) (time.Time, error) {
+	var resp struct {
+		ServerTime int64
+	}
+	err := HttpGet4WithContext(ctx, client, API_V3+"time", nil, &resp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, resp.ServerTime*int64(time.Millisecond)), nil
+}
+
+func init() {
+	RegisterServerClock(NewServerClock(BINANCE, fetchServerTime))
 }
 
 func (bn *Binance) GetExchangeName() string {
 	return BINANCE
 }
 
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (bn *Binance) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	bn.logger = logger
+}
+
+func (bn *Binance) log() Logger {
+	return ExchangeLogger(bn.logger, BINANCE)
+}
+
+type symbolFilter struct {
+	FilterType  string
+	TickSize    decimal.Decimal
+	StepSize    decimal.Decimal
+	MinQty      decimal.Decimal
+	MinNotional decimal.Decimal
+}
+
+type symbolInfo struct {
+	Symbol              string
+	Status              string
+	BaseAsset           string
+	QuoteAsset          string
+	BaseAssetPrecision  int32
+	QuotePrecision      int32
+	Filters             []symbolFilter
+}
+
+func (this *symbolInfo) toInstrument() Instrument {
+	inst := Instrument{
+		Exchange:        BINANCE,
+		Symbol:          this.Symbol,
+		Pair:            NewCurrencyPair(NewCurrency(this.BaseAsset, ""), NewCurrency(this.QuoteAsset, "")),
+		Type:            INSTRUMENT_SPOT,
+		PricePrecision:  this.QuotePrecision,
+		AmountPrecision: this.BaseAssetPrecision,
+	}
+	for _, filter := range this.Filters {
+		switch filter.FilterType {
+		case "PRICE_FILTER":
+			inst.TickSize = filter.TickSize
+		case "LOT_SIZE":
+			inst.LotSize = filter.StepSize
+			inst.MinQty = filter.MinQty
+		case "MIN_NOTIONAL":
+			inst.MinNotional = filter.MinNotional
+		}
+	}
+	switch this.Status {
+	case "TRADING":
+		inst.Status = INSTRUMENT_STATUS_TRADING
+	case "BREAK", "HALT", "AUCTION_MATCH", "PRE_TRADING", "POST_TRADING":
+		inst.Status = INSTRUMENT_STATUS_SUSPENDED
+	case "END_OF_DAY":
+		inst.Status = INSTRUMENT_STATUS_DELISTED
+	}
+	inst.FillPrecision()
+	return inst
+}
+
+func (bn *Binance) GetInstruments() ([]Instrument, error) {
+	var resp struct {
+		Symbols []symbolInfo
+	}
+	err := HttpGet4(bn.httpClient, API_V3+EXCHANGE_INFO_URI, nil, &resp)
+	if err != nil {
+		bn.log().Warn("get exchange info failed", "err", err)
+		return nil, err
+	}
+
+	ret := make([]Instrument, len(resp.Symbols))
+	for i := range resp.Symbols {
+		ret[i] = resp.Symbols[i].toInstrument()
+	}
+	return ret, nil
+}
+
 func (bn *Binance) GetTicker(currency CurrencyPair) (*Ticker, error) {
-	currency2 := bn.adaptCurrencyPair(currency)
-	tickerUri := API_V1 + fmt.Sprintf(TICKER_URI, currency2.ToSymbol(""))
-	tickerMap, err := HttpGet(bn.httpClient, tickerUri)
+	return bn.GetTickerWithContext(context.Background(), currency)
+}
+
+func (bn *Binance) GetTickerWithContext(ctx context.Context, currency CurrencyPair) (*Ticker, error) {
+	symbol, err := bn.pairSymbol(currency)
+	if err != nil {
+		return nil, err
+	}
+	tickerUri := API_V1 + fmt.Sprintf(TICKER_URI, symbol)
+	tickerMap, err := HttpGetWithContext(ctx, bn.httpClient, tickerUri)
 
 	if err != nil {
-		log.Println("GetTicker error:", err)
+		bn.log().Warn("get ticker failed", "pair", currency, "err", err)
 		return nil, err
 	}
 
@@ -85,23 +215,79 @@ func (bn *Binance) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	return &ticker, nil
 }
 
+/**
+ * 所有交易对的24小时行情, 交易对代码通过exchangeInfo转换, 找不到的交易对忽略
+ */
+func (bn *Binance) GetAllTickers() (map[CurrencyPair]*TickerDecimal, error) {
+	var resp []struct {
+		Symbol             string
+		PriceChangePercent decimal.Decimal
+		LastPrice          decimal.Decimal
+		BidPrice           decimal.Decimal
+		AskPrice           decimal.Decimal
+		OpenPrice          decimal.Decimal
+		HighPrice          decimal.Decimal
+		LowPrice           decimal.Decimal
+		Volume             decimal.Decimal
+		QuoteVolume        decimal.Decimal
+		CloseTime          int64
+	}
+	err := HttpGet4(bn.httpClient, API_V3+ALL_TICKERS_URI, nil, &resp)
+	if err != nil {
+		bn.log().Warn("get all tickers failed", "err", err)
+		return nil, err
+	}
+
+	ret := make(map[CurrencyPair]*TickerDecimal, len(resp))
+	for _, r := range resp {
+		inst, err := bn.symbols.FromNative(r.Symbol)
+		if errors.Is(err, ErrInstrumentNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret[inst.Pair] = &TickerDecimal{
+			Pair:     inst.Pair,
+			Last:     r.LastPrice,
+			Buy:      r.BidPrice,
+			Sell:     r.AskPrice,
+			Open:     r.OpenPrice,
+			High:     r.HighPrice,
+			Low:      r.LowPrice,
+			Vol:      r.Volume,
+			QuoteVol: r.QuoteVolume,
+			Change:   r.PriceChangePercent.Div(decimal.New(100, 0)),
+			Date:     uint64(r.CloseTime / 1000),
+		}
+	}
+	return ret, nil
+}
+
 func (bn *Binance) GetDepth(size int, currencyPair CurrencyPair) (*Depth, error) {
+	return bn.GetDepthWithContext(context.Background(), size, currencyPair)
+}
+
+func (bn *Binance) GetDepthWithContext(ctx context.Context, size int, currencyPair CurrencyPair) (*Depth, error) {
 	if size > 100 {
 		size = 100
 	} else if size < 5 {
 		size = 5
 	}
-	currencyPair2 := bn.adaptCurrencyPair(currencyPair)
+	symbol, err := bn.pairSymbol(currencyPair)
+	if err != nil {
+		return nil, err
+	}
 
-	apiUrl := fmt.Sprintf(API_V1+DEPTH_URI, currencyPair2.ToSymbol(""), size)
-	resp, err := HttpGet(bn.httpClient, apiUrl)
+	apiUrl := fmt.Sprintf(API_V1+DEPTH_URI, symbol, size)
+	resp, err := HttpGetWithContext(ctx, bn.httpClient, apiUrl)
 	if err != nil {
-		log.Println("GetDepth error:", err)
+		bn.log().Warn("get depth failed", "pair", currencyPair, "err", err)
 		return nil, err
 	}
 
-	if _, isok := resp["code"]; isok {
-		return nil, errors.New(resp["msg"].(string))
+	if code, isok := resp["code"]; isok {
+		return nil, binanceError(code, resp["msg"].(string))
 	}
 
 	bids := resp["bids"].([]interface{})
@@ -131,11 +317,14 @@ func (bn *Binance) GetDepth(size int, currencyPair CurrencyPair) (*Depth, error)
 	return depth, nil
 }
 
-func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType, orderSide string) (*Order, error) {
-	pair = bn.adaptCurrencyPair(pair)
+func (bn *Binance) placeOrder(ctx context.Context, amount, price string, pair CurrencyPair, orderType, orderSide string) (*Order, error) {
+	symbol, err := bn.pairSymbol(pair)
+	if err != nil {
+		return nil, err
+	}
 	path := API_V3 + ORDER_URI
 	params := url.Values{}
-	params.Set("symbol", pair.ToSymbol(""))
+	params.Set("symbol", symbol)
 	params.Set("side", orderSide)
 	params.Set("type", orderType)
 
@@ -148,10 +337,13 @@ func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType
 		params.Set("price", price)
 	}
 
-	bn.buildParamsSigned(&params)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := HttpPostForm2(bn.httpClient, path, params,
-		map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	resp, err := HttpPostForm2WithContext(ctx, bn.httpClient, path, params,
+		header)
 	//log.Println("resp:", string(resp), "err:", err)
 	if err != nil {
 		return nil, err
@@ -160,7 +352,7 @@ func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType
 	respmap := make(map[string]interface{})
 	err = json.Unmarshal(resp, &respmap)
 	if err != nil {
-		log.Println(string(resp))
+		bn.log().Warn("decode response failed", "err", err, "body", string(resp))
 		return nil, err
 	}
 
@@ -188,17 +380,24 @@ func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType
 }
 
 func (bn *Binance) GetAccount() (*Account, error) {
+	return bn.GetAccountWithContext(context.Background())
+}
+
+func (bn *Binance) GetAccountWithContext(ctx context.Context) (*Account, error) {
 	params := url.Values{}
-	bn.buildParamsSigned(&params)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
 	path := API_V3 + ACCOUNT_URI + params.Encode()
-	respmap, err := HttpGet2(bn.httpClient, path, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	respmap, err := HttpGet2WithContext(ctx, bn.httpClient, path, header)
 	if err != nil {
-		log.Println(err)
+		bn.log().Warn("get account failed", "err", err)
 		return nil, err
 	}
 	//log.Println("respmap:", respmap)
-	if _, isok := respmap["code"]; isok == true {
-		return nil, errors.New(respmap["msg"].(string))
+	if code, isok := respmap["code"]; isok == true {
+		return nil, binanceError(code, respmap["msg"].(string))
 	}
 	acc := Account{}
 	acc.Exchange = bn.GetExchangeName()
@@ -208,7 +407,7 @@ func (bn *Binance) GetAccount() (*Account, error) {
 	for _, v := range balances {
 		//log.Println(v)
 		vv := v.(map[string]interface{})
-		currency := NewCurrency(vv["asset"].(string), "").AdaptBccToBch()
+		currency := bn.symbols.CanonicalCurrency(NewCurrency(vv["asset"].(string), ""))
 		acc.SubAccounts[currency] = SubAccount{
 			Currency:     currency,
 			Amount:       ToFloat64(vv["free"]),
@@ -220,31 +419,57 @@ func (bn *Binance) GetAccount() (*Account, error) {
 }
 
 func (bn *Binance) LimitBuy(amount, price string, currencyPair CurrencyPair) (*Order, error) {
-	return bn.placeOrder(amount, price, currencyPair, "LIMIT", "BUY")
+	return bn.LimitBuyWithContext(context.Background(), amount, price, currencyPair)
+}
+
+func (bn *Binance) LimitBuyWithContext(ctx context.Context, amount, price string, currencyPair CurrencyPair) (*Order, error) {
+	return bn.placeOrder(ctx, amount, price, currencyPair, "LIMIT", "BUY")
 }
 
 func (bn *Binance) LimitSell(amount, price string, currencyPair CurrencyPair) (*Order, error) {
-	return bn.placeOrder(amount, price, currencyPair, "LIMIT", "SELL")
+	return bn.LimitSellWithContext(context.Background(), amount, price, currencyPair)
+}
+
+func (bn *Binance) LimitSellWithContext(ctx context.Context, amount, price string, currencyPair CurrencyPair) (*Order, error) {
+	return bn.placeOrder(ctx, amount, price, currencyPair, "LIMIT", "SELL")
 }
 
 func (bn *Binance) MarketBuy(amount, price string, currencyPair CurrencyPair) (*Order, error) {
-	return bn.placeOrder(amount, price, currencyPair, "MARKET", "BUY")
+	return bn.MarketBuyWithContext(context.Background(), amount, price, currencyPair)
+}
+
+func (bn *Binance) MarketBuyWithContext(ctx context.Context, amount, price string, currencyPair CurrencyPair) (*Order, error) {
+	return bn.placeOrder(ctx, amount, price, currencyPair, "MARKET", "BUY")
 }
 
 func (bn *Binance) MarketSell(amount, price string, currencyPair CurrencyPair) (*Order, error) {
-	return bn.placeOrder(amount, price, currencyPair, "MARKET", "SELL")
+	return bn.MarketSellWithContext(context.Background(), amount, price, currencyPair)
+}
+
+func (bn *Binance) MarketSellWithContext(ctx context.Context, amount, price string, currencyPair CurrencyPair) (*Order, error) {
+	return bn.placeOrder(ctx, amount, price, currencyPair, "MARKET", "SELL")
 }
 
 func (bn *Binance) CancelOrder(orderId string, currencyPair CurrencyPair) (bool, error) {
-	currencyPair = bn.adaptCurrencyPair(currencyPair)
+	return bn.CancelOrderWithContext(context.Background(), orderId, currencyPair)
+}
+
+func (bn *Binance) CancelOrderWithContext(ctx context.Context, orderId string, currencyPair CurrencyPair) (bool, error) {
+	symbol, err := bn.pairSymbol(currencyPair)
+	if err != nil {
+		return false, err
+	}
 	path := API_V3 + ORDER_URI
 	params := url.Values{}
-	params.Set("symbol", currencyPair.ToSymbol(""))
+	params.Set("symbol", symbol)
 	params.Set("orderId", orderId)
 
-	bn.buildParamsSigned(&params)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return false, err
+	}
 
-	resp, err := HttpDeleteForm(bn.httpClient, path, params, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	resp, err := HttpDeleteFormWithContext(ctx, bn.httpClient, path, params, header)
 
 	//log.Println("resp:", string(resp), "err:", err)
 	if err != nil {
@@ -254,7 +479,7 @@ func (bn *Binance) CancelOrder(orderId string, currencyPair CurrencyPair) (bool,
 	respmap := make(map[string]interface{})
 	err = json.Unmarshal(resp, &respmap)
 	if err != nil {
-		log.Println(string(resp))
+		bn.log().Warn("decode response failed", "err", err, "body", string(resp))
 		return false, err
 	}
 
@@ -267,18 +492,28 @@ func (bn *Binance) CancelOrder(orderId string, currencyPair CurrencyPair) (bool,
 }
 
 func (bn *Binance) GetOneOrder(orderId string, currencyPair CurrencyPair) (*Order, error) {
+	return bn.GetOneOrderWithContext(context.Background(), orderId, currencyPair)
+}
+
+func (bn *Binance) GetOneOrderWithContext(ctx context.Context, orderId string, currencyPair CurrencyPair) (*Order, error) {
+	symbol, err := bn.pairSymbol(currencyPair)
+	if err != nil {
+		return nil, err
+	}
 	params := url.Values{}
-	currencyPair = bn.adaptCurrencyPair(currencyPair)
-	params.Set("symbol", currencyPair.ToSymbol(""))
+	params.Set("symbol", symbol)
 	if orderId != "" {
 		params.Set("orderId", orderId)
 	}
 	params.Set("orderId", orderId)
 
-	bn.buildParamsSigned(&params)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
 	path := API_V3 + ORDER_URI + params.Encode()
 
-	respmap, err := HttpGet2(bn.httpClient, path, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	respmap, err := HttpGet2WithContext(ctx, bn.httpClient, path, header)
 	//log.Println(respmap)
 	if err != nil {
 		return nil, err
@@ -319,14 +554,24 @@ func (bn *Binance) GetOneOrder(orderId string, currencyPair CurrencyPair) (*Orde
 }
 
 func (bn *Binance) GetUnfinishOrders(currencyPair CurrencyPair) ([]Order, error) {
+	return bn.GetUnfinishOrdersWithContext(context.Background(), currencyPair)
+}
+
+func (bn *Binance) GetUnfinishOrdersWithContext(ctx context.Context, currencyPair CurrencyPair) ([]Order, error) {
+	symbol, err := bn.pairSymbol(currencyPair)
+	if err != nil {
+		return nil, err
+	}
 	params := url.Values{}
-	currencyPair = bn.adaptCurrencyPair(currencyPair)
-	params.Set("symbol", currencyPair.ToSymbol(""))
+	params.Set("symbol", symbol)
 
-	bn.buildParamsSigned(&params)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
 	path := API_V3 + UNFINISHED_ORDERS_INFO + params.Encode()
 
-	respmap, err := HttpGet3(bn.httpClient, path, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	respmap, err := HttpGet3WithContext(ctx, bn.httpClient, path, header)
 	//log.Println("respmap", respmap, "err", err)
 	if err != nil {
 		return nil, err
@@ -355,17 +600,105 @@ func (bn *Binance) GetUnfinishOrders(currencyPair CurrencyPair) ([]Order, error)
 }
 
 func (bn *Binance) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
+	return bn.GetKlineRecordsWithContext(context.Background(), currency, period, size, since)
+}
+
+func (bn *Binance) GetKlineRecordsWithContext(ctx context.Context, currency CurrencyPair, period, size, since int) ([]Kline, error) {
 	panic("not implements")
 }
 
-//非个人，整个交易所的交易记录
 func (bn *Binance) GetTrades(currencyPair CurrencyPair, since int64) ([]Trade, error) {
+	return bn.GetTradesWithContext(context.Background(), currencyPair, since)
+}
+
+//非个人，整个交易所的交易记录
+func (bn *Binance) GetTradesWithContext(ctx context.Context, currencyPair CurrencyPair, since int64) ([]Trade, error) {
 	panic("not implements")
 }
 
 func (bn *Binance) GetOrderHistorys(currency CurrencyPair, currentPage, pageSize int) ([]Order, error) {
+	return bn.GetOrderHistorysWithContext(context.Background(), currency, currentPage, pageSize)
+}
+
+func (bn *Binance) GetOrderHistorysWithContext(ctx context.Context, currency CurrencyPair, currentPage, pageSize int) ([]Order, error) {
 	panic("not implements")
 }
-func (ba *Binance) adaptCurrencyPair(pair CurrencyPair) CurrencyPair {
-	return pair.AdaptBchToBcc().AdaptUsdToUsdt()
+/**
+ * 获取成交明细, from/to为毫秒时间戳, 0表示不限制, 按时间正序返回
+ */
+func (bn *Binance) GetFills(currencyPair CurrencyPair, from, to int64, limit int) ([]FillDecimal, error) {
+	symbol, err := bn.pairSymbol(currencyPair)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	if from > 0 {
+		params.Set("startTime", fmt.Sprint(from))
+	}
+	if to > 0 {
+		params.Set("endTime", fmt.Sprint(to))
+	}
+	if limit > 0 {
+		params.Set("limit", fmt.Sprint(limit))
+	}
+
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
+	path := API_V3 + MY_TRADES_URI + params.Encode()
+
+	var resp []struct {
+		Id              int64
+		OrderId         int64
+		Price           decimal.Decimal
+		Qty             decimal.Decimal
+		Commission      decimal.Decimal
+		CommissionAsset string
+		Time            int64
+		IsBuyer         bool
+		IsMaker         bool
+	}
+	err = HttpGet4(bn.httpClient, path, header, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]FillDecimal, len(resp))
+	for i, o := range resp {
+		side := SELL
+		if o.IsBuyer {
+			side = BUY
+		}
+		ret[i] = FillDecimal{
+			FillId:          fmt.Sprint(o.Id),
+			OrderId:         fmt.Sprint(o.OrderId),
+			Currency:        currencyPair,
+			Side:            TradeSide(side),
+			Price:           o.Price,
+			Qty:             o.Qty,
+			Fee:             o.Commission,
+			FeeCurrency:     o.CommissionAsset,
+			IsMaker:         o.IsMaker,
+			TransactionTime: o.Time,
+		}
+	}
+	return ret, nil
+}
+
+/**
+ * 按时间向后翻页, 获取[from, to]内的全部成交明细
+ */
+func (bn *Binance) GetAllFills(currencyPair CurrencyPair, from, to int64, limit int) ([]FillDecimal, error) {
+	return GetAllFillsByTime(from, to, limit, func(from, to int64, limit int) ([]FillDecimal, error) {
+		return bn.GetFills(currencyPair, from, to, limit)
+	})
+}
+
+/**
+ * 交易对代码从交易品种列表中查找, 没有USD计价的交易对时使用USDT计价
+ */
+func (bn *Binance) pairSymbol(pair CurrencyPair) (string, error) {
+	return bn.symbols.ToNative(pair, INSTRUMENT_SPOT)
 }
@@ -0,0 +1,47 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/stephenlyu/GoEx"
+)
+
+// https://binance-docs.github.io/apidocs/spot/cn/#api, -2010/-2011等拒单原因由错误信息区分
+var binanceErrorCodes = ErrorCodes{
+	"-1001": ERR_NETWORK,
+	"-1003": ERR_RATE_LIMITED,
+	"-1015": ERR_RATE_LIMITED,
+	"-1016": ERR_MAINTENANCE,
+	"-1021": ERR_NONCE,
+	"-1022": ERR_AUTH,
+	"-1100": ERR_INVALID_PARAM,
+	"-1102": ERR_INVALID_PARAM,
+	"-1121": ERR_INVALID_SYMBOL,
+	"-2013": ERR_ORDER_NOT_FOUND,
+	"-2014": ERR_AUTH,
+	"-2015": ERR_AUTH,
+}
+
+func binanceError(code interface{}, msg string) error {
+	c := fmt.Sprint(code)
+	if f, ok := code.(float64); ok {
+		c = fmt.Sprint(int64(f))
+	}
+	return binanceErrorCodes.NewError(BINANCE, c, msg)
+}
+
+func parseBinanceError(statusCode int, body []byte) *ExchangeError {
+	var resp struct {
+		Code int64
+		Msg  string
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Code == 0 {
+		return nil
+	}
+	return binanceErrorCodes.NewError(BINANCE, fmt.Sprint(resp.Code), resp.Msg)
+}
+
+func init() {
+	RegisterErrorParser(BINANCE, parseBinanceError, "api.binance.com")
+}
@@ -0,0 +1,318 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	. "github.com/stephenlyu/GoEx"
+)
+
+const (
+	WAPI_V3 = API_BASE_URL + "wapi/v3/"
+	SAPI_V1 = API_BASE_URL + "sapi/v1/"
+
+	DEPOSIT_ADDRESS_URI  = "depositAddress.html?"
+	DEPOSIT_HISTORY_URI  = "depositHistory.html?"
+	WITHDRAW_URI         = "withdraw.html?"
+	WITHDRAW_HISTORY_URI = "withdrawHistory.html?"
+	ASSET_DETAIL_URI     = "assetDetail.html?"
+	MARGIN_TRANSFER_URI  = "margin/transfer?"
+	FUTURES_TRANSFER_URI = "futures/transfer?"
+)
+
+func (bn *Binance) GetDepositAddress(currency Currency) ([]DepositAddress, error) {
+	params := url.Values{}
+	params.Set("asset", currency.Symbol)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Success    bool
+		Msg        string
+		Address    string
+		AddressTag string
+		Asset      string
+	}
+	err = HttpGet4(bn.httpClient, WAPI_V3+DEPOSIT_ADDRESS_URI+params.Encode(), header, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, binanceError("", resp.Msg)
+	}
+
+	return []DepositAddress{{
+		Currency: NewCurrency(resp.Asset, ""),
+		Address:  resp.Address,
+		Tag:      resp.AddressTag,
+	}}, nil
+}
+
+type depositWithdraw struct {
+	Id             string
+	Amount         decimal.Decimal
+	TransactionFee decimal.Decimal
+	Asset          string
+	Address        string
+	AddressTag     string
+	TxId           string
+	InsertTime     int64
+	ApplyTime      int64
+	Status         int
+}
+
+// 0: 等待确认; 6: 已入账但不可提现; 1: 充值成功
+func (this *depositWithdraw) toDeposit() *FundingRecord {
+	status := FUNDING_PROCESSING
+	if this.Status == 1 {
+		status = FUNDING_SUCCESS
+	}
+	return &FundingRecord{
+		Id:        this.TxId,
+		Type:      FUNDING_DEPOSIT,
+		Currency:  NewCurrency(this.Asset, ""),
+		Amount:    this.Amount,
+		Address:   this.Address,
+		Tag:       this.AddressTag,
+		TxId:      this.TxId,
+		Status:    status,
+		RawStatus: fmt.Sprint(this.Status),
+		Timestamp: this.InsertTime,
+	}
+}
+
+// 0: 已发送确认邮件; 1: 已取消; 2: 等待确认; 3: 被拒绝; 4: 处理中; 5: 提现失败; 6: 提现完成
+func (this *depositWithdraw) toWithdraw() *FundingRecord {
+	var status FundingStatus
+	switch this.Status {
+	case 1:
+		status = FUNDING_CANCELED
+	case 3, 5:
+		status = FUNDING_FAILED
+	case 4:
+		status = FUNDING_PROCESSING
+	case 6:
+		status = FUNDING_SUCCESS
+	default:
+		status = FUNDING_PENDING
+	}
+	return &FundingRecord{
+		Id:        this.Id,
+		Type:      FUNDING_WITHDRAW,
+		Currency:  NewCurrency(this.Asset, ""),
+		Amount:    this.Amount,
+		Fee:       this.TransactionFee,
+		Address:   this.Address,
+		Tag:       this.AddressTag,
+		TxId:      this.TxId,
+		Status:    status,
+		RawStatus: fmt.Sprint(this.Status),
+		Timestamp: this.ApplyTime,
+	}
+}
+
+func (bn *Binance) GetDeposits(currency Currency) ([]FundingRecord, error) {
+	params := url.Values{}
+	params.Set("asset", currency.Symbol)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Success     bool
+		Msg         string
+		DepositList []depositWithdraw
+	}
+	err = HttpGet4(bn.httpClient, WAPI_V3+DEPOSIT_HISTORY_URI+params.Encode(), header, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, binanceError("", resp.Msg)
+	}
+
+	ret := make([]FundingRecord, len(resp.DepositList))
+	for i := range resp.DepositList {
+		ret[i] = *resp.DepositList[i].toDeposit()
+	}
+	return ret, nil
+}
+
+func (bn *Binance) GetWithdrawals(currency Currency) ([]FundingRecord, error) {
+	params := url.Values{}
+	params.Set("asset", currency.Symbol)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Success      bool
+		Msg          string
+		WithdrawList []depositWithdraw
+	}
+	err = HttpGet4(bn.httpClient, WAPI_V3+WITHDRAW_HISTORY_URI+params.Encode(), header, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, binanceError("", resp.Msg)
+	}
+
+	ret := make([]FundingRecord, len(resp.WithdrawList))
+	for i := range resp.WithdrawList {
+		ret[i] = *resp.WithdrawList[i].toWithdraw()
+	}
+	return ret, nil
+}
+
+func (bn *Binance) GetWithdrawal(currency Currency, withdrawId string) (*FundingRecord, error) {
+	records, err := bn.GetWithdrawals(currency)
+	if err != nil {
+		return nil, err
+	}
+	return FindFundingRecord(records, withdrawId)
+}
+
+/**
+ * 币安自动扣除提币手续费, 忽略param.Fee; param.Chain作为提币网络network
+ */
+func (bn *Binance) CreateWithdrawal(param WithdrawParam) (string, error) {
+	params := url.Values{}
+	params.Set("asset", param.Currency.Symbol)
+	params.Set("address", param.Address)
+	params.Set("amount", param.Amount.String())
+	if param.Tag != "" {
+		params.Set("addressTag", param.Tag)
+	}
+	if param.Chain != "" {
+		params.Set("network", param.Chain)
+	}
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := HttpPostForm3(bn.httpClient, WAPI_V3+WITHDRAW_URI+params.Encode(), "", header)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		Success bool
+		Msg     string
+		Id      string
+	}
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	if !resp.Success {
+		return "", binanceError("", resp.Msg)
+	}
+
+	return resp.Id, nil
+}
+
+func (bn *Binance) GetWithdrawalFees(currency Currency) ([]WithdrawFeeDecimal, error) {
+	params := url.Values{}
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Success     bool
+		Msg         string
+		AssetDetail map[string]struct {
+			MinWithdrawAmount decimal.Decimal
+			WithdrawFee       decimal.Decimal
+			WithdrawStatus    bool
+		}
+	}
+	err = HttpGet4(bn.httpClient, WAPI_V3+ASSET_DETAIL_URI+params.Encode(), header, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, binanceError("", resp.Msg)
+	}
+
+	var ret []WithdrawFeeDecimal
+	for asset, detail := range resp.AssetDetail {
+		if currency != UNKNOWN && !strings.EqualFold(asset, currency.Symbol) {
+			continue
+		}
+		ret = append(ret, WithdrawFeeDecimal{
+			Currency:  NewCurrency(asset, ""),
+			MinFee:    detail.WithdrawFee,
+			MaxFee:    detail.WithdrawFee,
+			MinAmount: detail.MinWithdrawAmount,
+		})
+	}
+	return ret, nil
+}
+
+/**
+ * 支持币币账户与杠杆账户(margin)、U本位合约账户(swap)、币本位合约账户(future)之间的划转
+ */
+func (bn *Binance) TransferBetweenWallets(currency Currency, amount decimal.Decimal, from, to WalletType) (string, error) {
+	var uri, _type string
+	switch {
+	case from == WALLET_SPOT && to == WALLET_MARGIN:
+		uri, _type = MARGIN_TRANSFER_URI, "1"
+	case from == WALLET_MARGIN && to == WALLET_SPOT:
+		uri, _type = MARGIN_TRANSFER_URI, "2"
+	case from == WALLET_SPOT && to == WALLET_SWAP:
+		uri, _type = FUTURES_TRANSFER_URI, "1"
+	case from == WALLET_SWAP && to == WALLET_SPOT:
+		uri, _type = FUTURES_TRANSFER_URI, "2"
+	case from == WALLET_SPOT && to == WALLET_FUTURE:
+		uri, _type = FUTURES_TRANSFER_URI, "3"
+	case from == WALLET_FUTURE && to == WALLET_SPOT:
+		uri, _type = FUTURES_TRANSFER_URI, "4"
+	default:
+		return "", EX_ERR_NOT_SUPPORTED
+	}
+
+	params := url.Values{}
+	params.Set("asset", currency.Symbol)
+	params.Set("amount", amount.String())
+	params.Set("type", _type)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := HttpPostForm2(bn.httpClient, SAPI_V1+uri, params, header)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		TranId decimal.Decimal
+		Code   int
+		Msg    string
+	}
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.Code != 0 {
+		return "", binanceError(resp.Code, resp.Msg)
+	}
+
+	return resp.TranId.String(), nil
+}
@@ -1,17 +1,31 @@
 package binance
 
 import (
+	"errors"
 	"github.com/stephenlyu/GoEx"
 	"net/http"
 	"testing"
 )
 
+var _ goex.FillsAPI = (*Binance)(nil)
+
 var ba = New(http.DefaultClient, "", "")
 
+var _ goex.APIContext = ba
+var _ goex.TickersAPI = ba
+
 func TestBinance_GetTicker(t *testing.T) {
 	ticker, _ := ba.GetTicker(goex.LTC_BTC)
 	t.Log(ticker)
 }
+func TestBinance_GetAllTickers(t *testing.T) {
+	tickers, err := ba.GetAllTickers()
+	t.Log(len(tickers), err)
+	if err == nil {
+		t.Log(tickers[goex.BTC_USDT])
+	}
+}
+
 func TestBinance_LimitSell(t *testing.T) {
 	order, err := ba.LimitSell("1", "1", goex.LTC_BTC)
 	t.Log(order, err)
@@ -35,3 +49,29 @@ func TestBinance_GetUnfinishOrders(t *testing.T) {
 	orders, err := ba.GetUnfinishOrders(goex.ETH_BTC)
 	t.Log(orders, err)
 }
+
+func TestBinance_GetFills(t *testing.T) {
+	fills, err := ba.GetFills(goex.ETH_BTC, 0, 0, 100)
+	t.Log(fills, err)
+}
+
+func TestBinance_GetDepositAddress(t *testing.T) {
+	ret, err := ba.GetDepositAddress(goex.BTC)
+	t.Log(ret, err)
+}
+
+func TestBinance_GetWithdrawals(t *testing.T) {
+	ret, err := ba.GetWithdrawals(goex.BTC)
+	t.Log(ret, err)
+}
+
+func TestParseBinanceError(t *testing.T) {
+	err := parseBinanceError(400, []byte(`{"code":-2013,"msg":"Order does not exist."}`))
+	if !errors.Is(err, goex.ERR_ORDER_NOT_FOUND) {
+		t.Error(err.Category)
+	}
+	err = parseBinanceError(400, []byte(`{"code":-2010,"msg":"Order would immediately match and take."}`))
+	if !errors.Is(err, goex.ERR_POST_ONLY_REJECTED) {
+		t.Error(err.Category)
+	}
+}
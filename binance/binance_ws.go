@@ -8,7 +8,6 @@ import (
 	"time"
 	"github.com/shopspring/decimal"
 	"github.com/pborman/uuid"
-	"github.com/z-ray/log"
 	"github.com/gorilla/websocket"
 )
 
@@ -34,6 +33,7 @@ func (this *Binance) createDataWsConn(symbols []string) {
 	url := fmt.Sprintf("wss://stream.binance.com:9443/stream?streams=%s", strings.Join(streams, "/"))
 	ws := NewWsConn(url)
 	ws.SetErrorHandler(this.errorHandle)
+	ws.SetLogger(this.logger)
 	ws.HeartbeatEx(func() (int, string) {return websocket.PongMessage, "pong"}, 20*time.Second)
 	ws.ReConnect()
 	ws.ReceiveMessageEx(func(isBin bool, msg []byte) {
@@ -46,7 +46,7 @@ func (this *Binance) createDataWsConn(symbols []string) {
 		}
 		err := json.Unmarshal(msg, &data)
 		if err != nil {
-			log.Print(err)
+			this.log().Warn("decode websocket message failed", "err", err)
 			return
 		}
 
@@ -8,7 +8,6 @@ import (
 	"time"
 	"github.com/shopspring/decimal"
 	"github.com/pborman/uuid"
-	"github.com/z-ray/log"
 	"github.com/gorilla/websocket"
 	"sync"
 	"sort"
@@ -43,6 +42,7 @@ func (this *Binance) createDataWsConn(symbols []string) {
 	url := fmt.Sprintf("wss://fstream.binance.com/stream?streams=%s", strings.Join(streams, "/"))
 	ws := NewWsConn(url)
 	ws.SetErrorHandler(this.errorHandle)
+	ws.SetLogger(this.logger)
 	ws.HeartbeatEx(func() (int, string) {return websocket.PongMessage, "pong"}, 20*time.Second)
 	ws.ReConnect()
 	ws.ReceiveMessageEx(func(isBin bool, msg []byte) {
@@ -55,7 +55,7 @@ func (this *Binance) createDataWsConn(symbols []string) {
 		}
 		err := json.Unmarshal(msg, &data)
 		if err != nil {
-			log.Print(err)
+			this.log().Warn("decode websocket message failed", "err", err)
 			return
 		}
 
@@ -251,7 +251,7 @@ func (this *DepthManager) Feed(du *DepthUpdate) *DepthDecimal {
 		fallthrough
 	case DmStateNormal:
 		if this.lastU > 0 && this.lastU != du.PrevU {
-			println("missing packets")
+			this.ba.log().Warn("depth update missing packets, resync", "pair", this.pair, "last_u", this.lastU, "pu", du.PrevU)
 			this.lastU = 0
 			this.lastUpdateId = 0
 			this.SetState(DmStateInit)
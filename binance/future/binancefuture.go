@@ -1,11 +1,11 @@
 package binancefuture
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	. "github.com/stephenlyu/GoEx"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -22,15 +22,16 @@ const (
 	TICKER_URI             = "ticker/24hr?symbol=%s"
 	TRADES_URI            = "trades?symbol=%s&limit=1"
 	DEPTH_URI              = "depth?symbol=%s&limit=%d"
+	KLINES_URI             = "klines?"
 	ACCOUNT_URI            = "account?"
 	ORDER_URI              = "order?"
 	UNFINISHED_ORDERS_INFO = "openOrders?"
 )
 
 type Binance struct {
-	accessKey,
-	secretKey          string
+	signer             *SignerRef
 	httpClient         *http.Client
+	symbols            *SymbolMapper
 
 	wsData             *WsConn
 	wsLock             sync.Mutex
@@ -40,54 +41,122 @@ type Binance struct {
 	wsAccountHandleMap map[string]func(*SubAccountDecimal)
 	wsOrderHandleMap   map[string]func([]OrderDecimal)
 	errorHandle        func(error)
+	logger             Logger
 
 	depthManagers 	   map[string]*DepthManager
 }
 
-func (bn *Binance) buildParamsSigned(postForm *url.Values) error {
+/**
+ * 签名请求参数, 返回带API Key的请求头
+ */
+func (bn *Binance) buildParamsSigned(postForm *url.Values) (map[string]string, error) {
 	postForm.Set("recvWindow", "6000000")
-	tonce := strconv.FormatInt(time.Now().UnixNano(), 10)[0:13]
+	tonce := strconv.FormatInt(ServerTime(BINANCE_FUTURE).UnixNano(), 10)[0:13]
 	postForm.Set("timestamp", tonce)
-	payload := postForm.Encode()
-	sign, _ := GetParamHmacSHA256Sign(bn.secretKey, payload)
-	postForm.Set("signature", sign)
-	return nil
+	signature, err := bn.signer.Sign(HMAC_SHA256_HEX, SignMessage(postForm.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	postForm.Set("signature", signature.Sign)
+	return map[string]string{"X-MBX-APIKEY": signature.ApiKey}, nil
+}
+
+// https://binance-docs.github.io/apidocs/futures/cn/#0f3f2d5ee7
+func fetchServerTime(ctx context.Context, client *http.Client) (time.Time, error) {
+	var resp struct {
+		ServerTime int64
+	}
+	err := HttpGet4WithContext(ctx, client, API_V1+"time", nil, &resp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, resp.ServerTime*int64(time.Millisecond)), nil
+}
+
+func init() {
+	RegisterServerClock(NewServerClock(BINANCE_FUTURE, fetchServerTime))
 }
 
 func New(client *http.Client, api_key, secret_key string) *Binance {
-	return &Binance{
-		accessKey: api_key,
-		secretKey: secret_key,
+	return NewWithSigner(client, NewStaticSigner(api_key, secret_key, ""))
+}
+
+/**
+ * 使用自定义签名器创建实例, 密钥可以来自环境变量、加密文件或凭证代理
+ */
+func NewWithSigner(client *http.Client, signer Signer) *Binance {
+	bn := &Binance{
+		signer:     NewSignerRef(signer),
 		httpClient: client}
+	bn.symbols = NewSymbolMapper(BINANCE_FUTURE, NewInstrumentCatalog(BINANCE_FUTURE, bn))
+	SetServerClockHttpClient(BINANCE_FUTURE, client)
+	return bn
+}
+
+/**
+ * 替换签名器, 用于运行时轮换密钥
+ */
+func (bn *Binance) SetSigner(signer Signer) {
+	bn.signer.Set(signer)
 }
 
 func (bn *Binance) GetExchangeName() string {
 	return BINANCE
 }
 
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (bn *Binance) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	bn.logger = logger
+}
+
+func (bn *Binance) log() Logger {
+	return ExchangeLogger(bn.logger, BINANCE)
+}
+
 func (bn *Binance) GetExchangeInfo() (*Exchange, error) {
 	tickerUri := API_V1 + EXCHANGE_INFO_URI
 	var exchange *Exchange
 	err := HttpGet4(bn.httpClient, tickerUri, nil, &exchange)
 
 	if err != nil {
-		log.Println("GetExchangeInfo error:", err)
+		bn.log().Warn("get exchange info failed", "err", err)
 		return nil, err
 	}
 
 	if exchange.Code != 0 {
-		return nil, fmt.Errorf("error_code: %d", exchange.Code)
+		return nil, binanceFutureError(exchange.Code, exchange.Msg)
 	}
 
 	return exchange, nil
 }
 
+func (bn *Binance) GetInstruments() ([]Instrument, error) {
+	exchange, err := bn.GetExchangeInfo()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(exchange.Symbols))
+	for i := range exchange.Symbols {
+		ret[i] = exchange.Symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (bn *Binance) GetTicker(currency CurrencyPair) (*TickerDecimal, error) {
-	currency2 := bn.adaptCurrencyPair(currency)
-	tickerUri := API_V1 + fmt.Sprintf(TICKER_URI, currency2.ToSymbol(""))
+	symbol, err := bn.pairSymbol(currency)
+	if err != nil {
+		return nil, err
+	}
+	tickerUri := API_V1 + fmt.Sprintf(TICKER_URI, symbol)
 
 	var resp struct {
 		Code int
+		Msg string
 		CloseTime int64
 		LastPrice decimal.Decimal
 		LowPrice decimal.Decimal
@@ -95,15 +164,15 @@ func (bn *Binance) GetTicker(currency CurrencyPair) (*TickerDecimal, error) {
 		Volume decimal.Decimal
 	}
 
-	err := HttpGet4(bn.httpClient, tickerUri, nil, &resp)
+	err = HttpGet4(bn.httpClient, tickerUri, nil, &resp)
 
 	if err != nil {
-		log.Println("GetTicker error:", err)
+		bn.log().Warn("get ticker failed", "pair", currency, "err", err)
 		return nil, err
 	}
 
 	if resp.Code != 0 {
-		return nil, fmt.Errorf("error_code: %d", resp.Code)
+		return nil, binanceFutureError(resp.Code, resp.Msg)
 	}
 
 	t := new(TickerDecimal)
@@ -120,15 +189,18 @@ func (bn *Binance) GetDepthInternal(size int, currencyPair CurrencyPair) (*Depth
 	if size < 5 {
 		size = 5
 	}
-	currencyPair2 := bn.adaptCurrencyPair(currencyPair)
+	symbol, err := bn.pairSymbol(currencyPair)
+	if err != nil {
+		return nil, err
+	}
 
-	apiUrl := fmt.Sprintf(API_V1 + DEPTH_URI, currencyPair2.ToSymbol(""), size)
+	apiUrl := fmt.Sprintf(API_V1 + DEPTH_URI, symbol, size)
 
 	var data DepthData
 
-	err := HttpGet4(bn.httpClient, apiUrl, nil, &data)
+	err = HttpGet4(bn.httpClient, apiUrl, nil, &data)
 	if err != nil {
-		log.Println("GetDepth error:", err)
+		bn.log().Warn("get depth failed", "pair", currencyPair, "err", err)
 		return nil, err
 	}
 	return &data, nil
@@ -141,7 +213,7 @@ func (bn *Binance) GetDepth(size int, currencyPair CurrencyPair) (*DepthDecimal,
 	}
 
 	if data.Code != 0 {
-		return nil, fmt.Errorf("error_code: %d", data.Code)
+		return nil, binanceFutureError(data.Code, data.Msg)
 	}
 
 	depth := new(DepthDecimal)
@@ -162,7 +234,6 @@ func (bn *Binance) GetDepth(size int, currencyPair CurrencyPair) (*DepthDecimal,
 
 func (bn *Binance) GetTrades(currencyPair CurrencyPair) ([]TradeDecimal, error) {
 	url := fmt.Sprintf(API_V1 + TRADES_URI, currencyPair.ToSymbol(""))
-	println(url)
 	var data []struct {
 		Qty decimal.Decimal
 		Price  decimal.Decimal
@@ -173,7 +244,7 @@ func (bn *Binance) GetTrades(currencyPair CurrencyPair) ([]TradeDecimal, error)
 
 	err := HttpGet4(bn.httpClient, url, nil, &data)
 	if err != nil {
-		log.Println("GetTrades error:", err)
+		bn.log().Warn("get trades failed", "pair", currencyPair, "err", err)
 		return nil, err
 	}
 
@@ -195,11 +266,74 @@ func (bn *Binance) GetTrades(currencyPair CurrencyPair) ([]TradeDecimal, error)
 	return trades, nil
 }
 
+var klineIntervals = map[int]string{
+	KLINE_PERIOD_1MIN:   "1m",
+	KLINE_PERIOD_5MIN:   "5m",
+	KLINE_PERIOD_15MIN:  "15m",
+	KLINE_PERIOD_30MIN:  "30m",
+	KLINE_PERIOD_60MIN:  "1h",
+	KLINE_PERIOD_4H:     "4h",
+	KLINE_PERIOD_1DAY:   "1d",
+	KLINE_PERIOD_1WEEK:  "1w",
+	KLINE_PERIOD_1MONTH: "1M",
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线
+ */
+func (bn *Binance) GetKlineRecordsDecimal(currencyPair CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	interval, err := KlinePeriodCode(klineIntervals, period)
+	if err != nil {
+		return nil, err
+	}
+	symbol := currencyPair.ToSymbol("")
+
+	return GetAllKlineDecimals(int64(since), size, 1500, func(q PageQuery) ([]KlineDecimal, error) {
+		params := url.Values{}
+		params.Set("symbol", symbol)
+		params.Set("interval", interval)
+		params.Set("limit", strconv.Itoa(q.Limit))
+		if q.From > 0 {
+			params.Set("startTime", strconv.FormatInt(q.From, 10))
+		} else if q.To > 0 {
+			params.Set("endTime", strconv.FormatInt(q.To, 10))
+		}
+
+		var data [][]interface{}
+		err := HttpGet4(bn.httpClient, API_V1+KLINES_URI+params.Encode(), nil, &data)
+		if err != nil {
+			bn.log().Warn("get klines failed", "pair", currencyPair, "err", err)
+			return nil, err
+		}
+
+		// [open time, open, high, low, close, volume, ...]
+		klines := make([]KlineDecimal, 0, len(data))
+		for _, r := range data {
+			if len(r) < 6 {
+				continue
+			}
+			klines = append(klines, KlineDecimal{
+				Pair:      currencyPair,
+				Timestamp: ToDecimal(r[0]).IntPart() / 1000,
+				Open:      ToDecimal(r[1]),
+				High:      ToDecimal(r[2]),
+				Low:       ToDecimal(r[3]),
+				Close:     ToDecimal(r[4]),
+				Vol:       ToDecimal(r[5]),
+			})
+		}
+		return klines, nil
+	})
+}
+
 func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType, orderSide string) (*Order, error) {
-	pair = bn.adaptCurrencyPair(pair)
+	symbol, err := bn.pairSymbol(pair)
+	if err != nil {
+		return nil, err
+	}
 	path := API_V1 + ORDER_URI
 	params := url.Values{}
-	params.Set("symbol", pair.ToSymbol(""))
+	params.Set("symbol", symbol)
 	params.Set("side", orderSide)
 	params.Set("type", orderType)
 
@@ -212,10 +346,13 @@ func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType
 		params.Set("price", price)
 	}
 
-	bn.buildParamsSigned(&params)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := HttpPostForm2(bn.httpClient, path, params,
-		map[string]string{"X-MBX-APIKEY": bn.accessKey})
+		header)
 	//log.Println("resp:", string(resp), "err:", err)
 	if err != nil {
 		return nil, err
@@ -224,10 +361,15 @@ func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType
 	respmap := make(map[string]interface{})
 	err = json.Unmarshal(resp, &respmap)
 	if err != nil {
-		log.Println(string(resp))
+		bn.log().Warn("decode response failed", "err", err, "body", string(resp))
 		return nil, err
 	}
 
+	if code, isok := respmap["code"]; isok {
+		msg, _ := respmap["msg"].(string)
+		return nil, binanceFutureError(code, msg)
+	}
+
 	orderId := ToInt(respmap["orderId"])
 	if orderId <= 0 {
 		return nil, errors.New(string(resp))
@@ -253,16 +395,20 @@ func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType
 
 func (bn *Binance) GetAccount() (*Account, error) {
 	params := url.Values{}
-	bn.buildParamsSigned(&params)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
 	path := API_V1 + ACCOUNT_URI + params.Encode()
-	respmap, err := HttpGet2(bn.httpClient, path, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	respmap, err := HttpGet2(bn.httpClient, path, header)
 	if err != nil {
-		log.Println(err)
+		bn.log().Warn("get account failed", "err", err)
 		return nil, err
 	}
 	//log.Println("respmap:", respmap)
-	if _, isok := respmap["code"]; isok == true {
-		return nil, errors.New(respmap["msg"].(string))
+	if code, isok := respmap["code"]; isok == true {
+		msg, _ := respmap["msg"].(string)
+		return nil, binanceFutureError(code, msg)
 	}
 	acc := Account{}
 	acc.Exchange = bn.GetExchangeName()
@@ -272,7 +418,7 @@ func (bn *Binance) GetAccount() (*Account, error) {
 	for _, v := range balances {
 		//log.Println(v)
 		vv := v.(map[string]interface{})
-		currency := NewCurrency(vv["asset"].(string), "").AdaptBccToBch()
+		currency := bn.symbols.CanonicalCurrency(NewCurrency(vv["asset"].(string), ""))
 		acc.SubAccounts[currency] = SubAccount{
 			Currency:     currency,
 			Amount:       ToFloat64(vv["free"]),
@@ -300,15 +446,21 @@ func (bn *Binance) MarketSell(amount, price string, currencyPair CurrencyPair) (
 }
 
 func (bn *Binance) CancelOrder(orderId string, currencyPair CurrencyPair) (bool, error) {
-	currencyPair = bn.adaptCurrencyPair(currencyPair)
+	symbol, err := bn.pairSymbol(currencyPair)
+	if err != nil {
+		return false, err
+	}
 	path := API_V1 + ORDER_URI
 	params := url.Values{}
-	params.Set("symbol", currencyPair.ToSymbol(""))
+	params.Set("symbol", symbol)
 	params.Set("orderId", orderId)
 
-	bn.buildParamsSigned(&params)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return false, err
+	}
 
-	resp, err := HttpDeleteForm(bn.httpClient, path, params, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	resp, err := HttpDeleteForm(bn.httpClient, path, params, header)
 
 	//log.Println("resp:", string(resp), "err:", err)
 	if err != nil {
@@ -318,10 +470,15 @@ func (bn *Binance) CancelOrder(orderId string, currencyPair CurrencyPair) (bool,
 	respmap := make(map[string]interface{})
 	err = json.Unmarshal(resp, &respmap)
 	if err != nil {
-		log.Println(string(resp))
+		bn.log().Warn("decode response failed", "err", err, "body", string(resp))
 		return false, err
 	}
 
+	if code, isok := respmap["code"]; isok {
+		msg, _ := respmap["msg"].(string)
+		return false, binanceFutureError(code, msg)
+	}
+
 	orderIdCanceled := ToInt(respmap["orderId"])
 	if orderIdCanceled <= 0 {
 		return false, errors.New(string(resp))
@@ -331,18 +488,24 @@ func (bn *Binance) CancelOrder(orderId string, currencyPair CurrencyPair) (bool,
 }
 
 func (bn *Binance) GetOneOrder(orderId string, currencyPair CurrencyPair) (*Order, error) {
+	symbol, err := bn.pairSymbol(currencyPair)
+	if err != nil {
+		return nil, err
+	}
 	params := url.Values{}
-	currencyPair = bn.adaptCurrencyPair(currencyPair)
-	params.Set("symbol", currencyPair.ToSymbol(""))
+	params.Set("symbol", symbol)
 	if orderId != "" {
 		params.Set("orderId", orderId)
 	}
 	params.Set("orderId", orderId)
 
-	bn.buildParamsSigned(&params)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
 	path := API_V1 + ORDER_URI + params.Encode()
 
-	respmap, err := HttpGet2(bn.httpClient, path, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	respmap, err := HttpGet2(bn.httpClient, path, header)
 	//log.Println(respmap)
 	if err != nil {
 		return nil, err
@@ -383,14 +546,20 @@ func (bn *Binance) GetOneOrder(orderId string, currencyPair CurrencyPair) (*Orde
 }
 
 func (bn *Binance) GetUnfinishOrders(currencyPair CurrencyPair) ([]Order, error) {
+	symbol, err := bn.pairSymbol(currencyPair)
+	if err != nil {
+		return nil, err
+	}
 	params := url.Values{}
-	currencyPair = bn.adaptCurrencyPair(currencyPair)
-	params.Set("symbol", currencyPair.ToSymbol(""))
+	params.Set("symbol", symbol)
 
-	bn.buildParamsSigned(&params)
+	header, err := bn.buildParamsSigned(&params)
+	if err != nil {
+		return nil, err
+	}
 	path := API_V1 + UNFINISHED_ORDERS_INFO + params.Encode()
 
-	respmap, err := HttpGet3(bn.httpClient, path, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	respmap, err := HttpGet3(bn.httpClient, path, header)
 	//log.Println("respmap", respmap, "err", err)
 	if err != nil {
 		return nil, err
@@ -418,6 +587,9 @@ func (bn *Binance) GetUnfinishOrders(currencyPair CurrencyPair) ([]Order, error)
 	return orders, nil
 }
 
-func (ba *Binance) adaptCurrencyPair(pair CurrencyPair) CurrencyPair {
-	return pair.AdaptBchToBcc().AdaptUsdToUsdt()
+/**
+ * 合约代码从交易品种列表中查找, 没有USD计价的合约时使用USDT计价
+ */
+func (bn *Binance) pairSymbol(pair CurrencyPair) (string, error) {
+	return bn.symbols.ToNative(pair, INSTRUMENT_SWAP)
 }
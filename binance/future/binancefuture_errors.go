@@ -0,0 +1,54 @@
+package binancefuture
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/stephenlyu/GoEx"
+)
+
+// https://binance-docs.github.io/apidocs/futures/cn/#45fa4e00db, 与现货共用-1xxx通用错误码, -2xxx/-4xxx/-5xxx为合约的拒单原因
+var binanceFutureErrorCodes = ErrorCodes{
+	"-1001": ERR_NETWORK,
+	"-1003": ERR_RATE_LIMITED,
+	"-1015": ERR_RATE_LIMITED,
+	"-1016": ERR_MAINTENANCE,
+	"-1021": ERR_NONCE,
+	"-1022": ERR_AUTH,
+	"-1100": ERR_INVALID_PARAM,
+	"-1102": ERR_INVALID_PARAM,
+	"-1121": ERR_INVALID_SYMBOL,
+	"-2011": ERR_ORDER_NOT_FOUND,
+	"-2013": ERR_ORDER_NOT_FOUND,
+	"-2014": ERR_AUTH,
+	"-2015": ERR_AUTH,
+	"-2018": ERR_INSUFFICIENT_BALANCE,
+	"-2019": ERR_INSUFFICIENT_BALANCE,
+	"-4003": ERR_INVALID_PARAM,
+	"-4014": ERR_INVALID_PARAM,
+	"-4023": ERR_INVALID_PARAM,
+	"-5022": ERR_POST_ONLY_REJECTED,
+}
+
+func binanceFutureError(code interface{}, msg string) error {
+	c := fmt.Sprint(code)
+	if f, ok := code.(float64); ok {
+		c = fmt.Sprint(int64(f))
+	}
+	return binanceFutureErrorCodes.NewError(BINANCE, c, msg)
+}
+
+func parseBinanceFutureError(statusCode int, body []byte) *ExchangeError {
+	var resp struct {
+		Code int64
+		Msg  string
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Code == 0 {
+		return nil
+	}
+	return binanceFutureErrorCodes.NewError(BINANCE, fmt.Sprint(resp.Code), resp.Msg)
+}
+
+func init() {
+	RegisterErrorParser(BINANCE, parseBinanceFutureError, "fapi.binance.com")
+}
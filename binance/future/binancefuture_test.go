@@ -1,6 +1,7 @@
 package binancefuture
 
 import (
+	"errors"
 	"github.com/stephenlyu/GoEx"
 	"net/http"
 	"testing"
@@ -58,3 +59,14 @@ func TestBinance_GetUnfinishOrders(t *testing.T) {
 	orders, err := ba.GetUnfinishOrders(goex.ETH_BTC)
 	t.Log(orders, err)
 }
+
+func TestParseBinanceFutureError(t *testing.T) {
+	err := parseBinanceFutureError(400, []byte(`{"code":-2019,"msg":"Margin is insufficient."}`))
+	if !errors.Is(err, goex.ERR_INSUFFICIENT_BALANCE) {
+		t.Error(err.Category)
+	}
+	err = parseBinanceFutureError(400, []byte(`{"code":-5022,"msg":"Due to the order could not be executed as maker, the Post Only order will be rejected."}`))
+	if !errors.Is(err, goex.ERR_POST_ONLY_REJECTED) {
+		t.Error(err.Category)
+	}
+}
@@ -1,6 +1,9 @@
 package binancefuture
 
-import "github.com/shopspring/decimal"
+import (
+	"github.com/shopspring/decimal"
+	. "github.com/stephenlyu/GoEx"
+)
 
 type RateLimit struct {
 	Interval string
@@ -37,6 +40,38 @@ type Symbol struct {
 	TimeInForce []string
 }
 
+func (this *Symbol) ToInstrument() Instrument {
+	inst := Instrument{
+		Exchange: BINANCE,
+		Symbol: this.Symbol,
+		Pair: NewCurrencyPair(NewCurrency(this.BaseAsset, ""), NewCurrency(this.QuoteAsset, "")),
+		Type: INSTRUMENT_SWAP,
+		PricePrecision: int32(this.PricePrecision),
+		AmountPrecision: int32(this.QuantityPrecision),
+		ContractValue: decimal.New(1, 0),
+		Multiplier: decimal.New(1, 0),
+		SettleCurrency: NewCurrency(this.QuoteAsset, ""),
+	}
+	for _, filter := range this.Filters {
+		switch filter.FilterType {
+		case "PRICE_FILTER":
+			inst.TickSize = filter.TickSize
+		case "LOT_SIZE":
+			inst.LotSize = filter.StepSize
+			inst.MinQty = filter.MinQty
+		}
+	}
+	switch this.Status {
+	case "TRADING":
+		inst.Status = INSTRUMENT_STATUS_TRADING
+	case "":
+	default:
+		inst.Status = INSTRUMENT_STATUS_SUSPENDED
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 type Exchange struct {
 	Code            int
 	Msg             string
@@ -50,6 +85,7 @@ type Exchange struct {
 
 type DepthData struct {
 	Code int
+	Msg string
 	LastUpdateId int64
 	Asks [][]decimal.Decimal
 	Bids [][]decimal.Decimal
@@ -0,0 +1,51 @@
+package bitfinex
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/stephenlyu/GoEx"
+)
+
+// v1接口只返回错误信息{"message":"..."}, v2接口返回["error",10100,"apikey: invalid"]
+var bitfinexV2ErrorCodes = ErrorCodes{
+	"10100": ERR_AUTH,
+	"10114": ERR_NONCE,
+	"11010": ERR_RATE_LIMITED,
+	"20060": ERR_MAINTENANCE,
+}
+
+func bitfinexError(message string) error {
+	return NewExchangeError(BITFINEX, ClassifyMessage(message), "", message)
+}
+
+func parseBitfinexError(statusCode int, body []byte) *ExchangeError {
+	var v1 struct {
+		Message string
+		Error   string
+	}
+	if json.Unmarshal(body, &v1) == nil {
+		msg := v1.Message
+		if msg == "" {
+			msg = v1.Error
+		}
+		if msg == "" {
+			return nil
+		}
+		return NewExchangeError(BITFINEX, ClassifyMessage(msg), "", msg)
+	}
+
+	var v2 []interface{}
+	if json.Unmarshal(body, &v2) != nil || len(v2) < 3 || v2[0] != "error" {
+		return nil
+	}
+	code := fmt.Sprint(v2[1])
+	if f, ok := v2[1].(float64); ok {
+		code = fmt.Sprint(int64(f))
+	}
+	return bitfinexV2ErrorCodes.NewError(BITFINEX, code, fmt.Sprint(v2[2]))
+}
+
+func init() {
+	RegisterErrorParser(BITFINEX, parseBitfinexError, "api.bitfinex.com")
+}
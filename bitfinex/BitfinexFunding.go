@@ -0,0 +1,271 @@
+package bitfinex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	. "github.com/stephenlyu/GoEx"
+)
+
+// 充提币方式, 需要标签的币种(XRP/EOS等)充值地址中address为标签, address_pool为地址
+var depositMethods = map[string]string{
+	"BTC":  "bitcoin",
+	"LTC":  "litecoin",
+	"ETH":  "ethereum",
+	"ETC":  "ethereumc",
+	"ZEC":  "zcash",
+	"XMR":  "monero",
+	"IOTA": "iota",
+	"BCH":  "bcash",
+	"USDT": "tetheruso",
+	"EOS":  "eos",
+	"XRP":  "ripple",
+	"DASH": "dash",
+	"NEO":  "neo",
+}
+
+var bitfinexWallets = map[WalletType]string{
+	WALLET_SPOT:    "exchange",
+	WALLET_MARGIN:  "trading",
+	WALLET_FUNDING: "deposit",
+}
+
+func (bfx *Bitfinex) depositMethod(currency Currency) (string, error) {
+	method, ok := depositMethods[strings.ToUpper(currency.Symbol)]
+	if !ok {
+		return "", fmt.Errorf("unknown deposit method of %s", currency.Symbol)
+	}
+	return method, nil
+}
+
+func (bfx *Bitfinex) GetDepositAddress(currency Currency) ([]DepositAddress, error) {
+	method, err := bfx.depositMethod(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{
+		"method":      method,
+		"wallet_name": "exchange",
+		"renew":       0,
+	}
+
+	var resp struct {
+		Result      string
+		Method      string
+		Currency    string
+		Address     string
+		AddressPool string `json:"address_pool"`
+		Message     string
+	}
+	err = bfx.doAuthenticatedRequest("POST", "deposit/new", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Result != "success" {
+		return nil, bitfinexError(resp.Message)
+	}
+
+	if resp.AddressPool != "" {
+		return []DepositAddress{{Currency: currency, Address: resp.AddressPool, Tag: resp.Address}}, nil
+	}
+	return []DepositAddress{{Currency: currency, Address: resp.Address}}, nil
+}
+
+type Movement struct {
+	Id               int64
+	Txid             string
+	Currency         string
+	Method           string
+	Type             string
+	Amount           decimal.Decimal
+	Description      string
+	Address          string
+	Status           string
+	Timestamp        decimal.Decimal
+	TimestampCreated decimal.Decimal `json:"timestamp_created"`
+	Fee              decimal.Decimal
+}
+
+func (this *Movement) ToFundingRecord() *FundingRecord {
+	var status FundingStatus
+	switch this.Status {
+	case "COMPLETED":
+		status = FUNDING_SUCCESS
+	case "CANCELED":
+		status = FUNDING_CANCELED
+	case "FAILED":
+		status = FUNDING_FAILED
+	case "PROCESSING", "SENDING", "UNCONFIRMED":
+		status = FUNDING_PROCESSING
+	default:
+		status = FUNDING_PENDING
+	}
+
+	_type := FUNDING_DEPOSIT
+	if this.Type == "WITHDRAWAL" {
+		_type = FUNDING_WITHDRAW
+	}
+
+	ts := this.TimestampCreated
+	if ts.IsZero() {
+		ts = this.Timestamp
+	}
+
+	return &FundingRecord{
+		Id:        fmt.Sprint(this.Id),
+		Type:      _type,
+		Currency:  NewCurrency(strings.ToUpper(this.Currency), ""),
+		Amount:    this.Amount.Abs(),
+		Fee:       this.Fee.Abs(),
+		Address:   this.Address,
+		TxId:      this.Txid,
+		Status:    status,
+		RawStatus: this.Status,
+		Timestamp: ts.Shift(3).IntPart(),
+	}
+}
+
+func (bfx *Bitfinex) getMovements(currency Currency, _type FundingType) ([]FundingRecord, error) {
+	params := map[string]interface{}{
+		"currency": strings.ToUpper(currency.Symbol),
+		"limit":    100,
+	}
+
+	var resp []Movement
+	err := bfx.doAuthenticatedRequest("POST", "history/movements", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []FundingRecord
+	for i := range resp {
+		r := resp[i].ToFundingRecord()
+		if r.Type == _type {
+			ret = append(ret, *r)
+		}
+	}
+	return ret, nil
+}
+
+func (bfx *Bitfinex) GetDeposits(currency Currency) ([]FundingRecord, error) {
+	return bfx.getMovements(currency, FUNDING_DEPOSIT)
+}
+
+func (bfx *Bitfinex) GetWithdrawals(currency Currency) ([]FundingRecord, error) {
+	return bfx.getMovements(currency, FUNDING_WITHDRAW)
+}
+
+func (bfx *Bitfinex) GetWithdrawal(currency Currency, withdrawId string) (*FundingRecord, error) {
+	records, err := bfx.GetWithdrawals(currency)
+	if err != nil {
+		return nil, err
+	}
+	return FindFundingRecord(records, withdrawId)
+}
+
+/**
+ * 从交易账户提币, Bitfinex自动扣除提币手续费, 忽略param.Fee. 不支持param.Chain
+ */
+func (bfx *Bitfinex) CreateWithdrawal(param WithdrawParam) (string, error) {
+	if param.Chain != "" {
+		return "", EX_ERR_NOT_SUPPORTED
+	}
+	method, err := bfx.depositMethod(param.Currency)
+	if err != nil {
+		return "", err
+	}
+
+	params := map[string]interface{}{
+		"withdraw_type":  method,
+		"walletselected": "exchange",
+		"amount":         param.Amount.String(),
+		"address":        param.Address,
+	}
+	if param.Tag != "" {
+		params["payment_id"] = param.Tag
+	}
+
+	var resp []struct {
+		Status       string
+		Message      string
+		WithdrawalId decimal.Decimal `json:"withdrawal_id"`
+	}
+	err = bfx.doAuthenticatedRequest("POST", "withdraw", params, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp) == 0 {
+		return "", errors.New("empty response")
+	}
+
+	if resp[0].Status != "success" {
+		return "", bitfinexError(resp[0].Message)
+	}
+
+	return resp[0].WithdrawalId.String(), nil
+}
+
+func (bfx *Bitfinex) GetWithdrawalFees(currency Currency) ([]WithdrawFeeDecimal, error) {
+	var resp struct {
+		Withdraw map[string]decimal.Decimal
+	}
+	err := bfx.doAuthenticatedRequest("POST", "account_fees", map[string]interface{}{}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []WithdrawFeeDecimal
+	for symbol, fee := range resp.Withdraw {
+		if currency != UNKNOWN && !strings.EqualFold(symbol, currency.Symbol) {
+			continue
+		}
+		ret = append(ret, WithdrawFeeDecimal{
+			Currency: NewCurrency(symbol, ""),
+			MinFee:   fee,
+			MaxFee:   fee,
+		})
+	}
+	return ret, nil
+}
+
+/**
+ * 支持交易账户(spot)、保证金账户(margin)和资金账户(funding)之间的划转, Bitfinex不返回划转ID
+ */
+func (bfx *Bitfinex) TransferBetweenWallets(currency Currency, amount decimal.Decimal, from, to WalletType) (string, error) {
+	fromWallet, ok1 := bitfinexWallets[from]
+	toWallet, ok2 := bitfinexWallets[to]
+	if !ok1 || !ok2 {
+		return "", EX_ERR_NOT_SUPPORTED
+	}
+
+	params := map[string]interface{}{
+		"amount":     amount.String(),
+		"currency":   strings.ToUpper(currency.Symbol),
+		"walletfrom": fromWallet,
+		"walletto":   toWallet,
+	}
+
+	var resp []struct {
+		Status  string
+		Message string
+	}
+	err := bfx.doAuthenticatedRequest("POST", "transfer", params, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp) == 0 {
+		return "", errors.New("empty response")
+	}
+
+	if resp[0].Status != "success" {
+		return "", bitfinexError(resp[0].Message)
+	}
+
+	return "", nil
+}
@@ -3,45 +3,115 @@ package bitfinex
 import (
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	. "github.com/stephenlyu/GoEx"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type Bitfinex struct {
 	httpClient *http.Client
 	accessKey,
 	secretKey string
+	symbols *SymbolMapper
 }
 
 const (
 	BASE_URL = "https://api.bitfinex.com/v1"
+
+	// 币种代码到通用名称的映射, 如DSH -> DASH, UST -> USDT
+	CURRENCY_MAP_URL = "https://api-pub.bitfinex.com/v2/conf/pub:map:currency:sym"
 )
 
 func New(client *http.Client, accessKey, secretKey string) *Bitfinex {
-	return &Bitfinex{client, accessKey, secretKey}
+	bfx := &Bitfinex{httpClient: client, accessKey: accessKey, secretKey: secretKey}
+	bfx.symbols = NewSymbolMapper(BITFINEX, NewInstrumentCatalog(BITFINEX, bfx))
+	return bfx
+}
+
+func (bfx *Bitfinex) SymbolMapper() *SymbolMapper {
+	return bfx.symbols
 }
 
 func (bfx *Bitfinex) GetExchangeName() string {
 	return BITFINEX
 }
 
+// price_precision是有效数字位数而不是小数位数, 不转换为PricePrecision
+func (bfx *Bitfinex) GetInstruments() ([]Instrument, error) {
+	if err := bfx.loadCurrencyAliases(); err != nil {
+		return nil, err
+	}
+
+	var resp []struct {
+		Pair             string
+		MinimumOrderSize decimal.Decimal `json:"minimum_order_size"`
+	}
+	err := HttpGet4(bfx.httpClient, BASE_URL+"/symbols_details", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Instrument, 0, len(resp))
+	for _, r := range resp {
+		var pair CurrencyPair
+		if parts := strings.Split(r.Pair, ":"); len(parts) == 2 {
+			pair = NewCurrencyPair(NewCurrency(strings.ToUpper(parts[0]), ""), NewCurrency(strings.ToUpper(parts[1]), ""))
+		} else if len(r.Pair) == 6 {
+			pair = NewCurrencyPair(NewCurrency(strings.ToUpper(r.Pair[:3]), ""), NewCurrency(strings.ToUpper(r.Pair[3:]), ""))
+		} else {
+			continue
+		}
+		ret = append(ret, Instrument{
+			Exchange: BITFINEX,
+			Symbol:   r.Pair,
+			Pair:     pair,
+			Type:     INSTRUMENT_SPOT,
+			MinQty:   r.MinimumOrderSize,
+		})
+	}
+	return ret, nil
+}
+
+/**
+ * 从交易所加载币种别名, 交易对代码使用DSH、UST等缩写
+ */
+func (bfx *Bitfinex) loadCurrencyAliases() error {
+	var resp [][][]string
+	err := HttpGet4(bfx.httpClient, CURRENCY_MAP_URL, nil, &resp)
+	if err != nil {
+		return err
+	}
+	if len(resp) == 0 {
+		return nil
+	}
+	for _, alias := range resp[0] {
+		if len(alias) == 2 {
+			bfx.symbols.AddAssetAlias(alias[0], CanonicalCurrency(NewCurrency(alias[1], "")).Symbol)
+		}
+	}
+	return nil
+}
+
 func (bfx *Bitfinex) GetTicker(currencyPair CurrencyPair) (*Ticker, error) {
 	//pubticker
-	currencyPair = bfx.adaptCurrencyPair(currencyPair)
+	symbol, err := bfx.symbols.ToNative(currencyPair, INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, err
+	}
 
-	apiUrl := fmt.Sprintf("%s/pubticker/%s", BASE_URL, strings.ToLower(currencyPair.ToSymbol("")))
+	apiUrl := fmt.Sprintf("%s/pubticker/%s", BASE_URL, symbol)
 	resp, err := HttpGet(bfx.httpClient, apiUrl)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp["error"] != nil {
-		return nil, errors.New(resp["error"].(string))
+		return nil, bitfinexError(resp["error"].(string))
 	}
 
 	//fmt.Println(resp)
@@ -57,7 +127,11 @@ func (bfx *Bitfinex) GetTicker(currencyPair CurrencyPair) (*Ticker, error) {
 }
 
 func (bfx *Bitfinex) GetDepth(size int, currencyPair CurrencyPair) (*Depth, error) {
-	apiUrl := fmt.Sprintf("%s/book/%s?limit_bids=%d&limit_asks=%d", BASE_URL, bfx.currencyPairToSymbol(currencyPair), size, size)
+	symbol, err := bfx.symbols.ToNative(currencyPair, INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, err
+	}
+	apiUrl := fmt.Sprintf("%s/book/%s?limit_bids=%d&limit_asks=%d", BASE_URL, symbol, size, size)
 	resp, err := HttpGet(bfx.httpClient, apiUrl)
 	if err != nil {
 		return nil, err
@@ -151,9 +225,14 @@ func (bfx *Bitfinex) GetAccount() (*Account, error) {
 }
 
 func (bfx *Bitfinex) placeOrder(orderType, side, amount, price string, pair CurrencyPair) (*Order, error) {
+	symbol, err := bfx.symbols.ToNative(pair, INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, err
+	}
+
 	path := "order/new"
 	params := map[string]interface{}{
-		"symbol":   bfx.currencyPairToSymbol(pair),
+		"symbol":   symbol,
 		"amount":   amount,
 		"price":    price,
 		"side":     side,
@@ -161,7 +240,7 @@ func (bfx *Bitfinex) placeOrder(orderType, side, amount, price string, pair Curr
 		"exchange": "bitfinex"}
 
 	var respmap map[string]interface{}
-	err := bfx.doAuthenticatedRequest("POST", path, params, &respmap)
+	err = bfx.doAuthenticatedRequest("POST", path, params, &respmap)
 	if err != nil {
 		return nil, err
 	}
@@ -220,9 +299,14 @@ func (bfx *Bitfinex) CancelOrder(orderId string, currencyPair CurrencyPair) (boo
 	return respmap["is_cancelled"].(bool), nil
 }
 
-func (bfx *Bitfinex) toOrder(respmap map[string]interface{}) *Order {
+func (bfx *Bitfinex) toOrder(respmap map[string]interface{}) (*Order, error) {
+	inst, err := bfx.symbols.FromNative(respmap["symbol"].(string))
+	if err != nil {
+		return nil, err
+	}
+
 	order := new(Order)
-	order.Currency = bfx.symbolToCurrencyPair(respmap["symbol"].(string))
+	order.Currency = inst.Pair
 	order.OrderID = ToInt(respmap["id"])
 	order.OrderID2 = fmt.Sprint(ToInt(respmap["id"]))
 	order.Amount = ToFloat64(respmap["original_amount"])
@@ -247,7 +331,7 @@ func (bfx *Bitfinex) toOrder(respmap map[string]interface{}) *Order {
 	if respmap["is_cancelled"].(bool) {
 		order.Status = ORDER_CANCEL
 	}
-	return order
+	return order, nil
 }
 
 func (bfx *Bitfinex) GetOneOrder(orderId string, currencyPair CurrencyPair) (*Order, error) {
@@ -257,7 +341,7 @@ func (bfx *Bitfinex) GetOneOrder(orderId string, currencyPair CurrencyPair) (*Or
 	if err != nil {
 		return nil, err
 	}
-	return bfx.toOrder(respmap), nil
+	return bfx.toOrder(respmap)
 }
 
 func (bfx *Bitfinex) GetUnfinishOrders(currencyPair CurrencyPair) ([]Order, error) {
@@ -269,8 +353,11 @@ func (bfx *Bitfinex) GetUnfinishOrders(currencyPair CurrencyPair) ([]Order, erro
 
 	var orders []Order
 	for _, v := range ordersmap {
-		ordermap := v.(map[string]interface{})
-		orders = append(orders, *bfx.toOrder(ordermap))
+		order, err := bfx.toOrder(v.(map[string]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
 	}
 	return orders, nil
 }
@@ -284,8 +371,11 @@ func (bfx *Bitfinex) GetOrderHistory(pageSize int) ([]Order, error) {
 
 	var orders []Order
 	for _, v := range ordersmap {
-		ordermap := v.(map[string]interface{})
-		orders = append(orders, *bfx.toOrder(ordermap))
+		order, err := bfx.toOrder(v.(map[string]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
 	}
 	return orders, nil
 }
@@ -323,47 +413,8 @@ func (bfx *Bitfinex) doAuthenticatedRequest(method, path string, payload map[str
 	return err
 }
 
-func (bfx *Bitfinex) currencyPairToSymbol(currencyPair CurrencyPair) string {
-	return strings.ToUpper(currencyPair.ToSymbol(""))
-}
-
-func (bfx *Bitfinex) symbolToCurrencyPair(symbol string) CurrencyPair {
-	currencyA := strings.ToUpper(symbol[0:3])
-	currencyB := strings.ToUpper(symbol[3:])
-	return NewCurrencyPair(NewCurrency(currencyA, ""), NewCurrency(currencyB, ""))
-}
-
 func (bfx *Bitfinex) adaptTimestamp(timestamp string) int {
 	times := strings.Split(timestamp, ".")
 	intTime, _ := strconv.Atoi(times[0])
 	return intTime
 }
-
-func (bfx *Bitfinex) adaptCurrencyPair(pair CurrencyPair) CurrencyPair {
-	var currencyA Currency
-	var currencyB Currency
-
-	DASH := NewCurrency("DASH", "")
-	DSH := NewCurrency("DSH", "")
-	QTM := NewCurrency("QTM", "")
-	IOTA := NewCurrency("IOTA", "")
-	IOT := NewCurrency("IOT", "")
-
-	if pair.CurrencyA == DASH {
-		currencyA = DSH
-	} else if pair.CurrencyA == QTUM {
-		currencyA = QTM
-	} else if pair.CurrencyA == IOTA {
-		currencyA = IOT
-	} else {
-		currencyA = pair.CurrencyA
-	}
-
-	if pair.CurrencyB == USDT {
-		currencyB = USD
-	} else {
-		currencyB = pair.CurrencyB
-	}
-
-	return NewCurrencyPair(currencyA, currencyB)
-}
@@ -11,6 +11,8 @@ import (
 
 var bfx = New(http.DefaultClient, "", "")
 
+var _ goex.InstrumentAPI = bfx
+
 type Key struct {
 	ApiKey string 	`json:"api-key"`
 	SecretKey string `json:"secret-key"`
@@ -66,3 +68,17 @@ func TestBitfinex_OrderHistory(t *testing.T) {
 	chk(err)
 	Output(orders)
 }
+
+func TestBitfinex_GetDepositAddress(t *testing.T) {
+	bfx := New(http.DefaultClient, API_KEY, SECRET_KEY)
+	ret, err := bfx.GetDepositAddress(goex.BTC)
+	chk(err)
+	Output(ret)
+}
+
+func TestBitfinex_GetWithdrawals(t *testing.T) {
+	bfx := New(http.DefaultClient, API_KEY, SECRET_KEY)
+	ret, err := bfx.GetWithdrawals(goex.BTC)
+	chk(err)
+	Output(ret)
+}
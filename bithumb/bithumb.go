@@ -278,6 +278,38 @@ func (bit *Bithumb) doAuthenticatedRequest(uri, params string, ret interface{})
 	return err
 }
 
+/**
+ * 交易品种来自ticker/ALL, 只有韩元交易对, 接口不提供精度和最小下单量
+ */
+func (bit *Bithumb) GetInstruments() ([]Instrument, error) {
+	var resp struct {
+		Status string
+		Data   map[string]json.RawMessage
+	}
+	err := HttpGet4(bit.client, baseUrl+"/public/ticker/ALL", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != "0000" {
+		return nil, errors.New(resp.Status)
+	}
+
+	ret := make([]Instrument, 0, len(resp.Data))
+	for symbol := range resp.Data {
+		if symbol == "date" {
+			continue
+		}
+		ret = append(ret, Instrument{
+			Exchange: BITHUMB,
+			Symbol:   symbol,
+			Pair:     NewCurrencyPair(NewCurrency(symbol, ""), KRW),
+			Type:     INSTRUMENT_SPOT,
+			Status:   INSTRUMENT_STATUS_TRADING,
+		})
+	}
+	return ret, nil
+}
+
 func (bit *Bithumb) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	respmap, err := HttpGet(bit.client, fmt.Sprintf("%s/public/ticker/%s", baseUrl, currency.CurrencyA))
 	if err != nil {
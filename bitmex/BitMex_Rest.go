@@ -1,6 +1,7 @@
 package bitmex
 
 import (
+	"context"
 	"net/http"
 	"github.com/stephenlyu/GoEx"
 	"strings"
@@ -24,23 +25,67 @@ const (
 	ORDER_ALL_URL = "/order/all"
 	WALLET_HISTORY_URL = "/user/walletHistory"
 	INSTRUMENT_INDICES_URL = "/instrument/indices"
+	INSTRUMENT_ACTIVE_URL = "/instrument/active"
 )
 
 type BitMexRest struct {
-	apiKey string
-	apiSecretKey string
+	signer *goex.SignerRef
 	client *http.Client
+	logger goex.Logger
 }
 
 func NewBitMexRest(apiKey string, apiSecretKey string) *BitMexRest {
+	return NewBitMexRestWithSigner(goex.NewStaticSigner(apiKey, apiSecretKey, ""))
+}
+
+/**
+ * 使用自定义签名器创建实例, 密钥可以来自环境变量、加密文件或凭证代理
+ */
+func NewBitMexRestWithSigner(signer goex.Signer) *BitMexRest {
 	return &BitMexRest{
-		apiKey: apiKey,
-		apiSecretKey: apiSecretKey,
+		signer: goex.NewSignerRef(signer),
 
 		client: http.DefaultClient,
 	}
 }
 
+/**
+ * 替换签名器, 用于运行时轮换密钥
+ */
+func (bitmex *BitMexRest) SetSigner(signer goex.Signer) {
+	bitmex.signer.Set(signer)
+}
+
+// GET /api/v1返回{"name":"BitMEX API",...,"timestamp":1573185585135}
+func fetchServerTime(ctx context.Context, client *http.Client) (time.Time, error) {
+	var resp struct {
+		Timestamp int64
+	}
+	err := goex.HttpGet4WithContext(ctx, client, BASE_URL, nil, &resp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, resp.Timestamp*int64(time.Millisecond)), nil
+}
+
+func init() {
+	goex.RegisterServerClock(goex.NewServerClock(goex.BITMEX, fetchServerTime))
+}
+
+/**
+ * 设置实例的日志, 为nil时使用goex.DefaultLogger
+ */
+func (bitmex *BitMexRest) SetLogger(logger goex.Logger) {
+	if logger != nil {
+		logger = goex.NewRedactingLogger(logger)
+	}
+	bitmex.logger = logger
+}
+
+func (bitmex *BitMexRest) log() goex.Logger {
+	return goex.ExchangeLogger(bitmex.logger, goex.BITMEX)
+}
+
 func (bitmex *BitMexRest) map2Query(params map[string]string) string {
 	keys := make([]string, len(params))
 	var i int
@@ -61,29 +106,32 @@ func (bitmex *BitMexRest) map2Query(params map[string]string) string {
 	return strings.Join(parts, "&")
 }
 
-func (bitmex *BitMexRest) buildSigHeader(method string, path string, data string) map[string]string {
-	now := time.Now().Unix()
+func (bitmex *BitMexRest) buildSigHeader(method string, path string, data string) (map[string]string, error) {
+	now := goex.ServerTime(goex.BITMEX).Unix()
 	expires := now + 30
-	signature := BuildSignature(bitmex.apiSecretKey, method, ROOT_URL + path, expires, data)
+	signature, err := bitmex.signer.Sign(goex.HMAC_SHA256_HEX, goex.SignMessage(signMessage(method, ROOT_URL + path, expires, data)))
+	if err != nil {
+		return nil, err
+	}
 	return map[string]string{
-		"api-key": bitmex.apiKey,
-		"api-signature": signature,
+		"api-key": signature.ApiKey,
+		"api-signature": signature.Sign,
 		"api-expires": fmt.Sprintf("%d", expires),
 		"Content-Type": "application/x-www-form-urlencoded",
-	}
+	}, nil
 }
 
 func (BitMexRest *BitMexRest) handleRespHeader(header http.Header) {
 	//"x-ratelimit-limit": 300
 	//"x-ratelimit-remaining": 297
 	//"x-ratelimit-reset": 1489791662
+	//限频头由goex.RateLimiter在goex.NewHttpRequestEx中处理, 这里只在额度耗尽时提示
 	if header == nil {
 		return
 	}
-	fmt.Printf("x-ratelimit-limit: %s x-ratelimit-remaining: %s x-ratelimit-reset: %s\n",
-		header.Get("x-ratelimit-limit"),
-		header.Get("x-ratelimit-remaining"),
-		header.Get("x-ratelimit-reset"))
+	if header.Get("x-ratelimit-remaining") == "0" {
+		BitMexRest.log().Warn("rate limit exhausted", "reset", header.Get("x-ratelimit-reset"))
+	}
 }
 
 func (bitmex *BitMexRest) GetTrade(symbol string, reverse bool) (error, []goex.Trade) {
@@ -107,8 +155,7 @@ func (bitmex *BitMexRest) GetTrade(symbol string, reverse bool) (error, []goex.T
 
 	query := bitmex.map2Query(params)
 	query = url.Escape(query)
-	header := bitmex.buildSigHeader("GET", TRADE_URL + "?" + query, "")
-	header = map[string]string{}
+	header := map[string]string{}
 	err, respHeader := goex.HttpGet5(bitmex.client, BASE_URL+TRADE_URL+"?"+ query, header, &data)
 	bitmex.handleRespHeader(respHeader)
 	if err != nil {
@@ -131,6 +178,26 @@ func (bitmex *BitMexRest) GetTrade(symbol string, reverse bool) (error, []goex.T
 	return nil, ret
 }
 
+/**
+ * 返回值顺序与其他方法不同, 用于实现goex.InstrumentAPI
+ */
+func (bitmex *BitMexRest) GetInstruments() ([]goex.Instrument, error) {
+	var data []BitmexInstrument
+	err, respHeader := goex.HttpGet5(bitmex.client, BASE_URL+INSTRUMENT_ACTIVE_URL, map[string]string{}, &data)
+	bitmex.handleRespHeader(respHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]goex.Instrument, 0, len(data))
+	for i := range data {
+		if inst, ok := data[i].ToInstrument(); ok {
+			ret = append(ret, inst)
+		}
+	}
+	return ret, nil
+}
+
 func (bitmex *BitMexRest) GetOrderBook(symbol string) (error, *goex.Depth) {
 	params := map[string]string{"symbol": symbol, "depth": "10"}
 
@@ -171,7 +238,10 @@ func (bitmex *BitMexRest) GetAccount() (error, *goex.FutureAccount) {
 	params := map[string]string{"currency":"XBt"}
 	query := bitmex.map2Query(params)
 	query = url.Escape(query)
-	header := bitmex.buildSigHeader("GET", MARGIN_URL + "?" + query, "")
+	header, err := bitmex.buildSigHeader("GET", MARGIN_URL + "?" + query, "")
+	if err != nil {
+		return err, nil
+	}
 
 	var margin Margin
 
@@ -193,7 +263,10 @@ func (bitmex *BitMexRest) GetPosition(symbol string, count int) (error, []goex.F
 	params := map[string]string{"filter":string(bytes), "count": fmt.Sprintf("%d", count)}
 	query := bitmex.map2Query(params)
 	query = url.Escape(query)
-	header := bitmex.buildSigHeader("GET", POSITION_GET_URL + "?" + query, "")
+	header, err := bitmex.buildSigHeader("GET", POSITION_GET_URL + "?" + query, "")
+	if err != nil {
+		return err, nil
+	}
 	var positions []BitmexPosition
 
 	err, respHeader := goex.HttpGet5(bitmex.client, BASE_URL+POSITION_GET_URL+"?"+query, header, &positions)
@@ -236,7 +309,10 @@ func (bitmex *BitMexRest) PlaceOrder(symbol string, side goex.TradeSide, price f
 	}
 	data := bitmex.map2Query(params)
 	data = url.Escape(data)
-	header := bitmex.buildSigHeader("POST", ORDER_URL, data)
+	header, err := bitmex.buildSigHeader("POST", ORDER_URL, data)
+	if err != nil {
+		return err, nil
+	}
 
 	bytes, respHeader, err := goex.NewHttpRequestEx(bitmex.client, "POST", BASE_URL+ORDER_URL, data, header)
 	bitmex.handleRespHeader(respHeader)
@@ -263,7 +339,10 @@ func (bitmex *BitMexRest) CancelOrder(orderId string, clientOrderId string) (err
 		params["clOrdID"] = clientOrderId
 	}
 	data := bitmex.map2Query(params)
-	header := bitmex.buildSigHeader("DELETE", ORDER_URL, data)
+	header, err := bitmex.buildSigHeader("DELETE", ORDER_URL, data)
+	if err != nil {
+		return err, nil
+	}
 	data = url.Escape(data)
 	bytes, respHeader, err := goex.NewHttpRequestEx(bitmex.client, "DELETE", BASE_URL + ORDER_URL, data, header)
 	bitmex.handleRespHeader(respHeader)
@@ -284,7 +363,10 @@ func (bitmex *BitMexRest) CancelAll() (error, []goex.FutureOrder) {
 	params := map[string]string {
 	}
 	data := bitmex.map2Query(params)
-	header := bitmex.buildSigHeader("DELETE", ORDER_ALL_URL, data)
+	header, err := bitmex.buildSigHeader("DELETE", ORDER_ALL_URL, data)
+	if err != nil {
+		return err, nil
+	}
 	data = url.Escape(data)
 	bytes, respHeader, err := goex.NewHttpRequestEx(bitmex.client, "DELETE", BASE_URL + ORDER_ALL_URL, data, header)
 	bitmex.handleRespHeader(respHeader)
@@ -323,7 +405,10 @@ func (bitmex *BitMexRest) ListOrders(symbol string, openOnly bool, startTime, en
 		params["count"] = strconv.Itoa(count)
 	}
 	query := bitmex.map2Query(params)
-	header := bitmex.buildSigHeader("GET", ORDER_URL + "?" + query, "")
+	header, err := bitmex.buildSigHeader("GET", ORDER_URL + "?" + query, "")
+	if err != nil {
+		return err, nil
+	}
 	query = url.Escape(query)
 
 	var orders []BitmexOrder
@@ -353,7 +438,10 @@ func (bitmex *BitMexRest) ListFills(symbol string, startTime, endTime string, co
 		params["count"] = strconv.Itoa(count)
 	}
 	query := bitmex.map2Query(params)
-	header := bitmex.buildSigHeader("GET", TRADE_HISTORY_URL + "?" + query, "")
+	header, err := bitmex.buildSigHeader("GET", TRADE_HISTORY_URL + "?" + query, "")
+	if err != nil {
+		return err, nil
+	}
 	query = url.Escape(query)
 
 	var executions []Execution
@@ -390,7 +478,10 @@ func (bitmex *BitMexRest) GetWalletHistory(start, count int) (error, []WalletTra
 	params := map[string]string{"currency":"XBt", "count": strconv.Itoa(count), "start": strconv.Itoa(start)}
 	query := bitmex.map2Query(params)
 	query = url.Escape(query)
-	header := bitmex.buildSigHeader("GET", WALLET_HISTORY_URL + "?" + query, "")
+	header, err := bitmex.buildSigHeader("GET", WALLET_HISTORY_URL + "?" + query, "")
+	if err != nil {
+		return err, nil
+	}
 	var history []WalletTransaction
 
 	err, respHeader := goex.HttpGet5(bitmex.client, BASE_URL+WALLET_HISTORY_URL+"?"+query, header, &history)
@@ -401,3 +492,29 @@ func (bitmex *BitMexRest) GetWalletHistory(start, count int) (error, []WalletTra
 
 	return nil, history
 }
+
+/**
+ * 获取[from, to]时间范围内的全部钱包流水, 时间单位为毫秒, 0表示不限制
+ */
+func (bitmex *BitMexRest) GetAllWalletHistory(from, to int64, count int) (error, []WalletTransaction) {
+	p := goex.NewPaginator(goex.PAGING_BY_PAGE, count, from, to, func(q goex.PageQuery) ([]interface{}, string, error) {
+		err, history := bitmex.GetWalletHistory((q.Page-1)*q.Limit, q.Limit)
+		records := make([]interface{}, len(history))
+		for i, h := range history {
+			records[i] = h
+		}
+		return records, "", err
+	}, func(r interface{}) string {
+		return r.(WalletTransaction).TransactId
+	}, func(r interface{}) int64 {
+		_, ts := ParseTimestamp(r.(WalletTransaction).Timestamp)
+		return ts
+	})
+
+	records, err := p.All()
+	ret := make([]WalletTransaction, len(records))
+	for i, r := range records {
+		ret[i] = r.(WalletTransaction)
+	}
+	return err, ret
+}
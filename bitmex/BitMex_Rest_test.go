@@ -108,3 +108,10 @@ func TestBitMexRest_GetWalletHistory(t *testing.T) {
 	chk(err)
 	Output(ret)
 }
+
+func TestBitMexRest_GetAllWalletHistory(t *testing.T) {
+	bitmex := NewBitMexRest(API_KEY, SECRET_KEY)
+	err, ret := bitmex.GetAllWalletHistory(0, 0, 100)
+	chk(err)
+	Output(ret)
+}
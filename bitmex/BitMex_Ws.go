@@ -6,13 +6,11 @@ import (
 	"time"
 	"sync"
 	"encoding/json"
-	"log"
 	"strings"
 )
 
 type BitMexWs struct {
-	apiKey,
-	apiSecretKey     string
+	signer           *SignerRef
 	ws               *WsConn
 	createWsLock     sync.Mutex
 	wsDepthHandleMap map[string]func(*Depth)
@@ -22,10 +20,36 @@ type BitMexWs struct {
 	accountHandle    func(*FutureAccount)
 	positionHandle   func([]FuturePosition)
 	errorHandle      func(error)
+	logger           Logger
 }
 
 func NewBitMexWs(apiKey, apiSecretyKey string) *BitMexWs {
-	return &BitMexWs{apiKey: apiKey, apiSecretKey: apiSecretyKey}
+	return NewBitMexWsWithSigner(NewStaticSigner(apiKey, apiSecretyKey, ""))
+}
+
+func NewBitMexWsWithSigner(signer Signer) *BitMexWs {
+	return &BitMexWs{signer: NewSignerRef(signer)}
+}
+
+/**
+ * 替换签名器, 新签名器在下次Authenticate时生效
+ */
+func (bitmexWs *BitMexWs) SetSigner(signer Signer) {
+	bitmexWs.signer.Set(signer)
+}
+
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (bitmexWs *BitMexWs) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	bitmexWs.logger = logger
+}
+
+func (bitmexWs *BitMexWs) log() Logger {
+	return ExchangeLogger(bitmexWs.logger, BITMEX)
 }
 
 func (bitmexWs *BitMexWs) createWsConn() {
@@ -40,6 +64,7 @@ func (bitmexWs *BitMexWs) createWsConn() {
 
 			bitmexWs.ws = NewWsConn("wss://www.bitmex.com/realtime")
 			bitmexWs.ws.SetErrorHandler(bitmexWs.errorHandle)
+			bitmexWs.ws.SetLogger(bitmexWs.logger)
 			bitmexWs.ws.Heartbeat(func() interface{} { return "ping"}, 5*time.Second)
 			bitmexWs.ws.ReConnect()
 			bitmexWs.ws.ReceiveMessageEx(func(isBin bool, msg []byte) {
@@ -56,7 +81,7 @@ func (bitmexWs *BitMexWs) createWsConn() {
 				}
 				err := json.Unmarshal(msg, &resp)
 				if err != nil {
-					log.Print(err)
+					bitmexWs.log().Warn("decode websocket message failed", "err", err)
 					return
 				}
 
@@ -172,7 +197,7 @@ func (bitmexWs *BitMexWs) parseDepth(msg []byte) *Depth {
 }
 
 func (bitmexWs *BitMexWs) parseMargin(msg []byte) *FutureAccount {
-	log.Println("BitMexWs.parseMargin", string(msg))
+	bitmexWs.log().Debug("margin message", "msg", string(msg))
 	var data struct {
 		Data []Margin
 	}
@@ -209,7 +234,7 @@ func (bitmexWs *BitMexWs) parseOrder(msg []byte) []FutureOrder {
 }
 
 func (bitmexWs *BitMexWs) parsePosition(msg []byte) []FuturePosition {
-	fmt.Println(string(msg))
+	bitmexWs.log().Debug("position message", "msg", string(msg))
 	var data struct {
 		Data []BitmexPosition
 	}
@@ -265,11 +290,15 @@ func (bitmexWs *BitMexWs) GetTradeWithWs(symbol string, handle func(string, []Tr
 }
 
 func (bitmexWs *BitMexWs) Authenticate() error {
+	expires := ServerTime(BITMEX).Unix() + 30
+	signature, err := bitmexWs.signer.Sign(HMAC_SHA256_HEX, SignMessage(signMessage("GET", "/realtime", expires, "")))
+	if err != nil {
+		return err
+	}
 	bitmexWs.createWsConn()
-	expires := time.Now().Unix() + 30
 	return bitmexWs.ws.Subscribe(map[string]interface{}{
 		"op":   "authKeyExpires",
-		"args": []interface{}{bitmexWs.apiKey, expires, BuildWsSignature(bitmexWs.apiSecretKey, "/realtime", expires)}})
+		"args": []interface{}{signature.ApiKey, expires, signature.Sign}})
 }
 
 func (bitmexWs *BitMexWs) GetAccountWithWs(handle func(*FutureAccount)) error {
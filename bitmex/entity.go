@@ -1,6 +1,7 @@
 package bitmex
 
 import (
+	"github.com/shopspring/decimal"
 	"github.com/stephenlyu/GoEx"
 	"time"
 	"strings"
@@ -181,6 +182,74 @@ func (m *Margin) ToFutureAccount() *goex.FutureAccount {
 	}
 }
 
+type BitmexInstrument struct {
+	Symbol string
+	State string
+	Typ string
+	Expiry string
+	Underlying string
+	QuoteCurrency string
+	SettlCurrency string
+	TickSize decimal.Decimal
+	LotSize decimal.Decimal
+	Multiplier decimal.Decimal
+	IsInverse bool
+	IsQuanto bool
+	UnderlyingToPositionMultiplier decimal.Decimal
+}
+
+/**
+ * 只转换永续合约(FFWCSX)和交割合约(FFCCSX), 其他类型ok为false.
+ * 反向合约面值为1个计价币; 双币种(quanto)合约无法换算成币的数量, ContractValue为0
+ */
+func (this *BitmexInstrument) ToInstrument() (inst goex.Instrument, ok bool) {
+	var typ goex.InstrumentType
+	switch this.Typ {
+	case "FFWCSX":
+		typ = goex.INSTRUMENT_SWAP
+	case "FFCCSX":
+		typ = goex.INSTRUMENT_FUTURE
+	default:
+		return inst, false
+	}
+
+	// 结算币种XBt为聪
+	settle := strings.ToUpper(this.SettlCurrency)
+	inst = goex.Instrument{
+		Exchange: goex.BITMEX,
+		Symbol: this.Symbol,
+		Pair: goex.NewCurrencyPair(goex.NewCurrency(this.Underlying, ""), goex.NewCurrency(this.QuoteCurrency, "")),
+		Type: typ,
+		TickSize: this.TickSize,
+		LotSize: this.LotSize,
+		MinQty: this.LotSize,
+		Multiplier: decimal.New(1, 0),
+		SettleCurrency: goex.NewCurrency(settle, ""),
+	}
+	switch {
+	case this.IsInverse:
+		inst.ContractValue = decimal.New(1, 0)
+	case this.IsQuanto:
+	case this.UnderlyingToPositionMultiplier.Sign() > 0:
+		inst.ContractValue = decimal.New(1, 0).Div(this.UnderlyingToPositionMultiplier)
+	case settle == "XBT":
+		inst.ContractValue = this.Multiplier.Abs().Mul(decimal.NewFromFloat(SATOSHI))
+	}
+	if expiry, err := time.Parse(UTC_FORMAT, this.Expiry); err == nil {
+		inst.Expiry = expiry
+	}
+	switch this.State {
+	case "Open":
+		inst.Status = goex.INSTRUMENT_STATUS_TRADING
+	case "Closed", "Unlisted", "Settled":
+		inst.Status = goex.INSTRUMENT_STATUS_DELISTED
+	default:
+		inst.Status = goex.INSTRUMENT_STATUS_SUSPENDED
+	}
+	inst.FillPrecision()
+	return inst, true
+}
+
 func ParseTimestamp(ts string) (error, int64) {
 	t, err := time.Parse(UTC_FORMAT, ts)
 	if err != nil {
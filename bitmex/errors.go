@@ -0,0 +1,40 @@
+package bitmex
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/stephenlyu/GoEx"
+)
+
+// bitmex错误格式为{"error":{"message":"...","name":"HTTPError"}}, 没有错误码, 按HTTP状态码和错误信息分类
+func parseBitMexError(statusCode int, body []byte) *goex.ExchangeError {
+	var resp struct {
+		Error struct {
+			Message string
+			Name    string
+		}
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Error.Message == "" {
+		return nil
+	}
+
+	msg := resp.Error.Message
+	lower := strings.ToLower(msg)
+	var category goex.ErrorCategory
+	switch {
+	case strings.Contains(lower, "expired"):
+		category = goex.ERR_NONCE
+	case strings.Contains(lower, "overloaded"):
+		category = goex.ERR_RATE_LIMITED
+	case statusCode == 404 && strings.Contains(lower, "not found"):
+		category = goex.ERR_ORDER_NOT_FOUND
+	default:
+		category = goex.ClassifyMessage(msg)
+	}
+	return goex.NewExchangeError(goex.BITMEX, category, resp.Error.Name, msg)
+}
+
+func init() {
+	goex.RegisterErrorParser(goex.BITMEX, parseBitMexError, "www.bitmex.com", "testnet.bitmex.com")
+}
@@ -5,14 +5,15 @@ import (
 	"github.com/stephenlyu/GoEx"
 )
 
+func signMessage(method string, path string, expires int64, data string) string {
+	return fmt.Sprintf("%s%s%d%s", method, path, expires, data)
+}
+
 func BuildSignature(secret string, method string, path string, expires int64, data string) string {
-	message := fmt.Sprintf("%s%s%d%s", method, path, expires, data)
-	ret, _ := goex.GetParamHmacSHA256Sign(secret, message)
+	ret, _ := goex.GetParamHmacSHA256Sign(secret, signMessage(method, path, expires, data))
 	return ret
 }
 
 func BuildWsSignature(secret string, path string, expires int64) string {
-	message := fmt.Sprintf("GET%s%d", path, expires)
-	ret, _ := goex.GetParamHmacSHA256Sign(secret, message)
-	return ret
+	return BuildSignature(secret, "GET", path, expires, "")
 }
@@ -144,6 +144,18 @@ func (ok *Bitribe) GetSymbols() ([]Symbol, error) {
 	return data.Symbols, nil
 }
 
+func (ok *Bitribe) GetInstruments() ([]Instrument, error) {
+	symbols, err := ok.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (this *Bitribe) transSymbol(symbol string) string {
 	return strings.ToUpper(strings.Replace(symbol, "_", "", -1))
 }
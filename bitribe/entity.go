@@ -27,6 +27,31 @@ type Symbol struct {
 	}
 }
 
+func (this *Symbol) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Symbol: this.Symbol,
+		Pair: goex.NewCurrencyPair2(this.Symbol),
+		Type: goex.INSTRUMENT_SPOT,
+		Status: goex.INSTRUMENT_STATUS_SUSPENDED,
+	}
+	if this.Status == "TRADING" {
+		inst.Status = goex.INSTRUMENT_STATUS_TRADING
+	}
+	for _, f := range this.Filters {
+		switch f.FilterType {
+		case "PRICE_FILTER":
+			inst.TickSize = f.TickSize
+		case "LOT_SIZE":
+			inst.LotSize = f.StepSize
+			inst.MinQty = f.MinQty
+		case "MIN_NOTIONAL":
+			inst.MinNotional = f.MinNotional
+		}
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 type OrderInfo struct {
 	Msg string
 	Code decimal.Decimal
@@ -13,6 +13,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 var (
@@ -329,6 +331,50 @@ func (bitstamp *Bitstamp) GetOrderHistorys(currency CurrencyPair, currentPage, p
 
 //
 
+/**
+ * 交易品种来自trading-pairs-info, minimum_order为计价币的最小下单金额, 如"10.0 USD"
+ */
+func (bitstamp *Bitstamp) GetInstruments() ([]Instrument, error) {
+	var resp []struct {
+		Name            string
+		UrlSymbol       string `json:"url_symbol"`
+		BaseDecimals    int32  `json:"base_decimals"`
+		CounterDecimals int32  `json:"counter_decimals"`
+		MinimumOrder    string `json:"minimum_order"`
+		Trading         string
+	}
+	err := HttpGet4(bitstamp.client, BASE_URL+"v2/trading-pairs-info/", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Instrument, 0, len(resp))
+	for _, r := range resp {
+		parts := strings.Split(r.Name, "/")
+		if len(parts) != 2 {
+			continue
+		}
+		inst := Instrument{
+			Exchange:        BITSTAMP,
+			Symbol:          r.UrlSymbol,
+			Pair:            NewCurrencyPair(NewCurrency(parts[0], ""), NewCurrency(parts[1], "")),
+			Type:            INSTRUMENT_SPOT,
+			PricePrecision:  r.CounterDecimals,
+			AmountPrecision: r.BaseDecimals,
+			Status:          INSTRUMENT_STATUS_SUSPENDED,
+		}
+		if fields := strings.Fields(r.MinimumOrder); len(fields) > 0 {
+			inst.MinNotional, _ = decimal.NewFromString(fields[0])
+		}
+		if r.Trading == "Enabled" {
+			inst.Status = INSTRUMENT_STATUS_TRADING
+		}
+		inst.FillPrecision()
+		ret = append(ret, inst)
+	}
+	return ret, nil
+}
+
 func (bitstamp *Bitstamp) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	urlStr := BASE_URL + "v2/ticker/" + strings.ToLower(currency.ToSymbol(""))
 	respmap, err := HttpGet(bitstamp.client, urlStr)
@@ -389,8 +435,67 @@ func (bitstamp *Bitstamp) GetDepth(size int, currency CurrencyPair) (*Depth, err
 	return dep, nil
 }
 
+var klineSteps = map[int]string{
+	KLINE_PERIOD_1MIN:  "60",
+	KLINE_PERIOD_5MIN:  "300",
+	KLINE_PERIOD_15MIN: "900",
+	KLINE_PERIOD_30MIN: "1800",
+	KLINE_PERIOD_60MIN: "3600",
+	KLINE_PERIOD_4H:    "14400",
+	KLINE_PERIOD_1DAY:  "86400",
+}
+
 func (bitstamp *Bitstamp) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
-	panic("not implement")
+	klines, err := bitstamp.GetKlineRecordsDecimal(currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return KlineDecimalsToKlines(klines), nil
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线
+ */
+func (bitstamp *Bitstamp) GetKlineRecordsDecimal(currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	step, err := KlinePeriodCode(klineSteps, period)
+	if err != nil {
+		return nil, err
+	}
+	urlStr := BASE_URL + "v2/ohlc/" + strings.ToLower(currency.ToSymbol("")) + "/"
+
+	return GetAllKlineDecimals(int64(since), size, 1000, func(q PageQuery) ([]KlineDecimal, error) {
+		params := url.Values{}
+		params.Set("step", step)
+		params.Set("limit", strconv.Itoa(q.Limit))
+		if q.From > 0 {
+			params.Set("start", strconv.FormatInt(q.From/1000, 10))
+		} else if q.To > 0 {
+			params.Set("end", strconv.FormatInt(q.To/1000, 10))
+		}
+
+		var resp struct {
+			Data struct {
+				Ohlc []struct {
+					Timestamp decimal.Decimal
+					Open      decimal.Decimal
+					Close     decimal.Decimal
+					High      decimal.Decimal
+					Low       decimal.Decimal
+					Volume    decimal.Decimal
+				}
+			}
+		}
+		err := HttpGet4(bitstamp.client, urlStr+"?"+params.Encode(), nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		klines := make([]KlineDecimal, len(resp.Data.Ohlc))
+		for i, r := range resp.Data.Ohlc {
+			klines[i] = KlineDecimal{Pair: currency, Timestamp: r.Timestamp.IntPart(), Open: r.Open, Close: r.Close, High: r.High, Low: r.Low, Vol: r.Volume}
+		}
+		return klines, nil
+	})
 }
 
 ////非个人，整个交易所的交易记录
@@ -6,6 +6,10 @@ import (
 	"net/http"
 	"sort"
 	"errors"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type Bittrex struct {
@@ -72,6 +76,107 @@ func (bx *Bittrex) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	}, nil
 }
 
+/**
+ * 所有市场的24小时行情. 市场代码是计价币在前, 如BTC-LTC, BaseVolume是计价币成交额, PrevDay是24小时前的价格
+ */
+func (bx *Bittrex) GetAllTickers() (map[CurrencyPair]*TickerDecimal, error) {
+	var resp struct {
+		Success bool
+		Message string
+		Result  []struct {
+			MarketName string
+			High       decimal.Decimal
+			Low        decimal.Decimal
+			Volume     decimal.Decimal
+			Last       decimal.Decimal
+			BaseVolume decimal.Decimal
+			TimeStamp  string
+			Bid        decimal.Decimal
+			Ask        decimal.Decimal
+			PrevDay    decimal.Decimal
+		}
+	}
+	err := HttpGet4(bx.client, bx.baseUrl+"/public/getmarketsummaries", nil, &resp)
+	if err != nil {
+		errCode := HTTP_ERR_CODE
+		errCode.OriginErrMsg = err.Error()
+		return nil, errCode
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.Message)
+	}
+
+	ret := make(map[CurrencyPair]*TickerDecimal, len(resp.Result))
+	for _, r := range resp.Result {
+		parts := strings.Split(r.MarketName, "-")
+		if len(parts) != 2 {
+			continue
+		}
+		pair := CanonicalPair(NewCurrencyPair(NewCurrency(parts[1], ""), NewCurrency(parts[0], "")))
+		ticker := &TickerDecimal{
+			Pair:     pair,
+			Last:     r.Last,
+			Buy:      r.Bid,
+			Sell:     r.Ask,
+			Open:     r.PrevDay,
+			High:     r.High,
+			Low:      r.Low,
+			Vol:      r.Volume,
+			QuoteVol: r.BaseVolume,
+		}
+		if t, err := time.ParseInLocation("2006-01-02T15:04:05", r.TimeStamp, time.UTC); err == nil {
+			ticker.Date = uint64(t.Unix())
+		}
+		ticker.FillChange()
+		ret[pair] = ticker
+	}
+	return ret, nil
+}
+
+/**
+ * 交易品种来自getmarkets, 接口只提供最小下单量, 价格固定为8位小数
+ */
+func (bx *Bittrex) GetInstruments() ([]Instrument, error) {
+	var resp struct {
+		Success bool
+		Message string
+		Result  []struct {
+			MarketCurrency string
+			BaseCurrency   string
+			MinTradeSize   decimal.Decimal
+			MarketName     string
+			IsActive       bool
+		}
+	}
+	err := HttpGet4(bx.client, bx.baseUrl+"/public/getmarkets", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.Message)
+	}
+
+	ret := make([]Instrument, 0, len(resp.Result))
+	for _, r := range resp.Result {
+		inst := Instrument{
+			Exchange:        BITTREX,
+			Symbol:          r.MarketName,
+			Pair:            CanonicalPair(NewCurrencyPair(NewCurrency(r.MarketCurrency, ""), NewCurrency(r.BaseCurrency, ""))),
+			Type:            INSTRUMENT_SPOT,
+			MinQty:          r.MinTradeSize,
+			PricePrecision:  8,
+			AmountPrecision: 8,
+			Status:          INSTRUMENT_STATUS_SUSPENDED,
+		}
+		if r.IsActive {
+			inst.Status = INSTRUMENT_STATUS_TRADING
+		}
+		inst.FillPrecision()
+		ret = append(ret, inst)
+	}
+	return ret, nil
+}
+
 func (bx *Bittrex) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
 
 	resp, err := HttpGet(bx.client, fmt.Sprintf("%s/public/getorderbook?market=%s&type=both", bx.baseUrl, currency.ToSymbol2("-")))
@@ -105,8 +210,62 @@ func (bx *Bittrex) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
 	return dep, nil
 }
 
+var klineTickIntervals = map[int]string{
+	KLINE_PERIOD_1MIN:  "oneMin",
+	KLINE_PERIOD_5MIN:  "fiveMin",
+	KLINE_PERIOD_30MIN: "thirtyMin",
+	KLINE_PERIOD_60MIN: "hour",
+	KLINE_PERIOD_1DAY:  "day",
+}
+
 func (bx *Bittrex) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
-	panic("not implement")
+	klines, err := bx.GetKlineRecordsDecimal(currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return KlineDecimalsToKlines(klines), nil
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线.
+ * v1.1没有K线接口, 使用v2.0的GetTicks, 该接口不分页, 一次返回最近一段时间的全部K线
+ */
+func (bx *Bittrex) GetKlineRecordsDecimal(currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	tickInterval, err := KlinePeriodCode(klineTickIntervals, period)
+	if err != nil {
+		return nil, err
+	}
+	reqUrl := fmt.Sprintf("%s/pub/market/GetTicks?marketName=%s&tickInterval=%s",
+		strings.Replace(bx.baseUrl, "api/v1.1", "Api/v2.0", 1), currency.ToSymbol2("-"), tickInterval)
+
+	var ticks []KlineDecimal
+	return GetAllKlineDecimals(int64(since), size, size, func(q PageQuery) ([]KlineDecimal, error) {
+		if ticks == nil {
+			var resp struct {
+				Success bool
+				Message string
+				Result  []struct {
+					O, H, L, C, V decimal.Decimal
+					T             string
+				}
+			}
+			if err := HttpGet4(bx.client, reqUrl, nil, &resp); err != nil {
+				return nil, err
+			}
+			if !resp.Success {
+				return nil, errors.New(resp.Message)
+			}
+			ticks = make([]KlineDecimal, 0, len(resp.Result))
+			for _, r := range resp.Result {
+				t, err := time.Parse("2006-01-02T15:04:05", r.T)
+				if err != nil {
+					continue
+				}
+				ticks = append(ticks, KlineDecimal{Pair: currency, Timestamp: t.Unix(), Open: r.O, High: r.H, Low: r.L, Close: r.C, Vol: r.V})
+			}
+		}
+		return ticks, nil
+	})
 }
 
 //非个人，整个交易所的交易记录
@@ -14,6 +14,12 @@ func TestBittrex_GetTicker(t *testing.T) {
 	t.Log("ticker=>", ticker)
 }
 
+func TestBittrex_GetAllTickers(t *testing.T) {
+	tickers, err := b.GetAllTickers()
+	t.Log("err=>", err)
+	t.Log("ticker=>", tickers[goex.LTC_BTC])
+}
+
 func TestBittrex_GetDepth(t *testing.T) {
 	dep, err := b.GetDepth(1, goex.BTC_USDT)
 	t.Log("err=>", err)
@@ -13,7 +13,8 @@ const (
 
 	API_BASE_URL = "https://api.btcmarkets.net/"
 
-	TICKER_URI = "market/%s/%s/tick"
+	TICKER_URI  = "market/%s/%s/tick"
+	MARKETS_URI = "v2/market/active"
 )
 
 type Btcmarkets struct {
@@ -30,6 +31,39 @@ func (btcm *Btcmarkets) GetExchangeName() string {
 	return EXCHANGE_NAME
 }
 
+/**
+ * 交易品种来自v2/market/active, 接口不提供精度和最小下单量
+ */
+func (btcm *Btcmarkets) GetInstruments() ([]Instrument, error) {
+	var resp struct {
+		Success      bool
+		ErrorMessage string
+		Markets      []struct {
+			Instrument string
+			Currency   string
+		}
+	}
+	err := HttpGet4(btcm.httpClient, API_BASE_URL+MARKETS_URI, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+
+	ret := make([]Instrument, len(resp.Markets))
+	for i, r := range resp.Markets {
+		ret[i] = Instrument{
+			Exchange: EXCHANGE_NAME,
+			Symbol:   r.Instrument + "-" + r.Currency,
+			Pair:     NewCurrencyPair(NewCurrency(r.Instrument, ""), NewCurrency(r.Currency, "")),
+			Type:     INSTRUMENT_SPOT,
+			Status:   INSTRUMENT_STATUS_TRADING,
+		}
+	}
+	return ret, nil
+}
+
 func (btcm *Btcmarkets) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	tickerUri := fmt.Sprintf(API_BASE_URL+TICKER_URI, currency.CurrencyA.String(), currency.CurrencyB.String())
 	//log.Println("tickerUrl:", tickerUri)
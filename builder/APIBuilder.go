@@ -28,21 +28,32 @@ import (
 )
 
 type APIBuilder struct {
-	client      *http.Client
-	httpTimeout time.Duration
-	apiKey      string
-	secretkey   string
-	clientId    string
+	client          *http.Client
+	transportConfig *TransportConfig // NewAPIBuilder创建的client才有, HttpProxy和HttpTimeout修改配置后重建Transport
+	httpTimeout     time.Duration
+	apiKey          string
+	secretkey       string
+	clientId        string
 }
 
+/**
+ * 使用独立的http.Client, 不修改http.DefaultClient
+ */
 func NewAPIBuilder() (builder *APIBuilder) {
-	_client := http.DefaultClient
-	transport := &http.Transport{
-		MaxIdleConns:    10,
-		IdleConnTimeout: 4 * time.Second,
+	config := DefaultTransportConfig()
+	transport, _ := NewTransport(config)
+	return &APIBuilder{client: &http.Client{Transport: transport}, transportConfig: &config}
+}
+
+/**
+ * 使用自定义的传输层配置
+ */
+func NewAPIBuilderWithTransport(config TransportConfig) (*APIBuilder, error) {
+	transport, err := NewTransport(config)
+	if err != nil {
+		return nil, err
 	}
-	_client.Transport = transport
-	return &APIBuilder{client: _client}
+	return &APIBuilder{client: &http.Client{Transport: transport}, transportConfig: &config}, nil
 }
 
 func NewCustomAPIBuilder(client *http.Client) (builder *APIBuilder) {
@@ -59,13 +70,38 @@ func (builder *APIBuilder) APISecretkey(key string) (_builder *APIBuilder) {
 	return builder
 }
 
+func (builder *APIBuilder) rebuildTransport() error {
+	transport, err := NewTransport(*builder.transportConfig)
+	if err != nil {
+		return err
+	}
+	if old, ok := builder.client.Transport.(*Transport); ok {
+		old.CloseIdleConnections()
+	}
+	builder.client.Transport = transport
+	return nil
+}
+
+/**
+ * 设置代理, 支持http://、https://和socks5://. 代理地址无效时不修改
+ */
 func (builder *APIBuilder) HttpProxy(proxyUrl string) (_builder *APIBuilder) {
+	if builder.transportConfig != nil {
+		old := builder.transportConfig.Proxy
+		builder.transportConfig.Proxy = proxyUrl
+		if builder.rebuildTransport() != nil {
+			builder.transportConfig.Proxy = old
+		}
+		return builder
+	}
+
 	proxy, err := url.Parse(proxyUrl)
 	if err != nil {
-		return
+		return builder
+	}
+	if transport, ok := builder.client.Transport.(*http.Transport); ok {
+		transport.Proxy = http.ProxyURL(proxy)
 	}
-	transport := builder.client.Transport.(*http.Transport)
-	transport.Proxy = http.ProxyURL(proxy)
 	return builder
 }
 
@@ -77,8 +113,14 @@ func (builder *APIBuilder) ClientID(id string) (_builder *APIBuilder) {
 func (builder *APIBuilder) HttpTimeout(timeout time.Duration) (_builder *APIBuilder) {
 	builder.httpTimeout = timeout
 	builder.client.Timeout = timeout
-	transport := builder.client.Transport.(*http.Transport)
-	if transport != nil {
+	if builder.transportConfig != nil {
+		builder.transportConfig.DialTimeout = timeout
+		builder.transportConfig.TLSHandshakeTimeout = timeout
+		builder.transportConfig.ResponseHeaderTimeout = timeout
+		builder.rebuildTransport()
+		return builder
+	}
+	if transport, ok := builder.client.Transport.(*http.Transport); ok {
 		transport.ResponseHeaderTimeout = timeout
 		transport.TLSHandshakeTimeout = timeout
 		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
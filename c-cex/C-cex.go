@@ -14,6 +14,7 @@ const (
 	API_BASE_URL = "https://c-cex.com/"
 
 	TICKER_URI = "t/"
+	PAIRS_URI  = "t/pairs.json"
 )
 
 type C_cex struct {
@@ -30,6 +31,34 @@ func (ccex *C_cex) GetExchangeName() string {
 	return EXCHANGE_NAME
 }
 
+/**
+ * 交易品种来自pairs.json, 如"ltc-btc", 接口不提供精度和最小下单量
+ */
+func (ccex *C_cex) GetInstruments() ([]Instrument, error) {
+	var resp struct {
+		Pairs []string
+	}
+	err := HttpGet4(ccex.httpClient, API_BASE_URL+PAIRS_URI, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Instrument, 0, len(resp.Pairs))
+	for _, symbol := range resp.Pairs {
+		parts := strings.Split(symbol, "-")
+		if len(parts) != 2 {
+			continue
+		}
+		ret = append(ret, Instrument{
+			Exchange: EXCHANGE_NAME,
+			Symbol:   symbol,
+			Pair:     NewCurrencyPair(NewCurrency(parts[0], ""), NewCurrency(parts[1], "")),
+			Type:     INSTRUMENT_SPOT,
+		})
+	}
+	return ret, nil
+}
+
 func (ccex *C_cex) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	currency = ccex.adaptCurrencyPair(currency)
 
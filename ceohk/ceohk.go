@@ -104,6 +104,31 @@ func (ok *CEOHK) GetAllTickers() (map[string]*TickerDecimal, error) {
 	return ret, nil
 }
 
+/**
+ * 交易品种来自allTicker, 如"sht_qc", 接口不提供精度和最小下单量
+ */
+func (ok *CEOHK) GetInstruments() ([]Instrument, error) {
+	tickers, err := ok.GetAllTickers()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Instrument, 0, len(tickers))
+	for symbol := range tickers {
+		pair := NewCurrencyPair2(strings.ToUpper(symbol))
+		if pair == UNKNOWN_PAIR {
+			continue
+		}
+		ret = append(ret, Instrument{
+			Symbol: symbol,
+			Pair:   pair,
+			Type:   INSTRUMENT_SPOT,
+			Status: INSTRUMENT_STATUS_TRADING,
+		})
+	}
+	return ret, nil
+}
+
 func (ok *CEOHK) GetTicker(market string) (*TickerDecimal, error) {
 	market = strings.ToLower(market)
 	url := API_BASE_URL + TICKER
@@ -257,8 +257,69 @@ func (coin58 *Coin58) GetDepth(size int, currency CurrencyPair) (*Depth, error)
 	return dep, nil
 }
 
+var klinePeriods = map[int]string{
+	KLINE_PERIOD_1MIN:  "1min",
+	KLINE_PERIOD_5MIN:  "5min",
+	KLINE_PERIOD_15MIN: "15min",
+	KLINE_PERIOD_30MIN: "30min",
+	KLINE_PERIOD_60MIN: "1hour",
+	KLINE_PERIOD_4H:    "4hour",
+	KLINE_PERIOD_1DAY:  "1day",
+	KLINE_PERIOD_1WEEK: "1week",
+}
+
 func (coin58 *Coin58) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
-	panic("not implement")
+	klines, err := coin58.GetKlineRecordsDecimal(currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return KlineDecimalsToKlines(klines), nil
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线
+ */
+func (coin58 *Coin58) GetKlineRecordsDecimal(currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	p, err := KlinePeriodCode(klinePeriods, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetAllKlineDecimals(int64(since), size, 500, func(q PageQuery) ([]KlineDecimal, error) {
+		params := url.Values{}
+		params.Set("symbol", currency.AdaptUsdToUsdt().ToSymbol("_"))
+		params.Set("period", p)
+		params.Set("limit", fmt.Sprint(q.Limit))
+		if q.From > 0 {
+			params.Set("since", fmt.Sprint(q.From))
+		}
+		m, err := HttpGet(coin58.client, coin58.apiurl+"spot/candles?"+params.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		error := m["error"]
+		if error != nil {
+			return nil, coin58.adaptError(error.(map[string]interface{}))
+		}
+
+		// [time, open, high, low, close, volume]
+		r, _ := m["result"].([]interface{})
+		klines := make([]KlineDecimal, 0, len(r))
+		for _, v := range r {
+			k, _ := v.([]interface{})
+			if len(k) < 6 {
+				continue
+			}
+			ts := ToDecimal(k[0]).IntPart()
+			if q.To > 0 && ts > q.To {
+				continue
+			}
+			klines = append(klines, KlineDecimal{Pair: currency, Timestamp: ts / 1000,
+				Open: ToDecimal(k[1]), High: ToDecimal(k[2]), Low: ToDecimal(k[3]), Close: ToDecimal(k[4]), Vol: ToDecimal(k[5])})
+		}
+		return klines, nil
+	})
 }
 
 //非个人，整个交易所的交易记录
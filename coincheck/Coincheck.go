@@ -144,8 +144,11 @@ func (cc *Coincheck) GetAccount() (*Account, error) {
 	return nil, nil
 }
 
+/**
+ * 交易所没有公开的K线接口
+ */
 func (cc *Coincheck) GetKlineRecords(currency CurrencyPair, period , size, since int) ([]Kline, error) {
-	return nil, nil
+	return nil, EX_ERR_NOT_SUPPORTED
 }
 
 //非个人，整个交易所的交易记录
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/shopspring/decimal"
 	. "github.com/stephenlyu/GoEx"
 )
 
@@ -238,8 +239,112 @@ func (coinex *CoinEx) GetAccount() (*Account, error) {
 	return acc, nil
 }
 
+var klineTypes = map[int]string{
+	KLINE_PERIOD_1MIN:  "1min",
+	KLINE_PERIOD_5MIN:  "5min",
+	KLINE_PERIOD_15MIN: "15min",
+	KLINE_PERIOD_30MIN: "30min",
+	KLINE_PERIOD_60MIN: "1hour",
+	KLINE_PERIOD_4H:    "4hour",
+	KLINE_PERIOD_1DAY:  "1day",
+	KLINE_PERIOD_1WEEK: "1week",
+}
+
 func (coinex *CoinEx) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
-	panic("not implement")
+	klines, err := coinex.GetKlineRecordsDecimal(currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return KlineDecimalsToKlines(klines), nil
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线. 接口只返回最近的1000根K线
+ */
+func (coinex *CoinEx) GetKlineRecordsDecimal(currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	typ, err := KlinePeriodCode(klineTypes, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetAllKlineDecimals(int64(since), size, 1000, func(q PageQuery) ([]KlineDecimal, error) {
+		params := url.Values{}
+		params.Set("market", currency.ToSymbol(""))
+		params.Set("type", typ)
+		params.Set("limit", strconv.Itoa(q.Limit))
+		buf, err := coinex.doRequestInner("GET", "market/kline", &params)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp struct {
+			Code    int
+			Message string
+			// [time, open, close, high, low, volume, amount, market]
+			Data [][]interface{}
+		}
+		if err = json.Unmarshal(buf, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Code != 0 {
+			return nil, errors.New(resp.Message)
+		}
+
+		klines := make([]KlineDecimal, 0, len(resp.Data))
+		for _, r := range resp.Data {
+			if len(r) < 6 {
+				continue
+			}
+			klines = append(klines, KlineDecimal{Pair: currency, Timestamp: ToDecimal(r[0]).IntPart(),
+				Open: ToDecimal(r[1]), Close: ToDecimal(r[2]), High: ToDecimal(r[3]), Low: ToDecimal(r[4]), Vol: ToDecimal(r[5])})
+		}
+		return klines, nil
+	})
+}
+
+/**
+ * 交易品种来自market/info, min_amount为最小下单数量
+ */
+func (coinex *CoinEx) GetInstruments() ([]Instrument, error) {
+	buf, err := coinex.doRequestInner("GET", "market/info", &url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Code    int
+		Message string
+		Data    map[string]struct {
+			Name           string
+			TradingName    string          `json:"trading_name"`
+			PricingName    string          `json:"pricing_name"`
+			TradingDecimal int32           `json:"trading_decimal"`
+			PricingDecimal int32           `json:"pricing_decimal"`
+			MinAmount      decimal.Decimal `json:"min_amount"`
+		}
+	}
+	if err = json.Unmarshal(buf, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Code != 0 {
+		return nil, errors.New(resp.Message)
+	}
+
+	ret := make([]Instrument, 0, len(resp.Data))
+	for _, r := range resp.Data {
+		inst := Instrument{
+			Exchange:        COINEX,
+			Symbol:          r.Name,
+			Pair:            NewCurrencyPair(NewCurrency(r.TradingName, ""), NewCurrency(r.PricingName, "")),
+			Type:            INSTRUMENT_SPOT,
+			PricePrecision:  r.PricingDecimal,
+			AmountPrecision: r.TradingDecimal,
+			MinQty:          r.MinAmount,
+		}
+		inst.FillPrecision()
+		ret = append(ret, inst)
+	}
+	return ret, nil
 }
 
 //非个人，整个交易所的交易记录
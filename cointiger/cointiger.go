@@ -148,6 +148,18 @@ func (ok *CoinTiger) GetSymbols() ([]Symbol, error) {
 	return ret, nil
 }
 
+func (ok *CoinTiger) GetInstruments() ([]Instrument, error) {
+	symbols, err := ok.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (this *CoinTiger) transSymbol(symbol string) string {
 	return strings.ToLower(strings.Replace(symbol, "_", "", -1))
 }
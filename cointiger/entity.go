@@ -14,6 +14,19 @@ type Symbol struct {
 	AmountMin decimal.Decimal
 }
 
+func (this *Symbol) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Symbol: this.Symbol,
+		Pair: goex.NewCurrencyPair2(this.Symbol),
+		Type: goex.INSTRUMENT_SPOT,
+		PricePrecision: int32(this.PricePrecision),
+		AmountPrecision: int32(this.AmountPrecision),
+		MinQty: this.AmountMin,
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 type OrderInfo struct {
 	Symbol string
 	Fee decimal.Decimal
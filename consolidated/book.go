@@ -0,0 +1,405 @@
+package consolidated
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// DEFAULT_MAX_AGE Depth older than this is stale unless the venue sets its own MaxAge
+const DEFAULT_MAX_AGE = 5 * time.Second
+
+type venueBook struct {
+	venue    Venue
+	bids     []goex.DepthRecordDecimal // Descending, base currency amounts
+	asks     []goex.DepthRecordDecimal // Ascending, base currency amounts
+	updated  time.Time
+	exchange time.Time
+}
+
+// Book Consolidated order book of one pair across venues.
+// Depth callbacks of any connector can feed it through DepthHandler or DepthFloatHandler;
+// each update replaces the venue's whole book, levels are re-sorted and quantities converted to the base currency.
+// The BBO is kept from the first level of each venue, Snapshot merges the full depth.
+type Book struct {
+	Pair        goex.CurrencyPair
+	FeeAdjusted bool          // Bids are lowered and asks raised by each venue's FeeRate
+	MaxAge      time.Duration // Default staleness threshold
+
+	lock       sync.Mutex
+	venues     map[string]*venueBook
+	bbo        BBO
+	bboHandles []func(BBO)
+	now        func() time.Time
+
+	notifyLock sync.Mutex
+	delivered  uint64 // Seq of the last BBO passed to the handlers
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewBook Book constructor
+func NewBook(pair goex.CurrencyPair) *Book {
+	return &Book{
+		Pair:   pair,
+		MaxAge: DEFAULT_MAX_AGE,
+		venues: make(map[string]*venueBook),
+		now:    time.Now,
+	}
+}
+
+// AddVenue Add or reconfigure a venue, its current depth is kept
+func (b *Book) AddVenue(venue Venue) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if vb, ok := b.venues[venue.Name]; ok {
+		vb.venue = venue
+		return
+	}
+	b.venues[venue.Name] = &venueBook{venue: venue}
+}
+
+// RemoveVenue Drop a venue and its depth
+func (b *Book) RemoveVenue(name string) {
+	b.lock.Lock()
+	delete(b.venues, name)
+	bbo, changed := b.updateBBO()
+	handles := b.bboHandles
+	b.lock.Unlock()
+	b.notify(handles, bbo, changed)
+}
+
+// SubscribeBBO Register a handler for best bid or offer changes.
+// Handlers run one at a time in Seq order, a BBO superseded before its turn is skipped;
+// handlers must not feed the book
+func (b *Book) SubscribeBBO(handle func(BBO)) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.bboHandles = append(b.bboHandles, handle)
+}
+
+// Subscribe Add a venue and start its depth stream, e.g.
+// book.Subscribe(venue, func(h func(*goex.DepthDecimal)) error { return api.GetDepthWithWs("btcusdt", h) })
+func (b *Book) Subscribe(venue Venue, subscribe func(handle func(*goex.DepthDecimal)) error) error {
+	b.AddVenue(venue)
+	return subscribe(b.DepthHandler(venue.Name))
+}
+
+// SubscribeFloat Same as Subscribe for connectors delivering float depth
+func (b *Book) SubscribeFloat(venue Venue, subscribe func(handle func(*goex.Depth)) error) error {
+	b.AddVenue(venue)
+	return subscribe(b.DepthFloatHandler(venue.Name))
+}
+
+// DepthHandler Depth callback of a venue, the signature matches GetDepthWithWs handlers
+func (b *Book) DepthHandler(venue string) func(*goex.DepthDecimal) {
+	return func(depth *goex.DepthDecimal) {
+		b.OnDepth(venue, depth)
+	}
+}
+
+// DepthFloatHandler Depth callback of a venue for connectors delivering float depth
+func (b *Book) DepthFloatHandler(venue string) func(*goex.Depth) {
+	return func(depth *goex.Depth) {
+		b.OnDepthFloat(venue, depth)
+	}
+}
+
+func toDecimalRecords(records goex.DepthRecords) goex.DepthRecordsDecimal {
+	ret := make(goex.DepthRecordsDecimal, len(records))
+	for i, r := range records {
+		ret[i] = goex.DepthRecordDecimal{Price: decimal.NewFromFloat(r.Price), Amount: decimal.NewFromFloat(r.Amount)}
+	}
+	return ret
+}
+
+// OnDepthFloat Feed a float depth update of a venue
+func (b *Book) OnDepthFloat(venue string, depth *goex.Depth) {
+	if depth == nil {
+		return
+	}
+	b.OnDepth(venue, &goex.DepthDecimal{
+		ContractType: depth.ContractType,
+		InstrumentId: depth.InstrumentId,
+		Pair:         depth.Pair,
+		UTime:        depth.UTime,
+		AskList:      toDecimalRecords(depth.AskList),
+		BidList:      toDecimalRecords(depth.BidList),
+	})
+}
+
+func samePair(a, b goex.CurrencyPair) bool {
+	return goex.CanonicalCurrency(a.CurrencyA) == goex.CanonicalCurrency(b.CurrencyA) &&
+		goex.CanonicalCurrency(a.CurrencyB) == goex.CanonicalCurrency(b.CurrencyB)
+}
+
+// OnDepth Feed a depth update of a venue. Unknown venues are added with default settings;
+// updates carrying a different pair are dropped, USD and USDT quotes are treated as different pairs
+func (b *Book) OnDepth(venue string, depth *goex.DepthDecimal) {
+	if depth == nil {
+		return
+	}
+	if depth.Pair.CurrencyA.Symbol != "" && depth.Pair != goex.UNKNOWN_PAIR && !samePair(depth.Pair, b.Pair) {
+		return
+	}
+
+	b.lock.Lock()
+	vb, ok := b.venues[venue]
+	if !ok {
+		vb = &venueBook{venue: Venue{Name: venue}}
+		b.venues[venue] = vb
+	}
+	vb.bids = normalize(depth.BidList, vb.venue.Qty, true)
+	vb.asks = normalize(depth.AskList, vb.venue.Qty, false)
+	vb.updated = b.now()
+	vb.exchange = depth.UTime
+	bbo, changed := b.updateBBO()
+	handles := b.bboHandles
+	b.lock.Unlock()
+	b.notify(handles, bbo, changed)
+}
+
+func normalize(records goex.DepthRecordsDecimal, qty QtyConverter, desc bool) []goex.DepthRecordDecimal {
+	ret := make([]goex.DepthRecordDecimal, 0, len(records))
+	for _, r := range records {
+		if r.Price.Sign() <= 0 || r.Amount.Sign() <= 0 {
+			continue
+		}
+		if qty != nil {
+			r.Amount = qty(r.Price, r.Amount)
+		}
+		ret = append(ret, r)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if desc {
+			return ret[i].Price.GreaterThan(ret[j].Price)
+		}
+		return ret[i].Price.LessThan(ret[j].Price)
+	})
+	return ret
+}
+
+// notify Deliver a changed BBO outside the book lock, BBOs older than the last delivered one are dropped
+func (b *Book) notify(handles []func(BBO), bbo BBO, changed bool) {
+	if !changed {
+		return
+	}
+	b.notifyLock.Lock()
+	defer b.notifyLock.Unlock()
+	if bbo.Seq <= b.delivered {
+		return
+	}
+	b.delivered = bbo.Seq
+	for _, handle := range handles {
+		handle(bbo)
+	}
+}
+
+func (b *Book) isStale(vb *venueBook, now time.Time) bool {
+	maxAge := vb.venue.MaxAge
+	if maxAge <= 0 {
+		maxAge = b.MaxAge
+	}
+	return vb.updated.IsZero() || (maxAge > 0 && now.Sub(vb.updated) > maxAge)
+}
+
+func (b *Book) adjust(vb *venueBook, price decimal.Decimal, bid bool) decimal.Decimal {
+	if !b.FeeAdjusted || vb.venue.FeeRate.IsZero() {
+		return price
+	}
+	if bid {
+		return price.Mul(decimal.New(1, 0).Sub(vb.venue.FeeRate))
+	}
+	return price.Mul(decimal.New(1, 0).Add(vb.venue.FeeRate))
+}
+
+func (b *Book) venueNames() []string {
+	names := make([]string, 0, len(b.venues))
+	for name := range b.venues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// merge Merge the levels of fresh venues, levels is the number of levels to return, 0 for all
+func (b *Book) merge(now time.Time, levels int) ([]Level, []Level, []string) {
+	names := b.venueNames()
+
+	var stale []string
+	bidMap := make(map[string]*Level)
+	askMap := make(map[string]*Level)
+	var bids, asks []*Level
+	add := func(m map[string]*Level, list *[]*Level, vb *venueBook, r goex.DepthRecordDecimal, bid bool) {
+		price := b.adjust(vb, r.Price, bid)
+		key := price.String()
+		l, ok := m[key]
+		if !ok {
+			l = &Level{Price: price}
+			m[key] = l
+			*list = append(*list, l)
+		}
+		l.Amount = l.Amount.Add(r.Amount)
+		l.Venues = append(l.Venues, VenueQty{Venue: vb.venue.Name, Price: r.Price, Amount: r.Amount})
+	}
+	for _, name := range names {
+		vb := b.venues[name]
+		if b.isStale(vb, now) {
+			stale = append(stale, name)
+			continue
+		}
+		for _, r := range vb.bids {
+			add(bidMap, &bids, vb, r, true)
+		}
+		for _, r := range vb.asks {
+			add(askMap, &asks, vb, r, false)
+		}
+	}
+
+	sort.Slice(bids, func(i, j int) bool { return bids[i].Price.GreaterThan(bids[j].Price) })
+	sort.Slice(asks, func(i, j int) bool { return asks[i].Price.LessThan(asks[j].Price) })
+	return flatten(bids, levels), flatten(asks, levels), stale
+}
+
+func flatten(levels []*Level, n int) []Level {
+	if n > 0 && len(levels) > n {
+		levels = levels[:n]
+	}
+	ret := make([]Level, len(levels))
+	for i, l := range levels {
+		ret[i] = *l
+	}
+	return ret
+}
+
+// best Best consolidated level of one side, only the first level of each fresh venue is looked at
+func (b *Book) best(names []string, now time.Time, bid bool) Level {
+	var best Level
+	for _, name := range names {
+		vb := b.venues[name]
+		records := vb.asks
+		if bid {
+			records = vb.bids
+		}
+		if len(records) == 0 || b.isStale(vb, now) {
+			continue
+		}
+		price := b.adjust(vb, records[0].Price, bid)
+		if len(best.Venues) > 0 {
+			cmp := price.Cmp(best.Price)
+			if bid {
+				cmp = -cmp
+			}
+			if cmp > 0 {
+				continue
+			}
+			if cmp < 0 {
+				best = Level{}
+			}
+		}
+		best.Price = price
+		for _, r := range records {
+			if !r.Price.Equal(records[0].Price) {
+				break
+			}
+			best.Amount = best.Amount.Add(r.Amount)
+			best.Venues = append(best.Venues, VenueQty{Venue: vb.venue.Name, Price: r.Price, Amount: r.Amount})
+		}
+	}
+	return best
+}
+
+func (b *Book) updateBBO() (BBO, bool) {
+	now := b.now()
+	names := b.venueNames()
+	bbo := BBO{Pair: b.Pair, Bid: b.best(names, now, true), Ask: b.best(names, now, false), Time: now}
+	if bbo.equal(&b.bbo) {
+		return bbo, false
+	}
+	bbo.Seq = b.bbo.Seq + 1
+	b.bbo = bbo
+	return bbo, true
+}
+
+// Snapshot Consolidated depth of fresh venues, levels is the number of levels per side, 0 for all
+func (b *Book) Snapshot(levels int) Snapshot {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := b.now()
+	bids, asks, stale := b.merge(now, levels)
+	return Snapshot{Pair: b.Pair, Bids: bids, Asks: asks, Stale: stale, Time: now}
+}
+
+// BBO Current best bid and offer
+func (b *Book) BBO() BBO {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.bbo
+}
+
+// Status Freshness of all venues, ordered by name
+func (b *Book) Status() []VenueStatus {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := b.now()
+	ret := make([]VenueStatus, 0, len(b.venues))
+	for name, vb := range b.venues {
+		ret = append(ret, VenueStatus{Venue: name, Updated: vb.updated, Exchange: vb.exchange, Stale: b.isStale(vb, now)})
+	}
+	sort.Slice(ret, func(i, j int) bool { return strings.Compare(ret[i].Venue, ret[j].Venue) < 0 })
+	return ret
+}
+
+// CheckStale Re-evaluate the BBO as venues age out, Start calls it periodically
+func (b *Book) CheckStale() {
+	b.lock.Lock()
+	bbo, changed := b.updateBBO()
+	handles := b.bboHandles
+	b.lock.Unlock()
+	b.notify(handles, bbo, changed)
+}
+
+// Start Call CheckStale periodically so that the BBO drops venues that stopped updating
+func (b *Book) Start(interval time.Duration) {
+	b.lock.Lock()
+	if b.stopCh != nil {
+		b.lock.Unlock()
+		return
+	}
+	b.stopCh = make(chan struct{})
+	stopCh := b.stopCh
+	b.lock.Unlock()
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				b.CheckStale()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop Stop the staleness check
+func (b *Book) Stop() {
+	b.lock.Lock()
+	stopCh := b.stopCh
+	b.stopCh = nil
+	b.lock.Unlock()
+
+	if stopCh != nil {
+		close(stopCh)
+	}
+	b.wg.Wait()
+}
@@ -0,0 +1,146 @@
+package consolidated
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+	"github.com/stretchr/testify/assert"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func depth(bids, asks [][2]string) *goex.DepthDecimal {
+	d := &goex.DepthDecimal{Pair: goex.BTC_USDT}
+	for _, r := range bids {
+		d.BidList = append(d.BidList, goex.DepthRecordDecimal{Price: dec(r[0]), Amount: dec(r[1])})
+	}
+	for _, r := range asks {
+		d.AskList = append(d.AskList, goex.DepthRecordDecimal{Price: dec(r[0]), Amount: dec(r[1])})
+	}
+	return d
+}
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func TestBook_Consolidate(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1560000000, 0)}
+	book := NewBook(goex.BTC_USDT)
+	book.now = clock.now
+	var bbos []BBO
+	book.SubscribeBBO(func(bbo BBO) {
+		bbos = append(bbos, bbo)
+	})
+
+	book.AddVenue(Venue{Name: "okex"})
+	// Asks delivered in descending order are re-sorted
+	book.OnDepth("okex", depth([][2]string{{"100", "1"}, {"99", "2"}}, [][2]string{{"102", "1"}, {"101", "1"}}))
+	book.OnDepth("huobi", depth([][2]string{{"100", "3"}, {"98", "1"}}, [][2]string{{"101.5", "2"}}))
+	assert.Equal(t, 2, len(bbos))
+	bbo := book.BBO()
+	assert.Equal(t, "100", bbo.Bid.Price.String())
+	assert.Equal(t, "4", bbo.Bid.Amount.String())
+	assert.Equal(t, 2, len(bbo.Bid.Venues))
+	assert.Equal(t, "huobi", bbo.Bid.Venues[0].Venue)
+	assert.Equal(t, "101", bbo.Ask.Price.String())
+	assert.Equal(t, "okex", bbo.Ask.Venues[0].Venue)
+	assert.False(t, bbo.Crossed())
+
+	// Same BBO, no notification
+	book.OnDepth("huobi", depth([][2]string{{"100", "3"}, {"97", "1"}}, [][2]string{{"101.5", "2"}}))
+	assert.Equal(t, 2, len(bbos))
+
+	snapshot := book.Snapshot(2)
+	assert.Equal(t, 2, len(snapshot.Bids))
+	assert.Equal(t, "99", snapshot.Bids[1].Price.String())
+	assert.Equal(t, "101.5", snapshot.Asks[1].Price.String())
+
+	// Depth for another pair is dropped
+	other := depth([][2]string{{"10", "1"}}, nil)
+	other.Pair = goex.ETH_USDT
+	book.OnDepth("okex", other)
+	assert.Equal(t, "100", book.BBO().Bid.Price.String())
+
+	// okex goes stale
+	book.AddVenue(Venue{Name: "okex", MaxAge: time.Second})
+	clock.t = clock.t.Add(2 * time.Second)
+	book.CheckStale()
+	assert.Equal(t, 3, len(bbos))
+	assert.Equal(t, "3", bbos[2].Bid.Amount.String())
+	assert.Equal(t, "101.5", bbos[2].Ask.Price.String())
+	status := book.Status()
+	assert.Equal(t, "huobi", status[0].Venue)
+	assert.False(t, status[0].Stale)
+	assert.True(t, status[1].Stale)
+	assert.Equal(t, []string{"okex"}, book.Snapshot(0).Stale)
+}
+
+func TestBook_FeesAndContracts(t *testing.T) {
+	book := NewBook(goex.BTC_USD)
+	book.FeeAdjusted = true
+	inverse := goex.Instrument{Pair: goex.BTC_USD, Type: goex.INSTRUMENT_FUTURE, ContractValue: dec("100"), SettleCurrency: goex.BTC}
+	book.AddVenue(Venue{Name: "okex", FeeRate: dec("0.001"), Qty: InstrumentQty(inverse)})
+	book.AddVenue(Venue{Name: "bitmex", FeeRate: dec("0.00075")})
+
+	var d goex.Depth
+	d.Pair = goex.CurrencyPair{CurrencyA: goex.XBT, CurrencyB: goex.USD}
+	d.BidList = goex.DepthRecords{{Price: 8000, Amount: 2}}
+	d.AskList = goex.DepthRecords{{Price: 8001, Amount: 1}}
+	book.OnDepthFloat("bitmex", &d)
+	book.OnDepth("okex", &goex.DepthDecimal{
+		BidList: goex.DepthRecordsDecimal{{Price: dec("8000"), Amount: dec("160")}},
+		AskList: goex.DepthRecordsDecimal{{Price: dec("8000"), Amount: dec("80")}},
+	})
+
+	bbo := book.BBO()
+	assert.Equal(t, "7994", bbo.Bid.Price.String())
+	assert.Equal(t, "bitmex", bbo.Bid.Venues[0].Venue)
+	assert.Equal(t, "8000", bbo.Bid.Venues[0].Price.String())
+	// 8000 * 1.001 > 8001 * 1.00075
+	assert.Equal(t, "bitmex", bbo.Ask.Venues[0].Venue)
+
+	snapshot := book.Snapshot(0)
+	assert.Equal(t, "okex", snapshot.Bids[1].Venues[0].Venue)
+	assert.Equal(t, "2", snapshot.Bids[1].Amount.String())
+	assert.Equal(t, "1", snapshot.Asks[1].Amount.String())
+}
+
+func TestBook_BBOOrder(t *testing.T) {
+	book := NewBook(goex.BTC_USDT)
+	var seqs []uint64
+	var last BBO
+	book.SubscribeBBO(func(bbo BBO) {
+		seqs = append(seqs, bbo.Seq)
+		last = bbo
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 1; j <= 50; j++ {
+				price := decimal.New(int64(100*i+j), 0).String()
+				book.OnDepth("okex", depth([][2]string{{price, "1"}}, nil))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.True(t, len(seqs) > 0)
+	for i := 1; i < len(seqs); i++ {
+		assert.True(t, seqs[i] > seqs[i-1])
+	}
+	assert.Equal(t, book.BBO().Seq, last.Seq)
+	assert.True(t, book.BBO().Bid.Price.Equal(last.Bid.Price))
+}
@@ -0,0 +1,83 @@
+package consolidated
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// QtyConverter Convert a venue quantity at a price into the base currency amount
+type QtyConverter func(price, amount decimal.Decimal) decimal.Decimal
+
+// InstrumentQty Quantity converter for contract venues, spot instruments are returned unchanged
+func InstrumentQty(inst goex.Instrument) QtyConverter {
+	if inst.Type == goex.INSTRUMENT_SPOT {
+		return nil
+	}
+	return func(price, amount decimal.Decimal) decimal.Decimal {
+		coin, err := inst.ContractsToCoin(amount, price)
+		if err != nil {
+			return decimal.Zero
+		}
+		return coin
+	}
+}
+
+// Venue One exchange feeding the book
+type Venue struct {
+	Name    string
+	FeeRate decimal.Decimal // Taker fee rate, used when the book is fee adjusted
+	Qty     QtyConverter    // Nil when the venue quotes base currency amounts
+	MaxAge  time.Duration   // Depth older than this is stale, zero uses the book default
+}
+
+// VenueQty Contribution of one venue to a consolidated level
+type VenueQty struct {
+	Venue  string
+	Price  decimal.Decimal // Venue price before fee adjustment
+	Amount decimal.Decimal // Base currency amount
+}
+
+// Level Consolidated price level, Price is fee adjusted when the book is
+type Level struct {
+	Price  decimal.Decimal
+	Amount decimal.Decimal
+	Venues []VenueQty
+}
+
+// Snapshot Consolidated depth, bids descending and asks ascending
+type Snapshot struct {
+	Pair  goex.CurrencyPair
+	Bids  []Level
+	Asks  []Level
+	Stale []string // Venues excluded because their depth is stale or missing
+	Time  time.Time
+}
+
+// BBO Best bid and offer across fresh venues, an empty side has a zero price.
+// Bid above Ask means the venues are crossed
+type BBO struct {
+	Pair goex.CurrencyPair
+	Bid  Level
+	Ask  Level
+	Time time.Time
+	Seq  uint64 // Increases with every change, handlers receive BBOs in Seq order
+}
+
+func (b *BBO) Crossed() bool {
+	return b.Bid.Price.Sign() > 0 && b.Ask.Price.Sign() > 0 && b.Bid.Price.GreaterThan(b.Ask.Price)
+}
+
+func (b *BBO) equal(o *BBO) bool {
+	return b.Bid.Price.Equal(o.Bid.Price) && b.Bid.Amount.Equal(o.Bid.Amount) &&
+		b.Ask.Price.Equal(o.Ask.Price) && b.Ask.Amount.Equal(o.Ask.Amount)
+}
+
+// VenueStatus Freshness of one venue
+type VenueStatus struct {
+	Venue    string
+	Updated  time.Time // Local receive time of the latest depth
+	Exchange time.Time // Exchange time of the latest depth, zero when not provided
+	Stale    bool
+}
@@ -6,6 +6,8 @@ import (
 	//"log"
 	"net/http"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 const (
@@ -15,6 +17,8 @@ const (
 
 	TICKERS_URI = "GetMarkets"
 	TICKER_URI  = "GetMarket/"
+
+	TRADE_PAIRS_URI = "GetTradePairs"
 )
 
 type Cryptopia struct {
@@ -54,6 +58,50 @@ func (cta *Cryptopia) GetTickers(currency CurrencyPair) (*Ticker, error) {
 	//return nil, nil
 }
 
+/**
+ * 交易品种来自GetTradePairs, Label为"DOT/BTC"的格式, 价格固定为8位小数
+ */
+func (cta *Cryptopia) GetInstruments() ([]Instrument, error) {
+	var resp struct {
+		Success bool
+		Error   string
+		Data    []struct {
+			Label        string
+			Symbol       string
+			BaseSymbol   string
+			MinimumTrade decimal.Decimal
+			Status       string
+		}
+	}
+	err := HttpGet4(cta.httpClient, API_BASE_URL+TRADE_PAIRS_URI, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.Error)
+	}
+
+	ret := make([]Instrument, len(resp.Data))
+	for i, r := range resp.Data {
+		inst := Instrument{
+			Exchange:        EXCHANGE_NAME,
+			Symbol:          r.Label,
+			Pair:            NewCurrencyPair(NewCurrency(r.Symbol, ""), NewCurrency(r.BaseSymbol, "")),
+			Type:            INSTRUMENT_SPOT,
+			MinQty:          r.MinimumTrade,
+			PricePrecision:  8,
+			AmountPrecision: 8,
+			Status:          INSTRUMENT_STATUS_SUSPENDED,
+		}
+		if r.Status == "OK" {
+			inst.Status = INSTRUMENT_STATUS_TRADING
+		}
+		inst.FillPrecision()
+		ret[i] = inst
+	}
+	return ret, nil
+}
+
 func (cta *Cryptopia) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	currency = cta.adaptCurrencyPair(currency)
 
@@ -20,6 +20,26 @@ type Symbol struct {
 	}
 }
 
+func (this *Symbol) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Symbol: this.Symbol,
+		Pair:   goex.NewCurrencyPair2(this.Symbol),
+		Type:   goex.INSTRUMENT_SPOT,
+		MinQty: this.AmountMin,
+	}
+	for _, f := range this.Filters {
+		switch f.FilterType {
+		case "PRICE_FILTER":
+			inst.TickSize = f.TickSize
+		case "LOT_SIZE":
+			inst.LotSize = f.StepSize
+			inst.MinQty = f.MinQty
+		}
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 type OrderInfo struct {
 	Msg  string
 	Code decimal.Decimal
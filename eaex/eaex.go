@@ -162,6 +162,18 @@ func (ok *EAEX) GetSymbols() ([]Symbol, error) {
 	return ret, nil
 }
 
+func (ok *EAEX) GetInstruments() ([]Instrument, error) {
+	symbols, err := ok.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (this *EAEX) transSymbol(symbol string) string {
 	return strings.ToUpper(strings.Replace(symbol, "_", "", -1))
 }
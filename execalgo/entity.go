@@ -0,0 +1,149 @@
+package execalgo
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// OrderAPI Order methods the algorithms need, implemented by every goex.API
+type OrderAPI interface {
+	LimitBuy(amount, price string, currency goex.CurrencyPair) (*goex.Order, error)
+	LimitSell(amount, price string, currency goex.CurrencyPair) (*goex.Order, error)
+	CancelOrder(orderId string, currency goex.CurrencyPair) (bool, error)
+	GetOneOrder(orderId string, currency goex.CurrencyPair) (*goex.Order, error)
+}
+
+// Quoter Best bid and ask, used for child prices and the arrival price
+type Quoter interface {
+	Quote() (bid, ask decimal.Decimal, err error)
+}
+
+// QuoterFunc Adapt a function to Quoter
+type QuoterFunc func() (bid, ask decimal.Decimal, err error)
+
+func (f QuoterFunc) Quote() (bid, ask decimal.Decimal, err error) {
+	return f()
+}
+
+// TickerQuoter Quote through GetTicker of a connector
+func TickerQuoter(api goex.API, pair goex.CurrencyPair) Quoter {
+	return QuoterFunc(func() (decimal.Decimal, decimal.Decimal, error) {
+		ticker, err := api.GetTicker(pair)
+		if err != nil {
+			return decimal.Zero, decimal.Zero, err
+		}
+		return decimal.NewFromFloat(ticker.Buy), decimal.NewFromFloat(ticker.Sell), nil
+	})
+}
+
+var ErrNoQuote = errors.New("no quote available")
+
+// Parent Parent order, Side is BUY or SELL and Amount is in the base currency
+type Parent struct {
+	Pair       goex.CurrencyPair
+	Side       goex.TradeSide
+	Amount     decimal.Decimal
+	LimitPrice decimal.Decimal  // Worst child price, zero for no limit
+	Instrument *goex.Instrument // Optional, child orders are rounded and checked against its rules
+}
+
+func (p *Parent) buy() bool {
+	return p.Side == goex.BUY
+}
+
+// Progress Execution state reported after every fill and at the end
+type Progress struct {
+	Algo         string
+	Filled       decimal.Decimal
+	Remaining    decimal.Decimal
+	AvgPrice     decimal.Decimal // Volume weighted fill price, zero when nothing filled
+	ArrivalPrice decimal.Decimal // Mid price when the execution started
+	SlippageBps  decimal.Decimal // Fill price versus arrival in basis points, positive is worse
+	ChildOrders  int
+	Done         bool
+	Err          error // Set when the execution ended on an error
+}
+
+// DepthFeed Latest top of book from a depth stream, feed it with Handler or FloatHandler
+// through the connector's GetDepthWithWs. Implements Quoter
+type DepthFeed struct {
+	lock     sync.Mutex
+	bid, ask decimal.Decimal
+	updated  time.Time
+	handles  []func(bid, ask decimal.Decimal)
+}
+
+func NewDepthFeed() *DepthFeed {
+	return &DepthFeed{}
+}
+
+// OnDepth Update the top of book, the order of the lists does not matter
+func (f *DepthFeed) OnDepth(depth *goex.DepthDecimal) {
+	if depth == nil {
+		return
+	}
+	var bid, ask decimal.Decimal
+	for _, r := range depth.BidList {
+		if r.Amount.Sign() > 0 && r.Price.GreaterThan(bid) {
+			bid = r.Price
+		}
+	}
+	for _, r := range depth.AskList {
+		if r.Amount.Sign() > 0 && (ask.IsZero() || r.Price.LessThan(ask)) {
+			ask = r.Price
+		}
+	}
+
+	f.lock.Lock()
+	changed := !bid.Equal(f.bid) || !ask.Equal(f.ask)
+	f.bid, f.ask, f.updated = bid, ask, time.Now()
+	handles := f.handles
+	f.lock.Unlock()
+	if changed {
+		for _, handle := range handles {
+			handle(bid, ask)
+		}
+	}
+}
+
+// Handler Depth callback, the signature matches GetDepthWithWs handlers
+func (f *DepthFeed) Handler() func(*goex.DepthDecimal) {
+	return f.OnDepth
+}
+
+// FloatHandler Depth callback for connectors delivering float depth
+func (f *DepthFeed) FloatHandler() func(*goex.Depth) {
+	return func(depth *goex.Depth) {
+		if depth == nil {
+			return
+		}
+		d := &goex.DepthDecimal{}
+		for _, r := range depth.BidList {
+			d.BidList = append(d.BidList, goex.DepthRecordDecimal{Price: decimal.NewFromFloat(r.Price), Amount: decimal.NewFromFloat(r.Amount)})
+		}
+		for _, r := range depth.AskList {
+			d.AskList = append(d.AskList, goex.DepthRecordDecimal{Price: decimal.NewFromFloat(r.Price), Amount: decimal.NewFromFloat(r.Amount)})
+		}
+		f.OnDepth(d)
+	}
+}
+
+// SubscribeChange Register a handler for top of book changes
+func (f *DepthFeed) SubscribeChange(handle func(bid, ask decimal.Decimal)) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.handles = append(f.handles, handle)
+}
+
+func (f *DepthFeed) Quote() (decimal.Decimal, decimal.Decimal, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.updated.IsZero() {
+		return decimal.Zero, decimal.Zero, ErrNoQuote
+	}
+	return f.bid, f.ask, nil
+}
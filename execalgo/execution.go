@@ -0,0 +1,347 @@
+package execalgo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+var (
+	ErrStopped      = errors.New("execution stopped")
+	ErrCancelFailed = errors.New("child order still working after cancel")
+)
+
+// RemainderError The execution gave up on a remainder the instrument rules do not allow to place,
+// e.g. below the minimum quantity. Progress.Remaining is what is left unfilled
+type RemainderError struct {
+	Remaining decimal.Decimal
+	Rule      *goex.OrderRuleError
+}
+
+func (e *RemainderError) Error() string {
+	return fmt.Sprintf("remaining %s cannot be placed: %s", e.Remaining, e.Rule)
+}
+
+func (e *RemainderError) Unwrap() error {
+	return e.Rule
+}
+
+const CANCEL_CHECKS = 3 // Cancel attempts before the execution ends with ErrCancelFailed
+
+// Config Settings shared by all algorithms
+type Config struct {
+	PollInterval time.Duration // How often working child orders are queried
+}
+
+var DefaultConfig = Config{PollInterval: time.Second}
+
+type childFill struct {
+	deal     decimal.Decimal
+	notional decimal.Decimal
+}
+
+// Execution A running parent order. Child orders are placed as limit orders, queried until final
+// and cancelled when the algorithm moves on; fills are accounted from DealAmount and AvgPrice deltas
+type Execution struct {
+	Parent Parent
+	Config Config
+
+	api    OrderAPI
+	quoter Quoter
+
+	lock     sync.Mutex
+	progress Progress
+	notional decimal.Decimal
+	fills    map[string]*childFill
+	handles  []func(Progress)
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func newExecution(algo string, api OrderAPI, quoter Quoter, parent Parent, config Config) (*Execution, error) {
+	if (parent.Side != goex.BUY && parent.Side != goex.SELL) || parent.Amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid parent order %s %s: %w", parent.Side, parent.Amount, goex.ERR_INVALID_PARAM)
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultConfig.PollInterval
+	}
+	e := &Execution{
+		Parent: parent,
+		Config: config,
+		api:    api,
+		quoter: quoter,
+		fills:  make(map[string]*childFill),
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	e.progress = Progress{Algo: algo, Remaining: parent.Amount}
+	if quoter != nil {
+		if bid, ask, err := quoter.Quote(); err == nil && bid.Sign() > 0 && ask.Sign() > 0 {
+			e.progress.ArrivalPrice = bid.Add(ask).Div(decimal.New(2, 0))
+		}
+	}
+	return e, nil
+}
+
+// run Run the algorithm in the background, working orders left by it are cancelled
+func (e *Execution) run(algo func() error) {
+	go func() {
+		err := algo()
+		if errors.Is(err, ErrStopped) {
+			err = nil
+		}
+		e.lock.Lock()
+		e.progress.Done = true
+		e.progress.Err = err
+		p := e.progress
+		handles := e.handles
+		e.lock.Unlock()
+		for _, handle := range handles {
+			handle(p)
+		}
+		close(e.done)
+	}()
+}
+
+// SubscribeProgress Register a handler called after every fill and when the execution ends
+func (e *Execution) SubscribeProgress(handle func(Progress)) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.handles = append(e.handles, handle)
+}
+
+// Progress Current progress
+func (e *Execution) Progress() Progress {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.progress
+}
+
+// Stop Cancel the working child order and end the execution, already filled amounts are kept
+func (e *Execution) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+	})
+}
+
+// Wait Block until the execution ends and return the final progress
+func (e *Execution) Wait() Progress {
+	<-e.done
+	return e.Progress()
+}
+
+func (e *Execution) stopped() bool {
+	select {
+	case <-e.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep Wait for d, returns ErrStopped when stopped meanwhile
+func (e *Execution) sleep(d time.Duration) error {
+	if d <= 0 {
+		if e.stopped() {
+			return ErrStopped
+		}
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-e.stopCh:
+		return ErrStopped
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (e *Execution) remaining() decimal.Decimal {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.progress.Remaining
+}
+
+// withinLimit Cap a child price by the parent limit price
+func (e *Execution) withinLimit(price decimal.Decimal) decimal.Decimal {
+	limit := e.Parent.LimitPrice
+	if limit.Sign() <= 0 {
+		return price
+	}
+	if e.Parent.buy() {
+		return decimal.Min(price, limit)
+	}
+	return decimal.Max(price, limit)
+}
+
+// marketablePrice Price crossing the spread, capped by the limit price
+func (e *Execution) marketablePrice() (decimal.Decimal, error) {
+	if e.quoter == nil {
+		return decimal.Zero, ErrNoQuote
+	}
+	bid, ask, err := e.quoter.Quote()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	price := bid
+	if e.Parent.buy() {
+		price = ask
+	}
+	if price.Sign() <= 0 {
+		return decimal.Zero, ErrNoQuote
+	}
+	return e.withinLimit(price), nil
+}
+
+func orderId(o *goex.Order) string {
+	if o.OrderID2 != "" {
+		return o.OrderID2
+	}
+	return strconv.Itoa(o.OrderID)
+}
+
+func isFinal(o *goex.Order) bool {
+	return o.Status == goex.ORDER_FINISH || o.Status == goex.ORDER_CANCEL || o.Status == goex.ORDER_REJECT
+}
+
+// place Place a child limit order, returns a *RemainderError when the amount is below the instrument rules
+func (e *Execution) place(price, amount decimal.Decimal) (*goex.Order, error) {
+	if inst := e.Parent.Instrument; inst != nil {
+		var err error
+		price, amount, err = inst.PrepareLimitOrder(e.Parent.Side, price, amount)
+		var ruleErr *goex.OrderRuleError
+		if errors.As(err, &ruleErr) && (ruleErr.Rule == goex.RULE_MIN_QTY || ruleErr.Rule == goex.RULE_AMOUNT || ruleErr.Rule == goex.RULE_MIN_NOTIONAL) {
+			return nil, &RemainderError{Remaining: e.remaining(), Rule: ruleErr}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	var order *goex.Order
+	var err error
+	if e.Parent.buy() {
+		order, err = e.api.LimitBuy(amount.String(), price.String(), e.Parent.Pair)
+	} else {
+		order, err = e.api.LimitSell(amount.String(), price.String(), e.Parent.Pair)
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.lock.Lock()
+	e.progress.ChildOrders++
+	e.lock.Unlock()
+	e.record(order)
+	return order, nil
+}
+
+// record Account the fill delta of a child order and notify progress handlers
+func (e *Execution) record(order *goex.Order) {
+	if order == nil {
+		return
+	}
+	deal := decimal.NewFromFloat(order.DealAmount)
+	notional := deal.Mul(decimal.NewFromFloat(order.AvgPrice))
+
+	e.lock.Lock()
+	id := orderId(order)
+	f, ok := e.fills[id]
+	if !ok {
+		f = &childFill{}
+		e.fills[id] = f
+	}
+	if !deal.GreaterThan(f.deal) {
+		e.lock.Unlock()
+		return
+	}
+	p := &e.progress
+	p.Filled = p.Filled.Add(deal.Sub(f.deal))
+	e.notional = e.notional.Add(notional.Sub(f.notional))
+	f.deal, f.notional = deal, notional
+	p.Remaining = decimal.Max(e.Parent.Amount.Sub(p.Filled), decimal.Zero)
+	p.AvgPrice = e.notional.Div(p.Filled)
+	if p.ArrivalPrice.Sign() > 0 {
+		p.SlippageBps = p.AvgPrice.Sub(p.ArrivalPrice).Div(p.ArrivalPrice).Mul(decimal.New(10000, 0))
+		if !e.Parent.buy() {
+			p.SlippageBps = p.SlippageBps.Neg()
+		}
+	}
+	progress := *p
+	handles := e.handles
+	e.lock.Unlock()
+
+	for _, handle := range handles {
+		handle(progress)
+	}
+}
+
+// refresh Query a child order and account its fills, the known state is returned on query errors
+func (e *Execution) refresh(order *goex.Order) *goex.Order {
+	latest, err := e.api.GetOneOrder(orderId(order), e.Parent.Pair)
+	if err != nil || latest == nil {
+		return order
+	}
+	if latest.OrderID2 == "" && latest.OrderID == 0 {
+		latest.OrderID2, latest.OrderID = order.OrderID2, order.OrderID
+	}
+	e.record(latest)
+	return latest
+}
+
+// cancel Cancel a child order unless it is final and account the last fills. The cancel is retried
+// until the order shows up final; an error is returned while it may still be working, so callers
+// never place a replacement next to a live order
+func (e *Execution) cancel(order *goex.Order) (*goex.Order, error) {
+	if order == nil || isFinal(order) {
+		return order, nil
+	}
+	var cancelErr error
+	for i := 0; i < CANCEL_CHECKS; i++ {
+		if i > 0 {
+			time.Sleep(e.Config.PollInterval)
+		}
+		if _, err := e.api.CancelOrder(orderId(order), e.Parent.Pair); err != nil {
+			cancelErr = err
+		}
+		if order = e.refresh(order); isFinal(order) {
+			return order, nil
+		}
+	}
+	if cancelErr != nil {
+		return order, fmt.Errorf("child order %s: %w: %v", orderId(order), ErrCancelFailed, cancelErr)
+	}
+	return order, fmt.Errorf("child order %s: %w", orderId(order), ErrCancelFailed)
+}
+
+// work Query a child order until it is final, timeout passes or changed is signalled.
+// The order is left working, callers cancel it when moving on
+func (e *Execution) work(order *goex.Order, timeout time.Duration, changed <-chan struct{}) (*goex.Order, error) {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	ticker := time.NewTicker(e.Config.PollInterval)
+	defer ticker.Stop()
+	for !isFinal(order) {
+		select {
+		case <-e.stopCh:
+			return order, ErrStopped
+		case <-deadline:
+			return order, nil
+		case <-changed:
+			return order, nil
+		case <-ticker.C:
+			order = e.refresh(order)
+		}
+	}
+	return order, nil
+}
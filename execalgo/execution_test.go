@@ -0,0 +1,252 @@
+package execalgo
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+	"github.com/stephenlyu/GoEx/internal/testapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func newFakeAPI(fill bool) *testapi.FakeAPI {
+	return &testapi.FakeAPI{Fill: fill}
+}
+
+func fixedQuoter(bid, ask string) Quoter {
+	return QuoterFunc(func() (decimal.Decimal, decimal.Decimal, error) {
+		return dec(bid), dec(ask), nil
+	})
+}
+
+var testConfig = Config{PollInterval: time.Millisecond}
+
+func TestRunTWAP(t *testing.T) {
+	api := newFakeAPI(true)
+	parent := Parent{Pair: goex.BTC_USDT, Side: goex.BUY, Amount: dec("3")}
+	e, err := RunTWAP(api, fixedQuoter("99", "101"), parent, ScheduleConfig{Config: testConfig, Duration: 30 * time.Millisecond, Slices: 3})
+	assert.Nil(t, err)
+
+	var updates int
+	var lock sync.Mutex
+	e.SubscribeProgress(func(p Progress) {
+		lock.Lock()
+		defer lock.Unlock()
+		updates++
+	})
+	p := e.Wait()
+	assert.Nil(t, p.Err)
+	assert.True(t, p.Done)
+	assert.Equal(t, []string{"1@101", "1@101", "1@101"}, api.Placed())
+	assert.Equal(t, 3, p.ChildOrders)
+	assert.Equal(t, "3", p.Filled.String())
+	assert.True(t, p.Remaining.IsZero())
+	assert.Equal(t, "101", p.AvgPrice.String())
+	assert.Equal(t, "100", p.ArrivalPrice.String())
+	assert.Equal(t, "100", p.SlippageBps.String())
+	lock.Lock()
+	assert.True(t, updates > 0)
+	lock.Unlock()
+
+	// Sells are priced at the bid and capped by the limit price, paying less than arrival is worse
+	api = newFakeAPI(true)
+	parent = Parent{Pair: goex.BTC_USDT, Side: goex.SELL, Amount: dec("2"), LimitPrice: dec("99.5")}
+	e, err = RunTWAP(api, fixedQuoter("99", "101"), parent, ScheduleConfig{Config: testConfig, Duration: 20 * time.Millisecond, Slices: 2})
+	assert.Nil(t, err)
+	p = e.Wait()
+	assert.Equal(t, []string{"1@99.5", "1@99.5"}, api.Placed())
+	assert.Equal(t, "50", p.SlippageBps.String())
+
+	_, err = RunTWAP(api, nil, Parent{Side: goex.BUY_MARKET, Amount: dec("1")}, ScheduleConfig{Duration: time.Second, Slices: 1})
+	assert.ErrorIs(t, err, goex.ERR_INVALID_PARAM)
+	_, err = RunTWAP(api, nil, parent, ScheduleConfig{Duration: time.Second})
+	assert.ErrorIs(t, err, goex.ERR_INVALID_PARAM)
+}
+
+func TestExecution_Stop(t *testing.T) {
+	api := newFakeAPI(false)
+	parent := Parent{Pair: goex.BTC_USDT, Side: goex.BUY, Amount: dec("2")}
+	e, err := RunTWAP(api, fixedQuoter("99", "101"), parent, ScheduleConfig{Config: testConfig, Duration: time.Hour, Slices: 2})
+	assert.Nil(t, err)
+	for len(api.Placed()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	e.Stop()
+	p := e.Wait()
+	assert.Nil(t, p.Err)
+	assert.True(t, p.Done)
+	assert.True(t, p.Filled.IsZero())
+	assert.Equal(t, []string{"1"}, api.Cancelled())
+}
+
+func TestExecution_CancelFailed(t *testing.T) {
+	parent := Parent{Pair: goex.BTC_USDT, Side: goex.BUY, Amount: dec("2")}
+	config := ScheduleConfig{Config: testConfig, Duration: 20 * time.Millisecond, Slices: 2}
+
+	// The next slice is not placed while the previous child may still fill
+	api := newFakeAPI(false)
+	api.CancelErr = errors.New("timeout")
+	e, err := RunTWAP(api, fixedQuoter("99", "101"), parent, config)
+	assert.Nil(t, err)
+	p := e.Wait()
+	assert.ErrorIs(t, p.Err, ErrCancelFailed)
+	assert.Equal(t, []string{"1@101"}, api.Placed())
+	assert.Equal(t, []string{"1", "1", "1"}, api.Cancelled())
+
+	api = newFakeAPI(false)
+	api.Stuck = true
+	e, err = RunTWAP(api, fixedQuoter("99", "101"), parent, config)
+	assert.Nil(t, err)
+	p = e.Wait()
+	assert.ErrorIs(t, p.Err, ErrCancelFailed)
+	assert.Equal(t, []string{"1@101"}, api.Placed())
+
+	// The peg is not replaced while the working order does not go away
+	api = newFakeAPI(false)
+	api.Stuck = true
+	feed := NewDepthFeed()
+	feed.OnDepth(&goex.DepthDecimal{
+		BidList: goex.DepthRecordsDecimal{{Price: dec("99"), Amount: dec("1")}},
+		AskList: goex.DepthRecordsDecimal{{Price: dec("101"), Amount: dec("1")}},
+	})
+	e, err = RunPegged(api, feed, parent, PegConfig{Config: testConfig})
+	assert.Nil(t, err)
+	for len(api.Placed()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	feed.OnDepth(&goex.DepthDecimal{
+		BidList: goex.DepthRecordsDecimal{{Price: dec("100"), Amount: dec("1")}},
+		AskList: goex.DepthRecordsDecimal{{Price: dec("101"), Amount: dec("1")}},
+	})
+	p = e.Wait()
+	assert.ErrorIs(t, p.Err, ErrCancelFailed)
+	assert.Equal(t, []string{"2@99"}, api.Placed())
+}
+
+func TestVolumeProfile(t *testing.T) {
+	start := time.Unix(1000, 0)
+	ms := func(d time.Duration) int64 {
+		return start.Add(d).UnixNano() / int64(time.Millisecond)
+	}
+	trades := []goex.Trade{
+		{Amount: 1, Date: ms(10 * time.Second)},
+		{Amount: 2, Date: ms(50 * time.Second)},
+		// The same time of the previous cycle
+		{Amount: 1, Date: ms(50*time.Second - time.Hour)},
+		// Outside of the window
+		{Amount: 5, Date: ms(2 * time.Minute)},
+	}
+	weights := VolumeProfile(trades, start, time.Minute, 2, time.Hour)
+	assert.Equal(t, []string{"0.25", "0.75"}, []string{weights[0].String(), weights[1].String()})
+
+	weights = VolumeProfile(nil, start, time.Minute, 4, time.Hour)
+	assert.Equal(t, "0.25", weights[3].String())
+
+	klines := []goex.KlineDecimal{
+		{Timestamp: start.Add(-24 * time.Hour).Unix(), Vol: dec("3")},
+		{Timestamp: start.Add(-23 * time.Hour).Unix(), Vol: dec("1")},
+		{Timestamp: start.Add(-22 * time.Hour).Unix(), Vol: dec("7")},
+	}
+	weights = KlineVolumeProfile(klines, start, 2*time.Hour, 2, 24*time.Hour)
+	assert.Equal(t, []string{"0.75", "0.25"}, []string{weights[0].String(), weights[1].String()})
+}
+
+type fakeKlineAPI struct {
+	period, size, since int
+	klines              []goex.KlineDecimal
+}
+
+func (api *fakeKlineAPI) GetKlineRecordsDecimal(currency goex.CurrencyPair, period, size, since int) ([]goex.KlineDecimal, error) {
+	api.period, api.size, api.since = period, size, since
+	return api.klines, nil
+}
+
+func TestRunVWAP(t *testing.T) {
+	config := ScheduleConfig{Duration: 2 * time.Hour, Slices: 4}
+	assert.Nil(t, config.validate())
+	assert.Equal(t, goex.KLINE_PERIOD_30MIN, config.KlinePeriod)
+	config = ScheduleConfig{Duration: time.Hour, Slices: 2, KlinePeriod: 100}
+	assert.ErrorIs(t, config.validate(), goex.ERR_INVALID_PARAM)
+
+	api := newFakeAPI(true)
+	klines := &fakeKlineAPI{}
+	parent := Parent{Pair: goex.BTC_USDT, Side: goex.BUY, Amount: dec("2")}
+	start := time.Now()
+	e, err := RunVWAP(api, fixedQuoter("99", "101"), klines, parent, ScheduleConfig{Config: testConfig, Duration: 20 * time.Millisecond, Slices: 2})
+	assert.Nil(t, err)
+	p := e.Wait()
+	assert.Nil(t, p.Err)
+	assert.Equal(t, []string{"1@101", "1@101"}, api.Placed())
+	// The window one cycle ago in 1 minute klines
+	assert.Equal(t, goex.KLINE_PERIOD_1MIN, klines.period)
+	assert.Equal(t, 1, klines.size)
+	assert.InDelta(t, start.Add(-DEFAULT_PROFILE_CYCLE).UnixNano()/int64(time.Millisecond), klines.since, 1000)
+}
+
+func TestRunIceberg(t *testing.T) {
+	api := newFakeAPI(true)
+	parent := Parent{Pair: goex.BTC_USDT, Side: goex.SELL, Amount: dec("2.5")}
+	e, err := RunIceberg(api, nil, parent, IcebergConfig{Config: testConfig, Visible: dec("1"), Price: dec("100")})
+	assert.Nil(t, err)
+	p := e.Wait()
+	assert.Nil(t, p.Err)
+	assert.Equal(t, []string{"1@100", "1@100", "0.5@100"}, api.Placed())
+	assert.Equal(t, "2.5", p.Filled.String())
+	assert.True(t, p.ArrivalPrice.IsZero())
+
+	_, err = RunIceberg(api, nil, parent, IcebergConfig{Visible: dec("1")})
+	assert.ErrorIs(t, err, goex.ERR_INVALID_PARAM)
+
+	// A remainder below the minimum quantity ends the execution with a RemainderError
+	api = newFakeAPI(true)
+	parent.Instrument = &goex.Instrument{Symbol: "BTCUSDT", TickSize: dec("0.01"), LotSize: dec("0.1"), MinQty: dec("1")}
+	e, err = RunIceberg(api, nil, parent, IcebergConfig{Config: testConfig, Visible: dec("1"), Price: dec("100")})
+	assert.Nil(t, err)
+	p = e.Wait()
+	var remainderErr *RemainderError
+	assert.True(t, errors.As(p.Err, &remainderErr))
+	assert.Equal(t, "0.5", remainderErr.Remaining.String())
+	assert.Equal(t, goex.RULE_MIN_QTY, remainderErr.Rule.Rule)
+	assert.ErrorIs(t, p.Err, goex.ERR_INVALID_PARAM)
+	assert.Equal(t, []string{"1@100", "1@100"}, api.Placed())
+	assert.Equal(t, "0.5", p.Remaining.String())
+}
+
+func TestRunPegged(t *testing.T) {
+	api := newFakeAPI(false)
+	feed := NewDepthFeed()
+	feed.OnDepth(&goex.DepthDecimal{
+		BidList: goex.DepthRecordsDecimal{{Price: dec("99"), Amount: dec("1")}},
+		AskList: goex.DepthRecordsDecimal{{Price: dec("101"), Amount: dec("1")}},
+	})
+	parent := Parent{Pair: goex.BTC_USDT, Side: goex.BUY, Amount: dec("1")}
+	e, err := RunPegged(api, feed, parent, PegConfig{Config: testConfig})
+	assert.Nil(t, err)
+	for len(api.Placed()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The bid moves up, the working order is replaced at the new best bid
+	feed.OnDepth(&goex.DepthDecimal{
+		BidList: goex.DepthRecordsDecimal{{Price: dec("100"), Amount: dec("1")}},
+		AskList: goex.DepthRecordsDecimal{{Price: dec("101"), Amount: dec("1")}},
+	})
+	for len(api.Placed()) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	api.SetFill(true)
+	p := e.Wait()
+	assert.Nil(t, p.Err)
+	assert.Equal(t, []string{"1@99", "1@100"}, api.Placed())
+	assert.Equal(t, "1", p.Filled.String())
+	assert.Equal(t, "100", p.AvgPrice.String())
+	assert.Equal(t, "100", p.ArrivalPrice.String())
+	assert.True(t, p.SlippageBps.IsZero())
+}
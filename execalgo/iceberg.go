@@ -0,0 +1,58 @@
+package execalgo
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// IcebergConfig Client-side iceberg settings, only Visible is shown on the book at a time
+type IcebergConfig struct {
+	Config
+	Visible decimal.Decimal
+	Price   decimal.Decimal // Limit price of every slice, zero to take the parent LimitPrice
+}
+
+// RunIceberg Execute a parent order as a resting limit order of which only the visible slice is placed,
+// a new slice is placed whenever the previous one is completely filled
+func RunIceberg(api OrderAPI, quoter Quoter, parent Parent, config IcebergConfig) (*Execution, error) {
+	if config.Price.Sign() <= 0 {
+		config.Price = parent.LimitPrice
+	}
+	if config.Visible.Sign() <= 0 || config.Price.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid iceberg %s@%s: %w", config.Visible, config.Price, goex.ERR_INVALID_PARAM)
+	}
+	e, err := newExecution("ICEBERG", api, quoter, parent, config.Config)
+	if err != nil {
+		return nil, err
+	}
+	e.run(func() error {
+		return e.iceberg(config)
+	})
+	return e, nil
+}
+
+func (e *Execution) iceberg(config IcebergConfig) error {
+	price := e.withinLimit(config.Price)
+	for {
+		remaining := e.remaining()
+		if remaining.Sign() <= 0 {
+			return nil
+		}
+		order, err := e.place(price, decimal.Min(config.Visible, remaining))
+		if err != nil {
+			return err
+		}
+		order, err = e.work(order, 0, nil)
+		if err != nil {
+			if _, cancelErr := e.cancel(order); cancelErr != nil {
+				return cancelErr
+			}
+			return err
+		}
+		if order.Status != goex.ORDER_FINISH {
+			return fmt.Errorf("child order %s ended %s", orderId(order), order.Status)
+		}
+	}
+}
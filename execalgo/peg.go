@@ -0,0 +1,128 @@
+package execalgo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// PegConfig Pegged order settings
+type PegConfig struct {
+	Config
+	Offset      decimal.Decimal // Distance into the spread from the best bid (buy) or ask (sell), may be negative
+	MinInterval time.Duration   // Minimum time between two replacements
+}
+
+// RunPegged Execute a parent order as a limit order pegged to the best bid (buy) or ask (sell) of a depth feed.
+// Connectors have no amend, so the working order is cancelled and replaced with the remaining amount
+// when the peg price moves. The peg never crosses the spread and respects the parent LimitPrice
+func RunPegged(api OrderAPI, feed *DepthFeed, parent Parent, config PegConfig) (*Execution, error) {
+	if feed == nil || config.MinInterval < 0 {
+		return nil, fmt.Errorf("invalid peg: %w", goex.ERR_INVALID_PARAM)
+	}
+	e, err := newExecution("PEG", api, feed, parent, config.Config)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(chan struct{}, 1)
+	feed.SubscribeChange(func(bid, ask decimal.Decimal) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	e.run(func() error {
+		return e.peg(feed, config, changed)
+	})
+	return e, nil
+}
+
+// pegPrice Peg price for the current top of book, false when the book side is empty
+func (e *Execution) pegPrice(feed *DepthFeed, offset decimal.Decimal) (decimal.Decimal, bool) {
+	bid, ask, err := feed.Quote()
+	if err != nil {
+		return decimal.Zero, false
+	}
+	var price decimal.Decimal
+	if e.Parent.buy() {
+		if bid.Sign() <= 0 {
+			return decimal.Zero, false
+		}
+		price = bid.Add(offset)
+		if ask.Sign() > 0 && !price.LessThan(ask) {
+			price = bid
+		}
+	} else {
+		if ask.Sign() <= 0 {
+			return decimal.Zero, false
+		}
+		price = ask.Sub(offset)
+		if !price.GreaterThan(bid) {
+			price = ask
+		}
+	}
+	return e.withinLimit(price), price.Sign() > 0
+}
+
+func (e *Execution) peg(feed *DepthFeed, config PegConfig, changed <-chan struct{}) error {
+	var lastPlaced time.Time
+	for {
+		remaining := e.remaining()
+		if remaining.Sign() <= 0 {
+			return nil
+		}
+		price, ok := e.pegPrice(feed, config.Offset)
+		if !ok {
+			if err := e.waitChange(changed, e.Config.PollInterval); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := e.sleep(time.Until(lastPlaced.Add(config.MinInterval))); err != nil {
+			return err
+		}
+		order, err := e.place(price, remaining)
+		if err != nil {
+			return err
+		}
+		lastPlaced = time.Now()
+
+		for {
+			order, err = e.work(order, 0, changed)
+			if err != nil {
+				if _, cancelErr := e.cancel(order); cancelErr != nil {
+					return cancelErr
+				}
+				return err
+			}
+			if isFinal(order) {
+				break
+			}
+			if next, ok := e.pegPrice(feed, config.Offset); ok && !next.Equal(price) {
+				// The replacement is placed only once the old order is final
+				if order, err = e.cancel(order); err != nil {
+					return err
+				}
+				break
+			}
+		}
+		if order.Status == goex.ORDER_REJECT {
+			return fmt.Errorf("child order %s ended %s", orderId(order), order.Status)
+		}
+	}
+}
+
+// waitChange Wait for a signal on changed, at most timeout
+func (e *Execution) waitChange(changed <-chan struct{}, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-e.stopCh:
+		return ErrStopped
+	case <-changed:
+	case <-timer.C:
+	}
+	return nil
+}
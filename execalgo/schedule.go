@@ -0,0 +1,233 @@
+package execalgo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+const DEFAULT_PROFILE_CYCLE = 24 * time.Hour
+
+// ScheduleConfig TWAP and VWAP settings. The parent is split into Slices over Duration, every slice tops the
+// filled amount up to its cumulative target with a marketable limit order that is cancelled after SliceTimeout
+type ScheduleConfig struct {
+	Config
+	Duration     time.Duration
+	Slices       int
+	SliceTimeout time.Duration // Zero or longer than a slice means until the next slice
+	Cycle        time.Duration // VWAP only, period of the volume profile, DEFAULT_PROFILE_CYCLE when zero
+	KlinePeriod  int           // VWAP only, goex.KLINE_PERIOD_* of the profile, the longest one within a slice when zero
+}
+
+func (c *ScheduleConfig) validate() error {
+	if c.Duration <= 0 || c.Slices <= 0 {
+		return fmt.Errorf("invalid schedule %s/%d: %w", c.Duration, c.Slices, goex.ERR_INVALID_PARAM)
+	}
+	interval := c.Duration / time.Duration(c.Slices)
+	if c.SliceTimeout <= 0 || c.SliceTimeout > interval {
+		c.SliceTimeout = interval
+	}
+	if c.Cycle <= 0 {
+		c.Cycle = DEFAULT_PROFILE_CYCLE
+	}
+	if c.KlinePeriod == 0 {
+		c.KlinePeriod = goex.KLINE_PERIOD_1MIN
+		for period := goex.KLINE_PERIOD_1MIN; period <= goex.KLINE_PERIOD_1DAY; period++ {
+			if goex.KlinePeriodDuration(period) <= interval {
+				c.KlinePeriod = period
+			}
+		}
+	}
+	if goex.KlinePeriodDuration(c.KlinePeriod) <= 0 {
+		return fmt.Errorf("invalid kline period %d: %w", c.KlinePeriod, goex.ERR_INVALID_PARAM)
+	}
+	return nil
+}
+
+// KlineAPI REST klines of a connector, used for the VWAP volume profile
+type KlineAPI interface {
+	GetKlineRecordsDecimal(currency goex.CurrencyPair, period, size, since int) ([]goex.KlineDecimal, error)
+}
+
+// RunTWAP Execute a parent order in equal slices over time
+func RunTWAP(api OrderAPI, quoter Quoter, parent Parent, config ScheduleConfig) (*Execution, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	e, err := newExecution("TWAP", api, quoter, parent, config.Config)
+	if err != nil {
+		return nil, err
+	}
+	weights := make([]decimal.Decimal, config.Slices)
+	for i := range weights {
+		weights[i] = decimal.New(1, 0)
+	}
+	e.run(func() error {
+		return e.schedule(weights, config)
+	})
+	return e, nil
+}
+
+// RunVWAP Execute a parent order in slices weighted by the historical volume of the same time within
+// the profile cycle. The profile is built from the klines covering the execution window one cycle ago
+func RunVWAP(api OrderAPI, quoter Quoter, klines KlineAPI, parent Parent, config ScheduleConfig) (*Execution, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	period := goex.KlinePeriodDuration(config.KlinePeriod)
+	window := config.Duration
+	if window > config.Cycle {
+		window = config.Cycle
+	}
+	size := int((window + period - 1) / period)
+	since := now.Add(-config.Cycle).UnixNano() / int64(time.Millisecond)
+	history, err := klines.GetKlineRecordsDecimal(parent.Pair, config.KlinePeriod, size, int(since))
+	if err != nil {
+		return nil, err
+	}
+	weights := KlineVolumeProfile(history, now, config.Duration, config.Slices, config.Cycle)
+
+	e, err := newExecution("VWAP", api, quoter, parent, config.Config)
+	if err != nil {
+		return nil, err
+	}
+	e.run(func() error {
+		return e.schedule(weights, config)
+	})
+	return e, nil
+}
+
+// VolumeProfile Slice weights for an execution window from historical trades. Trade times are folded
+// into one cycle, e.g. the time of day, and summed per slice of the window; the weights add up to 1.
+// Equal weights are returned when no trade falls into the window
+func VolumeProfile(trades []goex.Trade, start time.Time, duration time.Duration, slices int, cycle time.Duration) []decimal.Decimal {
+	p := newProfile(start, duration, slices, cycle)
+	for _, t := range trades {
+		if t.Amount > 0 {
+			p.add(t.Date, decimal.NewFromFloat(t.Amount))
+		}
+	}
+	return p.weights()
+}
+
+// KlineVolumeProfile Same as VolumeProfile with the volume of every kline taken at its open time
+func KlineVolumeProfile(klines []goex.KlineDecimal, start time.Time, duration time.Duration, slices int, cycle time.Duration) []decimal.Decimal {
+	p := newProfile(start, duration, slices, cycle)
+	for _, k := range klines {
+		if k.Vol.Sign() > 0 {
+			p.add(k.Timestamp*1000, k.Vol)
+		}
+	}
+	return p.weights()
+}
+
+// profile Volumes per slice of an execution window, times in ms
+type profile struct {
+	startMs, durationMs, cycleMs int64
+	volumes                      []decimal.Decimal
+	total                        decimal.Decimal
+}
+
+func newProfile(start time.Time, duration time.Duration, slices int, cycle time.Duration) *profile {
+	if slices < 0 {
+		slices = 0
+	}
+	return &profile{
+		startMs:    start.UnixNano() / int64(time.Millisecond),
+		durationMs: int64(duration / time.Millisecond),
+		cycleMs:    int64(cycle / time.Millisecond),
+		volumes:    make([]decimal.Decimal, slices),
+		total:      decimal.Zero,
+	}
+}
+
+func (p *profile) add(ms int64, amount decimal.Decimal) {
+	if p.durationMs <= 0 || p.cycleMs <= 0 || len(p.volumes) == 0 {
+		return
+	}
+	offset := ((ms-p.startMs)%p.cycleMs + p.cycleMs) % p.cycleMs
+	if offset >= p.durationMs {
+		return
+	}
+	i := int(offset * int64(len(p.volumes)) / p.durationMs)
+	p.volumes[i] = p.volumes[i].Add(amount)
+	p.total = p.total.Add(amount)
+}
+
+func (p *profile) weights() []decimal.Decimal {
+	if len(p.volumes) == 0 {
+		return nil
+	}
+	if p.total.Sign() <= 0 {
+		weight := decimal.New(1, 0).Div(decimal.New(int64(len(p.volumes)), 0))
+		for i := range p.volumes {
+			p.volumes[i] = weight
+		}
+		return p.volumes
+	}
+	for i := range p.volumes {
+		p.volumes[i] = p.volumes[i].Div(p.total)
+	}
+	return p.volumes
+}
+
+// schedule Work the slices, amounts a slice could not fill roll over to the next one
+func (e *Execution) schedule(weights []decimal.Decimal, config ScheduleConfig) error {
+	total := decimal.Zero
+	for _, w := range weights {
+		total = total.Add(w)
+	}
+	if total.Sign() <= 0 {
+		return fmt.Errorf("empty schedule: %w", goex.ERR_INVALID_PARAM)
+	}
+
+	interval := config.Duration / time.Duration(len(weights))
+	start := time.Now()
+	cumulative := decimal.Zero
+	for i, w := range weights {
+		if err := e.sleep(time.Until(start.Add(interval * time.Duration(i)))); err != nil {
+			return err
+		}
+		cumulative = cumulative.Add(w)
+		target := e.Parent.Amount
+		if i < len(weights)-1 {
+			target = e.Parent.Amount.Mul(cumulative).Div(total)
+		}
+		e.lock.Lock()
+		need := target.Sub(e.progress.Filled)
+		e.lock.Unlock()
+		if need.Sign() <= 0 {
+			continue
+		}
+
+		price, err := e.marketablePrice()
+		if err != nil {
+			// Skipped slices roll over, only the last one fails the execution
+			if i == len(weights)-1 {
+				return err
+			}
+			continue
+		}
+		order, err := e.place(price, need)
+		var remainderErr *RemainderError
+		if errors.As(err, &remainderErr) && i < len(weights)-1 {
+			// Too small for a child order, rolls over to the next slice
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		order, err = e.work(order, config.SliceTimeout, nil)
+		if _, cancelErr := e.cancel(order); cancelErr != nil {
+			return cancelErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -27,6 +27,7 @@ const (
 	TICKER = "/v1/market/history/kline24h"
 	DEPTH = "/v1/market/depth"
 	TRADE = "/v1/market/history/trade"
+	KLINE = "/v1/market/history/kline"
 	ACCOUNTS = "/v1/api/account/wallet"
 	PLACE_ORDER = "/v1/api/spot/orders"
 	BATCH_PLACE_ORDERS = "/v1/api/spot/orders_list"
@@ -36,6 +37,11 @@ const (
 	QUERY_ORDER = "/v1/api/spot/orderdetail"
 )
 
+func init() {
+	// Fameex没有查询服务器时间的接口, 用行情接口响应的Date头估计
+	RegisterServerClock(NewServerClock(FAMEEX, DateHeaderTimeFetcher(API_BASE_URL + SYMBOL)))
+}
+
 type Fameex struct {
 	ApiKey string
 	SecretKey string
@@ -61,6 +67,7 @@ func NewFameex(client *http.Client, ApiKey string, SecretKey, userId string) *Fa
 	this.SecretKey = SecretKey
 	this.UserId = userId
 	this.client = client
+	SetServerClockHttpClient(FAMEEX, client)
 
 	return this
 }
@@ -94,7 +101,8 @@ func (this *Fameex) ensureSymbols() error {
 	return nil
 }
 
-func (this *Fameex) getPrecision(symbol string) (error, int) {
+// 交易对的价格精度, symbol如BTC_USDT
+func (this *Fameex) GetPrecision(symbol string) (error, int) {
 	err := this.ensureSymbols()
 	if err != nil {
 		return err, 0
@@ -139,7 +147,7 @@ func (this *Fameex) sign(method, reqUrl string, param map[string]string) string
 
 	message := strings.Join(lines, "\n")
 	sign := this.signData(message)
-	return data + "&Signature=" + url.QueryEscape(sign) + "&Timestamp=" + strconv.FormatInt(time.Now().Unix()*1000, 10)
+	return data + "&Signature=" + url.QueryEscape(sign) + "&Timestamp=" + strconv.FormatInt(ServerTime(FAMEEX).Unix()*1000, 10)
 }
 
 func (this *Fameex) buildQueryString(params map[string]string) string {
@@ -192,6 +200,18 @@ func (this *Fameex) GetSymbols() ([]Symbol, error) {
 	return ret, nil
 }
 
+func (this *Fameex) GetInstruments() ([]Instrument, error) {
+	symbols, err := this.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (this *Fameex) transSymbol(symbol string) string {
 	return strings.ToLower(strings.Replace(symbol, "_", "", -1))
 }
@@ -354,6 +374,75 @@ func (this *Fameex) GetTrades(symbol string) ([]TradeDecimal, error) {
 	return trades, nil
 }
 
+var klinePeriods = map[int]string {
+	KLINE_PERIOD_1MIN: "1min",
+	KLINE_PERIOD_5MIN: "5min",
+	KLINE_PERIOD_15MIN: "15min",
+	KLINE_PERIOD_30MIN: "30min",
+	KLINE_PERIOD_60MIN: "1hour",
+	KLINE_PERIOD_4H: "4hour",
+	KLINE_PERIOD_1DAY: "1day",
+	KLINE_PERIOD_1WEEK: "1week",
+	KLINE_PERIOD_1MONTH: "1mon",
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线.
+ * 接口只返回最近的K线, 更早的K线无法获取
+ */
+func (this *Fameex) GetKlineRecordsDecimal(pair CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	code, err := KlinePeriodCode(klinePeriods, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetAllKlineDecimals(int64(since), size, 300, func(q PageQuery) ([]KlineDecimal, error) {
+		params := map[string]string {}
+		queryString := this.sign("POST", KLINE, params)
+
+		reqUrl := API_BASE_URL + KLINE + "?" + queryString
+		postData := map[string]interface{} {
+			"symbol": pair.ToSymbol("-"),
+			"period": code,
+			"size": strconv.Itoa(q.Limit),
+		}
+		bytes, err := HttpPostForm4(this.client, reqUrl, postData, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var data struct {
+			Code int
+			Data [] struct {
+				Id int64
+				Open decimal.Decimal
+				Close decimal.Decimal
+				High decimal.Decimal
+				Low decimal.Decimal
+				Amount decimal.Decimal
+			}
+		}
+
+		err = json.Unmarshal(bytes, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		if data.Code != 200 {
+			return nil, fmt.Errorf("error_code: %d", data.Code)
+		}
+
+		klines := make([]KlineDecimal, 0, len(data.Data))
+		for _, o := range data.Data {
+			if q.From > 0 && o.Id * 1000 < q.From || q.To > 0 && o.Id * 1000 > q.To {
+				continue
+			}
+			klines = append(klines, KlineDecimal{Pair: pair, Timestamp: o.Id, Open: o.Open, Close: o.Close, High: o.High, Low: o.Low, Vol: o.Amount})
+		}
+		return klines, nil
+	})
+}
+
 func (this *Fameex) GetAccounts() ([]SubAccountDecimal, error) {
 	params := map[string]string {}
 	queryString := this.sign("GET", ACCOUNTS, params)
@@ -73,7 +73,7 @@ func (this *Fameex) createWsConn() {
 }
 
 func (this *Fameex) getLoginData() interface{} {
-	now := time.Now().UnixNano()
+	now := ServerTime(FAMEEX).UnixNano()
 	return map[string]interface{}{
 		"op":   "login",
 		"AccessKey": this.ApiKey,
@@ -110,7 +110,7 @@ func (this *Fameex) GetDepthWithWs(symbol string,
 	depthHandle func(*DepthDecimal),
 	tradesHandle func(string, []TradeDecimal),
 	orderHandle func([]OrderDecimal)) error {
-	err, precision := this.getPrecision(symbol)
+	err, precision := this.GetPrecision(symbol)
 	if err != nil {
 		return err
 	}
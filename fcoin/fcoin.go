@@ -1,6 +1,7 @@
 package fcoin
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/base64"
@@ -29,8 +30,8 @@ type FCoin struct {
 	baseUrl,
 	accessKey,
 	secretKey string
-	timeoffset   int64
 	tradeSymbols []TradeSymbol
+	logger       Logger
 
 	ws                *WsConn
 	createWsLock      sync.Mutex
@@ -52,6 +53,23 @@ type TradeSymbol struct {
 	Tradable      bool   `json:"tradable"`
 }
 
+func (this *TradeSymbol) ToInstrument() Instrument {
+	inst := Instrument{
+		Exchange:        FCOIN,
+		Symbol:          this.Name,
+		Pair:            NewCurrencyPair(NewCurrency(this.BaseCurrency, ""), NewCurrency(this.QuoteCurrency, "")),
+		Type:            INSTRUMENT_SPOT,
+		PricePrecision:  int32(this.PriceDecimal),
+		AmountPrecision: int32(this.AmountDecimal),
+		Status:          INSTRUMENT_STATUS_SUSPENDED,
+	}
+	if this.Tradable {
+		inst.Status = INSTRUMENT_STATUS_TRADING
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 type Asset struct {
 	Currency  Currency
 	Avaliable float64
@@ -63,7 +81,11 @@ type Asset struct {
 
 func NewFCoin(client *http.Client, apikey, secretkey string) *FCoin {
 	fc := &FCoin{baseUrl: "https://api.fcoin.com/v2/", accessKey: apikey, secretKey: secretkey, httpClient: client}
-	fc.setTimeOffset()
+	SetServerClockHttpClient(FCOIN, client)
+	// 后台同步, 不阻塞构造
+	if clock := GetServerClock(FCOIN); clock != nil {
+		clock.Start()
+	}
 	var err error
 	fc.tradeSymbols, err = fc.GetTradeSymbols()
 	if len(fc.tradeSymbols) == 0 || err != nil {
@@ -73,21 +95,36 @@ func NewFCoin(client *http.Client, apikey, secretkey string) *FCoin {
 	return fc
 }
 
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (fc *FCoin) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	fc.logger = logger
+}
+
+func (fc *FCoin) log() Logger {
+	return ExchangeLogger(fc.logger, FCOIN)
+}
+
 func (fc *FCoin) GetExchangeName() string {
 	return FCOIN
 }
 
-func (fc *FCoin) setTimeOffset() error {
-	respmap, err := HttpGet(fc.httpClient, fc.baseUrl+"public/server-time")
+// {"status":0,"data":1531966474519}
+func fetchServerTime(ctx context.Context, client *http.Client) (time.Time, error) {
+	respmap, err := HttpGetWithContext(ctx, client, "https://api.fcoin.com/v2/public/server-time")
 	if err != nil {
-		return err
+		return time.Time{}, err
 	}
 	stime := int64(ToInt(respmap["data"]))
-	st := time.Unix(stime/1000, 0)
-	lt := time.Now()
-	offset := st.Sub(lt).Seconds()
-	fc.timeoffset = int64(offset)
-	return nil
+	return time.Unix(0, stime*int64(time.Millisecond)), nil
+}
+
+func init() {
+	RegisterServerClock(NewServerClock(FCOIN, fetchServerTime))
 }
 
 func (fc *FCoin) GetTicker(currencyPair CurrencyPair) (*TickerDecimal, error) {
@@ -108,7 +145,7 @@ func (fc *FCoin) GetTicker(currencyPair CurrencyPair) (*TickerDecimal, error) {
 	}
 
 	if resp.Status != 0 {
-		return nil, errors.New(resp.Msg)
+		return nil, fcoinError(resp.Status, resp.Msg)
 	}
 
 	t := resp.Data.Ticker
@@ -148,7 +185,7 @@ func (fc *FCoin) GetDepth(size int, currency CurrencyPair) (*DepthDecimal, error
 	}
 
 	if resp.Status != 0 {
-		return nil, errors.New(resp.Msg)
+		return nil, fcoinError(resp.Status, resp.Msg)
 	}
 
 	bids := resp.Data.Bids
@@ -181,7 +218,7 @@ func (fc *FCoin) GetDepth(size int, currency CurrencyPair) (*DepthDecimal, error
 }
 
 func (fc *FCoin) getAuthenticatedHeader(method, uri string, params url.Values) map[string]string {
-	timestamp := time.Now().Unix()*1000 + fc.timeoffset*1000
+	timestamp := ServerTime(FCOIN).UnixNano() / int64(time.Millisecond)
 	sign := fc.buildSigned(method, fc.baseUrl+uri, timestamp, params)
 
 	return map[string]string{
@@ -219,7 +256,7 @@ func (fc *FCoin) doAuthenticatedRequest(method, uri string, params url.Values) (
 		json.Unmarshal(respbody, &respmap)
 	}
 	if ToInt(respmap["status"]) != 0 {
-		return nil, errors.New(respmap["msg"].(string))
+		return nil, fcoinError(respmap["status"], respmap["msg"].(string))
 	}
 
 	return respmap["data"], err
@@ -368,7 +405,7 @@ func (fc *FCoin) GetOneOrder(orderId string, currency CurrencyPair) (*OrderDecim
 	}
 
 	if resp.Status != 0 {
-		return nil, errors.New(resp.Msg)
+		return nil, fcoinError(resp.Status, resp.Msg)
 	}
 
 	return resp.Data.ToOrderDecimal(currency), nil
@@ -403,7 +440,7 @@ func (fc *FCoin) GetUnfinishedOrders(currency CurrencyPair, from, to int64, limi
 	}
 
 	if resp.Status != 0 {
-		return nil, errors.New(resp.Msg)
+		return nil, fcoinError(resp.Status, resp.Msg)
 	}
 
 	var ords []OrderDecimal
@@ -445,7 +482,7 @@ func (fc *FCoin) GetFinishedOrders(currency CurrencyPair, from, to int64, limit
 	}
 
 	if resp.Status != 0 {
-		return nil, errors.New(resp.Msg)
+		return nil, fcoinError(resp.Status, resp.Msg)
 	}
 
 	var ords []OrderDecimal
@@ -457,6 +494,19 @@ func (fc *FCoin) GetFinishedOrders(currency CurrencyPair, from, to int64, limit
 	return ords, nil
 }
 
+/**
+ * 获取[from, to]时间范围内的全部已完成订单, 时间单位为毫秒, 0表示不限制
+ */
+func (fc *FCoin) GetAllFinishedOrders(currency CurrencyPair, from, to int64, limit int) ([]OrderDecimal, error) {
+	if limit == 0 {
+		limit = 100
+	}
+	return GetAllOrderDecimals(PAGING_BY_TIME_DESC, limit, from, to, func(q PageQuery) ([]OrderDecimal, string, error) {
+		orders, err := fc.GetFinishedOrders(currency, q.From, q.To, q.Limit)
+		return orders, "", err
+	})
+}
+
 func (fc *FCoin) GetAccount() (*Account, error) {
 	r, err := fc.doAuthenticatedRequest("GET", "accounts/balance", url.Values{})
 	if err != nil {
@@ -514,14 +564,80 @@ func (fc *FCoin) AssetTransfer(currency Currency, amount, from, to string) (bool
 	return true, nil
 }
 
+var klinePeriods = map[int]string{
+	KLINE_PERIOD_1MIN:   "M1",
+	KLINE_PERIOD_5MIN:   "M5",
+	KLINE_PERIOD_15MIN:  "M15",
+	KLINE_PERIOD_30MIN:  "M30",
+	KLINE_PERIOD_60MIN:  "H1",
+	KLINE_PERIOD_4H:     "H4",
+	KLINE_PERIOD_1DAY:   "D1",
+	KLINE_PERIOD_1WEEK:  "W1",
+	KLINE_PERIOD_1MONTH: "MN",
+}
+
 func (fc *FCoin) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
-	panic("not implement")
+	klines, err := fc.GetKlineRecordsDecimal(currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return KlineDecimalsToKlines(klines), nil
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线
+ */
+func (fc *FCoin) GetKlineRecordsDecimal(currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	resolution, err := KlinePeriodCode(klinePeriods, period)
+	if err != nil {
+		return nil, err
+	}
+	periodSec := int64(KlinePeriodDuration(period) / time.Second)
+	symbol := strings.ToLower(currency.ToSymbol(""))
+
+	return GetAllKlineDecimals(int64(since), size, 150, func(q PageQuery) ([]KlineDecimal, error) {
+		params := url.Values{}
+		params.Set("limit", strconv.Itoa(q.Limit))
+		// 只支持查询before之前的K线, 正序翻页时取[From, From+Limit个周期)
+		if q.From > 0 {
+			params.Set("before", strconv.FormatInt(q.From/1000+int64(q.Limit)*periodSec, 10))
+		} else if q.To > 0 {
+			params.Set("before", strconv.FormatInt(q.To/1000, 10))
+		}
+
+		var resp struct {
+			Status int
+			Msg    string
+			Data   []struct {
+				Id      int64
+				Open    decimal.Decimal
+				Close   decimal.Decimal
+				High    decimal.Decimal
+				Low     decimal.Decimal
+				BaseVol decimal.Decimal `json:"base_vol"`
+			}
+		}
+		uri := fmt.Sprintf("market/candles/%s/%s?%s", resolution, symbol, params.Encode())
+		err := HttpGet4(fc.httpClient, fc.baseUrl+uri, nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Status != 0 {
+			return nil, fcoinError(resp.Status, resp.Msg)
+		}
+
+		klines := make([]KlineDecimal, len(resp.Data))
+		for i, r := range resp.Data {
+			klines[i] = KlineDecimal{Pair: currency, Timestamp: r.Id, Open: r.Open, Close: r.Close, High: r.High, Low: r.Low, Vol: r.BaseVol}
+		}
+		return klines, nil
+	})
 }
 
 //非个人，整个交易所的交易记录
 func (fc *FCoin) GetTrades(currencyPair CurrencyPair, since int64) ([]TradeDecimal, error) {
 	url := fmt.Sprintf(fc.baseUrl + "market/trades/%s", strings.ToLower(currencyPair.ToSymbol("")))
-	println(url)
+	fc.log().Debug("get trades", "url", url)
 	resp, err := fc.httpClient.Get(url)
 	if err != nil {
 		return nil, err
@@ -571,7 +687,7 @@ func (fc *FCoin) GetTradeSymbols() ([]TradeSymbol, error) {
 	}
 
 	if respmap["status"].(float64) != 0 {
-		return nil, errors.New(respmap["msg"].(string))
+		return nil, fcoinError(respmap["status"], respmap["msg"].(string))
 	}
 
 	datamap := respmap["data"].([]interface{})
@@ -593,6 +709,18 @@ func (fc *FCoin) GetTradeSymbols() ([]TradeSymbol, error) {
 	return tradeSymbols, nil
 }
 
+func (fc *FCoin) GetInstruments() ([]Instrument, error) {
+	symbols, err := fc.GetTradeSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (fc *FCoin) GetTradeSymbol(currencyPair CurrencyPair) (*TradeSymbol, error) {
 	if len(fc.tradeSymbols) == 0 {
 		var err error
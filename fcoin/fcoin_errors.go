@@ -0,0 +1,36 @@
+package fcoin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/stephenlyu/GoEx"
+)
+
+// fcoin返回{"status":1016,"msg":"..."}, 未收录的错误码按msg分类
+var fcoinErrorCodes = ErrorCodes{
+	"429":  ERR_RATE_LIMITED,
+	"1002": ERR_MAINTENANCE,
+	"1016": ERR_INSUFFICIENT_BALANCE,
+	"2001": ERR_AUTH,
+	"3008": ERR_ORDER_NOT_FOUND,
+}
+
+func fcoinError(status interface{}, msg string) error {
+	return fcoinErrorCodes.NewError(FCOIN, fmt.Sprint(ToInt(status)), msg)
+}
+
+func parseFCoinError(statusCode int, body []byte) *ExchangeError {
+	var resp struct {
+		Status int
+		Msg    string
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Status == 0 {
+		return nil
+	}
+	return fcoinErrorCodes.NewError(FCOIN, fmt.Sprint(resp.Status), resp.Msg)
+}
+
+func init() {
+	RegisterErrorParser(FCOIN, parseFCoinError, "api.fcoin.com")
+}
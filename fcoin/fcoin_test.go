@@ -95,6 +95,12 @@ func TestFCoin_GetFinishedOrders(t *testing.T) {
 	output(ret)
 }
 
+func TestFCoin_GetAllFinishedOrders(t *testing.T) {
+	ret, err := ft.GetAllFinishedOrders(goex.NewCurrencyPair2("SHT_USDT"), 0, 0, 0)
+	chk(err)
+	output(ret)
+}
+
 func TestFCoin_AssetTransfer(t *testing.T) {
 	ft.AssetTransfer(goex.NewCurrency("FT", ""), "0.000945618753747253", "assets", "spot")
 }
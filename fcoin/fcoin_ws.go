@@ -23,6 +23,7 @@ func (this *FCoin) createWsConn() {
 
 			this.ws = NewWsConn("wss://api.fcoin.com/v2/ws")
 			this.ws.SetErrorHandler(this.errorHandle)
+			this.ws.SetLogger(this.logger)
 			this.ws.Heartbeat(func() interface{} {
 				ts := time.Now().UnixNano()/1000000
 				args := make([]interface{}, 0)
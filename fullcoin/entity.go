@@ -13,6 +13,18 @@ type Symbol struct {
 	AmountPrecision int 	`json:"amount_precision"`
 }
 
+func (this *Symbol) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Symbol: this.Symbol,
+		Pair: goex.NewCurrencyPair2(this.BaseCurrency + "_" + this.QuoteCurrency),
+		Type: goex.INSTRUMENT_SPOT,
+		PricePrecision: int32(this.PricePrecision),
+		AmountPrecision: int32(this.AmountPrecision),
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 //{side:"BUY",type:"1",volume:"0.01",price:"6400",fee_is_user_exchange_coin:"0"}
 type OrderReq struct {
 	Side string 			`json:"side"`
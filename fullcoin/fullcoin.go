@@ -138,6 +138,18 @@ func (this *FullCoin) GetSymbols() ([]Symbol, error) {
 	return data.Data, nil
 }
 
+func (this *FullCoin) GetInstruments() ([]Instrument, error) {
+	symbols, err := this.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (this *FullCoin) transSymbol(symbol string) string {
 	return strings.ToLower(strings.Replace(symbol, "_", "", -1))
 }
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 var (
@@ -50,6 +53,53 @@ func (g *Gate) GetAccount() (*Account, error) {
 	panic("not implement")
 }
 
+/**
+ * 交易品种来自marketinfo, min_amount_a为最小下单数量, min_amount_b为计价币的最小下单金额
+ */
+func (g *Gate) GetInstruments() ([]Instrument, error) {
+	var resp struct {
+		Result string
+		Pairs  []map[string]struct {
+			DecimalPlaces       int32           `json:"decimal_places"`
+			AmountDecimalPlaces int32           `json:"amount_decimal_places"`
+			MinAmountA          decimal.Decimal `json:"min_amount_a"`
+			MinAmountB          decimal.Decimal `json:"min_amount_b"`
+			TradeDisabled       int             `json:"trade_disabled"`
+		}
+	}
+	err := HttpGet4(g.client, marketBaseUrl+"/marketinfo", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []Instrument
+	for _, m := range resp.Pairs {
+		for symbol, r := range m {
+			pair := NewCurrencyPair2(strings.ToUpper(symbol))
+			if pair == UNKNOWN_PAIR {
+				continue
+			}
+			inst := Instrument{
+				Exchange:        GATEIO,
+				Symbol:          symbol,
+				Pair:            pair,
+				Type:            INSTRUMENT_SPOT,
+				PricePrecision:  r.DecimalPlaces,
+				AmountPrecision: r.AmountDecimalPlaces,
+				MinQty:          r.MinAmountA,
+				MinNotional:     r.MinAmountB,
+				Status:          INSTRUMENT_STATUS_TRADING,
+			}
+			if r.TradeDisabled != 0 {
+				inst.Status = INSTRUMENT_STATUS_SUSPENDED
+			}
+			inst.FillPrecision()
+			ret = append(ret, inst)
+		}
+	}
+	return ret, nil
+}
+
 func (g *Gate) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	uri := fmt.Sprintf("%s/ticker/%s", marketBaseUrl, strings.ToLower(currency.ToSymbol("_")))
 
@@ -98,8 +148,71 @@ func (g *Gate) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
 	return dep, nil
 }
 
+var klineGroupSecs = map[int]int64{
+	KLINE_PERIOD_1MIN:  60,
+	KLINE_PERIOD_5MIN:  300,
+	KLINE_PERIOD_15MIN: 900,
+	KLINE_PERIOD_30MIN: 1800,
+	KLINE_PERIOD_60MIN: 3600,
+	KLINE_PERIOD_4H:    14400,
+	KLINE_PERIOD_1DAY:  86400,
+	KLINE_PERIOD_1WEEK: 604800,
+}
+
 func (g *Gate) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
-	panic("not implement")
+	klines, err := g.GetKlineRecordsDecimal(currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return KlineDecimalsToKlines(klines), nil
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线.
+ * 接口只能按range_hour查询到当前时间为止的K线, 时间范围越大返回的数据越多
+ */
+func (g *Gate) GetKlineRecordsDecimal(currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	groupSec, ok := klineGroupSecs[period]
+	if !ok {
+		return nil, EX_ERR_NOT_SUPPORTED
+	}
+	symbol := strings.ToLower(currency.ToSymbol("_"))
+
+	return GetAllKlineDecimals(int64(since), size, 1000, func(q PageQuery) ([]KlineDecimal, error) {
+		start := time.Now().Unix() - int64(q.Limit)*groupSec
+		if q.From > 0 {
+			start = q.From / 1000
+		} else if q.To > 0 {
+			start = q.To/1000 - int64(q.Limit)*groupSec
+		}
+		rangeHour := (time.Now().Unix()-start)/3600 + 1
+
+		var resp struct {
+			Result string
+			Data   [][]decimal.Decimal
+		}
+		err := HttpGet4(g.client, fmt.Sprintf("%s/candlestick2/%s?group_sec=%d&range_hour=%d", marketBaseUrl, symbol, groupSec, rangeHour), nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Result != "true" {
+			return nil, API_ERR
+		}
+
+		// [time, volume, close, high, low, open]
+		klines := make([]KlineDecimal, 0, len(resp.Data))
+		for _, r := range resp.Data {
+			if len(r) < 6 {
+				continue
+			}
+			ts := r[0].IntPart() / 1000
+			if q.To > 0 && ts*1000 > q.To {
+				continue
+			}
+			klines = append(klines, KlineDecimal{Pair: currency, Timestamp: ts, Vol: r[1], Close: r[2], High: r[3], Low: r[4], Open: r[5]})
+		}
+		return klines, nil
+	})
 }
 
 //非个人，整个交易所的交易记录
@@ -0,0 +1,45 @@
+package gateiospot
+
+import (
+	"encoding/json"
+
+	. "github.com/stephenlyu/GoEx"
+)
+
+// https://www.gateio.ws/docs/apiv4/zh_CN/index.html#label-list
+var gateioV4ErrorLabels = ErrorCodes{
+	"INVALID_KEY":             ERR_AUTH,
+	"INVALID_SIGNATURE":       ERR_AUTH,
+	"MISSING_REQUIRED_HEADER": ERR_AUTH,
+	"FORBIDDEN":               ERR_AUTH,
+	"REQUEST_EXPIRED":         ERR_NONCE,
+	"TOO_MANY_REQUESTS":       ERR_RATE_LIMITED,
+	"INVALID_CURRENCY":        ERR_INVALID_SYMBOL,
+	"INVALID_CURRENCY_PAIR":   ERR_INVALID_SYMBOL,
+	"INVALID_PARAM_VALUE":     ERR_INVALID_PARAM,
+	"BALANCE_NOT_ENOUGH":      ERR_INSUFFICIENT_BALANCE,
+	"ORDER_NOT_FOUND":         ERR_ORDER_NOT_FOUND,
+	"ORDER_CLOSED":            ERR_ORDER_NOT_FOUND,
+	"POC_FILL_IMMEDIATELY":    ERR_POST_ONLY_REJECTED,
+	"SERVER_ERROR":            ERR_MAINTENANCE,
+}
+
+// 旧版接口只返回错误信息
+func gateioError(message string) error {
+	return NewExchangeError(GATEIO, ClassifyMessage(message), "", "fail, error:"+message)
+}
+
+func parseGateIOV4Error(statusCode int, body []byte) *ExchangeError {
+	var resp struct {
+		Label   string
+		Message string
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Label == "" {
+		return nil
+	}
+	return gateioV4ErrorLabels.NewError(GATEIO, resp.Label, resp.Message)
+}
+
+func init() {
+	RegisterErrorParser(GATEIO, parseGateIOV4Error, "api.gateio.ws")
+}
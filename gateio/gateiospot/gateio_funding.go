@@ -0,0 +1,299 @@
+package gateiospot
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	. "github.com/stephenlyu/GoEx"
+)
+
+// api2没有提币ID、充值标签和账户划转, 充提接口使用v4
+const (
+	API_V4_BASE_URL = "https://api.gateio.ws"
+	API_V4_PREFIX   = "/api/v4"
+
+	V4_DEPOSIT_ADDRESS  = "/wallet/deposit_address"
+	V4_DEPOSITS         = "/wallet/deposits"
+	V4_WITHDRAWALS      = "/withdrawals"
+	V4_WITHDRAW_HISTORY = "/wallet/withdrawals"
+	V4_WITHDRAW_STATUS  = "/wallet/withdraw_status"
+	V4_TRANSFERS        = "/wallet/transfers"
+	V4_SERVER_TIME      = "/spot/time"
+)
+
+// https://www.gate.io/docs/developers/apiv4/zh_CN/#获取服务器当前时间
+func fetchServerTime(ctx context.Context, client *http.Client) (time.Time, error) {
+	var resp struct {
+		ServerTime int64 `json:"server_time"`
+	}
+	err := HttpGet4WithContext(ctx, client, API_V4_BASE_URL+API_V4_PREFIX+V4_SERVER_TIME, nil, &resp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, resp.ServerTime*int64(time.Millisecond)), nil
+}
+
+func init() {
+	RegisterServerClock(NewServerClock(GATEIO, fetchServerTime))
+}
+
+func (this *GateIOSpot) buildV4Header(method, path, query, body string) (map[string]string, error) {
+	timestamp := strconv.FormatInt(ServerTime(GATEIO).Unix(), 10)
+	hashed := sha512.Sum512([]byte(body))
+	payload := strings.Join([]string{method, API_V4_PREFIX + path, query, hex.EncodeToString(hashed[:]), timestamp}, "\n")
+	signature, err := GetParamHmacSHA512Sign(this.apiSecretKey, payload)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"KEY":          this.apiKey,
+		"Timestamp":    timestamp,
+		"SIGN":         signature,
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
+	}, nil
+}
+
+func (this *GateIOSpot) v4Get(path string, params url.Values, result interface{}) error {
+	query := params.Encode()
+	header, err := this.buildV4Header("GET", path, query, "")
+	if err != nil {
+		return err
+	}
+	reqUrl := API_V4_BASE_URL + API_V4_PREFIX + path
+	if query != "" {
+		reqUrl += "?" + query
+	}
+	return HttpGet4(this.client, reqUrl, header, result)
+}
+
+func (this *GateIOSpot) v4Post(path string, param interface{}) ([]byte, error) {
+	bytes, _ := json.Marshal(param)
+	header, err := this.buildV4Header("POST", path, "", string(bytes))
+	if err != nil {
+		return nil, err
+	}
+	return HttpPostJson(this.client, API_V4_BASE_URL+API_V4_PREFIX+path, string(bytes), header)
+}
+
+func (this *GateIOSpot) GetDepositAddress(currency Currency) ([]DepositAddress, error) {
+	params := url.Values{}
+	params.Set("currency", currency.Symbol)
+
+	var resp struct {
+		Currency            string
+		Address             string
+		MultichainAddresses []struct {
+			Chain        string
+			Address      string
+			PaymentId    string `json:"payment_id"`
+			ObtainFailed int    `json:"obtain_failed"`
+		} `json:"multichain_addresses"`
+	}
+	err := this.v4Get(V4_DEPOSIT_ADDRESS, params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.MultichainAddresses) == 0 {
+		return []DepositAddress{{Currency: currency, Address: resp.Address}}, nil
+	}
+
+	var ret []DepositAddress
+	for _, a := range resp.MultichainAddresses {
+		if a.ObtainFailed != 0 {
+			continue
+		}
+		ret = append(ret, DepositAddress{
+			Currency: currency,
+			Address:  a.Address,
+			Tag:      a.PaymentId,
+			Chain:    a.Chain,
+		})
+	}
+	return ret, nil
+}
+
+type LedgerRecord struct {
+	Id        string
+	Txid      string
+	Timestamp decimal.Decimal
+	Amount    decimal.Decimal
+	Fee       decimal.Decimal
+	Currency  string
+	Address   string
+	Memo      string
+	Status    string
+}
+
+func (this *LedgerRecord) ToFundingRecord(_type FundingType) *FundingRecord {
+	var status FundingStatus
+	switch this.Status {
+	case "DONE":
+		status = FUNDING_SUCCESS
+	case "CANCEL":
+		status = FUNDING_CANCELED
+	case "FAIL", "INVALID", "BCODE":
+		status = FUNDING_FAILED
+	case "PEND", "PROCES", "EXTPEND", "DMOVE", "SPLITPEND":
+		status = FUNDING_PROCESSING
+	default:
+		status = FUNDING_PENDING
+	}
+
+	return &FundingRecord{
+		Id:        this.Id,
+		Type:      _type,
+		Currency:  NewCurrency(strings.ToUpper(this.Currency), ""),
+		Amount:    this.Amount,
+		Fee:       this.Fee,
+		Address:   this.Address,
+		Tag:       this.Memo,
+		TxId:      this.Txid,
+		Status:    status,
+		RawStatus: this.Status,
+		Timestamp: this.Timestamp.IntPart() * 1000,
+	}
+}
+
+func (this *GateIOSpot) getLedgerRecords(path string, currency Currency, _type FundingType) ([]FundingRecord, error) {
+	params := url.Values{}
+	params.Set("currency", currency.Symbol)
+
+	var resp []LedgerRecord
+	err := this.v4Get(path, params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]FundingRecord, len(resp))
+	for i := range resp {
+		ret[i] = *resp[i].ToFundingRecord(_type)
+	}
+	return ret, nil
+}
+
+func (this *GateIOSpot) GetDeposits(currency Currency) ([]FundingRecord, error) {
+	return this.getLedgerRecords(V4_DEPOSITS, currency, FUNDING_DEPOSIT)
+}
+
+func (this *GateIOSpot) GetWithdrawals(currency Currency) ([]FundingRecord, error) {
+	return this.getLedgerRecords(V4_WITHDRAW_HISTORY, currency, FUNDING_WITHDRAW)
+}
+
+func (this *GateIOSpot) GetWithdrawal(currency Currency, withdrawId string) (*FundingRecord, error) {
+	records, err := this.GetWithdrawals(currency)
+	if err != nil {
+		return nil, err
+	}
+	return FindFundingRecord(records, withdrawId)
+}
+
+/**
+ * Gate自动扣除提币手续费, 忽略param.Fee
+ */
+func (this *GateIOSpot) CreateWithdrawal(param WithdrawParam) (string, error) {
+	req := map[string]string{
+		"currency": param.Currency.Symbol,
+		"address":  param.Address,
+		"amount":   param.Amount.String(),
+	}
+	if param.Tag != "" {
+		req["memo"] = param.Tag
+	}
+	if param.Chain != "" {
+		req["chain"] = param.Chain
+	}
+
+	body, err := this.v4Post(V4_WITHDRAWALS, req)
+	if err != nil {
+		return "", err
+	}
+
+	var resp LedgerRecord
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Id, nil
+}
+
+func (this *GateIOSpot) GetWithdrawalFees(currency Currency) ([]WithdrawFeeDecimal, error) {
+	params := url.Values{}
+	if currency != UNKNOWN {
+		params.Set("currency", currency.Symbol)
+	}
+
+	var resp []struct {
+		Currency           string
+		WithdrawFix        decimal.Decimal `json:"withdraw_fix"`
+		WithdrawAmountMini decimal.Decimal `json:"withdraw_amount_mini"`
+	}
+	err := this.v4Get(V4_WITHDRAW_STATUS, params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]WithdrawFeeDecimal, len(resp))
+	for i, f := range resp {
+		ret[i] = WithdrawFeeDecimal{
+			Currency:  NewCurrency(strings.ToUpper(f.Currency), ""),
+			MinFee:    f.WithdrawFix,
+			MaxFee:    f.WithdrawFix,
+			MinAmount: f.WithdrawAmountMini,
+		}
+	}
+	return ret, nil
+}
+
+/**
+ * 支持现货账户与永续合约账户(swap)、交割合约账户(future)之间的划转, 划转币种即结算币种
+ * 杠杆账户划转需要指定交易对, 暂不支持
+ */
+func (this *GateIOSpot) TransferBetweenWallets(currency Currency, amount decimal.Decimal, from, to WalletType) (string, error) {
+	accounts := map[WalletType]string{
+		WALLET_SPOT:   "spot",
+		WALLET_SWAP:   "futures",
+		WALLET_FUTURE: "delivery",
+	}
+	fromAccount, ok1 := accounts[from]
+	toAccount, ok2 := accounts[to]
+	if !ok1 || !ok2 || from == to {
+		return "", EX_ERR_NOT_SUPPORTED
+	}
+
+	req := map[string]string{
+		"currency": currency.Symbol,
+		"from":     fromAccount,
+		"to":       toAccount,
+		"amount":   amount.String(),
+		"settle":   strings.ToLower(currency.Symbol),
+	}
+
+	body, err := this.v4Post(V4_TRANSFERS, req)
+	if err != nil {
+		return "", err
+	}
+	if len(body) == 0 {
+		return "", nil
+	}
+
+	var resp struct {
+		TxId decimal.Decimal `json:"tx_id"`
+	}
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return "", fmt.Errorf("bad response: %s", string(body))
+	}
+	return resp.TxId.String(), nil
+}
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"time"
 )
 
 const (
@@ -24,6 +25,7 @@ const (
 	ORDER_BOOKS = "/api2/1/orderBooks"
 	ORDER_BOOK = "/api2/1/orderBook/%s"
 	TRADE_HISTORY = "/api2/1/tradeHistory/%s"
+	CANDLESTICK = "/api2/1/candlestick2/%s?group_sec=%d&range_hour=%d"
 	BALANCES = "/api2/1/private/balances"
 	PRIVATE_BUY = "/api2/1/private/buy"
 	PRIVATE_SELL = "/api2/1/private/sell"
@@ -32,12 +34,14 @@ const (
 	CANCEL_ALL_ORDERS = "/api2/1/private/cancelAllOrders"
 	GET_ORDER = "/api2/1/private/getOrder"
 	OPEN_ORDERS = "/api2/1/private/openOrders"
+	MY_TRADE_HISTORY = "/api2/1/private/tradeHistory"
 )
 
 type GateIOSpot struct {
 	apiKey,
 	apiSecretKey string
 	client            *http.Client
+	logger            Logger
 
 	ws                *WsConn
 	createWsLock      sync.Mutex
@@ -58,6 +62,24 @@ func NewGateIOSpot(	apiKey, apiSecretKey string) *GateIOSpot {
 	}
 }
 
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (this *GateIOSpot) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	this.logger = logger
+}
+
+func (this *GateIOSpot) log() Logger {
+	return ExchangeLogger(this.logger, GATEIO)
+}
+
+func (this *GateIOSpot) GetExchangeName() string {
+	return GATEIO
+}
+
 func (this *GateIOSpot) GetPairs() ([]CurrencyPair, error) {
 	resp, err := this.client.Get(API_BASE_URL + PAIRS)
 	if err != nil {
@@ -95,6 +117,23 @@ type MarketInfo struct {
 	TradeDisabled int				`json:"trade_disabled"`
 }
 
+func (this *MarketInfo) ToInstrument() Instrument {
+	inst := Instrument{
+		Exchange: GATEIO,
+		Symbol: strings.ToLower(this.Pair.ToSymbol("_")),
+		Pair: this.Pair,
+		Type: INSTRUMENT_SPOT,
+		PricePrecision: int32(this.DecimalPlaces),
+		MinQty: this.MinAmount,
+		Status: INSTRUMENT_STATUS_TRADING,
+	}
+	if this.TradeDisabled != 0 {
+		inst.Status = INSTRUMENT_STATUS_SUSPENDED
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 func (this *GateIOSpot) GetMarketInfo() ([]MarketInfo, error) {
 	resp, err := this.client.Get(API_BASE_URL + MARKET_INFO)
 	if err != nil {
@@ -133,6 +172,18 @@ func (this *GateIOSpot) GetMarketInfo() ([]MarketInfo, error) {
 	return ret, err
 }
 
+func (this *GateIOSpot) GetInstruments() ([]Instrument, error) {
+	markets, err := this.GetMarketInfo()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(markets))
+	for i := range markets {
+		ret[i] = markets[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (this *GateIOSpot) GetTicker(pair CurrencyPair) (*Ticker, error) {
 	resp, err := this.client.Get(API_BASE_URL + fmt.Sprintf(TICKER, strings.ToLower(pair.ToSymbol("_"))))
 	if err != nil {
@@ -178,6 +229,52 @@ func (this *GateIOSpot) GetTicker(pair CurrencyPair) (*Ticker, error) {
 	return ret, err
 }
 
+/**
+ * 所有交易对的24小时行情. 注意gate的baseVolume是计价币成交额, quoteVolume是交易币成交量
+ */
+func (this *GateIOSpot) GetAllTickers() (map[CurrencyPair]*TickerDecimal, error) {
+	var tickers map[string]struct {
+		Result        string
+		Last          decimal.Decimal
+		LowestAsk     decimal.Decimal
+		HighestBid    decimal.Decimal
+		PercentChange decimal.Decimal
+		BaseVolume    decimal.Decimal
+		QuoteVolume   decimal.Decimal
+		High24hr      decimal.Decimal
+		Low24hr       decimal.Decimal
+	}
+	err := HttpGet4(this.client, API_BASE_URL+TICKERS, nil, &tickers)
+	if err != nil {
+		return nil, err
+	}
+
+	now := uint64(time.Now().Unix())
+	ret := make(map[CurrencyPair]*TickerDecimal, len(tickers))
+	for symbol, r := range tickers {
+		pair := NewCurrencyPair2(symbol)
+		if pair == UNKNOWN_PAIR {
+			continue
+		}
+		pair = CanonicalPair(pair)
+		ticker := &TickerDecimal{
+			Pair:     pair,
+			Last:     r.Last,
+			Buy:      r.HighestBid,
+			Sell:     r.LowestAsk,
+			High:     r.High24hr,
+			Low:      r.Low24hr,
+			Vol:      r.QuoteVolume,
+			QuoteVol: r.BaseVolume,
+			Change:   r.PercentChange.Div(decimal.New(100, 0)),
+			Date:     now,
+		}
+		ticker.FillChange()
+		ret[pair] = ticker
+	}
+	return ret, nil
+}
+
 func (this *GateIOSpot) GetOrderBook(pair CurrencyPair) (*DepthDecimal, error) {
 	resp, err := this.client.Get(API_BASE_URL + fmt.Sprintf(ORDER_BOOK, strings.ToLower(pair.ToSymbol("_"))))
 	if err != nil {
@@ -280,6 +377,65 @@ func (this *GateIOSpot) GetTrades(pair CurrencyPair) ([]TradeDecimal, error) {
 	return ret, err
 }
 
+var klineGroupSecs = map[int]int64{
+	KLINE_PERIOD_1MIN:  60,
+	KLINE_PERIOD_5MIN:  300,
+	KLINE_PERIOD_15MIN: 900,
+	KLINE_PERIOD_30MIN: 1800,
+	KLINE_PERIOD_60MIN: 3600,
+	KLINE_PERIOD_4H:    14400,
+	KLINE_PERIOD_1DAY:  86400,
+	KLINE_PERIOD_1WEEK: 604800,
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线.
+ * 接口只能按range_hour查询到当前时间为止的K线, 时间范围越大返回的数据越多
+ */
+func (this *GateIOSpot) GetKlineRecordsDecimal(pair CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	groupSec, ok := klineGroupSecs[period]
+	if !ok {
+		return nil, EX_ERR_NOT_SUPPORTED
+	}
+	symbol := strings.ToLower(pair.ToSymbol("_"))
+
+	return GetAllKlineDecimals(int64(since), size, 1000, func(q PageQuery) ([]KlineDecimal, error) {
+		start := time.Now().Unix() - int64(q.Limit)*groupSec
+		if q.From > 0 {
+			start = q.From / 1000
+		} else if q.To > 0 {
+			start = q.To/1000 - int64(q.Limit)*groupSec
+		}
+		rangeHour := (time.Now().Unix()-start)/3600 + 1
+
+		var data struct {
+			Result string
+			Data [][]decimal.Decimal
+		}
+		err := HttpGet4(this.client, API_BASE_URL + fmt.Sprintf(CANDLESTICK, symbol, groupSec, rangeHour), nil, &data)
+		if err != nil {
+			return nil, err
+		}
+		if data.Result != "true" {
+			return nil, errors.New("fail")
+		}
+
+		// [time, volume, close, high, low, open]
+		klines := make([]KlineDecimal, 0, len(data.Data))
+		for _, r := range data.Data {
+			if len(r) < 6 {
+				continue
+			}
+			ts := r[0].IntPart() / 1000
+			if q.To > 0 && ts*1000 > q.To {
+				continue
+			}
+			klines = append(klines, KlineDecimal{Pair: pair, Timestamp: ts, Vol: r[1], Close: r[2], High: r[3], Low: r[4], Open: r[5]})
+		}
+		return klines, nil
+	})
+}
+
 func (this *GateIOSpot) buildHeader(body string) map[string]string {
 	signature, _ := GetParamHmacSHA512Sign(this.apiSecretKey, body)
 	return map[string]string {
@@ -360,7 +516,7 @@ func (this *GateIOSpot) PlaceOrder(side string, pair CurrencyPair, price, amount
 	}
 
 	if data.Result != "true" {
-		return "", errors.New("fail, error:" + data.Message)
+		return "", gateioError(data.Message)
 	}
 
 	return data.OrderNumber.String(), err
@@ -387,7 +543,7 @@ func (this *GateIOSpot) CancelOrder(pair CurrencyPair, orderId string) error {
 		if strings.Contains(data.Message, "already finished") {
 			return nil
 		}
-		return errors.New("fail, error:" + data.Message)
+		return gateioError(data.Message)
 	}
 
 	return err
@@ -417,7 +573,7 @@ func (this *GateIOSpot) CancelOrders(pair CurrencyPair, orderIds []string) error
 	}
 
 	if !data.Result {
-		return errors.New("fail, error:" + data.Message)
+		return gateioError(data.Message)
 	}
 
 	return err
@@ -447,7 +603,7 @@ func (this *GateIOSpot) CancelAllOrders(pair CurrencyPair, types string) error {
 	}
 
 	if !data.Result {
-		return errors.New("fail, error:" + data.Message)
+		return gateioError(data.Message)
 	}
 
 	return err
@@ -510,7 +666,7 @@ func (this *GateIOSpot) GetOrder(pair CurrencyPair, orderId string) (*OrderDecim
 	}
 
 	if data.Result != "true" {
-		return nil, errors.New("fail, error:" + data.Message)
+		return nil, gateioError(data.Message)
 	}
 
 	ret := new(OrderDecimal)
@@ -559,7 +715,7 @@ func (this *GateIOSpot) GetOpenOrders(pair CurrencyPair) ([]OrderDecimal, error)
 	}
 
 	if data.Result != "true" {
-		return nil, errors.New("fail, error:" + data.Message)
+		return nil, gateioError(data.Message)
 	}
 
 	ret := make([]OrderDecimal, len(data.Orders))
@@ -578,3 +734,68 @@ func (this *GateIOSpot) GetOpenOrders(pair CurrencyPair) ([]OrderDecimal, error)
 
 	return ret, err
 }
+
+// 交易所只返回最近的成交记录, 不支持分页, from/to/limit在本地过滤
+func (this *GateIOSpot) GetFills(pair CurrencyPair, from, to int64, limit int) ([]FillDecimal, error) {
+	var param string = "currencyPair=" + strings.ToLower(pair.ToSymbol("_"))
+
+	header := this.buildHeader(param)
+	body, err := HttpPostForm3(this.client, API_BASE_URL + MY_TRADE_HISTORY, param, header)
+	if err != nil {
+		return nil, err
+	}
+	var data struct {
+		Result string
+		Message string
+		Trades []struct {
+			TradeID decimal.Decimal
+			OrderNumber decimal.Decimal
+			Type string
+			Rate decimal.Decimal
+			Amount decimal.Decimal
+			TimeUnix int64			`json:"time_unix"`
+			Role string
+			Fee decimal.Decimal
+			FeeCoin string			`json:"fee_coin"`
+		}
+	}
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Result != "true" {
+		return nil, gateioError(data.Message)
+	}
+
+	var ret []FillDecimal
+	for _, o := range data.Trades {
+		ts := o.TimeUnix * 1000
+		if from > 0 && ts < from || to > 0 && ts > to {
+			continue
+		}
+		ret = append(ret, FillDecimal{
+			FillId: o.TradeID.String(),
+			OrderId: o.OrderNumber.String(),
+			Currency: pair,
+			Side: this.translateType(o.Type),
+			Price: o.Rate,
+			Qty: o.Amount,
+			Fee: o.Fee,
+			FeeCurrency: strings.ToUpper(o.FeeCoin),
+			IsMaker: o.Role == "maker",
+			TransactionTime: ts,
+		})
+		if limit > 0 && len(ret) >= limit {
+			break
+		}
+	}
+
+	return ret, err
+}
+
+// 交易所不支持分页, 返回最近成交中[from, to]内的全部记录
+func (this *GateIOSpot) GetAllFills(pair CurrencyPair, from, to int64, limit int) ([]FillDecimal, error) {
+	fills, err := this.GetFills(pair, from, to, 0)
+	return MergeFills(fills), err
+}
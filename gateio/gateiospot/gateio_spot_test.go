@@ -11,6 +11,8 @@ import (
 	"sync"
 )
 
+var _ goex.FillsAPI = (*GateIOSpot)(nil)
+
 var (
 	gateioSpot *GateIOSpot
 )
@@ -58,6 +60,12 @@ func TestGateIOSpot_GetTicker(t *testing.T) {
 	output(ret)
 }
 
+func TestGateIOSpot_GetAllTickers(t *testing.T) {
+	ret, err := gateioSpot.GetAllTickers()
+	assert.Nil(t, err)
+	output(ret[goex.EOS_USDT])
+}
+
 func TestGateIOSpot_GetOrderBook(t *testing.T) {
 	ret, err := gateioSpot.GetOrderBook(goex.EOS_USDT)
 	assert.Nil(t, err)
@@ -138,3 +146,21 @@ func TestGateIOSpot_GetOpenOrders(t *testing.T) {
 	assert.Nil(t, err)
 	output(ret)
 }
+
+func TestGateIOSpot_GetFills(t *testing.T) {
+	ret, err := gateioSpot.GetFills(goex.ETH_USDT, 0, 0, 0)
+	assert.Nil(t, err)
+	output(ret)
+}
+
+func TestGateIOSpot_GetDepositAddress(t *testing.T) {
+	ret, err := gateioSpot.GetDepositAddress(goex.USDT)
+	assert.Nil(t, err)
+	output(ret)
+}
+
+func TestGateIOSpot_GetWithdrawalFees(t *testing.T) {
+	ret, err := gateioSpot.GetWithdrawalFees(goex.USDT)
+	assert.Nil(t, err)
+	output(ret)
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	. "github.com/stephenlyu/GoEx"
-	"log"
 	"time"
 	"sort"
 	"github.com/shopspring/decimal"
@@ -54,6 +53,7 @@ func (this *GateIOSpot) createWsConn() {
 
 			this.ws = NewWsConn("wss://ws.gate.io/v3/")
 			this.ws.SetErrorHandler(this.errorHandle)
+			this.ws.SetLogger(this.logger)
 			this.ws.Heartbeat(func() interface{} {
 				return map[string]interface{} {
 					"id": _NextId(),
@@ -76,7 +76,7 @@ func (this *GateIOSpot) createWsConn() {
 				}
 				err := json.Unmarshal(msg, &data)
 				if err != nil {
-					log.Print(err)
+					this.log().Warn("decode websocket message failed", "err", err)
 					return
 				}
 
@@ -134,8 +134,11 @@ func (this *GateIOSpot) Login(handle func(error)) error {
 	this.createWsConn()
 	this.wsLoginHandle = handle
 
-	nonce := time.Now().UnixNano() / 1000000
-	sign, _ := GetParamHmacSHA512Base64SignEx(this.apiSecretKey, strconv.FormatInt(nonce, 10))
+	nonce := ServerTime(GATEIO).UnixNano() / 1000000
+	sign, err := GetParamHmacSHA512Base64SignEx(this.apiSecretKey, strconv.FormatInt(nonce, 10))
+	if err != nil {
+		return err
+	}
 	params := []interface{}{this.apiKey, sign, nonce}
 
 	return this.ws.Subscribe(map[string]interface{}{
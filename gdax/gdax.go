@@ -4,7 +4,12 @@ import (
 	"fmt"
 	. "github.com/stephenlyu/GoEx"
 	"net/http"
+	"net/url"
 	"sort"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 //www.coinbase.com or www.gdax.com
@@ -48,6 +53,53 @@ func (g *Gdax) GetAccount() (*Account, error) {
 	panic("not implement")
 }
 
+/**
+ * 交易品种来自products, 没有base_increment的旧接口用base_min_size作为数量最小变动
+ */
+func (g *Gdax) GetInstruments() ([]Instrument, error) {
+	var resp []struct {
+		Id              string
+		BaseCurrency    string          `json:"base_currency"`
+		QuoteCurrency   string          `json:"quote_currency"`
+		BaseMinSize     decimal.Decimal `json:"base_min_size"`
+		BaseIncrement   decimal.Decimal `json:"base_increment"`
+		QuoteIncrement  decimal.Decimal `json:"quote_increment"`
+		MinMarketFunds  decimal.Decimal `json:"min_market_funds"`
+		Status          string
+		TradingDisabled bool `json:"trading_disabled"`
+	}
+	err := HttpGet4(g.httpClient, g.baseUrl+"/products", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Instrument, len(resp))
+	for i, r := range resp {
+		inst := Instrument{
+			Exchange:    GDAX,
+			Symbol:      r.Id,
+			Pair:        NewCurrencyPair(NewCurrency(r.BaseCurrency, ""), NewCurrency(r.QuoteCurrency, "")),
+			Type:        INSTRUMENT_SPOT,
+			TickSize:    r.QuoteIncrement,
+			LotSize:     r.BaseIncrement,
+			MinQty:      r.BaseMinSize,
+			MinNotional: r.MinMarketFunds,
+			Status:      INSTRUMENT_STATUS_SUSPENDED,
+		}
+		if inst.LotSize.Sign() <= 0 {
+			inst.LotSize = r.BaseMinSize
+		}
+		if r.Status == "online" && !r.TradingDisabled {
+			inst.Status = INSTRUMENT_STATUS_TRADING
+		} else if r.Status == "delisted" {
+			inst.Status = INSTRUMENT_STATUS_DELISTED
+		}
+		inst.FillPrecision()
+		ret[i] = inst
+	}
+	return ret, nil
+}
+
 func (g *Gdax) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	resp, err := HttpGet(g.httpClient, fmt.Sprintf("%s/products/%s/ticker", g.baseUrl, currency.ToSymbol("-")))
 	if err != nil {
@@ -112,8 +164,62 @@ func (g *Gdax) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
 	return dep, nil
 }
 
+var klineGranularities = map[int]int64{
+	KLINE_PERIOD_1MIN:  60,
+	KLINE_PERIOD_5MIN:  300,
+	KLINE_PERIOD_15MIN: 900,
+	KLINE_PERIOD_60MIN: 3600,
+	KLINE_PERIOD_1DAY:  86400,
+}
+
 func (g *Gdax) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
-	panic("not implement")
+	klines, err := g.GetKlineRecordsDecimal(currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return KlineDecimalsToKlines(klines), nil
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线
+ */
+func (g *Gdax) GetKlineRecordsDecimal(currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	granularity, ok := klineGranularities[period]
+	if !ok {
+		return nil, EX_ERR_NOT_SUPPORTED
+	}
+
+	return GetAllKlineDecimals(int64(since), size, 300, func(q PageQuery) ([]KlineDecimal, error) {
+		// start和end需要同时指定, 每次最多300根
+		params := url.Values{}
+		params.Set("granularity", strconv.FormatInt(granularity, 10))
+		window := time.Duration(int64(q.Limit)*granularity) * time.Second
+		if q.From > 0 {
+			start := time.Unix(0, q.From*int64(time.Millisecond)).UTC()
+			params.Set("start", start.Format(time.RFC3339))
+			params.Set("end", start.Add(window).Format(time.RFC3339))
+		} else if q.To > 0 {
+			end := time.Unix(0, q.To*int64(time.Millisecond)).UTC()
+			params.Set("start", end.Add(-window).Format(time.RFC3339))
+			params.Set("end", end.Format(time.RFC3339))
+		}
+
+		// [time, low, high, open, close, volume]
+		var resp [][]decimal.Decimal
+		err := HttpGet4(g.httpClient, fmt.Sprintf("%s/products/%s/candles?%s", g.baseUrl, currency.ToSymbol("-"), params.Encode()), nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		klines := make([]KlineDecimal, 0, len(resp))
+		for _, r := range resp {
+			if len(r) < 6 {
+				continue
+			}
+			klines = append(klines, KlineDecimal{Pair: currency, Timestamp: r[0].IntPart(), Low: r[1], High: r[2], Open: r[3], Close: r[4], Vol: r[5]})
+		}
+		return klines, nil
+	})
 }
 
 //非个人，整个交易所的交易记录
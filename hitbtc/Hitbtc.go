@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stephenlyu/GoEx"
 )
 
@@ -84,6 +85,37 @@ func (hitbtc *Hitbtc) GetSymbols() ([]goex.CurrencyPair, error) {
 	return pairs, nil
 }
 
+// 交易品种来自同一个symbol接口, quantityIncrement同时是最小下单量
+func (hitbtc *Hitbtc) GetInstruments() ([]goex.Instrument, error) {
+	var resp []struct {
+		Id                string
+		BaseCurrency      string
+		QuoteCurrency     string
+		QuantityIncrement decimal.Decimal
+		TickSize          decimal.Decimal
+	}
+	err := hitbtc.doRequest("GET", SYMBOLS_URI, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]goex.Instrument, len(resp))
+	for i, r := range resp {
+		inst := goex.Instrument{
+			Exchange: goex.HITBTC,
+			Symbol:   r.Id,
+			Pair:     goex.NewCurrencyPair(goex.NewCurrency(r.BaseCurrency, ""), goex.NewCurrency(r.QuoteCurrency, "")),
+			Type:     goex.INSTRUMENT_SPOT,
+			TickSize: r.TickSize,
+			LotSize:  r.QuantityIncrement,
+			MinQty:   r.QuantityIncrement,
+		}
+		inst.FillPrecision()
+		ret[i] = inst
+	}
+	return ret, nil
+}
+
 // https://api.hitbtc.com/#tickers
 
 /*
@@ -395,8 +427,70 @@ func (hitbtc *Hitbtc) GetDepth(size int, currency goex.CurrencyPair) (*goex.Dept
 	return &goex.Depth{AskList: askList, BidList: bidList}, nil
 }
 
+var klinePeriods = map[int]string{
+	goex.KLINE_PERIOD_1MIN:   "M1",
+	goex.KLINE_PERIOD_5MIN:   "M5",
+	goex.KLINE_PERIOD_15MIN:  "M15",
+	goex.KLINE_PERIOD_30MIN:  "M30",
+	goex.KLINE_PERIOD_60MIN:  "H1",
+	goex.KLINE_PERIOD_4H:     "H4",
+	goex.KLINE_PERIOD_1DAY:   "D1",
+	goex.KLINE_PERIOD_1WEEK:  "D7",
+	goex.KLINE_PERIOD_1MONTH: "1M",
+}
+
 func (hitbtc *Hitbtc) GetKlineRecords(currency goex.CurrencyPair, period, size, since int) ([]goex.Kline, error) {
-	panic("not implement")
+	klines, err := hitbtc.GetKlineRecordsDecimal(currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return goex.KlineDecimalsToKlines(klines), nil
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线
+ */
+func (hitbtc *Hitbtc) GetKlineRecordsDecimal(currency goex.CurrencyPair, period, size, since int) ([]goex.KlineDecimal, error) {
+	p, err := goex.KlinePeriodCode(klinePeriods, period)
+	if err != nil {
+		return nil, err
+	}
+	symbol := hitbtc.adaptCurrencyPair(currency).ToSymbol("")
+
+	return goex.GetAllKlineDecimals(int64(since), size, 1000, func(q goex.PageQuery) ([]goex.KlineDecimal, error) {
+		params := url.Values{}
+		params.Set("period", p)
+		params.Set("limit", fmt.Sprint(q.Limit))
+		if q.From > 0 {
+			params.Set("sort", "ASC")
+			params.Set("from", time.Unix(0, q.From*int64(time.Millisecond)).UTC().Format(time.RFC3339))
+		} else {
+			params.Set("sort", "DESC")
+			if q.To > 0 {
+				params.Set("till", time.Unix(0, q.To*int64(time.Millisecond)).UTC().Format(time.RFC3339))
+			}
+		}
+
+		var resp []struct {
+			Timestamp string
+			Open      decimal.Decimal
+			Close     decimal.Decimal
+			Min       decimal.Decimal
+			Max       decimal.Decimal
+			Volume    decimal.Decimal
+		}
+		err := hitbtc.doRequest("GET", KLINE_URI+"/"+symbol+"?"+params.Encode(), &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		klines := make([]goex.KlineDecimal, len(resp))
+		for i, r := range resp {
+			klines[i] = goex.KlineDecimal{Pair: currency, Timestamp: parseTime(r.Timestamp),
+				Open: r.Open, Close: r.Close, High: r.Max, Low: r.Min, Vol: r.Volume}
+		}
+		return klines, nil
+	})
 }
 
 // https://api.hitbtc.com/#candles
@@ -451,8 +545,8 @@ func (hitbtc *Hitbtc) GetKline(currencyPair goex.CurrencyPair, period string, si
 			Timestamp: parseTime(e["timestamp"].(string)),
 			Open:      goex.ToFloat64(e["open"]),
 			Close:     goex.ToFloat64(e["close"]),
-			High:      goex.ToFloat64(e["high"]),
-			Low:       goex.ToFloat64(e["low"]),
+			High:      goex.ToFloat64(e["max"]),
+			Low:       goex.ToFloat64(e["min"]),
 			Vol:       goex.ToFloat64(e["volume"]), // base currency, eg: ETH for pair ETHBTC
 		}
 		klines = append(klines, one)
@@ -1,17 +1,19 @@
 package huobi
 
 import (
+	"context"
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/shopspring/decimal"
 	. "github.com/stephenlyu/GoEx"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -34,23 +36,32 @@ type HuoBiPro struct {
 	httpClient        *http.Client
 	baseUrl           string
 	accountId         string
-	accessKey         string
-	secretKey         string
+	signer            *SignerRef
 	ws                *WsConn
 	createWsLock      sync.Mutex
 	wsTickerHandleMap map[string]func(*Ticker)
 	wsDepthHandleMap  map[string]func(*Depth)
+	symbols           *SymbolMapper
+	logger            Logger
 }
 
 func NewHuoBiPro(client *http.Client, apikey, secretkey, accountId string) *HuoBiPro {
+	return NewHuoBiProWithSigner(client, NewStaticSigner(apikey, secretkey, ""), accountId)
+}
+
+/**
+ * 使用自定义签名器创建实例, 密钥可以来自环境变量、加密文件或凭证代理
+ */
+func NewHuoBiProWithSigner(client *http.Client, signer Signer, accountId string) *HuoBiPro {
 	hbpro := new(HuoBiPro)
 	hbpro.baseUrl = "https://api.huobi.br.com"
 	hbpro.httpClient = client
-	hbpro.accessKey = apikey
-	hbpro.secretKey = secretkey
+	SetServerClockHttpClient(HUOBI_PRO, client)
+	hbpro.signer = NewSignerRef(signer)
 	hbpro.accountId = accountId
 	hbpro.wsDepthHandleMap = make(map[string]func(*Depth))
 	hbpro.wsTickerHandleMap = make(map[string]func(*Ticker))
+	hbpro.symbols = NewSymbolMapper(HUOBI_PRO, NewInstrumentCatalog(HUOBI_PRO, hbpro))
 	return hbpro
 }
 
@@ -64,7 +75,7 @@ func NewHuoBiProSpot(client *http.Client, apikey, secretkey string) *HuoBiPro {
 		panic(err)
 	}
 	hb.accountId = accinfo.Id
-	log.Println("account state :", accinfo.State)
+	hb.log().Info("account state", "account", accinfo.Id, "state", accinfo.State)
 	return hb
 }
 
@@ -78,14 +89,52 @@ func NewHuoBiProPoint(client *http.Client, apikey, secretkey string) *HuoBiPro {
 		panic(err)
 	}
 	hb.accountId = accinfo.Id
-	log.Println("account state :", accinfo.State)
+	hb.log().Info("account state", "account", accinfo.Id, "state", accinfo.State)
 	return hb
 }
 
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (hbpro *HuoBiPro) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	hbpro.logger = logger
+}
+
+func (hbpro *HuoBiPro) log() Logger {
+	return ExchangeLogger(hbpro.logger, HUOBI_PRO)
+}
+
+// https://huobiapi.github.io/docs/spot/v1/cn/#fd6ce2a756, {"status":"ok","data":1629715504949}
+func fetchServerTime(ctx context.Context, client *http.Client) (time.Time, error) {
+	var resp struct {
+		Status  string
+		Data    int64
+		ErrCode string `json:"err-code"`
+		ErrMsg  string `json:"err-msg"`
+	}
+	err := HttpGet4WithContext(ctx, client, "https://api.huobi.pro/v1/common/timestamp", nil, &resp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if resp.Status != "ok" {
+		return time.Time{}, huobiError(resp.ErrCode, resp.ErrMsg)
+	}
+	return time.Unix(0, resp.Data*int64(time.Millisecond)), nil
+}
+
+func init() {
+	RegisterServerClock(NewServerClock(HUOBI_PRO, fetchServerTime))
+}
+
 func (hbpro *HuoBiPro) GetAccountInfo(acc string) (AccountInfo, error) {
 	path := "/v1/account/accounts"
 	params := &url.Values{}
-	hbpro.buildPostForm("GET", path, params)
+	if err := hbpro.buildPostForm("GET", path, params); err != nil {
+		return AccountInfo{}, err
+	}
 
 	//log.Println(hbpro.baseUrl + path + "?" + params.Encode())
 
@@ -95,7 +144,7 @@ func (hbpro *HuoBiPro) GetAccountInfo(acc string) (AccountInfo, error) {
 	}
 	//log.Println(respmap)
 	if respmap["status"].(string) != "ok" {
-		return AccountInfo{}, errors.New(respmap["err-code"].(string))
+		return AccountInfo{}, huobiRespError(respmap)
 	}
 
 	var info AccountInfo
@@ -115,14 +164,20 @@ func (hbpro *HuoBiPro) GetAccountInfo(acc string) (AccountInfo, error) {
 }
 
 func (hbpro *HuoBiPro) GetAccount() (*Account, error) {
+	return hbpro.GetAccountWithContext(context.Background())
+}
+
+func (hbpro *HuoBiPro) GetAccountWithContext(ctx context.Context) (*Account, error) {
 	path := fmt.Sprintf("/v1/account/accounts/%s/balance", hbpro.accountId)
 	params := &url.Values{}
 	params.Set("accountId-id", hbpro.accountId)
-	hbpro.buildPostForm("GET", path, params)
+	if err := hbpro.buildPostForm("GET", path, params); err != nil {
+		return nil, err
+	}
 
 	urlStr := hbpro.baseUrl + path + "?" + params.Encode()
 	//println(urlStr)
-	respmap, err := HttpGet(hbpro.httpClient, urlStr)
+	respmap, err := HttpGetWithContext(ctx, hbpro.httpClient, urlStr)
 
 	if err != nil {
 		return nil, err
@@ -131,7 +186,7 @@ func (hbpro *HuoBiPro) GetAccount() (*Account, error) {
 	//log.Println(respmap)
 
 	if respmap["status"].(string) != "ok" {
-		return nil, errors.New(respmap["err-code"].(string))
+		return nil, huobiRespError(respmap)
 	}
 
 	datamap := respmap["data"].(map[string]interface{})
@@ -171,7 +226,7 @@ func (hbpro *HuoBiPro) GetAccount() (*Account, error) {
 	return acc, nil
 }
 
-func (hbpro *HuoBiPro) placeOrder(amount, price string, pair CurrencyPair, orderType string) (string, error) {
+func (hbpro *HuoBiPro) placeOrder(ctx context.Context, amount, price string, pair CurrencyPair, orderType string) (string, error) {
 	path := "/v1/order/orders/place"
 	params := url.Values{}
 	params.Set("account-id", hbpro.accountId)
@@ -184,9 +239,11 @@ func (hbpro *HuoBiPro) placeOrder(amount, price string, pair CurrencyPair, order
 		params.Set("price", price)
 	}
 
-	hbpro.buildPostForm("POST", path, &params)
+	if err := hbpro.buildPostForm("POST", path, &params); err != nil {
+		return "", err
+	}
 
-	resp, err := HttpPostForm3(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode(), hbpro.toJson(params),
+	resp, err := HttpPostForm3WithContext(ctx, hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode(), hbpro.toJson(params),
 		map[string]string{"Content-Type": "application/json", "Accept-Language": "zh-cn"})
 	if err != nil {
 		return "", err
@@ -199,14 +256,18 @@ func (hbpro *HuoBiPro) placeOrder(amount, price string, pair CurrencyPair, order
 	}
 
 	if respmap["status"].(string) != "ok" {
-		return "", errors.New(respmap["err-code"].(string))
+		return "", huobiRespError(respmap)
 	}
 
 	return respmap["data"].(string), nil
 }
 
 func (hbpro *HuoBiPro) LimitBuy(amount, price string, currency CurrencyPair) (*Order, error) {
-	orderId, err := hbpro.placeOrder(amount, price, currency, "buy-limit")
+	return hbpro.LimitBuyWithContext(context.Background(), amount, price, currency)
+}
+
+func (hbpro *HuoBiPro) LimitBuyWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	orderId, err := hbpro.placeOrder(ctx, amount, price, currency, "buy-limit")
 	if err != nil {
 		return nil, err
 	}
@@ -220,7 +281,11 @@ func (hbpro *HuoBiPro) LimitBuy(amount, price string, currency CurrencyPair) (*O
 }
 
 func (hbpro *HuoBiPro) LimitSell(amount, price string, currency CurrencyPair) (*Order, error) {
-	orderId, err := hbpro.placeOrder(amount, price, currency, "sell-limit")
+	return hbpro.LimitSellWithContext(context.Background(), amount, price, currency)
+}
+
+func (hbpro *HuoBiPro) LimitSellWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	orderId, err := hbpro.placeOrder(ctx, amount, price, currency, "sell-limit")
 	if err != nil {
 		return nil, err
 	}
@@ -234,7 +299,11 @@ func (hbpro *HuoBiPro) LimitSell(amount, price string, currency CurrencyPair) (*
 }
 
 func (hbpro *HuoBiPro) MarketBuy(amount, price string, currency CurrencyPair) (*Order, error) {
-	orderId, err := hbpro.placeOrder(amount, price, currency, "buy-market")
+	return hbpro.MarketBuyWithContext(context.Background(), amount, price, currency)
+}
+
+func (hbpro *HuoBiPro) MarketBuyWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	orderId, err := hbpro.placeOrder(ctx, amount, price, currency, "buy-market")
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +317,11 @@ func (hbpro *HuoBiPro) MarketBuy(amount, price string, currency CurrencyPair) (*
 }
 
 func (hbpro *HuoBiPro) MarketSell(amount, price string, currency CurrencyPair) (*Order, error) {
-	orderId, err := hbpro.placeOrder(amount, price, currency, "sell-market")
+	return hbpro.MarketSellWithContext(context.Background(), amount, price, currency)
+}
+
+func (hbpro *HuoBiPro) MarketSellWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	orderId, err := hbpro.placeOrder(ctx, amount, price, currency, "sell-market")
 	if err != nil {
 		return nil, err
 	}
@@ -305,16 +378,22 @@ func (hbpro *HuoBiPro) parseOrder(ordmap map[string]interface{}) Order {
 }
 
 func (hbpro *HuoBiPro) GetOneOrder(orderId string, currency CurrencyPair) (*Order, error) {
+	return hbpro.GetOneOrderWithContext(context.Background(), orderId, currency)
+}
+
+func (hbpro *HuoBiPro) GetOneOrderWithContext(ctx context.Context, orderId string, currency CurrencyPair) (*Order, error) {
 	path := "/v1/order/orders/" + orderId
 	params := url.Values{}
-	hbpro.buildPostForm("GET", path, &params)
-	respmap, err := HttpGet(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode())
+	if err := hbpro.buildPostForm("GET", path, &params); err != nil {
+		return nil, err
+	}
+	respmap, err := HttpGetWithContext(ctx, hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode())
 	if err != nil {
 		return nil, err
 	}
 
 	if respmap["status"].(string) != "ok" {
-		return nil, errors.New(respmap["err-code"].(string))
+		return nil, huobiRespError(respmap)
 	}
 
 	datamap := respmap["data"].(map[string]interface{})
@@ -325,7 +404,11 @@ func (hbpro *HuoBiPro) GetOneOrder(orderId string, currency CurrencyPair) (*Orde
 }
 
 func (hbpro *HuoBiPro) GetUnfinishOrders(currency CurrencyPair) ([]Order, error) {
-	return hbpro.getOrders(queryOrdersParams{
+	return hbpro.GetUnfinishOrdersWithContext(context.Background(), currency)
+}
+
+func (hbpro *HuoBiPro) GetUnfinishOrdersWithContext(ctx context.Context, currency CurrencyPair) ([]Order, error) {
+	return hbpro.getOrders(ctx, queryOrdersParams{
 		pair:   currency,
 		states: "pre-submitted,submitted,partial-filled",
 		size:   100,
@@ -334,10 +417,16 @@ func (hbpro *HuoBiPro) GetUnfinishOrders(currency CurrencyPair) ([]Order, error)
 }
 
 func (hbpro *HuoBiPro) CancelOrder(orderId string, currency CurrencyPair) (bool, error) {
+	return hbpro.CancelOrderWithContext(context.Background(), orderId, currency)
+}
+
+func (hbpro *HuoBiPro) CancelOrderWithContext(ctx context.Context, orderId string, currency CurrencyPair) (bool, error) {
 	path := fmt.Sprintf("/v1/order/orders/%s/submitcancel", orderId)
 	params := url.Values{}
-	hbpro.buildPostForm("POST", path, &params)
-	resp, err := HttpPostForm3(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode(), hbpro.toJson(params),
+	if err := hbpro.buildPostForm("POST", path, &params); err != nil {
+		return false, err
+	}
+	resp, err := HttpPostForm3WithContext(ctx, hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode(), hbpro.toJson(params),
 		map[string]string{"Content-Type": "application/json", "Accept-Language": "zh-cn"})
 	if err != nil {
 		return false, err
@@ -357,7 +446,11 @@ func (hbpro *HuoBiPro) CancelOrder(orderId string, currency CurrencyPair) (bool,
 }
 
 func (hbpro *HuoBiPro) GetOrderHistorys(currency CurrencyPair, currentPage, pageSize int) ([]Order, error) {
-	return hbpro.getOrders(queryOrdersParams{
+	return hbpro.GetOrderHistorysWithContext(context.Background(), currency, currentPage, pageSize)
+}
+
+func (hbpro *HuoBiPro) GetOrderHistorysWithContext(ctx context.Context, currency CurrencyPair, currentPage, pageSize int) ([]Order, error) {
+	return hbpro.getOrders(ctx, queryOrdersParams{
 		pair:   currency,
 		size:   pageSize,
 		states: "partial-canceled,filled",
@@ -376,7 +469,7 @@ type queryOrdersParams struct {
 	pair CurrencyPair
 }
 
-func (hbpro *HuoBiPro) getOrders(queryparams queryOrdersParams) ([]Order, error) {
+func (hbpro *HuoBiPro) getOrders(ctx context.Context, queryparams queryOrdersParams) ([]Order, error) {
 	path := "/v1/order/orders"
 	params := url.Values{}
 	params.Set("symbol", strings.ToLower(queryparams.pair.ToSymbol("")))
@@ -390,14 +483,16 @@ func (hbpro *HuoBiPro) getOrders(queryparams queryOrdersParams) ([]Order, error)
 		params.Set("size", fmt.Sprint(queryparams.size))
 	}
 
-	hbpro.buildPostForm("GET", path, &params)
-	respmap, err := HttpGet(hbpro.httpClient, fmt.Sprintf("%s%s?%s", hbpro.baseUrl, path, params.Encode()))
+	if err := hbpro.buildPostForm("GET", path, &params); err != nil {
+		return nil, err
+	}
+	respmap, err := HttpGetWithContext(ctx, hbpro.httpClient, fmt.Sprintf("%s%s?%s", hbpro.baseUrl, path, params.Encode()))
 	if err != nil {
 		return nil, err
 	}
 
 	if respmap["status"].(string) != "ok" {
-		return nil, errors.New(respmap["err-code"].(string))
+		return nil, huobiRespError(respmap)
 	}
 
 	datamap := respmap["data"].([]interface{})
@@ -412,15 +507,115 @@ func (hbpro *HuoBiPro) getOrders(queryparams queryOrdersParams) ([]Order, error)
 	return orders, nil
 }
 
+type matchResult struct {
+	Id           int64           `json:"id"`
+	OrderId      int64           `json:"order-id"`
+	MatchId      int64           `json:"match-id"`
+	Symbol       string          `json:"symbol"`
+	Type         string          `json:"type"`
+	Price        decimal.Decimal `json:"price"`
+	FilledAmount decimal.Decimal `json:"filled-amount"`
+	FilledFees   decimal.Decimal `json:"filled-fees"`
+	FeeCurrency  string          `json:"fee-currency"`
+	Role         string          `json:"role"`
+	CreatedAt    int64           `json:"created-at"`
+}
+
+/**
+ * 获取成交明细, from/to为毫秒时间戳, 0表示不限制, 按时间倒序返回
+ */
+func (hbpro *HuoBiPro) GetFills(currency CurrencyPair, from, to int64, size int) ([]FillDecimal, error) {
+	path := "/v1/order/matchresults"
+	params := url.Values{}
+	params.Set("symbol", strings.ToLower(currency.ToSymbol("")))
+	if from > 0 {
+		params.Set("start-time", fmt.Sprint(from))
+	}
+	if to > 0 {
+		params.Set("end-time", fmt.Sprint(to))
+	}
+	if size > 0 {
+		params.Set("size", fmt.Sprint(size))
+	}
+
+	if err := hbpro.buildPostForm("GET", path, &params); err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Status  string
+		ErrCode string `json:"err-code"`
+		ErrMsg  string `json:"err-msg"`
+		Data    []matchResult
+	}
+	err := HttpGet4(hbpro.httpClient, fmt.Sprintf("%s%s?%s", hbpro.baseUrl, path, params.Encode()), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Status != "ok" {
+		return nil, huobiError(resp.ErrCode, resp.ErrMsg)
+	}
+
+	ret := make([]FillDecimal, len(resp.Data))
+	for i, o := range resp.Data {
+		side := BUY
+		if strings.HasPrefix(o.Type, "sell") {
+			side = SELL
+		}
+		ret[i] = FillDecimal{
+			FillId:          fmt.Sprint(o.Id),
+			OrderId:         fmt.Sprint(o.OrderId),
+			Currency:        currency,
+			Side:            TradeSide(side),
+			Price:           o.Price,
+			Qty:             o.FilledAmount,
+			Fee:             o.FilledFees,
+			FeeCurrency:     strings.ToUpper(o.FeeCurrency),
+			IsMaker:         o.Role == "maker",
+			TransactionTime: o.CreatedAt,
+		}
+	}
+
+	return ret, nil
+}
+
+/**
+ * 从to开始按时间向前翻页, 获取[from, to]内的全部成交明细
+ */
+func (hbpro *HuoBiPro) GetAllFills(currency CurrencyPair, from, to int64, size int) ([]FillDecimal, error) {
+	return GetAllFillsByCursor(size, func(cursor string, limit int) ([]FillDecimal, string, error) {
+		end := to
+		if cursor != "" {
+			end, _ = strconv.ParseInt(cursor, 10, 64)
+		}
+		fills, err := hbpro.GetFills(currency, from, end, limit)
+		if err != nil || len(fills) < limit {
+			return fills, "", err
+		}
+		next := end
+		for _, f := range fills {
+			if next == 0 || f.TransactionTime < next {
+				next = f.TransactionTime
+			}
+		}
+		return fills, fmt.Sprint(next), nil
+	})
+}
+
 func (hbpro *HuoBiPro) GetTicker(currencyPair CurrencyPair) (*Ticker, error) {
+	return hbpro.GetTickerWithContext(context.Background(), currencyPair)
+}
+
+func (hbpro *HuoBiPro) GetTickerWithContext(ctx context.Context, currencyPair CurrencyPair) (*Ticker, error) {
 	url := hbpro.baseUrl + "/market/detail/merged?symbol=" + strings.ToLower(currencyPair.ToSymbol(""))
-	respmap, err := HttpGet(hbpro.httpClient, url)
+	respmap, err := HttpGetWithContext(ctx, hbpro.httpClient, url)
 	if err != nil {
 		return nil, err
 	}
 
 	if respmap["status"].(string) == "error" {
-		return nil, errors.New(respmap["err-msg"].(string))
+		return nil, huobiRespError(respmap)
 	}
 
 	tickmap, ok := respmap["tick"].(map[string]interface{})
@@ -448,15 +643,131 @@ func (hbpro *HuoBiPro) GetTicker(currencyPair CurrencyPair) (*Ticker, error) {
 	return ticker, nil
 }
 
+type symbolInfo struct {
+	BaseCurrency    string          `json:"base-currency"`
+	QuoteCurrency   string          `json:"quote-currency"`
+	PricePrecision  int32           `json:"price-precision"`
+	AmountPrecision int32           `json:"amount-precision"`
+	Symbol          string          `json:"symbol"`
+	State           string          `json:"state"`
+	MinOrderAmt     decimal.Decimal `json:"min-order-amt"`
+	MinOrderValue   decimal.Decimal `json:"min-order-value"`
+}
+
+func (this *symbolInfo) toInstrument() Instrument {
+	inst := Instrument{
+		Exchange:        HUOBI_PRO,
+		Symbol:          this.Symbol,
+		Pair:            NewCurrencyPair(NewCurrency(strings.ToUpper(this.BaseCurrency), ""), NewCurrency(strings.ToUpper(this.QuoteCurrency), "")),
+		Type:            INSTRUMENT_SPOT,
+		PricePrecision:  this.PricePrecision,
+		AmountPrecision: this.AmountPrecision,
+		MinQty:          this.MinOrderAmt,
+		MinNotional:     this.MinOrderValue,
+	}
+	switch this.State {
+	case "online":
+		inst.Status = INSTRUMENT_STATUS_TRADING
+	case "suspend", "pre-online":
+		inst.Status = INSTRUMENT_STATUS_SUSPENDED
+	case "offline":
+		inst.Status = INSTRUMENT_STATUS_DELISTED
+	}
+	inst.FillPrecision()
+	return inst
+}
+
+func (hbpro *HuoBiPro) GetInstruments() ([]Instrument, error) {
+	var resp struct {
+		Status  string
+		Data    []symbolInfo
+		ErrCode string `json:"err-code"`
+		ErrMsg  string `json:"err-msg"`
+	}
+	err := HttpGet4(hbpro.httpClient, hbpro.baseUrl+"/v1/common/symbols", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != "ok" {
+		return nil, huobiError(resp.ErrCode, resp.ErrMsg)
+	}
+
+	ret := make([]Instrument, len(resp.Data))
+	for i := range resp.Data {
+		ret[i] = resp.Data[i].toInstrument()
+	}
+	return ret, nil
+}
+
+/**
+ * 所有交易对的24小时行情, amount为成交量, vol为成交额. 交易对代码通过/v1/common/symbols转换
+ */
+func (hbpro *HuoBiPro) GetAllTickers() (map[CurrencyPair]*TickerDecimal, error) {
+	var resp struct {
+		Status string
+		Ts     int64
+		Data   []struct {
+			Symbol string
+			Open   decimal.Decimal
+			High   decimal.Decimal
+			Low    decimal.Decimal
+			Close  decimal.Decimal
+			Amount decimal.Decimal
+			Vol    decimal.Decimal
+			Bid    decimal.Decimal
+			Ask    decimal.Decimal
+		}
+		ErrCode string `json:"err-code"`
+		ErrMsg  string `json:"err-msg"`
+	}
+	err := HttpGet4(hbpro.httpClient, hbpro.baseUrl+"/market/tickers", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != "ok" {
+		return nil, huobiError(resp.ErrCode, resp.ErrMsg)
+	}
+
+	ret := make(map[CurrencyPair]*TickerDecimal, len(resp.Data))
+	for _, r := range resp.Data {
+		inst, err := hbpro.symbols.FromNative(r.Symbol)
+		if errors.Is(err, ErrInstrumentNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ticker := &TickerDecimal{
+			Pair:     inst.Pair,
+			Last:     r.Close,
+			Buy:      r.Bid,
+			Sell:     r.Ask,
+			Open:     r.Open,
+			High:     r.High,
+			Low:      r.Low,
+			Vol:      r.Amount,
+			QuoteVol: r.Vol,
+			Date:     uint64(resp.Ts / 1000),
+		}
+		ticker.FillChange()
+		ret[inst.Pair] = ticker
+	}
+	return ret, nil
+}
+
 func (hbpro *HuoBiPro) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
+	return hbpro.GetDepthWithContext(context.Background(), size, currency)
+}
+
+func (hbpro *HuoBiPro) GetDepthWithContext(ctx context.Context, size int, currency CurrencyPair) (*Depth, error) {
 	url := hbpro.baseUrl + "/market/depth?symbol=%s&type=step0"
-	respmap, err := HttpGet(hbpro.httpClient, fmt.Sprintf(url, strings.ToLower(currency.ToSymbol(""))))
+	respmap, err := HttpGetWithContext(ctx, hbpro.httpClient, fmt.Sprintf(url, strings.ToLower(currency.ToSymbol(""))))
 	if err != nil {
 		return nil, err
 	}
 
 	if "ok" != respmap["status"].(string) {
-		return nil, errors.New(respmap["err-msg"].(string))
+		return nil, huobiRespError(respmap)
 	}
 
 	tick, _ := respmap["tick"].(map[string]interface{})
@@ -498,23 +809,42 @@ func (hbpro *HuoBiPro) GetDepth(size int, currency CurrencyPair) (*Depth, error)
 }
 
 func (hbpro *HuoBiPro) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
+	return hbpro.GetKlineRecordsWithContext(context.Background(), currency, period, size, since)
+}
+
+func (hbpro *HuoBiPro) GetKlineRecordsWithContext(ctx context.Context, currency CurrencyPair, period, size, since int) ([]Kline, error) {
 	panic("not implement")
 }
 
 //非个人，整个交易所的交易记录
 func (hbpro *HuoBiPro) GetTrades(currencyPair CurrencyPair, since int64) ([]Trade, error) {
+	return hbpro.GetTradesWithContext(context.Background(), currencyPair, since)
+}
+
+func (hbpro *HuoBiPro) GetTradesWithContext(ctx context.Context, currencyPair CurrencyPair, since int64) ([]Trade, error) {
 	panic("not implement")
 }
 
+/**
+ * 替换签名器, 用于运行时轮换密钥
+ */
+func (hbpro *HuoBiPro) SetSigner(signer Signer) {
+	hbpro.signer.Set(signer)
+}
+
 func (hbpro *HuoBiPro) buildPostForm(reqMethod, path string, postForm *url.Values) error {
-	postForm.Set("AccessKeyId", hbpro.accessKey)
 	postForm.Set("SignatureMethod", "HmacSHA256")
 	postForm.Set("SignatureVersion", "2")
-	postForm.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05"))
+	postForm.Set("Timestamp", ServerTime(HUOBI_PRO).UTC().Format("2006-01-02T15:04:05"))
 	domain := strings.Replace(hbpro.baseUrl, "https://", "", len(hbpro.baseUrl))
-	payload := fmt.Sprintf("%s\n%s\n%s\n%s", reqMethod, domain, path, postForm.Encode())
-	sign, _ := GetParamHmacSHA256Base64Sign(hbpro.secretKey, payload)
-	postForm.Set("Signature", sign)
+	signature, err := hbpro.signer.Sign(HMAC_SHA256_BASE64, func(apiKey string) string {
+		postForm.Set("AccessKeyId", apiKey)
+		return fmt.Sprintf("%s\n%s\n%s\n%s", reqMethod, domain, path, postForm.Encode())
+	})
+	if err != nil {
+		return err
+	}
+	postForm.Set("Signature", signature.Sign)
 	return nil
 }
 
@@ -535,6 +865,7 @@ func (hbpro *HuoBiPro) createWsConn() {
 
 		if hbpro.ws == nil {
 			hbpro.ws = NewWsConn("wss://api.huobi.br.com/ws")
+			hbpro.ws.SetLogger(hbpro.logger)
 			hbpro.ws.Heartbeat(func() interface{} {
 				return map[string]interface{}{
 					"ping": time.Now().Unix()}
@@ -546,7 +877,7 @@ func (hbpro *HuoBiPro) createWsConn() {
 				datamap := make(map[string]interface{})
 				err := json.Unmarshal(data, &datamap)
 				if err != nil {
-					log.Println("json unmarshal error for ", string(data))
+					hbpro.log().Warn("decode websocket message failed", "err", err, "data", string(data))
 					return
 				}
 
@@ -564,13 +895,13 @@ func (hbpro *HuoBiPro) createWsConn() {
 				}
 
 				if datamap["id"] != nil { //忽略订阅成功的回执消息
-					log.Println(string(data))
+					hbpro.log().Debug("subscribe response", "data", string(data))
 					return
 				}
 
 				ch, isok := datamap["ch"].(string)
 				if !isok {
-					log.Println("error:", string(data))
+					hbpro.log().Warn("unexpected websocket message", "data", string(data))
 					return
 				}
 
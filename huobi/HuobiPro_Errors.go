@@ -0,0 +1,46 @@
+package huobi
+
+import (
+	. "github.com/stephenlyu/GoEx"
+)
+
+// https://huobiapi.github.io/docs/spot/v1/cn/#5ea2e0cde2-5, 接口返回status为error时的err-code
+var huobiErrorCodes = ErrorCodes{
+	"api-signature-not-valid":                   ERR_AUTH,
+	"api-signature-check-failed":                ERR_AUTH,
+	"login-required":                            ERR_AUTH,
+	"invalid-access-key":                        ERR_AUTH,
+	"api-key-expired":                           ERR_AUTH,
+	"invalid-timestamp":                         ERR_NONCE,
+	"api-not-support-temp-addr":                 ERR_AUTH,
+	"bad-request":                               ERR_INVALID_PARAM,
+	"invalid-parameter":                         ERR_INVALID_PARAM,
+	"too-many-request":                          ERR_RATE_LIMITED,
+	"api-limit-reached":                         ERR_RATE_LIMITED,
+	"base-symbol-error":                         ERR_INVALID_SYMBOL,
+	"base-currency-error":                       ERR_INVALID_SYMBOL,
+	"invalid-symbol":                            ERR_INVALID_SYMBOL,
+	"base-record-invalid":                       ERR_ORDER_NOT_FOUND,
+	"order-not-found":                           ERR_ORDER_NOT_FOUND,
+	"account-frozen-balance-insufficient-error": ERR_INSUFFICIENT_BALANCE,
+	"account-balance-insufficient-error":        ERR_INSUFFICIENT_BALANCE,
+	"insufficient-balance":                      ERR_INSUFFICIENT_BALANCE,
+	"order-accountbalance-error":                ERR_INSUFFICIENT_BALANCE,
+	"order-orderprice-precision-error":          ERR_INVALID_PARAM,
+	"order-orderamount-precision-error":         ERR_INVALID_PARAM,
+	"order-value-min-error":                     ERR_INVALID_PARAM,
+	"order-limitorder-amount-min-error":         ERR_INVALID_PARAM,
+	"order-limitorder-amount-max-error":         ERR_INVALID_PARAM,
+	"base-system-error":                         ERR_MAINTENANCE,
+	"system-maintenance":                        ERR_MAINTENANCE,
+}
+
+func huobiError(code, msg string) error {
+	return huobiErrorCodes.NewError(HUOBI_PRO, code, msg)
+}
+
+func huobiRespError(respmap map[string]interface{}) error {
+	code, _ := respmap["err-code"].(string)
+	msg, _ := respmap["err-msg"].(string)
+	return huobiError(code, msg)
+}
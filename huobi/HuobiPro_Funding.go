@@ -0,0 +1,319 @@
+package huobi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	. "github.com/stephenlyu/GoEx"
+)
+
+type depositWithdraw struct {
+	Id         int64
+	Type       string
+	Currency   string
+	Chain      string
+	TxHash     string `json:"tx-hash"`
+	Amount     decimal.Decimal
+	Address    string
+	AddressTag string `json:"address-tag"`
+	Fee        decimal.Decimal
+	State      string
+	CreatedAt  int64 `json:"created-at"`
+	UpdatedAt  int64 `json:"updated-at"`
+}
+
+func (this *depositWithdraw) ToFundingRecord() *FundingRecord {
+	var status FundingStatus
+	var _type FundingType
+	if this.Type == "deposit" {
+		_type = FUNDING_DEPOSIT
+		switch this.State {
+		case "confirmed", "safe":
+			status = FUNDING_SUCCESS
+		case "orphan":
+			status = FUNDING_FAILED
+		default:
+			status = FUNDING_PROCESSING
+		}
+	} else {
+		_type = FUNDING_WITHDRAW
+		switch this.State {
+		case "pass", "wallet-transfer":
+			status = FUNDING_PROCESSING
+		case "confirmed":
+			status = FUNDING_SUCCESS
+		case "canceled", "repealed":
+			status = FUNDING_CANCELED
+		case "reject", "wallet-reject", "confirm-error":
+			status = FUNDING_FAILED
+		default:
+			status = FUNDING_PENDING
+		}
+	}
+
+	return &FundingRecord{
+		Id:        fmt.Sprint(this.Id),
+		Type:      _type,
+		Currency:  NewCurrency(strings.ToUpper(this.Currency), ""),
+		Amount:    this.Amount,
+		Fee:       this.Fee,
+		Address:   this.Address,
+		Tag:       this.AddressTag,
+		TxId:      this.TxHash,
+		Status:    status,
+		RawStatus: this.State,
+		Timestamp: this.CreatedAt,
+	}
+}
+
+func (hbpro *HuoBiPro) GetDepositAddress(currency Currency) ([]DepositAddress, error) {
+	path := "/v2/account/deposit/address"
+	params := url.Values{}
+	params.Set("currency", strings.ToLower(currency.Symbol))
+	if err := hbpro.buildPostForm("GET", path, &params); err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Code    int
+		Message string
+		Data    []struct {
+			Currency   string
+			Address    string
+			AddressTag string
+			Chain      string
+		}
+	}
+	err := HttpGet4(hbpro.httpClient, fmt.Sprintf("%s%s?%s", hbpro.baseUrl, path, params.Encode()), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Code != 200 {
+		return nil, huobiError(fmt.Sprint(resp.Code), resp.Message)
+	}
+
+	ret := make([]DepositAddress, len(resp.Data))
+	for i, a := range resp.Data {
+		ret[i] = DepositAddress{
+			Currency: NewCurrency(strings.ToUpper(a.Currency), ""),
+			Address:  a.Address,
+			Tag:      a.AddressTag,
+			Chain:    a.Chain,
+		}
+	}
+	return ret, nil
+}
+
+func (hbpro *HuoBiPro) getDepositWithdraws(currency Currency, _type string) ([]FundingRecord, error) {
+	path := "/v1/query/deposit-withdraw"
+	params := url.Values{}
+	params.Set("currency", strings.ToLower(currency.Symbol))
+	params.Set("type", _type)
+	params.Set("size", "100")
+	if err := hbpro.buildPostForm("GET", path, &params); err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Status  string
+		ErrCode string `json:"err-code"`
+		ErrMsg  string `json:"err-msg"`
+		Data    []depositWithdraw
+	}
+	err := HttpGet4(hbpro.httpClient, fmt.Sprintf("%s%s?%s", hbpro.baseUrl, path, params.Encode()), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Status != "ok" {
+		return nil, huobiError(resp.ErrCode, resp.ErrMsg)
+	}
+
+	ret := make([]FundingRecord, len(resp.Data))
+	for i := range resp.Data {
+		ret[i] = *resp.Data[i].ToFundingRecord()
+	}
+	return ret, nil
+}
+
+func (hbpro *HuoBiPro) GetDeposits(currency Currency) ([]FundingRecord, error) {
+	return hbpro.getDepositWithdraws(currency, "deposit")
+}
+
+func (hbpro *HuoBiPro) GetWithdrawals(currency Currency) ([]FundingRecord, error) {
+	return hbpro.getDepositWithdraws(currency, "withdraw")
+}
+
+func (hbpro *HuoBiPro) GetWithdrawal(currency Currency, withdrawId string) (*FundingRecord, error) {
+	records, err := hbpro.GetWithdrawals(currency)
+	if err != nil {
+		return nil, err
+	}
+	return FindFundingRecord(records, withdrawId)
+}
+
+func (hbpro *HuoBiPro) CreateWithdrawal(param WithdrawParam) (string, error) {
+	fee := param.Fee
+	if fee.IsZero() {
+		fees, err := hbpro.GetWithdrawalFees(param.Currency)
+		if err != nil {
+			return "", err
+		}
+		// 有多条链时必须指定链, 否则手续费可能与交易所默认的链不符
+		var chains []WithdrawFeeDecimal
+		for _, f := range fees {
+			if param.Chain == "" || strings.EqualFold(f.Chain, param.Chain) {
+				chains = append(chains, f)
+			}
+		}
+		switch {
+		case len(chains) == 0:
+			return "", fmt.Errorf("%s withdraw chain %q not found: %w", param.Currency.Symbol, param.Chain, ERR_INVALID_PARAM)
+		case len(chains) > 1:
+			return "", fmt.Errorf("%s has %d withdraw chains, chain is required: %w", param.Currency.Symbol, len(chains), ERR_INVALID_PARAM)
+		}
+		fee = chains[0].MinFee
+	}
+
+	path := "/v1/dw/withdraw/api/create"
+	params := url.Values{}
+	params.Set("address", param.Address)
+	params.Set("amount", param.Amount.String())
+	params.Set("currency", strings.ToLower(param.Currency.Symbol))
+	params.Set("fee", fee.String())
+	if param.Chain != "" {
+		params.Set("chain", param.Chain)
+	}
+	if param.Tag != "" {
+		params.Set("addr-tag", param.Tag)
+	}
+
+	if err := hbpro.buildPostForm("POST", path, &params); err != nil {
+		return "", err
+	}
+
+	resp, err := HttpPostForm3(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode(), hbpro.toJson(params),
+		map[string]string{"Content-Type": "application/json", "Accept-Language": "zh-cn"})
+	if err != nil {
+		return "", err
+	}
+
+	var data struct {
+		Status  string
+		ErrCode string `json:"err-code"`
+		ErrMsg  string `json:"err-msg"`
+		Data    decimal.Decimal
+	}
+	err = json.Unmarshal(resp, &data)
+	if err != nil {
+		return "", err
+	}
+
+	if data.Status != "ok" {
+		return "", huobiError(data.ErrCode, data.ErrMsg)
+	}
+
+	return data.Data.String(), nil
+}
+
+func (hbpro *HuoBiPro) GetWithdrawalFees(currency Currency) ([]WithdrawFeeDecimal, error) {
+	reqUrl := hbpro.baseUrl + "/v2/reference/currencies"
+	if currency != UNKNOWN {
+		reqUrl += "?currency=" + strings.ToLower(currency.Symbol)
+	}
+
+	var resp struct {
+		Code    int
+		Message string
+		Data    []struct {
+			Currency string
+			Chains   []struct {
+				Chain                  string
+				TransactFeeWithdraw    decimal.Decimal
+				MinTransactFeeWithdraw decimal.Decimal
+				MaxTransactFeeWithdraw decimal.Decimal
+				MinWithdrawAmt         decimal.Decimal
+				WithdrawFeeType        string
+			}
+		}
+	}
+	err := HttpGet4(hbpro.httpClient, reqUrl, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Code != 200 {
+		return nil, huobiError(fmt.Sprint(resp.Code), resp.Message)
+	}
+
+	var ret []WithdrawFeeDecimal
+	for _, c := range resp.Data {
+		for _, chain := range c.Chains {
+			fee := WithdrawFeeDecimal{
+				Currency:  NewCurrency(strings.ToUpper(c.Currency), ""),
+				Chain:     chain.Chain,
+				MinFee:    chain.MinTransactFeeWithdraw,
+				MaxFee:    chain.MaxTransactFeeWithdraw,
+				MinAmount: chain.MinWithdrawAmt,
+			}
+			// 固定手续费
+			if chain.WithdrawFeeType == "fixed" {
+				fee.MinFee = chain.TransactFeeWithdraw
+				fee.MaxFee = chain.TransactFeeWithdraw
+			}
+			ret = append(ret, fee)
+		}
+	}
+	return ret, nil
+}
+
+/**
+ * 目前只支持币币账户和交割合约账户之间的划转
+ */
+func (hbpro *HuoBiPro) TransferBetweenWallets(currency Currency, amount decimal.Decimal, from, to WalletType) (string, error) {
+	var _type string
+	if from == WALLET_SPOT && to == WALLET_FUTURE {
+		_type = "pro-to-futures"
+	} else if from == WALLET_FUTURE && to == WALLET_SPOT {
+		_type = "futures-to-pro"
+	} else {
+		return "", EX_ERR_NOT_SUPPORTED
+	}
+
+	path := "/v1/futures/transfer"
+	params := url.Values{}
+	params.Set("currency", strings.ToLower(currency.Symbol))
+	params.Set("amount", amount.String())
+	params.Set("type", _type)
+
+	if err := hbpro.buildPostForm("POST", path, &params); err != nil {
+		return "", err
+	}
+
+	resp, err := HttpPostForm3(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode(), hbpro.toJson(params),
+		map[string]string{"Content-Type": "application/json", "Accept-Language": "zh-cn"})
+	if err != nil {
+		return "", err
+	}
+
+	var data struct {
+		Status  string
+		ErrCode string `json:"err-code"`
+		ErrMsg  string `json:"err-msg"`
+		Data    decimal.Decimal
+	}
+	err = json.Unmarshal(resp, &data)
+	if err != nil {
+		return "", err
+	}
+
+	if data.Status != "ok" {
+		return "", huobiError(data.ErrCode, data.ErrMsg)
+	}
+
+	return data.Data.String(), nil
+}
@@ -11,6 +11,9 @@ import (
 	"log"
 )
 
+var _ goex.FillsAPI = (*HuoBiPro)(nil)
+var _ goex.APIContext = (*HuoBiPro)(nil)
+
 var httpProxyClient = &http.Client{
 	Transport: &http.Transport{
 		Proxy: func(req *http.Request) (*url.URL, error) {
@@ -39,6 +42,14 @@ func TestHuobiPro_GetTicker(t *testing.T) {
 	t.Log(ticker)
 }
 
+func TestHuobiPro_GetAllTickers(t *testing.T) {
+	tickers, err := hbpro.GetAllTickers()
+	t.Log(len(tickers), err)
+	if err == nil {
+		t.Log(tickers[goex.BTC_USDT])
+	}
+}
+
 func TestHuobiPro_GetDepth(t *testing.T) {
 	dep, err := hbpro.GetDepth(2, goex.LTC_USDT)
 	assert.Nil(t, err)
@@ -121,3 +132,21 @@ func TestHuobiPro_GetDepthWithWs(t *testing.T) {
 	})
 	time.Sleep(time.Minute)
 }
+
+func TestHuobiPro_GetFills(t *testing.T) {
+	fills, err := hbpro.GetFills(goex.BTC_USDT, 0, 0, 100)
+	assert.Nil(t, err)
+	t.Log(fills)
+}
+
+func TestHuobiPro_GetDepositAddress(t *testing.T) {
+	ret, err := hbpro.GetDepositAddress(goex.USDT)
+	assert.Nil(t, err)
+	t.Log(ret)
+}
+
+func TestHuobiPro_GetWithdrawalFees(t *testing.T) {
+	ret, err := hbpro.GetWithdrawalFees(goex.USDT)
+	assert.Nil(t, err)
+	t.Log(ret)
+}
@@ -1,6 +1,8 @@
 package huobifuture
 
 import (
+	"time"
+
 	"github.com/shopspring/decimal"
 	"github.com/stephenlyu/GoEx"
 )
@@ -16,6 +18,38 @@ type ContractInfo struct {
 	ContractStatus int 				`json:"contract_status"`
 }
 
+/**
+ * 币本位交割合约, 以USD计价, 每次下单的最小单位为1张
+ */
+func (this *ContractInfo) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Symbol: this.ContractCode,
+		Pair: goex.NewCurrencyPair(goex.NewCurrency(this.Symbol, ""), goex.USD),
+		Type: goex.INSTRUMENT_FUTURE,
+		TickSize: this.PriceTick,
+		LotSize: decimal.New(1, 0),
+		MinQty: decimal.New(1, 0),
+		ContractValue: this.ContractSize,
+		Multiplier: decimal.New(1, 0),
+		SettleCurrency: goex.NewCurrency(this.Symbol, ""),
+	}
+	// 交割时间为交割日16:00(北京时间)
+	if delivery, err := time.Parse("20060102", this.DeliveryDate); err == nil {
+		inst.Expiry = delivery.Add(8 * time.Hour)
+	}
+	// 0已下市 1上市 2待上市 3停牌 4待上市中 5结算中 6交割中 7结算完成 8交割完成
+	switch this.ContractStatus {
+	case 1:
+		inst.Status = goex.INSTRUMENT_STATUS_TRADING
+	case 0, 7, 8:
+		inst.Status = goex.INSTRUMENT_STATUS_DELISTED
+	default:
+		inst.Status = goex.INSTRUMENT_STATUS_SUSPENDED
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 const (
 	DirectionBuy = "buy"
 	DirectionSell = "sell"
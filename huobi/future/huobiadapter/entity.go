@@ -4,59 +4,46 @@ import (
 	"github.com/stephenlyu/tds/entity"
 	"fmt"
 	"strings"
-	"github.com/z-ray/log"
 	"regexp"
 )
 
 var CODE_PATTERN, _ = regexp.Compile("[0-9]+")
 
 // 获取交易代码
-func ContractCodeFromSecurity(security *entity.Security) string {
+func ContractCodeFromSecurity(security *entity.Security) (string, error) {
 	switch security.Code {
 	case "QFUT", "TFUT", "NFUT":
-		contractCode, err := DEFAULT_INSTRUMENT_MANAGER.GetContractCode(SymbolFromSecurity(security))
+		symbol, err := SymbolFromSecurity(security)
 		if err != nil {
-			panic(err)
+			return "", err
 		}
-		return contractCode
+		return DEFAULT_INSTRUMENT_MANAGER.GetContractCode(symbol)
 	default:
 		if CODE_PATTERN.Match([]byte(security.Code)) {
-			return fmt.Sprintf("%s%s", security.Category, security.GetCode())
+			return fmt.Sprintf("%s%s", security.Category, security.GetCode()), nil
 		}
 
-		panic("Unknown contract type")
+		return "", fmt.Errorf("Unknown contract type %s", security.String())
 	}
-	return ""
 }
 
-func SymbolFromSecurity(security *entity.Security) string {
+func SymbolFromSecurity(security *entity.Security) (string, error) {
 	switch security.Code {
 	case "QFUT":
-		return fmt.Sprintf("%s_CQ", security.GetCategory())
+		return fmt.Sprintf("%s_CQ", security.GetCategory()), nil
 	case "TFUT":
-		return fmt.Sprintf("%s_CW", security.GetCategory())
+		return fmt.Sprintf("%s_CW", security.GetCategory()), nil
 	case "NFUT":
-		return fmt.Sprintf("%s_NW", security.GetCategory())
+		return fmt.Sprintf("%s_NW", security.GetCategory()), nil
 	default:
-		symbol, err := DEFAULT_INSTRUMENT_MANAGER.GetSymbol(security.GetCategory() + security.GetCode())
-		if err != nil {
-			log.Printf("error: %+v", err)
-			panic(err)
-		}
-		if symbol != "" {
-			return symbol
-		}
-
-		panic("Unknown security")
+		return DEFAULT_INSTRUMENT_MANAGER.GetSymbol(security.GetCategory() + security.GetCode())
 	}
-	return ""
 }
 
-func ContractCodeToSecurity(contractCode string) *entity.Security {
+func ContractCodeToSecurity(contractCode string) (*entity.Security, error) {
 	symbol, err := DEFAULT_INSTRUMENT_MANAGER.GetSymbol(contractCode)
 	if err != nil {
-		log.Printf("error: %+v", err)
-		panic(err)
+		return nil, err
 	}
 	parts := strings.Split(symbol, "_")
 	var code string
@@ -69,5 +56,5 @@ func ContractCodeToSecurity(contractCode string) *entity.Security {
 		code = fmt.Sprintf("%sNFUT.HUOBI", parts[0])
 	}
 
-	return entity.ParseSecurityUnsafe(code)
+	return entity.ParseSecurityUnsafe(code), nil
 }
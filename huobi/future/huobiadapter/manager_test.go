@@ -2,31 +2,11 @@ package huobiadapter
 
 import (
 	"testing"
-	"github.com/stephenlyu/tds/util"
 	"fmt"
-	"github.com/stephenlyu/tds/date"
 	"time"
 	"github.com/stephenlyu/tds/entity"
 )
 
-func TestNextSyncTimestamp(t *testing.T) {
-	var ts uint64
-	ts = NextSyncTimestamp(util.Tick())
-	fmt.Println(date.Timestamp2SecondString(ts))
-
-	ts, _ = date.SecondString2Timestamp("20181206 14:00:00")
-	ts = NextSyncTimestamp(ts)
-	fmt.Println(date.Timestamp2SecondString(ts))
-
-	ts, _ = date.SecondString2Timestamp("20181206 16:00:00")
-	ts = NextSyncTimestamp(ts)
-	fmt.Println(date.Timestamp2SecondString(ts))
-
-	ts, _ = date.SecondString2Timestamp("20181206 16:00:01")
-	ts = NextSyncTimestamp(ts)
-	fmt.Println(date.Timestamp2SecondString(ts))
-}
-
 func TestInstrumentManager_GetInstrumentId(t *testing.T) {
 	mgr := NewManager()
 	for _, code := range []string {"EOS_CQ", "EOS_CW", "EOS_NW"} {
@@ -54,7 +34,12 @@ func TestNewInstrumentManager(t *testing.T) {
 func TestContractCodeFromSecurity(t *testing.T) {
 	for _, code := range []string {"EOSQFUT.HUOBI", "EOSTFUT.HUOBI", "EOSNFUT.HUOBI", "EOS190927.HUOBI", "EOS190816.HUOBI", "EOS190823.HUOBI"} {
 		security := entity.ParseSecurityUnsafe(code)
-		contractCode := ContractCodeFromSecurity(security)
-		fmt.Println(code, contractCode, SymbolFromSecurity(security), ContractCodeToSecurity(contractCode).String())
+		contractCode, err := ContractCodeFromSecurity(security)
+		if err != nil {
+			panic(err)
+		}
+		symbol, _ := SymbolFromSecurity(security)
+		security1, _ := ContractCodeToSecurity(contractCode)
+		fmt.Println(code, contractCode, symbol, security1)
 	}
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package huobifuture
 
 import (
+	"context"
 	"net/http"
 	"encoding/json"
 	"fmt"
@@ -11,7 +12,6 @@ import (
 	"sort"
 	"net/url"
 	"sync"
-	"log"
 	"errors"
 )
 
@@ -27,6 +27,7 @@ const (
 	TICKER = "/market/detail/merged"
 	DEPTH = "/market/depth"
 	TRADE = "/market/trade"
+	KLINE = "/market/history/kline"
 	ACCOUNTS = "/api/v1/contract_account_info"
 	POSITIONS = "/api/v1/contract_position_info"
 	PLACE_ORDER = "/api/v1/contract_order"
@@ -39,8 +40,7 @@ const (
 )
 
 type HuobiFuture struct {
-	ApiKey             string
-	SecretKey          string
+	signer             *SignerRef
 	client             *http.Client
 
 	symbols            map[string]*ContractInfo
@@ -58,59 +58,118 @@ type HuobiFuture struct {
 	privateErrorHandle func(error)
 
 	lock               sync.Mutex
+	logger             Logger
+}
+
+// https://huobiapi.github.io/docs/dm/v1/cn/#api-2, {"status":"ok","ts":1578124684692}
+func fetchServerTime(ctx context.Context, client *http.Client) (time.Time, error) {
+	var resp struct {
+		Status string
+		ErrCode int `json:"err_code"`
+		ErrMsg string `json:"err_msg"`
+		Ts int64
+	}
+	err := HttpGet4WithContext(ctx, client, API_BASE_URL + "/api/v1/timestamp", nil, &resp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if resp.Status != "ok" {
+		return time.Time{}, huobiFutureError(resp.ErrCode, resp.ErrMsg)
+	}
+	return time.Unix(0, resp.Ts*int64(time.Millisecond)), nil
+}
+
+func init() {
+	RegisterServerClock(NewServerClock(HUOBI, fetchServerTime))
 }
 
 func NewHuobiFuture(client *http.Client, ApiKey, SecretKey string) *HuobiFuture {
+	return NewHuobiFutureWithSigner(client, NewStaticSigner(ApiKey, SecretKey, ""))
+}
+
+/**
+ * 使用自定义签名器创建实例, 密钥可以来自环境变量、加密文件或凭证代理
+ */
+func NewHuobiFutureWithSigner(client *http.Client, signer Signer) *HuobiFuture {
 	this := new(HuobiFuture)
-	this.ApiKey = ApiKey
-	this.SecretKey = SecretKey
+	this.signer = NewSignerRef(signer)
 	this.client = client
+	SetServerClockHttpClient(HUOBI, client)
 
 	return this
 }
 
-func (this *HuobiFuture) signData(data string) string {
-	sign, _ := GetParamHmacSHA256Base64Sign(this.SecretKey, data)
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (this *HuobiFuture) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	this.logger = logger
+}
 
-	return sign
+func (this *HuobiFuture) log() Logger {
+	return ExchangeLogger(this.logger, HUOBI)
 }
 
-func (this *HuobiFuture) getTimestamp() string {
-	const (
-		DATE_FORMAT = "2006-01-02T15:04:05"
-	)
-	return time.Now().In(time.UTC).Format(DATE_FORMAT)
+/**
+ * 替换签名器, 用于运行时轮换密钥
+ */
+func (this *HuobiFuture) SetSigner(signer Signer) {
+	this.signer.Set(signer)
 }
 
-func (this *HuobiFuture) sign(method, reqUrl string, param map[string]string) string {
-	param["AccessKeyId"] = this.ApiKey
+/**
+ * 按Huobi的规则签名: 参数加上AccessKeyId后排序, 对"method\nhost\npath\nquery"签名
+ */
+func (this *HuobiFuture) signParams(method, path string, param map[string]string) (data string, sign string, err error) {
 	param["SignatureMethod"] = "HmacSHA256"
 	param["SignatureVersion"] = "2"
 	param["Timestamp"] = this.getTimestamp()
-	var keys []string
-	for k := range param {
-		keys = append(keys, k)
-	}
-	sort.Slice(keys, func(i,j int) bool {
-		return keys[i] < keys[j]
-	})
+	signature, err := this.signer.Sign(HMAC_SHA256_BASE64, func(apiKey string) string {
+		param["AccessKeyId"] = apiKey
+		var keys []string
+		for k := range param {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i,j int) bool {
+			return keys[i] < keys[j]
+		})
 
-	var parts []string
-	for _, k := range keys {
-		parts = append(parts, k + "=" + url.QueryEscape(param[k]))
-	}
-	data := strings.Join(parts, "&")
+		var parts []string
+		for _, k := range keys {
+			parts = append(parts, k + "=" + url.QueryEscape(param[k]))
+		}
+		data = strings.Join(parts, "&")
 
-	lines := []string {
-		method,
-		HOST,
-		reqUrl,
-		data,
+		lines := []string {
+			method,
+			HOST,
+			path,
+			data,
+		}
+		return strings.Join(lines, "\n")
+	})
+	if err != nil {
+		return "", "", err
 	}
+	return data, signature.Sign, nil
+}
+
+func (this *HuobiFuture) getTimestamp() string {
+	const (
+		DATE_FORMAT = "2006-01-02T15:04:05"
+	)
+	return ServerTime(HUOBI).In(time.UTC).Format(DATE_FORMAT)
+}
 
-	message := strings.Join(lines, "\n")
-	sign := this.signData(message)
-	return data + "&Signature=" + url.QueryEscape(sign)
+func (this *HuobiFuture) sign(method, reqUrl string, param map[string]string) (string, error) {
+	data, sign, err := this.signParams(method, reqUrl, param)
+	if err != nil {
+		return "", err
+	}
+	return data + "&Signature=" + url.QueryEscape(sign), nil
 }
 
 func (this *HuobiFuture) buildQueryString(params map[string]string) string {
@@ -125,7 +184,8 @@ func (this *HuobiFuture) GetContractInfo() ([]ContractInfo, error) {
 	url := API_BASE_URL + CONTRACT_INFO
 	var resp struct {
 		Status string
-		Msg string
+		ErrCode int `json:"err_code"`
+		ErrMsg string `json:"err_msg"`
 		Data []ContractInfo
 	}
 
@@ -136,13 +196,25 @@ func (this *HuobiFuture) GetContractInfo() ([]ContractInfo, error) {
 	}
 
 	if resp.Status != "ok" {
-		log.Printf("HuobiFuture.GetContractInfo error status: %s\n", resp.Status)
-		return nil, fmt.Errorf("error_code: %s", resp.Status)
+		this.log().Warn("get contract info failed", "status", resp.Status)
+		return nil, huobiFutureError(resp.ErrCode, resp.ErrMsg)
 	}
 
 	return resp.Data, nil
 }
 
+func (this *HuobiFuture) GetInstruments() ([]Instrument, error) {
+	contracts, err := this.GetContractInfo()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(contracts))
+	for i := range contracts {
+		ret[i] = contracts[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (this *HuobiFuture) GetTicker(symbol string) (*TickerDecimal, error) {
 	params := map[string]string {
 		"symbol": symbol,
@@ -150,7 +222,8 @@ func (this *HuobiFuture) GetTicker(symbol string) (*TickerDecimal, error) {
 	url := API_BASE_URL + TICKER + "?" + this.buildQueryString(params)
 	var resp struct {
 		Status string
-		Msg string
+		ErrCode int `json:"err_code"`
+		ErrMsg string `json:"err_msg"`
 		Tick struct {
 			Vol decimal.Decimal
 			Ask []decimal.Decimal
@@ -170,8 +243,8 @@ func (this *HuobiFuture) GetTicker(symbol string) (*TickerDecimal, error) {
 	}
 
 	if resp.Status != "ok" {
-		log.Printf("HuobiFuture.GetTicker error code: %s\n", resp.Status)
-		return nil, fmt.Errorf("error_code: %s", resp.Status)
+		this.log().Warn("get ticker failed", "status", resp.Status)
+		return nil, huobiFutureError(resp.ErrCode, resp.ErrMsg)
 	}
 
 	r := &resp.Tick
@@ -197,6 +270,8 @@ func (this *HuobiFuture) GetDepth(symbol string) (*DepthDecimal, error) {
 	url := API_BASE_URL + DEPTH + "?" + this.buildQueryString(params)
 	var resp struct {
 		Status string
+		ErrCode int `json:"err_code"`
+		ErrMsg string `json:"err_msg"`
 		Tick struct {
 			Ts int64
 			Asks [][]decimal.Decimal
@@ -210,8 +285,8 @@ func (this *HuobiFuture) GetDepth(symbol string) (*DepthDecimal, error) {
 	}
 
 	if resp.Status != "ok" {
-		log.Printf("HuobiFuture.GetDepth error code: %s\n", resp.Status)
-		return nil, fmt.Errorf("error_code: %s", resp.Status)
+		this.log().Warn("get depth failed", "status", resp.Status)
+		return nil, huobiFutureError(resp.ErrCode, resp.ErrMsg)
 	}
 
 	r := resp.Tick
@@ -238,6 +313,8 @@ func (this *HuobiFuture) GetTrades(symbol string) ([]TradeDecimal, error) {
 	url := API_BASE_URL + TRADE + "?" + this.buildQueryString(params)
 	var resp struct {
 		Status string
+		ErrCode int `json:"err_code"`
+		ErrMsg string `json:"err_msg"`
 		Tick struct {
 				 Data []struct {
 					 Amount decimal.Decimal
@@ -255,8 +332,8 @@ func (this *HuobiFuture) GetTrades(symbol string) ([]TradeDecimal, error) {
 	}
 
 	if resp.Status != "ok" {
-		log.Printf("HuobiFuture.GetTrade error code: %s\n", resp.Status)
-		return nil, fmt.Errorf("error_code: %s", resp.Status)
+		this.log().Warn("get trades failed", "status", resp.Status)
+		return nil, huobiFutureError(resp.ErrCode, resp.ErrMsg)
 	}
 
 	var trades = make([]TradeDecimal, len(resp.Tick.Data))
@@ -273,9 +350,82 @@ func (this *HuobiFuture) GetTrades(symbol string) ([]TradeDecimal, error) {
 	return trades, nil
 }
 
+var klinePeriods = map[int]string {
+	KLINE_PERIOD_1MIN: "1min",
+	KLINE_PERIOD_5MIN: "5min",
+	KLINE_PERIOD_15MIN: "15min",
+	KLINE_PERIOD_30MIN: "30min",
+	KLINE_PERIOD_60MIN: "60min",
+	KLINE_PERIOD_4H: "4hour",
+	KLINE_PERIOD_1DAY: "1day",
+	KLINE_PERIOD_1WEEK: "1week",
+	KLINE_PERIOD_1MONTH: "1mon",
+}
+
+/**
+ * symbol为合约代码如BTC_CQ, since为毫秒时间戳, 小于等于0时获取最近的size根K线
+ */
+func (this *HuobiFuture) GetKlineRecordsDecimal(symbol string, period, size, since int) ([]KlineDecimal, error) {
+	code, err := KlinePeriodCode(klinePeriods, period)
+	if err != nil {
+		return nil, err
+	}
+	periodSec := int64(KlinePeriodDuration(period) / time.Second)
+	pair := NewCurrencyPair(NewCurrency(strings.Split(symbol, "_")[0], ""), USD)
+
+	return GetAllKlineDecimals(int64(since), size, 2000, func(q PageQuery) ([]KlineDecimal, error) {
+		params := map[string]string {
+			"symbol": symbol,
+			"period": code,
+		}
+		// 指定from/to时不能指定size
+		if q.From > 0 {
+			params["from"] = fmt.Sprint(q.From / 1000)
+			params["to"] = fmt.Sprint(q.From / 1000 + int64(q.Limit - 1) * periodSec)
+		} else if q.To > 0 {
+			params["from"] = fmt.Sprint(q.To / 1000 - int64(q.Limit - 1) * periodSec)
+			params["to"] = fmt.Sprint(q.To / 1000)
+		} else {
+			params["size"] = fmt.Sprint(q.Limit)
+		}
+		url := API_BASE_URL + KLINE + "?" + this.buildQueryString(params)
+		var resp struct {
+			Status string
+			ErrCode int `json:"err_code"`
+			ErrMsg string `json:"err_msg"`
+			Data []struct {
+				Id int64
+				Open decimal.Decimal
+				Close decimal.Decimal
+				High decimal.Decimal
+				Low decimal.Decimal
+				Vol decimal.Decimal
+			}
+		}
+
+		err := HttpGet4(this.client, url, nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.Status != "ok" {
+			return nil, huobiFutureError(resp.ErrCode, resp.ErrMsg)
+		}
+
+		klines := make([]KlineDecimal, len(resp.Data))
+		for i, o := range resp.Data {
+			klines[i] = KlineDecimal{Pair: pair, Timestamp: o.Id, Open: o.Open, Close: o.Close, High: o.High, Low: o.Low, Vol: o.Vol}
+		}
+		return klines, nil
+	})
+}
+
 func (this *HuobiFuture) GetAccounts() (*FutureAccountDecimal, error) {
 	params := map[string]string {}
-	queryString := this.sign("POST", ACCOUNTS, params)
+	queryString, err := this.sign("POST", ACCOUNTS, params)
+	if err != nil {
+		return nil, err
+	}
 
 	reqUrl := API_BASE_URL + ACCOUNTS + "?" + queryString
 	postData := map[string]interface{} {}
@@ -291,6 +441,7 @@ func (this *HuobiFuture) GetAccounts() (*FutureAccountDecimal, error) {
 	var data struct {
 		Status string
 		ErrCode int 			`json:"err_code"`
+		ErrMsg string 		`json:"err_msg"`
 		Data []struct {
 			Symbol string
 			MarginBalance decimal.Decimal		`json:"margin_balance"`
@@ -308,8 +459,8 @@ func (this *HuobiFuture) GetAccounts() (*FutureAccountDecimal, error) {
 	}
 
 	if data.Status != "ok" {
-		log.Printf("HuobiFuture.GetAccounts error code: %d\n", data.ErrCode)
-		return nil, fmt.Errorf("error_code: %d", data.ErrCode)
+		this.log().Warn("get accounts failed", "err_code", data.ErrCode)
+		return nil, huobiFutureError(data.ErrCode, data.ErrMsg)
 	}
 
 	var ret *FutureAccountDecimal
@@ -331,7 +482,10 @@ func (this *HuobiFuture) GetAccounts() (*FutureAccountDecimal, error) {
 
 func (this *HuobiFuture) GetPosition(symbol string) ([]PositionInfo, error) {
 	params := map[string]string {}
-	queryString := this.sign("POST", POSITIONS, params)
+	queryString, err := this.sign("POST", POSITIONS, params)
+	if err != nil {
+		return nil, err
+	}
 
 	reqUrl := API_BASE_URL + POSITIONS + "?" + queryString
 	postData := map[string]interface{} {
@@ -349,6 +503,7 @@ func (this *HuobiFuture) GetPosition(symbol string) ([]PositionInfo, error) {
 	var data struct {
 		Status string
 		ErrCode int 			`json:"err_code"`
+		ErrMsg string 		`json:"err_msg"`
 		Data []PositionInfo
 	}
 
@@ -358,8 +513,8 @@ func (this *HuobiFuture) GetPosition(symbol string) ([]PositionInfo, error) {
 	}
 
 	if data.Status != "ok" {
-		log.Printf("HuobiFuture.GetPosition error code: %d\n", data.ErrCode)
-		return nil, fmt.Errorf("error_code: %d", data.ErrCode)
+		this.log().Warn("get position failed", "err_code", data.ErrCode)
+		return nil, huobiFutureError(data.ErrCode, data.ErrMsg)
 	}
 
 	return data.Data, nil
@@ -367,17 +522,21 @@ func (this *HuobiFuture) GetPosition(symbol string) ([]PositionInfo, error) {
 
 func (this *HuobiFuture) PlaceOrder(req OrderReq) (string, error) {
 	params := map[string]string {}
-	queryString := this.sign("POST", PLACE_ORDER, params)
+	queryString, err := this.sign("POST", PLACE_ORDER, params)
+	if err != nil {
+		return "", err
+	}
 
 	reqUrl := API_BASE_URL + PLACE_ORDER + "?" + queryString
 	bytes, err := HttpPostForm4(this.client, reqUrl, req, nil)
 	if err != nil {
 		return "", err
 	}
-	println(string(bytes))
+	this.log().Debug("place order response", "body", string(bytes))
 	var data struct {
 		Status string
 		ErrCode int 			`json:"err_code"`
+		ErrMsg string 		`json:"err_msg"`
 		Data struct {
 				 OrderId decimal.Decimal 		`json:"order_id"`
 			 }
@@ -389,8 +548,8 @@ func (this *HuobiFuture) PlaceOrder(req OrderReq) (string, error) {
 	}
 
 	if data.Status != "ok" {
-		log.Printf("HuobiFuture.PlaceOrder error code: %d\n", data.ErrCode)
-		return "", fmt.Errorf("error_code: %d", data.ErrCode)
+		this.log().Warn("place order failed", "err_code", data.ErrCode)
+		return "", huobiFutureError(data.ErrCode, data.ErrMsg)
 	}
 
 	return data.Data.OrderId.String(), nil
@@ -398,7 +557,10 @@ func (this *HuobiFuture) PlaceOrder(req OrderReq) (string, error) {
 
 func (this *HuobiFuture) PlaceOrders(reqList []OrderReq) ([]string, []error, error) {
 	params := map[string]string {}
-	queryString := this.sign("POST", BATCH_PLACE_ORDERS, params)
+	queryString, err := this.sign("POST", BATCH_PLACE_ORDERS, params)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	reqUrl := API_BASE_URL + BATCH_PLACE_ORDERS + "?" + queryString
 	postData := map[string]interface{} {
@@ -411,6 +573,7 @@ func (this *HuobiFuture) PlaceOrders(reqList []OrderReq) ([]string, []error, err
 	var data struct {
 		Status string
 		ErrCode int 			`json:"err_code"`
+		ErrMsg string 		`json:"err_msg"`
 		Data struct {
 				 Errors []struct {
 					 Index int
@@ -430,15 +593,15 @@ func (this *HuobiFuture) PlaceOrders(reqList []OrderReq) ([]string, []error, err
 	}
 
 	if data.Status != "ok" {
-		log.Printf("HuobiFuture.PlaceOrders error code: %d\n", data.ErrCode)
-		return nil, nil, fmt.Errorf("error_code: %d", data.ErrCode)
+		this.log().Warn("place orders failed", "err_code", data.ErrCode)
+		return nil, nil, huobiFutureError(data.ErrCode, data.ErrMsg)
 	}
 
 	var orderIds = make([]string, len(reqList))
 	var errorList = make([]error, len(reqList))
 	for _, r := range data.Data.Errors {
-		log.Printf("HuobiFuture.PlaceOrders error code: %d\n", r.ErrCode)
-		errorList[r.Index-1] = fmt.Errorf("error_code: %d", r.ErrCode)
+		this.log().Warn("place orders failed", "err_code", r.ErrCode)
+		errorList[r.Index-1] = huobiFutureError(r.ErrCode, r.ErrMsg)
 	}
 
 	for _, r := range data.Data.Success {
@@ -452,7 +615,10 @@ func (this *HuobiFuture) BatchCancelOrders(symbol string, orderIds []string) (er
 	var errorList =  make([]error, len(orderIds))
 
 	params := map[string]string {}
-	queryString := this.sign("POST", BATCH_CANCEL, params)
+	queryString, err := this.sign("POST", BATCH_CANCEL, params)
+	if err != nil {
+		return err, nil
+	}
 
 	reqUrl := API_BASE_URL + BATCH_CANCEL + "?" + queryString
 	postData := map[string]interface{} {
@@ -467,10 +633,12 @@ func (this *HuobiFuture) BatchCancelOrders(symbol string, orderIds []string) (er
 	var data struct {
 		Status string
 		ErrCode int 			`json:"err_code"`
+		ErrMsg string 		`json:"err_msg"`
 		Data struct {
 				 Errors []struct {
 					 OrderId   string
 					 ErrCode int			`json:"err_code"`
+					 ErrMsg string 		`json:"err_msg"`
 				 }
 				 Successes string
 			 }
@@ -482,8 +650,8 @@ func (this *HuobiFuture) BatchCancelOrders(symbol string, orderIds []string) (er
 	}
 
 	if data.Status != "ok" {
-		log.Printf("HuobiFuture.BatchCancelOrders error code: %d\n", data.ErrCode)
-		return fmt.Errorf("error_code: %d", data.ErrCode), errorList
+		this.log().Warn("cancel orders failed", "err_code", data.ErrCode)
+		return huobiFutureError(data.ErrCode, data.ErrMsg), errorList
 	}
 
 	orderIdMap := make(map[string]int)
@@ -496,8 +664,8 @@ func (this *HuobiFuture) BatchCancelOrders(symbol string, orderIds []string) (er
 		if r.ErrCode == 1071 || r.ErrCode == 1061 || r.ErrCode == 1062 || r.ErrCode == 1063 {
 			continue
 		}
-		log.Printf("HuobiFuture.BatchCancelOrders error code: %d\n", r.ErrCode)
-		errorList[orderIdMap[r.OrderId]] = fmt.Errorf("error_code: %d", r.ErrCode)
+		this.log().Warn("cancel orders failed", "err_code", r.ErrCode)
+		errorList[orderIdMap[r.OrderId]] = huobiFutureError(r.ErrCode, r.ErrMsg)
 	}
 
 	return nil, errorList
@@ -509,7 +677,10 @@ func (this *HuobiFuture) QueryPendingOrders(symbol string, page, pageSize int) (
 	}
 
 	params := map[string]string {}
-	queryString := this.sign("POST", OPEN_ORDERS, params)
+	queryString, err := this.sign("POST", OPEN_ORDERS, params)
+	if err != nil {
+		return nil, err
+	}
 
 	reqUrl := API_BASE_URL + OPEN_ORDERS+ "?" + queryString
 	postData := map[string]interface{} {
@@ -529,6 +700,7 @@ func (this *HuobiFuture) QueryPendingOrders(symbol string, page, pageSize int) (
 	var data struct {
 		Status string
 		ErrCode int 			`json:"err_code"`
+		ErrMsg string 		`json:"err_msg"`
 		Data struct {
 				 Orders []OrderInfo
 			 }
@@ -540,8 +712,8 @@ func (this *HuobiFuture) QueryPendingOrders(symbol string, page, pageSize int) (
 	}
 
 	if data.Status != "ok" {
-		log.Printf("HuobiFuture.QueryPendingOrders error code: %d", data.ErrCode)
-		return nil, fmt.Errorf("error_code: %d", data.ErrCode)
+		this.log().Warn("query pending orders failed", "err_code", data.ErrCode)
+		return nil, huobiFutureError(data.ErrCode, data.ErrMsg)
 	}
 
 	var ret = make([]FutureOrderDecimal, len(data.Data.Orders))
@@ -561,7 +733,10 @@ func (this *HuobiFuture) QueryHisOrders(symbol string, page, pageSize int) ([]Fu
 	}
 
 	params := map[string]string {}
-	queryString := this.sign("POST", HIS_ORDERS, params)
+	queryString, err := this.sign("POST", HIS_ORDERS, params)
+	if err != nil {
+		return nil, err
+	}
 
 	reqUrl := API_BASE_URL + HIS_ORDERS + "?" + queryString
 	postData := map[string]interface{} {
@@ -581,6 +756,7 @@ func (this *HuobiFuture) QueryHisOrders(symbol string, page, pageSize int) ([]Fu
 	var data struct {
 		Status string
 		ErrCode int 			`json:"err_code"`
+		ErrMsg string 		`json:"err_msg"`
 		Data struct {
 				   Orders []OrderInfo
 			   }
@@ -592,8 +768,8 @@ func (this *HuobiFuture) QueryHisOrders(symbol string, page, pageSize int) ([]Fu
 	}
 
 	if data.Status != "ok" {
-		log.Printf("HuobiFuture.QueryHisOrders error code: %d\n", data.ErrCode)
-		return nil, fmt.Errorf("error_code: %d", data.ErrCode)
+		this.log().Warn("query history orders failed", "err_code", data.ErrCode)
+		return nil, huobiFutureError(data.ErrCode, data.ErrMsg)
 	}
 
 	var ret = make([]FutureOrderDecimal, len(data.Data.Orders))
@@ -606,7 +782,10 @@ func (this *HuobiFuture) QueryHisOrders(symbol string, page, pageSize int) ([]Fu
 
 func (this *HuobiFuture) QueryOrder(symbol string, orderId, clientOid string) (*FutureOrderDecimal, error) {
 	params := map[string]string {}
-	queryString := this.sign("POST", QUERY_ORDER, params)
+	queryString, err := this.sign("POST", QUERY_ORDER, params)
+	if err != nil {
+		return nil, err
+	}
 
 	reqUrl := API_BASE_URL + QUERY_ORDER + "?" + queryString
 	postData := map[string]interface{} {
@@ -627,6 +806,7 @@ func (this *HuobiFuture) QueryOrder(symbol string, orderId, clientOid string) (*
 	var data struct {
 		Status string
 		ErrCode int 			`json:"err_code"`
+		ErrMsg string 		`json:"err_msg"`
 		Data []OrderInfo
 	}
 
@@ -636,11 +816,11 @@ func (this *HuobiFuture) QueryOrder(symbol string, orderId, clientOid string) (*
 	}
 
 	if data.Status != "ok" {
-		log.Printf("HuobiFuture.QueryOrder error code: %d\n", data.ErrCode)
+		this.log().Warn("query order failed", "err_code", data.ErrCode)
 		if data.ErrCode == 1017 {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("error_code: %d", data.ErrCode)
+		return nil, huobiFutureError(data.ErrCode, data.ErrMsg)
 	}
 
 	if len(data.Data) == 0 {
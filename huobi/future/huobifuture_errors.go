@@ -0,0 +1,70 @@
+package huobifuture
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	. "github.com/stephenlyu/GoEx"
+)
+
+// https://huobiapi.github.io/docs/dm/v1/cn/#8664ee712b, 接口返回status为error时的err_code. 签名等网关错误的err-code为字符串
+var huobiFutureErrorCodes = ErrorCodes{
+	"1004": ERR_MAINTENANCE,
+	"1010": ERR_AUTH,
+	"1012": ERR_INVALID_SYMBOL,
+	"1013": ERR_INVALID_SYMBOL,
+	"1014": ERR_INVALID_SYMBOL,
+	"1017": ERR_ORDER_NOT_FOUND,
+	"1030": ERR_INVALID_PARAM,
+	"1032": ERR_RATE_LIMITED,
+	"1040": ERR_INVALID_PARAM,
+	"1041": ERR_INVALID_PARAM,
+	"1047": ERR_INSUFFICIENT_BALANCE,
+	"1048": ERR_INSUFFICIENT_BALANCE,
+	"1051": ERR_ORDER_NOT_FOUND,
+	"1061": ERR_ORDER_NOT_FOUND,
+	"1066": ERR_INVALID_PARAM,
+	"1067": ERR_INVALID_PARAM,
+	"1080": ERR_MAINTENANCE,
+	"1089": ERR_MAINTENANCE,
+	"1090": ERR_MAINTENANCE,
+	"1091": ERR_MAINTENANCE,
+
+	"api-signature-not-valid":    ERR_AUTH,
+	"api-signature-check-failed": ERR_AUTH,
+	"invalid-access-key":         ERR_AUTH,
+	"api-key-expired":            ERR_AUTH,
+	"invalid-timestamp":          ERR_NONCE,
+	"too-many-request":           ERR_RATE_LIMITED,
+}
+
+func huobiFutureError(code int, msg string) error {
+	return huobiFutureErrorCodes.NewError(HUOBI, strconv.Itoa(code), msg)
+}
+
+func parseHuobiFutureError(statusCode int, body []byte) *ExchangeError {
+	var resp map[string]interface{}
+	if json.Unmarshal(body, &resp) != nil {
+		return nil
+	}
+	code, ok := resp["err_code"]
+	if !ok {
+		code, ok = resp["err-code"]
+	}
+	if !ok {
+		return nil
+	}
+	if f, isFloat := code.(float64); isFloat {
+		code = int64(f)
+	}
+	msg, _ := resp["err_msg"].(string)
+	if msg == "" {
+		msg, _ = resp["err-msg"].(string)
+	}
+	return huobiFutureErrorCodes.NewError(HUOBI, fmt.Sprint(code), msg)
+}
+
+func init() {
+	RegisterErrorParser(HUOBI, parseHuobiFutureError, HOST)
+}
@@ -1,6 +1,7 @@
 package huobifuture
 
 import (
+	"errors"
 	"testing"
 	"encoding/json"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"io/ioutil"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	goex "github.com/stephenlyu/GoEx"
 	"time"
 	"net/http"
 )
@@ -155,3 +157,12 @@ func TestHuobiFuture_GetOrderByClientId(t *testing.T) {
 	assert.Nil(t, err)
 	output(order)
 }
+
+func TestParseHuobiFutureError(t *testing.T) {
+	err := parseHuobiFutureError(200, []byte(`{"status":"error","err_code":1047,"err_msg":"Insufficient margin available.","ts":1490759594752}`))
+	assert.True(t, errors.Is(err, goex.ERR_INSUFFICIENT_BALANCE))
+	assert.Equal(t, "1047", err.Code)
+
+	err = parseHuobiFutureError(403, []byte(`{"status":"error","err-code":"api-signature-not-valid","err-msg":"Signature not valid"}`))
+	assert.True(t, errors.Is(err, goex.ERR_AUTH))
+}
@@ -3,12 +3,9 @@ package huobifuture
 import (
 	"encoding/json"
 	. "github.com/stephenlyu/GoEx"
-	"log"
 	"fmt"
 	"strings"
 	"github.com/pborman/uuid"
-	"sort"
-	"net/url"
 	"github.com/shopspring/decimal"
 )
 
@@ -21,11 +18,12 @@ func (this *HuobiFuture) createPrivateWsConn() {
 		if this.privateWs == nil {
 			this.privateWs = NewWsConn("wss://api.hbdm.com/notification")
 			this.privateWs.SetErrorHandler(this.privateErrorHandle)
+			this.privateWs.SetLogger(this.logger)
 			this.privateWs.ReConnect()
 			this.privateWs.ReceiveMessageEx(func(isBin bool, msg []byte) {
 				msg, err := GzipDecode(msg)
 				if err != nil {
-					fmt.Println(err)
+					this.log().Warn("decode websocket message failed", "err", err)
 					return
 				}
 				//println(string(msg))
@@ -34,11 +32,12 @@ func (this *HuobiFuture) createPrivateWsConn() {
 					Op string
 					Ts decimal.Decimal
 					ErrCode int 			`json:"err_code"`
+					ErrMsg string 			`json:"err_msg"`
 					Topic string
 				}
 				err = json.Unmarshal(msg, &data)
 				if err != nil {
-					log.Print(err)
+					this.log().Warn("decode websocket message failed", "err", err)
 					return
 				}
 
@@ -49,7 +48,7 @@ func (this *HuobiFuture) createPrivateWsConn() {
 				case "auth":
 					var err error
 					if data.ErrCode != 0 {
-						err = fmt.Errorf("error_code: %d", data.ErrCode)
+						err = huobiFutureError(data.ErrCode, data.ErrMsg)
 					}
 					this.wsLoginHandle(err)
 				case "notify":
@@ -66,44 +65,25 @@ func (this *HuobiFuture) createPrivateWsConn() {
 	}
 }
 
-func (this *HuobiFuture) loginSign() (param map[string]string) {
-	param = make(map[string]string)
-	param["AccessKeyId"] = this.ApiKey
-	param["SignatureMethod"] = "HmacSHA256"
-	param["SignatureVersion"] = "2"
-	param["Timestamp"] = this.getTimestamp()
-	var keys []string
-	for k := range param {
-		keys = append(keys, k)
-	}
-	sort.Slice(keys, func(i,j int) bool {
-		return keys[i] < keys[j]
-	})
-
-	var parts []string
-	for _, k := range keys {
-		parts = append(parts, k + "=" + url.QueryEscape(param[k]))
-	}
-	data := strings.Join(parts, "&")
-
-	lines := []string {
-		"GET",
-		HOST,
-		"/notification",
-		data,
+func (this *HuobiFuture) loginSign() (map[string]string, error) {
+	param := make(map[string]string)
+	_, sign, err := this.signParams("GET", "/notification", param)
+	if err != nil {
+		return nil, err
 	}
-
-	message := strings.Join(lines, "\n")
-	param["Signature"] = this.signData(message)
-	return
+	param["Signature"] = sign
+	return param, nil
 }
 
-func (this *HuobiFuture) getLoginData() interface{} {
-	param := this.loginSign()
+func (this *HuobiFuture) getLoginData() (interface{}, error) {
+	param, err := this.loginSign()
+	if err != nil {
+		return nil, err
+	}
 	param["op"] = "auth"
 	param["type"] = "api"
 	param["cid"] = uuid.New()
-	return param
+	return param, nil
 }
 
 func (this *HuobiFuture) doLogin() error {
@@ -115,8 +95,11 @@ func (this *HuobiFuture) doLogin() error {
 
 	this.wsLoginHandle = onDone
 
-	data := this.getLoginData()
-	err := this.privateWs.SendMessage(data)
+	data, err := this.getLoginData()
+	if err != nil {
+		return err
+	}
+	err = this.privateWs.SendMessage(data)
 	if err != nil {
 		return err
 	}
@@ -145,7 +128,7 @@ func (this *HuobiFuture) GetOrderWithWs(symbol string,
 		"cid": uuid.New(),
 		"topic": topic,
 	}
-	fmt.Printf("%+v\n", event)
+	this.log().Debug("subscribe orders", "topic", topic)
 	return this.privateWs.Subscribe(event)
 }
 
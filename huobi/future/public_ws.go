@@ -3,7 +3,6 @@ package huobifuture
 import (
 	"encoding/json"
 	. "github.com/stephenlyu/GoEx"
-	"log"
 	"github.com/shopspring/decimal"
 	"fmt"
 	"strings"
@@ -41,11 +40,12 @@ func (this *HuobiFuture) createPublicWsConn() {
 
 			this.publicWs = NewWsConn("wss://dm.btcgateway.pro/ws")
 			this.publicWs.SetErrorHandler(this.errorHandle)
+			this.publicWs.SetLogger(this.logger)
 			this.publicWs.ReConnect()
 			this.publicWs.ReceiveMessageEx(func(isBin bool, msg []byte) {
 				msg, err := GzipDecode(msg)
 				if err != nil {
-					fmt.Println(err)
+					this.log().Warn("decode websocket message failed", "err", err)
 					return
 				}
 				//println(string(msg))
@@ -56,7 +56,7 @@ func (this *HuobiFuture) createPublicWsConn() {
 				}
 				err = json.Unmarshal(msg, &data)
 				if err != nil {
-					log.Print(err)
+					this.log().Warn("decode websocket message failed", "err", err)
 					return
 				}
 
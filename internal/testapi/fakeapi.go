@@ -0,0 +1,118 @@
+// Package testapi In-memory goex.API used by the order routing and execution tests
+package testapi
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+
+	goex "github.com/stephenlyu/GoEx"
+)
+
+var ErrOrderNotFound = errors.New("order not found")
+
+// FakeAPI Records limit orders and cancels, order ids are 1, 2, ... in placement order.
+// Methods other than GetAccount, LimitBuy, LimitSell, CancelOrder and GetOneOrder panic
+type FakeAPI struct {
+	goex.API
+
+	Balance   map[goex.Currency]float64
+	Fill      bool  // Orders fill completely at their price on the next query
+	PlaceErr  error // LimitBuy and LimitSell fail with it
+	CancelErr error // CancelOrder fails with it
+	Stuck     bool  // Cancels are acknowledged but orders stay working
+
+	lock      sync.Mutex
+	orders    map[string]*goex.Order
+	placed    []string
+	cancelled []string
+	queries   int
+}
+
+func (api *FakeAPI) GetAccount() (*goex.Account, error) {
+	acc := &goex.Account{SubAccounts: make(map[goex.Currency]goex.SubAccount)}
+	for c, amount := range api.Balance {
+		acc.SubAccounts[c] = goex.SubAccount{Currency: c, Amount: amount}
+	}
+	return acc, nil
+}
+
+func (api *FakeAPI) place(side goex.TradeSide, amount, price string, currency goex.CurrencyPair) (*goex.Order, error) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	if api.PlaceErr != nil {
+		return nil, api.PlaceErr
+	}
+	if api.orders == nil {
+		api.orders = make(map[string]*goex.Order)
+	}
+	api.placed = append(api.placed, amount+"@"+price)
+	id := strconv.Itoa(len(api.placed))
+	api.orders[id] = &goex.Order{OrderID2: id, Amount: goex.ToFloat64(amount), Price: goex.ToFloat64(price), Side: side, Currency: currency, Status: goex.ORDER_UNFINISH}
+	order := *api.orders[id]
+	return &order, nil
+}
+
+func (api *FakeAPI) LimitBuy(amount, price string, currency goex.CurrencyPair) (*goex.Order, error) {
+	return api.place(goex.BUY, amount, price, currency)
+}
+
+func (api *FakeAPI) LimitSell(amount, price string, currency goex.CurrencyPair) (*goex.Order, error) {
+	return api.place(goex.SELL, amount, price, currency)
+}
+
+func (api *FakeAPI) CancelOrder(orderId string, currency goex.CurrencyPair) (bool, error) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	api.cancelled = append(api.cancelled, orderId)
+	if api.CancelErr != nil {
+		return false, api.CancelErr
+	}
+	if o := api.orders[orderId]; o != nil && o.Status == goex.ORDER_UNFINISH && !api.Stuck {
+		o.Status = goex.ORDER_CANCEL
+	}
+	return true, nil
+}
+
+func (api *FakeAPI) GetOneOrder(orderId string, currency goex.CurrencyPair) (*goex.Order, error) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	api.queries++
+	o := api.orders[orderId]
+	if o == nil {
+		return nil, ErrOrderNotFound
+	}
+	if api.Fill && o.Status == goex.ORDER_UNFINISH {
+		o.DealAmount, o.AvgPrice, o.Status = o.Amount, o.Price, goex.ORDER_FINISH
+	}
+	order := *o
+	return &order, nil
+}
+
+// SetFill Change Fill while orders are being polled
+func (api *FakeAPI) SetFill(fill bool) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	api.Fill = fill
+}
+
+// Placed Placed orders as amount@price
+func (api *FakeAPI) Placed() []string {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	return append([]string(nil), api.placed...)
+}
+
+// Cancelled Order ids passed to CancelOrder, failed cancels included
+func (api *FakeAPI) Cancelled() []string {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	return append([]string(nil), api.cancelled...)
+}
+
+// Queries Number of GetOneOrder calls
+func (api *FakeAPI) Queries() int {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	return api.queries
+}
@@ -30,6 +30,42 @@ type Kraken struct {
 	httpClient *http.Client
 	accessKey,
 	secretKey string
+	symbols *SymbolMapper
+}
+
+// 交易对信息, 键为交易所的交易对代码, 如XXBTZUSD
+type AssetPair struct {
+	Altname      string `json:"altname"`
+	Wsname       string `json:"wsname"` // 如XBT/USD, 暗池交易对没有
+	Base         string `json:"base"`
+	Quote        string `json:"quote"`
+	PairDecimals int32  `json:"pair_decimals"`
+	LotDecimals  int32  `json:"lot_decimals"`
+	OrderMin     string `json:"ordermin"`
+}
+
+func (this *AssetPair) ToInstrument(symbol string) (inst Instrument, ok bool) {
+	currencies := strings.Split(this.Wsname, "/")
+	if len(currencies) != 2 {
+		return
+	}
+	inst = Instrument{
+		Exchange:        KRAKEN,
+		Symbol:          symbol,
+		Pair:            NewCurrencyPair(NewCurrency(currencies[0], ""), NewCurrency(currencies[1], "")),
+		Type:            INSTRUMENT_SPOT,
+		PricePrecision:  this.PairDecimals,
+		AmountPrecision: this.LotDecimals,
+		MinQty:          ToDecimal(this.OrderMin),
+	}
+	inst.FillPrecision()
+	return inst, true
+}
+
+// 资产信息, 键为余额等接口使用的资产代码, 如XXBT, altname为通用名称, 如XBT
+type Asset struct {
+	Altname  string `json:"altname"`
+	Decimals int32  `json:"decimals"`
 }
 
 var (
@@ -41,14 +77,57 @@ var (
 )
 
 func New(client *http.Client, accesskey, secretkey string) *Kraken {
-	return &Kraken{client, accesskey, secretkey}
+	k := &Kraken{httpClient: client, accessKey: accesskey, secretKey: secretkey}
+	k.symbols = NewSymbolMapper(KRAKEN, NewInstrumentCatalog(KRAKEN, k))
+	return k
+}
+
+/**
+ * 交易对信息, 同时从资产信息加载资产代码的别名, 如XXBT -> BTC
+ */
+func (k *Kraken) GetInstruments() ([]Instrument, error) {
+	if err := k.loadAssetAliases(); err != nil {
+		return nil, err
+	}
+
+	var resultmap map[string]AssetPair
+	err := k.doAuthenticatedRequest("GET", "public/AssetPairs", url.Values{}, &resultmap)
+	if err != nil {
+		return nil, err
+	}
+	var ret []Instrument
+	for symbol, pair := range resultmap {
+		if inst, ok := pair.ToInstrument(symbol); ok {
+			ret = append(ret, inst)
+		}
+	}
+	return ret, nil
+}
+
+func (k *Kraken) loadAssetAliases() error {
+	var assets map[string]Asset
+	err := k.doAuthenticatedRequest("GET", "public/Assets", url.Values{}, &assets)
+	if err != nil {
+		return err
+	}
+	for name, asset := range assets {
+		canonical := CanonicalCurrency(NewCurrency(asset.Altname, ""))
+		if !strings.EqualFold(name, canonical.Symbol) {
+			k.symbols.AddAssetAlias(name, canonical.Symbol)
+		}
+	}
+	return nil
+}
+
+func (k *Kraken) SymbolMapper() *SymbolMapper {
+	return k.symbols
 }
 
 func (k *Kraken) placeOrder(orderType, side, amount, price string, pair CurrencyPair) (*Order, error) {
 	apiuri := "private/AddOrder"
 
 	params := url.Values{}
-	params.Set("pair", k.convertPair(pair).ToSymbol(""))
+	params.Set("pair", k.pairSymbol(pair))
 	params.Set("type", side)
 	params.Set("ordertype", orderType)
 	params.Set("price", price)
@@ -192,6 +271,11 @@ func (k *Kraken) GetAccount() (*Account, error) {
 		return nil, err
 	}
 
+	// 资产代码的别名随交易对信息加载
+	if _, err := k.symbols.Catalog.All(); err != nil {
+		return nil, err
+	}
+
 	acc := new(Account)
 	acc.Exchange = k.GetExchangeName()
 	acc.SubAccounts = make(map[Currency]SubAccount)
@@ -202,8 +286,8 @@ func (k *Kraken) GetAccount() (*Account, error) {
 		//log.Println(symbol, amount)
 		acc.SubAccounts[currency] = SubAccount{Currency: currency, Amount: amount, ForzenAmount: 0, LoanAmount: 0}
 
-		if currency.Symbol == "XBT" { // adapt to btc
-			acc.SubAccounts[BTC] = SubAccount{Currency: BTC, Amount: amount, ForzenAmount: 0, LoanAmount: 0}
+		if currency == BTC { // 兼容之前返回的XBT
+			acc.SubAccounts[XBT] = SubAccount{Currency: XBT, Amount: amount, ForzenAmount: 0, LoanAmount: 0}
 		}
 	}
 
@@ -219,7 +303,7 @@ func (k *Kraken) GetAccount() (*Account, error) {
 
 func (k *Kraken) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	var resultmap map[string]interface{}
-	err := k.doAuthenticatedRequest("GET", "public/Ticker?pair="+k.convertPair(currency).ToSymbol(""), url.Values{}, &resultmap)
+	err := k.doAuthenticatedRequest("GET", "public/Ticker?pair="+k.pairSymbol(currency), url.Values{}, &resultmap)
 	if err != nil {
 		return nil, err
 	}
@@ -239,7 +323,7 @@ func (k *Kraken) GetTicker(currency CurrencyPair) (*Ticker, error) {
 }
 
 func (k *Kraken) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
-	apiuri := fmt.Sprintf("public/Depth?pair=%s&count=%d", k.convertPair(currency).ToSymbol(""), size)
+	apiuri := fmt.Sprintf("public/Depth?pair=%s&count=%d", k.pairSymbol(currency), size)
 	var resultmap map[string]interface{}
 	err := k.doAuthenticatedRequest("GET", apiuri, url.Values{}, &resultmap)
 	if err != nil {
@@ -268,8 +352,69 @@ func (k *Kraken) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
 	return &dep, nil
 }
 
+var klineIntervals = map[int]string{
+	KLINE_PERIOD_1MIN:  "1",
+	KLINE_PERIOD_5MIN:  "5",
+	KLINE_PERIOD_15MIN: "15",
+	KLINE_PERIOD_30MIN: "30",
+	KLINE_PERIOD_60MIN: "60",
+	KLINE_PERIOD_4H:    "240",
+	KLINE_PERIOD_1DAY:  "1440",
+	KLINE_PERIOD_1WEEK: "10080",
+}
+
 func (k *Kraken) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
-	panic("")
+	klines, err := k.GetKlineRecordsDecimal(currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return KlineDecimalsToKlines(klines), nil
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线. 接口每次最多返回720根, 不指定since时只能取到最近的720根
+ */
+func (k *Kraken) GetKlineRecordsDecimal(currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	interval, err := KlinePeriodCode(klineIntervals, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetAllKlineDecimals(int64(since), size, 720, func(q PageQuery) ([]KlineDecimal, error) {
+		apiuri := fmt.Sprintf("public/OHLC?pair=%s&interval=%s", k.pairSymbol(currency), interval)
+		if q.From > 0 {
+			// 返回时间晚于since的K线
+			apiuri += fmt.Sprintf("&since=%d", q.From/1000-1)
+		}
+		var resultmap map[string]interface{}
+		err := k.doAuthenticatedRequest("GET", apiuri, url.Values{}, &resultmap)
+		if err != nil {
+			return nil, err
+		}
+
+		var klines []KlineDecimal
+		for key, v := range resultmap {
+			// last为下次查询用的since
+			rows, ok := v.([]interface{})
+			if key == "last" || !ok {
+				continue
+			}
+			// [time, open, high, low, close, vwap, volume, count]
+			for _, row := range rows {
+				r, _ := row.([]interface{})
+				if len(r) < 7 {
+					continue
+				}
+				ts := ToDecimal(r[0]).IntPart()
+				if q.To > 0 && ts*1000 > q.To {
+					continue
+				}
+				klines = append(klines, KlineDecimal{Pair: currency, Timestamp: ts,
+					Open: ToDecimal(r[1]), High: ToDecimal(r[2]), Low: ToDecimal(r[3]), Close: ToDecimal(r[4]), Vol: ToDecimal(r[6])})
+			}
+		}
+		return klines, nil
+	})
 }
 
 //非个人，整个交易所的交易记录
@@ -337,11 +482,17 @@ func (k *Kraken) doAuthenticatedRequest(method, apiuri string, params url.Values
 }
 
 func (k *Kraken) convertCurrency(currencySymbol string) Currency {
-	if len(currencySymbol) >= 4 {
-		currencySymbol = strings.Replace(currencySymbol, "X", "", 1)
-		currencySymbol = strings.Replace(currencySymbol, "Z", "", 1)
+	return k.symbols.CanonicalCurrency(NewCurrency(currencySymbol, ""))
+}
+
+/**
+ * 交易对代码从交易对信息中查找, 获取交易对信息失败时按XBT的命名规则拼接
+ */
+func (k *Kraken) pairSymbol(pair CurrencyPair) string {
+	if symbol, err := k.symbols.ToNative(pair, INSTRUMENT_SPOT); err == nil {
+		return symbol
 	}
-	return NewCurrency(currencySymbol, "")
+	return k.convertPair(pair).ToSymbol("")
 }
 
 func (k *Kraken) convertPair(pair CurrencyPair) CurrencyPair {
@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/shopspring/decimal"
 	. "github.com/stephenlyu/GoEx"
 	"strings"
 )
@@ -16,6 +17,7 @@ const (
 	API_BASE_URL = "https://api.liqui.io/"
 	API_V1       = API_BASE_URL + "api/3/"
 	TICKER_URI   = "ticker/%s"
+	INFO_URI     = "info"
 )
 
 type Liqui struct {
@@ -32,6 +34,46 @@ func (liqui *Liqui) GetExchangeName() string {
 	return EXCHANGE_NAME
 }
 
+/**
+ * 交易品种来自info, hidden为1的交易对不在页面显示, 当作暂停交易
+ */
+func (liqui *Liqui) GetInstruments() ([]Instrument, error) {
+	var resp struct {
+		Pairs map[string]struct {
+			DecimalPlaces int32           `json:"decimal_places"`
+			MinAmount     decimal.Decimal `json:"min_amount"`
+			Hidden        int
+		}
+	}
+	err := HttpGet4(liqui.httpClient, API_V1+INFO_URI, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Instrument, 0, len(resp.Pairs))
+	for symbol, r := range resp.Pairs {
+		pair := NewCurrencyPair2(strings.ToUpper(symbol))
+		if pair == UNKNOWN_PAIR {
+			continue
+		}
+		inst := Instrument{
+			Exchange:       EXCHANGE_NAME,
+			Symbol:         symbol,
+			Pair:           pair,
+			Type:           INSTRUMENT_SPOT,
+			PricePrecision: r.DecimalPlaces,
+			MinQty:         r.MinAmount,
+			Status:         INSTRUMENT_STATUS_TRADING,
+		}
+		if r.Hidden != 0 {
+			inst.Status = INSTRUMENT_STATUS_SUSPENDED
+		}
+		inst.FillPrecision()
+		ret = append(ret, inst)
+	}
+	return ret, nil
+}
+
 func (liqui *Liqui) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	cur := strings.ToLower(currency.ToSymbol("_"))
 	if cur == "nil" {
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	. "github.com/stephenlyu/GoEx"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -40,6 +39,7 @@ type OKEx struct {
 	wsTickerHandleMap map[string]func(*Ticker)
 	wsDepthHandleMap  map[string]func(*Depth)
 	wsTradeHandleMap map[string]func(CurrencyPair, string, []Trade)
+	logger            Logger
 }
 
 func NewOKEx(client *http.Client, api_key, secret_key string) *OKEx {
@@ -69,6 +69,20 @@ func (ok *OKEx) buildPostForm(postForm *url.Values) error {
 	return nil
 }
 
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (ok *OKEx) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	ok.logger = logger
+}
+
+func (ok *OKEx) log() Logger {
+	return ExchangeLogger(ok.logger, OKEX_FUTURE)
+}
+
 func (ok *OKEx) GetExchangeName() string {
 	return OKEX_FUTURE
 }
@@ -169,7 +183,7 @@ func (ok *OKEx) GetFutureDepth(currencyPair CurrencyPair, contractType string, s
 	}
 
 	if bodyMap["error_code"] != nil {
-		log.Println(bodyMap)
+		ok.log().Warn("get future depth failed", "pair", currencyPair, "contract_type", contractType, "body", string(body))
 		return nil, errors.New(string(body))
 	}
 
@@ -243,7 +257,7 @@ func (ok *OKEx) GetFutureIndex(currencyPair CurrencyPair) (float64, error) {
 
 	v, yes := bodyMap["future_index"]
 	if !yes {
-		println(string(body))
+		ok.log().Warn("get future index failed", "pair", currencyPair, "body", string(body))
 		return 0, errors.New("No future_index field")
 	}
 
@@ -559,12 +573,12 @@ func (ok *OKEx) GetExchangeRate() (float64, error) {
 	respMap, err := HttpGet(ok.client, FUTURE_API_BASE_URL+_EXCHANGE_RATE_URI)
 
 	if err != nil {
-		log.Println(respMap)
+		ok.log().Warn("get exchange rate failed", "err", err)
 		return -1, err
 	}
 
 	if respMap["rate"] == nil {
-		log.Println(respMap)
+		ok.log().Warn("get exchange rate failed", "resp", respMap)
 		return -1, errors.New("error")
 	}
 
@@ -600,13 +614,13 @@ func (ok *OKEx) GetKlineRecords(contract_type string, currencyPair CurrencyPair,
 	//log.Println(params.Encode())
 	resp, err := ok.client.Get(FUTURE_API_BASE_URL + _GET_KLINE_URI + "?" + params.Encode())
 	if err != nil {
-		log.Println(err)
+		ok.log().Warn("get klines failed", "pair", currencyPair, "err", err)
 		return nil, err
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Println(err)
+		ok.log().Warn("get klines failed", "pair", currencyPair, "err", err)
 		return nil, err
 	}
 	//log.Println(string(body))
@@ -614,7 +628,7 @@ func (ok *OKEx) GetKlineRecords(contract_type string, currencyPair CurrencyPair,
 	var klines [][]interface{}
 	err = json.Unmarshal(body, &klines)
 	if err != nil {
-		log.Println(string(body))
+		ok.log().Warn("decode response failed", "err", err, "body", string(body))
 		return nil, err
 	}
 
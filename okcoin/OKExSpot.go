@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	. "github.com/stephenlyu/GoEx"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -20,6 +19,7 @@ type OKExSpot struct {
 	createWsLock      sync.Mutex
 	wsTickerHandleMap map[string]func(*Ticker)
 	wsDepthHandleMap  map[string]func(*Depth)
+	logger            Logger
 }
 
 func NewOKExSpot(client *http.Client, accesskey, secretkey string) *OKExSpot {
@@ -29,6 +29,20 @@ func NewOKExSpot(client *http.Client, accesskey, secretkey string) *OKExSpot {
 		wsDepthHandleMap:  make(map[string]func(*Depth))}
 }
 
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (okSpot *OKExSpot) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	okSpot.logger = logger
+}
+
+func (okSpot *OKExSpot) log() Logger {
+	return ExchangeLogger(okSpot.logger, OKEX)
+}
+
 func (ctx *OKExSpot) GetExchangeName() string {
 	return OKEX
 }
@@ -90,6 +104,7 @@ func (okSpot *OKExSpot) createWsConn() {
 
 		if okSpot.ws == nil {
 			okSpot.ws = NewWsConn("wss://real.okex.com:10441/websocket")
+			okSpot.ws.SetLogger(okSpot.logger)
 			okSpot.ws.Heartbeat(func() interface{} { return map[string]string{"event": "ping"} }, 20*time.Second)
 			okSpot.ws.ReConnect()
 			okSpot.ws.ReceiveMessage(func(msg []byte) {
@@ -101,7 +116,7 @@ func (okSpot *OKExSpot) createWsConn() {
 				var data []interface{}
 				err := json.Unmarshal(msg, &data)
 				if err != nil {
-					log.Println(err)
+					okSpot.log().Warn("decode websocket message failed", "err", err)
 					return
 				}
 
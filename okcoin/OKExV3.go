@@ -1,6 +1,7 @@
 package okcoin
 
 import (
+	"context"
 	. "github.com/stephenlyu/GoEx"
 	"net/http"
 	"io/ioutil"
@@ -39,6 +40,7 @@ const (
 	V3_WITHDRAW					= "/api/account/v3/withdrawal"
 	V3_DEPOSIT_HISTORY 			= "/api/account/v3/deposit/history/%s"
 	V3_WITHDRAW_HISTORY 		= "/api/account/v3/withdrawal/history/%s"
+	V3_DEPOSIT_ADDRESS 			= "/api/account/v3/deposit/address?currency=%s"
 )
 
 const (
@@ -71,6 +73,32 @@ type V3Instrument struct {
 	Alias string 			`json:"alias"`
 }
 
+func (this *V3Instrument) ToInstrument() Instrument {
+	tickSize, _ := decimal.NewFromString(this.TickSize)
+	lotSize, _ := decimal.NewFromString(this.TradeIncrement)
+	contractVal, _ := decimal.NewFromString(this.ContractVal)
+	// 交割时间为交割日16:00(北京时间)
+	delivery, _ := time.ParseInLocation("2006-01-02", this.Delivery, time.UTC)
+	inst := Instrument{
+		Exchange: OKEX,
+		Symbol: this.InstrumentId,
+		Pair: NewCurrencyPair(NewCurrency(this.UnderlyingIndex, ""), NewCurrency(this.QuoteCurrency, "")),
+		Type: INSTRUMENT_FUTURE,
+		TickSize: tickSize,
+		LotSize: lotSize,
+		MinQty: lotSize,
+		ContractValue: contractVal,
+		Multiplier: decimal.New(1, 0),
+		SettleCurrency: NewCurrency(this.UnderlyingIndex, ""),
+		Status: INSTRUMENT_STATUS_TRADING,
+	}
+	if !delivery.IsZero() {
+		inst.Expiry = delivery.Add(8 * time.Hour)
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 type V3Position struct {
 	CreateAt string 		`json:"create_at"`
 	InstrumentId string 	`json:"instrument_id"`
@@ -89,7 +117,7 @@ type V3Position struct {
 	UpdatedAt string 		`json:"updated_at"`
 }
 
-func (this *V3Position) ToFuturePosition() *FuturePosition {
+func (this *V3Position) ToFuturePosition(pair CurrencyPair) *FuturePosition {
 	p := &FuturePosition{}
 	p.BuyAmount, _ = strconv.ParseFloat(this.LongQty, 64)
 	p.BuyAvailable, _ = strconv.ParseFloat(this.LongAvailQty, 64)
@@ -103,7 +131,7 @@ func (this *V3Position) ToFuturePosition() *FuturePosition {
 	p.SellPriceAvg, _ = strconv.ParseFloat(this.ShortAvgCost, 64)
 	p.ForceLiquPrice, _ = strconv.ParseFloat(this.LiquidationPrice, 64)
 	p.InstrumentId = this.InstrumentId
-	p.Symbol = InstrumentId2CurrencyPair(this.InstrumentId)
+	p.Symbol = pair
 
 	return p
 }
@@ -113,22 +141,24 @@ func V3ParseDate(s string) int64 {
 	return t.UnixNano() / int64(time.Millisecond)
 }
 
-func InstrumentId2CurrencyPair(instrumentId string) CurrencyPair {
-	parts := strings.Split(instrumentId, "-")
-	return CurrencyPair{
-		Currency{Symbol: parts[0]},
-		Currency{Symbol: parts[1]},
+/**
+ * 合约代码对应的交易对, 从交易品种列表中查找
+ */
+func instrumentPair(symbols *SymbolMapper, instrumentId string) (CurrencyPair, error) {
+	inst, err := symbols.FromNative(instrumentId)
+	if err != nil {
+		return UNKNOWN_PAIR, err
 	}
+	return inst.Pair, nil
 }
 
 type OKExV3 struct {
-	apiKey,
-	apiSecretKey string
-	passphrase string
+	signer     *SignerRef
 	client            *http.Client
 
 	ws                *WsConn
 	createWsLock      sync.Mutex
+	wsLock            sync.RWMutex // 保护下面的handle map和depthManagers, ws读协程与订阅/取消订阅并发访问
 	wsLoginHandle func(err error)
 	wsDepthHandleMap  map[string]func(*Depth)
 	wsTradeHandleMap map[string]func(string, []Trade)
@@ -139,29 +169,81 @@ type OKExV3 struct {
 	wsOrderHandleMap  map[string]func([]FutureOrder)
 	depthManagers	 map[string]*DepthManager
 	errorHandle      func(error)
+	logger           Logger
+	symbols          *SymbolMapper
 }
 
 func NewOKExV3(client *http.Client, api_key, secret_key, passphrase string) *OKExV3 {
+	return NewOKExV3WithSigner(client, NewStaticSigner(api_key, secret_key, passphrase))
+}
+
+/**
+ * 使用自定义签名器创建实例, 密钥可以来自环境变量、加密文件或凭证代理
+ */
+func NewOKExV3WithSigner(client *http.Client, signer Signer) *OKExV3 {
 	ok := new(OKExV3)
-	ok.apiKey = api_key
-	ok.apiSecretKey = secret_key
-	ok.passphrase = passphrase
+	ok.signer = NewSignerRef(signer)
 	ok.client = client
+	ok.symbols = NewSymbolMapper(OKEX_FUTURE, NewInstrumentCatalog(OKEX_FUTURE, InstrumentAPIFunc(ok.getContractInstruments)))
+	SetServerClockHttpClient(OKEX, client)
 	return ok
 }
 
-func (ok *OKExV3) buildHeader(method, requestPath, body string) map[string]string {
-	now := time.Now().In(time.UTC)
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (ok *OKExV3) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	ok.logger = logger
+}
+
+func (ok *OKExV3) log() Logger {
+	return ExchangeLogger(ok.logger, OKEX)
+}
+
+/**
+ * 替换签名器, 用于运行时轮换密钥. websocket需要重新登录才能使用新的密钥
+ */
+func (ok *OKExV3) SetSigner(signer Signer) {
+	ok.signer.Set(signer)
+}
+
+// https://www.okex.com/docs/zh/#spot-time, {"iso":"2015-01-07T23:47:25.201Z","epoch":1420674445.201}
+func fetchV3ServerTime(ctx context.Context, client *http.Client) (time.Time, error) {
+	var resp struct {
+		Epoch decimal.Decimal
+	}
+	err := HttpGet4WithContext(ctx, client, FUTURE_V3_API_BASE_URL+"/api/general/v3/time", nil, &resp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, resp.Epoch.Shift(9).IntPart()), nil
+}
+
+func init() {
+	RegisterServerClock(NewServerClock(OKEX, fetchV3ServerTime))
+}
+
+func (ok *OKExV3) GetExchangeName() string {
+	return OKEX
+}
+
+func (ok *OKExV3) buildHeader(method, requestPath, body string) (map[string]string, error) {
+	now := ServerTime(OKEX).In(time.UTC)
 	timestamp := now.Format(V3_DATE_FORMAT)
-	message := timestamp + method + requestPath + body
-	signature, _ := GetParamHmacSHA256Base64Sign(ok.apiSecretKey, message)
+	signature, err := ok.signer.Sign(HMAC_SHA256_BASE64, SignMessage(timestamp + method + requestPath + body))
+	if err != nil {
+		return nil, err
+	}
 	return map[string]string {
-		"OK-ACCESS-KEY": ok.apiKey,
-		"OK-ACCESS-SIGN": signature,
+		"OK-ACCESS-KEY": signature.ApiKey,
+		"OK-ACCESS-SIGN": signature.Sign,
 		"OK-ACCESS-TIMESTAMP": timestamp,
-		"OK-ACCESS-PASSPHRASE": ok.passphrase,
+		"OK-ACCESS-PASSPHRASE": signature.Passphrase,
 		"Content-Type": "application/json",
-	}
+	}, nil
 }
 
 func (ok *OKExV3) GetInstruments() ([]V3Instrument, error) {
@@ -185,6 +267,40 @@ func (ok *OKExV3) GetInstruments() ([]V3Instrument, error) {
 	return instruments, err
 }
 
+/**
+ * 统一格式的交易品种, 用于InstrumentCatalog: NewInstrumentCatalog(exchange, InstrumentAPIFunc(ok.GetUnifiedInstruments))
+ */
+func (ok *OKExV3) GetUnifiedInstruments() ([]Instrument, error) {
+	instruments, err := ok.GetInstruments()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(instruments))
+	for i := range instruments {
+		ret[i] = instruments[i].ToInstrument()
+	}
+	return ret, nil
+}
+
+/**
+ * 交割合约和永续合约的交易品种, ws同时推送两种合约的持仓和订单
+ */
+func (ok *OKExV3) getContractInstruments() ([]Instrument, error) {
+	futures, err := ok.GetUnifiedInstruments()
+	if err != nil {
+		return nil, err
+	}
+	swaps, err := (&OKExV3_SWAP{client: ok.client}).GetUnifiedInstruments()
+	if err != nil {
+		return nil, err
+	}
+	return append(futures, swaps...), nil
+}
+
+func (ok *OKExV3) SymbolMapper() *SymbolMapper {
+	return ok.symbols
+}
+
 //{
 //"instrument_id":"EOS-USD-190628",
 //"last":"3.708",
@@ -263,7 +379,10 @@ func (this *OKExV3) GetDepth(instrumentId string) (*DepthDecimal, error) {
 	r := data
 
 	depth := new(DepthDecimal)
-	depth.Pair = InstrumentId2CurrencyPair(instrumentId)
+	depth.Pair, err = instrumentPair(this.symbols, instrumentId)
+	if err != nil {
+		return nil, err
+	}
 
 	depth.AskList = make([]DepthRecordDecimal, len(r.Asks), len(r.Asks))
 	for i, o := range r.Asks {
@@ -333,8 +452,11 @@ func (ok *OKExV3) GetPosition() ([]FuturePosition, error) {
 	var result struct {
 		Holding [][]V3Position
 	}
-	header := ok.buildHeader("GET", FUTURE_V3_POSITION, "")
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + FUTURE_V3_POSITION, header, &result)
+	header, err := ok.buildHeader("GET", FUTURE_V3_POSITION, "")
+	if err != nil {
+		return nil, err
+	}
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + FUTURE_V3_POSITION, header, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -342,7 +464,11 @@ func (ok *OKExV3) GetPosition() ([]FuturePosition, error) {
 	var ret []FuturePosition
 	for _, positions := range result.Holding {
 		for _, p := range positions {
-			ret = append(ret, *p.ToFuturePosition())
+			pair, err := instrumentPair(ok.symbols, p.InstrumentId)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, *p.ToFuturePosition(pair))
 		}
 	}
 
@@ -354,15 +480,23 @@ func (ok *OKExV3) GetInstrumentPosition(instrumentId string) ([]FuturePosition,
 		Holding []V3Position
 	}
 	reqPath := fmt.Sprintf(FUTURE_V3_INSTRUMENT_POSITION, instrumentId)
-	header := ok.buildHeader("GET", reqPath, "")
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqPath, header, &result)
+	header, err := ok.buildHeader("GET", reqPath, "")
+	if err != nil {
+		return nil, err
+	}
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqPath, header, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := instrumentPair(ok.symbols, instrumentId)
 	if err != nil {
 		return nil, err
 	}
 
 	var ret []FuturePosition
 	for _, p := range result.Holding {
-		ret = append(ret, *p.ToFuturePosition())
+		ret = append(ret, *p.ToFuturePosition(pair))
 	}
 
 	return ret, err
@@ -391,8 +525,6 @@ func (ok *OKExV3) GetInstrumentTicker(instrumentId string) (*Ticker, error) {
 		return nil, err
 	}
 
-	fmt.Println(tickerMap)
-
 	ticker := new(Ticker)
 	ticker.Date = uint64(V3ParseDate(tickerMap["timestamp"].(string)))
 	ticker.Buy, _ = strconv.ParseFloat(tickerMap["best_bid"].(string), 64)
@@ -428,7 +560,7 @@ func (ok *OKExV3) GetInstrumentIndex(instrumentId string) (float64, error) {
 
 	v, yes := bodyMap["index"]
 	if !yes {
-		println(string(body))
+		ok.log().Warn("get index failed", "instrument_id", instrumentId, "body", string(body))
 		return 0, errors.New("No future_index field")
 	}
 
@@ -471,14 +603,17 @@ type V3AccountsResponse struct {
 
 func (ok *OKExV3) GetAccount() (*FutureAccount, error) {
 	var resp *V3AccountsResponse
-	header := ok.buildHeader("GET", FUTURE_V3_ACCOUNTS, "")
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + FUTURE_V3_ACCOUNTS, header, &resp)
+	header, err := ok.buildHeader("GET", FUTURE_V3_ACCOUNTS, "")
+	if err != nil {
+		return nil, err
+	}
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + FUTURE_V3_ACCOUNTS, header, &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	if !resp.Result && resp.Error_code > 0 {
-		return nil, fmt.Errorf("error code: %d", resp.Error_code)
+		return nil, OKExV3Error(resp.Error_code, "")
 	}
 
 	account := new(FutureAccount)
@@ -495,8 +630,11 @@ func (ok *OKExV3) GetAccount() (*FutureAccount, error) {
 func (ok *OKExV3) GetCurrencyAccount(currency Currency) (*FutureSubAccount, error) {
 	var resp *V3CurrencyInfo
 	reqUrl := fmt.Sprintf(FUTURE_V3_CURRENCY_ACCOUNTS, currency)
-	header := ok.buildHeader("GET", reqUrl, "")
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -518,7 +656,10 @@ func (ok *OKExV3) PlaceFutureOrder(clientOid string, instrumentId string, price,
 	bytes, _ := json.Marshal(params)
 	data := string(bytes)
 
-	header := ok.buildHeader("POST", FUTURE_V3_ORDER, data)
+	header, err := ok.buildHeader("POST", FUTURE_V3_ORDER, data)
+	if err != nil {
+		return "", err
+	}
 
 	placeOrderUrl := FUTURE_V3_API_BASE_URL + FUTURE_V3_ORDER
 	body, err := HttpPostJson(ok.client, placeOrderUrl, data, header)
@@ -542,7 +683,7 @@ func (ok *OKExV3) PlaceFutureOrder(clientOid string, instrumentId string, price,
 	}
 
 	if ret.ErrorCode.IntPart() != 0 {
-		return "", fmt.Errorf("error code: %d", ret.ErrorCode.IntPart())
+		return "", OKExV3Error(ret.ErrorCode, ret.ErrorMessage)
 	}
 
 	return ret.OrderId, nil
@@ -551,12 +692,15 @@ func (ok *OKExV3) PlaceFutureOrder(clientOid string, instrumentId string, price,
 func (ok *OKExV3) FutureCancelOrder(instrumentId, orderId string) error {
 	reqUrl := fmt.Sprintf(FUTURE_V3_CANCEL_ORDER, instrumentId, orderId)
 
-	header := ok.buildHeader("POST", reqUrl, "")
+	header, err := ok.buildHeader("POST", reqUrl, "")
+	if err != nil {
+		return err
+	}
 
 	reqPath := FUTURE_V3_API_BASE_URL + reqUrl
 	body, err := HttpPostJson(ok.client, reqPath, "", header)
 	if err != nil {
-		fmt.Println("FutureCancelOrder fail, error: ", err)
+		ok.log().Warn("cancel order failed", "instrument_id", instrumentId, "order_id", orderId, "err", err)
 		return err
 	}
 
@@ -567,7 +711,8 @@ func (ok *OKExV3) FutureCancelOrder(instrumentId, orderId string) error {
 	}
 	if respMap["result"] != nil && !respMap["result"].(bool) {
 		if respMap["error_code"] != nil {
-			return fmt.Errorf("error code: %s", respMap["error_code"].(string))
+			message, _ := respMap["error_message"].(string)
+			return OKExV3Error(respMap["error_code"], message)
 		}
 		return errors.New(string(body))
 	}
@@ -601,7 +746,10 @@ func (ok *OKExV3) PlaceFutureOrders(req BatchPlaceOrderReq) ([]BatchPlaceOrderRe
 	bytes, _ := json.Marshal(req)
 	data := string(bytes)
 
-	header := ok.buildHeader("POST", FUTURE_V3_ORDERS, data)
+	header, err := ok.buildHeader("POST", FUTURE_V3_ORDERS, data)
+	if err != nil {
+		return nil, err
+	}
 
 	placeOrderUrl := FUTURE_V3_API_BASE_URL + FUTURE_V3_ORDERS
 	body, err := HttpPostJson(ok.client, placeOrderUrl, data, header)
@@ -643,7 +791,10 @@ func (ok *OKExV3) FutureCancelOrders(instrumentId string, orderIds, clientOids [
 
 	reqUrl := fmt.Sprintf(FUTURE_V3_CANCEL_ORDERS, instrumentId)
 
-	header := ok.buildHeader("POST", reqUrl, string(bytes))
+	header, err := ok.buildHeader("POST", reqUrl, string(bytes))
+	if err != nil {
+		return err
+	}
 
 	reqPath := FUTURE_V3_API_BASE_URL + reqUrl
 	body, err := HttpPostJson(ok.client, reqPath, string(bytes), header)
@@ -685,7 +836,7 @@ type V3OrderInfo struct {
 	Leverage string
 }
 
-func (this *V3OrderInfo) ToFutureOrder() *FutureOrder {
+func (this *V3OrderInfo) ToFutureOrder(pair CurrencyPair) *FutureOrder {
 	if this.OrderId == "" {
 		return nil
 	}
@@ -709,7 +860,7 @@ func (this *V3OrderInfo) ToFutureOrder() *FutureOrder {
 	case "4":
 		o.Status = ORDER_CANCEL_ING
 	}
-	o.Currency = InstrumentId2CurrencyPair(this.InstrumentId)
+	o.Currency = pair
 	o.OType, _ = strconv.Atoi(this.Type)
 	o.Fee, _ = strconv.ParseFloat(this.Fee, 64)
 	o.LeverRate, _ = strconv.Atoi(this.Leverage)
@@ -719,15 +870,22 @@ func (this *V3OrderInfo) ToFutureOrder() *FutureOrder {
 
 func (ok *OKExV3) GetInstrumentOrder(instrumentId string, orderId string) (*FutureOrder, error) {
 	reqUrl := fmt.Sprintf(FUTURE_V3_ORDER_INFO, instrumentId, orderId)
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp *V3OrderInfo
 
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
+	if err != nil {
+		return nil, err
+	}
+	pair, err := instrumentPair(ok.symbols, instrumentId)
 	if err != nil {
 		return nil, err
 	}
-	return resp.ToFutureOrder(), nil
+	return resp.ToFutureOrder(pair), nil
 }
 
 type V3Fill struct {
@@ -784,11 +942,14 @@ func (ok *OKExV3) GetOrderFills(instrumentId, orderId string, from, to, limit st
 		reqUrl += "?" + strings.Join(params, "&")
 	}
 
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var fills []V3Fill
 
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &fills)
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &fills)
 	if err != nil {
 		return nil, err
 	}
@@ -820,14 +981,17 @@ func (ok *OKExV3) GetInstrumentOrders(instrumentId string, status, from, to, lim
 		reqUrl += "?" + strings.Join(params, "&")
 	}
 
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp *struct{
 		Result bool
 		Orders []V3OrderInfo		`json:"order_info"`
 	}
 
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -836,14 +1000,49 @@ func (ok *OKExV3) GetInstrumentOrders(instrumentId string, status, from, to, lim
 		return nil, errors.New("query orders fail")
 	}
 
+	pair, err := instrumentPair(ok.symbols, instrumentId)
+	if err != nil {
+		return nil, err
+	}
 	ret := make([]FutureOrder, len(resp.Orders))
 	for i, o := range resp.Orders {
-		ret[i] = *o.ToFutureOrder()
+		ret[i] = *o.ToFutureOrder(pair)
 	}
 
 	return ret, nil
 }
 
+/**
+ * 从最新的订单开始向前翻页, 获取全部订单. 以上一页最早的订单ID作为after参数
+ */
+func (ok *OKExV3) GetAllInstrumentOrders(instrumentId string, status string, limit int) ([]FutureOrder, error) {
+	p := NewPaginator(PAGING_BY_CURSOR, limit, 0, 0, func(q PageQuery) ([]interface{}, string, error) {
+		orders, err := ok.GetInstrumentOrders(instrumentId, status, "", q.Cursor, strconv.Itoa(q.Limit))
+		records := make([]interface{}, len(orders))
+		var next decimal.Decimal
+		for i, o := range orders {
+			records[i] = o
+			id, _ := decimal.NewFromString(o.OrderID2)
+			if i == 0 || id.LessThan(next) {
+				next = id
+			}
+		}
+		if len(orders) == 0 {
+			return records, "", err
+		}
+		return records, next.String(), err
+	}, func(r interface{}) string {
+		return r.(FutureOrder).OrderID2
+	}, nil)
+
+	records, err := p.All()
+	ret := make([]FutureOrder, len(records))
+	for i, r := range records {
+		ret[i] = r.(FutureOrder)
+	}
+	return ret, err
+}
+
 type FutureLedger struct {
 	Amount string			`json:"amount"`
 	Balance string			`json:"balance"`
@@ -872,11 +1071,14 @@ func (ok *OKExV3) GetLedger(currency Currency, from, to, limit string) ([]Future
 	if len(params) > 0 {
 		reqUrl += "?" + strings.Join(params, "&")
 	}
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp []FutureLedger
 
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -884,6 +1086,40 @@ func (ok *OKExV3) GetLedger(currency Currency, from, to, limit string) ([]Future
 	return resp, nil
 }
 
+/**
+ * 获取[from, to]时间范围内的全部账单流水, 时间单位为毫秒, 0表示不限制.
+ * 账单按时间倒序返回, 以本页最小的ledger_id作为下一页的to, 取更早的记录
+ */
+func (ok *OKExV3) GetAllLedger(currency Currency, from, to int64, limit int) ([]FutureLedger, error) {
+	p := NewPaginator(PAGING_BY_CURSOR, limit, from, to, func(q PageQuery) ([]interface{}, string, error) {
+		ledgers, err := ok.GetLedger(currency, "", q.Cursor, strconv.Itoa(q.Limit))
+		records := make([]interface{}, len(ledgers))
+		var next decimal.Decimal
+		for i, l := range ledgers {
+			records[i] = l
+			id, _ := decimal.NewFromString(l.LedgerId)
+			if i == 0 || id.LessThan(next) {
+				next = id
+			}
+		}
+		if len(ledgers) == 0 {
+			return records, "", err
+		}
+		return records, next.String(), err
+	}, func(r interface{}) string {
+		return r.(FutureLedger).LedgerId
+	}, func(r interface{}) int64 {
+		return V3ParseDate(r.(FutureLedger).Timestamp)
+	})
+
+	records, err := p.All()
+	ret := make([]FutureLedger, len(records))
+	for i, r := range records {
+		ret[i] = r.(FutureLedger)
+	}
+	return ret, err
+}
+
 const (
 	WalletLedgerTypeDeposit = "1"
 	WalletLedgerTypeWithdraw = "2"
@@ -926,11 +1162,14 @@ func (ok *OKExV3) GetWalletLedger(currency Currency, from, to, limit, _type stri
 	if len(params) > 0 {
 		reqUrl += "?" + strings.Join(params, "&")
 	}
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp []WalletLedger
 
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -960,6 +1199,11 @@ type TransferResp struct {
 }
 
 func (ok *OKExV3) WalletTransfer(currency Currency, amount float64, from, to int, subAccount string, instrumentId string) (error, *TransferResp) {
+	return ok.walletTransfer(currency, strconv.FormatFloat(amount, 'f', -1, 64), from, to, subAccount, instrumentId)
+}
+
+// amount为十进制字符串, 避免浮点数精度损失
+func (ok *OKExV3) walletTransfer(currency Currency, amount string, from, to int, subAccount string, instrumentId string) (error, *TransferResp) {
 	param := map[string]interface{} {
 		"currency": currency.Symbol,
 		"amount": amount,
@@ -970,7 +1214,10 @@ func (ok *OKExV3) WalletTransfer(currency Currency, amount float64, from, to int
 	}
 	bytes, _ := json.Marshal(param)
 
-	header := ok.buildHeader("POST", WALLET_V3_TRANSFER, string(bytes))
+	header, err := ok.buildHeader("POST", WALLET_V3_TRANSFER, string(bytes))
+	if err != nil {
+		return err, nil
+	}
 
 	reqPath := FUTURE_V3_API_BASE_URL + WALLET_V3_TRANSFER
 	body, err := HttpPostJson(ok.client, reqPath, string(bytes), header)
@@ -998,11 +1245,14 @@ type WalletCurrency struct {
 
 func (ok *OKExV3) GetWallet(currency Currency) (*WalletCurrency, error) {
 	reqUrl := fmt.Sprintf(WALLET_V3_INFO, strings.ToLower(currency.Symbol))
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp []WalletCurrency
 
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -1024,11 +1274,14 @@ func (ok *OKExV3) GetWithdrawFee(currency string) ([]WithDrawFee, error) {
 	if currency != "" {
 		reqUrl += "?currency=" + currency
 	}
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp []WithDrawFee
 
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -1053,6 +1306,11 @@ type WithdrawResp struct {
 }
 
 func (ok *OKExV3) Withdraw(currency Currency, amount float64, destination int, toAddress string, tradePwd string, fee float64) (error, *WithdrawResp) {
+	return ok.withdraw(currency, strconv.FormatFloat(amount, 'f', -1, 64), destination, toAddress, tradePwd, strconv.FormatFloat(fee, 'f', -1, 64))
+}
+
+// amount和fee为十进制字符串, 避免浮点数精度损失
+func (ok *OKExV3) withdraw(currency Currency, amount string, destination int, toAddress string, tradePwd string, fee string) (error, *WithdrawResp) {
 	param := map[string]interface{} {
 		"currency": currency.Symbol,
 		"amount": amount,
@@ -1063,7 +1321,10 @@ func (ok *OKExV3) Withdraw(currency Currency, amount float64, destination int, t
 	}
 	bytes, _ := json.Marshal(param)
 
-	header := ok.buildHeader("POST", V3_WITHDRAW, string(bytes))
+	header, err := ok.buildHeader("POST", V3_WITHDRAW, string(bytes))
+	if err != nil {
+		return err, nil
+	}
 
 	reqPath := FUTURE_V3_API_BASE_URL + V3_WITHDRAW
 	body, err := HttpPostJson(ok.client, reqPath, string(bytes), header)
@@ -1084,6 +1345,7 @@ func (ok *OKExV3) Withdraw(currency Currency, amount float64, destination int, t
 }
 
 type DepositRecord struct {
+	DepositId decimal.Decimal 	`json:"deposit_id"`
 	Amount decimal.Decimal
 	Txid string
 	Currency string
@@ -1094,11 +1356,14 @@ type DepositRecord struct {
 
 func (ok *OKExV3) GetDepositHistory(currency string) ([]DepositRecord, error) {
 	reqUrl := fmt.Sprintf(V3_DEPOSIT_HISTORY, currency)
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp []DepositRecord
 
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -1125,11 +1390,14 @@ type WithdrawRecord struct {
 
 func (ok *OKExV3) GetWithdrawHistory(currency string) ([]WithdrawRecord, error) {
 	reqUrl := fmt.Sprintf(V3_WITHDRAW_HISTORY, currency)
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp []WithdrawRecord
 
-	err := HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
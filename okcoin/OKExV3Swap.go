@@ -70,6 +70,27 @@ type V3_SWAPInstrument struct {
 	UnderlyingIndex string 	`json:"underlying_index"`
 }
 
+func (this *V3_SWAPInstrument) ToInstrument() Instrument {
+	tickSize, _ := decimal.NewFromString(this.TickSize)
+	lotSize, _ := decimal.NewFromString(this.SizeIncrement)
+	contractVal, _ := decimal.NewFromString(this.ContractVal)
+	inst := Instrument{
+		Exchange: OKEX,
+		Symbol: this.InstrumentId,
+		Pair: NewCurrencyPair(NewCurrency(this.UnderlyingIndex, ""), NewCurrency(this.QuoteCurrency, "")),
+		Type: INSTRUMENT_SWAP,
+		TickSize: tickSize,
+		LotSize: lotSize,
+		MinQty: lotSize,
+		ContractValue: contractVal,
+		Multiplier: decimal.New(1, 0),
+		SettleCurrency: NewCurrency(this.Coin, ""),
+		Status: INSTRUMENT_STATUS_TRADING,
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 type V3_SWAPPosition struct {
 	MarginMode string 		`json:"margin_mode"`
 	LiquidationPrice string `json:"liquidation_price"`
@@ -85,7 +106,7 @@ type V3_SWAPPosition struct {
 	Timestamp string 		`json:"timestamp"`
 }
 
-func (this *V3_SWAPPosition) ToFuturePosition() *FuturePosition {
+func (this *V3_SWAPPosition) ToFuturePosition(pair CurrencyPair) *FuturePosition {
 	p := &FuturePosition{}
 	if this.Side == "long" {
 		p.BuyAmount, _ = strconv.ParseFloat(this.Position, 64)
@@ -103,7 +124,7 @@ func (this *V3_SWAPPosition) ToFuturePosition() *FuturePosition {
 	p.LeverRate, _ = strconv.Atoi(this.Leverage)
 	p.ForceLiquPrice, _ = strconv.ParseFloat(this.LiquidationPrice, 64)
 	p.InstrumentId = this.InstrumentId
-	p.Symbol = V3SWAPInstrumentId2CurrencyPair(this.InstrumentId)
+	p.Symbol = pair
 
 	return p
 }
@@ -113,23 +134,13 @@ func V3_SWAPParseDate(s string) int64 {
 	return t.UnixNano() / int64(time.Millisecond)
 }
 
-func V3SWAPInstrumentId2CurrencyPair(instrumentId string) CurrencyPair {
-	parts := strings.Split(instrumentId, "-")
-	return CurrencyPair{
-		Currency{Symbol: parts[0]},
-		Currency{Symbol: parts[1]},
-	}
-}
-
 func V3SWAPInstrumentId2Currency(instrumentId string) Currency {
 	parts := strings.Split(instrumentId, "-")
 	return Currency{Symbol: parts[0]}
 }
 
 type OKExV3_SWAP struct {
-	apiKey,
-	apiSecretKey string
-	passphrase string
+	signer     *SignerRef
 	client            *http.Client
 
 	ws                *WsConn
@@ -137,29 +148,61 @@ type OKExV3_SWAP struct {
 	wsDepthHandleMap  map[string]func(*Depth)
 	wsTradeHandleMap map[string]func(string, []Trade)
 	depthManagers	 map[string]*DepthManager
+	logger           Logger
+	symbols          *SymbolMapper
 }
 
 func NewOKExV3_SWAP(client *http.Client, api_key, secret_key, passphrase string) *OKExV3_SWAP {
+	return NewOKExV3_SWAPWithSigner(client, NewStaticSigner(api_key, secret_key, passphrase))
+}
+
+/**
+ * 使用自定义签名器创建实例, 密钥可以来自环境变量、加密文件或凭证代理
+ */
+func NewOKExV3_SWAPWithSigner(client *http.Client, signer Signer) *OKExV3_SWAP {
 	ok := new(OKExV3_SWAP)
-	ok.apiKey = api_key
-	ok.apiSecretKey = secret_key
-	ok.passphrase = passphrase
+	ok.signer = NewSignerRef(signer)
 	ok.client = client
+	ok.symbols = NewSymbolMapper(OKEX_FUTURE, NewInstrumentCatalog(OKEX_FUTURE, InstrumentAPIFunc(ok.GetUnifiedInstruments)))
+	SetServerClockHttpClient(OKEX, client)
 	return ok
 }
 
-func (ok *OKExV3_SWAP) buildHeader(method, requestPath, body string) map[string]string {
-	now := time.Now().In(time.UTC)
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (ok *OKExV3_SWAP) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	ok.logger = logger
+}
+
+func (ok *OKExV3_SWAP) log() Logger {
+	return ExchangeLogger(ok.logger, OKEX)
+}
+
+/**
+ * 替换签名器, 用于运行时轮换密钥. websocket需要重新登录才能使用新的密钥
+ */
+func (ok *OKExV3_SWAP) SetSigner(signer Signer) {
+	ok.signer.Set(signer)
+}
+
+func (ok *OKExV3_SWAP) buildHeader(method, requestPath, body string) (map[string]string, error) {
+	now := ServerTime(OKEX).In(time.UTC)
 	timestamp := now.Format(V3_SWAP_DATE_FORMAT)
-	message := timestamp + method + requestPath + body
-	signature, _ := GetParamHmacSHA256Base64Sign(ok.apiSecretKey, message)
+	signature, err := ok.signer.Sign(HMAC_SHA256_BASE64, SignMessage(timestamp + method + requestPath + body))
+	if err != nil {
+		return nil, err
+	}
 	return map[string]string {
-		"OK-ACCESS-KEY": ok.apiKey,
-		"OK-ACCESS-SIGN": signature,
+		"OK-ACCESS-KEY": signature.ApiKey,
+		"OK-ACCESS-SIGN": signature.Sign,
 		"OK-ACCESS-TIMESTAMP": timestamp,
-		"OK-ACCESS-PASSPHRASE": ok.passphrase,
+		"OK-ACCESS-PASSPHRASE": signature.Passphrase,
 		"Content-Type": "application/json",
-	}
+	}, nil
 }
 
 func (ok *OKExV3_SWAP) GetInstruments() ([]V3_SWAPInstrument, error) {
@@ -180,6 +223,25 @@ func (ok *OKExV3_SWAP) GetInstruments() ([]V3_SWAPInstrument, error) {
 	return instruments, err
 }
 
+/**
+ * 统一格式的交易品种, 用于InstrumentCatalog: NewInstrumentCatalog(exchange, InstrumentAPIFunc(ok.GetUnifiedInstruments))
+ */
+func (ok *OKExV3_SWAP) GetUnifiedInstruments() ([]Instrument, error) {
+	instruments, err := ok.GetInstruments()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(instruments))
+	for i := range instruments {
+		ret[i] = instruments[i].ToInstrument()
+	}
+	return ret, nil
+}
+
+func (ok *OKExV3_SWAP) SymbolMapper() *SymbolMapper {
+	return ok.symbols
+}
+
 //{
 //"instrument_id":"EOS-USD-SWAP",
 //"last":"3.611",
@@ -258,7 +320,10 @@ func (this *OKExV3_SWAP) GetDepth(instrumentId string) (*DepthDecimal, error) {
 	r := data
 
 	depth := new(DepthDecimal)
-	depth.Pair = InstrumentId2CurrencyPair(instrumentId)
+	depth.Pair, err = instrumentPair(this.symbols, instrumentId)
+	if err != nil {
+		return nil, err
+	}
 
 	depth.AskList = make([]DepthRecordDecimal, len(r.Asks), len(r.Asks))
 	for i, o := range r.Asks {
@@ -329,8 +394,11 @@ func (ok *OKExV3_SWAP) GetPosition() ([]FuturePosition, error) {
 		MarginMode string 	`json:"margin_mode"`
 		Holding []V3_SWAPPosition
 	}
-	header := ok.buildHeader("GET", SWAP_V3_POSITION, "")
-	err := HttpGet4(ok.client, SWAP_V3_API_BASE_URL + SWAP_V3_POSITION, header, &result)
+	header, err := ok.buildHeader("GET", SWAP_V3_POSITION, "")
+	if err != nil {
+		return nil, err
+	}
+	err = HttpGet4(ok.client, SWAP_V3_API_BASE_URL + SWAP_V3_POSITION, header, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -340,7 +408,11 @@ func (ok *OKExV3_SWAP) GetPosition() ([]FuturePosition, error) {
 			panic("Fixed margin mode not supported")
 		}
 		for _, p := range item.Holding {
-			ret = append(ret, *p.ToFuturePosition())
+			pair, err := instrumentPair(ok.symbols, p.InstrumentId)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, *p.ToFuturePosition(pair))
 		}
 	}
 
@@ -353,8 +425,11 @@ func (ok *OKExV3_SWAP) GetInstrumentPosition(instrumentId string) ([]FuturePosit
 		Holding []V3_SWAPPosition
 	}
 	reqPath := fmt.Sprintf(SWAP_V3_INSTRUMENT_POSITION, instrumentId)
-	header := ok.buildHeader("GET", reqPath, "")
-	err := HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqPath, header, &result)
+	header, err := ok.buildHeader("GET", reqPath, "")
+	if err != nil {
+		return nil, err
+	}
+	err = HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqPath, header, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -363,9 +438,14 @@ func (ok *OKExV3_SWAP) GetInstrumentPosition(instrumentId string) ([]FuturePosit
 		panic("fixed margin mode not supported")
 	}
 
+	pair, err := instrumentPair(ok.symbols, instrumentId)
+	if err != nil {
+		return nil, err
+	}
+
 	var ret []FuturePosition
 	for _, p := range result.Holding {
-		ret = append(ret, *p.ToFuturePosition())
+		ret = append(ret, *p.ToFuturePosition(pair))
 	}
 
 	return ret, err
@@ -428,7 +508,7 @@ func (ok *OKExV3_SWAP) GetInstrumentIndex(instrumentId string) (float64, error)
 
 	v, yes := bodyMap["index"]
 	if !yes {
-		println(string(body))
+		ok.log().Warn("get index failed", "instrument_id", instrumentId, "body", string(body))
 		return 0, errors.New("No future_index field")
 	}
 
@@ -471,14 +551,17 @@ type V3_SWAPAccountsResponse struct {
 
 func (ok *OKExV3_SWAP) GetAccount() (*FutureAccount, error) {
 	var resp *V3_SWAPAccountsResponse
-	header := ok.buildHeader("GET", SWAP_V3_ACCOUNTS, "")
-	err := HttpGet4(ok.client, SWAP_V3_API_BASE_URL + SWAP_V3_ACCOUNTS, header, &resp)
+	header, err := ok.buildHeader("GET", SWAP_V3_ACCOUNTS, "")
+	if err != nil {
+		return nil, err
+	}
+	err = HttpGet4(ok.client, SWAP_V3_API_BASE_URL + SWAP_V3_ACCOUNTS, header, &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	if !resp.Result && resp.Error_code > 0 {
-		return nil, fmt.Errorf("error code: %d", resp.Error_code)
+		return nil, OKExV3Error(resp.Error_code, "")
 	}
 
 	account := new(FutureAccount)
@@ -498,8 +581,11 @@ func (ok *OKExV3_SWAP) GetInstrumentAccount(instrumentId string) (*FutureSubAcco
 		Info *V3_SWAPCurrencyInfo
 	}
 	reqUrl := fmt.Sprintf(SWAP_V3_INSTRUMENT_ACCOUNTS, instrumentId)
-	header := ok.buildHeader("GET", reqUrl, "")
-	err := HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &resp)
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
+	err = HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -526,7 +612,10 @@ func (ok *OKExV3_SWAP) PlaceFutureOrder(clientOid string, instrumentId string, p
 	bytes, _ := json.Marshal(params)
 	data := string(bytes)
 
-	header := ok.buildHeader("POST", SWAP_V3_ORDER, data)
+	header, err := ok.buildHeader("POST", SWAP_V3_ORDER, data)
+	if err != nil {
+		return "", err
+	}
 
 	placeOrderUrl := SWAP_V3_API_BASE_URL + SWAP_V3_ORDER
 	body, err := HttpPostJson(ok.client, placeOrderUrl, data, header)
@@ -549,7 +638,7 @@ func (ok *OKExV3_SWAP) PlaceFutureOrder(clientOid string, instrumentId string, p
 	}
 
 	if ret.ErrorCode != "0" {
-		return "", fmt.Errorf("error code: %s", ret.ErrorCode)
+		return "", OKExV3Error(ret.ErrorCode, ret.ErrorMessage)
 	}
 
 	return ret.OrderId, nil
@@ -558,7 +647,10 @@ func (ok *OKExV3_SWAP) PlaceFutureOrder(clientOid string, instrumentId string, p
 func (ok *OKExV3_SWAP) FutureCancelOrder(instrumentId, orderId string) error {
 	reqUrl := fmt.Sprintf(SWAP_V3_CANCEL_ORDER, instrumentId, orderId)
 
-	header := ok.buildHeader("POST", reqUrl, "")
+	header, err := ok.buildHeader("POST", reqUrl, "")
+	if err != nil {
+		return err
+	}
 
 	reqPath := SWAP_V3_API_BASE_URL + reqUrl
 	body, err := HttpPostJson(ok.client, reqPath, "", header)
@@ -578,7 +670,7 @@ func (ok *OKExV3_SWAP) FutureCancelOrder(instrumentId, orderId string) error {
 		return err
 	}
 	if resp.ErrorCode != "" {
-		return fmt.Errorf("error code: %s", resp.ErrorCode)
+		return OKExV3Error(resp.ErrorCode, resp.ErrorMessage)
 	}
 
 	return nil
@@ -609,7 +701,10 @@ func (ok *OKExV3_SWAP) PlaceFutureOrders(req V3SwapBatchPlaceOrderReq) ([]V3Swap
 	bytes, _ := json.Marshal(req)
 	data := string(bytes)
 
-	header := ok.buildHeader("POST", SWAP_V3_ORDERS, data)
+	header, err := ok.buildHeader("POST", SWAP_V3_ORDERS, data)
+	if err != nil {
+		return nil, err
+	}
 
 	placeOrderUrl := SWAP_V3_API_BASE_URL + SWAP_V3_ORDERS
 	body, err := HttpPostJson(ok.client, placeOrderUrl, data, header)
@@ -642,7 +737,10 @@ func (ok *OKExV3_SWAP) FutureCancelOrders(instrumentId string, orderIds []string
 
 	reqUrl := fmt.Sprintf(SWAP_V3_CANCEL_ORDERS, instrumentId)
 
-	header := ok.buildHeader("POST", reqUrl, string(bytes))
+	header, err := ok.buildHeader("POST", reqUrl, string(bytes))
+	if err != nil {
+		return err
+	}
 
 	reqPath := SWAP_V3_API_BASE_URL + reqUrl
 	body, err := HttpPostJson(ok.client, reqPath, string(bytes), header)
@@ -662,7 +760,7 @@ func (ok *OKExV3_SWAP) FutureCancelOrders(instrumentId string, orderIds []string
 		return err
 	}
 	if resp.ErrorCode != "" {
-		return fmt.Errorf("error code: %s", resp.ErrorCode)
+		return OKExV3Error(resp.ErrorCode, resp.ErrorMessage)
 	}
 
 	return nil
@@ -684,7 +782,7 @@ type V3_SWAPOrderInfo struct {
 	Leverage string
 }
 
-func (this *V3_SWAPOrderInfo) ToFutureOrder() *FutureOrder {
+func (this *V3_SWAPOrderInfo) ToFutureOrder(pair CurrencyPair) *FutureOrder {
 	if this.OrderId == "" {
 		return nil
 	}
@@ -708,7 +806,7 @@ func (this *V3_SWAPOrderInfo) ToFutureOrder() *FutureOrder {
 	case "4":
 		o.Status = ORDER_CANCEL_ING
 	}
-	o.Currency = V3SWAPInstrumentId2CurrencyPair(this.InstrumentId)
+	o.Currency = pair
 	o.OType, _ = strconv.Atoi(this.Type)
 	o.Fee, _ = strconv.ParseFloat(this.Fee, 64)
 	o.LeverRate, _ = strconv.Atoi(this.Leverage)
@@ -735,20 +833,27 @@ func (ok *OKExV3_SWAP) GetInstrumentOrders(instrumentId string, status, from, to
 		reqUrl += "?" + strings.Join(params, "&")
 	}
 
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp *struct{
 		Orders []V3_SWAPOrderInfo		`json:"order_info"`
 	}
 
-	err := HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
 
+	pair, err := instrumentPair(ok.symbols, instrumentId)
+	if err != nil {
+		return nil, err
+	}
 	ret := make([]FutureOrder, len(resp.Orders))
 	for i, o := range resp.Orders {
-		ret[i] = *o.ToFutureOrder()
+		ret[i] = *o.ToFutureOrder(pair)
 	}
 
 	return ret, nil
@@ -756,15 +861,22 @@ func (ok *OKExV3_SWAP) GetInstrumentOrders(instrumentId string, status, from, to
 
 func (ok *OKExV3_SWAP) GetInstrumentOrder(instrumentId string, orderId string) (*FutureOrder, error) {
 	reqUrl := fmt.Sprintf(SWAP_V3_ORDER_INFO, instrumentId, orderId)
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp *V3_SWAPOrderInfo
 
-	err := HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &resp)
+	if err != nil {
+		return nil, err
+	}
+	pair, err := instrumentPair(ok.symbols, instrumentId)
 	if err != nil {
 		return nil, err
 	}
-	return resp.ToFutureOrder(), nil
+	return resp.ToFutureOrder(pair), nil
 }
 
 type V3_SwapFill struct {
@@ -821,11 +933,14 @@ func (ok *OKExV3_SWAP) GetOrderFills(instrumentId, orderId string, from, to, lim
 		reqUrl += "?" + strings.Join(params, "&")
 	}
 
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var fills []V3_SwapFill
 
-	err := HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &fills)
+	err = HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &fills)
 	if err != nil {
 		return nil, err
 	}
@@ -862,11 +977,14 @@ func (ok *OKExV3_SWAP) GetLedger(instrumentId string, from, to, limit string) ([
 	if len(params) > 0 {
 		reqUrl += "?" + strings.Join(params, "&")
 	}
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp []V3FutureLedger
 
-	err := HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -889,11 +1007,14 @@ func (ok *OKExV3_SWAP) GetFundingRateHistory(instrumentId string, from, to, limi
 	if len(params) > 0 {
 		reqUrl += "?" + strings.Join(params, "&")
 	}
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp []SWAPFundingRate
 
-	err := HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &resp)
+	err = HttpGet4(ok.client, SWAP_V3_API_BASE_URL + reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,81 @@
+package okcoin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	. "github.com/stephenlyu/GoEx"
+)
+
+// https://www.okex.com/docs/zh/#README, 30xxx为公共错误码, 32xxx为交割合约, 33xxx为币币, 35xxx为永续合约
+var OKExV3ErrorCodes = ErrorCodes{
+	"30001": ERR_AUTH,
+	"30002": ERR_AUTH,
+	"30003": ERR_AUTH,
+	"30004": ERR_AUTH,
+	"30005": ERR_NONCE,
+	"30006": ERR_AUTH,
+	"30008": ERR_NONCE,
+	"30012": ERR_AUTH,
+	"30013": ERR_AUTH,
+	"30014": ERR_RATE_LIMITED,
+	"30023": ERR_INVALID_PARAM,
+	"30024": ERR_INVALID_PARAM,
+	"30025": ERR_INVALID_PARAM,
+	"30026": ERR_RATE_LIMITED,
+	"30030": ERR_MAINTENANCE,
+	"30031": ERR_INVALID_SYMBOL,
+	"30032": ERR_INVALID_SYMBOL,
+	"32004": ERR_ORDER_NOT_FOUND,
+	"32014": ERR_INSUFFICIENT_BALANCE,
+	"33014": ERR_ORDER_NOT_FOUND,
+	"33017": ERR_INSUFFICIENT_BALANCE,
+	"35029": ERR_ORDER_NOT_FOUND,
+}
+
+/**
+ * OKEx v3接口错误, code为错误码, 可以是数字或字符串
+ */
+func OKExV3Error(code interface{}, message string) error {
+	return OKExV3ErrorCodes.NewError(OKEX, okexCode(code), message)
+}
+
+func okexCode(code interface{}) string {
+	switch c := code.(type) {
+	case nil:
+		return ""
+	case float64:
+		return fmt.Sprint(int64(c))
+	case decimal.Decimal:
+		return c.String()
+	}
+	return fmt.Sprint(code)
+}
+
+/**
+ * 解析v3接口的错误响应, 格式为{"code":33014,"message":"..."}或{"error_code":"33014","error_message":"..."}
+ */
+func ParseOKExV3Error(statusCode int, body []byte) *ExchangeError {
+	var resp struct {
+		Code         interface{}
+		Message      string
+		ErrorCode    interface{} `json:"error_code"`
+		ErrorMessage string      `json:"error_message"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return nil
+	}
+	code, message := okexCode(resp.ErrorCode), resp.ErrorMessage
+	if code == "" || code == "0" {
+		code, message = okexCode(resp.Code), resp.Message
+	}
+	if code == "" || code == "0" {
+		return nil
+	}
+	return OKExV3ErrorCodes.NewError(OKEX, code, message)
+}
+
+func init() {
+	RegisterErrorParser(OKEX, ParseOKExV3Error, "www.okex.com")
+}
@@ -0,0 +1,227 @@
+package okcoin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	. "github.com/stephenlyu/GoEx"
+)
+
+var okexV3WalletTypes = map[WalletType]int{
+	WALLET_SPOT:    WALLET_ACCOUNT_SPOT,
+	WALLET_MARGIN:  WALLET_ACCOUNT_LEVERAGE,
+	WALLET_FUTURE:  WALLET_ACCOUNT_FUTURE,
+	WALLET_SWAP:    WALLET_ACCOUNT_SWAP,
+	WALLET_FUNDING: WALLET_ACCOUNT_WALLET,
+}
+
+type V3DepositAddress struct {
+	Address   string
+	Tag       string
+	PaymentId string `json:"payment_id"`
+	Memo      string
+	Currency  string
+	To        decimal.Decimal // 充值到账账户, 1: 币币账户; 6: 资金账户
+}
+
+func (ok *OKExV3) GetDepositAddress(currency Currency) ([]DepositAddress, error) {
+	reqUrl := fmt.Sprintf(V3_DEPOSIT_ADDRESS, strings.ToLower(currency.Symbol))
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []V3DepositAddress
+
+	err = HttpGet4(ok.client, FUTURE_V3_API_BASE_URL+reqUrl, header, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []DepositAddress
+	for _, a := range resp {
+		tag := a.Tag
+		if tag == "" {
+			tag = a.Memo
+		}
+		if tag == "" {
+			tag = a.PaymentId
+		}
+		ret = append(ret, DepositAddress{
+			Currency: NewCurrency(strings.ToUpper(a.Currency), ""),
+			Address:  a.Address,
+			Tag:      tag,
+		})
+	}
+	return ret, nil
+}
+
+// 0: 等待确认; 1: 确认到账; 2: 充值成功
+func (this *DepositRecord) ToFundingRecord() *FundingRecord {
+	var status FundingStatus
+	switch this.Status.IntPart() {
+	case 0:
+		status = FUNDING_PROCESSING
+	default:
+		status = FUNDING_SUCCESS
+	}
+
+	id := this.DepositId.String()
+	if this.DepositId.IsZero() {
+		id = this.Txid
+	}
+
+	return &FundingRecord{
+		Id:        id,
+		Type:      FUNDING_DEPOSIT,
+		Currency:  NewCurrency(strings.ToUpper(this.Currency), ""),
+		Amount:    this.Amount,
+		Address:   this.To,
+		TxId:      this.Txid,
+		Status:    status,
+		RawStatus: this.Status.String(),
+		Timestamp: V3ParseDate(this.Timestamp),
+	}
+}
+
+func (ok *OKExV3) GetDeposits(currency Currency) ([]FundingRecord, error) {
+	records, err := ok.GetDepositHistory(strings.ToLower(currency.Symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]FundingRecord, len(records))
+	for i := range records {
+		ret[i] = *records[i].ToFundingRecord()
+	}
+	return ret, nil
+}
+
+/**
+ * 不支持param.Chain
+ */
+func (ok *OKExV3) CreateWithdrawal(param WithdrawParam) (string, error) {
+	if param.Chain != "" {
+		return "", EX_ERR_NOT_SUPPORTED
+	}
+	fee := param.Fee
+	if fee.IsZero() {
+		fees, err := ok.GetWithdrawFee(strings.ToLower(param.Currency.Symbol))
+		if err != nil {
+			return "", err
+		}
+		if len(fees) == 0 {
+			return "", errors.New("unknown withdraw fee")
+		}
+		fee = fees[0].MinFee
+	}
+
+	// 需要标签的币种, 地址格式为 address:tag
+	address := param.Address
+	if param.Tag != "" {
+		address += ":" + param.Tag
+	}
+
+	err, resp := ok.withdraw(param.Currency, param.Amount.String(), WithdrawDestinationOuter, address, param.TradePwd, fee.String())
+	if err != nil {
+		return "", err
+	}
+	return resp.WithdrawalId.String(), nil
+}
+
+// -3: 撤销中; -2: 已撤销; -1: 失败; 0: 等待提现; 1: 提现中; 2: 已汇出; 3: 邮箱确认; 4: 人工审核中; 5: 等待身份认证
+func (this *WithdrawRecord) ToFundingRecord() *FundingRecord {
+	var status FundingStatus
+	switch this.Status.IntPart() {
+	case -2:
+		status = FUNDING_CANCELED
+	case -1:
+		status = FUNDING_FAILED
+	case 1:
+		status = FUNDING_PROCESSING
+	case 2:
+		status = FUNDING_SUCCESS
+	default:
+		status = FUNDING_PENDING
+	}
+
+	tag := this.Tag
+	if tag == "" {
+		tag = this.PaymentId
+	}
+
+	// 手续费带有币种后缀, 如0.00000009btc
+	fee, _ := decimal.NewFromString(strings.TrimSuffix(strings.ToLower(this.Fee), strings.ToLower(this.Currency)))
+
+	return &FundingRecord{
+		Id:        this.WithdrawalId.String(),
+		Type:      FUNDING_WITHDRAW,
+		Currency:  NewCurrency(strings.ToUpper(this.Currency), ""),
+		Amount:    this.Amount,
+		Fee:       fee,
+		Address:   this.To,
+		Tag:       tag,
+		TxId:      this.Txid,
+		Status:    status,
+		RawStatus: this.Status.String(),
+		Timestamp: V3ParseDate(this.Timestamp),
+	}
+}
+
+func (ok *OKExV3) GetWithdrawals(currency Currency) ([]FundingRecord, error) {
+	records, err := ok.GetWithdrawHistory(strings.ToLower(currency.Symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]FundingRecord, len(records))
+	for i := range records {
+		ret[i] = *records[i].ToFundingRecord()
+	}
+	return ret, nil
+}
+
+func (ok *OKExV3) GetWithdrawal(currency Currency, withdrawId string) (*FundingRecord, error) {
+	records, err := ok.GetWithdrawals(currency)
+	if err != nil {
+		return nil, err
+	}
+	return FindFundingRecord(records, withdrawId)
+}
+
+func (ok *OKExV3) GetWithdrawalFees(currency Currency) ([]WithdrawFeeDecimal, error) {
+	var symbol string
+	if currency != UNKNOWN {
+		symbol = strings.ToLower(currency.Symbol)
+	}
+	fees, err := ok.GetWithdrawFee(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]WithdrawFeeDecimal, len(fees))
+	for i, f := range fees {
+		ret[i] = WithdrawFeeDecimal{
+			Currency: NewCurrency(strings.ToUpper(f.Currency), ""),
+			MinFee:   f.MinFee,
+			MaxFee:   f.MaxFee,
+		}
+	}
+	return ret, nil
+}
+
+func (ok *OKExV3) TransferBetweenWallets(currency Currency, amount decimal.Decimal, from, to WalletType) (string, error) {
+	fromAccount, ok1 := okexV3WalletTypes[from]
+	toAccount, ok2 := okexV3WalletTypes[to]
+	if !ok1 || !ok2 {
+		return "", EX_ERR_NOT_SUPPORTED
+	}
+
+	err, resp := ok.walletTransfer(currency, amount.String(), fromAccount, toAccount, "", "")
+	if err != nil {
+		return "", err
+	}
+	return resp.TransferId.String(), nil
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	. "github.com/stephenlyu/GoEx"
-	"log"
 	"strings"
 	"time"
 	"compress/flate"
@@ -23,6 +22,19 @@ func GzipDecodeV3(in []byte) ([]byte, error) {
 	return ioutil.ReadAll(reader)
 }
 
+func (okFuture *OKExV3) initWsHandles() {
+	okFuture.wsLock.Lock()
+	defer okFuture.wsLock.Unlock()
+	okFuture.wsDepthHandleMap = make(map[string]func(*Depth))
+	okFuture.wsTradeHandleMap = make(map[string]func(string, []Trade))
+	okFuture.wsIndexTickerHandleMap = make(map[string]func(string, []Ticker))
+	okFuture.wsFundingRateHandleMap = make(map[string]func(SWAPFundingRate))
+	okFuture.wsPositionHandleMap = make(map[string]func([]FuturePosition))
+	okFuture.wsAccountHandleMap = make(map[string]func(bool, *FutureAccount))
+	okFuture.wsOrderHandleMap = make(map[string]func([]FutureOrder))
+	okFuture.depthManagers = make(map[string]*DepthManager)
+}
+
 func (okFuture *OKExV3) createWsConn() {
 	if okFuture.ws == nil {
 		//connect wsx
@@ -30,119 +42,149 @@ func (okFuture *OKExV3) createWsConn() {
 		defer okFuture.createWsLock.Unlock()
 
 		if okFuture.ws == nil {
-			okFuture.wsDepthHandleMap = make(map[string]func(*Depth))
-			okFuture.wsTradeHandleMap = make(map[string]func(string, []Trade))
-			okFuture.wsIndexTickerHandleMap = make(map[string]func(string, []Ticker))
-			okFuture.wsFundingRateHandleMap = make(map[string]func(SWAPFundingRate))
-			okFuture.wsPositionHandleMap = make(map[string]func([]FuturePosition))
-			okFuture.wsAccountHandleMap = make(map[string]func(bool, *FutureAccount))
-			okFuture.wsOrderHandleMap = make(map[string]func([]FutureOrder))
-			okFuture.depthManagers = make(map[string]*DepthManager)
-
+			okFuture.initWsHandles()
 			okFuture.ws = NewWsConn("wss://real.okex.com:8443/ws/v3")
 			okFuture.ws.Heartbeat(func() interface{} { return "ping"}, 20*time.Second)
 			okFuture.ws.SetErrorHandler(okFuture.errorHandle)
+			okFuture.ws.SetLogger(okFuture.logger)
 			okFuture.ws.ReConnect()
-			okFuture.ws.ReceiveMessageEx(func(isBin bool, msg []byte) {
-				if isBin {
-					msg, _ = GzipDecodeV3(msg)
-				}
-				//println(string(msg))
-				if string(msg) == "pong" {
-					okFuture.ws.UpdateActivedTime()
-					return
-				}
-
-				var data struct {
-					Event string
-					ErrorCode int
-					Message string
-					Success bool
-					Table string
-					Action string
-					Data []interface{}
-				}
-				err := json.Unmarshal(msg, &data)
-				if err != nil {
-					log.Print(err)
-					return
-				}
-
-				if data.Event == "login" {
-					var err error
-					if !data.Success {
-						err = errors.New("Login failure")
-					}
-					if okFuture.wsLoginHandle != nil {
-						okFuture.wsLoginHandle(err)
-					}
-					return
-				}
-
-				if len(data.Data) == 0 {
-					return
-				}
-
-				switch data.Table  {
-				case "swap/trade", "futures/trade":
-				instrumentId, trades := okFuture.parseTrade(msg)
-				if instrumentId != "" {
-					topic := fmt.Sprintf("%s:%s", data.Table, instrumentId)
-					okFuture.wsTradeHandleMap[topic](instrumentId, trades)
-				}
-				case "swap/depth", "futures/depth":
-					depth := okFuture.parseDepth(msg)
-					if depth != nil {
-						topic := fmt.Sprintf("%s:%s", data.Table, depth.InstrumentId)
-						okFuture.wsDepthHandleMap[topic](depth)
-					}
-				case "index/ticker":
-					instrumentId, tickers := okFuture.parseIndexTicker(msg)
-					if len(tickers) > 0 {
-						topic := fmt.Sprintf("%s:%s", data.Table, instrumentId)
-						okFuture.wsIndexTickerHandleMap[topic](instrumentId, tickers)
-					}
-				case "futures/position":
-					instrumentId, positions := okFuture.parseFuturesPosition(msg)
-					if positions != nil {
-						topic := fmt.Sprintf("%s:%s", data.Table, instrumentId)
-						okFuture.wsPositionHandleMap[topic](positions)
-					}
-				case "futures/account":
-					account := okFuture.parseFuturesAccount(msg)
-					if account != nil {
-						okFuture.wsAccountHandleMap[data.Table](false, account)
-					}
-				case "futures/order":
-					instrumentId, orders := okFuture.parseFuturesOrder(msg)
-					if orders != nil {
-						topic := fmt.Sprintf("%s:%s", data.Table, instrumentId)
-						okFuture.wsOrderHandleMap[topic](orders)
-					}
-				case "swap/funding_rate":
-					fundingRate := okFuture.parseFundingRate(msg)
-					if fundingRate != nil {
-						okFuture.wsFundingRateHandleMap[data.Table](*fundingRate)
-					}
-				case "swap/position":
-					instrumentId, positions := okFuture.parseSwapPosition(msg)
-					if positions != nil {
-						topic := fmt.Sprintf("%s:%s", data.Table, instrumentId)
-						okFuture.wsPositionHandleMap[topic](positions)
-					}
-				case "swap/account":
-					account := okFuture.parseSwapAccount(msg)
-					if account != nil {
-						okFuture.wsAccountHandleMap[data.Table](false, account)
-					}
-				case "swap/order":
-					instrumentId, orders := okFuture.parseSwapOrder(msg)
-					if orders != nil {
-						topic := fmt.Sprintf("%s:%s", data.Table, instrumentId)
-						okFuture.wsOrderHandleMap[topic](orders)
-					}
-				}
-			})
+			okFuture.ws.ReceiveMessageEx(okFuture.handleWsMessage)
+		}
+	}
+}
+
+func (okFuture *OKExV3) handleWsMessage(isBin bool, msg []byte) {
+	if isBin {
+		msg, _ = GzipDecodeV3(msg)
+	}
+	//println(string(msg))
+	if string(msg) == "pong" {
+		okFuture.ws.UpdateActivedTime()
+		return
+	}
+
+	var data struct {
+		Event string
+		ErrorCode int
+		Message string
+		Success bool
+		Table string
+		Action string
+		Data []interface{}
+	}
+	err := json.Unmarshal(msg, &data)
+	if err != nil {
+		okFuture.log().Warn("decode websocket message failed", "err", err)
+		return
+	}
+
+	if data.Event == "login" {
+		var err error
+		if !data.Success {
+			err = errors.New("Login failure")
+		}
+		if okFuture.wsLoginHandle != nil {
+			okFuture.wsLoginHandle(err)
+		}
+		return
+	}
+
+	if len(data.Data) == 0 {
+		return
+	}
+
+	switch data.Table  {
+	case "swap/trade", "futures/trade":
+		instrumentId, trades := okFuture.parseTrade(msg)
+		if instrumentId != "" {
+			topic := fmt.Sprintf("%s:%s", data.Table, instrumentId)
+			okFuture.wsLock.RLock()
+			handle := okFuture.wsTradeHandleMap[topic]
+			okFuture.wsLock.RUnlock()
+			if handle != nil {
+				handle(instrumentId, trades)
+			}
+		}
+	case "swap/depth", "futures/depth":
+		depth := okFuture.parseDepth(msg)
+		if depth != nil {
+			topic := fmt.Sprintf("%s:%s", data.Table, depth.InstrumentId)
+			okFuture.wsLock.RLock()
+			handle := okFuture.wsDepthHandleMap[topic]
+			okFuture.wsLock.RUnlock()
+			if handle != nil {
+				handle(depth)
+			}
+		}
+	case "index/ticker":
+		instrumentId, tickers := okFuture.parseIndexTicker(msg)
+		if len(tickers) > 0 {
+			topic := fmt.Sprintf("%s:%s", data.Table, instrumentId)
+			okFuture.wsLock.RLock()
+			handle := okFuture.wsIndexTickerHandleMap[topic]
+			okFuture.wsLock.RUnlock()
+			if handle != nil {
+				handle(instrumentId, tickers)
+			}
+		}
+	case "futures/position", "swap/position":
+		var instrumentId string
+		var positions []FuturePosition
+		if data.Table == "swap/position" {
+			instrumentId, positions = okFuture.parseSwapPosition(msg)
+		} else {
+			instrumentId, positions = okFuture.parseFuturesPosition(msg)
+		}
+		if positions != nil {
+			topic := fmt.Sprintf("%s:%s", data.Table, instrumentId)
+			okFuture.wsLock.RLock()
+			handle := okFuture.wsPositionHandleMap[topic]
+			okFuture.wsLock.RUnlock()
+			if handle != nil {
+				handle(positions)
+			}
+		}
+	case "futures/account", "swap/account":
+		var account *FutureAccount
+		if data.Table == "swap/account" {
+			account = okFuture.parseSwapAccount(msg)
+		} else {
+			account = okFuture.parseFuturesAccount(msg)
+		}
+		if account != nil {
+			okFuture.wsLock.RLock()
+			handle := okFuture.wsAccountHandleMap[data.Table]
+			okFuture.wsLock.RUnlock()
+			if handle != nil {
+				handle(false, account)
+			}
+		}
+	case "futures/order", "swap/order":
+		var instrumentId string
+		var orders []FutureOrder
+		if data.Table == "swap/order" {
+			instrumentId, orders = okFuture.parseSwapOrder(msg)
+		} else {
+			instrumentId, orders = okFuture.parseFuturesOrder(msg)
+		}
+		if orders != nil {
+			topic := fmt.Sprintf("%s:%s", data.Table, instrumentId)
+			okFuture.wsLock.RLock()
+			handle := okFuture.wsOrderHandleMap[topic]
+			okFuture.wsLock.RUnlock()
+			if handle != nil {
+				handle(orders)
+			}
+		}
+	case "swap/funding_rate":
+		fundingRate := okFuture.parseFundingRate(msg)
+		if fundingRate != nil {
+			okFuture.wsLock.RLock()
+			handle := okFuture.wsFundingRateHandleMap[data.Table]
+			okFuture.wsLock.RUnlock()
+			if handle != nil {
+				handle(*fundingRate)
+			}
 		}
 	}
 }
@@ -151,15 +193,17 @@ func (okFuture *OKExV3) isSwap(instrumentId string) bool {
 	return strings.HasSuffix(instrumentId, "SWAP")
 }
 
-func (okFuture *OKExV3) getLoginData() interface{} {
-	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
-	message := timestamp + "GET/users/self/verify"
-	sign, _ := GetParamHmacSHA256Base64Sign(okFuture.apiSecretKey, message)
+func (okFuture *OKExV3) getLoginData() (interface{}, error) {
+	timestamp := strconv.FormatInt(ServerTime(OKEX).Unix(), 10)
+	signature, err := okFuture.signer.Sign(HMAC_SHA256_BASE64, SignMessage(timestamp + "GET/users/self/verify"))
+	if err != nil {
+		return nil, err
+	}
 
 	return map[string]interface{}{
 		"op":   "login",
-		"args": []interface{}{okFuture.apiKey, okFuture.passphrase, timestamp, sign},
-	}
+		"args": []interface{}{signature.ApiKey, signature.Passphrase, timestamp, signature.Sign},
+	}, nil
 }
 
 func (okFuture *OKExV3) doLogin() error {
@@ -171,8 +215,11 @@ func (okFuture *OKExV3) doLogin() error {
 
 	okFuture.wsLoginHandle = onDone
 
-	data := okFuture.getLoginData()
-	err := okFuture.ws.SendMessage(data)
+	data, err := okFuture.getLoginData()
+	if err != nil {
+		return err
+	}
+	err = okFuture.ws.SendMessage(data)
 	if err != nil {
 		return err
 	}
@@ -197,8 +244,10 @@ func (okFuture *OKExV3) GetDepthWithWs(instrumentId string, handle func(*Depth))
 		channel = fmt.Sprintf("futures/depth:%s", instrumentId)
 	}
 
+	okFuture.wsLock.Lock()
 	okFuture.wsDepthHandleMap[channel] = handle
 	okFuture.depthManagers[instrumentId] = NewDepthManager()
+	okFuture.wsLock.Unlock()
 	return okFuture.ws.Subscribe(map[string]interface{}{
 		"op":   "subscribe",
 		"args": []interface{}{channel}})
@@ -214,19 +263,67 @@ func (okFuture *OKExV3) GetTradeWithWs(instrumentId string, handle func(string,
 		channel = fmt.Sprintf("futures/trade:%s", instrumentId)
 	}
 
+	okFuture.wsLock.Lock()
 	okFuture.wsTradeHandleMap[channel] = handle
+	okFuture.wsLock.Unlock()
 	return okFuture.ws.Subscribe(map[string]interface{}{
 		"op":   "subscribe",
 		"args": []interface{}{channel}})
 }
 
+func (okFuture *OKExV3) unsubscribeWs(channel string) error {
+	okFuture.createWsConn()
+	return okFuture.ws.Unsubscribe(
+		map[string]interface{}{
+			"op":   "unsubscribe",
+			"args": []interface{}{channel}},
+		map[string]interface{}{
+			"op":   "subscribe",
+			"args": []interface{}{channel}})
+}
+
+// 取消GetDepthWithWs的订阅, 如合约交割后
+func (okFuture *OKExV3) UnsubscribeDepthWithWs(instrumentId string) error {
+	var channel string
+	if okFuture.isSwap(instrumentId) {
+		channel = fmt.Sprintf("swap/depth:%s", instrumentId)
+	} else {
+		channel = fmt.Sprintf("futures/depth:%s", instrumentId)
+	}
+
+	err := okFuture.unsubscribeWs(channel)
+	okFuture.wsLock.Lock()
+	delete(okFuture.wsDepthHandleMap, channel)
+	delete(okFuture.depthManagers, instrumentId)
+	okFuture.wsLock.Unlock()
+	return err
+}
+
+// 取消GetTradeWithWs的订阅
+func (okFuture *OKExV3) UnsubscribeTradeWithWs(instrumentId string) error {
+	var channel string
+	if okFuture.isSwap(instrumentId) {
+		channel = fmt.Sprintf("swap/trade:%s", instrumentId)
+	} else {
+		channel = fmt.Sprintf("futures/trade:%s", instrumentId)
+	}
+
+	err := okFuture.unsubscribeWs(channel)
+	okFuture.wsLock.Lock()
+	delete(okFuture.wsTradeHandleMap, channel)
+	okFuture.wsLock.Unlock()
+	return err
+}
+
 func (okFuture *OKExV3) GetIndexTickerWithWs(instrumentId string, handle func(string, []Ticker)) error {
 	okFuture.createWsConn()
 
 	var channel string
 	channel = fmt.Sprintf("index/ticker:%s", instrumentId)
 
+	okFuture.wsLock.Lock()
 	okFuture.wsIndexTickerHandleMap[channel] = handle
+	okFuture.wsLock.Unlock()
 	return okFuture.ws.Subscribe(map[string]interface{}{
 		"op":   "subscribe",
 		"args": []interface{}{channel}})
@@ -238,7 +335,9 @@ func (okFuture *OKExV3) GetFundingRateWithWs(instrumentId string, handle func(SW
 	var channel string
 	channel = fmt.Sprintf("swap/funding_rate:%s", instrumentId)
 
+	okFuture.wsLock.Lock()
 	okFuture.wsFundingRateHandleMap["swap/funding_rate"] = handle
+	okFuture.wsLock.Unlock()
 	return okFuture.ws.Subscribe(map[string]interface{}{
 		"op":   "subscribe",
 		"args": []interface{}{channel}})
@@ -254,7 +353,9 @@ func (okFuture *OKExV3) GetPositionWithWs(instrumentId string, handle func([]Fut
 		channel = fmt.Sprintf("futures/position:%s", instrumentId)
 	}
 
+	okFuture.wsLock.Lock()
 	okFuture.wsPositionHandleMap[channel] = handle
+	okFuture.wsLock.Unlock()
 	return okFuture.ws.Subscribe(map[string]interface{}{
 		"op":   "subscribe",
 		"args": []interface{}{channel}})
@@ -273,7 +374,9 @@ func (okFuture *OKExV3) GetAccountWithWs(currency Currency, isSwap bool, handle
 		key = fmt.Sprintf("futures/account")
 	}
 
+	okFuture.wsLock.Lock()
 	okFuture.wsAccountHandleMap[key] = handle
+	okFuture.wsLock.Unlock()
 	return okFuture.ws.Subscribe(map[string]interface{}{
 		"op":   "subscribe",
 		"args": []interface{}{channel}})
@@ -289,7 +392,9 @@ func (okFuture *OKExV3) GetOrderWithWs(instrumentId string, handle func([]Future
 		channel = fmt.Sprintf("futures/order:%s", instrumentId)
 	}
 
+	okFuture.wsLock.Lock()
 	okFuture.wsOrderHandleMap[channel] = handle
+	okFuture.wsLock.Unlock()
 	return okFuture.ws.Subscribe(map[string]interface{}{
 		"op":   "subscribe",
 		"args": []interface{}{channel}})
@@ -355,9 +460,12 @@ func (okFuture *OKExV3) parseDepth(msg []byte) *Depth {
 	timestamp := V3ParseDate(data.Data[0].Timestamp)
 	instrumentId := data.Data[0].InstrumentId
 	parts := strings.Split(instrumentId, "-")
-	depthManager, _ := okFuture.depthManagers[instrumentId]
+	okFuture.wsLock.RLock()
+	depthManager := okFuture.depthManagers[instrumentId]
+	okFuture.wsLock.RUnlock()
 	if depthManager == nil {
-		panic("Illegal state error")
+		// 已取消订阅
+		return nil
 	}
 
 	asks, bids := depthManager.Update(data.Action, data.Data[0].Asks, data.Data[0].Bids)
@@ -414,7 +522,7 @@ func (okFuture *OKExV3) parseFuturesPosition(msg []byte) (string, []FuturePositi
 
 	ret := make([]FuturePosition, len(data.Data))
 	for i := range data.Data {
-		ret[i] = *data.Data[i].ToFuturePosition()
+		ret[i] = *data.Data[i].ToFuturePosition(okFuture.wsInstrumentPair(data.Data[i].InstrumentId))
 	}
 
 	return instrumentId, ret
@@ -453,7 +561,7 @@ func (okFuture *OKExV3) parseFuturesOrder(msg []byte) (string, []FutureOrder) {
 
 	ret := make([]FutureOrder, len(data.Data))
 	for i := range data.Data {
-		ret[i] = *data.Data[i].ToFutureOrder()
+		ret[i] = *data.Data[i].ToFutureOrder(okFuture.wsInstrumentPair(data.Data[i].InstrumentId))
 	}
 
 	return instrumentId, ret
@@ -472,7 +580,7 @@ func (okFuture *OKExV3) parseSwapPosition(msg []byte) (string, []FuturePosition)
 
 	ret := make([]FuturePosition, len(data.Data))
 	for i := range data.Data {
-		ret[i] = *data.Data[i].ToFuturePosition()
+		ret[i] = *data.Data[i].ToFuturePosition(okFuture.wsInstrumentPair(data.Data[i].InstrumentId))
 	}
 
 	return instrumentId, ret
@@ -519,12 +627,21 @@ func (okFuture *OKExV3) parseSwapOrder(msg []byte) (string, []FutureOrder) {
 
 	ret := make([]FutureOrder, len(data.Data))
 	for i := range data.Data {
-		ret[i] = *data.Data[i].ToFutureOrder()
+		ret[i] = *data.Data[i].ToFutureOrder(okFuture.wsInstrumentPair(data.Data[i].InstrumentId))
 	}
 
 	return instrumentId, ret
 }
 
+// ws推送中的合约代码转换为交易对, 找不到时通过errorHandle报告
+func (okFuture *OKExV3) wsInstrumentPair(instrumentId string) CurrencyPair {
+	pair, err := instrumentPair(okFuture.symbols, instrumentId)
+	if err != nil && okFuture.errorHandle != nil {
+		okFuture.errorHandle(err)
+	}
+	return pair
+}
+
 func (okFuture *OKExV3) CloseWs() {
 	okFuture.ws.CloseWs()
 }
@@ -8,6 +8,11 @@ import (
 	"time"
 	"os"
 	"runtime/pprof"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"github.com/gorilla/websocket"
 )
 
 var okexFutureV3 = NewOKExV3(http.DefaultClient, "", "", "")
@@ -87,3 +92,79 @@ func TestOKExV3_Authenticated_Swap(t *testing.T) {
 
 	time.Sleep(10 * time.Minute)
 }
+
+// 合约交割换月时在FuturesResolver协程取消旧合约、订阅新合约, 同时ws读协程在分发深度和成交
+func TestOKExV3_RollWhileDispatching(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	ok := NewOKExV3(http.DefaultClient, "", "", "")
+	ok.initWsHandles()
+	ok.ws = goex.NewWsConn("ws" + strings.TrimPrefix(srv.URL, "http"))
+	defer ok.ws.Close()
+
+	const rolls = 50
+	instrumentId := func(i int) string {
+		return fmt.Sprintf("BTC-USD-%06d", i)
+	}
+	onDepth := func(*goex.Depth) {}
+	onTrade := func(string, []goex.Trade) {}
+	if err := ok.GetDepthWithWs(instrumentId(0), onDepth); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := 0; ; n++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			id := instrumentId(n % (rolls + 1))
+			ok.handleWsMessage(false, []byte(`{"table":"futures/depth","action":"partial","data":[{"instrument_id":"` + id +
+				`","asks":[["8001","10","0","1"]],"bids":[["8000","5","0","1"]],"timestamp":"2020-01-01T00:00:00.000Z"}]}`))
+			ok.handleWsMessage(false, []byte(`{"table":"futures/trade","data":[{"instrument_id":"` + id +
+				`","price":"8000","side":"buy","qty":"1","timestamp":"2020-01-01T00:00:00.000Z","trade_id":"1"}]}`))
+		}
+	}()
+
+	for i := 0; i < rolls; i++ {
+		from, to := instrumentId(i), instrumentId(i+1)
+		if err := ok.UnsubscribeDepthWithWs(from); err != nil {
+			t.Fatal(err)
+		}
+		if err := ok.UnsubscribeTradeWithWs(from); err != nil {
+			t.Fatal(err)
+		}
+		if err := ok.GetDepthWithWs(to, onDepth); err != nil {
+			t.Fatal(err)
+		}
+		if err := ok.GetTradeWithWs(to, onTrade); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	ok.wsLock.RLock()
+	defer ok.wsLock.RUnlock()
+	if len(ok.depthManagers) != 1 || ok.depthManagers[instrumentId(rolls)] == nil {
+		t.Error(ok.depthManagers)
+	}
+}
@@ -167,6 +167,12 @@ func TestOKExV3_GetInstrumentOrders(t *testing.T) {
 	output(orders)
 }
 
+func TestOKExV3_GetAllInstrumentOrders(t *testing.T) {
+	orders, err := okexV3.GetAllInstrumentOrders("EOS-USD-190927", "7", 100)
+	assert.Nil(t, err)
+	output(orders)
+}
+
 func TestOKExV3_GetInstrumentOrder(t *testing.T) {
 	order, err := okexV3.GetInstrumentOrder("EOS-USD-190927", "ea376aad93ee403cbe51b8b44d87dbca")
 	assert.Nil(t, err)
@@ -453,3 +459,50 @@ func TestOKExV3_SWAP_GetFundingRateHistory(t *testing.T) {
 	assert.Nil(t, err)
 	output(ret)
 }
+
+func TestOKExV3_GetDepositAddress(t *testing.T) {
+	ret, err := okexV3.GetDepositAddress(goex.EOS)
+	assert.Nil(t, err)
+	output(ret)
+}
+
+func TestOKExV3_GetWithdrawals(t *testing.T) {
+	ret, err := okexV3.GetWithdrawals(goex.EOS)
+	assert.Nil(t, err)
+	output(ret)
+}
+
+// 按请求的to参数返回ledger_id小于to的账单, 每页最多limit条
+type fakeLedgerTransport struct {
+	ids      []int
+	requests []string
+}
+
+func (f *fakeLedgerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasSuffix(req.URL.Path, "/ledger") {
+		return &http.Response{StatusCode: 404, Body: ioutil.NopCloser(strings.NewReader("")), Request: req}, nil
+	}
+	f.requests = append(f.requests, req.URL.RawQuery)
+	to, _ := strconv.Atoi(req.URL.Query().Get("to"))
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	var page []FutureLedger
+	for _, id := range f.ids {
+		if (to == 0 || id < to) && len(page) < limit {
+			page = append(page, FutureLedger{LedgerId: strconv.Itoa(id), Timestamp: fmt.Sprintf("2020-01-01T00:00:%02d.000Z", id)})
+		}
+	}
+	body, _ := json.Marshal(page)
+	return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(string(body))), Request: req}, nil
+}
+
+func TestOKExV3_GetAllLedgerPaging(t *testing.T) {
+	transport := &fakeLedgerTransport{ids: []int{5, 4, 3, 2, 1}}
+	api := NewOKExV3(&http.Client{Transport: transport}, "key", "secret", "passphrase")
+	defer goex.SetServerClockHttpClient(goex.OKEX, http.DefaultClient)
+
+	ledgers, err := api.GetAllLedger(goex.BTC, 0, 0, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(ledgers))
+	assert.Equal(t, "1", ledgers[4].LedgerId)
+	assert.Equal(t, []string{"limit=3", "to=3&limit=3"}, transport.requests)
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	. "github.com/stephenlyu/GoEx"
-	"log"
 	"strings"
 	"time"
 	"compress/flate"
@@ -31,6 +30,7 @@ func (okFuture *OKEx) createWsConn() {
 			okFuture.wsTradeHandleMap = make(map[string]func(CurrencyPair, string, []Trade))
 
 			okFuture.ws = NewWsConn("wss://real.okex.com:10440/websocket/okexapi?compress=true")
+			okFuture.ws.SetLogger(okFuture.logger)
 			okFuture.ws.Heartbeat(func() interface{} { return map[string]string{"event": "ping"} }, 30*time.Second)
 			okFuture.ws.ReConnect()
 			okFuture.ws.ReceiveMessageEx(func(isBin bool, msg []byte) {
@@ -45,7 +45,7 @@ func (okFuture *OKEx) createWsConn() {
 				var data []interface{}
 				err := json.Unmarshal(msg, &data)
 				if err != nil {
-					log.Print(err)
+					okFuture.log().Warn("decode websocket message failed", "err", err)
 					return
 				}
 
@@ -150,12 +150,12 @@ func (okFuture *OKEx) parseTrade(data []interface{}) []Trade {
 func (okFuture *OKEx) parseDepth(tickmap map[string]interface{}) *Depth {
 	asks, ok := tickmap["asks"].([]interface{})
 	if !ok {
-		log.Printf("OKEx.parseDepth bad data %+v", tickmap)
+		okFuture.log().Warn("parse depth failed", "data", tickmap)
 		return nil
 	}
 	bids, ok := tickmap["bids"].([]interface{})
 	if !ok {
-		log.Printf("OKEx.parseDepth bad data %+v", tickmap)
+		okFuture.log().Warn("parse depth failed", "data", tickmap)
 		return nil
 	}
 
@@ -8,7 +8,6 @@ import (
 	"github.com/stephenlyu/GoEx"
 	"time"
 	"math"
-	"fmt"
 )
 
 type OKExQuoter struct {
@@ -18,6 +17,7 @@ type OKExQuoter struct {
 	firstTrade bool
 
 	callback quoter.QuoterCallback
+	logger   goex.Logger
 }
 
 func newOKExQuoter() quoter.Quoter {
@@ -40,6 +40,15 @@ func (this *OKExQuoter) SetCallback(callback quoter.QuoterCallback) {
 	this.callback = callback
 }
 
+// 设置日志, 同时用于底层的OKEx实例, 为nil时使用goex.DefaultLogger
+func (this *OKExQuoter) SetLogger(logger goex.Logger) {
+	this.okex.SetLogger(logger)
+	if logger != nil {
+		logger = goex.NewRedactingLogger(logger)
+	}
+	this.logger = logger
+}
+
 func (this *OKExQuoter) Destroy() {
 	this.okex.CloseWs()
 }
@@ -133,7 +142,7 @@ func (this *OKExQuoter) onTrade(pair goex.CurrencyPair, contractType string, tra
 			sellVolume += t.Amount
 		} else {
 			side = entity.TICK_SIDE_UNKNOWN
-			fmt.Println("unknown", t.Type)
+			goex.ExchangeLogger(this.logger, goex.OKEX_FUTURE).Warn("unknown trade type", "pair", pair, "type", t.Type)
 		}
 
 		price = t.Price
@@ -4,7 +4,6 @@ import (
 	"github.com/stephenlyu/tds/entity"
 	"fmt"
 	"strings"
-	"github.com/z-ray/log"
 	"regexp"
 	"github.com/stephenlyu/GoEx"
 )
@@ -12,39 +11,30 @@ import (
 var CODE_PATTERN, _ = regexp.Compile("[0-9]+")
 var SUFFIX_PATTERN, _ = regexp.Compile("[QTN]?FUT[A-Z]+")
 
-func FromSecurity(security *entity.Security) string {
+func FromSecurity(security *entity.Security) (string, error) {
 	if security.IsIndex() {
-		return fmt.Sprintf("%s-USD", security.GetCategory())
+		return fmt.Sprintf("%s-USD", security.GetCategory()), nil
 	}
 
 	switch security.Code {
 	case "QFUT", "TFUT", "NFUT":
-		instrumentId, err := DEFAULT_INSTRUMENT_MANAGER.GetInstrumentId(security.String())
-		if err != nil {
-			panic(err)
-		}
-		return instrumentId
+		return DEFAULT_INSTRUMENT_MANAGER.GetInstrumentId(security.String())
 	case "FUT":
-		return fmt.Sprintf("%s-USD-SWAP", security.Category)
+		return fmt.Sprintf("%s-USD-SWAP", security.Category), nil
 	default:
 		if SUFFIX_PATTERN.Match([]byte(security.Code)) {
-			instrumentId, err := DEFAULT_INSTRUMENT_MANAGER.GetInstrumentId(security.String())
-			if err != nil {
-				panic(err)
-			}
-			return instrumentId
+			return DEFAULT_INSTRUMENT_MANAGER.GetInstrumentId(security.String())
 		}
 
 		if CODE_PATTERN.Match([]byte(security.Code)) {
-			return fmt.Sprintf("%s-USD-%s", security.Category, security.GetCode())
+			return fmt.Sprintf("%s-USD-%s", security.Category, security.GetCode()), nil
 		}
 
-		panic("Unknown contract type")
+		return "", fmt.Errorf("Unknown contract type %s", security.String())
 	}
-	return ""
 }
 
-func ToSecurity(instrumentId string) *entity.Security {
+func ToSecurity(instrumentId string) (*entity.Security, error) {
 	var code string
 	if strings.HasSuffix(instrumentId, "SWAP") {
 		parts := strings.Split(instrumentId, "-")
@@ -56,13 +46,13 @@ func ToSecurity(instrumentId string) *entity.Security {
 		var err error
 		code, err = DEFAULT_INSTRUMENT_MANAGER.GetCode(instrumentId)
 		if err != nil {
-			log.Printf("error: %+v", err)
-			panic(err)
+			return nil, err
 		}
 	}
-	return entity.ParseSecurityUnsafe(code)
+	return entity.ParseSecurityUnsafe(code), nil
 }
 
+// Deprecated: 使用OKExV3.SymbolMapper().FromNative
 func InstrumentId2CurrencyPair(instrumentId string) goex.CurrencyPair {
 	parts := strings.Split(instrumentId, "-")
 	return goex.CurrencyPair{
@@ -3,188 +3,103 @@ package okexadapterv3
 import (
 	"github.com/stephenlyu/GoEx/okcoin"
 	"net/http"
-	"github.com/stephenlyu/tds/util"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 	"github.com/stephenlyu/tds/entity"
-	"log"
+	"github.com/stephenlyu/GoEx"
 )
 
 //
-// 负责将Security映射为对应的InstumentId
+// 负责将Security映射为对应的InstumentId. 合约由FuturesResolver按到期时间解析,
+// 交割换月时通知SubscribeRoll注册的回调
 //
 
-const WEEK_MILLS = 7 * 24 * 3600 * 1000
+const CHECK_INTERVAL = time.Minute
 
-type InstrumentManager struct {
-	api *okcoin.OKExV3
+// 代码前缀 -> 合约类型, 如EOSQFUT.OKEX为EOS-USD季度合约, EOSQFUTUSDT.OKEX为EOS-USDT季度合约
+var codeContractTypes = map[string]string{
+	"QFUT": goex.QUARTER_CONTRACT,
+	"TFUT": goex.THIS_WEEK_CONTRACT,
+	"NFUT": goex.NEXT_WEEK_CONTRACT,
+}
 
-	lock sync.RWMutex
-	nextSyncTimestamp uint64
-	codeInstrumentIdMap map[string]string
-	instrumentIdCodeMap map[string]string
+type InstrumentManager struct {
+	resolver  *goex.FuturesResolver
+	startOnce sync.Once
 }
 
 func NewInstrumentManager() *InstrumentManager {
+	api := okcoin.NewOKExV3(http.DefaultClient, "", "", "")
 	return &InstrumentManager{
-		api: okcoin.NewOKExV3(http.DefaultClient, "", "", ""),
-		codeInstrumentIdMap: make(map[string]string),
+		resolver: goex.NewFuturesResolver(goex.OKEX_FUTURE, goex.NewInstrumentCatalog(goex.OKEX_FUTURE, goex.InstrumentAPIFunc(api.GetUnifiedInstruments))),
 	}
 }
 
-func (this *InstrumentManager) getNextSyncTimestamp() uint64 {
-	this.lock.RLock()
-	defer this.lock.RUnlock()
-	return this.nextSyncTimestamp
+// 第一次使用时启动后台检查
+func (this *InstrumentManager) start() {
+	this.startOnce.Do(func() {
+		this.resolver.Start(CHECK_INTERVAL)
+	})
 }
 
-func (this *InstrumentManager) ensureMap() error {
-	now := util.Tick()
-	if now < this.getNextSyncTimestamp() {
-		return nil
+func parseCode(code string) (goex.CurrencyPair, string, error) {
+	security := entity.ParseSecurityUnsafe(code)
+	c := security.GetCode()
+	if len(c) < 4 {
+		return goex.CurrencyPair{}, "", fmt.Errorf("%s is not a futures contract code", code)
 	}
-
-	log.Printf("InstrumentManager.ensureMap")
-
-	var err error
-	var instruments []okcoin.V3Instrument
-
-	for i := 0; i < 5; i++ {
-		instruments, err = this.api.GetInstruments()
-		if err == nil {
-			break
-		}
-		nSeconds := i * 5 + 1
-		log.Printf("InstrumentManager.ensureMap error: %+v sleep %d seconds", err, nSeconds)
-		time.Sleep(time.Duration(nSeconds)*time.Second)
-	}
-	if err != nil {
-		return err
-	}
-
-	// 建立Currency -> InstrumentIds映射
-	currencyInstruments := make(map[string][]okcoin.V3Instrument)
-	for _, ins := range instruments {
-		parts := strings.Split(ins.InstrumentId, "-")
-		currency := parts[0]
-		currencyInstruments[currency] = append(currencyInstruments[currency], ins)
-	}
-
-	m := make(map[string]string)
-
-	var missingCode bool
-	for currency, l := range currencyInstruments {
-		var instruments []okcoin.V3Instrument
-		for _, ins := range l {
-			switch ins.Alias {
-			default:
-				break
-			case "quarter", "this_week", "next_week":
-				instruments = append(instruments, ins)
-			}
-		}
-
-		if len(instruments) != 3 && len(instruments) != 6 {
-			missingCode = true
-		}
-
-		for _, ins := range instruments {
-			var suffix string
-			if strings.Contains(ins.InstrumentId, "USDT") {
-				suffix = "USDT"
-			}
-			var code string
-			switch ins.Alias {
-			case "quarter":
-				code = fmt.Sprintf("%sQFUT%s.OKEX", currency, suffix)
-			case "this_week":
-				code = fmt.Sprintf("%sTFUT%s.OKEX", currency, suffix)
-			case "next_week":
-				code = fmt.Sprintf("%sNFUT%s.OKEX", currency, suffix)
-			}
-			if code != "" {
-				m[code] = ins.InstrumentId
-			}
-		}
-	}
-	this.lock.Lock()
-	var changed = false
-	for k, v := range m {
-		if this.codeInstrumentIdMap[k] != v {
-			this.codeInstrumentIdMap[k] = v
-			changed = true
-			break
-		}
+	contractType, ok := codeContractTypes[c[:4]]
+	if !ok {
+		return goex.CurrencyPair{}, "", fmt.Errorf("%s is not a futures contract code", code)
 	}
-	if changed {
-		this.codeInstrumentIdMap = m
-		this.instrumentIdCodeMap = make(map[string]string)
-		for c, i := range this.codeInstrumentIdMap {
-			this.instrumentIdCodeMap[i] = c
-		}
-		// 周合约交割后，会出现一个只有两个合约的阶段，这个阶段，需要持续更新，直到新的合约产生
-		if !missingCode {
-			this.nextSyncTimestamp = NextSyncTimestamp(util.Tick())
-		}
+	quote := goex.USD
+	if suffix := c[4:]; suffix != "" {
+		quote = goex.NewCurrency(suffix, "")
 	}
-	this.lock.Unlock()
-	return nil
+	return goex.NewCurrencyPair(goex.NewCurrency(security.GetCategory(), ""), quote), contractType, nil
 }
 
 func (this *InstrumentManager) GetInstrumentId(code string) (string, error) {
-	err := this.ensureMap()
+	pair, contractType, err := parseCode(code)
 	if err != nil {
 		return "", err
 	}
-
-	instrumentId, ok := this.codeInstrumentIdMap[code]
-	if !ok {
-		security := entity.ParseSecurityUnsafe(code)
-		code := security.GetCode()
-		var suffix string
-		if len(code) > 4 {
-			suffix = code[4:]
-		}
-		if strings.HasPrefix(code, "QFUT") {
-			instrumentId, ok = this.codeInstrumentIdMap[fmt.Sprintf("%sNFUT%s.OKEX", security.GetCategory(), suffix)]
-		} else if strings.HasPrefix(code, "NFUT") {
-			instrumentId, ok = this.codeInstrumentIdMap[fmt.Sprintf("%sTFUT%s.OKEX", security.GetCategory(), suffix)]
-		}
-	}
-	if !ok {
-		return "", fmt.Errorf("No instrument id for %s", code)
+	this.start()
+	inst, err := this.resolver.Watch(pair, contractType)
+	if err != nil {
+		return "", err
 	}
-	return instrumentId, nil
+	return inst.Symbol, nil
 }
 
 func (this *InstrumentManager) GetCode(instrumentId string) (string, error) {
-	err := this.ensureMap()
+	parts := strings.Split(instrumentId, "-")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("No code for %s", instrumentId)
+	}
+	contractType, err := this.resolver.ContractTypeOf(instrumentId)
 	if err != nil {
 		return "", err
 	}
-
-	code, ok := this.instrumentIdCodeMap[instrumentId]
-	if !ok {
-		return "", fmt.Errorf("No code for %s", instrumentId)
+	var suffix string
+	if parts[1] != "USD" {
+		suffix = parts[1]
+	}
+	for prefix, t := range codeContractTypes {
+		if t == contractType {
+			return fmt.Sprintf("%s%s%s.OKEX", parts[0], prefix, suffix), nil
+		}
 	}
-	return code, nil
+	return "", fmt.Errorf("No code for %s", instrumentId)
 }
 
-func NextSyncTimestamp(now uint64) uint64 {
-	const (
-		HOUR_MILLIS = 3600 * 1000
-		DAY_MILLIS = 24 * HOUR_MILLIS
-	)
-	now += 8 * HOUR_MILLIS		// 东八区+8
-
-	passedDayMillis := now % DAY_MILLIS
-	dayStartTs := now / DAY_MILLIS * DAY_MILLIS
-	if passedDayMillis > 16 * HOUR_MILLIS {
-		return dayStartTs + (24 + 16 - 8) * HOUR_MILLIS
-	}
-	return dayStartTs + (16 - 8) * HOUR_MILLIS + 5 * 1000	// 延迟5秒
+// 合约交割换月时回调, from为交割的合约, to为接替的合约
+func (this *InstrumentManager) SubscribeRoll(handle func(from, to string)) {
+	this.resolver.SubscribeRoll(func(roll goex.ContractRoll) {
+		handle(roll.From.Symbol, roll.To.Symbol)
+	})
 }
 
 var DEFAULT_INSTRUMENT_MANAGER = NewInstrumentManager()
@@ -4,26 +4,21 @@ import (
 	"testing"
 	"github.com/stephenlyu/tds/util"
 	"fmt"
-	"github.com/stephenlyu/tds/date"
+	"github.com/stephenlyu/GoEx"
 	"time"
 )
 
-func TestNextSyncTimestamp(t *testing.T) {
-	var ts uint64
-	ts = NextSyncTimestamp(util.Tick())
-	fmt.Println(date.Timestamp2SecondString(ts))
+func TestParseCode(t *testing.T) {
+	pair, contractType, err := parseCode("EOSQFUTUSDT.OKEX")
+	util.Assert(err == nil, "")
+	util.Assert(pair.ToSymbol("_") == "EOS_USDT" && contractType == goex.QUARTER_CONTRACT, "")
 
-	ts, _ = date.SecondString2Timestamp("20181206 14:00:00")
-	ts = NextSyncTimestamp(ts)
-	fmt.Println(date.Timestamp2SecondString(ts))
+	pair, contractType, err = parseCode("EOSTFUT.OKEX")
+	util.Assert(err == nil, "")
+	util.Assert(pair.ToSymbol("_") == "EOS_USD" && contractType == goex.THIS_WEEK_CONTRACT, "")
 
-	ts, _ = date.SecondString2Timestamp("20181206 16:00:00")
-	ts = NextSyncTimestamp(ts)
-	fmt.Println(date.Timestamp2SecondString(ts))
-
-	ts, _ = date.SecondString2Timestamp("20181206 16:00:01")
-	ts = NextSyncTimestamp(ts)
-	fmt.Println(date.Timestamp2SecondString(ts))
+	_, _, err = parseCode("EOS190628.OKEX")
+	util.Assert(err != nil, "")
 }
 
 func TestInstrumentManager_GetInstrumentId(t *testing.T) {
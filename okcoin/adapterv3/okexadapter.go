@@ -8,7 +8,7 @@ import (
 	"github.com/stephenlyu/GoEx"
 	"time"
 	"math"
-	"fmt"
+	"sync"
 )
 
 type OKExQuoter struct {
@@ -19,7 +19,10 @@ type OKExQuoter struct {
 
 	callback quoter.QuoterCallback
 
+	lock sync.Mutex
 	instrumentIdSecurityMap map[string]*entity.Security
+	destroyed bool
+	logger goex.Logger
 }
 
 func newOKExQuoter() quoter.Quoter {
@@ -36,44 +39,105 @@ func newOKExQuoter() quoter.Quoter {
 			}
 		}
 	})
+	DEFAULT_INSTRUMENT_MANAGER.SubscribeRoll(this.onRoll)
 
 	return this
 }
 
+func (this *OKExQuoter) onError(err error) {
+	if this.callback != nil {
+		this.callback.OnError(err)
+	}
+}
+
 func (this *OKExQuoter) Subscribe(security *entity.Security) {
-	this.tickMap[security.String()] = &entity.TickItem{Code: security.String()}
+	instrumentId, err := FromSecurity(security)
+	if err != nil {
+		this.onError(err)
+		return
+	}
 
-	instrumentId := FromSecurity(security)
+	this.lock.Lock()
+	this.tickMap[security.String()] = &entity.TickItem{Code: security.String()}
 	this.instrumentIdSecurityMap[instrumentId] = security
+	this.lock.Unlock()
+
 	if security.IsIndex() {
-		this.okex.GetIndexTickerWithWs(instrumentId, this.onTicker)
+		err = this.okex.GetIndexTickerWithWs(instrumentId, this.onTicker)
 	} else {
-		this.okex.GetDepthWithWs(instrumentId, this.onDepth)
-		this.okex.GetTradeWithWs(instrumentId, this.onTrade)
+		err = this.subscribe(instrumentId)
+	}
+	if err != nil {
+		this.onError(err)
 	}
 }
 
+func (this *OKExQuoter) subscribe(instrumentId string) error {
+	if err := this.okex.GetDepthWithWs(instrumentId, this.onDepth); err != nil {
+		return err
+	}
+	return this.okex.GetTradeWithWs(instrumentId, this.onTrade)
+}
+
 func (this *OKExQuoter) SetCallback(callback quoter.QuoterCallback) {
 	this.callback = callback
 }
 
+// 设置日志, 同时用于底层的OKEx实例, 为nil时使用goex.DefaultLogger
+func (this *OKExQuoter) SetLogger(logger goex.Logger) {
+	this.okex.SetLogger(logger)
+	if logger != nil {
+		logger = goex.NewRedactingLogger(logger)
+	}
+	this.logger = logger
+}
+
 func (this *OKExQuoter) Destroy() {
+	this.lock.Lock()
+	this.destroyed = true
+	this.lock.Unlock()
 	this.okex.CloseWs()
 }
 
-func (this *OKExQuoter) checkInstrumentCodeChanged(instrumentId string) {
-	security, _ := this.instrumentIdSecurityMap[instrumentId]
-	newInstrumentId := FromSecurity(security)
-	if instrumentId != newInstrumentId {
-		panic("Quit for delivery")
+// 合约交割后取消旧合约的订阅, 订阅接替的合约
+func (this *OKExQuoter) onRoll(from, to string) {
+	this.lock.Lock()
+	security, ok := this.instrumentIdSecurityMap[from]
+	if !ok || this.destroyed {
+		this.lock.Unlock()
+		return
+	}
+	delete(this.instrumentIdSecurityMap, from)
+	this.instrumentIdSecurityMap[to] = security
+	this.lock.Unlock()
+
+	if err := this.okex.UnsubscribeDepthWithWs(from); err != nil {
+		this.onError(err)
+	}
+	if err := this.okex.UnsubscribeTradeWithWs(from); err != nil {
+		this.onError(err)
+	}
+	if err := this.subscribe(to); err != nil {
+		this.onError(err)
 	}
 }
 
-func (this *OKExQuoter) onDepth(depth *goex.Depth) {
-	this.checkInstrumentCodeChanged(depth.InstrumentId)
-	security, _ := this.instrumentIdSecurityMap[depth.InstrumentId]
+// 已换月的旧合约返回false
+func (this *OKExQuoter) lookup(instrumentId string) (*entity.Security, *entity.TickItem, bool) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	security, ok := this.instrumentIdSecurityMap[instrumentId]
+	if !ok {
+		return nil, nil, false
+	}
+	return security, this.tickMap[security.String()], true
+}
 
-	thisTick := this.tickMap[security.String()]
+func (this *OKExQuoter) onDepth(depth *goex.Depth) {
+	_, thisTick, ok := this.lookup(depth.InstrumentId)
+	if !ok {
+		return
+	}
 	thisTick.Timestamp = uint64(depth.UTime.UnixNano() / int64(time.Millisecond))
 
 	askLen, bidLen := 20, 20
@@ -127,10 +191,10 @@ func (this *OKExQuoter) onTrade(instrumentId string, trades []goex.Trade) {
 	if len(trades) == 0 {
 		return
 	}
-	this.checkInstrumentCodeChanged(instrumentId)
-
-	security, _ := this.instrumentIdSecurityMap[instrumentId]
-	thisTick := this.tickMap[security.String()]
+	security, thisTick, ok := this.lookup(instrumentId)
+	if !ok {
+		return
+	}
 
 	open, high, low, amount, volume, side, buyVolume, sellVolume := thisTick.Open, thisTick.High, thisTick.Low, thisTick.Amount, thisTick.Volume, thisTick.Side, thisTick.BuyVolume, thisTick.SellVolume
 	var price float64
@@ -170,7 +234,7 @@ func (this *OKExQuoter) onTrade(instrumentId string, trades []goex.Trade) {
 			sellVolume += t.Amount
 		} else {
 			side = entity.TICK_SIDE_UNKNOWN
-			fmt.Println("unknown", t.Type)
+			goex.ExchangeLogger(this.logger, goex.OKEX_FUTURE).Warn("unknown trade type", "instrument_id", instrumentId, "type", t.Type)
 		}
 
 		price = t.Price
@@ -180,7 +244,7 @@ func (this *OKExQuoter) onTrade(instrumentId string, trades []goex.Trade) {
 }
 
 func (this *OKExQuoter) onTicker(instrumentId string, tickers []goex.Ticker) {
-	security, ok := this.instrumentIdSecurityMap[instrumentId]
+	security, _, ok := this.lookup(instrumentId)
 	if !ok {
 		return
 	}
@@ -188,7 +252,6 @@ func (this *OKExQuoter) onTicker(instrumentId string, tickers []goex.Ticker) {
 	if len(tickers) == 0 {
 		return
 	}
-	this.checkInstrumentCodeChanged(instrumentId)
 
 	ticker := tickers[0]
 
@@ -40,7 +40,9 @@ func TestOKExQuoter_SubscribeIndex(t *testing.T) {
 
 func TestFromSecurity(t *testing.T) {
 	security := entity.ParseSecurityUnsafe("EOSINDEX.OKEX")
-	instrumentId := FromSecurity(security)
-	security1 := ToSecurity(instrumentId)
+	instrumentId, err := FromSecurity(security)
+	util.Assert(err == nil, "")
+	security1, err := ToSecurity(instrumentId)
+	util.Assert(err == nil, "")
 	util.Assert(security.String() == security1.String(), "")
 }
\ No newline at end of file
@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
 	. "github.com/stephenlyu/GoEx"
+	"github.com/stephenlyu/GoEx/okcoin"
 	"github.com/stephenlyu/tds/util"
 )
 
@@ -19,9 +22,11 @@ const (
 	SPOT_V3_API_BASE_URL              = "https://www.okex.com"
 	SPOT_V3_INSTRUMENTS               = "/api/spot/v3/instruments"
 	SPOT_V3_TRADES                    = "/api/spot/v3/instruments/%s/trades"
+	SPOT_V3_CANDLES                   = "/api/spot/v3/instruments/%s/candles?"
 	SPOT_V3_ACCOUNTS                  = "/api/spot/v3/accounts"
 	SPOT_V3_CURRENCY_ACCOUNTS         = "/api/spot/v3/accounts/%s"
 	SPOT_V3_INSTRUMENT_TICKER         = "/api/spot/v3/instruments/%s/ticker"
+	SPOT_V3_TICKERS                   = "/api/spot/v3/instruments/ticker"
 	SPOT_V3_ORDERS                    = "/api/spot/v3/orders"
 	SPOT_V3_BATCH_ORDERS              = "/api/spot/v3/batch_orders"
 	SPOT_V3_CANCEL_ORDERS             = "/api/spot/v3/cancel_batch_orders"
@@ -29,6 +34,7 @@ const (
 	SPOT_V3_INSTRUMENT_ORDERS         = "/api/spot/v3/orders?instrument_id=%s"
 	SPOT_V3_INSTRUMENT_ORDERS_PENDING = "/api/spot/v3/orders_pending?instrument_id=%s"
 	SPOT_V3_ORDER_INFO                = "/api/spot/v3/orders/%s?instrument_id=%s"
+	SPOT_V3_FILLS                     = "/api/spot/v3/fills?instrument_id=%s"
 )
 
 const (
@@ -51,28 +57,29 @@ type V3Instrument struct {
 	TickSize      decimal.Decimal `json:"tick_size"`
 }
 
+func (this *V3Instrument) ToInstrument() Instrument {
+	inst := Instrument{
+		Exchange: OKEX,
+		Symbol:   this.InstrumentId,
+		Pair:     NewCurrencyPair(NewCurrency(this.BaseCurrency, ""), NewCurrency(this.QuoteCurrency, "")),
+		Type:     INSTRUMENT_SPOT,
+		TickSize: this.TickSize,
+		LotSize:  this.SizeIncrement,
+		MinQty:   this.MinSize,
+		Status:   INSTRUMENT_STATUS_TRADING,
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 func V3ParseDate(s string) int64 {
 	t, _ := time.ParseInLocation(V3_DATE_FORMAT, s, time.UTC)
 	return t.UnixNano() / int64(time.Millisecond)
 }
 
-func InstrumentId2CurrencyPair(instrumentId string) CurrencyPair {
-	parts := strings.Split(instrumentId, "-")
-	return CurrencyPair{
-		Currency{Symbol: parts[0]},
-		Currency{Symbol: parts[1]},
-	}
-}
-
-func CurrencyPair2InstrumentId(pair CurrencyPair) string {
-	return fmt.Sprintf("%s-%s", pair.CurrencyA.Symbol, pair.CurrencyB.Symbol)
-}
-
 type OKExV3Spot struct {
-	apiKey,
-	apiSecretKey string
-	passphrase string
-	client     *http.Client
+	signer *SignerRef
+	client *http.Client
 
 	ws                 *WsConn
 	createWsLock       sync.Mutex
@@ -83,29 +90,61 @@ type OKExV3Spot struct {
 	wsOrderHandleMap   map[string]func([]OrderDecimal)
 	depthManagers      map[string]*DepthManager
 	errorHandle        func(error)
+	logger             Logger
+	symbols            *SymbolMapper
 }
 
 func NewOKExV3Spot(client *http.Client, api_key, secret_key, passphrase string) *OKExV3Spot {
+	return NewOKExV3SpotWithSigner(client, NewStaticSigner(api_key, secret_key, passphrase))
+}
+
+/**
+ * 使用自定义签名器创建实例, 密钥可以来自环境变量、加密文件或凭证代理
+ */
+func NewOKExV3SpotWithSigner(client *http.Client, signer Signer) *OKExV3Spot {
 	ok := new(OKExV3Spot)
-	ok.apiKey = api_key
-	ok.apiSecretKey = secret_key
-	ok.passphrase = passphrase
+	ok.signer = NewSignerRef(signer)
 	ok.client = client
+	ok.symbols = NewSymbolMapper(OKEX, NewInstrumentCatalog(OKEX, InstrumentAPIFunc(ok.GetUnifiedInstruments)))
+	SetServerClockHttpClient(OKEX, client)
 	return ok
 }
 
-func (ok *OKExV3Spot) buildHeader(method, requestPath, body string) map[string]string {
-	now := time.Now().In(time.UTC)
+/**
+ * 替换签名器, 用于运行时轮换密钥. websocket需要重新登录才能使用新的密钥
+ */
+func (ok *OKExV3Spot) SetSigner(signer Signer) {
+	ok.signer.Set(signer)
+}
+
+/**
+ * 设置实例的日志, 为nil时使用DefaultLogger
+ */
+func (ok *OKExV3Spot) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	ok.logger = logger
+}
+
+func (ok *OKExV3Spot) log() Logger {
+	return ExchangeLogger(ok.logger, OKEX)
+}
+
+func (ok *OKExV3Spot) buildHeader(method, requestPath, body string) (map[string]string, error) {
+	now := ServerTime(OKEX).In(time.UTC)
 	timestamp := now.Format(V3_DATE_FORMAT)
-	message := timestamp + method + requestPath + body
-	signature, _ := GetParamHmacSHA256Base64Sign(ok.apiSecretKey, message)
+	signature, err := ok.signer.Sign(HMAC_SHA256_BASE64, SignMessage(timestamp+method+requestPath+body))
+	if err != nil {
+		return nil, err
+	}
 	return map[string]string{
-		"OK-ACCESS-KEY":        ok.apiKey,
-		"OK-ACCESS-SIGN":       signature,
+		"OK-ACCESS-KEY":        signature.ApiKey,
+		"OK-ACCESS-SIGN":       signature.Sign,
 		"OK-ACCESS-TIMESTAMP":  timestamp,
-		"OK-ACCESS-PASSPHRASE": ok.passphrase,
+		"OK-ACCESS-PASSPHRASE": signature.Passphrase,
 		"Content-Type":         "application/json",
-	}
+	}, nil
 }
 
 func (ok *OKExV3Spot) GetInstruments() ([]V3Instrument, error) {
@@ -126,6 +165,36 @@ func (ok *OKExV3Spot) GetInstruments() ([]V3Instrument, error) {
 	return instruments, err
 }
 
+/**
+ * 统一格式的交易品种, 用于InstrumentCatalog: NewInstrumentCatalog(exchange, InstrumentAPIFunc(ok.GetUnifiedInstruments))
+ */
+func (ok *OKExV3Spot) GetUnifiedInstruments() ([]Instrument, error) {
+	instruments, err := ok.GetInstruments()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(instruments))
+	for i := range instruments {
+		ret[i] = instruments[i].ToInstrument()
+	}
+	return ret, nil
+}
+
+func (ok *OKExV3Spot) SymbolMapper() *SymbolMapper {
+	return ok.symbols
+}
+
+/**
+ * 交易对代码对应的交易对, 从交易品种列表中查找
+ */
+func (ok *OKExV3Spot) instrumentPair(instrumentId string) (CurrencyPair, error) {
+	inst, err := ok.symbols.FromNative(instrumentId)
+	if err != nil {
+		return UNKNOWN_PAIR, err
+	}
+	return inst.Pair, nil
+}
+
 func (ok *OKExV3Spot) GetTrades(instrumentId string) ([]TradeDecimal, error) {
 	resp, err := ok.client.Get(SPOT_V3_API_BASE_URL + fmt.Sprintf(SPOT_V3_TRADES, instrumentId))
 	if err != nil {
@@ -162,6 +231,70 @@ func (ok *OKExV3Spot) GetTrades(instrumentId string) ([]TradeDecimal, error) {
 	return ret, err
 }
 
+var klineGranularities = map[int]string{
+	KLINE_PERIOD_1MIN:  "60",
+	KLINE_PERIOD_5MIN:  "300",
+	KLINE_PERIOD_15MIN: "900",
+	KLINE_PERIOD_30MIN: "1800",
+	KLINE_PERIOD_60MIN: "3600",
+	KLINE_PERIOD_4H:    "14400",
+	KLINE_PERIOD_1DAY:  "86400",
+	KLINE_PERIOD_1WEEK: "604800",
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线
+ */
+func (ok *OKExV3Spot) GetKlineRecordsDecimal(currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	granularity, err := KlinePeriodCode(klineGranularities, period)
+	if err != nil {
+		return nil, err
+	}
+	periodMs := int64(KlinePeriodDuration(period) / time.Millisecond)
+	instrumentId, err := ok.symbols.ToNative(currency, INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, err
+	}
+	formatTime := func(ms int64) string {
+		return time.Unix(0, ms*int64(time.Millisecond)).UTC().Format(V3_DATE_FORMAT)
+	}
+
+	return GetAllKlineDecimals(int64(since), size, 200, func(q PageQuery) ([]KlineDecimal, error) {
+		params := url.Values{}
+		params.Set("granularity", granularity)
+		if q.From > 0 {
+			params.Set("start", formatTime(q.From))
+			params.Set("end", formatTime(q.From+int64(q.Limit)*periodMs))
+		} else if q.To > 0 {
+			params.Set("end", formatTime(q.To))
+		}
+
+		var resp [][]string
+		err := HttpGet4(ok.client, SPOT_V3_API_BASE_URL+fmt.Sprintf(SPOT_V3_CANDLES, instrumentId)+params.Encode(), nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		// [time, open, high, low, close, volume]
+		klines := make([]KlineDecimal, 0, len(resp))
+		for _, r := range resp {
+			if len(r) < 6 {
+				continue
+			}
+			klines = append(klines, KlineDecimal{
+				Pair:      currency,
+				Timestamp: V3ParseDate(r[0]) / 1000,
+				Open:      ToDecimal(r[1]),
+				High:      ToDecimal(r[2]),
+				Low:       ToDecimal(r[3]),
+				Close:     ToDecimal(r[4]),
+				Vol:       ToDecimal(r[5]),
+			})
+		}
+		return klines, nil
+	})
+}
+
 func (ok *OKExV3Spot) GetInstrumentTicker(instrumentId string) (*TickerDecimal, error) {
 	url := SPOT_V3_API_BASE_URL + SPOT_V3_INSTRUMENT_TICKER
 	resp, err := ok.client.Get(fmt.Sprintf(url, instrumentId))
@@ -197,6 +330,54 @@ func (ok *OKExV3Spot) GetInstrumentTicker(instrumentId string) (*TickerDecimal,
 	return ticker, nil
 }
 
+/**
+ * 所有币对的24小时行情
+ */
+func (ok *OKExV3Spot) GetAllTickers() (map[CurrencyPair]*TickerDecimal, error) {
+	var resp []struct {
+		InstrumentId   string          `json:"instrument_id"`
+		Last           decimal.Decimal `json:"last"`
+		BestBid        decimal.Decimal `json:"best_bid"`
+		BestAsk        decimal.Decimal `json:"best_ask"`
+		Open24h        decimal.Decimal `json:"open_24h"`
+		High24h        decimal.Decimal `json:"high_24h"`
+		Low24h         decimal.Decimal `json:"low_24h"`
+		BaseVolume24h  decimal.Decimal `json:"base_volume_24h"`
+		QuoteVolume24h decimal.Decimal `json:"quote_volume_24h"`
+		Timestamp      string          `json:"timestamp"`
+	}
+	err := HttpGet4(ok.client, SPOT_V3_API_BASE_URL+SPOT_V3_TICKERS, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[CurrencyPair]*TickerDecimal, len(resp))
+	for _, r := range resp {
+		pair, err := ok.instrumentPair(r.InstrumentId)
+		if errors.Is(err, ErrInstrumentNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ticker := &TickerDecimal{
+			Pair:     pair,
+			Last:     r.Last,
+			Buy:      r.BestBid,
+			Sell:     r.BestAsk,
+			Open:     r.Open24h,
+			High:     r.High24h,
+			Low:      r.Low24h,
+			Vol:      r.BaseVolume24h,
+			QuoteVol: r.QuoteVolume24h,
+			Date:     uint64(V3ParseDate(r.Timestamp) / 1000),
+		}
+		ticker.FillChange()
+		ret[pair] = ticker
+	}
+	return ret, nil
+}
+
 type V3CurrencyInfo struct {
 	Currency  string
 	Balance   decimal.Decimal `json:"balance"`
@@ -217,8 +398,11 @@ func (this *V3CurrencyInfo) ToSubAccount() *SubAccountDecimal {
 
 func (ok *OKExV3Spot) GetAccount() (*AccountDecimal, error) {
 	var resp []V3CurrencyInfo
-	header := ok.buildHeader("GET", SPOT_V3_ACCOUNTS, "")
-	err := HttpGet4(ok.client, SPOT_V3_API_BASE_URL+SPOT_V3_ACCOUNTS, header, &resp)
+	header, err := ok.buildHeader("GET", SPOT_V3_ACCOUNTS, "")
+	if err != nil {
+		return nil, err
+	}
+	err = HttpGet4(ok.client, SPOT_V3_API_BASE_URL+SPOT_V3_ACCOUNTS, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -238,8 +422,11 @@ func (ok *OKExV3Spot) GetAccount() (*AccountDecimal, error) {
 func (ok *OKExV3Spot) GetCurrencyAccount(currency Currency) (*SubAccountDecimal, error) {
 	var resp *V3CurrencyInfo
 	reqUrl := fmt.Sprintf(SPOT_V3_CURRENCY_ACCOUNTS, currency)
-	header := ok.buildHeader("GET", reqUrl, "")
-	err := HttpGet4(ok.client, SPOT_V3_API_BASE_URL+reqUrl, header, &resp)
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
+	err = HttpGet4(ok.client, SPOT_V3_API_BASE_URL+reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -292,7 +479,10 @@ func (ok *OKExV3Spot) PlaceOrder(req OrderReq) (string, error) {
 	bytes, _ := json.Marshal(req.ToParam())
 	data := string(bytes)
 
-	header := ok.buildHeader("POST", SPOT_V3_ORDERS, data)
+	header, err := ok.buildHeader("POST", SPOT_V3_ORDERS, data)
+	if err != nil {
+		return "", err
+	}
 
 	placeOrderUrl := SPOT_V3_API_BASE_URL + SPOT_V3_ORDERS
 	body, err := HttpPostJson(ok.client, placeOrderUrl, data, header)
@@ -300,7 +490,7 @@ func (ok *OKExV3Spot) PlaceOrder(req OrderReq) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	println(string(body))
+	ok.log().Debug("place order response", "body", string(body))
 	var ret *struct {
 		OrderId      string `json:"order_id"`
 		ClientOid    string `json:"client_oid"`
@@ -314,7 +504,7 @@ func (ok *OKExV3Spot) PlaceOrder(req OrderReq) (string, error) {
 	}
 
 	if ret.ErrorCode != "" && ret.ErrorCode != "0" {
-		return "", fmt.Errorf("error code: %s", ret.ErrorCode)
+		return "", okcoin.OKExV3Error(ret.ErrorCode, ret.ErrorMessage)
 	}
 
 	return ret.OrderId, nil
@@ -335,23 +525,27 @@ func (ok *OKExV3Spot) CancelOrder(instrumentId, orderId, clientOid string) error
 	bytes, _ := json.Marshal(param)
 	data := string(bytes)
 
-	header := ok.buildHeader("POST", reqUrl, data)
+	header, err := ok.buildHeader("POST", reqUrl, data)
+	if err != nil {
+		return err
+	}
 
 	reqPath := SPOT_V3_API_BASE_URL + reqUrl
 	body, err := HttpPostJson(ok.client, reqPath, data, header)
 	if err != nil {
-		if strings.Contains(err.Error(), "33014") {
+		if errors.Is(err, ERR_ORDER_NOT_FOUND) {
 			return nil
 		}
 		return err
 	}
-	println(string(body))
+	ok.log().Debug("cancel order response", "body", string(body))
 	respMap := make(map[string]interface{})
 	err = json.Unmarshal(body, &respMap)
 
 	if respMap["result"] != nil && !respMap["result"].(bool) {
 		if respMap["error_code"] != nil {
-			return fmt.Errorf("error code: %s", respMap["error_code"].(string))
+			message, _ := respMap["error_message"].(string)
+			return okcoin.OKExV3Error(respMap["error_code"], message)
 		}
 		return errors.New(string(body))
 	}
@@ -383,7 +577,10 @@ func (ok *OKExV3Spot) PlaceOrders(req []OrderReq) ([]BatchPlaceOrderRespItem, er
 
 	bytes, _ := json.Marshal(param)
 	data := string(bytes)
-	header := ok.buildHeader("POST", SPOT_V3_BATCH_ORDERS, data)
+	header, err := ok.buildHeader("POST", SPOT_V3_BATCH_ORDERS, data)
+	if err != nil {
+		return nil, err
+	}
 
 	placeOrderUrl := SPOT_V3_API_BASE_URL + SPOT_V3_BATCH_ORDERS
 	body, err := HttpPostJson(ok.client, placeOrderUrl, data, header)
@@ -423,17 +620,19 @@ func (ok *OKExV3Spot) CancelOrders(instrumentId string, orderIds []string, clien
 	bytes, _ := json.Marshal([]interface{}{param})
 	reqUrl := SPOT_V3_CANCEL_ORDERS
 
-	header := ok.buildHeader("POST", reqUrl, string(bytes))
-	println(string(bytes))
+	header, err := ok.buildHeader("POST", reqUrl, string(bytes))
+	if err != nil {
+		return err
+	}
 	reqPath := SPOT_V3_API_BASE_URL + reqUrl
 	body, err := HttpPostJson(ok.client, reqPath, string(bytes), header)
 	if err != nil {
-		if strings.Contains(err.Error(), "33027") || strings.Contains(err.Error(), "33014") {
+		if errors.Is(err, ERR_ORDER_NOT_FOUND) || strings.Contains(err.Error(), "33027") {
 			return nil
 		}
 		return err
 	}
-	println(string(body))
+	ok.log().Debug("cancel orders response", "body", string(body))
 	var resp map[string][]struct {
 		Result    bool   `json:"result"`
 		OrderId   string `json:"order_id"`
@@ -463,7 +662,7 @@ type V3OrderInfo struct {
 	OrderType      string `json:"order_type"`
 }
 
-func (this *V3OrderInfo) ToOrder() *OrderDecimal {
+func (this *V3OrderInfo) ToOrder(pair CurrencyPair) *OrderDecimal {
 	if this.OrderId == "" {
 		return nil
 	}
@@ -513,7 +712,7 @@ func (this *V3OrderInfo) ToOrder() *OrderDecimal {
 	case ORDER_STATUS_FAILURE:
 		o.Status = ORDER_REJECT
 	}
-	o.Currency = InstrumentId2CurrencyPair(this.InstrumentId)
+	o.Currency = pair
 	return o
 }
 
@@ -547,23 +746,50 @@ func (ok *OKExV3Spot) GetInstrumentOrders(instrumentId string, status, from, to,
 		reqUrl += "&" + strings.Join(params, "&")
 	}
 
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp []V3OrderInfo
 
-	err := HttpGet4(ok.client, SPOT_V3_API_BASE_URL+reqUrl, header, &resp)
+	err = HttpGet4(ok.client, SPOT_V3_API_BASE_URL+reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
 
+	pair, err := ok.instrumentPair(instrumentId)
+	if err != nil {
+		return nil, err
+	}
 	ret := make([]OrderDecimal, len(resp))
 	for i, o := range resp {
-		ret[i] = *o.ToOrder()
+		ret[i] = *o.ToOrder(pair)
 	}
 
 	return ret, nil
 }
 
+/**
+ * 从最新的订单开始向前翻页, 获取全部订单. 以上一页最早的订单ID作为to参数
+ */
+func (ok *OKExV3Spot) GetAllInstrumentOrders(instrumentId string, status string, limit int) ([]OrderDecimal, error) {
+	return GetAllOrderDecimals(PAGING_BY_CURSOR, limit, 0, 0, func(q PageQuery) ([]OrderDecimal, string, error) {
+		orders, err := ok.GetInstrumentOrders(instrumentId, status, "", q.Cursor, strconv.Itoa(q.Limit))
+		if err != nil || len(orders) == 0 {
+			return orders, "", err
+		}
+		var next decimal.Decimal
+		for i, o := range orders {
+			id, _ := decimal.NewFromString(o.OrderID2)
+			if i == 0 || id.LessThan(next) {
+				next = id
+			}
+		}
+		return orders, next.String(), nil
+	})
+}
+
 func (ok *OKExV3Spot) GetInstrumentPendingOrders(instrumentId string, from, to, limit string) ([]OrderDecimal, error) {
 	reqUrl := fmt.Sprintf(SPOT_V3_INSTRUMENT_ORDERS_PENDING, instrumentId)
 	var params []string
@@ -580,18 +806,25 @@ func (ok *OKExV3Spot) GetInstrumentPendingOrders(instrumentId string, from, to,
 		reqUrl += "&" + strings.Join(params, "&")
 	}
 
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp []V3OrderInfo
 
-	err := HttpGet4(ok.client, SPOT_V3_API_BASE_URL+reqUrl, header, &resp)
+	err = HttpGet4(ok.client, SPOT_V3_API_BASE_URL+reqUrl, header, &resp)
 	if err != nil {
 		return nil, err
 	}
 
+	pair, err := ok.instrumentPair(instrumentId)
+	if err != nil {
+		return nil, err
+	}
 	ret := make([]OrderDecimal, len(resp))
 	for i, o := range resp {
-		ret[i] = *o.ToOrder()
+		ret[i] = *o.ToOrder(pair)
 	}
 
 	return ret, nil
@@ -599,13 +832,139 @@ func (ok *OKExV3Spot) GetInstrumentPendingOrders(instrumentId string, from, to,
 
 func (ok *OKExV3Spot) GetInstrumentOrder(instrumentId string, orderId string) (*OrderDecimal, error) {
 	reqUrl := fmt.Sprintf(SPOT_V3_ORDER_INFO, orderId, instrumentId)
-	header := ok.buildHeader("GET", reqUrl, "")
+	header, err := ok.buildHeader("GET", reqUrl, "")
+	if err != nil {
+		return nil, err
+	}
 
 	var resp *V3OrderInfo
 
-	err := HttpGet4(ok.client, SPOT_V3_API_BASE_URL+reqUrl, header, &resp)
+	err = HttpGet4(ok.client, SPOT_V3_API_BASE_URL+reqUrl, header, &resp)
+	if err != nil {
+		return nil, err
+	}
+	pair, err := ok.instrumentPair(instrumentId)
+	if err != nil {
+		return nil, err
+	}
+	return resp.ToOrder(pair), nil
+}
+
+type V3Fill struct {
+	LedgerId     string          `json:"ledger_id"`
+	TradeId      string          `json:"trade_id"`
+	InstrumentId string          `json:"instrument_id"`
+	Price        decimal.Decimal `json:"price"`
+	Size         decimal.Decimal `json:"size"`
+	OrderId      string          `json:"order_id"`
+	Timestamp    string          `json:"timestamp"`
+	ExecType     string          `json:"exec_type"`
+	Fee          decimal.Decimal `json:"fee"`
+	Side         string          `json:"side"`
+	Currency     string          `json:"currency"`
+}
+
+// 每笔成交返回基础币和计价币两条账单, 合并为一条FillDecimal, FillId使用基础币账单的ledger_id, 可直接用作翻页参数
+func V3FillsToFillDecimals(fills []V3Fill, pair CurrencyPair) []FillDecimal {
+	var ret []FillDecimal
+	index := make(map[string]int)
+	for _, o := range fills {
+		key := o.OrderId + ":" + o.TradeId
+		i, ok := index[key]
+		if !ok {
+			f := FillDecimal{
+				OrderId:         o.OrderId,
+				Currency:        pair,
+				Price:           o.Price,
+				TransactionTime: V3ParseDate(o.Timestamp),
+				IsMaker:         o.ExecType == "M",
+			}
+			ret = append(ret, f)
+			i = len(ret) - 1
+			index[key] = i
+		}
+
+		f := &ret[i]
+		if strings.EqualFold(o.Currency, f.Currency.CurrencyA.Symbol) {
+			f.FillId = o.LedgerId
+			f.Qty = o.Size
+			if o.Side == "buy" {
+				f.Side = BUY
+			} else {
+				f.Side = SELL
+			}
+		}
+		if !o.Fee.IsZero() {
+			f.Fee = o.Fee.Abs()
+			f.FeeCurrency = strings.ToUpper(o.Currency)
+		}
+	}
+	return ret
+}
+
+// 按成交ID游标查询一页成交记录, from/to为成交ID
+func (ok *OKExV3Spot) GetFillsByCursor(instrumentId string, from, to, limit string) ([]FillDecimal, error) {
+	reqUrl := fmt.Sprintf(SPOT_V3_FILLS, instrumentId)
+	var params []string
+	if from != "" {
+		params = append(params, "from="+from)
+	}
+	if to != "" {
+		params = append(params, "to="+to)
+	}
+	if limit != "" {
+		params = append(params, "limit="+limit)
+	}
+	if len(params) > 0 {
+		reqUrl += "&" + strings.Join(params, "&")
+	}
+
+	header, err := ok.buildHeader("GET", reqUrl, "")
 	if err != nil {
 		return nil, err
 	}
-	return resp.ToOrder(), nil
+
+	var resp []V3Fill
+
+	err = HttpGet4(ok.client, SPOT_V3_API_BASE_URL+reqUrl, header, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := ok.instrumentPair(instrumentId)
+	if err != nil {
+		return nil, err
+	}
+	return V3FillsToFillDecimals(resp, pair), nil
+}
+
+// 查询[from, to]内最新的最多limit条成交, from/to为毫秒时间戳, 0表示不限
+func (ok *OKExV3Spot) GetFills(pair CurrencyPair, from, to int64, limit int) ([]FillDecimal, error) {
+	return GetFillsByCursorInRange(from, to, limit, ok.fillsCursor(pair))
+}
+
+// 从最新的成交开始向前翻页, 获取[from, to]内全部成交记录
+func (ok *OKExV3Spot) GetAllFills(pair CurrencyPair, from, to int64, limit int) ([]FillDecimal, error) {
+	return GetAllFillsByCursorInRange(from, to, limit, ok.fillsCursor(pair))
+}
+
+func (ok *OKExV3Spot) fillsCursor(pair CurrencyPair) FillsCursorFunc {
+	return func(cursor string, limit int) ([]FillDecimal, string, error) {
+		instrumentId, err := ok.symbols.ToNative(pair, INSTRUMENT_SPOT)
+		if err != nil {
+			return nil, "", err
+		}
+		fills, err := ok.GetFillsByCursor(instrumentId, "", cursor, strconv.Itoa(limit))
+		if err != nil || len(fills) == 0 {
+			return fills, "", err
+		}
+		var next decimal.Decimal
+		for i, f := range fills {
+			id, _ := decimal.NewFromString(f.FillId)
+			if i == 0 || id.LessThan(next) {
+				next = id
+			}
+		}
+		return fills, next.String(), nil
+	}
 }
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	. "github.com/stephenlyu/GoEx"
-	"log"
 	"strings"
 	"time"
 	"compress/flate"
@@ -38,6 +37,7 @@ func (okSpot *OKExV3Spot) createWsConn() {
 
 			okSpot.ws = NewWsConn("wss://real.okex.com:8443/ws/v3")
 			okSpot.ws.SetErrorHandler(okSpot.errorHandle)
+			okSpot.ws.SetLogger(okSpot.logger)
 			okSpot.ws.Heartbeat(func() interface{} { return "ping"}, 20*time.Second)
 			okSpot.ws.ReConnect()
 			okSpot.ws.ReceiveMessageEx(func(isBin bool, msg []byte) {
@@ -61,7 +61,7 @@ func (okSpot *OKExV3Spot) createWsConn() {
 				}
 				err := json.Unmarshal(msg, &data)
 				if err != nil {
-					log.Print(err)
+					okSpot.log().Warn("decode websocket message failed", "err", err)
 					return
 				}
 
@@ -112,15 +112,17 @@ func (okSpot *OKExV3Spot) createWsConn() {
 }
 
 
-func (okSpot *OKExV3Spot) getLoginData() interface{} {
-	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
-	message := timestamp + "GET/users/self/verify"
-	sign, _ := GetParamHmacSHA256Base64Sign(okSpot.apiSecretKey, message)
+func (okSpot *OKExV3Spot) getLoginData() (interface{}, error) {
+	timestamp := strconv.FormatInt(ServerTime(OKEX).Unix(), 10)
+	signature, err := okSpot.signer.Sign(HMAC_SHA256_BASE64, SignMessage(timestamp + "GET/users/self/verify"))
+	if err != nil {
+		return nil, err
+	}
 
 	return map[string]interface{}{
 		"op":   "login",
-		"args": []interface{}{okSpot.apiKey, okSpot.passphrase, timestamp, sign},
-	}
+		"args": []interface{}{signature.ApiKey, signature.Passphrase, timestamp, signature.Sign},
+	}, nil
 }
 
 func (okSpot *OKExV3Spot) doLogin() error {
@@ -132,8 +134,11 @@ func (okSpot *OKExV3Spot) doLogin() error {
 
 	okSpot.wsLoginHandle = onDone
 
-	data := okSpot.getLoginData()
-	err := okSpot.ws.SendMessage(data)
+	data, err := okSpot.getLoginData()
+	if err != nil {
+		return err
+	}
+	err = okSpot.ws.SendMessage(data)
 	if err != nil {
 		return err
 	}
@@ -294,7 +299,11 @@ func (okSpot *OKExV3Spot) parseOrder(msg []byte) (string, []OrderDecimal) {
 
 	ret := make([]OrderDecimal, len(data.Data))
 	for i := range data.Data {
-		ret[i] = *data.Data[i].ToOrder()
+		pair, err := okSpot.instrumentPair(data.Data[i].InstrumentId)
+		if err != nil && okSpot.errorHandle != nil {
+			okSpot.errorHandle(err)
+		}
+		ret[i] = *data.Data[i].ToOrder(pair)
 	}
 
 	return instrumentId, ret
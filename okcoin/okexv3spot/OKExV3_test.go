@@ -13,6 +13,8 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+var _ goex.FillsAPI = (*OKExV3Spot)(nil)
+
 var (
 	okexV3 *OKExV3Spot
 )
@@ -55,6 +57,12 @@ func TestOKExV3_GetInstrumentTicker(t *testing.T) {
 	output(ret)
 }
 
+func TestOKExV3_GetAllTickers(t *testing.T) {
+	ret, err := okexV3.GetAllTickers()
+	assert.Nil(t, err)
+	output(ret[goex.ETH_USDT])
+}
+
 func TestOKExV3_GetTrades(t *testing.T) {
 	ret, err := okexV3.GetTrades("ETH-USDT")
 	assert.Nil(t, err)
@@ -136,6 +144,12 @@ func TestOKExV3_GetInstrumentOrders(t *testing.T) {
 	output(orders)
 }
 
+func TestOKExV3_GetAllInstrumentOrders(t *testing.T) {
+	orders, err := okexV3.GetAllInstrumentOrders("BTC-USDT", "6", 100)
+	assert.Nil(t, err)
+	output(orders)
+}
+
 func TestOKExV3_GetInstrumentPendingOrders(t *testing.T) {
 	orders, err := okexV3.GetInstrumentPendingOrders("USDT-USDK", "", "", "")
 	assert.Nil(t, err)
@@ -147,3 +161,9 @@ func TestOKExV3_GetInstrumentOrder(t *testing.T) {
 	assert.Nil(t, err)
 	output(order)
 }
+
+func TestOKExV3_GetFills(t *testing.T) {
+	fills, err := okexV3.GetFills(goex.BTC_USDT, 0, 0, 100)
+	assert.Nil(t, err)
+	output(fills)
+}
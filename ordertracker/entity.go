@@ -0,0 +1,116 @@
+package ordertracker
+
+import (
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// OrderState canonical order state
+type OrderState int
+
+const (
+	STATE_NEW = iota
+	STATE_PARTIALLY_FILLED
+	STATE_FILLED
+	STATE_CANCELED
+	STATE_REJECTED
+	STATE_CANCEL_PENDING
+)
+
+var orderStateSymbol = [...]string{"NEW", "PARTIALLY_FILLED", "FILLED", "CANCELED", "REJECTED", "CANCEL_PENDING"}
+
+func (s OrderState) String() string {
+	if s < 0 || int(s) >= len(orderStateSymbol) {
+		return "UNKNOWN"
+	}
+	return orderStateSymbol[s]
+}
+
+// IsFinal Whether no further updates are expected for the order
+func (s OrderState) IsFinal() bool {
+	return s == STATE_FILLED || s == STATE_CANCELED || s == STATE_REJECTED
+}
+
+// FromTradeStatus translate goex.TradeStatus to OrderState
+func FromTradeStatus(status goex.TradeStatus) OrderState {
+	switch status {
+	case goex.ORDER_PART_FINISH:
+		return STATE_PARTIALLY_FILLED
+	case goex.ORDER_FINISH:
+		return STATE_FILLED
+	case goex.ORDER_CANCEL:
+		return STATE_CANCELED
+	case goex.ORDER_REJECT:
+		return STATE_REJECTED
+	case goex.ORDER_CANCEL_ING:
+		return STATE_CANCEL_PENDING
+	default:
+		return STATE_NEW
+	}
+}
+
+// Source where an order update comes from
+type Source int
+
+const (
+	SOURCE_STREAM = iota
+	SOURCE_REST
+)
+
+func (s Source) String() string {
+	if s == SOURCE_REST {
+		return "REST"
+	}
+	return "STREAM"
+}
+
+// Fill A fill delta derived from two consecutive order snapshots
+type Fill struct {
+	OrderID2  string
+	ClientOid string
+	Currency  goex.CurrencyPair
+	Side      goex.TradeSide
+	Qty       decimal.Decimal // Filled quantity of this delta
+	Price     decimal.Decimal // Average price of this delta, zero if unknown
+	CumQty    decimal.Decimal // Total filled quantity after this delta
+	Timestamp int64
+	Source    Source
+	// Recovered is set when the delta was detected by a REST reconciliation, that is, the stream missed it.
+	Recovered bool
+}
+
+// Transition An order state change
+type Transition struct {
+	Order  goex.OrderDecimal
+	From   OrderState
+	To     OrderState
+	Source Source
+}
+
+// TrackedOrder Canonical state of an order
+type TrackedOrder struct {
+	Order      goex.OrderDecimal
+	State      OrderState
+	UpdateTime int64
+}
+
+// FromFutureOrders translate derivatives orders so that they can be fed to a Tracker
+func FromFutureOrders(orders []goex.FutureOrderDecimal, pair goex.CurrencyPair) []goex.OrderDecimal {
+	ret := make([]goex.OrderDecimal, len(orders))
+	for i, o := range orders {
+		ret[i] = goex.OrderDecimal{
+			Price:      o.Price,
+			Amount:     o.Amount,
+			AvgPrice:   o.AvgPrice,
+			DealAmount: o.DealAmount,
+			Fee:        o.Fee,
+			OrderID2:   o.OrderID,
+			ClientOid:  o.ClientOrderID,
+			Timestamp:  o.OrderTime,
+			Status:     o.Status,
+			Currency:   pair,
+			Side:       o.Side,
+		}
+	}
+	return ret
+}
@@ -0,0 +1,411 @@
+package ordertracker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// PendingOrdersFunc Query open orders through REST, e.g. a closure around QueryPendingOrders or GetInstrumentPendingOrders
+type PendingOrdersFunc func() ([]goex.OrderDecimal, error)
+
+// QueryOrderFunc Query a single order through REST, e.g. a closure around QueryOrder or GetInstrumentOrder
+type QueryOrderFunc func(orderId string) (*goex.OrderDecimal, error)
+
+// Tracker Maintains canonical order states from stream updates and REST snapshots of one or more connectors.
+// Updates whose DealAmount is lower than the known one are treated as out-of-order and dropped;
+// fill quantities found by REST but never delivered by the stream are emitted as recovered fills.
+type Tracker struct {
+	lock   sync.Mutex
+	orders map[string]*TrackedOrder
+
+	fillHandles  []func(*Fill)
+	stateHandles []func(*Transition)
+	staleHandle  func(update *goex.OrderDecimal, known *TrackedOrder)
+	errorHandle  func(error)
+
+	staleCount int64
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewTracker Tracker constructor
+func NewTracker() *Tracker {
+	return &Tracker{
+		orders: make(map[string]*TrackedOrder),
+	}
+}
+
+func orderKey(o *goex.OrderDecimal) string {
+	if o.OrderID2 != "" {
+		return o.OrderID2
+	}
+	return o.ClientOid
+}
+
+// SubscribeFill Register a handler for fill deltas
+func (t *Tracker) SubscribeFill(handle func(*Fill)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.fillHandles = append(t.fillHandles, handle)
+}
+
+// SubscribeState Register a handler for state transitions
+func (t *Tracker) SubscribeState(handle func(*Transition)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.stateHandles = append(t.stateHandles, handle)
+}
+
+// SetStaleHandler Register a handler for dropped out-of-order updates
+func (t *Tracker) SetStaleHandler(handle func(update *goex.OrderDecimal, known *TrackedOrder)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.staleHandle = handle
+}
+
+// SetErrorHandler Register a handler for polling errors
+func (t *Tracker) SetErrorHandler(handle func(error)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.errorHandle = handle
+}
+
+// Track Start tracking an order that was just placed, before any update is received
+func (t *Tracker) Track(order goex.OrderDecimal) {
+	t.apply([]goex.OrderDecimal{order}, SOURCE_REST, false)
+}
+
+// OnOrders Feed stream order updates, the signature matches GetOrderWithWs handlers
+func (t *Tracker) OnOrders(orders []goex.OrderDecimal) {
+	t.apply(orders, SOURCE_STREAM, false)
+}
+
+// OnOrder Feed a single stream order update
+func (t *Tracker) OnOrder(order *goex.OrderDecimal) {
+	if order == nil {
+		return
+	}
+	t.apply([]goex.OrderDecimal{*order}, SOURCE_STREAM, false)
+}
+
+// MarkCancelPending Mark an order as cancel-pending after a cancel request was sent
+func (t *Tracker) MarkCancelPending(orderId string) {
+	t.lock.Lock()
+	o, ok := t.orders[orderId]
+	if !ok || o.State.IsFinal() || o.State == STATE_CANCEL_PENDING {
+		t.lock.Unlock()
+		return
+	}
+	tr := &Transition{Order: o.Order, From: o.State, To: STATE_CANCEL_PENDING, Source: SOURCE_REST}
+	o.State = STATE_CANCEL_PENDING
+	o.UpdateTime = time.Now().UnixNano() / int64(time.Millisecond)
+	stateHandles := t.stateHandles
+	t.lock.Unlock()
+
+	for _, h := range stateHandles {
+		h(tr)
+	}
+}
+
+// Get Get the tracked state of an order
+func (t *Tracker) Get(orderId string) (*TrackedOrder, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	o, ok := t.orders[orderId]
+	if !ok {
+		return nil, false
+	}
+	ret := *o
+	return &ret, true
+}
+
+// OpenOrders Get all tracked orders which are not in a final state
+func (t *Tracker) OpenOrders() []TrackedOrder {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var ret []TrackedOrder
+	for _, o := range t.orders {
+		if !o.State.IsFinal() {
+			ret = append(ret, *o)
+		}
+	}
+	return ret
+}
+
+// Remove Stop tracking an order
+func (t *Tracker) Remove(orderId string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.orders, orderId)
+}
+
+// PruneFinished Stop tracking final orders last updated before the given timestamp in milliseconds
+func (t *Tracker) PruneFinished(before int64) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var count int
+	for k, o := range t.orders {
+		if o.State.IsFinal() && o.UpdateTime < before {
+			delete(t.orders, k)
+			count++
+		}
+	}
+	return count
+}
+
+// StaleCount Number of out-of-order updates dropped so far
+func (t *Tracker) StaleCount() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.staleCount
+}
+
+// Reconcile Apply a REST snapshot of open orders of a currency pair. Tracked open orders of the pair missing
+// from the snapshot are queried one by one with query, if query is not nil.
+func (t *Tracker) Reconcile(pair goex.CurrencyPair, pending []goex.OrderDecimal, query QueryOrderFunc) error {
+	t.apply(pending, SOURCE_REST, true)
+
+	seen := make(map[string]bool, len(pending))
+	for i := range pending {
+		seen[orderKey(&pending[i])] = true
+	}
+
+	var missing []string
+	t.lock.Lock()
+	for k, o := range t.orders {
+		if !o.State.IsFinal() && o.Order.Currency == pair && !seen[k] {
+			missing = append(missing, k)
+		}
+	}
+	t.lock.Unlock()
+
+	if query == nil {
+		return nil
+	}
+
+	for _, orderId := range missing {
+		o, err := query(orderId)
+		if err != nil {
+			return err
+		}
+		if o == nil {
+			continue
+		}
+		t.apply([]goex.OrderDecimal{*o}, SOURCE_REST, true)
+	}
+	return nil
+}
+
+// Start Poll open orders of a currency pair periodically and reconcile them with the stream
+func (t *Tracker) Start(pair goex.CurrencyPair, interval time.Duration, pending PendingOrdersFunc, query QueryOrderFunc) {
+	t.lock.Lock()
+	if t.stopCh == nil {
+		t.stopCh = make(chan struct{})
+	}
+	stopCh := t.stopCh
+	t.lock.Unlock()
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				orders, err := pending()
+				if err == nil {
+					err = t.Reconcile(pair, orders, query)
+				}
+				if err != nil {
+					t.lock.Lock()
+					errorHandle := t.errorHandle
+					t.lock.Unlock()
+					if errorHandle != nil {
+						errorHandle(err)
+					}
+				}
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop Stop all polling goroutines
+func (t *Tracker) Stop() {
+	t.lock.Lock()
+	stopCh := t.stopCh
+	t.stopCh = nil
+	t.lock.Unlock()
+
+	if stopCh != nil {
+		close(stopCh)
+	}
+	t.wg.Wait()
+}
+
+// apply Merge order updates, reconcile is set for REST snapshots whose fills the stream has missed
+func (t *Tracker) apply(orders []goex.OrderDecimal, source Source, reconcile bool) {
+	var fills []*Fill
+	var transitions []*Transition
+	type stale struct {
+		update goex.OrderDecimal
+		known  TrackedOrder
+	}
+	var stales []stale
+
+	t.lock.Lock()
+	for i := range orders {
+		o := &orders[i]
+		key := orderKey(o)
+		if key == "" {
+			continue
+		}
+		state := FromTradeStatus(o.Status)
+
+		known, ok := t.orders[key]
+		if !ok && o.ClientOid != "" && o.ClientOid != key {
+			// Orders tracked before the exchange assigned an id are keyed by client order id
+			if known, ok = t.orders[o.ClientOid]; ok {
+				delete(t.orders, o.ClientOid)
+				t.orders[key] = known
+			}
+		}
+		if !ok {
+			known = &TrackedOrder{Order: *o, State: state, UpdateTime: updateTime(o)}
+			t.orders[key] = known
+			if state != STATE_NEW {
+				transitions = append(transitions, &Transition{Order: *o, From: STATE_NEW, To: state, Source: source})
+			}
+			if o.DealAmount.IsPositive() {
+				fill := newFill(nil, o, source)
+				fill.Recovered = reconcile
+				fills = append(fills, fill)
+			}
+			continue
+		}
+
+		cmp := o.DealAmount.Cmp(known.Order.DealAmount)
+		if cmp < 0 || (cmp == 0 && !isForward(known.State, state)) {
+			t.staleCount++
+			stales = append(stales, stale{update: *o, known: *known})
+			continue
+		}
+
+		if cmp > 0 {
+			fill := newFill(&known.Order, o, source)
+			// An increase first seen by a REST snapshot is one the stream has missed
+			fill.Recovered = reconcile
+			fills = append(fills, fill)
+		}
+
+		newState := state
+		if known.State.IsFinal() {
+			newState = known.State
+		} else if known.State == STATE_CANCEL_PENDING && !state.IsFinal() {
+			newState = STATE_CANCEL_PENDING
+		}
+
+		prevState := known.State
+		merged := *o
+		if merged.OrderID2 == "" {
+			merged.OrderID2 = known.Order.OrderID2
+		}
+		if merged.ClientOid == "" {
+			merged.ClientOid = known.Order.ClientOid
+		}
+		if merged.Currency == (goex.CurrencyPair{}) {
+			merged.Currency = known.Order.Currency
+		}
+		known.Order = merged
+		known.State = newState
+		known.UpdateTime = updateTime(o)
+		if newState != prevState {
+			transitions = append(transitions, &Transition{Order: merged, From: prevState, To: newState, Source: source})
+		}
+	}
+	fillHandles := t.fillHandles
+	stateHandles := t.stateHandles
+	staleHandle := t.staleHandle
+	t.lock.Unlock()
+
+	for _, s := range stales {
+		if staleHandle != nil {
+			staleHandle(&s.update, &s.known)
+		}
+	}
+	for _, tr := range transitions {
+		for _, h := range stateHandles {
+			h(tr)
+		}
+	}
+	for _, f := range fills {
+		for _, h := range fillHandles {
+			h(f)
+		}
+	}
+}
+
+// stateRank orders states along the only allowed direction: new -> partially filled -> cancel pending -> final
+func stateRank(s OrderState) int {
+	switch s {
+	case STATE_NEW:
+		return 0
+	case STATE_PARTIALLY_FILLED:
+		return 1
+	case STATE_CANCEL_PENDING:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func isForward(from, to OrderState) bool {
+	if from.IsFinal() {
+		return from == to
+	}
+	return stateRank(to) >= stateRank(from)
+}
+
+func updateTime(o *goex.OrderDecimal) int64 {
+	if o.Timestamp > 0 {
+		return o.Timestamp
+	}
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+func newFill(prev, cur *goex.OrderDecimal, source Source) *Fill {
+	var prevDeal, prevAvg, prevNotional decimal.Decimal
+	if prev != nil {
+		prevDeal = prev.DealAmount
+		prevAvg = prev.AvgPrice
+		prevNotional = prev.DealNotional
+	}
+	qty := cur.DealAmount.Sub(prevDeal)
+
+	var price decimal.Decimal
+	if cur.AvgPrice.IsPositive() && (prevDeal.IsZero() || prevAvg.IsPositive()) {
+		price = cur.AvgPrice.Mul(cur.DealAmount).Sub(prevAvg.Mul(prevDeal)).Div(qty)
+	} else if cur.DealNotional.IsPositive() {
+		price = cur.DealNotional.Sub(prevNotional).Div(qty)
+	} else {
+		price = cur.Price
+	}
+
+	return &Fill{
+		OrderID2:  cur.OrderID2,
+		ClientOid: cur.ClientOid,
+		Currency:  cur.Currency,
+		Side:      cur.Side,
+		Qty:       qty,
+		Price:     price,
+		CumQty:    cur.DealAmount,
+		Timestamp: updateTime(cur),
+		Source:    source,
+	}
+}
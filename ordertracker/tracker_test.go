@@ -0,0 +1,126 @@
+package ordertracker
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+	"github.com/stretchr/testify/assert"
+)
+
+func order(id string, status goex.TradeStatus, deal, avg string) goex.OrderDecimal {
+	return goex.OrderDecimal{
+		OrderID2:   id,
+		Price:      decimal.RequireFromString("100"),
+		Amount:     decimal.RequireFromString("10"),
+		DealAmount: decimal.RequireFromString(deal),
+		AvgPrice:   decimal.RequireFromString(avg),
+		Status:     status,
+		Currency:   goex.BTC_USDT,
+		Side:       goex.BUY,
+	}
+}
+
+func TestTracker_Fills(t *testing.T) {
+	tracker := NewTracker()
+	var fills []*Fill
+	tracker.SubscribeFill(func(f *Fill) {
+		fills = append(fills, f)
+	})
+
+	tracker.OnOrders([]goex.OrderDecimal{order("1", goex.ORDER_UNFINISH, "0", "0")})
+	tracker.OnOrders([]goex.OrderDecimal{order("1", goex.ORDER_PART_FINISH, "4", "100")})
+	tracker.OnOrders([]goex.OrderDecimal{order("1", goex.ORDER_FINISH, "10", "99.4")})
+
+	assert.Equal(t, 2, len(fills))
+	assert.True(t, fills[0].Qty.Equal(decimal.RequireFromString("4")))
+	assert.True(t, fills[0].Price.Equal(decimal.RequireFromString("100")))
+	assert.True(t, fills[1].Qty.Equal(decimal.RequireFromString("6")))
+	assert.True(t, fills[1].Price.Equal(decimal.RequireFromString("99")))
+
+	o, ok := tracker.Get("1")
+	assert.True(t, ok)
+	assert.Equal(t, OrderState(STATE_FILLED), o.State)
+}
+
+func TestTracker_OutOfOrder(t *testing.T) {
+	tracker := NewTracker()
+	var fills []*Fill
+	tracker.SubscribeFill(func(f *Fill) {
+		fills = append(fills, f)
+	})
+
+	tracker.OnOrders([]goex.OrderDecimal{order("1", goex.ORDER_PART_FINISH, "5", "100")})
+	tracker.OnOrders([]goex.OrderDecimal{order("1", goex.ORDER_UNFINISH, "0", "0")})
+	tracker.OnOrders([]goex.OrderDecimal{order("1", goex.ORDER_CANCEL, "5", "100")})
+	tracker.OnOrders([]goex.OrderDecimal{order("1", goex.ORDER_PART_FINISH, "5", "100")})
+
+	assert.Equal(t, 1, len(fills))
+	assert.Equal(t, int64(2), tracker.StaleCount())
+	o, _ := tracker.Get("1")
+	assert.Equal(t, OrderState(STATE_CANCELED), o.State)
+}
+
+func TestTracker_Reconcile(t *testing.T) {
+	tracker := NewTracker()
+	var fills []*Fill
+	tracker.SubscribeFill(func(f *Fill) {
+		fills = append(fills, f)
+	})
+
+	tracker.OnOrders([]goex.OrderDecimal{
+		order("1", goex.ORDER_UNFINISH, "0", "0"),
+		order("2", goex.ORDER_UNFINISH, "0", "0"),
+	})
+
+	pending := []goex.OrderDecimal{order("1", goex.ORDER_PART_FINISH, "2", "100")}
+	err := tracker.Reconcile(goex.BTC_USDT, pending, func(orderId string) (*goex.OrderDecimal, error) {
+		assert.Equal(t, "2", orderId)
+		o := order("2", goex.ORDER_FINISH, "10", "101")
+		return &o, nil
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, len(fills))
+	for _, f := range fills {
+		assert.True(t, f.Recovered)
+	}
+	assert.Equal(t, 1, len(tracker.OpenOrders()))
+
+	// Fills of orders first seen by a snapshot are recovered, those of a placement response are not
+	fills = nil
+	assert.Nil(t, tracker.Reconcile(goex.BTC_USDT, []goex.OrderDecimal{order("3", goex.ORDER_PART_FINISH, "3", "100")}, nil))
+	tracker.Track(order("4", goex.ORDER_FINISH, "10", "100"))
+	assert.Equal(t, 2, len(fills))
+	assert.True(t, fills[0].Recovered)
+	assert.False(t, fills[1].Recovered)
+}
+
+func TestTracker_CancelPending(t *testing.T) {
+	tracker := NewTracker()
+	var transitions []*Transition
+	tracker.SubscribeState(func(tr *Transition) {
+		transitions = append(transitions, tr)
+	})
+
+	o := order("", goex.ORDER_UNFINISH, "0", "0")
+	o.ClientOid = "c1"
+	tracker.Track(o)
+
+	o.OrderID2 = "1"
+	tracker.OnOrders([]goex.OrderDecimal{o})
+	tracker.MarkCancelPending("1")
+	tracker.OnOrders([]goex.OrderDecimal{order("1", goex.ORDER_PART_FINISH, "1", "100")})
+
+	tr, _ := tracker.Get("1")
+	assert.Equal(t, OrderState(STATE_CANCEL_PENDING), tr.State)
+	assert.Equal(t, "c1", tr.Order.ClientOid)
+
+	tracker.OnOrders([]goex.OrderDecimal{order("1", goex.ORDER_CANCEL, "1", "100")})
+	tr, _ = tracker.Get("1")
+	assert.Equal(t, OrderState(STATE_CANCELED), tr.State)
+	// No NEW -> NEW transition for the first sighting
+	assert.Equal(t, 2, len(transitions))
+	assert.Equal(t, OrderState(STATE_NEW), transitions[0].From)
+	assert.Equal(t, OrderState(STATE_CANCEL_PENDING), transitions[0].To)
+}
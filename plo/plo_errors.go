@@ -0,0 +1,28 @@
+package plo
+
+import (
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+	"github.com/stephenlyu/GoEx"
+)
+
+// plo返回{"err":1,"msg":"..."}, err为0表示成功, 没有公开的错误码表, 按msg分类
+func ploError(code, msg string) error {
+	return goex.NewExchangeError(goex.PLO, goex.ClassifyMessage(msg), code, msg)
+}
+
+func parsePloError(statusCode int, body []byte) *goex.ExchangeError {
+	var resp struct {
+		Error decimal.Decimal `json:"err"`
+		Msg   string          `json:"msg"`
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Error.IsZero() {
+		return nil
+	}
+	return goex.NewExchangeError(goex.PLO, goex.ClassifyMessage(resp.Msg), resp.Error.String(), resp.Msg)
+}
+
+func init() {
+	goex.RegisterErrorParser(goex.PLO, parsePloError, "api.plo.one")
+}
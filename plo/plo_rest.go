@@ -30,20 +30,32 @@ const (
 )
 
 type PloRest struct {
-	apiKey string
-	apiSecretKey string
+	signer *goex.SignerRef
 	client *http.Client
 }
 
 func NewPloRest(apiKey string, apiSecretKey string) *PloRest {
+	return NewPloRestWithSigner(goex.NewStaticSigner(apiKey, apiSecretKey, ""))
+}
+
+/**
+ * 使用自定义签名器创建实例, 密钥可以来自环境变量、加密文件或凭证代理
+ */
+func NewPloRestWithSigner(signer goex.Signer) *PloRest {
 	return &PloRest{
-		apiKey: apiKey,
-		apiSecretKey: apiSecretKey,
+		signer: goex.NewSignerRef(signer),
 
 		client: http.DefaultClient,
 	}
 }
 
+/**
+ * 替换签名器, 用于运行时轮换密钥
+ */
+func (this *PloRest) SetSigner(signer goex.Signer) {
+	this.signer.Set(signer)
+}
+
 func (bitmex *PloRest) map2Query(params map[string]string) string {
 	keys := make([]string, len(params))
 	var i int
@@ -187,12 +199,40 @@ func (this *PloRest) GetConfigList() (error, []PloConfig) {
 	}
 
 	if !data.Error.IsZero() {
-		return fmt.Errorf("error: %s", data.Error.String()), nil
+		return ploError(data.Error.String(), data.Msg), nil
 	}
 
 	return nil, data.Data
 }
 
+// ToInstrument convert to instrument, PLO only lists perpetual contracts traded in whole contracts
+func (r PloConfig) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Exchange:       goex.PLO,
+		Symbol:         r.Symbol,
+		Pair:           goex.NewCurrencyPair(goex.NewCurrency(r.Currency.Symbol, ""), goex.NewCurrency(r.QuoteCurrency.Symbol, "")),
+		Type:           goex.INSTRUMENT_SWAP,
+		PricePrecision: int32(r.PriceDecimalDigits),
+		MinQty:         decimal.New(1, 0),
+	}
+	inst.ContractValue, _ = decimal.NewFromString(r.UnitValue)
+	inst.FillPrecision()
+	return inst
+}
+
+// GetInstruments Get contract configs as instruments
+func (this *PloRest) GetInstruments() ([]goex.Instrument, error) {
+	err, configs := this.GetConfigList()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]goex.Instrument, len(configs))
+	for i := range configs {
+		ret[i] = configs[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 type PloBalance struct {
 	AccountId string 		`json:"accountId"`
 	Address string 			`json:"address"`
@@ -217,9 +257,10 @@ func (r PloBalance) ToFutureSubAccount() goex.FutureSubAccount {
 
 func (this *PloRest) GetBalances() (error, *goex.FutureAccount) {
 	ts := util.Tick()
-	message, signature := BuildSignature(this.apiKey, this.apiSecretKey, ts, "")
-
-	message += "&sign=" + signature
+	message, err := buildSignedForm(this.signer, ts, "")
+	if err != nil {
+		return err, nil
+	}
 
 	bytes, err := goex.HttpPostForm3(this.client, BASE_URL+BALANCES_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
 	if err != nil {
@@ -237,7 +278,7 @@ func (this *PloRest) GetBalances() (error, *goex.FutureAccount) {
 	}
 
 	if !resp.Error.IsZero() {
-		return fmt.Errorf("error: %s", resp.Error.String()), nil
+		return ploError(resp.Error.String(), resp.Msg), nil
 	}
 
 	ret := new(goex.FutureAccount)
@@ -294,12 +335,13 @@ func (this *PloRest) PlaceOrders(reqOrders []OrderReq) (error, []OrderResp) {
 
 	ts := util.Tick()
 	bytes, _ := json.Marshal(reqOrders)
-	message, signature := BuildSignature(this.apiKey, this.apiSecretKey, ts, base64.StdEncoding.EncodeToString(bytes))
-
-	message += "&sign=" + signature
+	message, err := buildSignedForm(this.signer, ts, base64.StdEncoding.EncodeToString(bytes))
+	if err != nil {
+		return err, nil
+	}
 	//println("placeorders", message)
 
-	bytes, err := goex.HttpPostForm3(this.client, BASE_URL+PLACE_ORDER_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	bytes, err = goex.HttpPostForm3(this.client, BASE_URL+PLACE_ORDER_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
 	if err != nil {
 		return err, nil
 	}
@@ -315,7 +357,7 @@ func (this *PloRest) PlaceOrders(reqOrders []OrderReq) (error, []OrderResp) {
 	}
 
 	if !resp.Error.IsZero() {
-		return fmt.Errorf("error: %s msg: %s", resp.Error.String(), resp.Msg), nil
+		return ploError(resp.Error.String(), resp.Msg), nil
 	}
 
 	for i := range resp.Data {
@@ -335,12 +377,13 @@ func (this *PloRest) SelfTrade(reqOrders []OrderReq) (error) {
 
 	ts := util.Tick()
 	bytes, _ := json.Marshal(reqOrders)
-	message, signature := BuildSignature(this.apiKey, this.apiSecretKey, ts, base64.StdEncoding.EncodeToString(bytes))
-
-	message += "&sign=" + signature
+	message, err := buildSignedForm(this.signer, ts, base64.StdEncoding.EncodeToString(bytes))
+	if err != nil {
+		return err
+	}
 	//println("selftrade", message)
 
-	bytes, err := goex.HttpPostForm3(this.client, BASE_URL+SELF_TRADE_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	bytes, err = goex.HttpPostForm3(this.client, BASE_URL+SELF_TRADE_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
 	if err != nil {
 		return err
 	}
@@ -355,7 +398,7 @@ func (this *PloRest) SelfTrade(reqOrders []OrderReq) (error) {
 	}
 
 	if !resp.Error.IsZero() {
-		return fmt.Errorf("error: %s msg: %s", resp.Error.String(), resp.Msg)
+		return ploError(resp.Error.String(), resp.Msg)
 	}
 
 	return nil
@@ -367,12 +410,13 @@ func (this *PloRest) SimpleSelfTrade(reqOrders []OrderReq) (error) {
 
 	ts := util.Tick()
 	bytes, _ := json.Marshal(reqOrders)
-	message, signature := BuildSignature(this.apiKey, this.apiSecretKey, ts, base64.StdEncoding.EncodeToString(bytes))
-
-	message += "&sign=" + signature
+	message, err := buildSignedForm(this.signer, ts, base64.StdEncoding.EncodeToString(bytes))
+	if err != nil {
+		return err
+	}
 	//println("simpleSelftrade", message)
 
-	bytes, err := goex.HttpPostForm3(this.client, BASE_URL+SIMPLE_SELF_TRADE_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	bytes, err = goex.HttpPostForm3(this.client, BASE_URL+SIMPLE_SELF_TRADE_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
 	if err != nil {
 		return err
 	}
@@ -387,7 +431,7 @@ func (this *PloRest) SimpleSelfTrade(reqOrders []OrderReq) (error) {
 	}
 
 	if !resp.Error.IsZero() {
-		return fmt.Errorf("error: %s msg: %s", resp.Error.String(), resp.Msg)
+		return ploError(resp.Error.String(), resp.Msg)
 	}
 
 	return nil
@@ -403,12 +447,13 @@ func (this *PloRest) CancelOrders(orderIds []string) (error, []error) {
 
 	bytes, _ := json.Marshal(data)
 	ts := util.Tick()
-	message, signature := BuildSignature(this.apiKey, this.apiSecretKey, ts, base64.StdEncoding.EncodeToString(bytes))
-
-	message += "&sign=" + signature
+	message, err := buildSignedForm(this.signer, ts, base64.StdEncoding.EncodeToString(bytes))
+	if err != nil {
+		return err, nil
+	}
 	//println("cancel orders", message)
 
-	bytes, err := goex.HttpPostForm3(this.client, BASE_URL+CANCEL_ORDER_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	bytes, err = goex.HttpPostForm3(this.client, BASE_URL+CANCEL_ORDER_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
 	if err != nil {
 		return err, nil
 	}
@@ -427,14 +472,14 @@ func (this *PloRest) CancelOrders(orderIds []string) (error, []error) {
 	}
 
 	if !resp.Error.IsZero() {
-		return fmt.Errorf("error: %s msg: %s", resp.Error.String(), resp.Msg), nil
+		return ploError(resp.Error.String(), resp.Msg), nil
 	}
 
 	errors := make([]error, len(resp.Data))
 	for i := range resp.Data {
 		item := &resp.Data[i]
 		if !item.Error.IsZero() {
-			errors[i] = fmt.Errorf("error: %s msg: %s", item.Error.String(), item.Msg)
+			errors[i] = ploError(item.Error.String(), item.Msg)
 		}
 	}
 
@@ -472,12 +517,13 @@ func (this *PloRest) BatchOrders(orderIds []string) (error, []PloOrder) {
 
 	bytes, _ := json.Marshal(data)
 	ts := util.Tick()
-	message, signature := BuildSignature(this.apiKey, this.apiSecretKey, ts, base64.StdEncoding.EncodeToString(bytes))
-
-	message += "&sign=" + signature
+	message, err := buildSignedForm(this.signer, ts, base64.StdEncoding.EncodeToString(bytes))
+	if err != nil {
+		return err, nil
+	}
 	//println("batch orders", message)
 
-	bytes, err := goex.HttpPostForm3(this.client, BASE_URL+BATCH_ORDER_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	bytes, err = goex.HttpPostForm3(this.client, BASE_URL+BATCH_ORDER_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
 	if err != nil {
 		return err, nil
 	}
@@ -493,7 +539,7 @@ func (this *PloRest) BatchOrders(orderIds []string) (error, []PloOrder) {
 	}
 
 	if !resp.Error.IsZero() {
-		return fmt.Errorf("error: %s msg: %s", resp.Error.String(), resp.Msg), nil
+		return ploError(resp.Error.String(), resp.Msg), nil
 	}
 
 	return nil, resp.Data
@@ -507,12 +553,13 @@ func (this *PloRest) QueryOrders(pair goex.CurrencyPair, status int) (error, []P
 
 	bytes, _ := json.Marshal(params)
 	ts := util.Tick()
-	message, signature := BuildSignature(this.apiKey, this.apiSecretKey, ts, base64.StdEncoding.EncodeToString(bytes))
-
-	message += "&sign=" + signature
+	message, err := buildSignedForm(this.signer, ts, base64.StdEncoding.EncodeToString(bytes))
+	if err != nil {
+		return err, nil
+	}
 	//println("query orders", message)
 
-	bytes, err := goex.HttpPostForm3(this.client, BASE_URL+ORDERS_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	bytes, err = goex.HttpPostForm3(this.client, BASE_URL+ORDERS_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
 	if err != nil {
 		return err, nil
 	}
@@ -533,7 +580,7 @@ func (this *PloRest) QueryOrders(pair goex.CurrencyPair, status int) (error, []P
 	}
 
 	if !resp.Error.IsZero() {
-		return fmt.Errorf("error: %s msg: %s", resp.Error.String(), resp.Msg), nil
+		return ploError(resp.Error.String(), resp.Msg), nil
 	}
 
 	return nil, resp.Data.Data
@@ -577,12 +624,13 @@ func (this *PloRest) QueryPositions(pair goex.CurrencyPair, status int) (error,
 
 	bytes, _ := json.Marshal(params)
 	ts := util.Tick()
-	message, signature := BuildSignature(this.apiKey, this.apiSecretKey, ts, base64.StdEncoding.EncodeToString(bytes))
-
-	message += "&sign=" + signature
+	message, err := buildSignedForm(this.signer, ts, base64.StdEncoding.EncodeToString(bytes))
+	if err != nil {
+		return err, nil
+	}
 	//println("query positions", message)
 
-	bytes, err := goex.HttpPostForm3(this.client, BASE_URL+POSITIONS_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	bytes, err = goex.HttpPostForm3(this.client, BASE_URL+POSITIONS_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
 	if err != nil {
 		return err, nil
 	}
@@ -598,7 +646,7 @@ func (this *PloRest) QueryPositions(pair goex.CurrencyPair, status int) (error,
 	}
 
 	if !resp.Error.IsZero() {
-		return fmt.Errorf("error: %s msg: %s", resp.Error.String(), resp.Msg), nil
+		return ploError(resp.Error.String(), resp.Msg), nil
 	}
 
 	return nil, resp.Data
@@ -613,11 +661,12 @@ func (this *PloRest) QueryPosRanking(pair goex.CurrencyPair, posType string, cou
 
 	bytes, _ := json.Marshal(params)
 	ts := util.Tick()
-	message, signature := BuildSignature(this.apiKey, this.apiSecretKey, ts, base64.StdEncoding.EncodeToString(bytes))
-
-	message += "&sign=" + signature
+	message, err := buildSignedForm(this.signer, ts, base64.StdEncoding.EncodeToString(bytes))
+	if err != nil {
+		return err, nil
+	}
 
-	bytes, err := goex.HttpPostForm3(this.client, BASE_URL+POS_RANK_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	bytes, err = goex.HttpPostForm3(this.client, BASE_URL+POS_RANK_URL, message, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
 	if err != nil {
 		return err, nil
 	}
@@ -633,7 +682,7 @@ func (this *PloRest) QueryPosRanking(pair goex.CurrencyPair, posType string, cou
 	}
 
 	if !resp.Error.IsZero() {
-		return fmt.Errorf("error: %s msg: %s", resp.Error.String(), resp.Msg), nil
+		return ploError(resp.Error.String(), resp.Msg), nil
 	}
 
 	return nil, resp.Data
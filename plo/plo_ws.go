@@ -14,8 +14,7 @@ import (
 )
 
 type PloWs struct {
-	apiKey,
-	apiSecretKey     string
+	signer           *SignerRef
 	ws               *WsConn
 	createWsLock     sync.Mutex
 
@@ -31,7 +30,18 @@ type PloWs struct {
 }
 
 func NewPloWs(apiKey, apiSecretyKey string) *PloWs {
-	return &PloWs{apiKey: apiKey, apiSecretKey: apiSecretyKey}
+	return NewPloWsWithSigner(NewStaticSigner(apiKey, apiSecretyKey, ""))
+}
+
+func NewPloWsWithSigner(signer Signer) *PloWs {
+	return &PloWs{signer: NewSignerRef(signer)}
+}
+
+/**
+ * 替换签名器, 新签名器在下次Authenticate时生效
+ */
+func (ploWs *PloWs) SetSigner(signer Signer) {
+	ploWs.signer.Set(signer)
 }
 
 func (ploWs *PloWs) createWsConn() {
@@ -274,15 +284,20 @@ func (ploWs *PloWs) GetTradeWithWs(pair CurrencyPair, isIndex bool, handle func(
 }
 
 func (ploWs *PloWs) Authenticate(handle func()) error {
+	ts := util.Tick()
+	signature, err := ploWs.signer.Sign(HMAC_SHA256_HEX, func(apiKey string) string {
+		return wsSignMessage(apiKey, ts)
+	})
+	if err != nil {
+		return err
+	}
 	ploWs.createWsConn()
 	ploWs.authHandle = handle
-	ts := util.Tick()
-	sign := BuildWsSignature(ploWs.apiKey, ploWs.apiSecretKey, ts)
 	return ploWs.ws.Subscribe(map[string]interface{}{
 		"op":   "connect",
-		"accessKey": ploWs.apiKey,
+		"accessKey": signature.ApiKey,
 		"ts": ts,
-		"sign": sign,
+		"sign": signature.Sign,
 	})
 }
 
@@ -5,14 +5,34 @@ import (
 	"github.com/stephenlyu/GoEx"
 )
 
+func signMessage(apiKey string, ts uint64, data string) string {
+	return fmt.Sprintf("accessKey=%s&data=%s&ts=%d", apiKey, data, ts)
+}
+
+func wsSignMessage(apiKey string, ts uint64) string {
+	return fmt.Sprintf("accessKey=%s&ts=%d", apiKey, ts)
+}
+
 func BuildSignature(apiKey, secret string, ts uint64, data string) (string, string) {
-	message := fmt.Sprintf("accessKey=%s&data=%s&ts=%d", apiKey, data, ts)
+	message := signMessage(apiKey, ts, data)
 	ret, _ := goex.GetParamHmacSHA256Sign(secret, message)
 	return message, ret
 }
 
 func BuildWsSignature(apiKey, secret string, ts uint64) string {
-	message := fmt.Sprintf("accessKey=%s&ts=%d", apiKey, ts)
-	ret, _ := goex.GetParamHmacSHA256Sign(secret, message)
+	ret, _ := goex.GetParamHmacSHA256Sign(secret, wsSignMessage(apiKey, ts))
 	return ret
 }
+
+/**
+ * 用签名器生成带签名的请求体: accessKey=...&data=...&ts=...&sign=...
+ */
+func buildSignedForm(signer goex.Signer, ts uint64, data string) (string, error) {
+	signature, err := signer.Sign(goex.HMAC_SHA256_HEX, func(apiKey string) string {
+		return signMessage(apiKey, ts, data)
+	})
+	if err != nil {
+		return "", err
+	}
+	return signature.Message + "&sign=" + signature.Sign, nil
+}
@@ -1,6 +1,7 @@
 package poloniex
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 const EXCHANGE_NAME = "poloniex.com"
@@ -38,8 +41,12 @@ func (poloniex *Poloniex) GetExchangeName() string {
 }
 
 func (poloniex *Poloniex) GetTicker(currency CurrencyPair) (*Ticker, error) {
+	return poloniex.GetTickerWithContext(context.Background(), currency)
+}
+
+func (poloniex *Poloniex) GetTickerWithContext(ctx context.Context, currency CurrencyPair) (*Ticker, error) {
 	//log.Println(poloniex.adaptCurrencyPair(currency).ToSymbol2("_"))
-	respmap, err := HttpGet(poloniex.client, PUBLIC_URL+TICKER_API)
+	respmap, err := HttpGetWithContext(ctx, poloniex.client, PUBLIC_URL+TICKER_API)
 	if err != nil {
 		log.Println(err)
 		return nil, err
@@ -65,8 +72,90 @@ func (poloniex *Poloniex) GetTicker(currency CurrencyPair) (*Ticker, error) {
 
 	return ticker, nil
 }
+
+/**
+ * 所有交易对的24小时行情. poloniex的交易对是计价币在前, 如USDT_BTC, baseVolume是计价币成交额
+ */
+func (poloniex *Poloniex) GetAllTickers() (map[CurrencyPair]*TickerDecimal, error) {
+	var tickers map[string]struct {
+		Last          decimal.Decimal
+		LowestAsk     decimal.Decimal
+		HighestBid    decimal.Decimal
+		PercentChange decimal.Decimal
+		BaseVolume    decimal.Decimal
+		QuoteVolume   decimal.Decimal
+		High24hr      decimal.Decimal
+		Low24hr       decimal.Decimal
+	}
+	err := HttpGet4(poloniex.client, PUBLIC_URL+TICKER_API, nil, &tickers)
+	if err != nil {
+		return nil, err
+	}
+
+	now := uint64(time.Now().Unix())
+	ret := make(map[CurrencyPair]*TickerDecimal, len(tickers))
+	for symbol, r := range tickers {
+		reversed := NewCurrencyPair2(symbol)
+		if reversed == UNKNOWN_PAIR {
+			continue
+		}
+		pair := CanonicalPair(NewCurrencyPair(reversed.CurrencyB, reversed.CurrencyA))
+		ticker := &TickerDecimal{
+			Pair:     pair,
+			Last:     r.Last,
+			Buy:      r.HighestBid,
+			Sell:     r.LowestAsk,
+			High:     r.High24hr,
+			Low:      r.Low24hr,
+			Vol:      r.QuoteVolume,
+			QuoteVol: r.BaseVolume,
+			Change:   r.PercentChange,
+			Date:     now,
+		}
+		ticker.FillChange()
+		ret[pair] = ticker
+	}
+	return ret, nil
+}
+
+/**
+ * 交易品种来自returnTicker, 接口不提供精度和最小下单量
+ */
+func (poloniex *Poloniex) GetInstruments() ([]Instrument, error) {
+	var tickers map[string]struct {
+		IsFrozen string
+	}
+	err := HttpGet4(poloniex.client, PUBLIC_URL+TICKER_API, nil, &tickers)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Instrument, 0, len(tickers))
+	for symbol, r := range tickers {
+		reversed := NewCurrencyPair2(symbol)
+		if reversed == UNKNOWN_PAIR {
+			continue
+		}
+		inst := Instrument{
+			Symbol: symbol,
+			Pair:   NewCurrencyPair(reversed.CurrencyB, reversed.CurrencyA),
+			Type:   INSTRUMENT_SPOT,
+			Status: INSTRUMENT_STATUS_TRADING,
+		}
+		if r.IsFrozen == "1" {
+			inst.Status = INSTRUMENT_STATUS_SUSPENDED
+		}
+		ret = append(ret, inst)
+	}
+	return ret, nil
+}
+
 func (poloniex *Poloniex) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
-	respmap, err := HttpGet(poloniex.client, PUBLIC_URL+
+	return poloniex.GetDepthWithContext(context.Background(), size, currency)
+}
+
+func (poloniex *Poloniex) GetDepthWithContext(ctx context.Context, size int, currency CurrencyPair) (*Depth, error) {
+	respmap, err := HttpGetWithContext(ctx, poloniex.client, PUBLIC_URL+
 		fmt.Sprintf(ORDER_BOOK_API, currency.AdaptUsdToUsdt().Reverse().ToSymbol("_"), size))
 
 	if err != nil {
@@ -115,11 +204,78 @@ func (poloniex *Poloniex) GetDepth(size int, currency CurrencyPair) (*Depth, err
 
 	return &depth, nil
 }
-func (Poloniex *Poloniex) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
-	return nil, nil
+
+var klinePeriods = map[int]int64{
+	KLINE_PERIOD_5MIN:  300,
+	KLINE_PERIOD_15MIN: 900,
+	KLINE_PERIOD_30MIN: 1800,
+	KLINE_PERIOD_4H:    14400,
+	KLINE_PERIOD_1DAY:  86400,
+}
+
+func (poloniex *Poloniex) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
+	return poloniex.GetKlineRecordsWithContext(context.Background(), currency, period, size, since)
+}
+
+func (poloniex *Poloniex) GetKlineRecordsWithContext(ctx context.Context, currency CurrencyPair, period, size, since int) ([]Kline, error) {
+	klines, err := poloniex.GetKlineRecordsDecimalWithContext(ctx, currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return KlineDecimalsToKlines(klines), nil
 }
 
-func (poloniex *Poloniex) placeLimitOrder(command, amount, price string, currency CurrencyPair) (*Order, error) {
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线. 不支持1分钟和1小时K线
+ */
+func (poloniex *Poloniex) GetKlineRecordsDecimal(currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	return poloniex.GetKlineRecordsDecimalWithContext(context.Background(), currency, period, size, since)
+}
+
+func (poloniex *Poloniex) GetKlineRecordsDecimalWithContext(ctx context.Context, currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	periodSec, ok := klinePeriods[period]
+	if !ok {
+		return nil, EX_ERR_NOT_SUPPORTED
+	}
+	symbol := currency.AdaptUsdToUsdt().Reverse().ToSymbol("_")
+
+	return GetAllKlineDecimals(int64(since), size, 1000, func(q PageQuery) ([]KlineDecimal, error) {
+		window := int64(q.Limit) * periodSec
+		end := time.Now().Unix()
+		if q.From > 0 {
+			end = q.From/1000 + window
+		} else if q.To > 0 {
+			end = q.To / 1000
+		}
+
+		// volume以计价币计, quoteVolume以基础币计
+		var resp []struct {
+			Date        int64
+			Open        decimal.Decimal
+			Close       decimal.Decimal
+			High        decimal.Decimal
+			Low         decimal.Decimal
+			QuoteVolume decimal.Decimal
+		}
+		err := HttpGet4WithContext(ctx, poloniex.client, PUBLIC_URL+fmt.Sprintf("?command=returnChartData&currencyPair=%s&period=%d&start=%d&end=%d",
+			symbol, periodSec, end-window, end), nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		klines := make([]KlineDecimal, 0, len(resp))
+		for _, r := range resp {
+			// 没有数据时返回一条date为0的记录
+			if r.Date == 0 {
+				continue
+			}
+			klines = append(klines, KlineDecimal{Pair: currency, Timestamp: r.Date, Open: r.Open, Close: r.Close, High: r.High, Low: r.Low, Vol: r.QuoteVolume})
+		}
+		return klines, nil
+	})
+}
+
+func (poloniex *Poloniex) placeLimitOrder(ctx context.Context, command, amount, price string, currency CurrencyPair) (*Order, error) {
 	postData := url.Values{}
 	postData.Set("command", command)
 	postData.Set("currencyPair", currency.AdaptUsdToUsdt().Reverse().ToSymbol("_"))
@@ -132,7 +288,7 @@ func (poloniex *Poloniex) placeLimitOrder(command, amount, price string, currenc
 		"Key":  poloniex.accessKey,
 		"Sign": sign}
 
-	resp, err := HttpPostForm2(poloniex.client, TRADE_API, postData, headers)
+	resp, err := HttpPostForm2WithContext(ctx, poloniex.client, TRADE_API, postData, headers)
 	if err != nil {
 		log.Println(err)
 		return nil, err
@@ -167,14 +323,26 @@ func (poloniex *Poloniex) placeLimitOrder(command, amount, price string, currenc
 }
 
 func (poloniex *Poloniex) LimitBuy(amount, price string, currency CurrencyPair) (*Order, error) {
-	return poloniex.placeLimitOrder("buy", amount, price, currency)
+	return poloniex.LimitBuyWithContext(context.Background(), amount, price, currency)
+}
+
+func (poloniex *Poloniex) LimitBuyWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	return poloniex.placeLimitOrder(ctx, "buy", amount, price, currency)
 }
 
 func (poloniex *Poloniex) LimitSell(amount, price string, currency CurrencyPair) (*Order, error) {
-	return poloniex.placeLimitOrder("sell", amount, price, currency)
+	return poloniex.LimitSellWithContext(context.Background(), amount, price, currency)
+}
+
+func (poloniex *Poloniex) LimitSellWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	return poloniex.placeLimitOrder(ctx, "sell", amount, price, currency)
 }
 
 func (poloniex *Poloniex) CancelOrder(orderId string, currency CurrencyPair) (bool, error) {
+	return poloniex.CancelOrderWithContext(context.Background(), orderId, currency)
+}
+
+func (poloniex *Poloniex) CancelOrderWithContext(ctx context.Context, orderId string, currency CurrencyPair) (bool, error) {
 	postData := url.Values{}
 	postData.Set("command", "cancelOrder")
 	postData.Set("orderNumber", orderId)
@@ -188,7 +356,7 @@ func (poloniex *Poloniex) CancelOrder(orderId string, currency CurrencyPair) (bo
 	headers := map[string]string{
 		"Key":  poloniex.accessKey,
 		"Sign": sign}
-	resp, err := HttpPostForm2(poloniex.client, TRADE_API, postData, headers)
+	resp, err := HttpPostForm2WithContext(ctx, poloniex.client, TRADE_API, postData, headers)
 	if err != nil {
 		log.Println(err)
 		return false, err
@@ -213,6 +381,10 @@ func (poloniex *Poloniex) CancelOrder(orderId string, currency CurrencyPair) (bo
 }
 
 func (poloniex *Poloniex) GetOneOrder(orderId string, currency CurrencyPair) (*Order, error) {
+	return poloniex.GetOneOrderWithContext(context.Background(), orderId, currency)
+}
+
+func (poloniex *Poloniex) GetOneOrderWithContext(ctx context.Context, orderId string, currency CurrencyPair) (*Order, error) {
 	postData := url.Values{}
 	postData.Set("command", "returnOrderTrades")
 	postData.Set("orderNumber", orderId)
@@ -223,7 +395,7 @@ func (poloniex *Poloniex) GetOneOrder(orderId string, currency CurrencyPair) (*O
 		"Key":  poloniex.accessKey,
 		"Sign": sign}
 
-	resp, err := HttpPostForm2(poloniex.client, TRADE_API, postData, headers)
+	resp, err := HttpPostForm2WithContext(ctx, poloniex.client, TRADE_API, postData, headers)
 	if err != nil {
 		log.Println(err)
 		return nil, err
@@ -285,6 +457,10 @@ func (poloniex *Poloniex) GetOneOrder(orderId string, currency CurrencyPair) (*O
 }
 
 func (poloniex *Poloniex) GetUnfinishOrders(currency CurrencyPair) ([]Order, error) {
+	return poloniex.GetUnfinishOrdersWithContext(context.Background(), currency)
+}
+
+func (poloniex *Poloniex) GetUnfinishOrdersWithContext(ctx context.Context, currency CurrencyPair) ([]Order, error) {
 	postData := url.Values{}
 	postData.Set("command", "returnOpenOrders")
 	postData.Set("currencyPair", currency.AdaptUsdToUsdt().Reverse().ToSymbol("_"))
@@ -298,7 +474,7 @@ func (poloniex *Poloniex) GetUnfinishOrders(currency CurrencyPair) ([]Order, err
 	headers := map[string]string{
 		"Key":  poloniex.accessKey,
 		"Sign": sign}
-	resp, err := HttpPostForm2(poloniex.client, TRADE_API, postData, headers)
+	resp, err := HttpPostForm2WithContext(ctx, poloniex.client, TRADE_API, postData, headers)
 	if err != nil {
 		log.Println(err)
 		return nil, err
@@ -336,11 +512,19 @@ func (poloniex *Poloniex) GetUnfinishOrders(currency CurrencyPair) ([]Order, err
 	//log.Println(orders)
 	return orders, nil
 }
-func (Poloniex *Poloniex) GetOrderHistorys(currency CurrencyPair, currentPage, pageSize int) ([]Order, error) {
+func (poloniex *Poloniex) GetOrderHistorys(currency CurrencyPair, currentPage, pageSize int) ([]Order, error) {
+	return poloniex.GetOrderHistorysWithContext(context.Background(), currency, currentPage, pageSize)
+}
+
+func (poloniex *Poloniex) GetOrderHistorysWithContext(ctx context.Context, currency CurrencyPair, currentPage, pageSize int) ([]Order, error) {
 	return nil, nil
 }
 
 func (poloniex *Poloniex) GetAccount() (*Account, error) {
+	return poloniex.GetAccountWithContext(context.Background())
+}
+
+func (poloniex *Poloniex) GetAccountWithContext(ctx context.Context) (*Account, error) {
 	postData := url.Values{}
 	postData.Add("command", "returnCompleteBalances")
 	sign, err := poloniex.buildPostForm(&postData)
@@ -351,7 +535,7 @@ func (poloniex *Poloniex) GetAccount() (*Account, error) {
 	headers := map[string]string{
 		"Key":  poloniex.accessKey,
 		"Sign": sign}
-	resp, err := HttpPostForm2(poloniex.client, TRADE_API, postData, headers)
+	resp, err := HttpPostForm2WithContext(ctx, poloniex.client, TRADE_API, postData, headers)
 
 	if err != nil {
 		log.Println(err)
@@ -503,13 +687,25 @@ func (poloniex *Poloniex) buildPostForm(postForm *url.Values) (string, error) {
 }
 
 func (poloniex *Poloniex) GetTrades(currencyPair CurrencyPair, since int64) ([]Trade, error) {
+	return poloniex.GetTradesWithContext(context.Background(), currencyPair, since)
+}
+
+func (poloniex *Poloniex) GetTradesWithContext(ctx context.Context, currencyPair CurrencyPair, since int64) ([]Trade, error) {
 	panic("unimplements")
 }
 
 func (poloniex *Poloniex) MarketBuy(amount, price string, currency CurrencyPair) (*Order, error) {
+	return poloniex.MarketBuyWithContext(context.Background(), amount, price, currency)
+}
+
+func (poloniex *Poloniex) MarketBuyWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
 	panic("unsupport the market order")
 }
 
 func (poloniex *Poloniex) MarketSell(amount, price string, currency CurrencyPair) (*Order, error) {
+	return poloniex.MarketSellWithContext(context.Background(), amount, price, currency)
+}
+
+func (poloniex *Poloniex) MarketSellWithContext(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
 	panic("unsupport the market order")
 }
@@ -0,0 +1,26 @@
+package poloniex
+
+import (
+	"encoding/json"
+
+	. "github.com/stephenlyu/GoEx"
+)
+
+// poloniex错误格式为{"error":"..."}, 没有错误码
+func poloniexError(message string) error {
+	return NewExchangeError(POLONIEX, ClassifyMessage(message), "", message)
+}
+
+func parsePoloniexError(statusCode int, body []byte) *ExchangeError {
+	var resp struct {
+		Error string
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Error == "" {
+		return nil
+	}
+	return NewExchangeError(POLONIEX, ClassifyMessage(resp.Error), "", resp.Error)
+}
+
+func init() {
+	RegisterErrorParser(POLONIEX, parsePoloniexError, "poloniex.com")
+}
@@ -0,0 +1,329 @@
+package poloniex
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	. "github.com/stephenlyu/GoEx"
+)
+
+const CURRENCIES_API = "?command=returnCurrencies"
+
+var poloniexWallets = map[WalletType]string{
+	WALLET_SPOT:    "exchange",
+	WALLET_MARGIN:  "margin",
+	WALLET_FUNDING: "lending",
+}
+
+func (poloniex *Poloniex) adaptCurrency(currency Currency) string {
+	if currency == BCC {
+		currency = BCH
+	}
+	return strings.ToUpper(currency.Symbol)
+}
+
+func (poloniex *Poloniex) doTradingRequest(params url.Values, result interface{}) error {
+	sign, err := poloniex.buildPostForm(&params)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{
+		"Key":  poloniex.accessKey,
+		"Sign": sign}
+
+	resp, err := HttpPostForm2(poloniex.client, TRADE_API, params, headers)
+	if err != nil {
+		return err
+	}
+
+	var errResp struct {
+		Error string
+	}
+	if json.Unmarshal(resp, &errResp) == nil && errResp.Error != "" {
+		return poloniexError(errResp.Error)
+	}
+
+	return json.Unmarshal(resp, result)
+}
+
+type PoloniexCurrency struct {
+	Id             int64
+	Name           string
+	TxFee          decimal.Decimal
+	MinConf        int
+	DepositAddress string // 需要标签的币种的公共充值地址, 此时returnDepositAddresses返回的是标签
+	Disabled       int
+	Delisted       int
+	Frozen         int
+}
+
+func (poloniex *Poloniex) GetCurrencies() (map[string]PoloniexCurrency, error) {
+	var resp map[string]PoloniexCurrency
+	err := HttpGet4(poloniex.client, PUBLIC_URL+CURRENCIES_API, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (poloniex *Poloniex) GetDepositAddress(currency Currency) ([]DepositAddress, error) {
+	symbol := poloniex.adaptCurrency(currency)
+
+	params := url.Values{}
+	params.Set("command", "returnDepositAddresses")
+	var addresses map[string]string
+	err := poloniex.doTradingRequest(params, &addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	address, ok := addresses[symbol]
+	if !ok {
+		params = url.Values{}
+		params.Set("command", "generateNewAddress")
+		params.Set("currency", symbol)
+		var resp struct {
+			Success  int
+			Response string
+		}
+		err = poloniex.doTradingRequest(params, &resp)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Success != 1 {
+			return nil, poloniexError(resp.Response)
+		}
+		address = resp.Response
+	}
+
+	currencies, err := poloniex.GetCurrencies()
+	if err != nil {
+		return nil, err
+	}
+	if info, ok := currencies[symbol]; ok && info.DepositAddress != "" {
+		return []DepositAddress{{Currency: currency, Address: info.DepositAddress, Tag: address}}, nil
+	}
+	return []DepositAddress{{Currency: currency, Address: address}}, nil
+}
+
+type PoloniexDeposit struct {
+	DepositNumber int64
+	Currency      string
+	Address       string
+	Amount        decimal.Decimal
+	Confirmations int
+	Txid          string
+	Timestamp     int64
+	Status        string
+}
+
+type PoloniexWithdrawal struct {
+	WithdrawalNumber int64
+	Currency         string
+	Address          string
+	Amount           decimal.Decimal
+	Fee              decimal.Decimal
+	Timestamp        int64
+	Status           string
+	PaymentID        string
+}
+
+func (this *PoloniexDeposit) ToFundingRecord() *FundingRecord {
+	status := FUNDING_PROCESSING
+	if this.Status == "COMPLETE" {
+		status = FUNDING_SUCCESS
+	}
+	return &FundingRecord{
+		Id:        strconv.FormatInt(this.DepositNumber, 10),
+		Type:      FUNDING_DEPOSIT,
+		Currency:  NewCurrency(this.Currency, ""),
+		Amount:    this.Amount,
+		Address:   this.Address,
+		TxId:      this.Txid,
+		Status:    status,
+		RawStatus: this.Status,
+		Timestamp: this.Timestamp * 1000,
+	}
+}
+
+// 已完成的提币状态为 "COMPLETE: <txid>"
+func (this *PoloniexWithdrawal) ToFundingRecord() *FundingRecord {
+	var status FundingStatus
+	var txid string
+	switch {
+	case strings.HasPrefix(this.Status, "COMPLETE"):
+		status = FUNDING_SUCCESS
+		txid = strings.TrimSpace(strings.TrimPrefix(this.Status, "COMPLETE:"))
+	case strings.HasPrefix(this.Status, "CANCEL"):
+		status = FUNDING_CANCELED
+	case strings.HasPrefix(this.Status, "FAIL"), strings.HasPrefix(this.Status, "REJECT"):
+		status = FUNDING_FAILED
+	case strings.HasPrefix(this.Status, "PROCESSING"):
+		status = FUNDING_PROCESSING
+	default:
+		status = FUNDING_PENDING
+	}
+	return &FundingRecord{
+		Id:        strconv.FormatInt(this.WithdrawalNumber, 10),
+		Type:      FUNDING_WITHDRAW,
+		Currency:  NewCurrency(this.Currency, ""),
+		Amount:    this.Amount,
+		Fee:       this.Fee,
+		Address:   this.Address,
+		Tag:       this.PaymentID,
+		TxId:      txid,
+		Status:    status,
+		RawStatus: this.Status,
+		Timestamp: this.Timestamp * 1000,
+	}
+}
+
+/**
+ * 获取最近30天的充提记录
+ */
+func (poloniex *Poloniex) getDepositsWithdrawals() ([]PoloniexDeposit, []PoloniexWithdrawal, error) {
+	now := time.Now()
+	params := url.Values{}
+	params.Set("command", "returnDepositsWithdrawals")
+	params.Set("start", fmt.Sprint(now.AddDate(0, 0, -30).Unix()))
+	params.Set("end", fmt.Sprint(now.Unix()))
+
+	var resp struct {
+		Deposits    []PoloniexDeposit
+		Withdrawals []PoloniexWithdrawal
+	}
+	err := poloniex.doTradingRequest(params, &resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Deposits, resp.Withdrawals, nil
+}
+
+func (poloniex *Poloniex) GetDeposits(currency Currency) ([]FundingRecord, error) {
+	deposits, _, err := poloniex.getDepositsWithdrawals()
+	if err != nil {
+		return nil, err
+	}
+
+	symbol := poloniex.adaptCurrency(currency)
+	var ret []FundingRecord
+	for i := range deposits {
+		if deposits[i].Currency == symbol {
+			ret = append(ret, *deposits[i].ToFundingRecord())
+		}
+	}
+	return ret, nil
+}
+
+func (poloniex *Poloniex) GetWithdrawals(currency Currency) ([]FundingRecord, error) {
+	_, withdrawals, err := poloniex.getDepositsWithdrawals()
+	if err != nil {
+		return nil, err
+	}
+
+	symbol := poloniex.adaptCurrency(currency)
+	var ret []FundingRecord
+	for i := range withdrawals {
+		if withdrawals[i].Currency == symbol {
+			ret = append(ret, *withdrawals[i].ToFundingRecord())
+		}
+	}
+	return ret, nil
+}
+
+func (poloniex *Poloniex) GetWithdrawal(currency Currency, withdrawId string) (*FundingRecord, error) {
+	records, err := poloniex.GetWithdrawals(currency)
+	if err != nil {
+		return nil, err
+	}
+	return FindFundingRecord(records, withdrawId)
+}
+
+/**
+ * Poloniex自动扣除提币手续费, 忽略param.Fee. 不同链的币种是不同的币, 不支持param.Chain
+ */
+func (poloniex *Poloniex) CreateWithdrawal(param WithdrawParam) (string, error) {
+	if param.Chain != "" {
+		return "", EX_ERR_NOT_SUPPORTED
+	}
+	params := url.Values{}
+	params.Set("command", "withdraw")
+	params.Set("currency", poloniex.adaptCurrency(param.Currency))
+	params.Set("amount", param.Amount.String())
+	params.Set("address", param.Address)
+	if param.Tag != "" {
+		params.Set("paymentId", param.Tag)
+	}
+
+	var resp struct {
+		Response         string
+		WithdrawalNumber int64
+	}
+	err := poloniex.doTradingRequest(params, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.WithdrawalNumber == 0 {
+		return "", nil
+	}
+	return strconv.FormatInt(resp.WithdrawalNumber, 10), nil
+}
+
+func (poloniex *Poloniex) GetWithdrawalFees(currency Currency) ([]WithdrawFeeDecimal, error) {
+	currencies, err := poloniex.GetCurrencies()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []WithdrawFeeDecimal
+	for symbol, info := range currencies {
+		if currency != UNKNOWN && symbol != poloniex.adaptCurrency(currency) {
+			continue
+		}
+		ret = append(ret, WithdrawFeeDecimal{
+			Currency: NewCurrency(symbol, ""),
+			MinFee:   info.TxFee,
+			MaxFee:   info.TxFee,
+		})
+	}
+	return ret, nil
+}
+
+/**
+ * 支持交易账户(spot)、杠杆账户(margin)和借贷账户(funding)之间的划转, Poloniex不返回划转ID
+ */
+func (poloniex *Poloniex) TransferBetweenWallets(currency Currency, amount decimal.Decimal, from, to WalletType) (string, error) {
+	fromAccount, ok1 := poloniexWallets[from]
+	toAccount, ok2 := poloniexWallets[to]
+	if !ok1 || !ok2 {
+		return "", EX_ERR_NOT_SUPPORTED
+	}
+
+	params := url.Values{}
+	params.Set("command", "transferBalance")
+	params.Set("currency", poloniex.adaptCurrency(currency))
+	params.Set("amount", amount.String())
+	params.Set("fromAccount", fromAccount)
+	params.Set("toAccount", toAccount)
+
+	var resp struct {
+		Success int
+		Message string
+	}
+	err := poloniex.doTradingRequest(params, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.Success != 1 {
+		return "", poloniexError(resp.Message)
+	}
+	return "", nil
+}
@@ -1,6 +1,7 @@
 package poloniex
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	. "github.com/stephenlyu/GoEx"
@@ -24,11 +25,11 @@ type PoloniexMarginPosition struct {
 }
 
 func (poloniex *Poloniex) MarginLimitBuy(amount, price string, currency CurrencyPair) (*Order, error) {
-	return poloniex.placeLimitOrder("marginBuy", amount, price, currency)
+	return poloniex.placeLimitOrder(context.Background(), "marginBuy", amount, price, currency)
 }
 
 func (poloniex *Poloniex) MarginLimitSell(amount, price string, currency CurrencyPair) (*Order, error) {
-	return poloniex.placeLimitOrder("marginSell", amount, price, currency)
+	return poloniex.placeLimitOrder(context.Background(), "marginSell", amount, price, currency)
 }
 
 func (poloniex *Poloniex) GetMarginPosition(currency CurrencyPair) (*PoloniexMarginPosition, error) {
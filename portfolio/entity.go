@@ -0,0 +1,154 @@
+package portfolio
+
+import (
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// Balance Normalized balance of one currency on one venue
+type Balance struct {
+	Exchange  string // Name of the source the balance comes from
+	Currency  goex.Currency
+	Total     decimal.Decimal // Available + Frozen for spot, account rights for futures
+	Available decimal.Decimal
+	Frozen    decimal.Decimal
+	Loan      decimal.Decimal
+	// Equity is the net worth of the balance: Total - Loan for spot, account rights for futures
+	Equity        decimal.Decimal
+	UnrealizedPnl decimal.Decimal
+	IsFuture      bool
+}
+
+// IsZero Whether nothing is held or owed
+func (b *Balance) IsZero() bool {
+	return b.Total.IsZero() && b.Loan.IsZero() && b.Equity.IsZero()
+}
+
+func (b *Balance) changed(o *Balance) bool {
+	return !b.Total.Equal(o.Total) || !b.Available.Equal(o.Available) || !b.Frozen.Equal(o.Frozen) ||
+		!b.Loan.Equal(o.Loan) || !b.Equity.Equal(o.Equity) || !b.UnrealizedPnl.Equal(o.UnrealizedPnl)
+}
+
+// Position A balance valued in the quote currency
+type Position struct {
+	Balance
+	Price decimal.Decimal // Price of the currency in the quote currency, zero if unknown
+	Value decimal.Decimal // Equity * Price
+}
+
+// Snapshot Valued view of all balances at a point in time
+type Snapshot struct {
+	Timestamp  int64 // Milliseconds
+	Quote      goex.Currency
+	Positions  []Position                 // Per venue and currency
+	Currencies map[goex.Currency]Position // Aggregated over venues, Exchange is empty
+	Exchanges  map[string]decimal.Decimal // Total value per venue
+	TotalValue decimal.Decimal
+	Unpriced   []goex.Currency // Currencies held but without a price, excluded from values
+}
+
+// Change A balance change on one venue, Prev is nil for new balances and Cur is nil for removed ones
+type Change struct {
+	Exchange string
+	Currency goex.Currency
+	Prev     *Balance
+	Cur      *Balance
+}
+
+// FromAccount translate goex.Account
+func FromAccount(account *goex.Account) []Balance {
+	var ret []Balance
+	for _, sub := range account.SubAccounts {
+		amount := decimal.NewFromFloat(sub.Amount)
+		frozen := decimal.NewFromFloat(sub.ForzenAmount)
+		loan := decimal.NewFromFloat(sub.LoanAmount)
+		total := amount.Add(frozen)
+		ret = append(ret, Balance{
+			Exchange:  account.Exchange,
+			Currency:  sub.Currency,
+			Total:     total,
+			Available: amount,
+			Frozen:    frozen,
+			Loan:      loan,
+			Equity:    total.Sub(loan),
+		})
+	}
+	return ret
+}
+
+// FromSubAccountDecimal translate goex.SubAccountDecimal
+func FromSubAccountDecimal(exchange string, sub *goex.SubAccountDecimal) Balance {
+	total := sub.Amount
+	if total.IsZero() {
+		total = sub.AvailableAmount.Add(sub.FrozenAmount)
+	}
+	available := sub.AvailableAmount
+	if available.IsZero() && !sub.Amount.IsZero() {
+		available = sub.Amount.Sub(sub.FrozenAmount)
+	}
+	return Balance{
+		Exchange:  exchange,
+		Currency:  sub.Currency,
+		Total:     total,
+		Available: available,
+		Frozen:    sub.FrozenAmount,
+		Loan:      sub.LoanAmount,
+		Equity:    total.Sub(sub.LoanAmount),
+	}
+}
+
+// FromSubAccountDecimals translate []goex.SubAccountDecimal, as returned by biki, zbg, eaex and the like
+func FromSubAccountDecimals(exchange string, subs []goex.SubAccountDecimal) []Balance {
+	ret := make([]Balance, len(subs))
+	for i := range subs {
+		ret[i] = FromSubAccountDecimal(exchange, &subs[i])
+	}
+	return ret
+}
+
+// FromAccountDecimal translate goex.AccountDecimal
+func FromAccountDecimal(account *goex.AccountDecimal) []Balance {
+	var ret []Balance
+	for _, sub := range account.SubAccounts {
+		ret = append(ret, FromSubAccountDecimal(account.Exchange, &sub))
+	}
+	return ret
+}
+
+// FromFutureAccount translate goex.FutureAccount
+func FromFutureAccount(exchange string, account *goex.FutureAccount) []Balance {
+	var ret []Balance
+	for _, sub := range account.FutureSubAccounts {
+		rights := decimal.NewFromFloat(sub.AccountRights)
+		keep := decimal.NewFromFloat(sub.KeepDeposit)
+		ret = append(ret, Balance{
+			Exchange:      exchange,
+			Currency:      sub.Currency,
+			Total:         rights,
+			Available:     rights.Sub(keep),
+			Frozen:        keep,
+			Equity:        rights,
+			UnrealizedPnl: decimal.NewFromFloat(sub.ProfitUnreal),
+			IsFuture:      true,
+		})
+	}
+	return ret
+}
+
+// FromFutureAccountDecimal translate goex.FutureAccountDecimal
+func FromFutureAccountDecimal(exchange string, account *goex.FutureAccountDecimal) []Balance {
+	var ret []Balance
+	for _, sub := range account.FutureSubAccounts {
+		ret = append(ret, Balance{
+			Exchange:      exchange,
+			Currency:      sub.Currency,
+			Total:         sub.AccountRights,
+			Available:     sub.AccountRights.Sub(sub.KeepDeposit),
+			Frozen:        sub.KeepDeposit,
+			Equity:        sub.AccountRights,
+			UnrealizedPnl: sub.ProfitUnreal,
+			IsFuture:      true,
+		})
+	}
+	return ret
+}
@@ -0,0 +1,395 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// BalanceFunc Query all balances of a venue through REST, see the *Source helpers
+type BalanceFunc func() ([]Balance, error)
+
+// AccountSource Adapt a connector returning goex.Account
+func AccountSource(getAccount func() (*goex.Account, error)) BalanceFunc {
+	return func() ([]Balance, error) {
+		account, err := getAccount()
+		if err != nil {
+			return nil, err
+		}
+		return FromAccount(account), nil
+	}
+}
+
+// AccountDecimalSource Adapt a connector returning goex.AccountDecimal
+func AccountDecimalSource(getAccount func() (*goex.AccountDecimal, error)) BalanceFunc {
+	return func() ([]Balance, error) {
+		account, err := getAccount()
+		if err != nil {
+			return nil, err
+		}
+		return FromAccountDecimal(account), nil
+	}
+}
+
+// SubAccountsSource Adapt a connector returning []goex.SubAccountDecimal
+func SubAccountsSource(getAccount func() ([]goex.SubAccountDecimal, error)) BalanceFunc {
+	return func() ([]Balance, error) {
+		subs, err := getAccount()
+		if err != nil {
+			return nil, err
+		}
+		return FromSubAccountDecimals("", subs), nil
+	}
+}
+
+// FutureAccountSource Adapt a connector returning goex.FutureAccount
+func FutureAccountSource(getAccount func() (*goex.FutureAccount, error)) BalanceFunc {
+	return func() ([]Balance, error) {
+		account, err := getAccount()
+		if err != nil {
+			return nil, err
+		}
+		return FromFutureAccount("", account), nil
+	}
+}
+
+// FutureAccountDecimalSource Adapt a connector returning goex.FutureAccountDecimal
+func FutureAccountDecimalSource(getAccount func() (*goex.FutureAccountDecimal, error)) BalanceFunc {
+	return func() ([]Balance, error) {
+		account, err := getAccount()
+		if err != nil {
+			return nil, err
+		}
+		return FromFutureAccountDecimal("", account), nil
+	}
+}
+
+// Portfolio Aggregates balances of several venues, values them in a quote currency, and notifies
+// balance changes and snapshots. Balances are polled from the registered sources or pushed by streams.
+type Portfolio struct {
+	lock     sync.Mutex
+	quote    goex.Currency
+	valuer   *Valuer
+	sources  map[string]BalanceFunc
+	balances map[string]map[goex.Currency]Balance
+	prices   map[goex.Currency]decimal.Decimal
+
+	snapshotHandles []func(*Snapshot)
+	changeHandles   []func(*Change)
+	errorHandle     func(error)
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewPortfolio Portfolio constructor
+func NewPortfolio(quote goex.Currency, valuer *Valuer) *Portfolio {
+	return &Portfolio{
+		quote:    quote,
+		valuer:   valuer,
+		sources:  make(map[string]BalanceFunc),
+		balances: make(map[string]map[goex.Currency]Balance),
+		prices:   make(map[goex.Currency]decimal.Decimal),
+	}
+}
+
+// AddSource Register a venue to poll, name is used as Balance.Exchange
+func (p *Portfolio) AddSource(name string, fetch BalanceFunc) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.sources[name] = fetch
+}
+
+// RemoveSource Stop polling a venue and forget its balances
+func (p *Portfolio) RemoveSource(name string) {
+	p.lock.Lock()
+	delete(p.sources, name)
+	p.lock.Unlock()
+	p.Update(name, nil)
+}
+
+// SubscribeSnapshot Register a handler for snapshots, sent after each refresh or balance change
+func (p *Portfolio) SubscribeSnapshot(handle func(*Snapshot)) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.snapshotHandles = append(p.snapshotHandles, handle)
+}
+
+// SubscribeChange Register a handler for balance changes
+func (p *Portfolio) SubscribeChange(handle func(*Change)) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.changeHandles = append(p.changeHandles, handle)
+}
+
+// SetErrorHandler Register a handler for polling errors
+func (p *Portfolio) SetErrorHandler(handle func(error)) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.errorHandle = handle
+}
+
+// Update Replace all balances of a venue, balances missing from the list are removed
+func (p *Portfolio) Update(name string, balances []Balance) {
+	p.apply(name, balances, true)
+}
+
+// UpdateBalance Update some balances of a venue, e.g. from an account stream
+func (p *Portfolio) UpdateBalance(name string, balances ...Balance) {
+	p.apply(name, balances, false)
+}
+
+// OnSubAccount Handler for account streams pushing goex.SubAccountDecimal, such as okexv3spot
+func (p *Portfolio) OnSubAccount(name string) func(*goex.SubAccountDecimal) {
+	return func(sub *goex.SubAccountDecimal) {
+		p.UpdateBalance(name, FromSubAccountDecimal(name, sub))
+	}
+}
+
+// OnAccountDecimal Handler for account streams pushing goex.AccountDecimal, such as gateio
+func (p *Portfolio) OnAccountDecimal(name string) func(*goex.AccountDecimal) {
+	return func(account *goex.AccountDecimal) {
+		p.UpdateBalance(name, FromAccountDecimal(account)...)
+	}
+}
+
+// OnFutureAccount Handler for account streams pushing goex.FutureAccount, such as bitmex and plo
+func (p *Portfolio) OnFutureAccount(name string) func(*goex.FutureAccount) {
+	return func(account *goex.FutureAccount) {
+		p.UpdateBalance(name, FromFutureAccount(name, account)...)
+	}
+}
+
+func (p *Portfolio) apply(name string, balances []Balance, replace bool) {
+	var changes []*Change
+
+	p.lock.Lock()
+	known := p.balances[name]
+	if known == nil {
+		known = make(map[goex.Currency]Balance)
+		p.balances[name] = known
+	}
+
+	seen := make(map[goex.Currency]bool, len(balances))
+	for _, b := range balances {
+		b.Exchange = name
+		seen[b.Currency] = true
+		prev, ok := known[b.Currency]
+		if b.IsZero() {
+			if ok {
+				delete(known, b.Currency)
+				prevCopy := prev
+				changes = append(changes, &Change{Exchange: name, Currency: b.Currency, Prev: &prevCopy})
+			}
+			continue
+		}
+		known[b.Currency] = b
+		cur := b
+		if !ok {
+			changes = append(changes, &Change{Exchange: name, Currency: b.Currency, Cur: &cur})
+		} else if prev.changed(&b) {
+			prevCopy := prev
+			changes = append(changes, &Change{Exchange: name, Currency: b.Currency, Prev: &prevCopy, Cur: &cur})
+		}
+	}
+
+	if replace {
+		for c, prev := range known {
+			if !seen[c] {
+				delete(known, c)
+				prevCopy := prev
+				changes = append(changes, &Change{Exchange: name, Currency: c, Prev: &prevCopy})
+			}
+		}
+	}
+	if len(known) == 0 {
+		delete(p.balances, name)
+	}
+
+	changeHandles := p.changeHandles
+	snapshotHandles := p.snapshotHandles
+	var snapshot *Snapshot
+	if len(changes) > 0 && len(snapshotHandles) > 0 {
+		snapshot = p.snapshot()
+	}
+	p.lock.Unlock()
+
+	for _, c := range changes {
+		for _, h := range changeHandles {
+			h(c)
+		}
+	}
+	if snapshot != nil {
+		for _, h := range snapshotHandles {
+			h(snapshot)
+		}
+	}
+}
+
+// RefreshPrices Price all held currencies again
+func (p *Portfolio) RefreshPrices() {
+	p.lock.Lock()
+	set := make(map[goex.Currency]bool)
+	for _, balances := range p.balances {
+		for c := range balances {
+			set[c] = true
+		}
+	}
+	p.lock.Unlock()
+
+	currencies := make([]goex.Currency, 0, len(set))
+	for c := range set {
+		currencies = append(currencies, c)
+	}
+	prices := p.valuer.Prices(currencies, p.quote)
+
+	p.lock.Lock()
+	p.prices = prices
+	p.lock.Unlock()
+}
+
+// Refresh Poll all sources and prices, then send a snapshot. Failing sources keep their last balances.
+func (p *Portfolio) Refresh() error {
+	p.lock.Lock()
+	sources := make(map[string]BalanceFunc, len(p.sources))
+	for name, fetch := range p.sources {
+		sources[name] = fetch
+	}
+	p.lock.Unlock()
+
+	var failed []string
+	for name, fetch := range sources {
+		balances, err := fetch()
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", name, err.Error()))
+			continue
+		}
+		p.Update(name, balances)
+	}
+
+	p.RefreshPrices()
+
+	p.lock.Lock()
+	snapshotHandles := p.snapshotHandles
+	snapshot := p.snapshot()
+	p.lock.Unlock()
+	for _, h := range snapshotHandles {
+		h(snapshot)
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("refresh fail, %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Snapshot Current valued view of all balances, using the prices of the last refresh
+func (p *Portfolio) Snapshot() *Snapshot {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.snapshot()
+}
+
+func (p *Portfolio) snapshot() *Snapshot {
+	s := &Snapshot{
+		Timestamp:  time.Now().UnixNano() / int64(time.Millisecond),
+		Quote:      p.quote,
+		Currencies: make(map[goex.Currency]Position),
+		Exchanges:  make(map[string]decimal.Decimal),
+	}
+
+	unpriced := make(map[goex.Currency]bool)
+	for name, balances := range p.balances {
+		for c, b := range balances {
+			pos := Position{Balance: b}
+			if price, ok := p.prices[c]; ok {
+				pos.Price = price
+				pos.Value = b.Equity.Mul(price)
+			} else {
+				unpriced[c] = true
+			}
+			s.Positions = append(s.Positions, pos)
+			s.Exchanges[name] = s.Exchanges[name].Add(pos.Value)
+			s.TotalValue = s.TotalValue.Add(pos.Value)
+
+			agg := s.Currencies[c]
+			agg.Currency = c
+			agg.Price = pos.Price
+			agg.Total = agg.Total.Add(b.Total)
+			agg.Available = agg.Available.Add(b.Available)
+			agg.Frozen = agg.Frozen.Add(b.Frozen)
+			agg.Loan = agg.Loan.Add(b.Loan)
+			agg.Equity = agg.Equity.Add(b.Equity)
+			agg.UnrealizedPnl = agg.UnrealizedPnl.Add(b.UnrealizedPnl)
+			agg.Value = agg.Value.Add(pos.Value)
+			s.Currencies[c] = agg
+		}
+	}
+
+	sort.Slice(s.Positions, func(i, j int) bool {
+		if s.Positions[i].Exchange != s.Positions[j].Exchange {
+			return s.Positions[i].Exchange < s.Positions[j].Exchange
+		}
+		return s.Positions[i].Currency.Symbol < s.Positions[j].Currency.Symbol
+	})
+	for c := range unpriced {
+		s.Unpriced = append(s.Unpriced, c)
+	}
+	sort.Slice(s.Unpriced, func(i, j int) bool {
+		return s.Unpriced[i].Symbol < s.Unpriced[j].Symbol
+	})
+	return s
+}
+
+// Start Poll all sources periodically
+func (p *Portfolio) Start(interval time.Duration) {
+	p.lock.Lock()
+	if p.stopCh != nil {
+		p.lock.Unlock()
+		return
+	}
+	p.stopCh = make(chan struct{})
+	stopCh := p.stopCh
+	p.lock.Unlock()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := p.Refresh(); err != nil {
+				p.lock.Lock()
+				errorHandle := p.errorHandle
+				p.lock.Unlock()
+				if errorHandle != nil {
+					errorHandle(err)
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop Stop polling
+func (p *Portfolio) Stop() {
+	p.lock.Lock()
+	stopCh := p.stopCh
+	p.stopCh = nil
+	p.lock.Unlock()
+
+	if stopCh != nil {
+		close(stopCh)
+	}
+	p.wg.Wait()
+}
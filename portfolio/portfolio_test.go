@@ -0,0 +1,136 @@
+package portfolio
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+	"github.com/stretchr/testify/assert"
+)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func tickers(prices map[string]string) TickerFunc {
+	return func(pair goex.CurrencyPair) (decimal.Decimal, error) {
+		if p, ok := prices[pair.ToSymbol("_")]; ok {
+			return d(p), nil
+		}
+		return decimal.Zero, errors.New("no market")
+	}
+}
+
+func TestValuer_Price(t *testing.T) {
+	v := NewValuer(tickers(map[string]string{
+		"BTC_USDT": "10000",
+		"ETH_BTC":  "0.02",
+		"USDT_XYZ": "4",
+	}))
+
+	price, err := v.Price(goex.BTC, goex.USDT)
+	assert.Nil(t, err)
+	assert.True(t, price.Equal(d("10000")))
+
+	price, err = v.Price(goex.ETH, goex.USDT)
+	assert.Nil(t, err)
+	assert.True(t, price.Equal(d("200")))
+
+	price, err = v.Price(goex.NewCurrency("XYZ", ""), goex.USDT)
+	assert.Nil(t, err)
+	assert.True(t, price.Equal(d("0.25")))
+
+	_, err = v.Price(goex.NewCurrency("ABC", ""), goex.USDT)
+	assert.Equal(t, ErrNoPrice, err)
+
+	v.AddPeg(goex.USD, goex.USDT)
+	price, err = v.Price(goex.USD, goex.USDT)
+	assert.Nil(t, err)
+	assert.True(t, price.Equal(d("1")))
+}
+
+func TestPortfolio_Refresh(t *testing.T) {
+	v := NewValuer(tickers(map[string]string{"BTC_USDT": "10000"}))
+	p := NewPortfolio(goex.USDT, v)
+
+	p.AddSource("spot", func() ([]Balance, error) {
+		return []Balance{
+			{Currency: goex.BTC, Total: d("1.5"), Available: d("1"), Frozen: d("0.5"), Equity: d("1.5")},
+			{Currency: goex.USDT, Total: d("1000"), Available: d("1000"), Loan: d("200"), Equity: d("800")},
+			{Currency: goex.NewCurrency("ABC", ""), Total: d("10"), Equity: d("10")},
+		}, nil
+	})
+	p.AddSource("future", FutureAccountDecimalSource(func() (*goex.FutureAccountDecimal, error) {
+		return &goex.FutureAccountDecimal{
+			FutureSubAccounts: map[goex.Currency]goex.FutureSubAccountDecimal{
+				goex.BTC: {Currency: goex.BTC, AccountRights: d("0.5"), KeepDeposit: d("0.1"), ProfitUnreal: d("0.05")},
+			},
+		}, nil
+	}))
+	p.AddSource("broken", func() ([]Balance, error) {
+		return nil, errors.New("timeout")
+	})
+
+	var snapshots []*Snapshot
+	p.SubscribeSnapshot(func(s *Snapshot) {
+		snapshots = append(snapshots, s)
+	})
+
+	err := p.Refresh()
+	assert.NotNil(t, err)
+
+	s := p.Snapshot()
+	assert.Equal(t, 4, len(s.Positions))
+	assert.Equal(t, "future", s.Positions[0].Exchange)
+	assert.True(t, s.Positions[0].IsFuture)
+	assert.True(t, s.Exchanges["spot"].Equal(d("15800")))
+	assert.True(t, s.Exchanges["future"].Equal(d("5000")))
+	assert.True(t, s.TotalValue.Equal(d("20800")))
+
+	btc := s.Currencies[goex.BTC]
+	assert.True(t, btc.Equity.Equal(d("2")))
+	assert.True(t, btc.Frozen.Equal(d("0.6")))
+	assert.True(t, btc.Value.Equal(d("20000")))
+
+	assert.Equal(t, []goex.Currency{goex.NewCurrency("ABC", "")}, s.Unpriced)
+	assert.NotEmpty(t, snapshots)
+}
+
+func TestPortfolio_Changes(t *testing.T) {
+	p := NewPortfolio(goex.USDT, NewValuer())
+
+	var changes []*Change
+	p.SubscribeChange(func(c *Change) {
+		changes = append(changes, c)
+	})
+
+	p.Update("spot", []Balance{
+		{Currency: goex.BTC, Total: d("1"), Available: d("1"), Equity: d("1")},
+		{Currency: goex.ETH, Total: d("2"), Available: d("2"), Equity: d("2")},
+	})
+	assert.Equal(t, 2, len(changes))
+
+	// unchanged balances are not notified
+	changes = nil
+	p.UpdateBalance("spot", Balance{Currency: goex.BTC, Total: d("1"), Available: d("1"), Equity: d("1")})
+	assert.Equal(t, 0, len(changes))
+
+	p.OnSubAccount("spot")(&goex.SubAccountDecimal{Currency: goex.BTC, AvailableAmount: d("0.4"), FrozenAmount: d("0.6")})
+	assert.Equal(t, 1, len(changes))
+	assert.True(t, changes[0].Prev.Available.Equal(d("1")))
+	assert.True(t, changes[0].Cur.Available.Equal(d("0.4")))
+	assert.True(t, changes[0].Cur.Total.Equal(d("1")))
+
+	// full update removes missing balances
+	changes = nil
+	p.Update("spot", []Balance{{Currency: goex.BTC, Total: d("1"), Available: d("0.4"), Frozen: d("0.6"), Equity: d("1")}})
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, goex.ETH, changes[0].Currency)
+	assert.Nil(t, changes[0].Cur)
+
+	changes = nil
+	p.RemoveSource("spot")
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, 0, len(p.Snapshot().Positions))
+}
@@ -0,0 +1,124 @@
+package portfolio
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// ErrNoPrice No connector has a market to price the currency in the quote currency
+var ErrNoPrice = errors.New("no price")
+
+// TickerFunc Query the last price of a currency pair, e.g. a closure around GetTicker
+type TickerFunc func(pair goex.CurrencyPair) (decimal.Decimal, error)
+
+// FromAPI Use the tickers of a goex.API connector
+func FromAPI(api goex.API) TickerFunc {
+	return func(pair goex.CurrencyPair) (decimal.Decimal, error) {
+		ticker, err := api.GetTicker(pair)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		return decimal.NewFromFloat(ticker.Last), nil
+	}
+}
+
+// FromTickerDecimal Use a connector returning TickerDecimal
+func FromTickerDecimal(getTicker func(pair goex.CurrencyPair) (*goex.TickerDecimal, error)) TickerFunc {
+	return func(pair goex.CurrencyPair) (decimal.Decimal, error) {
+		ticker, err := getTicker(pair)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		return ticker.Last, nil
+	}
+}
+
+// Valuer Prices currencies in a quote currency with the tickers of one or more connectors.
+// A currency is priced directly, through the inverse pair, or through a bridge currency such as BTC.
+type Valuer struct {
+	lock    sync.Mutex
+	tickers []TickerFunc
+	bridges []goex.Currency
+	pegs    map[goex.Currency]goex.Currency
+}
+
+// NewValuer Valuer constructor, tickers are tried in order
+func NewValuer(tickers ...TickerFunc) *Valuer {
+	return &Valuer{
+		tickers: tickers,
+		bridges: []goex.Currency{goex.USDT, goex.BTC, goex.ETH},
+		pegs:    make(map[goex.Currency]goex.Currency),
+	}
+}
+
+// SetBridges Replace the bridge currencies, default USDT, BTC, ETH
+func (v *Valuer) SetBridges(bridges ...goex.Currency) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.bridges = bridges
+}
+
+// AddPeg Value currency one to one in to, e.g. USD to USDT
+func (v *Valuer) AddPeg(currency, to goex.Currency) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.pegs[currency] = to
+}
+
+func (v *Valuer) pegged(a, b goex.Currency) bool {
+	return a == b || v.pegs[a] == b || v.pegs[b] == a
+}
+
+func (v *Valuer) direct(currency, quote goex.Currency) (decimal.Decimal, bool) {
+	if v.pegged(currency, quote) {
+		return decimal.New(1, 0), true
+	}
+	for _, t := range v.tickers {
+		if price, err := t(goex.NewCurrencyPair(currency, quote)); err == nil && price.IsPositive() {
+			return price, true
+		}
+		if price, err := t(goex.NewCurrencyPair(quote, currency)); err == nil && price.IsPositive() {
+			return decimal.New(1, 0).DivRound(price, 16), true
+		}
+	}
+	return decimal.Zero, false
+}
+
+// Price Price of currency in quote
+func (v *Valuer) Price(currency, quote goex.Currency) (decimal.Decimal, error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if price, ok := v.direct(currency, quote); ok {
+		return price, nil
+	}
+	for _, bridge := range v.bridges {
+		if bridge == currency || bridge == quote {
+			continue
+		}
+		p1, ok := v.direct(currency, bridge)
+		if !ok {
+			continue
+		}
+		p2, ok := v.direct(bridge, quote)
+		if !ok {
+			continue
+		}
+		return p1.Mul(p2), nil
+	}
+	return decimal.Zero, ErrNoPrice
+}
+
+// Prices Price a set of currencies in quote, currencies without a price are left out
+func (v *Valuer) Prices(currencies []goex.Currency, quote goex.Currency) map[goex.Currency]decimal.Decimal {
+	ret := make(map[goex.Currency]decimal.Decimal, len(currencies))
+	for _, c := range currencies {
+		if price, err := v.Price(c, quote); err == nil {
+			ret[c] = price
+		}
+	}
+	return ret
+}
@@ -0,0 +1,47 @@
+package router
+
+import (
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+)
+
+// Venue A spot connector orders can be routed to, Name must match the venue name in the consolidated book
+type Venue struct {
+	Name       string
+	API        goex.API
+	Pair       goex.CurrencyPair // Pair as the connector expects it
+	FeeRate    decimal.Decimal   // Taker fee rate
+	Instrument *goex.Instrument  // Optional, child orders are rounded and checked against its rules
+}
+
+// ParentOrder Order to be split, Side is BUY or SELL and Amount is in the base currency
+type ParentOrder struct {
+	Side       goex.TradeSide
+	Amount     decimal.Decimal
+	LimitPrice decimal.Decimal // Worst venue price accepted, before fees
+}
+
+// ChildOrder Part of a parent order sent to one venue as a marketable limit order
+type ChildOrder struct {
+	Venue  string
+	Price  decimal.Decimal // Worst book price the child sweeps
+	Amount decimal.Decimal
+	Cost   decimal.Decimal // Expected fee-inclusive quote amount
+
+	Order *goex.Order // Latest known state after placement
+	Err   error
+}
+
+// Plan Planned split of a parent order
+type Plan struct {
+	Parent   ParentOrder
+	Children []ChildOrder
+	Unrouted decimal.Decimal // Amount without liquidity, balance or within the order rules
+}
+
+// Report Result of executing a plan
+type Report struct {
+	Plan
+	Filled   decimal.Decimal // Total filled base amount
+	AvgPrice decimal.Decimal // Volume weighted fill price, zero when nothing filled
+}
@@ -0,0 +1,338 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+	"github.com/stephenlyu/GoEx/consolidated"
+)
+
+var ErrNoVenue = errors.New("no venue to route to")
+
+// Router Splits parent orders across venues by the liquidity of a consolidated book.
+// Book levels are consumed in fee-inclusive price order, each venue gets at most one child order
+// priced at the worst level it sweeps; with UseBalances children are capped by the account balances.
+type Router struct {
+	Book         *consolidated.Book
+	UseBalances  bool          // Query GetAccount on every venue before planning
+	FillTimeout  time.Duration // How long Execute polls child orders for fills, zero returns right after placement and leaves them working
+	PollInterval time.Duration
+
+	lock   sync.RWMutex
+	venues map[string]*Venue
+}
+
+// NewRouter Router constructor
+func NewRouter(book *consolidated.Book) *Router {
+	return &Router{
+		Book:         book,
+		UseBalances:  true,
+		PollInterval: 500 * time.Millisecond,
+		venues:       make(map[string]*Venue),
+	}
+}
+
+// AddVenue Add or replace a venue
+func (r *Router) AddVenue(venue Venue) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	v := venue
+	r.venues[venue.Name] = &v
+}
+
+// RemoveVenue Stop routing to a venue
+func (r *Router) RemoveVenue(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.venues, name)
+}
+
+func (r *Router) venueList() map[string]*Venue {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	ret := make(map[string]*Venue, len(r.venues))
+	for name, v := range r.venues {
+		ret[name] = v
+	}
+	return ret
+}
+
+func isBuy(side goex.TradeSide) bool {
+	return side == goex.BUY || side == goex.BUY_MARKET
+}
+
+func available(acc *goex.Account, currency goex.Currency) decimal.Decimal {
+	for c, sub := range acc.SubAccounts {
+		if strings.EqualFold(c.Symbol, currency.Symbol) {
+			return decimal.NewFromFloat(sub.Amount)
+		}
+	}
+	return decimal.Zero
+}
+
+// capacities Spendable balance per venue: quote currency for buys, base currency for sells.
+// Venues whose account query fails get no capacity
+func (r *Router) capacities(venues map[string]*Venue, buy bool) map[string]decimal.Decimal {
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	ret := make(map[string]decimal.Decimal, len(venues))
+	for name, v := range venues {
+		wg.Add(1)
+		go func(name string, v *Venue) {
+			defer wg.Done()
+			acc, err := v.API.GetAccount()
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				ret[name] = decimal.Zero
+				return
+			}
+			if buy {
+				ret[name] = available(acc, v.Pair.CurrencyB)
+			} else {
+				ret[name] = available(acc, v.Pair.CurrencyA)
+			}
+		}(name, v)
+	}
+	wg.Wait()
+	return ret
+}
+
+type quote struct {
+	venue     *Venue
+	price     decimal.Decimal
+	amount    decimal.Decimal
+	effective decimal.Decimal
+}
+
+// PlanOrder Split a parent order without placing it
+func (r *Router) PlanOrder(parent ParentOrder) (Plan, error) {
+	plan := Plan{Parent: parent, Unrouted: parent.Amount}
+	if (parent.Side != goex.BUY && parent.Side != goex.SELL) || parent.Amount.Sign() <= 0 || parent.LimitPrice.Sign() < 0 {
+		return plan, fmt.Errorf("invalid parent order %s %s@%s: %w", parent.Side, parent.Amount, parent.LimitPrice, goex.ERR_INVALID_PARAM)
+	}
+	venues := r.venueList()
+	if len(venues) == 0 {
+		return plan, ErrNoVenue
+	}
+	buy := isBuy(parent.Side)
+
+	var capacity map[string]decimal.Decimal
+	if r.UseBalances {
+		capacity = r.capacities(venues, buy)
+	}
+
+	snapshot := r.Book.Snapshot(0)
+	levels := snapshot.Bids
+	if buy {
+		levels = snapshot.Asks
+	}
+	one := decimal.New(1, 0)
+	var quotes []quote
+	for _, level := range levels {
+		for _, vq := range level.Venues {
+			v, ok := venues[vq.Venue]
+			if !ok {
+				continue
+			}
+			if parent.LimitPrice.Sign() > 0 {
+				if (buy && vq.Price.GreaterThan(parent.LimitPrice)) || (!buy && vq.Price.LessThan(parent.LimitPrice)) {
+					continue
+				}
+			}
+			q := quote{venue: v, price: vq.Price, amount: vq.Amount}
+			if buy {
+				q.effective = vq.Price.Mul(one.Add(v.FeeRate))
+			} else {
+				q.effective = vq.Price.Mul(one.Sub(v.FeeRate))
+			}
+			quotes = append(quotes, q)
+		}
+	}
+	sort.SliceStable(quotes, func(i, j int) bool {
+		if !quotes[i].effective.Equal(quotes[j].effective) {
+			return buy == quotes[i].effective.LessThan(quotes[j].effective)
+		}
+		return quotes[i].venue.Name < quotes[j].venue.Name
+	})
+
+	children := make(map[string]*ChildOrder)
+	var order []string
+	remain := parent.Amount
+	for _, q := range quotes {
+		if remain.Sign() <= 0 {
+			break
+		}
+		take := decimal.Min(remain, q.amount)
+		if capacity != nil {
+			left := capacity[q.venue.Name]
+			if buy {
+				// Buys spend the fee-inclusive quote amount
+				if most := left.Div(q.effective); most.LessThan(take) {
+					take = most
+				}
+			} else if left.LessThan(take) {
+				take = left
+			}
+		}
+		if take.Sign() <= 0 {
+			continue
+		}
+		if capacity != nil {
+			if buy {
+				capacity[q.venue.Name] = capacity[q.venue.Name].Sub(take.Mul(q.effective))
+			} else {
+				capacity[q.venue.Name] = capacity[q.venue.Name].Sub(take)
+			}
+		}
+
+		child, ok := children[q.venue.Name]
+		if !ok {
+			child = &ChildOrder{Venue: q.venue.Name}
+			children[q.venue.Name] = child
+			order = append(order, q.venue.Name)
+		}
+		child.Amount = child.Amount.Add(take)
+		child.Price = q.price
+		child.Cost = child.Cost.Add(take.Mul(q.effective))
+		remain = remain.Sub(take)
+	}
+
+	for _, name := range order {
+		child := children[name]
+		if inst := venues[name].Instrument; inst != nil {
+			price, amount, err := inst.PrepareLimitOrder(parent.Side, child.Price, child.Amount)
+			if err != nil {
+				continue
+			}
+			child.Cost = child.Cost.Mul(amount).Div(child.Amount)
+			child.Price, child.Amount = price, amount
+		}
+		plan.Children = append(plan.Children, *child)
+		plan.Unrouted = plan.Unrouted.Sub(child.Amount)
+	}
+	return plan, nil
+}
+
+func orderId(o *goex.Order) string {
+	if o.OrderID2 != "" {
+		return o.OrderID2
+	}
+	return strconv.Itoa(o.OrderID)
+}
+
+func isFinal(o *goex.Order) bool {
+	return o.Status == goex.ORDER_FINISH || o.Status == goex.ORDER_CANCEL || o.Status == goex.ORDER_REJECT
+}
+
+// Execute Plan a parent order and place the children concurrently. Placement errors are reported per child;
+// with FillTimeout the children are polled until they are final or the timeout expires, children still working then
+// are cancelled and queried once more. A failed cancel is reported in the child's Err and the order may stay live
+func (r *Router) Execute(parent ParentOrder) (*Report, error) {
+	plan, err := r.PlanOrder(parent)
+	if err != nil {
+		return nil, err
+	}
+	venues := r.venueList()
+	report := &Report{Plan: plan}
+
+	var wg sync.WaitGroup
+	for i := range report.Children {
+		wg.Add(1)
+		go func(child *ChildOrder) {
+			defer wg.Done()
+			v := venues[child.Venue]
+			if v == nil {
+				child.Err = ErrNoVenue
+				return
+			}
+			if isBuy(parent.Side) {
+				child.Order, child.Err = v.API.LimitBuy(child.Amount.String(), child.Price.String(), v.Pair)
+			} else {
+				child.Order, child.Err = v.API.LimitSell(child.Amount.String(), child.Price.String(), v.Pair)
+			}
+		}(&report.Children[i])
+	}
+	wg.Wait()
+
+	if r.FillTimeout > 0 {
+		r.pollFills(venues, report.Children)
+		r.cancelPending(venues, report.Children)
+	}
+
+	notional := decimal.Zero
+	for _, child := range report.Children {
+		if child.Order == nil {
+			continue
+		}
+		filled := decimal.NewFromFloat(child.Order.DealAmount)
+		report.Filled = report.Filled.Add(filled)
+		notional = notional.Add(filled.Mul(decimal.NewFromFloat(child.Order.AvgPrice)))
+	}
+	if report.Filled.Sign() > 0 {
+		report.AvgPrice = notional.Div(report.Filled)
+	}
+	return report, nil
+}
+
+func (r *Router) pollFills(venues map[string]*Venue, children []ChildOrder) {
+	deadline := time.Now().Add(r.FillTimeout)
+	for {
+		var wg sync.WaitGroup
+		pending := 0
+		for i := range children {
+			child := &children[i]
+			if child.Order == nil || isFinal(child.Order) {
+				continue
+			}
+			pending++
+			wg.Add(1)
+			go func(child *ChildOrder) {
+				defer wg.Done()
+				v := venues[child.Venue]
+				order, err := v.API.GetOneOrder(orderId(child.Order), v.Pair)
+				if err == nil && order != nil {
+					child.Order = order
+				}
+			}(child)
+		}
+		wg.Wait()
+
+		if pending == 0 || !time.Now().Add(r.PollInterval).Before(deadline) {
+			return
+		}
+		time.Sleep(r.PollInterval)
+	}
+}
+
+// Cancel the children that are not final and refresh them so late fills are counted
+func (r *Router) cancelPending(venues map[string]*Venue, children []ChildOrder) {
+	var wg sync.WaitGroup
+	for i := range children {
+		child := &children[i]
+		if child.Order == nil || isFinal(child.Order) {
+			continue
+		}
+		wg.Add(1)
+		go func(child *ChildOrder) {
+			defer wg.Done()
+			v := venues[child.Venue]
+			id := orderId(child.Order)
+			if _, err := v.API.CancelOrder(id, v.Pair); err != nil {
+				child.Err = fmt.Errorf("cancel order %s: %w", id, err)
+			}
+			order, err := v.API.GetOneOrder(id, v.Pair)
+			if err == nil && order != nil {
+				child.Order = order
+			}
+		}(child)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,126 @@
+package router
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	goex "github.com/stephenlyu/GoEx"
+	"github.com/stephenlyu/GoEx/consolidated"
+	"github.com/stephenlyu/GoEx/internal/testapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func testBook() *consolidated.Book {
+	book := consolidated.NewBook(goex.BTC_USDT)
+	book.OnDepth("a", &goex.DepthDecimal{
+		AskList: goex.DepthRecordsDecimal{{Price: dec("100"), Amount: dec("1")}, {Price: dec("102"), Amount: dec("5")}},
+		BidList: goex.DepthRecordsDecimal{{Price: dec("99"), Amount: dec("2")}},
+	})
+	book.OnDepth("b", &goex.DepthDecimal{
+		AskList: goex.DepthRecordsDecimal{{Price: dec("100"), Amount: dec("1")}, {Price: dec("101"), Amount: dec("2")}},
+		BidList: goex.DepthRecordsDecimal{{Price: dec("98"), Amount: dec("2")}},
+	})
+	return book
+}
+
+func TestRouter_PlanOrder(t *testing.T) {
+	r := NewRouter(testBook())
+	r.AddVenue(Venue{Name: "a", API: &testapi.FakeAPI{Balance: map[goex.Currency]float64{goex.USDT: 1000}}, Pair: goex.BTC_USDT, FeeRate: dec("0.002")})
+	r.AddVenue(Venue{Name: "b", API: &testapi.FakeAPI{Balance: map[goex.Currency]float64{goex.USDT: 1000}}, Pair: goex.BTC_USDT, FeeRate: dec("0.001")})
+
+	// b is cheaper at 100 after fees, then a@100 (100.2) beats b@101 (101.101)
+	plan, err := r.PlanOrder(ParentOrder{Side: goex.BUY, Amount: dec("3"), LimitPrice: dec("101")})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(plan.Children))
+	assert.Equal(t, "b", plan.Children[0].Venue)
+	assert.Equal(t, "2", plan.Children[0].Amount.String())
+	assert.Equal(t, "101", plan.Children[0].Price.String())
+	assert.Equal(t, "a", plan.Children[1].Venue)
+	assert.Equal(t, "1", plan.Children[1].Amount.String())
+	assert.Equal(t, "100.2", plan.Children[1].Cost.String())
+	assert.True(t, plan.Unrouted.IsZero())
+
+	// The limit price leaves part of the order unrouted
+	plan, err = r.PlanOrder(ParentOrder{Side: goex.BUY, Amount: dec("5"), LimitPrice: dec("100")})
+	assert.Nil(t, err)
+	assert.Equal(t, "3", plan.Unrouted.String())
+
+	// Balances cap the children: a has base currency for 0.5 only
+	r.AddVenue(Venue{Name: "a", API: &testapi.FakeAPI{Balance: map[goex.Currency]float64{goex.BTC: 0.5}}, Pair: goex.BTC_USDT})
+	plan, err = r.PlanOrder(ParentOrder{Side: goex.SELL, Amount: dec("3")})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(plan.Children))
+	assert.Equal(t, "a", plan.Children[0].Venue)
+	assert.Equal(t, "0.5", plan.Children[0].Amount.String())
+	assert.Equal(t, "2.5", plan.Unrouted.String())
+
+	// Instrument rules round the children
+	inst := goex.Instrument{Pair: goex.BTC_USDT, Type: goex.INSTRUMENT_SPOT, TickSize: dec("1"), LotSize: dec("0.3")}
+	r.AddVenue(Venue{Name: "a", API: &testapi.FakeAPI{Balance: map[goex.Currency]float64{goex.BTC: 0.5}}, Pair: goex.BTC_USDT, Instrument: &inst})
+	plan, err = r.PlanOrder(ParentOrder{Side: goex.SELL, Amount: dec("3")})
+	assert.Nil(t, err)
+	assert.Equal(t, "0.3", plan.Children[0].Amount.String())
+	assert.Equal(t, "2.7", plan.Unrouted.String())
+
+	_, err = r.PlanOrder(ParentOrder{Side: goex.BUY_MARKET, Amount: dec("1")})
+	assert.True(t, errors.Is(err, goex.ERR_INVALID_PARAM))
+}
+
+func TestRouter_Execute(t *testing.T) {
+	r := NewRouter(testBook())
+	r.UseBalances = false
+	r.FillTimeout = time.Second
+	r.PollInterval = time.Millisecond
+	a := &testapi.FakeAPI{Fill: true}
+	b := &testapi.FakeAPI{PlaceErr: errors.New("rejected")}
+	r.AddVenue(Venue{Name: "a", API: a, Pair: goex.BTC_USDT})
+	r.AddVenue(Venue{Name: "b", API: b, Pair: goex.BTC_USDT})
+
+	report, err := r.Execute(ParentOrder{Side: goex.BUY, Amount: dec("2"), LimitPrice: dec("100")})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(report.Children))
+	assert.Equal(t, []string{"1@100"}, a.Placed())
+	assert.Equal(t, 1, a.Queries())
+	for _, child := range report.Children {
+		if child.Venue == "b" {
+			assert.NotNil(t, child.Err)
+		} else {
+			assert.Nil(t, child.Err)
+			assert.Equal(t, goex.TradeStatus(goex.ORDER_FINISH), child.Order.Status)
+		}
+	}
+	assert.Equal(t, "1", report.Filled.String())
+	assert.Equal(t, "100", report.AvgPrice.String())
+}
+
+func TestRouter_ExecuteCancelsAfterTimeout(t *testing.T) {
+	r := NewRouter(testBook())
+	r.UseBalances = false
+	r.FillTimeout = 20 * time.Millisecond
+	r.PollInterval = time.Millisecond
+	a := &testapi.FakeAPI{}
+	r.AddVenue(Venue{Name: "a", API: a, Pair: goex.BTC_USDT})
+
+	report, err := r.Execute(ParentOrder{Side: goex.BUY, Amount: dec("1"), LimitPrice: dec("100")})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(report.Children))
+	assert.Equal(t, []string{"1"}, a.Cancelled())
+	assert.Nil(t, report.Children[0].Err)
+	assert.Equal(t, goex.TradeStatus(goex.ORDER_CANCEL), report.Children[0].Order.Status)
+	assert.True(t, report.Filled.IsZero())
+
+	// Without FillTimeout the children are left working
+	r.FillTimeout = 0
+	a = &testapi.FakeAPI{}
+	r.AddVenue(Venue{Name: "a", API: a, Pair: goex.BTC_USDT})
+	report, err = r.Execute(ParentOrder{Side: goex.BUY, Amount: dec("1"), LimitPrice: dec("100")})
+	assert.Nil(t, err)
+	assert.Empty(t, a.Cancelled())
+	assert.Equal(t, goex.TradeStatus(goex.ORDER_UNFINISH), report.Children[0].Order.Status)
+}
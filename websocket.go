@@ -2,26 +2,30 @@ package goex
 
 import (
 	"github.com/gorilla/websocket"
-	"log"
 	"time"
 	"io/ioutil"
 	"sync/atomic"
 	"errors"
+	"reflect"
+	"sync"
 )
 
 type WsConn struct {
 	*websocket.Conn
 	url                      string
+	exchange                 string
 	heartbeatIntervalTime    time.Duration
 	checkConnectIntervalTime time.Duration
 	actived                  time.Time
 	close                    chan int
 	isClose                  bool
 	subs                     []interface{}
+	subsLock                 sync.Mutex
 	loginFunc                func() error
 
 	errorCh                  chan error
 	errorHandler             func(error)
+	logger                   Logger
 
 	reconnecting 			 int32
 }
@@ -38,27 +42,28 @@ const (
 )
 
 func NewWsConn(wsurl string) *WsConn {
+	exchange := exchangeOfUrl(wsurl)
 	wsConn, resp, err := websocket.DefaultDialer.Dial(wsurl, nil)
+	notifyWsConnect(exchange, wsurl, err)
 	if err != nil {
 		if resp != nil {
-			log.Println(resp.Header)
-			log.Println(resp.Status)
 			bytes, _ := ioutil.ReadAll(resp.Body)
-			log.Println(string(bytes))
+			DefaultLogger().Error("dial websocket failed", "url", wsurl, "status", resp.Status, "body", string(bytes))
 		}
 		panic(err)
 	}
-	return &WsConn{Conn: wsConn, url: wsurl, actived: time.Now(), checkConnectIntervalTime: 30 * time.Second, close: make(chan int, 1), errorCh: make(chan error)}
+	return &WsConn{Conn: wsConn, url: wsurl, exchange: exchange, actived: time.Now(), checkConnectIntervalTime: 30 * time.Second, close: make(chan int, 1), errorCh: make(chan error)}
 }
 
 func (ws *WsConn) ReConnect() {
 
 	tryReconnect := func() error {
 		ws.Close()
-		log.Println("start reconnect websocket:", ws.url)
+		ws.log().Info("start reconnect websocket", "url", ws.url)
 		wsConn, _, err := websocket.DefaultDialer.Dial(ws.url, nil)
+		notifyWsReconnect(ws.exchange, ws.url, err)
 		if err != nil {
-			log.Println("reconnect fail ???")
+			ws.log().Warn("reconnect websocket failed", "url", ws.url, "err", err)
 			return err
 		} else {
 			ws.Conn = wsConn
@@ -67,17 +72,20 @@ func (ws *WsConn) ReConnect() {
 			if ws.loginFunc != nil {
 				err := ws.doLogin()
 				if err != nil {
-					log.Printf("login fail, error: %+v", err)
+					ws.log().Warn("websocket login failed", "url", ws.url, "err", err)
 					return err
 				}
 			}
 
 			//re subscribe
-			for _, sub := range ws.subs {
-				log.Println("subscribe:", sub)
+			ws.subsLock.Lock()
+			subs := append([]interface{}(nil), ws.subs...)
+			ws.subsLock.Unlock()
+			for _, sub := range subs {
+				ws.log().Debug("resubscribe", "url", ws.url, "sub", sub)
 				err := ws.WriteJSON(sub)
 				if err != nil {
-					log.Printf("subscribe %s fail, error: %+v", sub, err)
+					ws.log().Warn("resubscribe failed", "url", ws.url, "sub", sub, "err", err)
 					return err
 				}
 			}
@@ -131,7 +139,7 @@ func (ws *WsConn) ReConnect() {
 				timer.Reset(ws.checkConnectIntervalTime)
 			case <-ws.close:
 				timer.Stop()
-				log.Println("close websocket connect, exiting reconnect goroutine.")
+				ws.log().Debug("close websocket connect, exiting reconnect goroutine", "url", ws.url)
 				return
 			}
 		}
@@ -156,13 +164,13 @@ func (ws *WsConn) Heartbeat(heartbeat func() interface{}, interval time.Duration
 				}
 				ws.errorCh <- err
 				if err != nil {
-					log.Println("heartbeat error , ", err)
+					ws.log().Warn("heartbeat error", "url", ws.url, "err", err)
 					time.Sleep(time.Second)
 				}
 				timer.Reset(interval)
 			case <-ws.close:
 				timer.Stop()
-				log.Println("close websocket connect , exiting heartbeat goroutine.")
+				ws.log().Debug("close websocket connect, exiting heartbeat goroutine", "url", ws.url)
 				return
 			}
 		}
@@ -182,13 +190,13 @@ func (ws *WsConn) HeartbeatEx(heartbeat func() (int, string), interval time.Dura
 				err := ws.WriteMessage(t, []byte(data))
 				ws.errorCh <- err
 				if err != nil {
-					log.Println("heartbeat error , ", err)
+					ws.log().Warn("heartbeat error", "url", ws.url, "err", err)
 					time.Sleep(time.Second)
 				}
 				timer.Reset(interval)
 			case <-ws.close:
 				timer.Stop()
-				log.Println("close websocket connect , exiting heartbeat goroutine.")
+				ws.log().Debug("close websocket connect, exiting heartbeat goroutine", "url", ws.url)
 				return
 			}
 		}
@@ -200,10 +208,27 @@ func (ws *WsConn) Subscribe(subEvent interface{}) error {
 	if err != nil {
 		return err
 	}
+	ws.subsLock.Lock()
 	ws.subs = append(ws.subs, subEvent)
+	ws.subsLock.Unlock()
 	return nil
 }
 
+/**
+ * 取消订阅, subEvent为Subscribe时发送的内容, 取消后重连时不再重新订阅
+ */
+func (ws *WsConn) Unsubscribe(unsubEvent, subEvent interface{}) error {
+	ws.subsLock.Lock()
+	for i, sub := range ws.subs {
+		if reflect.DeepEqual(sub, subEvent) {
+			ws.subs = append(ws.subs[:i:i], ws.subs[i+1:]...)
+			break
+		}
+	}
+	ws.subsLock.Unlock()
+	return ws.WriteJSON(unsubEvent)
+}
+
 func (ws *WsConn) SendMessage(data interface{}) error {
 	return ws.WriteJSON(data)
 }
@@ -228,9 +253,9 @@ func (ws *WsConn) ReceiveMessage(handle func(msg []byte)) {
 				continue
 			}
 			if err != nil {
-				log.Println(err)
+				ws.log().Warn("read websocket message error", "url", ws.url, "err", err)
 				if ws.isClose {
-					log.Println("exiting receive message goroutine.")
+					ws.log().Debug("exiting receive message goroutine", "url", ws.url)
 					break
 				}
 				time.Sleep(time.Second)
@@ -238,6 +263,7 @@ func (ws *WsConn) ReceiveMessage(handle func(msg []byte)) {
 			}
 			switch t {
 			case websocket.TextMessage, websocket.BinaryMessage:
+				notifyWsMessage(ws.exchange, ws.url, len(msg))
 				handle(msg)
 			case websocket.PongMessage:
 				ws.actived = time.Now()
@@ -245,7 +271,7 @@ func (ws *WsConn) ReceiveMessage(handle func(msg []byte)) {
 				ws.CloseWs()
 				return
 			default:
-				log.Println("error websocket message type , content is :\n", string(msg))
+				ws.log().Warn("unexpected websocket message type", "url", ws.url, "type", t, "content", string(msg))
 			}
 		}
 	}()
@@ -262,9 +288,9 @@ func (ws *WsConn) ReceiveMessageEx(handle func(isBin bool, msg []byte)) {
 				continue
 			}
 			if err != nil {
-				log.Println(err)
+				ws.log().Warn("read websocket message error", "url", ws.url, "err", err)
 				if ws.isClose {
-					log.Println("exiting receive message goroutine.")
+					ws.log().Debug("exiting receive message goroutine", "url", ws.url)
 					break
 				}
 				time.Sleep(time.Second)
@@ -272,8 +298,10 @@ func (ws *WsConn) ReceiveMessageEx(handle func(isBin bool, msg []byte)) {
 			}
 			switch t {
 			case websocket.TextMessage:
+				notifyWsMessage(ws.exchange, ws.url, len(msg))
 				handle(false, msg)
 			case websocket.BinaryMessage:
+				notifyWsMessage(ws.exchange, ws.url, len(msg))
 				handle(true, msg)
 			case websocket.PongMessage:
 				ws.actived = time.Now()
@@ -281,7 +309,7 @@ func (ws *WsConn) ReceiveMessageEx(handle func(isBin bool, msg []byte)) {
 				ws.CloseWs()
 				return
 			default:
-				log.Println("error websocket message type , content is :\n", string(msg))
+				ws.log().Warn("unexpected websocket message type", "url", ws.url, "type", t, "content", string(msg))
 			}
 		}
 	}()
@@ -299,7 +327,7 @@ func (ws *WsConn) CloseWs() {
 
 	err := ws.Close()
 	if err != nil {
-		log.Println("close websocket connect error , ", err)
+		ws.log().Warn("close websocket connect error", "url", ws.url, "err", err)
 	}
 
 	ws.isClose = true
@@ -309,6 +337,20 @@ func (ws *WsConn) SetErrorHandler(handler func (error)) {
 	ws.errorHandler = handler
 }
 
+/**
+ * 设置连接的日志, 为nil时使用DefaultLogger
+ */
+func (ws *WsConn) SetLogger(logger Logger) {
+	if logger != nil {
+		logger = NewRedactingLogger(logger)
+	}
+	ws.logger = logger
+}
+
+func (ws *WsConn) log() Logger {
+	return LoggerOr(ws.logger)
+}
+
 func (ws *WsConn) isReconnecting() bool {
 	return atomic.LoadInt32(&ws.reconnecting) == 1
 }
\ No newline at end of file
@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/http"
 	"strings"
+
+	"github.com/shopspring/decimal"
 )
 
 //https://wex.nz
@@ -59,6 +61,46 @@ func (wex *Wex) GetAccount() (*Account, error) {
 	panic("not implements")
 }
 
+/**
+ * 交易品种来自info, hidden为1的交易对不在页面显示, 当作暂停交易
+ */
+func (wex *Wex) GetInstruments() ([]Instrument, error) {
+	var resp struct {
+		Pairs map[string]struct {
+			DecimalPlaces int32           `json:"decimal_places"`
+			MinAmount     decimal.Decimal `json:"min_amount"`
+			Hidden        int
+		}
+	}
+	err := HttpGet4(wex.client, baseurl+"/info", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Instrument, 0, len(resp.Pairs))
+	for symbol, r := range resp.Pairs {
+		pair := NewCurrencyPair2(strings.ToUpper(symbol))
+		if pair == UNKNOWN_PAIR {
+			continue
+		}
+		inst := Instrument{
+			Exchange:       WEX_NZ,
+			Symbol:         symbol,
+			Pair:           pair,
+			Type:           INSTRUMENT_SPOT,
+			PricePrecision: r.DecimalPlaces,
+			MinQty:         r.MinAmount,
+			Status:         INSTRUMENT_STATUS_TRADING,
+		}
+		if r.Hidden != 0 {
+			inst.Status = INSTRUMENT_STATUS_SUSPENDED
+		}
+		inst.FillPrecision()
+		ret = append(ret, inst)
+	}
+	return ret, nil
+}
+
 func (wex *Wex) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	respmap, err := HttpGet(wex.client, baseurl+"/ticker/"+strings.ToLower(currency.ToSymbol("_")))
 	if err != nil {
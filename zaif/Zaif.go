@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+
+	"github.com/shopspring/decimal"
 )
 
 type Zaif struct {
@@ -29,6 +31,42 @@ func (zf *Zaif) GetExchangeName() string {
 	return "zaif.jp"
 }
 
+/**
+ * 交易品种来自currency_pairs/all, aux_unit_step为价格最小变动, item_unit_step为数量最小变动
+ */
+func (zf *Zaif) GetInstruments() ([]Instrument, error) {
+	var resp []struct {
+		CurrencyPair string          `json:"currency_pair"`
+		AuxUnitStep  decimal.Decimal `json:"aux_unit_step"`
+		ItemUnitStep decimal.Decimal `json:"item_unit_step"`
+		ItemUnitMin  decimal.Decimal `json:"item_unit_min"`
+	}
+	err := HttpGet4(zf.client, zf.baseUrl+"1/currency_pairs/all", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Instrument, 0, len(resp))
+	for _, r := range resp {
+		pair := NewCurrencyPair2(strings.ToUpper(r.CurrencyPair))
+		if pair == UNKNOWN_PAIR {
+			continue
+		}
+		inst := Instrument{
+			Exchange: zf.GetExchangeName(),
+			Symbol:   r.CurrencyPair,
+			Pair:     pair,
+			Type:     INSTRUMENT_SPOT,
+			TickSize: r.AuxUnitStep,
+			LotSize:  r.ItemUnitStep,
+			MinQty:   r.ItemUnitMin,
+		}
+		inst.FillPrecision()
+		ret = append(ret, inst)
+	}
+	return ret, nil
+}
+
 func (zf *Zaif) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	tickerUrl := fmt.Sprintf(zf.baseUrl+"1/ticker/%s_jpy", strings.ToLower(currency.CurrencyA.Symbol))
 	//println(tickerUrl)
@@ -140,8 +178,11 @@ func (zf *Zaif) GetAccount() (*Account, error) {
 	return nil, nil
 }
 
+/**
+ * 交易所没有公开的K线接口
+ */
 func (zf *Zaif) GetKlineRecords(currency CurrencyPair , period int, size, since int) ([]Kline, error) {
-	return nil, nil
+	return nil, EX_ERR_NOT_SUPPORTED
 }
 
 //非个人，整个交易所的交易记录
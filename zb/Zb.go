@@ -11,12 +11,15 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 const (
 	MARKET_URL = "http://api.bitkk.com/data/v1/"
 	TICKER_API = "ticker?market=%s"
 	DEPTH_API  = "depth?market=%s&size=%d"
+	SYMBOL_API = "markets"
 
 	TRADE_URL                 = "https://trade.zb.com/api/"
 	GET_ACCOUNT_API           = "getAccountInfo"
@@ -32,18 +35,63 @@ type Zb struct {
 	httpClient *http.Client
 	accessKey,
 	secretKey string
+	symbols *SymbolMapper
 }
 
 func New(httpClient *http.Client, accessKey, secretKey string) *Zb {
-	return &Zb{httpClient, accessKey, secretKey}
+	zb := &Zb{httpClient: httpClient, accessKey: accessKey, secretKey: secretKey}
+	zb.symbols = NewSymbolMapper(ZB, NewInstrumentCatalog(ZB, zb))
+	// ZB的BCH仍然使用BCC
+	zb.symbols.AddAssetAlias("BCC", BCH.Symbol)
+	return zb
+}
+
+func (zb *Zb) SymbolMapper() *SymbolMapper {
+	return zb.symbols
 }
 
 func (zb *Zb) GetExchangeName() string {
 	return ZB
 }
 
+/**
+ * 交易品种来自markets, 如{"btc_usdt":{"amountScale":4,"priceScale":2}}
+ */
+func (zb *Zb) GetInstruments() ([]Instrument, error) {
+	var resp map[string]struct {
+		AmountScale int32
+		PriceScale  int32
+	}
+	err := HttpGet4(zb.httpClient, MARKET_URL+SYMBOL_API, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]Instrument, 0, len(resp))
+	for symbol, r := range resp {
+		pair := NewCurrencyPair2(strings.ToUpper(symbol))
+		if pair == UNKNOWN_PAIR {
+			continue
+		}
+		inst := Instrument{
+			Exchange:        ZB,
+			Symbol:          symbol,
+			Pair:            pair,
+			Type:            INSTRUMENT_SPOT,
+			PricePrecision:  r.PriceScale,
+			AmountPrecision: r.AmountScale,
+		}
+		inst.FillPrecision()
+		ret = append(ret, inst)
+	}
+	return ret, nil
+}
+
 func (zb *Zb) GetTicker(currency CurrencyPair) (*Ticker, error) {
-	symbol := currency.AdaptBchToBcc().AdaptUsdToUsdt().ToSymbol("_")
+	symbol, err := zb.symbols.ToNative(currency, INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, err
+	}
 	resp, err := HttpGet(zb.httpClient, MARKET_URL+fmt.Sprintf(TICKER_API, symbol))
 	if err != nil {
 		return nil, err
@@ -65,7 +113,10 @@ func (zb *Zb) GetTicker(currency CurrencyPair) (*Ticker, error) {
 }
 
 func (zb *Zb) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
-	symbol := currency.AdaptBchToBcc().AdaptUsdToUsdt().ToSymbol("_")
+	symbol, err := zb.symbols.ToNative(currency, INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, err
+	}
 	resp, err := HttpGet(zb.httpClient, MARKET_URL+fmt.Sprintf(DEPTH_API, symbol, size))
 	if err != nil {
 		return nil, err
@@ -159,7 +210,7 @@ func (zb *Zb) GetAccount() (*Account, error) {
 		subAcc := SubAccount{}
 		subAcc.Amount = ToFloat64(vv["available"])
 		subAcc.ForzenAmount = ToFloat64(vv["freez"])
-		subAcc.Currency = NewCurrency(vv["key"].(string), "").AdaptBchToBcc()
+		subAcc.Currency = zb.symbols.CanonicalCurrency(NewCurrency(vv["key"].(string), ""))
 		acc.SubAccounts[subAcc.Currency] = subAcc
 	}
 
@@ -170,7 +221,10 @@ func (zb *Zb) GetAccount() (*Account, error) {
 }
 
 func (zb *Zb) placeOrder(amount, price string, currency CurrencyPair, tradeType int) (*Order, error) {
-	symbol := currency.AdaptBchToBcc().AdaptUsdToUsdt().ToSymbol("_")
+	symbol, err := zb.symbols.ToNative(currency, INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, err
+	}
 	params := url.Values{}
 	params.Set("method", "order")
 	params.Set("price", price)
@@ -229,7 +283,10 @@ func (zb *Zb) LimitSell(amount, price string, currency CurrencyPair) (*Order, er
 }
 
 func (zb *Zb) CancelOrder(orderId string, currency CurrencyPair) (bool, error) {
-	symbol := currency.AdaptBchToBcc().AdaptUsdToUsdt().ToSymbol("_")
+	symbol, err := zb.symbols.ToNative(currency, INSTRUMENT_SPOT)
+	if err != nil {
+		return false, err
+	}
 	params := url.Values{}
 	params.Set("method", "cancelOrder")
 	params.Set("id", orderId)
@@ -301,7 +358,10 @@ func parseOrder(order *Order, ordermap map[string]interface{}) {
 }
 
 func (zb *Zb) GetOneOrder(orderId string, currency CurrencyPair) (*Order, error) {
-	symbol := currency.AdaptBchToBcc().AdaptUsdToUsdt().ToSymbol("_")
+	symbol, err := zb.symbols.ToNative(currency, INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, err
+	}
 	params := url.Values{}
 	params.Set("method", "getOrder")
 	params.Set("id", orderId)
@@ -330,8 +390,11 @@ func (zb *Zb) GetOneOrder(orderId string, currency CurrencyPair) (*Order, error)
 }
 
 func (zb *Zb) GetUnfinishOrders(currency CurrencyPair) ([]Order, error) {
+	symbol, err := zb.symbols.ToNative(currency, INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, err
+	}
 	params := url.Values{}
-	symbol := currency.AdaptBchToBcc().AdaptUsdToUsdt().ToSymbol("_")
 	params.Set("method", "getUnfinishedOrdersIgnoreTradeType")
 	params.Set("currency", symbol)
 	params.Set("pageIndex", "1")
@@ -375,14 +438,79 @@ func (zb *Zb) GetOrderHistorys(currency CurrencyPair, currentPage, pageSize int)
 	return nil, nil
 }
 
+var klineTypes = map[int]string{
+	KLINE_PERIOD_1MIN:  "1min",
+	KLINE_PERIOD_5MIN:  "5min",
+	KLINE_PERIOD_15MIN: "15min",
+	KLINE_PERIOD_30MIN: "30min",
+	KLINE_PERIOD_60MIN: "1hour",
+	KLINE_PERIOD_4H:    "4hour",
+	KLINE_PERIOD_1DAY:  "1day",
+	KLINE_PERIOD_1WEEK: "1week",
+}
+
 func (zb *Zb) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
-	return nil, nil
+	klines, err := zb.GetKlineRecordsDecimal(currency, period, size, since)
+	if err != nil {
+		return nil, err
+	}
+	return KlineDecimalsToKlines(klines), nil
+}
+
+/**
+ * since为毫秒时间戳, 小于等于0时获取最近的size根K线
+ */
+func (zb *Zb) GetKlineRecordsDecimal(currency CurrencyPair, period, size, since int) ([]KlineDecimal, error) {
+	typ, err := KlinePeriodCode(klineTypes, period)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := zb.symbols.ToNative(currency, INSTRUMENT_SPOT)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetAllKlineDecimals(int64(since), size, 1000, func(q PageQuery) ([]KlineDecimal, error) {
+		params := url.Values{}
+		params.Set("market", strings.ToLower(symbol))
+		params.Set("type", typ)
+		params.Set("size", strconv.Itoa(q.Limit))
+		if q.From > 0 {
+			params.Set("since", strconv.FormatInt(q.From, 10))
+		}
+
+		var resp struct {
+			Error string
+			// [time, open, high, low, close, vol]
+			Data [][]decimal.Decimal
+		}
+		err := HttpGet4(zb.httpClient, MARKET_URL+"kline?"+params.Encode(), nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Error != "" {
+			return nil, errors.New(resp.Error)
+		}
+
+		klines := make([]KlineDecimal, 0, len(resp.Data))
+		for _, r := range resp.Data {
+			if len(r) < 6 {
+				continue
+			}
+			ts := r[0].IntPart()
+			if q.To > 0 && ts > q.To {
+				continue
+			}
+			klines = append(klines, KlineDecimal{Pair: currency, Timestamp: ts / 1000, Open: r[1], High: r[2], Low: r[3], Close: r[4], Vol: r[5]})
+		}
+		return klines, nil
+	})
 }
 
 func (zb *Zb) Withdraw(amount string, currency Currency, fees, receiveAddr, safePwd string) (string, error) {
 	params := url.Values{}
 	params.Set("method", "withdraw")
-	params.Set("currency", strings.ToLower(currency.AdaptBchToBcc().String()))
+	params.Set("currency", strings.ToLower(zb.symbols.NativeCurrency(currency).String()))
 	params.Set("amount", amount)
 	params.Set("fees", fees)
 	params.Set("receiveAddr", receiveAddr)
@@ -412,7 +540,7 @@ func (zb *Zb) Withdraw(amount string, currency Currency, fees, receiveAddr, safe
 func (zb *Zb) CancelWithdraw(id string, currency Currency, safePwd string) (bool, error) {
 	params := url.Values{}
 	params.Set("method", "cancelWithdraw")
-	params.Set("currency", strings.ToLower(currency.AdaptBchToBcc().String()))
+	params.Set("currency", strings.ToLower(zb.symbols.NativeCurrency(currency).String()))
 	params.Set("downloadId", id)
 	params.Set("safePwd", safePwd)
 	zb.buildPostForm(&params)
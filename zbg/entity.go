@@ -18,6 +18,21 @@ type Market struct {
 	State int
 }
 
+func (this *Market) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Exchange: EXCHANGE_NAME,
+		Symbol: this.Name,
+		Id: this.MarketId,
+		Pair: goex.NewCurrencyPair2(strings.ToUpper(this.Name)),
+		Type: goex.INSTRUMENT_SPOT,
+		PricePrecision: int32(this.PriceDecimal),
+		AmountPrecision: int32(this.AmountDecimal),
+		MinQty: this.MinAmount,
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 type CurrencyInfo struct {
 	TotalNumber decimal.Decimal
 	CurrencyId string
@@ -35,13 +35,18 @@ const (
 	TRADES = "/api/data/v1/trades?marketName=%s&dataSize=%d"
 )
 
+func init() {
+	// ZBG没有查询服务器时间的接口, 用行情接口响应的Date头估计
+	RegisterServerClock(NewServerClock(EXCHANGE_NAME, DateHeaderTimeFetcher(API_BASE_URL + MARKET_LIST)))
+}
+
 type ZBG struct {
 	ApiId string
 	SecretKey string
 	client *http.Client
 
 	currencyInfoMap map[string]CurrencyInfo
-	marketMap map[string]Market
+	symbols *SymbolMapper
 
 }
 
@@ -51,7 +56,7 @@ func NewZBG(ApiId string, SecretKey string) *ZBG {
 	this.SecretKey = SecretKey
 	this.client = http.DefaultClient
 	this.currencyInfoMap = make(map[string]CurrencyInfo)
-	this.marketMap = make(map[string]Market)
+	this.symbols = NewSymbolMapper(EXCHANGE_NAME, NewInstrumentCatalog(EXCHANGE_NAME, this))
 	return this
 }
 
@@ -84,34 +89,19 @@ func (this *ZBG) getCurrencyNameById(id string) string {
 	return c.Name
 }
 
-func (this *ZBG) getMarketIdByName(name string) string {
-	name = strings.ToUpper(name)
-	c, ok := this.marketMap[name]
-	if ok {
-		return c.MarketId
-	}
+func (this *ZBG) SymbolMapper() *SymbolMapper {
+	return this.symbols
+}
 
-	var err error
-	var l []Market
-	for i := 0; i < 5; i++ {
-		l, err = this.GetMarketList()
-		if err == nil {
-			break
-		}
-		time.Sleep(time.Second)
-	}
+/**
+ * 交易对名称对应的marketId, 交易对从GetInstruments加载
+ */
+func (this *ZBG) getMarketId(marketName string) (string, error) {
+	inst, err := this.symbols.FromNative(marketName)
 	if err != nil {
-		panic(err)
-	}
-
-	for _, o := range l {
-		this.marketMap[strings.ToUpper(o.Name)] = o
-	}
-	c, ok = this.marketMap[name]
-	if !ok {
-		return ""
+		return "", err
 	}
-	return c.MarketId
+	return inst.Id, nil
 }
 
 func (ok *ZBG) GetMarketList() ([]Market, error) {
@@ -143,12 +133,24 @@ func (ok *ZBG) GetMarketList() ([]Market, error) {
 	}
 
 	if data.ResMsg.Code.IntPart() != 1 {
-		return nil, fmt.Errorf("error code: %s", data.ResMsg.Code.String())
+		return nil, zbgError(data.ResMsg.Code.String(), data.ResMsg.Message)
 	}
 
 	return data.Datas, nil
 }
 
+func (ok *ZBG) GetInstruments() ([]Instrument, error) {
+	markets, err := ok.GetMarketList()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(markets))
+	for i := range markets {
+		ret[i] = markets[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (ok *ZBG) GetCurrencyList() ([]CurrencyInfo, error) {
 	url := API_BASE_URL + CURRENCY_LIST
 	resp, err := ok.client.Get(url)
@@ -178,7 +180,7 @@ func (ok *ZBG) GetCurrencyList() ([]CurrencyInfo, error) {
 	}
 
 	if data.ResMsg.Code.IntPart() != 1 {
-		return nil, fmt.Errorf("error code: %s", data.ResMsg.Code.String())
+		return nil, zbgError(data.ResMsg.Code.String(), data.ResMsg.Message)
 	}
 
 	return data.Datas, nil
@@ -213,7 +215,7 @@ func (ok *ZBG) GetTicker(market string) (*TickerDecimal, error) {
 	}
 
 	if data.ResMsg.Code.IntPart() != 1 {
-		return nil, fmt.Errorf("error code: %s", data.ResMsg.Code.String())
+		return nil, zbgError(data.ResMsg.Code.String(), data.ResMsg.Message)
 	}
 
 	r := data.Datas
@@ -266,7 +268,7 @@ func (ok *ZBG) GetDepth(market string, dataSize int) (*DepthDecimal, error) {
 	}
 
 	if data.ResMsg.Code.IntPart() != 1 {
-		return nil, fmt.Errorf("error code: %s", data.ResMsg.Code.String())
+		return nil, zbgError(data.ResMsg.Code.String(), data.ResMsg.Message)
 	}
 
 	r := data.Datas
@@ -323,7 +325,7 @@ func (ok *ZBG) GetTrades(market string, dataSize int) ([]TradeDecimal, error) {
 	}
 
 	if data.ResMsg.Code.IntPart() != 1 {
-		return nil, fmt.Errorf("error code: %s", data.ResMsg.Code.String())
+		return nil, zbgError(data.ResMsg.Code.String(), data.ResMsg.Message)
 	}
 
 	var trades = make([]TradeDecimal, len(data.Datas))
@@ -345,7 +347,7 @@ func (ok *ZBG) GetTrades(market string, dataSize int) ([]TradeDecimal, error) {
 }
 
 func (this *ZBG) signData(data string) map[string]string {
-	timestamp := strconv.FormatInt(time.Now().UnixNano() / int64(time.Millisecond), 10)
+	timestamp := strconv.FormatInt(ServerTime(EXCHANGE_NAME).UnixNano() / int64(time.Millisecond), 10)
 	message := this.ApiId + timestamp + data + this.SecretKey
 	sign, _ := GetParamMD5Sign(this.SecretKey, message)
 
@@ -415,7 +417,7 @@ func (this *ZBG) GetAccount(page int, pageSize int) ([]SubAccountDecimal, error)
 	}
 
 	if resp.ResMsg.Code.IntPart() != 1 {
-		return nil, fmt.Errorf("error code: %s", resp.ResMsg.Code.String())
+		return nil, zbgError(resp.ResMsg.Code.String(), resp.ResMsg.Message)
 	}
 
 	var ret []SubAccountDecimal
@@ -436,7 +438,10 @@ func (this *ZBG) GetAccount(page int, pageSize int) ([]SubAccountDecimal, error)
 }
 
 func (this *ZBG) PlaceOrder(amount decimal.Decimal, _type int, marketName string, price decimal.Decimal) (string, error) {
-	marketId := this.getMarketIdByName(marketName)
+	marketId, err := this.getMarketId(marketName)
+	if err != nil {
+		return "", err
+	}
 	params := map[string]interface{} {
 		"amount": amount,
 		"rangeType": 0,
@@ -473,14 +478,17 @@ func (this *ZBG) PlaceOrder(amount decimal.Decimal, _type int, marketName string
 	}
 
 	if resp.ResMsg.Code.IntPart() != 1 {
-		return "", fmt.Errorf("error code: %s", resp.ResMsg.Code.String())
+		return "", zbgError(resp.ResMsg.Code.String(), resp.ResMsg.Message)
 	}
 
 	return resp.Datas.EntrustId, nil
 }
 
 func (this *ZBG) CancelOrder(marketName string, entrustId string) error {
-	marketId := this.getMarketIdByName(marketName)
+	marketId, err := this.getMarketId(marketName)
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{} {
 		"marketId": marketId,
 		"entrustId": entrustId,
@@ -511,17 +519,16 @@ func (this *ZBG) CancelOrder(marketName string, entrustId string) error {
 	}
 
 	if resp.ResMsg.Code.IntPart() != 1 {
-		return fmt.Errorf("error code: %s", resp.ResMsg.Code.String())
+		return zbgError(resp.ResMsg.Code.String(), resp.ResMsg.Message)
 	}
 
 	return nil
 }
 
 func (this *ZBG) QueryPendingOrders(marketName string) ([]OrderDecimal, error) {
-	marketName = strings.ToUpper(marketName)
-	marketId := this.getMarketIdByName(marketName)
-	if marketId == "" {
-		return nil, fmt.Errorf("unknown market %s", marketName)
+	marketId, err := this.getMarketId(marketName)
+	if err != nil {
+		return nil, err
 	}
 	header := this.signGet(map[string]string{"marketId": marketId})
 
@@ -535,13 +542,13 @@ func (this *ZBG) QueryPendingOrders(marketName string) ([]OrderDecimal, error) {
 		Datas []OrderInfo
 	}
 
-	err := HttpGet4(this.client, url, header, &resp)
+	err = HttpGet4(this.client, url, header, &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.ResMsg.Code.IntPart() != 1 {
-		return nil, fmt.Errorf("error code: %s", resp.ResMsg.Code.String())
+		return nil, zbgError(resp.ResMsg.Code.String(), resp.ResMsg.Message)
 	}
 
 	var ret = make([]OrderDecimal, len(resp.Datas))
@@ -560,10 +567,9 @@ func (this *ZBG) QueryPagedPendingOrders(marketName string, pageIndex, pageSize
 		pageSize = 20
 	}
 
-	marketName = strings.ToUpper(marketName)
-	marketId := this.getMarketIdByName(marketName)
-	if marketId == "" {
-		return nil, fmt.Errorf("unknown market %s", marketName)
+	marketId, err := this.getMarketId(marketName)
+	if err != nil {
+		return nil, err
 	}
 	header := this.signGet(map[string]string{
 		"marketId": marketId,
@@ -587,13 +593,13 @@ func (this *ZBG) QueryPagedPendingOrders(marketName string, pageIndex, pageSize
 			  }
 	}
 
-	err := HttpGet4(this.client, url, header, &resp)
+	err = HttpGet4(this.client, url, header, &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.ResMsg.Code.IntPart() != 1 {
-		return nil, fmt.Errorf("error code: %s", resp.ResMsg.Code.String())
+		return nil, zbgError(resp.ResMsg.Code.String(), resp.ResMsg.Message)
 	}
 
 	var ret = make([]OrderDecimal, len(resp.Datas.EntrustList))
@@ -611,10 +617,9 @@ func (this *ZBG) QueryDoneOrders(marketName string, pageIndex, pageSize int) ([]
 	if pageSize == 0 {
 		pageSize = 20
 	}
-	marketName = strings.ToUpper(marketName)
-	marketId := this.getMarketIdByName(marketName)
-	if marketId == "" {
-		return nil, fmt.Errorf("unknown market %s", marketName)
+	marketId, err := this.getMarketId(marketName)
+	if err != nil {
+		return nil, err
 	}
 	header := this.signGet(map[string]string{
 		"marketId": marketId,
@@ -637,13 +642,13 @@ func (this *ZBG) QueryDoneOrders(marketName string, pageIndex, pageSize int) ([]
 	    }
 	}
 
-	err := HttpGet4(this.client, url, header, &resp)
+	err = HttpGet4(this.client, url, header, &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.ResMsg.Code.IntPart() != 1 {
-		return nil, fmt.Errorf("error code: %s", resp.ResMsg.Code.String())
+		return nil, zbgError(resp.ResMsg.Code.String(), resp.ResMsg.Message)
 	}
 
 	var ret = make([]OrderDecimal, len(resp.Datas.EntrustList))
@@ -655,10 +660,9 @@ func (this *ZBG) QueryDoneOrders(marketName string, pageIndex, pageSize int) ([]
 }
 
 func (this *ZBG) QueryOrder(marketName string, entrustId string) (*OrderDecimal, error) {
-	marketName = strings.ToUpper(marketName)
-	marketId := this.getMarketIdByName(marketName)
-	if marketId == "" {
-		return nil, fmt.Errorf("unknown market %s", marketName)
+	marketId, err := this.getMarketId(marketName)
+	if err != nil {
+		return nil, err
 	}
 	header := this.signGet(map[string]string{
 		"marketId": marketId,
@@ -675,13 +679,13 @@ func (this *ZBG) QueryOrder(marketName string, entrustId string) (*OrderDecimal,
 		Datas *OrderInfo
 	}
 
-	err := HttpGet4(this.client, url, header, &resp)
+	err = HttpGet4(this.client, url, header, &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.ResMsg.Code.IntPart() != 1 {
-		return nil, fmt.Errorf("error code: %s", resp.ResMsg.Code.String())
+		return nil, zbgError(resp.ResMsg.Code.String(), resp.ResMsg.Message)
 	}
 
 	if resp.Datas == nil {
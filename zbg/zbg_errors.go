@@ -0,0 +1,12 @@
+package zbg
+
+import (
+	. "github.com/stephenlyu/GoEx"
+)
+
+const EXCHANGE_NAME = "zbg.com"
+
+// zbg返回{"resMsg":{"code":"1","message":"..."}}, code为1表示成功, 没有公开的错误码表, 按message分类
+func zbgError(code, message string) error {
+	return NewExchangeError(EXCHANGE_NAME, ClassifyMessage(message), code, message)
+}
@@ -12,6 +12,8 @@ import (
 	"time"
 )
 
+var _ goex.InstrumentAPI = (*ZBG)(nil)
+
 var zbg *ZBG
 
 func chk(err error) {
@@ -13,6 +13,18 @@ type Symbol struct {
 	PricePrecision  int `json:"price_precision"`
 }
 
+func (this *Symbol) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Symbol: this.Symbol,
+		Pair: goex.NewCurrencyPair2(this.Symbol),
+		Type: goex.INSTRUMENT_SPOT,
+		PricePrecision: int32(this.PricePrecision),
+		AmountPrecision: int32(this.AmountPrecision),
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 type OrderInfo struct {
 	ID         decimal.Decimal
 	Price      decimal.Decimal
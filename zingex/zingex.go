@@ -154,6 +154,18 @@ func (ok *ZingEx) GetSymbols() ([]Symbol, error) {
 	return data.Ticker, nil
 }
 
+func (ok *ZingEx) GetInstruments() ([]Instrument, error) {
+	symbols, err := ok.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (this *ZingEx) transSymbol(symbol string) string {
 	symbol = strings.ToLower(symbol)
 	return this.symbolNameMap[symbol]
@@ -12,6 +12,19 @@ type Symbol struct {
 	QuoteAssetPrecision int
 }
 
+// ToInstrument convert to instrument
+func (symbol *Symbol) ToInstrument() goex.Instrument {
+	inst := goex.Instrument{
+		Symbol:          symbol.Symbol,
+		Pair:            goex.NewCurrencyPair2(symbol.Symbol),
+		Type:            goex.INSTRUMENT_SPOT,
+		PricePrecision:  int32(symbol.QuoteAssetPrecision),
+		AmountPrecision: int32(symbol.BaseAssetPrecision),
+	}
+	inst.FillPrecision()
+	return inst
+}
+
 // OrderInfo is order info
 type OrderInfo struct {
 	Amount    decimal.Decimal
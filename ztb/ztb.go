@@ -108,6 +108,19 @@ func (ztb *Ztb) GetSymbols() ([]Symbol, error) {
 	return data, nil
 }
 
+// GetInstruments is for getting Ztb symbols as instruments
+func (ztb *Ztb) GetInstruments() ([]goex.Instrument, error) {
+	symbols, err := ztb.GetSymbols()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]goex.Instrument, len(symbols))
+	for i := range symbols {
+		ret[i] = symbols[i].ToInstrument()
+	}
+	return ret, nil
+}
+
 func (ztb *Ztb) transSymbol(symbol string) string {
 	return symbol
 }